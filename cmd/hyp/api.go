@@ -272,6 +272,7 @@ import (
 	"{{.ProjectName}}/internal/database"
 	"{{.ProjectName}}/internal/logger"
 	
+	"github.com/maoxiaoyue/hypgo/pkg/migrate"
 	"github.com/maoxiaoyue/hypgo/pkg/server"
 	hypContext "github.com/maoxiaoyue/hypgo/pkg/context"
 )
@@ -304,67 +305,59 @@ func main() {
 		hypContext.SetMode("release")
 	}
 
-	// 初始化數據庫
-	db, err := database.Init(cfg.Database)
-	if err != nil {
-		log.Emergencyf("Failed to initialize database: %v", err)
-		os.Exit(1)
-	}
-	defer database.Close()
+	// 創建服務器
+	srv := server.New(cfg, log)
 
-	// 初始化 Redis
-	if err := cache.Init(cfg.Redis); err != nil {
-		log.Warningf("Failed to initialize Redis: %v", err)
-		// Redis 是可選的，不退出
-	}
-	defer cache.Close()
+	// 啟動步驟：依序執行，任一步驟失敗則不開啟監聽；資源在 Shutdown 時以相反順序釋放
+	srv.OnStart("database", func(ctx context.Context) error {
+		if _, err := database.Init(cfg.Database); err != nil {
+			return err
+		}
+		srv.OnShutdown(func(context.Context) error { return database.Close() })
+		return nil
+	})
 
-	// 自動遷移數據庫
-	if cfg.Database.AutoMigrate {
-		log.Info("Running database migrations...")
-		if err := models.AutoMigrate(db); err != nil {
-			log.Errorf("Failed to migrate database: %v", err)
+	srv.OnStart("redis", func(ctx context.Context) error {
+		if err := cache.Init(cfg.Redis); err != nil {
+			// Redis 是可選的，不中止啟動
+			log.Warningf("Failed to initialize Redis: %v", err)
+			return nil
 		}
+		srv.OnShutdown(func(context.Context) error { return cache.Close() })
+		return nil
+	})
+
+	if cfg.Database.AutoMigrate {
+		srv.OnStart("migrations", func(ctx context.Context) error {
+			return models.AutoMigrate(database.GetDB())
+		})
+	}
+
+	// migration 就緒檢查：schema_migrations 落後 migrations/ 最新版本時 readiness 回報 degraded
+	if cfg.Database.MigrationCheck {
+		srv.OnStart("migration check", func(ctx context.Context) error {
+			dialect, err := migrate.DialectFor(cfg.Database.Driver)
+			if err != nil {
+				return err
+			}
+			migrations, err := migrate.LoadDir("migrations")
+			if err != nil {
+				return err
+			}
+			migrate.New(database.GetSQLDB(), dialect, migrations).RegisterHealthCheck(srv.HealthRegistry())
+			return nil
+		})
 	}
 
-	// 創建服務器
-	srv := server.NewWithContext(cfg, log)
-	
 	// 設置路由
 	setupRoutes(srv, cfg, log)
 
-	// 啟動服務器
+	// 啟動服務器：Start 先依序執行上方以 OnStart 註冊的步驟，任一步驟失敗即回傳錯誤而不開啟監聽；
+	// 協定（http1 / http2 / http3）與 TLS 依 config.yaml 的 server 區段決定
 	serverErrors := make(chan error, 1)
 	go func() {
-		log.Infof("Server starting on %s with protocol %s", 
-			cfg.Server.Addr, 
-			cfg.Server.Protocol)
-		
-		switch cfg.Server.Protocol {
-		case "http3":
-			if cfg.Server.TLS.Enabled {
-				serverErrors <- srv.StartHTTP3(
-					cfg.Server.Addr,
-					cfg.Server.TLS.CertFile,
-					cfg.Server.TLS.KeyFile,
-				)
-			} else {
-				log.Warning("HTTP/3 requires TLS, falling back to HTTP/2")
-				serverErrors <- srv.StartHTTP2(cfg.Server.Addr)
-			}
-		case "http2":
-			if cfg.Server.TLS.Enabled {
-				serverErrors <- srv.StartTLS(
-					cfg.Server.Addr,
-					cfg.Server.TLS.CertFile,
-					cfg.Server.TLS.KeyFile,
-				)
-			} else {
-				serverErrors <- srv.Start(cfg.Server.Addr)
-			}
-		default:
-			serverErrors <- srv.Start(cfg.Server.Addr)
-		}
+		log.Infof("Server starting on %s with protocol %s", cfg.Server.Addr, cfg.Server.Protocol)
+		serverErrors <- srv.Start()
 	}()
 
 	// 優雅關閉
@@ -679,6 +672,7 @@ type Config struct {
 	ConnMaxLifetime string ` + "`yaml:\"conn_max_lifetime\" json:\"conn_max_lifetime\"`" + `
 	LogLevel        string ` + "`yaml:\"log_level\" json:\"log_level\"`" + `
 	AutoMigrate     bool   ` + "`yaml:\"auto_migrate\" json:\"auto_migrate\"`" + `
+	MigrationCheck  bool   ` + "`yaml:\"migration_check\" json:\"migration_check\"`" + `
 }
 
 var (
@@ -775,9 +769,13 @@ const cacheInitContent = `package cache
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log"
 	"time"
 
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
 	"github.com/redis/go-redis/v9"
 )
 
@@ -791,13 +789,20 @@ type Config struct {
 	DialTimeout  string ` + "`yaml:\"dial_timeout\" json:\"dial_timeout\"`" + `
 	ReadTimeout  string ` + "`yaml:\"read_timeout\" json:\"read_timeout\"`" + `
 	WriteTimeout string ` + "`yaml:\"write_timeout\" json:\"write_timeout\"`" + `
+	// 連續失敗幾次後熔斷（預設 5）與熔斷持續時間（預設 30s）
+	BreakerThreshold int    ` + "`yaml:\"breaker_threshold\" json:\"breaker_threshold\"`" + `
+	BreakerCooldown  string ` + "`yaml:\"breaker_cooldown\" json:\"breaker_cooldown\"`" + `
 }
 
 var (
 	client *redis.Client
 	ctx    = context.Background()
+	cb     *resilience.CircuitBreaker
 )
 
+// ErrUnavailable Redis 熔斷中或未初始化
+var ErrUnavailable = errors.New("cache: redis unavailable")
+
 // Init 初始化 Redis 連接
 func Init(cfg Config) error {
 	// 設置默認值
@@ -813,6 +818,21 @@ func Init(cfg Config) error {
 	if cfg.MaxRetries == 0 {
 		cfg.MaxRetries = 3
 	}
+	cooldown, _ := time.ParseDuration(cfg.BreakerCooldown)
+	cb = resilience.NewCircuitBreaker(resilience.BreakerConfig{
+		Name:             "redis",
+		FailureThreshold: cfg.BreakerThreshold,
+		Cooldown:         cooldown,
+		// redis.Nil 是未命中，不是故障
+		IsFailure: func(err error) bool {
+			return resilience.DefaultIsFailure(err) && !errors.Is(err, redis.Nil)
+		},
+		Hooks: resilience.Hooks{
+			OnStateChange: func(name string, from, to resilience.State) {
+				log.Printf("[cache] %s circuit %s -> %s", name, from, to)
+			},
+		},
+	})
 
 	// 解析超時時間
 	dialTimeout, _ := time.ParseDuration(cfg.DialTimeout)
@@ -851,7 +871,7 @@ func Init(cfg Config) error {
 	return nil
 }
 
-// GetClient 獲取 Redis 客戶端
+// GetClient 獲取 Redis 客戶端（直接操作不經過熔斷器）
 func GetClient() *redis.Client {
 	return client
 }
@@ -864,39 +884,119 @@ func Close() error {
 	return nil
 }
 
-// Set 設置鍵值
+// ===== 熔斷器：快取是 best-effort，Redis 故障時降級為未命中 =====
+
+// do 經由熔斷器執行 Redis 操作；連續失敗達門檻後熔斷，冷卻期內不呼叫 Redis
+func do(fn func() error) error {
+	if client == nil || cb == nil {
+		return ErrUnavailable
+	}
+	err := cb.Execute(ctx, func(context.Context) error { return fn() })
+	if errors.Is(err, resilience.ErrOpen) {
+		return ErrUnavailable
+	}
+	return err
+}
+
+// Available Redis 目前是否可用（未熔斷）
+func Available() bool {
+	return client != nil && cb != nil && cb.State() != resilience.StateOpen
+}
+
+// ===== 快取操作 =====
+
+// Set 設置鍵值（熔斷中直接略過）
 func Set(key string, value interface{}, expiration time.Duration) error {
-	return client.Set(ctx, key, value, expiration).Err()
+	return skipUnavailable(do(func() error {
+		return client.Set(ctx, key, value, expiration).Err()
+	}))
 }
 
-// Get 獲取值
+// Get 獲取值；未命中與 Redis 故障都回傳 redis.Nil，呼叫端一律回源
 func Get(key string) (string, error) {
-	return client.Get(ctx, key).Result()
+	var val string
+	err := do(func() (err error) {
+		val, err = client.Get(ctx, key).Result()
+		return err
+	})
+	if err != nil {
+		return "", redis.Nil
+	}
+	return val, nil
 }
 
-// Delete 刪除鍵
+// Delete 刪除鍵（熔斷中直接略過）
 func Delete(keys ...string) error {
-	return client.Del(ctx, keys...).Err()
+	return skipUnavailable(do(func() error {
+		return client.Del(ctx, keys...).Err()
+	}))
 }
 
-// Exists 檢查鍵是否存在
+// Exists 檢查鍵是否存在（熔斷中視為不存在）
 func Exists(keys ...string) (int64, error) {
-	return client.Exists(ctx, keys...).Result()
+	var n int64
+	err := do(func() (err error) {
+		n, err = client.Exists(ctx, keys...).Result()
+		return err
+	})
+	return n, skipUnavailable(err)
 }
 
-// SetNX 只在鍵不存在時設置
+// SetNX 只在鍵不存在時設置（熔斷中回傳 ErrUnavailable，不可當作已取得鎖）
 func SetNX(key string, value interface{}, expiration time.Duration) (bool, error) {
-	return client.SetNX(ctx, key, value, expiration).Result()
+	var ok bool
+	err := do(func() (err error) {
+		ok, err = client.SetNX(ctx, key, value, expiration).Result()
+		return err
+	})
+	return ok, err
 }
 
-// Incr 增加計數
+// Incr 增加計數（熔斷中回傳 ErrUnavailable）
 func Incr(key string) (int64, error) {
-	return client.Incr(ctx, key).Result()
+	var n int64
+	err := do(func() (err error) {
+		n, err = client.Incr(ctx, key).Result()
+		return err
+	})
+	return n, err
 }
 
-// Expire 設置過期時間
+// Expire 設置過期時間（熔斷中直接略過）
 func Expire(key string, expiration time.Duration) error {
-	return client.Expire(ctx, key, expiration).Err()
+	return skipUnavailable(do(func() error {
+		return client.Expire(ctx, key, expiration).Err()
+	}))
+}
+
+// Remember 讀取快取，未命中（含 Redis 故障）時呼叫 load 並以 JSON 寫回
+// Redis 不可用時直接回傳 load 的結果，應用仍可由資料庫提供服務
+//
+//	user, err := cache.Remember("user:"+id, 10*time.Minute, func() (*models.User, error) {
+//	    return services.FindUser(id)
+//	})
+func Remember[T any](key string, ttl time.Duration, load func() (T, error)) (T, error) {
+	if raw, err := Get(key); err == nil {
+		var v T
+		if json.Unmarshal([]byte(raw), &v) == nil {
+			return v, nil
+		}
+	}
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	if data, err := json.Marshal(v); err == nil {
+		_ = Set(key, data, ttl)
+	}
+	return v, nil
+}
+
+func skipUnavailable(err error) error {
+	if errors.Is(err, ErrUnavailable) {
+		return nil
+	}
+	return err
 }
 `
 
@@ -907,10 +1007,17 @@ const configYamlContent = `# HypGo API Configuration
 server:
   protocol: http3         # http1, http2, http3
   addr: :8080
+  http3_addr: ""          # HTTP/3 UDP 位址，留空與 addr 相同
+  alt_svc_max_age: 86400  # 秒，Alt-Svc 的 ma 參數
+  http3_bind_retries: 3   # UDP 埠被占用時的重試次數（-1 不重試）；auto 模式仍失敗時降級為 HTTP/1.1 + HTTP/2
+  http3_bind_retry_delay: 1s
   read_timeout: 30s
   write_timeout: 30s
-  idle_timeout: 120s
-  keep_alive: 30s
+  read_header_timeout: 5s  # 標頭讀取期限，防 slow-loris
+  max_header_bytes: 1048576
+  max_multipart_memory: 33554432  # multipart 表單保留在記憶體的上限，超出部分寫入暫存檔
+  idle_timeout: 120        # 秒，HTTP 閒置連線關閉時間
+  keep_alive: 30           # 秒，TCP keep-alive 探測週期（-1 停用）
   max_handlers: 1000
   max_concurrent_streams: 100
   max_read_frame_size: 1048576
@@ -921,6 +1028,14 @@ server:
     key_file: "certs/server.key"
     auto_cert: false
     domains: []
+    session_ticket_rotation: 12h  # TCP session ticket 金鑰輪替週期
+  quic:                   # HTTP/3 調校，0 表示使用 quic-go 預設
+    max_incoming_streams: 100
+    max_stream_receive_window: 6291456       # 6MB，高延遲鏈路可調高
+    max_connection_receive_window: 15728640  # 15MB
+    max_idle_timeout: 30s
+    keep_alive_period: 0s
+    allow_0rtt: false     # 0-RTT 可被重放，僅 GET/HEAD/OPTIONS 接受 early data
 
 database:
   driver: postgres        # postgres, mysql, sqlite
@@ -930,6 +1045,7 @@ database:
   conn_max_lifetime: 1h
   log_level: warning      # silent, error, warning, info
   auto_migrate: true
+  migration_check: false  # readiness 比對 schema_migrations 與 migrations/ 最新版本，搭配 hyp migrate up 使用
 
 redis:
   addr: "${REDIS_ADDR}"
@@ -941,6 +1057,8 @@ redis:
   dial_timeout: 5s
   read_timeout: 3s
   write_timeout: 3s
+  breaker_threshold: 5    # 連續失敗幾次後熔斷，期間快取視為未命中
+  breaker_cooldown: 30s
 
 logger:
   level: debug            # debug, info, notice, warning, error, emergency
@@ -996,8 +1114,10 @@ monitoring:
   metrics_path: "/metrics"
   health_path: "/health"
   trace_enabled: false
-  trace_provider: "jaeger"
+  trace_provider: "jaeger"          # otlp / jaeger（Jaeger 1.35+ 的 OTLP/HTTP 端點）
   trace_endpoint: "${TRACE_ENDPOINT}"
+  trace_sample_rate: 1.0            # 0~1，0 表示不取樣
+  service_name: "hypgo-api"
 `
 
 const envExampleContent = `# Server Configuration
@@ -1037,7 +1157,7 @@ TLS_AUTO_CERT=false
 # Monitoring
 METRICS_ENABLED=true
 TRACE_ENABLED=false
-TRACE_ENDPOINT=http://localhost:14268/api/traces
+TRACE_ENDPOINT=http://localhost:4318/v1/traces
 
 # Logging
 LOG_LEVEL=debug
@@ -1048,6 +1168,7 @@ LOG_FILE=logs/api.log
 const apiControllerContent = `package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 	
@@ -1069,56 +1190,36 @@ func GetUsers(ctx *context.Context) {
 	users, total, err := userService.GetUsers(page, pageSize)
 	if err != nil {
 		logger.Errorf("Failed to get users: %v", err)
-		ctx.AbortWithStatusJSON(http.StatusInternalServerError, context.H{
-			"error": "Failed to retrieve users",
-		})
+		ctx.Fail(http.StatusInternalServerError, "Failed to retrieve users")
 		return
 	}
 	
 	// 設置響應頭
 	ctx.Header("X-Total-Count", strconv.Itoa(total))
 	
-	ctx.JSON(http.StatusOK, context.H{
-		"success": true,
-		"data":    users,
-		"meta": context.H{
-			"total":     total,
-			"page":      page,
-			"page_size": pageSize,
-		},
-	})
+	ctx.Paginated(users, total, page, pageSize)
 }
 
 // GetUser 獲取單個用戶
 func GetUser(ctx *context.Context) {
-	userID, err := strconv.Atoi(ctx.Param("id"))
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, context.H{
-			"error": "Invalid user ID",
-		})
+	userID, ok := ctx.MustParamInt("id")
+	if !ok {
 		return
 	}
 	
 	userService := services.NewUserService(database.GetDB())
 	user, err := userService.GetUserByID(userID)
 	if err != nil {
-		if err == services.ErrNotFound {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, context.H{
-				"error": "User not found",
-			})
+		if errors.Is(err, services.ErrNotFound) {
+			ctx.Fail(http.StatusNotFound, "User not found")
 		} else {
 			logger.Errorf("Failed to get user: %v", err)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, context.H{
-				"error": "Failed to retrieve user",
-			})
+			ctx.Fail(http.StatusInternalServerError, "Failed to retrieve user")
 		}
 		return
 	}
 	
-	ctx.JSON(http.StatusOK, context.H{
-		"success": true,
-		"data":    user,
-	})
+	ctx.Success(http.StatusOK, user)
 }
 
 // CreateUser 創建用戶
@@ -1136,15 +1237,11 @@ func CreateUser(ctx *context.Context) {
 	userService := services.NewUserService(database.GetDB())
 	user, err := userService.CreateUser(req)
 	if err != nil {
-		if err == services.ErrDuplicate {
-			ctx.AbortWithStatusJSON(http.StatusConflict, context.H{
-				"error": "User already exists",
-			})
+		if errors.Is(err, services.ErrDuplicate) {
+			ctx.Fail(http.StatusConflict, "User already exists")
 		} else {
 			logger.Errorf("Failed to create user: %v", err)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, context.H{
-				"error": "Failed to create user",
-			})
+			ctx.Fail(http.StatusInternalServerError, "Failed to create user")
 		}
 		return
 	}
@@ -1158,43 +1255,32 @@ func CreateUser(ctx *context.Context) {
 
 // UpdateUser 更新用戶
 func UpdateUser(ctx *context.Context) {
-	userID, err := strconv.Atoi(ctx.Param("id"))
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, context.H{
-			"error": "Invalid user ID",
-		})
+	userID, ok := ctx.MustParamInt("id")
+	if !ok {
 		return
 	}
 	
 	// 檢查權限
 	currentUserID := ctx.GetInt("user_id")
 	if currentUserID != userID && !ctx.HasRole("admin") {
-		ctx.AbortWithStatusJSON(http.StatusForbidden, context.H{
-			"error": "Permission denied",
-		})
+		ctx.Fail(http.StatusForbidden, "Permission denied")
 		return
 	}
 	
 	var req models.UpdateUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, context.H{
-			"error": "Invalid request data",
-		})
+		ctx.Fail(http.StatusBadRequest, "Invalid request data")
 		return
 	}
 	
 	userService := services.NewUserService(database.GetDB())
 	user, err := userService.UpdateUser(userID, req)
 	if err != nil {
-		if err == services.ErrNotFound {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, context.H{
-				"error": "User not found",
-			})
+		if errors.Is(err, services.ErrNotFound) {
+			ctx.Fail(http.StatusNotFound, "User not found")
 		} else {
 			logger.Errorf("Failed to update user: %v", err)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, context.H{
-				"error": "Failed to update user",
-			})
+			ctx.Fail(http.StatusInternalServerError, "Failed to update user")
 		}
 		return
 	}
@@ -1208,25 +1294,18 @@ func UpdateUser(ctx *context.Context) {
 
 // DeleteUser 刪除用戶
 func DeleteUser(ctx *context.Context) {
-	userID, err := strconv.Atoi(ctx.Param("id"))
-	if err != nil {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, context.H{
-			"error": "Invalid user ID",
-		})
+	userID, ok := ctx.MustParamInt("id")
+	if !ok {
 		return
 	}
 	
 	userService := services.NewUserService(database.GetDB())
 	if err := userService.DeleteUser(userID); err != nil {
-		if err == services.ErrNotFound {
-			ctx.AbortWithStatusJSON(http.StatusNotFound, context.H{
-				"error": "User not found",
-			})
+		if errors.Is(err, services.ErrNotFound) {
+			ctx.Fail(http.StatusNotFound, "User not found")
 		} else {
 			logger.Errorf("Failed to delete user: %v", err)
-			ctx.AbortWithStatusJSON(http.StatusInternalServerError, context.H{
-				"error": "Failed to delete user",
-			})
+			ctx.Fail(http.StatusInternalServerError, "Failed to delete user")
 		}
 		return
 	}
@@ -1237,9 +1316,7 @@ func DeleteUser(ctx *context.Context) {
 // WebSocket WebSocket 連接處理
 func WebSocket(ctx *context.Context) {
 	if !ctx.IsWebsocket() {
-		ctx.AbortWithStatusJSON(http.StatusBadRequest, context.H{
-			"error": "WebSocket connection required",
-		})
+		ctx.Fail(http.StatusBadRequest, "WebSocket connection required")
 		return
 	}
 	
@@ -1253,61 +1330,55 @@ const middlewareContent = `package middleware`
 const healthControllerContent = `package controllers
 
 import (
+	stdcontext "context"
+	"errors"
 	"net/http"
-	"runtime"
-	"time"
+	"sync"
 
 	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/health"
 	"{{.ProjectName}}/internal/cache"
 	"{{.ProjectName}}/internal/database"
 )
 
-// HealthCheck 健康檢查
-func HealthCheck(ctx *context.Context) {
-	// 檢查數據庫
-	dbStatus := "healthy"
-	if db := database.GetDB(); db != nil {
-		if err := db.Ping(); err != nil {
-			dbStatus = "unhealthy"
-		}
-	} else {
-		dbStatus = "not connected"
-	}
+var (
+	checksMu        sync.Mutex
+	databaseCheckOn bool
+	redisCheckOn    bool
+)
 
-	// 檢查 Redis
-	redisStatus := "healthy"
-	if client := cache.GetClient(); client != nil {
-		if err := client.Ping(client.Context()).Err(); err != nil {
-			redisStatus = "unhealthy"
-		}
-	} else {
-		redisStatus = "not connected"
+// registerHealthChecks 將已連線的數據庫與 Redis 註冊至健康檢查中心，尚未連線者留待之後的請求再註冊
+// 檢查時才取得當下的連線，之後重新連線也會檢查到新的連線
+// 其他子系統（Cassandra、Kafka…）可在啟動時自行呼叫 health.Register
+func registerHealthChecks() {
+	checksMu.Lock()
+	defer checksMu.Unlock()
+	if !databaseCheckOn && database.GetDB() != nil {
+		health.Register("database", func(c stdcontext.Context) error {
+			db := database.GetDB()
+			if db == nil {
+				return errors.New("database not connected")
+			}
+			return db.PingContext(c)
+		})
+		databaseCheckOn = true
 	}
+	if !redisCheckOn && cache.GetClient() != nil {
+		health.Register("redis", func(c stdcontext.Context) error {
+			client := cache.GetClient()
+			if client == nil {
+				return errors.New("redis not connected")
+			}
+			return client.Ping(c).Err()
+		})
+		redisCheckOn = true
+	}
+}
 
-	// 系統信息
-	var m runtime.MemStats
-	runtime.ReadMemStats(&m)
-
-	ctx.JSON(http.StatusOK, context.H{
-		"status":    "healthy",
-		"timestamp": time.Now().Unix(),
-		"protocol":  ctx.Protocol(),
-		"services": context.H{
-			"database": dbStatus,
-			"redis":    redisStatus,
-		},
-		"system": context.H{
-			"goroutines":   runtime.NumGoroutine(),
-			"memory_alloc": m.Alloc / 1024 / 1024,      // MB
-			"memory_sys":   m.Sys / 1024 / 1024,        // MB
-			"gc_runs":      m.NumGC,
-		},
-		"features": context.H{
-			"http3":     ctx.IsHTTP3(),
-			"http2":     ctx.IsHTTP2(),
-			"websocket": false,
-		},
-	})
+// HealthCheck 健康檢查（?verbose 顯示各項耗時）
+func HealthCheck(ctx *context.Context) {
+	registerHealthChecks()
+	health.Handler()(ctx)
 }
 
 // Metrics Prometheus 指標
@@ -1403,9 +1474,216 @@ func parseJWT(tokenString, secret string) (jwt.MapClaims, error) {
 	return nil, jwt.ErrSignatureInvalid
 }
 `
-const userModelContent = `user model`
-const userServiceContent = `user service`
-const userValidatorContent = `user validator`
+const userModelContent = `package models
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// User 用戶資料模型（對應 migrations/001_create_users）
+type User struct {
+	bun.BaseModel ` + "`" + `bun:"table:users,alias:u"` + "`" + `
+
+	ID        int64     ` + "`" + `bun:"id,pk,autoincrement" json:"id"` + "`" + `
+	Username  string    ` + "`" + `bun:"username,notnull,unique" json:"username"` + "`" + `
+	Email     string    ` + "`" + `bun:"email,notnull,unique" json:"email"` + "`" + `
+	Password  string    ` + "`" + `bun:"password,notnull" json:"-"` + "`" + `
+	FirstName string    ` + "`" + `bun:"first_name" json:"first_name,omitempty"` + "`" + `
+	LastName  string    ` + "`" + `bun:"last_name" json:"last_name,omitempty"` + "`" + `
+	Avatar    string    ` + "`" + `bun:"avatar" json:"avatar,omitempty"` + "`" + `
+	IsActive  bool      ` + "`" + `bun:"is_active,notnull,default:true" json:"is_active"` + "`" + `
+	CreatedAt time.Time ` + "`" + `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updated_at"` + "`" + `
+	DeletedAt time.Time ` + "`" + `bun:",soft_delete,nullzero" json:"-"` + "`" + `
+}
+
+// Role 角色（對應 migrations/002_create_roles）
+type Role struct {
+	bun.BaseModel ` + "`" + `bun:"table:roles,alias:r"` + "`" + `
+
+	ID          int64     ` + "`" + `bun:"id,pk,autoincrement" json:"id"` + "`" + `
+	Name        string    ` + "`" + `bun:"name,notnull,unique" json:"name"` + "`" + `
+	Description string    ` + "`" + `bun:"description" json:"description,omitempty"` + "`" + `
+	CreatedAt   time.Time ` + "`" + `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"` + "`" + `
+	UpdatedAt   time.Time ` + "`" + `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updated_at"` + "`" + `
+}
+
+// Permission 權限（對應 migrations/002_create_roles）
+type Permission struct {
+	bun.BaseModel ` + "`" + `bun:"table:permissions,alias:p"` + "`" + `
+
+	ID        int64     ` + "`" + `bun:"id,pk,autoincrement" json:"id"` + "`" + `
+	Name      string    ` + "`" + `bun:"name,notnull,unique" json:"name"` + "`" + `
+	Resource  string    ` + "`" + `bun:"resource" json:"resource,omitempty"` + "`" + `
+	Action    string    ` + "`" + `bun:"action" json:"action,omitempty"` + "`" + `
+	CreatedAt time.Time ` + "`" + `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"` + "`" + `
+	UpdatedAt time.Time ` + "`" + `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updated_at"` + "`" + `
+}
+
+// CreateUserRequest 建立用戶的請求（Schema Input）
+type CreateUserRequest struct {
+	Username  string ` + "`" + `json:"username" validate:"required,min=3,max=32,alphanum"` + "`" + `
+	Email     string ` + "`" + `json:"email" validate:"required,email"` + "`" + `
+	Password  string ` + "`" + `json:"password" validate:"required,min=8"` + "`" + `
+	FirstName string ` + "`" + `json:"first_name,omitempty"` + "`" + `
+	LastName  string ` + "`" + `json:"last_name,omitempty"` + "`" + `
+}
+
+// RegisterRequest 註冊請求，欄位與 CreateUserRequest 相同
+type RegisterRequest = CreateUserRequest
+
+// UpdateUserRequest 部分更新用戶的請求，nil 欄位不更新（repository.Patch）
+type UpdateUserRequest struct {
+	Email     *string ` + "`" + `json:"email,omitempty" validate:"omitempty,email"` + "`" + `
+	FirstName *string ` + "`" + `json:"first_name,omitempty"` + "`" + `
+	LastName  *string ` + "`" + `json:"last_name,omitempty"` + "`" + `
+	Avatar    *string ` + "`" + `json:"avatar,omitempty"` + "`" + `
+}
+
+// UserResp 用戶回應（Schema Output）
+type UserResp struct {
+	ID        int64  ` + "`" + `json:"id"` + "`" + `
+	Username  string ` + "`" + `json:"username"` + "`" + `
+	Email     string ` + "`" + `json:"email"` + "`" + `
+	FirstName string ` + "`" + `json:"first_name,omitempty"` + "`" + `
+	LastName  string ` + "`" + `json:"last_name,omitempty"` + "`" + `
+	Avatar    string ` + "`" + `json:"avatar,omitempty"` + "`" + `
+	IsActive  bool   ` + "`" + `json:"is_active"` + "`" + `
+}
+
+// UserListResp 用戶列表回應（Schema Output）
+type UserListResp struct {
+	Data  []UserResp ` + "`" + `json:"data"` + "`" + `
+	Total int        ` + "`" + `json:"total"` + "`" + `
+}
+`
+const userServiceContent = `package services
+
+import (
+	"context"
+
+	"github.com/maoxiaoyue/hypgo/pkg/errors"
+	"github.com/maoxiaoyue/hypgo/pkg/repository"
+	"github.com/uptrace/bun"
+	"golang.org/x/crypto/bcrypt"
+
+	"{{.ProjectName}}/app/models"
+)
+
+// 共用框架的錯誤碼；Repository 回傳的錯誤附帶 details，請以 errors.Is 判斷
+var (
+	ErrNotFound  = errors.ErrNotFound
+	ErrDuplicate = errors.ErrDuplicate
+)
+
+// UserService 用戶服務，內嵌 repository.Repository 提供通用 CRUD 與軟刪除
+type UserService struct {
+	*repository.Repository[models.User]
+}
+
+// NewUserService 創建用戶服務
+func NewUserService(db *bun.DB) *UserService {
+	return &UserService{Repository: repository.New[models.User](db)}
+}
+
+// GetUsers 分頁列出用戶
+func (s *UserService) GetUsers(page, pageSize int) ([]models.User, int, error) {
+	users, total, err := s.List(context.Background(), page, pageSize, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	for i := range users {
+		users[i].Password = ""
+	}
+	return users, total, nil
+}
+
+// GetUserByID 依 ID 取得用戶，不存在時回傳 ErrNotFound
+func (s *UserService) GetUserByID(id int) (*models.User, error) {
+	user, err := s.FindByID(context.Background(), id)
+	if err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// CreateUser 創建用戶，用戶名或 email 重複時回傳 ErrDuplicate
+func (s *UserService) CreateUser(req models.CreateUserRequest) (*models.User, error) {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{
+		Username:  req.Username,
+		Email:     req.Email,
+		Password:  string(hashedPassword),
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		IsActive:  true,
+	}
+	if err := s.Create(context.Background(), user); err != nil {
+		return nil, err
+	}
+
+	user.Password = ""
+	return user, nil
+}
+
+// UpdateUser 部分更新用戶（只更新請求中有提供的欄位）
+func (s *UserService) UpdateUser(id int, req models.UpdateUserRequest) (*models.User, error) {
+	user, err := s.Update(context.Background(), id, repository.Patch(req))
+	if err != nil {
+		return nil, err
+	}
+	user.Password = ""
+	return user, nil
+}
+
+// DeleteUser 刪除用戶（User 有 soft_delete 欄位時為軟刪除）
+func (s *UserService) DeleteUser(id int) error {
+	return s.Delete(context.Background(), id)
+}
+`
+const userValidatorContent = `package validators
+
+import (
+	"github.com/maoxiaoyue/hypgo/pkg/validate"
+)
+
+// 共用的欄位規則，註冊、更新資料等請求共用同一組定義
+var (
+	usernameRules = []validate.Rule{validate.MinLen(3), validate.MaxLen(32), validate.Alphanumeric()}
+	emailRules    = []validate.Rule{validate.MaxLen(255), validate.Email()}
+	passwordRules = []validate.Rule{validate.MaxBytes(72), validate.StrongPassword()} // bcrypt 上限為 72 個位元組（非字元）
+)
+
+// ValidateRegistration 驗證註冊欄位，回傳所有欄位錯誤（validate.Errors）
+func ValidateRegistration(username, email, password string) error {
+	var v validate.Collector
+	v.Check("username", username, usernameRules...)
+	v.Check("email", email, emailRules...)
+	v.Check("password", password, passwordRules...)
+	return v.Err()
+}
+
+// ValidatePasswordChange 驗證變更密碼，新密碼不可與舊密碼相同
+func ValidatePasswordChange(oldPassword, newPassword string) error {
+	var v validate.Collector
+	if v.Check("new_password", newPassword, passwordRules...) && newPassword == oldPassword {
+		v.Add("new_password", "new_password must differ from the current password")
+	}
+	return v.Err()
+}
+
+// ValidateRole 驗證角色名稱
+func ValidateRole(role string) error {
+	return validate.Check("role", role, validate.In("user", "admin"))
+}
+`
 const authServiceContent = `package services
 
 import (
@@ -1710,19 +1988,19 @@ docker-compose-down: ## Stop all services
 
 migrate: ## Run database migrations
 	@echo "$(GREEN)Running migrations...$(NC)"
-	@migrate -path migrations -database "$${DB_DSN}" up
+	@hyp migrate up
 
 migrate-down: ## Rollback migrations
 	@echo "$(YELLOW)Rolling back migrations...$(NC)"
-	@migrate -path migrations -database "$${DB_DSN}" down 1
+	@hyp migrate down 1
 
 migrate-create: ## Create new migration
 	@echo "$(GREEN)Creating migration: $(name)$(NC)"
-	@migrate create -ext sql -dir migrations -seq $(name)
+	@hyp migrate create $(name)
 
 seed: ## Seed the database
 	@echo "$(GREEN)Seeding database...$(NC)"
-	@go run scripts/seed.go
+	@hyp seed --env dev
 
 docs: ## Generate API documentation
 	@echo "$(GREEN)Generating documentation...$(NC)"
@@ -1733,13 +2011,12 @@ install-tools: ## Install development tools
 	@go install github.com/cosmtrek/air@latest
 	@go install github.com/golangci/golangci-lint/cmd/golangci-lint@latest
 	@go install github.com/swaggo/swag/cmd/swag@latest
-	@go install -tags 'postgres' github.com/golang-migrate/migrate/v4/cmd/migrate@latest
+	@go install github.com/maoxiaoyue/hypgo/cmd/hyp@latest
 	@echo "$(GREEN)Tools installed!$(NC)"
 
 cert: ## Generate self-signed certificates
 	@echo "$(GREEN)Generating certificates...$(NC)"
-	@mkdir -p certs
-	@openssl req -x509 -newkey rsa:4096 -keyout certs/server.key -out certs/server.crt -days 365 -nodes -subj "/CN=localhost"
+	@hyp cert --force
 	@echo "$(GREEN)Certificates generated!$(NC)"
 
 .DEFAULT_GOAL := help`
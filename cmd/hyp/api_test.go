@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/server"
+)
+
+// buildScaffold 將範本渲染到暫存模組（以 replace 指向本地 hypgo）並編譯
+func buildScaffold(t *testing.T, files map[string]string) {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("compiles a generated module")
+	}
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	goMod := "module scaffoldcheck\n\ngo 1.24\n\nrequire github.com/maoxiaoyue/hypgo v0.0.0\n\nreplace github.com/maoxiaoyue/hypgo => " + root + "\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := map[string]string{"ProjectName": "scaffoldcheck"}
+	for path, content := range files {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := createTemplateFile(full, content, data); err != nil {
+			t.Fatalf("render %s: %v", path, err)
+		}
+	}
+
+	cmd := exec.Command("go", "build", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("generated packages do not compile: %v\n%s", err, out)
+	}
+}
+
+// TestCacheInitTemplateCompiles 渲染 internal/cache/init.go 範本並以本地 hypgo 編譯
+func TestCacheInitTemplateCompiles(t *testing.T) {
+	buildScaffold(t, map[string]string{
+		"internal/cache/init.go": cacheInitContent,
+	})
+}
+
+// TestUserServiceTemplatesCompile model、service 與 validator 範本彼此引用的型別與欄位一致
+func TestUserServiceTemplatesCompile(t *testing.T) {
+	buildScaffold(t, map[string]string{
+		"internal/database/init.go":        databaseInitContent,
+		"app/models/init.go":               modelsInitContent,
+		"app/models/user.go":               userModelContent,
+		"app/services/user_service.go":     userServiceContent,
+		"app/services/auth_service.go":     authServiceContent,
+		"app/validators/user_validator.go": userValidatorContent,
+	})
+}
+
+// TestHealthControllerTemplateCompiles 健康檢查 controller 範本與 database / cache 範本一致
+func TestHealthControllerTemplateCompiles(t *testing.T) {
+	buildScaffold(t, map[string]string{
+		"internal/database/init.go": databaseInitContent,
+		"internal/cache/init.go":    cacheInitContent,
+		"app/controllers/health.go": healthControllerContent,
+	})
+}
+
+// TestMainTemplateUsesServerMethods main.go 範本呼叫的 srv 方法都存在，OnStart 註冊的步驟由 Start 執行
+func TestMainTemplateUsesServerMethods(t *testing.T) {
+	srvType := reflect.TypeOf(&server.Server{})
+	calls := regexp.MustCompile(`srv\.(\w+)\(`).FindAllStringSubmatch(mainGoContent, -1)
+	seen := make(map[string]bool)
+	for _, call := range calls {
+		seen[call[1]] = true
+		if _, ok := srvType.MethodByName(call[1]); !ok {
+			t.Errorf("main.go template calls undefined method srv.%s", call[1])
+		}
+	}
+	if !seen["OnStart"] || !seen["Start"] {
+		t.Errorf("Expected main.go template to register OnStart hooks and call srv.Start, got %v", seen)
+	}
+}
@@ -0,0 +1,312 @@
+// @chris
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var certCmd = &cobra.Command{
+	Use:   "cert",
+	Short: "Generate or rotate TLS certificates for local development",
+	Long: `Generate a TLS certificate and private key with Go's crypto libraries
+(no openssl required) into the certs/ directory.
+
+By default the certificate is self-signed. With --ca a local CA
+(ca.crt / ca.key) is created on first use and reused afterwards, and the
+certificate is issued by it with both server and client auth usages, so
+the same command produces server and client certificates for mTLS tests.
+
+Existing files are never overwritten without --force; re-running with
+--force rotates the certificate (the CA is kept unless --new-ca is given).
+
+Examples:
+  hyp cert                                   certs/server.crt + server.key for localhost
+  hyp cert --hosts api.local,10.0.0.5        Custom SANs (IPs are detected)
+  hyp cert --key rsa --rsa-bits 4096         RSA instead of ECDSA P-256
+  hyp cert --ca                              Issue server cert from a local CA
+  hyp cert --ca --name client --cn alice     Client cert for mTLS
+  hyp cert --force --days 30                 Rotate the certificate`,
+	Args:         cobra.NoArgs,
+	RunE:         runCert,
+	SilenceUsage: true,
+}
+
+func init() {
+	certCmd.Flags().String("dir", "certs", "Output directory")
+	certCmd.Flags().String("name", "server", "Base file name (<name>.crt / <name>.key)")
+	certCmd.Flags().String("cn", "localhost", "Certificate common name")
+	certCmd.Flags().StringSlice("hosts", []string{"localhost", "127.0.0.1", "::1"}, "Subject alternative names (DNS names or IPs)")
+	certCmd.Flags().Int("days", 365, "Validity in days")
+	certCmd.Flags().String("key", "ecdsa", "Key algorithm: ecdsa (P-256) or rsa")
+	certCmd.Flags().Int("rsa-bits", 2048, "RSA key size")
+	certCmd.Flags().Bool("ca", false, "Issue the certificate from a local CA (created if missing)")
+	certCmd.Flags().Bool("new-ca", false, "With --ca, replace the existing CA")
+	certCmd.Flags().Bool("force", false, "Overwrite existing certificate files")
+	rootCmd.AddCommand(certCmd)
+}
+
+// certOptions hyp cert 的參數
+type certOptions struct {
+	Dir     string
+	Name    string
+	CN      string
+	Hosts   []string
+	Days    int
+	Key     string
+	RSABits int
+	CA      bool
+	NewCA   bool
+	Force   bool
+}
+
+func runCert(cmd *cobra.Command, args []string) error {
+	var opts certOptions
+	opts.Dir, _ = cmd.Flags().GetString("dir")
+	opts.Name, _ = cmd.Flags().GetString("name")
+	opts.CN, _ = cmd.Flags().GetString("cn")
+	opts.Hosts, _ = cmd.Flags().GetStringSlice("hosts")
+	opts.Days, _ = cmd.Flags().GetInt("days")
+	opts.Key, _ = cmd.Flags().GetString("key")
+	opts.RSABits, _ = cmd.Flags().GetInt("rsa-bits")
+	opts.CA, _ = cmd.Flags().GetBool("ca")
+	opts.NewCA, _ = cmd.Flags().GetBool("new-ca")
+	opts.Force, _ = cmd.Flags().GetBool("force")
+
+	files, err := generateCert(opts)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		printStep("wrote %s", f)
+	}
+	fmt.Printf("✅ Certificate ready. Point config.yaml at it:\n\n")
+	fmt.Printf("  server:\n    tls:\n      enabled: true\n      cert_file: %s\n      key_file: %s\n",
+		filepath.Join(opts.Dir, opts.Name+".crt"), filepath.Join(opts.Dir, opts.Name+".key"))
+	return nil
+}
+
+// generateCert 依 opts 產生憑證，回傳寫入的檔案
+func generateCert(opts certOptions) ([]string, error) {
+	if opts.Days <= 0 {
+		return nil, fmt.Errorf("--days must be positive")
+	}
+	if opts.Name == "ca" && opts.CA {
+		return nil, fmt.Errorf("--name ca is reserved for the local CA")
+	}
+	certPath := filepath.Join(opts.Dir, opts.Name+".crt")
+	keyPath := filepath.Join(opts.Dir, opts.Name+".key")
+	if !opts.Force {
+		for _, p := range []string{certPath, keyPath} {
+			if _, err := os.Stat(p); err == nil {
+				return nil, fmt.Errorf("%s already exists (use --force to rotate)", p)
+			}
+		}
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	var written []string
+	tmpl, err := leafTemplate(opts)
+	if err != nil {
+		return nil, err
+	}
+	key, err := newCertKey(opts.Key, opts.RSABits)
+	if err != nil {
+		return nil, err
+	}
+
+	// 自簽：以自身簽署；CA 模式：以 CA 簽署並加入 clientAuth 供 mTLS 使用
+	parent, signer := tmpl, key
+	if opts.CA {
+		caCert, caKey, created, err := loadOrCreateCA(opts)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			written = append(written, filepath.Join(opts.Dir, "ca.crt"), filepath.Join(opts.Dir, "ca.key"))
+		}
+		parent, signer = caCert, caKey
+		tmpl.ExtKeyUsage = append(tmpl.ExtKeyUsage, x509.ExtKeyUsageClientAuth)
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, key.Public(), signer)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeCertPair(certPath, keyPath, der, key); err != nil {
+		return nil, err
+	}
+	return append(written, certPath, keyPath), nil
+}
+
+// leafTemplate 伺服器 / 用戶端憑證範本，hosts 中的 IP 放入 IPAddresses，其餘為 DNSNames
+func leafTemplate(opts certOptions) (*x509.Certificate, error) {
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: opts.CN, Organization: []string{"HypGo Development"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(0, 0, opts.Days),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+	}
+	if strings.EqualFold(opts.Key, "rsa") {
+		tmpl.KeyUsage |= x509.KeyUsageKeyEncipherment
+	}
+	for _, h := range opts.Hosts {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+		if ip := net.ParseIP(h); ip != nil {
+			tmpl.IPAddresses = append(tmpl.IPAddresses, ip)
+		} else {
+			tmpl.DNSNames = append(tmpl.DNSNames, h)
+		}
+	}
+	return tmpl, nil
+}
+
+// loadOrCreateCA 讀取 dir/ca.crt、ca.key；不存在或指定 --new-ca 時建立新的 CA（有效期 10 年）
+func loadOrCreateCA(opts certOptions) (*x509.Certificate, crypto.Signer, bool, error) {
+	certPath := filepath.Join(opts.Dir, "ca.crt")
+	keyPath := filepath.Join(opts.Dir, "ca.key")
+
+	if !opts.NewCA {
+		cert, key, err := readCA(certPath, keyPath)
+		if err == nil {
+			return cert, key, false, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, nil, false, err
+		}
+	}
+
+	key, err := newCertKey(opts.Key, opts.RSABits)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	serial, err := randomSerial()
+	if err != nil {
+		return nil, nil, false, err
+	}
+	now := time.Now()
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "HypGo Local CA", Organization: []string{"HypGo Development"}},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.AddDate(10, 0, 0),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		MaxPathLenZero:        true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, key.Public(), key)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	if err := writeCertPair(certPath, keyPath, der, key); err != nil {
+		return nil, nil, false, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, nil, false, err
+	}
+	return cert, key, true, nil
+}
+
+// readCA 讀取既有的 CA 憑證與私鑰
+func readCA(certPath, keyPath string) (*x509.Certificate, crypto.Signer, error) {
+	certPEM, err := os.ReadFile(certPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, nil, fmt.Errorf("%s: no CERTIFICATE block", certPath)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", certPath, err)
+	}
+	if !cert.IsCA {
+		return nil, nil, fmt.Errorf("%s is not a CA certificate", certPath)
+	}
+
+	block, _ = pem.Decode(keyPEM)
+	if block == nil || block.Type != "PRIVATE KEY" {
+		return nil, nil, fmt.Errorf("%s: no PRIVATE KEY block", keyPath)
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", keyPath, err)
+	}
+	key, ok := parsed.(crypto.Signer)
+	if !ok {
+		return nil, nil, fmt.Errorf("%s: unsupported key type %T", keyPath, parsed)
+	}
+	return cert, key, nil
+}
+
+// newCertKey 產生 ECDSA P-256 或 RSA 私鑰
+func newCertKey(algorithm string, rsaBits int) (crypto.Signer, error) {
+	switch strings.ToLower(algorithm) {
+	case "ecdsa", "ec", "":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	case "rsa":
+		if rsaBits < 2048 {
+			return nil, fmt.Errorf("--rsa-bits must be at least 2048")
+		}
+		return rsa.GenerateKey(rand.Reader, rsaBits)
+	default:
+		return nil, fmt.Errorf("unsupported key algorithm %q (ecdsa or rsa)", algorithm)
+	}
+}
+
+func randomSerial() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// writeCertPair 寫出 PEM 憑證與 PKCS#8 私鑰（私鑰權限 0600）
+func writeCertPair(certPath, keyPath string, der []byte, key crypto.Signer) error {
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o644); err != nil {
+		return err
+	}
+	// os.WriteFile 不會變更既有檔案的權限，--force 時先移除舊私鑰再以 0600 建立
+	if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}), 0o600)
+}
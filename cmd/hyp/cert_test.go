@@ -0,0 +1,89 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	dir := t.TempDir()
+	opts := certOptions{Dir: dir, Name: "server", CN: "localhost", Hosts: []string{"localhost", "127.0.0.1"}, Days: 30, Key: "ecdsa", RSABits: 2048}
+
+	if _, err := generateCert(opts); err != nil {
+		t.Fatal(err)
+	}
+	pair, err := tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, _ := x509.ParseCertificate(pair.Certificate[0])
+	if _, ok := cert.PublicKey.(*ecdsa.PublicKey); !ok {
+		t.Errorf("expected ECDSA key, got %T", cert.PublicKey)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Error(err)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "server.key")); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected key mode 0600, got %v", info.Mode().Perm())
+	}
+
+	if _, err := generateCert(opts); err == nil {
+		t.Error("expected existing files to be kept without --force")
+	}
+	// --force 時既有私鑰的寬鬆權限不會沿用
+	if err := os.Chmod(filepath.Join(dir, "server.key"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	opts.Force, opts.Key = true, "rsa"
+	if _, err := generateCert(opts); err != nil {
+		t.Fatal(err)
+	}
+	pair, _ = tls.LoadX509KeyPair(filepath.Join(dir, "server.crt"), filepath.Join(dir, "server.key"))
+	if _, ok := pair.PrivateKey.(*rsa.PrivateKey); !ok {
+		t.Errorf("expected rotated RSA key, got %T", pair.PrivateKey)
+	}
+	if info, _ := os.Stat(filepath.Join(dir, "server.key")); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected rotated key mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestGenerateCASignedCerts(t *testing.T) {
+	dir := t.TempDir()
+	opts := certOptions{Dir: dir, Name: "server", CN: "localhost", Hosts: []string{"localhost"}, Days: 30, Key: "ecdsa", CA: true}
+
+	files, err := generateCert(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 4 {
+		t.Errorf("expected CA and leaf files, got %v", files)
+	}
+	caPEM, _ := os.ReadFile(filepath.Join(dir, "ca.crt"))
+
+	// 第二張憑證沿用同一個 CA
+	opts.Name, opts.CN = "client", "alice"
+	if files, err := generateCert(opts); err != nil || len(files) != 2 {
+		t.Fatalf("expected only leaf files: %v %v", files, err)
+	}
+	if again, _ := os.ReadFile(filepath.Join(dir, "ca.crt")); string(again) != string(caPEM) {
+		t.Error("expected existing CA to be reused")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(caPEM)
+	for name, usage := range map[string]x509.ExtKeyUsage{"server": x509.ExtKeyUsageServerAuth, "client": x509.ExtKeyUsageClientAuth} {
+		pair, err := tls.LoadX509KeyPair(filepath.Join(dir, name+".crt"), filepath.Join(dir, name+".key"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cert, _ := x509.ParseCertificate(pair.Certificate[0])
+		if _, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{usage}}); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunConfigInvalidReturnsError(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(file, []byte("logger:\n  format: xml\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	configCmd.SetOut(&out)
+	defer configCmd.SetOut(nil)
+	configCmd.Flags().Set("file", file)
+	defer configCmd.Flags().Set("file", "config/config.yaml")
+
+	err := runConfig(configCmd, nil)
+	if err == nil || !strings.Contains(err.Error(), "logger.format") {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+	if !strings.Contains(out.String(), "format: xml") {
+		t.Errorf("config should still be printed before the error, got %q", out.String())
+	}
+}
@@ -18,7 +18,7 @@ var generateCmd = &cobra.Command{
 Available types:
   controller    Controller (handler) + Router (Schema routes) + Middleware
   model         Bun ORM model + Request/Response structs
-  service       Service layer with Error Catalog
+  service       Service layer embedding the generic repository
   command       CLI subcommand (Cobra) for CLI projects
   view          Desktop GUI view (Fyne) for desktop projects
   proto         Protobuf service definition + gRPC server for gRPC projects
@@ -119,10 +119,11 @@ func generateModel(name string) error {
 func generateService(name, moduleName string) error {
 	lowerName := strings.ToLower(name)
 
-	if err := scaffold.GenerateService("app/services", name); err != nil {
+	if err := scaffold.GenerateService("app/services", name, moduleName); err != nil {
 		return err
 	}
 	fmt.Printf("✅ Generated: app/services/%s_service.go\n", lowerName)
+	fmt.Printf("   Embeds repository.Repository[models.%s] — run: hyp generate model %s\n", strings.ToUpper(name[:1])+name[1:], lowerName)
 	return nil
 }
 
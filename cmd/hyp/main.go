@@ -30,10 +30,14 @@ Project Management:
   run            Start with hot reload + AutoSync (.hyp/context.yaml)
   restart        Zero-downtime hot restart (Unix SIGUSR2)
   generate       Generate controller / model / service with Schema + Error Catalog
+  config         Print the effective config with secrets masked
+  cert           Generate / rotate TLS certificates (self-signed or local CA)
 
 Database:
   migrate diff      Generate SQL migration from model struct changes
   migrate snapshot  Save current schema as baseline
+  migrate up/down   Apply or roll back migrations/*.sql (version / force / create)
+  seed              Run pending seeds from seeds/ (--env gated)
 
 Deployment:
   docker         Build Docker image
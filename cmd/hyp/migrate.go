@@ -2,10 +2,18 @@
 package main
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	"github.com/maoxiaoyue/hypgo/pkg/config"
 	"github.com/maoxiaoyue/hypgo/pkg/migrate"
 	"github.com/spf13/cobra"
 )
@@ -28,13 +36,27 @@ Workflow:
 Subcommands:
   diff       Compare current models with snapshot, generate SQL migrations
   snapshot   Save current model schema as a baseline snapshot
+  create     Create an empty numbered up/down migration pair
+  up         Apply all pending migrations
+  down       Roll back the last N migrations (default 1)
+  version    Print the current migration version
+  force      Set the version without running SQL (after a failed migration)
 
 The snapshot is stored at .hyp/schema_snapshot.json by default.
 
+up/down/version/force connect using database.driver and database.dsn from
+config/config.yaml and track the applied version in the schema_migrations
+table (compatible with golang-migrate). Supported drivers: mysql/tidb,
+postgres and sqlite (sqlite requires a cgo-enabled build).
+
 Examples:
   hyp migrate diff                    Generate PostgreSQL migration
   hyp migrate diff --dialect mysql    Generate MySQL migration
-  hyp migrate snapshot                Save current schema snapshot`,
+  hyp migrate snapshot                Save current schema snapshot
+  hyp migrate create add_user_phone   Create 003_add_user_phone.{up,down}.sql
+  hyp migrate up                      Apply pending migrations
+  hyp migrate down 2                  Roll back two migrations
+  hyp migrate force 2                 Mark version 2 as applied and clean`,
 }
 
 var migrateDiffCmd = &cobra.Command{
@@ -90,6 +112,62 @@ Examples:
 	RunE: runMigrateSnapshot,
 }
 
+var migrateCreateCmd = &cobra.Command{
+	Use:   "create NAME",
+	Short: "Create an empty up/down migration pair",
+	Long: `Create NNN_NAME.up.sql and NNN_NAME.down.sql in the migrations directory,
+using the next version number after the highest existing one.
+
+Examples:
+  hyp migrate create add_user_phone
+  hyp migrate create add_index -p db/migrations`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMigrateCreate,
+}
+
+var migrateUpCmd = &cobra.Command{
+	Use:   "up",
+	Short: "Apply all pending migrations",
+	Long: `Apply every *.up.sql migration newer than the current version, in order.
+
+A failed migration leaves the database marked dirty at that version; fix the
+database by hand, then run "hyp migrate force VERSION" before retrying.
+
+MySQL DSNs get multiStatements=true added automatically so that a migration
+file may contain several statements.`,
+	Args: cobra.NoArgs,
+	RunE: runMigrateUp,
+}
+
+var migrateDownCmd = &cobra.Command{
+	Use:   "down [N]",
+	Short: "Roll back the last N migrations (default 1)",
+	Long: `Run the *.down.sql of the last N applied migrations, newest first.
+
+Examples:
+  hyp migrate down          Roll back the latest migration
+  hyp migrate down 3        Roll back three migrations
+  hyp migrate down --all    Roll back everything`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runMigrateDown,
+}
+
+var migrateVersionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Print the current migration version",
+	Args:  cobra.NoArgs,
+	RunE:  runMigrateVersion,
+}
+
+var migrateForceCmd = &cobra.Command{
+	Use:   "force VERSION",
+	Short: "Set the migration version without running SQL",
+	Long: `Record VERSION as the current version and clear the dirty flag without
+executing any migration. Use -1 to mark the database as having no migrations.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runMigrateForce,
+}
+
 func init() {
 	migrateDiffCmd.Flags().StringP("dialect", "d", "postgres", "SQL dialect: postgres or mysql")
 	migrateDiffCmd.Flags().StringP("output", "o", "migrations/", "Output directory for migration files")
@@ -97,8 +175,21 @@ func init() {
 
 	migrateSnapshotCmd.Flags().StringP("snapshot", "s", ".hyp/schema_snapshot.json", "Snapshot file path")
 
+	migrateCreateCmd.Flags().StringP("path", "p", "migrations", "Migrations directory")
+	for _, cmd := range []*cobra.Command{migrateUpCmd, migrateDownCmd, migrateVersionCmd, migrateForceCmd} {
+		cmd.Flags().StringP("path", "p", "migrations", "Migrations directory")
+		addDatabaseFlags(cmd)
+		cmd.SilenceUsage = true
+	}
+	migrateDownCmd.Flags().Bool("all", false, "Roll back all migrations")
+
 	migrateCmd.AddCommand(migrateDiffCmd)
 	migrateCmd.AddCommand(migrateSnapshotCmd)
+	migrateCmd.AddCommand(migrateCreateCmd)
+	migrateCmd.AddCommand(migrateUpCmd)
+	migrateCmd.AddCommand(migrateDownCmd)
+	migrateCmd.AddCommand(migrateVersionCmd)
+	migrateCmd.AddCommand(migrateForceCmd)
 }
 
 func runMigrateDiff(cmd *cobra.Command, args []string) error {
@@ -186,3 +277,181 @@ func runMigrateSnapshot(cmd *cobra.Command, args []string) error {
 	fmt.Printf("Snapshot saved: %s (%d tables)\n", snapshotPath, len(tables))
 	return nil
 }
+
+func runMigrateCreate(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("path")
+	up, down, err := migrate.Create(dir, args[0])
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Created:\n  UP:   %s\n  DOWN: %s\n", up, down)
+	return nil
+}
+
+func runMigrateUp(cmd *cobra.Command, args []string) error {
+	m, closeDB, err := openMigrator(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	n, err := m.Up(context.Background())
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		fmt.Println("No pending migrations.")
+		return nil
+	}
+	fmt.Printf("✅ Applied %d migration(s)\n", n)
+	return nil
+}
+
+func runMigrateDown(cmd *cobra.Command, args []string) error {
+	steps := 1
+	if all, _ := cmd.Flags().GetBool("all"); all {
+		steps = int(^uint(0) >> 1)
+	} else if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
+		if err != nil || n <= 0 {
+			return fmt.Errorf("invalid step count %q", args[0])
+		}
+		steps = n
+	}
+
+	m, closeDB, err := openMigrator(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	n, err := m.Down(context.Background(), steps)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✅ Rolled back %d migration(s)\n", n)
+	return nil
+}
+
+func runMigrateVersion(cmd *cobra.Command, args []string) error {
+	m, closeDB, err := openMigrator(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	version, dirty, err := m.Version(context.Background())
+	if err != nil {
+		return err
+	}
+	switch {
+	case version == migrate.NilVersion:
+		fmt.Println("No migrations applied.")
+	case dirty:
+		fmt.Printf("%d (dirty)\n", version)
+	default:
+		fmt.Println(version)
+	}
+	return nil
+}
+
+func runMigrateForce(cmd *cobra.Command, args []string) error {
+	version, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid version %q", args[0])
+	}
+
+	m, closeDB, err := openMigrator(cmd)
+	if err != nil {
+		return err
+	}
+	defer closeDB()
+
+	if err := m.Force(context.Background(), version); err != nil {
+		return err
+	}
+	fmt.Printf("✅ Version forced to %d\n", version)
+	return nil
+}
+
+// openMigrator 依 --driver/--dsn 或設定檔連線並載入 migrations 目錄
+func openMigrator(cmd *cobra.Command) (*migrate.Migrator, func(), error) {
+	dir, _ := cmd.Flags().GetString("path")
+	migrations, err := migrate.LoadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, dialect, err := openDatabase(cmd)
+	if err != nil {
+		return nil, nil, err
+	}
+	m := migrate.New(db, dialect, migrations, migrate.WithLogger(printStep))
+	return m, func() { db.Close() }, nil
+}
+
+// addDatabaseFlags 連線相關旗標，供 migrate 與 seed 共用
+func addDatabaseFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("file", "f", "config/config.yaml", "Config file providing database.driver and database.dsn")
+	cmd.Flags().String("driver", "", "Database driver, overrides the config file")
+	cmd.Flags().String("dsn", "", "Database DSN, overrides the config file")
+}
+
+// openDatabase 依 --driver/--dsn 或設定檔的 database 區段開啟連線
+func openDatabase(cmd *cobra.Command) (*sql.DB, migrate.Dialect, error) {
+	file, _ := cmd.Flags().GetString("file")
+	driver, _ := cmd.Flags().GetString("driver")
+	dsn, _ := cmd.Flags().GetString("dsn")
+
+	if driver == "" || dsn == "" {
+		cfg, err := config.ReadConfig(file)
+		if err != nil {
+			return nil, "", fmt.Errorf("%w (or pass --driver and --dsn)", err)
+		}
+		if driver == "" {
+			driver = cfg.Database.Driver
+		}
+		if dsn == "" {
+			dsn = cfg.Database.DSN
+		}
+	}
+	if dsn == "" {
+		return nil, "", errors.New("database.dsn is empty")
+	}
+
+	dialect, err := migrate.DialectFor(driver)
+	if err != nil {
+		return nil, "", err
+	}
+	driverName := string(dialect)
+	switch dialect {
+	case migrate.DialectMySQL:
+		dsn = withMultiStatements(dsn)
+	case migrate.DialectSQLite:
+		if sqliteDriver == "" {
+			return nil, "", errors.New("sqlite requires hyp built with CGO_ENABLED=1")
+		}
+		driverName = sqliteDriver
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, "", err
+	}
+	return db, dialect, nil
+}
+
+func printStep(format string, args ...interface{}) {
+	fmt.Printf("  → "+format+"\n", args...)
+}
+
+// withMultiStatements 讓 go-sql-driver/mysql 允許單次 Exec 執行多條語句
+func withMultiStatements(dsn string) string {
+	if strings.Contains(dsn, "multiStatements=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&multiStatements=true"
+	}
+	return dsn + "?multiStatements=true"
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrateUpSQLite(t *testing.T) {
+	if sqliteDriver == "" {
+		t.Skip("sqlite driver requires cgo")
+	}
+	dir := t.TempDir()
+	migrations := filepath.Join(dir, "migrations")
+	if err := os.MkdirAll(migrations, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"001_create_users.up.sql":   "CREATE TABLE users (id INTEGER PRIMARY KEY, name TEXT);",
+		"001_create_users.down.sql": "DROP TABLE users;",
+	}
+	for name, body := range files {
+		if err := os.WriteFile(filepath.Join(migrations, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dsn := filepath.Join(dir, "app.db")
+
+	flags := migrateUpCmd.Flags()
+	for name, value := range map[string]string{"path": migrations, "driver": "sqlite", "dsn": dsn} {
+		flags.Set(name, value)
+	}
+	t.Cleanup(func() {
+		flags.Set("path", "migrations")
+		flags.Set("driver", "")
+		flags.Set("dsn", "")
+	})
+	if err := runMigrateUp(migrateUpCmd, nil); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+
+	db, err := sql.Open(sqliteDriver, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec("INSERT INTO users (name) VALUES ('bob')"); err != nil {
+		t.Errorf("expected users table after migrate up: %v", err)
+	}
+}
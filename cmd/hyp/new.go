@@ -384,8 +384,8 @@ func createNewConfigFile(projectName string) error {
 server:
   protocol: http2  # http1, http2, http3
   addr: :8080
-  read_timeout: 30
-  write_timeout: 30
+  read_timeout: 30s
+  write_timeout: 30s
   idle_timeout: 120
   keep_alive: 30
   max_handlers: 1000
@@ -409,7 +409,8 @@ database:
 
 logger:
   level: debug  # debug, info, notice, warning, emergency
-  output: logs/app.log  # stdout or file path
+  output: file  # stdout, file, both
+  file: logs/app.log
   colors: true
   rotation:
     max_size: 100MB
@@ -472,6 +473,9 @@ func createMainFile(projectName string) error {
 		"\tsrv := server.New(cfg, appLog)\n\n" +
 		"\t// 設定所有路由與中間件（定義於 app/routers/router.go）\n" +
 		"\trouters.Setup(srv.Router())\n\n" +
+		"\t// 資料庫、快取等啟動步驟以 srv.OnStart 註冊，例如：\n" +
+		"\t//\tsrv.OnStart(\"database\", func(ctx context.Context) error { ... })\n" +
+		"\t// srv.Start 會先依序執行這些步驟，任一步驟失敗即回傳錯誤而不開啟監聽\n\n" +
 		"\t// 啟動服務器\n" +
 		"\tgo func() {\n" +
 		"\t\tappLog.Infof(\"Starting HypGo server on %s\", cfg.Server.Addr)\n" +
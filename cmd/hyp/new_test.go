@@ -0,0 +1,28 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/config"
+)
+
+// TestNewConfigTemplateLoads hyp new 產生的 config.yaml 能通過載入與驗證
+func TestNewConfigTemplateLoads(t *testing.T) {
+	project := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(project, "config"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := createNewConfigFile(project); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := config.LoadConfig(filepath.Join(project, "config", "config.yaml"))
+	if err != nil {
+		t.Fatalf("generated config does not load: %v", err)
+	}
+	if got := cfg.Logger.GetFilename(); got != "logs/app.log" {
+		t.Errorf("logger file = %q, want logs/app.log", got)
+	}
+}
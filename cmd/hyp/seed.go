@@ -0,0 +1,77 @@
+// @chris
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/maoxiaoyue/hypgo/pkg/config"
+	"github.com/maoxiaoyue/hypgo/pkg/migrate"
+	"github.com/spf13/cobra"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Run pending seed SQL from seeds/",
+	Long: `Run the SQL seed files in seeds/ that have not run yet, in file name order.
+Each seed runs in a transaction together with its entry in the schema_seeds
+table, so running the command again only executes new seeds.
+
+File names select the environments a seed runs in:
+  001_roles.sql           every environment
+  100_demo_users.dev.sql  only with --env dev
+
+--env defaults to the environment in .hyp/config.yaml (local when unset).
+
+Seeds written in Go are registered with migrate.GlobalSeeds().Register and
+run by calling migrate.Seed from the application; they share the same
+schema_seeds bookkeeping and ordering.
+
+Examples:
+  hyp seed                    Seed for the current environment
+  hyp seed --env prod         Only seeds without a suffix or with .prod
+  hyp seed -p db/seeds        Use another seeds directory`,
+	Args:         cobra.NoArgs,
+	RunE:         runSeed,
+	SilenceUsage: true,
+}
+
+func init() {
+	seedCmd.Flags().StringP("path", "p", "seeds", "Seeds directory")
+	seedCmd.Flags().String("env", "", "Environment used to select seeds (default: .hyp/config.yaml environment)")
+	addDatabaseFlags(seedCmd)
+	rootCmd.AddCommand(seedCmd)
+}
+
+func runSeed(cmd *cobra.Command, args []string) error {
+	dir, _ := cmd.Flags().GetString("path")
+	env, _ := cmd.Flags().GetString("env")
+	if env == "" {
+		hypCfg, err := config.LoadHypConfig(".hyp/config.yaml")
+		if err != nil {
+			return err
+		}
+		env = hypCfg.Environment
+	}
+
+	db, dialect, err := openDatabase(cmd)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	ran, err := migrate.Seed(context.Background(), db, dialect, migrate.SeedOptions{
+		Env:  env,
+		Dir:  dir,
+		Logf: printStep,
+	})
+	if err != nil {
+		return err
+	}
+	if len(ran) == 0 {
+		fmt.Printf("No pending seeds for environment %q.\n", env)
+		return nil
+	}
+	fmt.Printf("✅ Ran %d seed(s) for environment %q\n", len(ran), env)
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build cgo
+
+// @chris
+package main
+
+// 內建 SQLite 驅動（mattn/go-sqlite3，需要 cgo），供 hyp migrate 連線 sqlite 資料庫
+import _ "github.com/mattn/go-sqlite3"
+
+// sqliteDriver database/sql 的 SQLite 驅動名稱
+const sqliteDriver = "sqlite3"
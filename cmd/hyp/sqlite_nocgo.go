@@ -0,0 +1,7 @@
+//go:build !cgo
+
+// @chris
+package main
+
+// sqliteDriver 以 CGO_ENABLED=0 建置時不含 SQLite 驅動
+const sqliteDriver = ""
@@ -0,0 +1,185 @@
+// Package cache 提供建構於 Redis 之上的協調工具
+// Lock 為單一 Redis 節點上的分散式鎖（SET NX PX 取得、比對 token 的 Lua 腳本釋放與續期），
+// 用於多實例部署時的互斥與領導者選舉，例如確保週期工作只在一個實例執行。
+//
+// @chris
+package cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseTimeout 釋放鎖的期限；逾時未釋放的鎖會在 TTL 後自動過期
+const releaseTimeout = 3 * time.Second
+
+// releaseScript 只刪除自己持有的鎖，避免鎖過期後誤刪其他持有者的鎖
+var releaseScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+// renewScript 只延長自己持有的鎖
+var renewScript = redis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// ===== 分散式鎖 =====
+
+// LockOption 鎖選項
+type LockOption func(*lockConfig)
+
+type lockConfig struct {
+	renew  bool
+	onLost func()
+}
+
+// AutoRenew 持有期間每 ttl/3 自動續期，適合執行時間無法預估的長任務
+// 續期發現鎖已不屬於自己，或超過 ttl 都無法續期時停止續期並呼叫 onLost（可為 nil），
+// 此時其他實例可能已取得鎖，任務應盡快中止
+func AutoRenew(onLost func()) LockOption {
+	return func(c *lockConfig) {
+		c.renew = true
+		c.onLost = onLost
+	}
+}
+
+// Lock 嘗試取得 key 的鎖，不等待：已被其他持有者佔用時回傳 acquired 為 false
+// 鎖在 ttl 後自動過期，避免持有者異常終止時永久鎖住；unlock 只釋放自己持有的鎖，可重複呼叫。
+// 未取得鎖或發生錯誤時 unlock 為不做事的函數，可直接 defer
+//
+// 僅使用單一 Redis 節點（或主從），主節點故障切換時可能短暫出現兩個持有者；
+// 需要嚴格互斥的操作仍應在資料層加上版本或唯一約束
+//
+// EX：
+//
+//	unlock, ok, err := cache.Lock(ctx, db.Redis(), "report:monthly", time.Minute, cache.AutoRenew(cancel))
+//	if err != nil || !ok {
+//	    return err // 其他實例正在執行
+//	}
+//	defer unlock()
+func Lock(ctx context.Context, client redis.UniversalClient, key string, ttl time.Duration, opts ...LockOption) (unlock func(), acquired bool, err error) {
+	noop := func() {}
+	if ttl < time.Millisecond {
+		return noop, false, fmt.Errorf("cache: lock %s: ttl must be at least 1ms", key)
+	}
+	var cfg lockConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	token := newToken()
+	ok, err := client.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return noop, false, fmt.Errorf("cache: lock %s: %w", key, err)
+	}
+	if !ok {
+		return noop, false, nil
+	}
+
+	l := &heldLock{
+		client: client,
+		key:    key,
+		token:  token,
+		ctx:    context.WithoutCancel(ctx),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if cfg.renew {
+		go l.renew(ttl, cfg.onLost)
+	} else {
+		close(l.done)
+	}
+	return l.unlock, true, nil
+}
+
+// heldLock 已取得的鎖
+type heldLock struct {
+	client redis.UniversalClient
+	key    string
+	token  string
+	ctx    context.Context
+	once   sync.Once
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+func (l *heldLock) unlock() {
+	l.once.Do(func() {
+		close(l.stop)
+		<-l.done
+		ctx, cancel := context.WithTimeout(l.ctx, releaseTimeout)
+		defer cancel()
+		releaseScript.Run(ctx, l.client, []string{l.key}, l.token)
+	})
+}
+
+// renew 定期續期直到 unlock 或鎖遺失
+func (l *heldLock) renew(ttl time.Duration, onLost func()) {
+	defer close(l.done)
+	ticker := time.NewTicker(ttl / 3)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-ticker.C:
+		}
+
+		ctx, cancel := context.WithTimeout(l.ctx, ttl/3)
+		n, err := renewScript.Run(ctx, l.client, []string{l.key}, l.token, ttl.Milliseconds()).Int64()
+		cancel()
+		switch {
+		case err == nil && n == 1:
+			renewed = time.Now()
+			continue
+		case err == nil || errors.Is(err, redis.Nil) || time.Since(renewed) >= ttl:
+			// 鎖已被釋放、過期或由他人持有，或長時間無法續期
+			if onLost != nil {
+				onLost()
+			}
+			return
+		}
+	}
+}
+
+// ===== Locker =====
+
+// Locker 綁定 Redis client 與選項的鎖，Lock 簽名與 jobs.Locker 相同，可設定為 jobs.Config.Locker
+type Locker struct {
+	client redis.UniversalClient
+	opts   []LockOption
+}
+
+// NewLocker 創建 Locker，opts 套用到每次 Lock
+//
+// EX：
+//
+//	sched := jobs.New(jobs.Config{Locker: cache.NewLocker(db.Redis())})
+func NewLocker(client redis.UniversalClient, opts ...LockOption) *Locker {
+	return &Locker{client: client, opts: opts}
+}
+
+// Lock 見套件函數 Lock
+func (l *Locker) Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), acquired bool, err error) {
+	return Lock(ctx, l.client, key, ttl, l.opts...)
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
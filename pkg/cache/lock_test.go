@@ -0,0 +1,207 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// fakeRedis 以 hook 攔截指令的記憶體 Redis，只實作鎖用到的 SET NX 與兩個腳本
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]fakeEntry
+	down atomic.Bool
+}
+
+type fakeEntry struct {
+	val string
+	exp time.Time
+}
+
+func newFakeClient(t *testing.T) (*redis.Client, *fakeRedis) {
+	f := &fakeRedis{data: make(map[string]fakeEntry)}
+	client := redis.NewClient(&redis.Options{Addr: "fake:6379"})
+	client.AddHook(f)
+	t.Cleanup(func() { client.Close() })
+	return client, f
+}
+
+func (f *fakeRedis) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (f *fakeRedis) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return next
+}
+
+func (f *fakeRedis) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if f.down.Load() {
+			cmd.SetErr(errors.New("connection refused"))
+			return cmd.Err()
+		}
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		f.process(cmd)
+		return cmd.Err()
+	}
+}
+
+func (f *fakeRedis) get(key string) (string, bool) {
+	e, ok := f.data[key]
+	if !ok || time.Now().After(e.exp) {
+		delete(f.data, key)
+		return "", false
+	}
+	return e.val, true
+}
+
+func (f *fakeRedis) process(cmd redis.Cmder) {
+	args := make([]string, len(cmd.Args()))
+	for i, a := range cmd.Args() {
+		args[i] = strings.ToLower(fmtArg(a))
+	}
+	switch {
+	case args[0] == "set" && len(args) == 6 && args[5] == "nx":
+		ms, _ := strconv.Atoi(args[4])
+		if args[3] == "ex" {
+			ms *= 1000
+		}
+		if _, held := f.get(args[1]); held {
+			cmd.(*redis.BoolCmd).SetVal(false)
+			return
+		}
+		f.data[args[1]] = fakeEntry{val: fmtArg(cmd.Args()[2]), exp: time.Now().Add(time.Duration(ms) * time.Millisecond)}
+		cmd.(*redis.BoolCmd).SetVal(true)
+	case args[0] == "evalsha" || args[0] == "eval":
+		key, token := fmtArg(cmd.Args()[3]), fmtArg(cmd.Args()[4])
+		c := cmd.(*redis.Cmd)
+		if val, ok := f.get(key); !ok || val != token {
+			c.SetVal(int64(0))
+			return
+		}
+		if args[1] == releaseScript.Hash() || strings.Contains(args[1], "'del'") {
+			delete(f.data, key)
+		} else {
+			ms, _ := strconv.Atoi(fmtArg(cmd.Args()[5]))
+			f.data[key] = fakeEntry{val: token, exp: time.Now().Add(time.Duration(ms) * time.Millisecond)}
+		}
+		c.SetVal(int64(1))
+	default:
+		cmd.SetErr(errors.New("ERR unsupported command " + args[0]))
+	}
+}
+
+func fmtArg(a interface{}) string {
+	switch v := a.(type) {
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	}
+	return ""
+}
+
+func TestLockContention(t *testing.T) {
+	client, _ := newFakeClient(t)
+	ctx := context.Background()
+
+	unlock, ok, err := Lock(ctx, client, "lock:report", time.Minute)
+	if err != nil || !ok {
+		t.Fatalf("Expected first Lock to succeed, got ok=%v err=%v", ok, err)
+	}
+	unlock2, ok, err := Lock(ctx, client, "lock:report", time.Minute)
+	if err != nil || ok {
+		t.Fatalf("Expected contended Lock to fail without error, got ok=%v err=%v", ok, err)
+	}
+	unlock2() // 未取得時為 noop
+
+	// 只有一個競爭者能取得
+	unlock()
+	var winners atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, _ := Lock(ctx, client, "lock:report", time.Minute); ok {
+				winners.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := winners.Load(); n != 1 {
+		t.Errorf("Expected exactly one winner, got %d", n)
+	}
+}
+
+func TestLockExpiryAndSafeRelease(t *testing.T) {
+	client, f := newFakeClient(t)
+	ctx := context.Background()
+
+	unlockA, ok, _ := Lock(ctx, client, "lock:job", 30*time.Millisecond)
+	if !ok {
+		t.Fatal("Expected to acquire lock")
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	_, ok, _ = Lock(ctx, client, "lock:job", time.Minute)
+	if !ok {
+		t.Fatal("Expected expired lock to be acquirable")
+	}
+	// A 的鎖已過期，釋放時不得刪除 B 的鎖
+	unlockA()
+	if _, held := f.get("lock:job"); !held {
+		t.Error("Expired holder must not release another holder's lock")
+	}
+
+	if _, _, err := Lock(ctx, client, "lock:job", 0); err == nil {
+		t.Error("Expected error for zero ttl")
+	}
+	f.down.Store(true)
+	if _, ok, err := Lock(ctx, client, "lock:other", time.Minute); err == nil || ok {
+		t.Errorf("Expected Redis error to be returned, got ok=%v err=%v", ok, err)
+	}
+}
+
+func TestLockAutoRenew(t *testing.T) {
+	client, f := newFakeClient(t)
+	ctx := context.Background()
+
+	unlock, ok, _ := Lock(ctx, client, "lock:long", 30*time.Millisecond, AutoRenew(nil))
+	if !ok {
+		t.Fatal("Expected to acquire lock")
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, ok, _ := Lock(ctx, client, "lock:long", time.Minute); ok {
+		t.Fatal("Expected renewed lock to still be held")
+	}
+	unlock()
+	if _, held := f.get("lock:long"); held {
+		t.Error("Expected unlock to release the renewed lock")
+	}
+
+	// 鎖被他人取走時停止續期並通知
+	lost := make(chan struct{})
+	locker := NewLocker(client, AutoRenew(func() { close(lost) }))
+	unlock, ok, _ = locker.Lock(ctx, "lock:stolen", 30*time.Millisecond)
+	if !ok {
+		t.Fatal("Expected to acquire lock")
+	}
+	defer unlock()
+	f.mu.Lock()
+	f.data["lock:stolen"] = fakeEntry{val: "someone-else", exp: time.Now().Add(time.Minute)}
+	f.mu.Unlock()
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("Expected onLost after the lock was taken over")
+	}
+}
@@ -0,0 +1,129 @@
+// @chris
+package config
+
+import (
+	"strings"
+	"time"
+)
+
+// HTTPAPIConfig 應用 API 層配置（config.yaml 的 api 區段）
+// 與 LLM 的 APIConfig 無關，後者描述遠端模型 API
+type HTTPAPIConfig struct {
+	Version     string `mapstructure:"version" yaml:"version"` // 路由版本前綴，如 "v1"
+	DocsEnabled bool   `mapstructure:"docs_enabled" yaml:"docs_enabled"`
+	DocsPath    string `mapstructure:"docs_path" yaml:"docs_path"`
+
+	RateLimit RateLimitConfig `mapstructure:"rate_limit" yaml:"rate_limit"`
+	CORS      CORSConfig      `mapstructure:"cors" yaml:"cors"`
+	JWT       JWTConfig       `mapstructure:"jwt" yaml:"jwt"`
+}
+
+// RateLimitConfig 限流配置（令牌桶：每分鐘補充 RequestsPerMinute，瞬間最多 Burst）
+type RateLimitConfig struct {
+	Enabled           bool `mapstructure:"enabled" yaml:"enabled"`
+	RequestsPerMinute int  `mapstructure:"requests_per_minute" yaml:"requests_per_minute"`
+	Burst             int  `mapstructure:"burst" yaml:"burst"`
+}
+
+// CORSConfig 跨來源資源共享配置
+type CORSConfig struct {
+	Enabled          bool     `mapstructure:"enabled" yaml:"enabled"`
+	AllowedOrigins   []string `mapstructure:"allowed_origins" yaml:"allowed_origins"`
+	AllowedMethods   []string `mapstructure:"allowed_methods" yaml:"allowed_methods"`
+	AllowedHeaders   []string `mapstructure:"allowed_headers" yaml:"allowed_headers"`
+	ExposeHeaders    []string `mapstructure:"expose_headers" yaml:"expose_headers"`
+	AllowCredentials bool     `mapstructure:"allow_credentials" yaml:"allow_credentials"`
+	MaxAge           int      `mapstructure:"max_age" yaml:"max_age"` // 預檢快取秒數
+}
+
+// JWTConfig JWT 簽發配置
+type JWTConfig struct {
+	Secret            string        `mapstructure:"secret" yaml:"secret"` // HMAC 金鑰，至少 32 位元組
+	Issuer            string        `mapstructure:"issuer" yaml:"issuer"`
+	Expiration        time.Duration `mapstructure:"expiration" yaml:"expiration"`                 // access token 有效期
+	RefreshExpiration time.Duration `mapstructure:"refresh_expiration" yaml:"refresh_expiration"` // refresh token 有效期
+}
+
+// minJWTSecretLen HS256 建議的最短金鑰長度
+const minJWTSecretLen = 32
+
+// applyDefaults 填入 API 預設值
+func (a *HTTPAPIConfig) applyDefaults() {
+	if a.Version == "" {
+		a.Version = "v1"
+	}
+	if a.DocsPath == "" {
+		a.DocsPath = "/docs"
+	}
+
+	if a.RateLimit.RequestsPerMinute == 0 {
+		a.RateLimit.RequestsPerMinute = 60
+	}
+	if a.RateLimit.Burst == 0 {
+		a.RateLimit.Burst = 10
+	}
+
+	if len(a.CORS.AllowedOrigins) == 0 {
+		a.CORS.AllowedOrigins = []string{"*"}
+	}
+	if len(a.CORS.AllowedMethods) == 0 {
+		a.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(a.CORS.AllowedHeaders) == 0 {
+		a.CORS.AllowedHeaders = []string{"Content-Type", "Authorization", "X-Request-ID"}
+	}
+	if a.CORS.MaxAge == 0 {
+		a.CORS.MaxAge = 86400 // 24小時
+	}
+
+	if a.JWT.Issuer == "" {
+		a.JWT.Issuer = "hypgo"
+	}
+	if a.JWT.Expiration == 0 {
+		a.JWT.Expiration = 24 * time.Hour
+	}
+	if a.JWT.RefreshExpiration == 0 {
+		a.JWT.RefreshExpiration = 7 * 24 * time.Hour
+	}
+}
+
+// validate 將所有問題記錄到 v（供 Config.Validate 彙總）
+func (a *HTTPAPIConfig) validate(v *ValidationError) {
+	const prefix = "api."
+	if a.DocsPath != "" && !strings.HasPrefix(a.DocsPath, "/") {
+		v.addf(prefix+"docs_path", "must start with /")
+	}
+
+	if a.RateLimit.Enabled && a.RateLimit.RequestsPerMinute <= 0 {
+		v.addf(prefix+"rate_limit.requests_per_minute", "must be positive when rate limiting is enabled")
+	}
+	if a.RateLimit.Burst < 0 {
+		v.addf(prefix+"rate_limit.burst", "must not be negative")
+	}
+
+	// 瀏覽器拒絕同時帶 Access-Control-Allow-Origin: * 與憑證的回應
+	if a.CORS.AllowCredentials {
+		for _, origin := range a.CORS.AllowedOrigins {
+			if origin == "*" {
+				v.addf(prefix+"cors.allowed_origins", `"*" cannot be combined with allow_credentials`)
+				break
+			}
+		}
+	}
+	if a.CORS.MaxAge < 0 {
+		v.addf(prefix+"cors.max_age", "must not be negative")
+	}
+
+	if a.JWT.Secret != "" && len(a.JWT.Secret) < minJWTSecretLen {
+		v.addf(prefix+"jwt.secret", "must be at least %d bytes", minJWTSecretLen)
+	}
+	if a.JWT.Expiration < 0 {
+		v.addf(prefix+"jwt.expiration", "must not be negative")
+	}
+	if a.JWT.RefreshExpiration < 0 {
+		v.addf(prefix+"jwt.refresh_expiration", "must not be negative")
+	}
+	if a.JWT.Expiration > 0 && a.JWT.RefreshExpiration > 0 && a.JWT.RefreshExpiration < a.JWT.Expiration {
+		v.addf(prefix+"jwt.refresh_expiration", "must not be shorter than expiration")
+	}
+}
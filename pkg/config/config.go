@@ -3,8 +3,10 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -19,24 +21,74 @@ type ConfigInterface interface {
 }
 
 type Config struct {
-	Server   ServerConfig   `mapstructure:"server" yaml:"server"`
-	Database DatabaseConfig `mapstructure:"database" yaml:"database"`
-	Logger   LoggerConfig   `mapstructure:"logger" yaml:"logger"`
+	Server     ServerConfig     `mapstructure:"server" yaml:"server"`
+	Database   DatabaseConfig   `mapstructure:"database" yaml:"database"`
+	Redis      RedisConfig      `mapstructure:"redis" yaml:"redis"`
+	Logger     LoggerConfig     `mapstructure:"logger" yaml:"logger"`
+	API        HTTPAPIConfig    `mapstructure:"api" yaml:"api"`
+	Monitoring MonitoringConfig `mapstructure:"monitoring" yaml:"monitoring"`
 }
 
 type ServerConfig struct {
-	Addr         string        `mapstructure:"addr" yaml:"addr"`
-	Protocol     string        `mapstructure:"protocol" yaml:"protocol"` // "http1", "http2", "http3", "auto"
-	TLS          TLSConfig     `mapstructure:"tls" yaml:"tls"`
+	Addr     string    `mapstructure:"addr" yaml:"addr"`
+	Protocol string    `mapstructure:"protocol" yaml:"protocol"` // "http1", "http2", "http3", "auto"
+	TLS      TLSConfig `mapstructure:"tls" yaml:"tls"`
+
+	// 分離埠模式：兩者皆設定時，HTTPAddr 僅做 HTTP→HTTPS 301 重導向（ACME challenge 除外），
+	// 應用改由 HTTPSAddr 提供；皆留空則沿用單一 Addr
+	HTTPAddr  string `mapstructure:"http_addr" yaml:"http_addr"`
+	HTTPSAddr string `mapstructure:"https_addr" yaml:"https_addr"`
+
+	// HTTP/3 的 UDP 監聽位址，留空則與 TCP 主位址（Addr 或 HTTPSAddr）相同；
+	// Alt-Svc 會依實際監聽的 UDP 埠發佈，AltSvcMaxAge 為其 ma 參數（秒）
+	HTTP3Addr    string `mapstructure:"http3_addr" yaml:"http3_addr"`
+	AltSvcMaxAge int    `mapstructure:"alt_svc_max_age" yaml:"alt_svc_max_age"`
+
+	// HTTP/3 UDP 綁定遇到暫時性錯誤（埠被占用等）時的重試次數（預設 3，-1 不重試）與間隔（預設 1s）
+	HTTP3BindRetries    int           `mapstructure:"http3_bind_retries" yaml:"http3_bind_retries"`
+	HTTP3BindRetryDelay time.Duration `mapstructure:"http3_bind_retry_delay" yaml:"http3_bind_retry_delay"`
+
+	// 可信代理網段（CIDR 或單一 IP），僅來自這些位址的請求才採信 X-Forwarded-For / X-Real-IP
+	TrustedProxies []string `mapstructure:"trusted_proxies" yaml:"trusted_proxies"`
+
+	// 單一請求的最長處理時間（0 為不限制），逾時回傳 503；HTTP/3 依 RTT 自動延長
+	RequestTimeout time.Duration `mapstructure:"request_timeout" yaml:"request_timeout"`
+
+	// Kubernetes 探針路徑（留空使用 /livez、/readyz，設為 "-"（ProbeDisabled）則不註冊）
+	LivenessPath  string `mapstructure:"liveness_path" yaml:"liveness_path"`
+	ReadinessPath string `mapstructure:"readiness_path" yaml:"readiness_path"`
+
+	// 關閉排空：readiness 失敗後等待 DrainDelay 才停止監聽，讓負載平衡器先移除實例；
+	// DrainReject 為 true 時，關閉期間的新請求直接回傳 503 並帶 Connection: close
+	DrainDelay  time.Duration `mapstructure:"drain_delay" yaml:"drain_delay"`
+	DrainReject bool          `mapstructure:"drain_reject" yaml:"drain_reject"`
+
 	ReadTimeout  time.Duration `mapstructure:"read_timeout" yaml:"read_timeout"`
 	WriteTimeout time.Duration `mapstructure:"write_timeout" yaml:"write_timeout"`
 
+	// 防 slow-loris：標頭需在 ReadHeaderTimeout 內讀完（預設 5s，不超過 read_timeout），與 body 的 read_timeout 分開，
+	// 慢速上傳不受影響；MaxHeaderBytes 為請求標頭上限（預設 1MB，HTTP/3 同樣適用）
+	ReadHeaderTimeout time.Duration `mapstructure:"read_header_timeout" yaml:"read_header_timeout"`
+	MaxHeaderBytes    int           `mapstructure:"max_header_bytes" yaml:"max_header_bytes"`
+
+	// multipart 表單解析時保留在記憶體的上限（位元組，預設 32MB），超出的檔案部分寫入暫存檔；
+	// Context 的 FormFile / MultipartForm / 表單綁定皆依此值，個別路由可用 Context.SetMaxMultipartMemory 覆寫
+	MaxMultipartMemory int64 `mapstructure:"max_multipart_memory" yaml:"max_multipart_memory"`
+
 	// HTTP/2 相關配置
 	MaxHandlers          int `mapstructure:"max_handlers" yaml:"max_handlers"`
 	MaxConcurrentStreams int `mapstructure:"max_concurrent_streams" yaml:"max_concurrent_streams"`
 	MaxReadFrameSize     int `mapstructure:"max_read_frame_size" yaml:"max_read_frame_size"`
 	IdleTimeout          int `mapstructure:"idle_timeout" yaml:"idle_timeout"` // 秒
 
+	// TCP keep-alive 探測週期（秒，-1 停用）；與 idle_timeout 分開：
+	// idle_timeout 是 HTTP 層關閉閒置連線的時間，keep_alive 是作業系統偵測斷線對端的週期。
+	// QUIC（HTTP/3）不使用 TCP keep-alive，其閒置逾時由 QUIC 本身的設定控制。
+	KeepAlive int `mapstructure:"keep_alive" yaml:"keep_alive"`
+
+	// HTTP/3（QUIC）調校參數
+	QUIC QUICConfig `mapstructure:"quic" yaml:"quic"`
+
 	// 優雅重啟
 	EnableGracefulRestart bool `mapstructure:"enable_graceful_restart" yaml:"enable_graceful_restart"`
 }
@@ -45,13 +97,32 @@ type TLSConfig struct {
 	Enabled  bool   `mapstructure:"enabled" yaml:"enabled"`
 	CertFile string `mapstructure:"cert_file" yaml:"cert_file"`
 	KeyFile  string `mapstructure:"key_file" yaml:"key_file"`
+
+	// 進階 TLS 設定（留空時沿用框架內建的安全預設）
+	MinVersion          string   `mapstructure:"min_version" yaml:"min_version"`     // "1.2"、"1.3"
+	CipherSuites        []string `mapstructure:"cipher_suites" yaml:"cipher_suites"` // IANA 名稱，如 TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256
+	PreferServerCiphers bool     `mapstructure:"prefer_server_ciphers" yaml:"prefer_server_ciphers"`
+
+	// TCP（HTTP/1.1、HTTP/2）session ticket 金鑰輪替週期，0 為交由 crypto/tls 自動輪替（24h）；
+	// 舊金鑰保留兩個週期，讓輪替前發出的 ticket 仍可恢復連線
+	SessionTicketRotation time.Duration `mapstructure:"session_ticket_rotation" yaml:"session_ticket_rotation"`
 }
 
 // RedisConfig Redis 配置
+// 頂層 redis 區段供應用快取使用；database.redis 供 hidb 使用，未設定時沿用頂層
 type RedisConfig struct {
 	Addr     string `mapstructure:"addr" yaml:"addr"`
 	Password string `mapstructure:"password" yaml:"password"`
 	DB       int    `mapstructure:"db" yaml:"db"`
+
+	// 連接池與重試（0 沿用 go-redis 預設：pool_size 為 10×GOMAXPROCS、max_retries 為 3，-1 停用重試）
+	PoolSize     int `mapstructure:"pool_size" yaml:"pool_size"`
+	MinIdleConns int `mapstructure:"min_idle_conns" yaml:"min_idle_conns"`
+	MaxRetries   int `mapstructure:"max_retries" yaml:"max_retries"`
+
+	DialTimeout  time.Duration `mapstructure:"dial_timeout" yaml:"dial_timeout"`   // 預設 5s
+	ReadTimeout  time.Duration `mapstructure:"read_timeout" yaml:"read_timeout"`   // 預設 3s
+	WriteTimeout time.Duration `mapstructure:"write_timeout" yaml:"write_timeout"` // 預設 3s
 }
 
 // ReplicaConfig 讀取副本配置
@@ -67,11 +138,22 @@ type ReplicaConfigProvider interface {
 	GetReplicas() []ReplicaConfig
 }
 
+// MigrationCheckProvider migration 就緒檢查配置提供者（可選介面）
+// 實現此介面且啟用時，hidb 的 RegisterHealthChecks 會一併註冊 "migrations" 檢查
+type MigrationCheckProvider interface {
+	IsMigrationCheckEnabled() bool
+	GetMigrationsDir() string
+}
+
 type DatabaseConfig struct {
 	Driver       string `mapstructure:"driver" yaml:"driver"` // mysql, postgresql, tidb, redis
 	DSN          string `mapstructure:"dsn" yaml:"dsn"`
 	MaxIdleConns int    `mapstructure:"max_idle_conns" yaml:"max_idle_conns"`
 	MaxOpenConns int    `mapstructure:"max_open_conns" yaml:"max_open_conns"`
+	// 就緒檢查比對 schema_migrations 與 MigrationsDir 中最新的 migration，
+	// 尚未套用完成前 readiness 回報 degraded（503），避免以舊 schema 服務；預設關閉
+	MigrationCheck bool   `mapstructure:"migration_check" yaml:"migration_check"`
+	MigrationsDir  string `mapstructure:"migrations_dir" yaml:"migrations_dir"` // 預設 "migrations"
 	// Redis 配置
 	Redis RedisConfig `mapstructure:"redis" yaml:"redis"`
 	// 讀取副本配置（讀寫分離）
@@ -98,12 +180,43 @@ type DatabaseConfigInterface interface {
 }
 
 type LoggerConfig struct {
-	Level        string `mapstructure:"level" yaml:"level"` // debug, info, notice, warning, emergency
-	Output       string `mapstructure:"output" yaml:"output"`
-	MaxSize      int    `mapstructure:"max_size" yaml:"max_size"`
-	MaxAge       int    `mapstructure:"max_age" yaml:"max_age"`
-	Compress     bool   `mapstructure:"compress" yaml:"compress"`
-	ColorEnabled bool   `mapstructure:"color_enabled" yaml:"color_enabled"`
+	Level      string `mapstructure:"level" yaml:"level"`   // debug, info, notice, warning, emergency
+	Output     string `mapstructure:"output" yaml:"output"` // stdout, file, both
+	File       string `mapstructure:"file" yaml:"file"`     // output 為 file / both 時的檔案路徑
+	MaxSize    int    `mapstructure:"max_size" yaml:"max_size"`
+	MaxAge     int    `mapstructure:"max_age" yaml:"max_age"`
+	MaxBackups int    `mapstructure:"max_backups" yaml:"max_backups"`
+	Compress   bool   `mapstructure:"compress" yaml:"compress"`
+	Format     string `mapstructure:"format" yaml:"format"` // json, text
+	TimeFormat string `mapstructure:"time_format" yaml:"time_format"`
+
+	// 彩色輸出；color_enabled 為舊鍵名，兩者任一為 true 即啟用
+	Colors       bool `mapstructure:"colors" yaml:"colors"`
+	ColorEnabled bool `mapstructure:"color_enabled" yaml:"color_enabled"`
+}
+
+// MonitoringConfig 監控與分散式追蹤配置
+type MonitoringConfig struct {
+	MetricsEnabled bool   `mapstructure:"metrics_enabled" yaml:"metrics_enabled"`
+	MetricsPath    string `mapstructure:"metrics_path" yaml:"metrics_path"`
+	HealthPath     string `mapstructure:"health_path" yaml:"health_path"`
+
+	// OpenTelemetry 追蹤：provider 為 "otlp" 或 "jaeger"（皆以 OTLP/HTTP 匯出，Jaeger 需 1.35+），
+	// endpoint 如 http://localhost:4318/v1/traces；留空時讀取 OTEL_EXPORTER_OTLP_* 環境變數
+	TraceEnabled    bool     `mapstructure:"trace_enabled" yaml:"trace_enabled"`
+	TraceProvider   string   `mapstructure:"trace_provider" yaml:"trace_provider"`
+	TraceEndpoint   string   `mapstructure:"trace_endpoint" yaml:"trace_endpoint"`
+	TraceSampleRate *float64 `mapstructure:"trace_sample_rate" yaml:"trace_sample_rate"` // 0~1，未設定時為 1，0 表示不取樣
+	ServiceName     string   `mapstructure:"service_name" yaml:"service_name"`
+}
+
+// SampleRate 以程式設定 TraceSampleRate 時使用，SampleRate(0) 表示不取樣
+//
+// EX：
+//
+//	cfg.Monitoring.TraceSampleRate = config.SampleRate(0.1)
+func SampleRate(rate float64) *float64 {
+	return &rate
 }
 
 // RedisConfigInterface Redis配置接口
@@ -160,7 +273,11 @@ func (cl *ConfigLoader) Load(configFile string, config interface{}) error {
 		}
 	}
 
-	// 解析配置到用戶提供的結構體
+	return decodeConfig(configData, config)
+}
+
+// decodeConfig 解析配置到用戶提供的結構體，套用預設值後驗證
+func decodeConfig(configData []byte, config interface{}) error {
 	if err := yaml.Unmarshal(configData, config); err != nil {
 		return fmt.Errorf("failed to unmarshal config: %w", err)
 	}
@@ -181,14 +298,39 @@ func (cl *ConfigLoader) Load(configFile string, config interface{}) error {
 }
 
 // LoadConfig 讀取設定檔，套用預設值並驗證後回傳 *Config（便捷函式）。
+// 設定 HYPGO_ENV 時先疊加同目錄的 profile 檔（見 LoadProfile）。
 // 檔案不存在、格式錯誤或驗證失敗時回傳 error；成功時預設值已套用。
 //
 //	cfg, err := config.LoadConfig("config.yaml")
 func LoadConfig(path string) (*Config, error) {
+	data, err := readProfile(path, "")
+	if err != nil {
+		return nil, err
+	}
 	cfg := &Config{}
-	if err := NewConfigLoader(path).Load(path, cfg); err != nil {
+	if err := decodeConfig(data, cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ReadConfig 讀取設定檔（含 HYPGO_ENV 的 profile）並套用預設值，但不驗證；
+// 供驗證失敗時仍需檢視生效配置的工具使用（如 hyp config）
+func ReadConfig(path string) (*Config, error) {
+	return ReadConfigProfile(path, "")
+}
+
+// ReadConfigProfile 同 ReadConfig，以 env 指定 profile（空字串時使用 HYPGO_ENV）
+func ReadConfigProfile(path, env string) (*Config, error) {
+	data, err := readProfile(path, env)
+	if err != nil {
 		return nil, err
 	}
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config: %w", err)
+	}
+	cfg.ApplyDefaults()
 	return cfg, nil
 }
 
@@ -207,6 +349,25 @@ func (c *Config) ApplyDefaults() {
 	if c.Server.WriteTimeout == 0 {
 		c.Server.WriteTimeout = 30 * time.Second
 	}
+	if c.Server.ReadHeaderTimeout == 0 {
+		// 預設 5s，但不超過使用者設定的 read_timeout，避免未設定的值造成驗證失敗
+		c.Server.ReadHeaderTimeout = 5 * time.Second
+		if c.Server.ReadTimeout > 0 && c.Server.ReadTimeout < c.Server.ReadHeaderTimeout {
+			c.Server.ReadHeaderTimeout = c.Server.ReadTimeout
+		}
+	}
+	if c.Server.MaxHeaderBytes == 0 {
+		c.Server.MaxHeaderBytes = 1 << 20 // 1MB
+	}
+	if c.Server.MaxMultipartMemory == 0 {
+		c.Server.MaxMultipartMemory = 32 << 20 // 32MB
+	}
+	if c.Server.LivenessPath == "" {
+		c.Server.LivenessPath = "/livez"
+	}
+	if c.Server.ReadinessPath == "" {
+		c.Server.ReadinessPath = "/readyz"
+	}
 
 	// HTTP/2 預設值
 	if c.Server.MaxHandlers == 0 {
@@ -221,6 +382,18 @@ func (c *Config) ApplyDefaults() {
 	if c.Server.IdleTimeout == 0 {
 		c.Server.IdleTimeout = 120 // 120秒
 	}
+	if c.Server.KeepAlive == 0 {
+		c.Server.KeepAlive = 30 // 30秒
+	}
+	if c.Server.AltSvcMaxAge == 0 {
+		c.Server.AltSvcMaxAge = 86400 // 24小時
+	}
+	if c.Server.HTTP3BindRetries == 0 {
+		c.Server.HTTP3BindRetries = 3
+	}
+	if c.Server.HTTP3BindRetryDelay == 0 {
+		c.Server.HTTP3BindRetryDelay = time.Second
+	}
 
 	// Database 預設值
 	if c.Database.MaxIdleConns == 0 {
@@ -229,9 +402,15 @@ func (c *Config) ApplyDefaults() {
 	if c.Database.MaxOpenConns == 0 {
 		c.Database.MaxOpenConns = 100
 	}
-	if c.Database.Redis.Addr == "" {
-		c.Database.Redis.Addr = "localhost:6379"
+	if c.Database.MigrationsDir == "" {
+		c.Database.MigrationsDir = "migrations"
 	}
+	// database.redis 未設定時沿用頂層 redis
+	if c.Database.Redis.Addr == "" && c.Redis.Addr != "" {
+		c.Database.Redis = c.Redis
+	}
+	c.Redis.applyDefaults()
+	c.Database.Redis.applyDefaults()
 	// 讀取副本預設值：繼承主庫的連接池參數
 	for i := range c.Database.Replicas {
 		if c.Database.Replicas[i].MaxIdleConns == 0 {
@@ -246,8 +425,16 @@ func (c *Config) ApplyDefaults() {
 	if c.Logger.Level == "" {
 		c.Logger.Level = "info"
 	}
-	if c.Logger.Output == "" {
+	switch c.Logger.Output {
+	case "":
 		c.Logger.Output = "stdout"
+	case "stdout", "file", "both":
+	default:
+		// 相容舊格式：output 直接寫檔案路徑（例如 output: logs/app.log）
+		if c.Logger.File == "" {
+			c.Logger.File = c.Logger.Output
+		}
+		c.Logger.Output = "file"
 	}
 	if c.Logger.MaxSize == 0 {
 		c.Logger.MaxSize = 100 // 100MB
@@ -255,40 +442,161 @@ func (c *Config) ApplyDefaults() {
 	if c.Logger.MaxAge == 0 {
 		c.Logger.MaxAge = 7 // 7天
 	}
+	if c.Logger.MaxBackups == 0 {
+		c.Logger.MaxBackups = 10
+	}
+	if c.Logger.Format == "" {
+		c.Logger.Format = "json"
+	}
+	if c.Logger.File == "" && c.Logger.Output != "stdout" {
+		c.Logger.File = "logs/app.log"
+	}
+
+	// API 預設值
+	c.API.applyDefaults()
+
+	// Monitoring 預設值
+	if c.Monitoring.MetricsPath == "" {
+		c.Monitoring.MetricsPath = "/metrics"
+	}
+	if c.Monitoring.HealthPath == "" {
+		c.Monitoring.HealthPath = "/health"
+	}
+	if c.Monitoring.TraceProvider == "" {
+		c.Monitoring.TraceProvider = "otlp"
+	}
+	if c.Monitoring.TraceSampleRate == nil {
+		c.Monitoring.TraceSampleRate = SampleRate(1)
+	}
+	if c.Monitoring.ServiceName == "" {
+		c.Monitoring.ServiceName = "hypgo"
+	}
 }
 
 // Validate 驗證配置
 func (c *Config) Validate() error {
-	// 驗證協議
-	if err := ValidateProtocol(c.Server.Protocol); err != nil {
-		return err
-	}
+	var v ValidationError
+	srv := &c.Server
 
-	// 驗證日誌級別
-	if err := ValidateLogLevel(c.Logger.Level); err != nil {
-		return err
+	v.addErr("server.protocol", ValidateProtocol(srv.Protocol))
+	v.addErr("logger.level", ValidateLogLevel(c.Logger.Level))
+	if c.Database.Driver != "" {
+		v.addErr("database.driver", ValidateDatabaseDriver(c.Database.Driver))
 	}
+	c.Redis.validate(&v, "redis.")
+	c.Database.Redis.validate(&v, "database.redis.")
 
-	// 驗證數據庫驅動
-	if c.Database.Driver != "" {
-		if err := ValidateDatabaseDriver(c.Database.Driver); err != nil {
-			return err
+	// Logger
+	switch c.Logger.Output {
+	case "stdout", "file", "both":
+	default:
+		v.addf("logger.output", "invalid output %q, must be stdout, file or both", c.Logger.Output)
+	}
+	if c.Logger.Format != "json" && c.Logger.Format != "text" {
+		v.addf("logger.format", "invalid format %q, must be json or text", c.Logger.Format)
+	}
+	limits := []struct {
+		field string
+		n     int
+	}{
+		{"logger.max_size", c.Logger.MaxSize},
+		{"logger.max_age", c.Logger.MaxAge},
+		{"logger.max_backups", c.Logger.MaxBackups},
+	}
+	for _, l := range limits {
+		if l.n < 0 {
+			v.addf(l.field, "must not be negative")
 		}
 	}
 
-	// 驗證 TLS 配置
-	if c.Server.TLS.Enabled {
-		if c.Server.TLS.CertFile == "" || c.Server.TLS.KeyFile == "" {
-			return fmt.Errorf("TLS enabled but cert_file or key_file is empty")
+	c.API.validate(&v)
+
+	// TLS 配置
+	if srv.TLS.Enabled {
+		if srv.TLS.CertFile == "" {
+			v.addf("server.tls.cert_file", "required when TLS is enabled")
+		}
+		if srv.TLS.KeyFile == "" {
+			v.addf("server.tls.key_file", "required when TLS is enabled")
 		}
 	}
+	if _, err := ParseTLSVersion(srv.TLS.MinVersion); err != nil {
+		v.addErr("server.tls.min_version", err)
+	}
+	if _, err := ParseCipherSuites(srv.TLS.CipherSuites); err != nil {
+		v.addErr("server.tls.cipher_suites", err)
+	}
+	if srv.TLS.SessionTicketRotation < 0 {
+		v.addf("server.tls.session_ticket_rotation", "must not be negative")
+	}
+
+	if _, err := ParseTrustedProxies(srv.TrustedProxies); err != nil {
+		v.addErr("server.trusted_proxies", err)
+	}
+
+	// 逾時與連線限制
+	if srv.IdleTimeout < 0 {
+		v.addf("server.idle_timeout", "must not be negative")
+	}
+	if srv.ReadHeaderTimeout < 0 {
+		v.addf("server.read_header_timeout", "must not be negative")
+	}
+	if srv.ReadTimeout > 0 && srv.ReadHeaderTimeout > srv.ReadTimeout {
+		v.addf("server.read_header_timeout", "must not exceed read_timeout (%v)", srv.ReadTimeout)
+	}
+	if srv.MaxHeaderBytes < 0 || srv.MaxHeaderBytes > maxHeaderBytesLimit {
+		v.addf("server.max_header_bytes", "must be between 0 and %d", maxHeaderBytesLimit)
+	}
+	if srv.MaxMultipartMemory < 0 {
+		v.addf("server.max_multipart_memory", "must not be negative")
+	}
+	if srv.KeepAlive < -1 {
+		v.addf("server.keep_alive", "must be -1 (disabled) or a positive number of seconds")
+	}
+	srv.QUIC.validate(&v)
+
+	// 分離埠模式需同時設定兩個位址並啟用 TLS
+	if (srv.HTTPAddr == "") != (srv.HTTPSAddr == "") {
+		v.addf("server.http_addr", "http_addr and https_addr must be set together")
+	}
+	if srv.HTTPSAddr != "" && !srv.TLS.Enabled {
+		v.addf("server.https_addr", "requires TLS to be enabled")
+	}
 
 	// HTTP/3 必須啟用 TLS
-	if c.Server.Protocol == "http3" && !c.Server.TLS.Enabled {
-		return fmt.Errorf("HTTP/3 requires TLS to be enabled")
+	if srv.Protocol == "http3" && !srv.TLS.Enabled {
+		v.addf("server.protocol", "HTTP/3 requires TLS to be enabled")
+	}
+	if srv.HTTP3Addr != "" {
+		if _, _, err := net.SplitHostPort(srv.HTTP3Addr); err != nil {
+			v.addf("server.http3_addr", "invalid address %q: %v", srv.HTTP3Addr, err)
+		}
+	}
+	if srv.AltSvcMaxAge < 0 {
+		v.addf("server.alt_svc_max_age", "must not be negative")
+	}
+	if srv.HTTP3BindRetries < -1 {
+		v.addf("server.http3_bind_retries", "must be -1 (no retry) or greater")
+	}
+	if srv.HTTP3BindRetryDelay < 0 {
+		v.addf("server.http3_bind_retry_delay", "must not be negative")
 	}
 
-	return nil
+	// Monitoring
+	if p := c.Monitoring.MetricsPath; p != "" && !strings.HasPrefix(p, "/") {
+		v.addf("monitoring.metrics_path", "must start with /")
+	}
+	if p := c.Monitoring.HealthPath; p != "" && !strings.HasPrefix(p, "/") {
+		v.addf("monitoring.health_path", "must start with /")
+	}
+	if p := c.Monitoring.TraceProvider; p != "" && p != "otlp" && p != "jaeger" {
+		v.addf("monitoring.trace_provider", "invalid provider %q, must be otlp or jaeger", p)
+	}
+	if r := c.Monitoring.TraceSampleRate; r != nil && (*r < 0 || *r > 1) {
+		v.addf("monitoring.trace_sample_rate", "must be between 0 and 1")
+	}
+
+	return v.err()
 }
 
 // GetServerConfig 實現 ConfigInterface
@@ -410,8 +718,25 @@ func (s *ServerConfig) GetWriteTimeout() int {
 	return int(s.WriteTimeout.Seconds())
 }
 
-// GetMaxHeaderBytes 獲取最大標頭字節數
+// ProbeDisabled 設為 liveness_path / readiness_path 時不註冊該探針（留空會套用預設路徑）
+const ProbeDisabled = "-"
+
+// ProbePath 探針實際註冊的路徑，停用時為空字串
+func ProbePath(path string) string {
+	if path == ProbeDisabled {
+		return ""
+	}
+	return path
+}
+
+// maxHeaderBytesLimit max_header_bytes 上限，避免設定錯誤讓單一連線佔用過多記憶體
+const maxHeaderBytesLimit = 16 << 20
+
+// GetMaxHeaderBytes 獲取最大標頭字節數，未設定時為 1MB
 func (s *ServerConfig) GetMaxHeaderBytes() int {
+	if s.MaxHeaderBytes > 0 {
+		return s.MaxHeaderBytes
+	}
 	return 1 << 20 // 1MB
 }
 
@@ -458,6 +783,19 @@ func (d *DatabaseConfig) GetReplicas() []ReplicaConfig {
 	return d.Replicas
 }
 
+// IsMigrationCheckEnabled 是否啟用 migration 就緒檢查（實現 MigrationCheckProvider 介面）
+func (d *DatabaseConfig) IsMigrationCheckEnabled() bool {
+	return d.MigrationCheck
+}
+
+// GetMigrationsDir 獲取 migration 檔案目錄
+func (d *DatabaseConfig) GetMigrationsDir() string {
+	if d.MigrationsDir != "" {
+		return d.MigrationsDir
+	}
+	return "migrations" // 預設值
+}
+
 // ===== LoggerConfig 接口實現 =====
 
 // GetLevel 獲取日誌級別
@@ -472,15 +810,21 @@ func (l *LoggerConfig) GetOutput() string {
 
 // GetFormat 獲取日誌格式
 func (l *LoggerConfig) GetFormat() string {
-	return "json" // 預設使用 JSON 格式
+	if l.Format == "" {
+		return "json" // 預設使用 JSON 格式
+	}
+	return l.Format
 }
 
-// GetFilename 獲取日誌文件名
+// GetFilename 獲取日誌文件名，僅輸出到 stdout 時為空
 func (l *LoggerConfig) GetFilename() string {
-	if l.Output == "file" {
+	if l.Output != "file" && l.Output != "both" {
+		return ""
+	}
+	if l.File == "" {
 		return "logs/app.log"
 	}
-	return ""
+	return l.File
 }
 
 // GetMaxSize 獲取最大文件大小（MB）
@@ -495,12 +839,15 @@ func (l *LoggerConfig) GetMaxAge() int {
 
 // GetMaxBackups 獲取最大備份數量
 func (l *LoggerConfig) GetMaxBackups() int {
-	return 10 // 預設保留 10 個備份
+	if l.MaxBackups == 0 {
+		return 10 // 預設保留 10 個備份
+	}
+	return l.MaxBackups
 }
 
 // IsColorized 是否啟用彩色輸出
 func (l *LoggerConfig) IsColorized() bool {
-	return l.ColorEnabled
+	return l.Colors || l.ColorEnabled
 }
 
 // ===== RedisConfig 接口實現 =====
@@ -522,3 +869,51 @@ func (r *RedisConfig) GetPassword() string {
 func (r *RedisConfig) GetDB() int {
 	return r.DB
 }
+
+// applyDefaults 填入 Redis 預設值
+func (r *RedisConfig) applyDefaults() {
+	if r.Addr == "" {
+		r.Addr = "localhost:6379"
+	}
+	if r.DialTimeout == 0 {
+		r.DialTimeout = 5 * time.Second
+	}
+	if r.ReadTimeout == 0 {
+		r.ReadTimeout = 3 * time.Second
+	}
+	if r.WriteTimeout == 0 {
+		r.WriteTimeout = 3 * time.Second
+	}
+}
+
+// validate 將所有問題記錄到 v，prefix 為欄位前綴（redis. 或 database.redis.）
+func (r *RedisConfig) validate(v *ValidationError, prefix string) {
+	if _, _, err := net.SplitHostPort(r.Addr); err != nil {
+		v.addf(prefix+"addr", "invalid address %q: %v", r.Addr, err)
+	}
+	if r.DB < 0 {
+		v.addf(prefix+"db", "must not be negative")
+	}
+	if r.PoolSize < 0 {
+		v.addf(prefix+"pool_size", "must not be negative")
+	}
+	if r.MinIdleConns < 0 || (r.PoolSize > 0 && r.MinIdleConns > r.PoolSize) {
+		v.addf(prefix+"min_idle_conns", "must be between 0 and pool_size")
+	}
+	if r.MaxRetries < -1 {
+		v.addf(prefix+"max_retries", "must be -1 (disabled) or greater")
+	}
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"dial_timeout", r.DialTimeout},
+		{"read_timeout", r.ReadTimeout},
+		{"write_timeout", r.WriteTimeout},
+	}
+	for _, t := range timeouts {
+		if t.d < 0 {
+			v.addf(prefix+t.name, "must not be negative")
+		}
+	}
+}
@@ -1,6 +1,12 @@
 package config
 
 import (
+	"crypto/tls"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 )
@@ -94,6 +100,9 @@ func TestConfig_ApplyDefaults(t *testing.T) {
 	if c.Server.MaxHandlers != 1000 {
 		t.Errorf("Expected Server.MaxHandlers = 1000, got %d", c.Server.MaxHandlers)
 	}
+	if c.Server.KeepAlive != 30 {
+		t.Errorf("Expected Server.KeepAlive = 30, got %d", c.Server.KeepAlive)
+	}
 
 	// Database defaults
 	if c.Database.MaxIdleConns != 10 {
@@ -119,6 +128,129 @@ func TestConfig_ApplyDefaults(t *testing.T) {
 	if c.Logger.MaxAge != 7 {
 		t.Errorf("Expected Logger.MaxAge = 7, got %d", c.Logger.MaxAge)
 	}
+	if c.Logger.Format != "json" || c.Logger.MaxBackups != 10 {
+		t.Errorf("Expected Logger.Format = json and MaxBackups = 10, got %q / %d", c.Logger.Format, c.Logger.MaxBackups)
+	}
+
+	// 舊格式：output 直接寫檔案路徑
+	legacy := Config{Logger: LoggerConfig{Output: "logs/app.log"}}
+	legacy.ApplyDefaults()
+	if legacy.Logger.Output != "file" || legacy.Logger.GetFilename() != "logs/app.log" {
+		t.Errorf("Expected legacy output path to become file output, got %+v", legacy.Logger)
+	}
+	if err := legacy.Validate(); err != nil {
+		t.Errorf("Expected legacy output path to validate, got %v", err)
+	}
+
+	// Redis / API / Monitoring defaults
+	if c.Redis.Addr != "localhost:6379" || c.Redis.DialTimeout != 5*time.Second {
+		t.Errorf("unexpected Redis defaults: %+v", c.Redis)
+	}
+	if c.API.Version != "v1" || c.API.DocsPath != "/docs" {
+		t.Errorf("unexpected API defaults: %+v", c.API)
+	}
+	if c.API.JWT.Expiration != 24*time.Hour || c.API.RateLimit.RequestsPerMinute != 60 {
+		t.Errorf("unexpected API defaults: %+v", c.API)
+	}
+	if c.Monitoring.MetricsPath != "/metrics" || c.Monitoring.HealthPath != "/health" {
+		t.Errorf("unexpected Monitoring defaults: %+v", c.Monitoring)
+	}
+	if r := c.Monitoring.TraceSampleRate; r == nil || *r != 1 {
+		t.Errorf("Expected default trace_sample_rate 1, got %v", r)
+	}
+}
+
+// 對應 hyp api 產生的 config/config.yaml 區段
+const scaffoldConfigYAML = `
+server:
+  protocol: http2
+  addr: :8080
+redis:
+  addr: "redis:6380"
+  password: "p$ss"
+  pool_size: 10
+  min_idle_conns: 5
+  max_retries: 3
+  dial_timeout: 5s
+  read_timeout: 3s
+logger:
+  level: debug
+  output: both
+  file: logs/api.log
+  max_backups: 5
+  format: text
+  colors: true
+  time_format: "2006-01-02 15:04:05"
+api:
+  version: "v2"
+  docs_enabled: true
+  rate_limit:
+    enabled: true
+    requests_per_minute: 120
+    burst: 20
+  cors:
+    enabled: true
+    allowed_origins: ["https://example.com"]
+    expose_headers: [X-Request-ID]
+    max_age: 600
+  jwt:
+    secret: "ssssssssssssssssssssssssssssssss"
+    issuer: "hypgo-api"
+    expiration: 1h
+    refresh_expiration: 720h
+monitoring:
+  metrics_enabled: true
+  trace_provider: "jaeger"
+  trace_sample_rate: 0
+  service_name: "hypgo-api"
+`
+
+func TestLoadConfig_ScaffoldSections(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(scaffoldConfigYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig: %v", err)
+	}
+
+	// 值中的 $ 保留原樣
+	if cfg.Redis.Addr != "redis:6380" || cfg.Redis.Password != "p$ss" {
+		t.Errorf("unexpected redis: %+v", cfg.Redis)
+	}
+	if cfg.Redis.PoolSize != 10 || cfg.Redis.MinIdleConns != 5 || cfg.Redis.WriteTimeout != 3*time.Second {
+		t.Errorf("unexpected redis pool settings: %+v", cfg.Redis)
+	}
+	if cfg.Database.Redis.Addr != "redis:6380" {
+		t.Errorf("Expected database.redis to inherit top-level redis, got %q", cfg.Database.Redis.Addr)
+	}
+
+	l := cfg.GetLoggerConfig()
+	if l.GetFormat() != "text" || l.GetFilename() != "logs/api.log" || l.GetMaxBackups() != 5 || !l.IsColorized() {
+		t.Errorf("unexpected logger: %+v", cfg.Logger)
+	}
+
+	if cfg.API.Version != "v2" || cfg.API.DocsPath != "/docs" {
+		t.Errorf("unexpected api: %+v", cfg.API)
+	}
+	if !cfg.API.RateLimit.Enabled || cfg.API.RateLimit.RequestsPerMinute != 120 {
+		t.Errorf("unexpected rate limit: %+v", cfg.API.RateLimit)
+	}
+	if cfg.API.CORS.AllowedOrigins[0] != "https://example.com" || len(cfg.API.CORS.AllowedMethods) == 0 {
+		t.Errorf("unexpected cors: %+v", cfg.API.CORS)
+	}
+	if cfg.API.JWT.Secret != strings.Repeat("s", 32) || cfg.API.JWT.Expiration != time.Hour {
+		t.Errorf("unexpected jwt: %+v", cfg.API.JWT)
+	}
+	if cfg.Monitoring.TraceProvider != "jaeger" || cfg.Monitoring.MetricsPath != "/metrics" {
+		t.Errorf("unexpected monitoring: %+v", cfg.Monitoring)
+	}
+	// 明確設定 0 表示不取樣，不可被預設值覆蓋
+	if r := cfg.Monitoring.TraceSampleRate; r == nil || *r != 0 {
+		t.Errorf("Expected explicit trace_sample_rate 0 to be kept, got %v", r)
+	}
 }
 
 func TestConfig_Validate(t *testing.T) {
@@ -151,4 +283,276 @@ func TestConfig_Validate(t *testing.T) {
 	if err := cTLSMissingKey.Validate(); err == nil {
 		t.Errorf("Expected validation to fail for TLS enabled without cert/key")
 	}
+
+	// Test invalid trusted proxy
+	cProxy := c
+	cProxy.Server.TrustedProxies = []string{"10.0.0.0/8", "not-an-ip"}
+	if err := cProxy.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for invalid trusted proxy")
+	}
+
+	// Test split port requires both addresses and TLS
+	cSplitPort := c
+	cSplitPort.Server.HTTPAddr = ":80"
+	if err := cSplitPort.Validate(); err == nil {
+		t.Errorf("Expected validation to fail when only http_addr is set")
+	}
+	cSplitPort.Server.HTTPSAddr = ":443"
+	if err := cSplitPort.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for https_addr without TLS")
+	}
+
+	// Test unknown TLS cipher suite
+	cBadCipher := c
+	cBadCipher.Server.TLS.CipherSuites = []string{"TLS_NOT_A_REAL_SUITE"}
+	if err := cBadCipher.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for unknown cipher suite")
+	}
+
+	// Test invalid TLS min version
+	cBadVersion := c
+	cBadVersion.Server.TLS.MinVersion = "2.0"
+	if err := cBadVersion.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for invalid tls min_version")
+	}
+
+	// Test keep-alive / idle timeout ranges
+	cKeepAlive := c
+	cKeepAlive.Server.KeepAlive = -1
+	if err := cKeepAlive.Validate(); err != nil {
+		t.Errorf("Expected keep_alive -1 (disabled) to be valid, got %v", err)
+	}
+	cKeepAlive.Server.KeepAlive = -5
+	if err := cKeepAlive.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for keep_alive < -1")
+	}
+	cIdle := c
+	cIdle.Server.IdleTimeout = -1
+	if err := cIdle.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for negative idle_timeout")
+	}
+
+	// Test header read timeout / size limits
+	if c.Server.ReadHeaderTimeout != 5*time.Second || c.Server.GetMaxHeaderBytes() != 1<<20 {
+		t.Errorf("Expected header defaults 5s / 1MB, got %v / %d", c.Server.ReadHeaderTimeout, c.Server.GetMaxHeaderBytes())
+	}
+	// 只設定較短的 read_timeout 時，預設的標頭逾時隨之縮短並通過驗證
+	cShort := Config{Server: ServerConfig{ReadTimeout: 2 * time.Second}}
+	cShort.ApplyDefaults()
+	if cShort.Server.ReadHeaderTimeout != 2*time.Second {
+		t.Errorf("Expected read_header_timeout clamped to read_timeout, got %v", cShort.Server.ReadHeaderTimeout)
+	}
+	if err := cShort.Validate(); err != nil {
+		t.Errorf("Expected short read_timeout alone to validate, got %v", err)
+	}
+	cHeader := c
+	cHeader.Server.ReadHeaderTimeout = -time.Second
+	if err := cHeader.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for negative read_header_timeout")
+	}
+	cHeader.Server.ReadHeaderTimeout = time.Minute
+	if err := cHeader.Validate(); err == nil {
+		t.Errorf("Expected validation to fail when read_header_timeout exceeds read_timeout")
+	}
+	cHeader.Server.ReadHeaderTimeout = 2 * time.Second
+	cHeader.Server.MaxHeaderBytes = 64 << 20
+	if err := cHeader.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for max_header_bytes above 16MB")
+	}
+	cHeader.Server.MaxHeaderBytes = 8 << 10
+	if err := cHeader.Validate(); err != nil || cHeader.Server.GetMaxHeaderBytes() != 8<<10 {
+		t.Errorf("Expected 8KB max_header_bytes to be valid, got %v", err)
+	}
+	if cHeader.Server.MaxMultipartMemory != 32<<20 {
+		t.Errorf("Expected default max_multipart_memory 32MB, got %d", cHeader.Server.MaxMultipartMemory)
+	}
+	cHeader.Server.MaxMultipartMemory = -1
+	if err := cHeader.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for negative max_multipart_memory")
+	}
+	cHeader.Server.MaxMultipartMemory = 1 << 20
+
+	// Test HTTP/3 address and Alt-Svc max-age
+	if c.Server.AltSvcMaxAge != 86400 {
+		t.Errorf("Expected default alt_svc_max_age 86400, got %d", c.Server.AltSvcMaxAge)
+	}
+	cH3 := c
+	cH3.Server.HTTP3Addr = ":8443"
+	if err := cH3.Validate(); err != nil {
+		t.Errorf("Expected http3_addr :8443 to be valid, got %v", err)
+	}
+	cH3.Server.HTTP3Addr = "8443"
+	if err := cH3.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for http3_addr without port separator")
+	}
+	cH3.Server.HTTP3Addr = ""
+	cH3.Server.AltSvcMaxAge = -1
+	if err := cH3.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for negative alt_svc_max_age")
+	}
+	cH3.Server.AltSvcMaxAge = 3600
+	if c.Server.HTTP3BindRetries != 3 || c.Server.HTTP3BindRetryDelay != time.Second {
+		t.Errorf("Expected default HTTP/3 bind retries 3 / 1s, got %d / %s", c.Server.HTTP3BindRetries, c.Server.HTTP3BindRetryDelay)
+	}
+	cH3.Server.HTTP3BindRetries = -1
+	if err := cH3.Validate(); err != nil {
+		t.Errorf("Expected http3_bind_retries -1 to be valid, got %v", err)
+	}
+	cH3.Server.HTTP3BindRetries = -2
+	if err := cH3.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for http3_bind_retries -2")
+	}
+
+	cTicket := c
+	cTicket.Server.TLS.SessionTicketRotation = -time.Hour
+	if err := cTicket.Validate(); err == nil {
+		t.Errorf("Expected validation to fail for negative session_ticket_rotation")
+	}
+
+	// Test QUIC tuning ranges
+	cQUIC := c
+	cQUIC.Server.QUIC = QUICConfig{
+		MaxIncomingStreams:         200,
+		InitialStreamReceiveWindow: 1 << 20,
+		MaxStreamReceiveWindow:     8 << 20,
+		MaxConnectionReceiveWindow: 32 << 20,
+		MaxIdleTimeout:             time.Minute,
+		KeepAlivePeriod:            15 * time.Second,
+	}
+	if err := cQUIC.Validate(); err != nil {
+		t.Errorf("Expected QUIC tuning to be valid, got %v", err)
+	}
+	badQUIC := []QUICConfig{
+		{MaxIncomingStreams: -1},
+		{InitialStreamReceiveWindow: 8 << 20, MaxStreamReceiveWindow: 1 << 20},
+		{InitialConnectionReceiveWindow: 8 << 20, MaxConnectionReceiveWindow: 1 << 20},
+		{MaxStreamReceiveWindow: 32 << 20, MaxConnectionReceiveWindow: 8 << 20},
+		{MaxConnectionReceiveWindow: 1<<62 + 1},
+		{HandshakeIdleTimeout: -time.Second},
+		{MaxIdleTimeout: 10 * time.Second, KeepAlivePeriod: 10 * time.Second},
+	}
+	for _, q := range badQUIC {
+		cQUIC.Server.QUIC = q
+		if err := cQUIC.Validate(); err == nil {
+			t.Errorf("Expected validation to fail for quic config %+v", q)
+		}
+	}
+}
+
+func TestConfig_ValidateAggregatesErrors(t *testing.T) {
+	c := Config{}
+	c.ApplyDefaults()
+	c.Server.Protocol = "spdy"
+	c.Logger.Level = "verbose"
+	c.Server.TLS.Enabled = true
+	c.Server.KeepAlive = -5
+	c.Server.QUIC.MaxIncomingStreams = -1
+	c.Redis.MinIdleConns = 20
+	c.Redis.PoolSize = 10
+	c.Logger.Format = "xml"
+	c.API.JWT.Secret = "short"
+	c.API.CORS.AllowCredentials = true
+	c.Monitoring.TraceProvider = "zipkin"
+	c.Monitoring.TraceSampleRate = SampleRate(1.5)
+
+	err := c.Validate()
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("Expected *ValidationError, got %T: %v", err, err)
+	}
+
+	fields := make(map[string]bool)
+	for _, fe := range verr.Errors {
+		fields[fe.Field] = true
+	}
+	for _, want := range []string{
+		"server.protocol", "logger.level", "server.tls.cert_file", "server.tls.key_file",
+		"server.keep_alive", "server.quic.max_incoming_streams",
+		"redis.min_idle_conns", "logger.format", "api.jwt.secret",
+		"api.cors.allowed_origins", "monitoring.trace_provider", "monitoring.trace_sample_rate",
+	} {
+		if !fields[want] {
+			t.Errorf("missing error for %s in %v", want, verr.Errors)
+		}
+	}
+	if !strings.HasPrefix(err.Error(), strconv.Itoa(len(verr.Errors))+" config errors:") {
+		t.Errorf("unexpected message: %s", err)
+	}
+
+	// 單一錯誤時只輸出該項
+	c = Config{}
+	c.ApplyDefaults()
+	c.Server.AltSvcMaxAge = -1
+	if err := c.Validate(); err == nil || err.Error() != "server.alt_svc_max_age: must not be negative" {
+		t.Errorf("unexpected single error: %v", err)
+	}
+}
+
+func TestParseTLSVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		want    uint16
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"1.2", tls.VersionTLS12, false},
+		{"1.3", tls.VersionTLS13, false},
+		{"TLS1.3", tls.VersionTLS13, false},
+		{"tls 1.2", tls.VersionTLS12, false},
+		{"1.4", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			got, err := ParseTLSVersion(tt.version)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTLSVersion(%q) error = %v, wantErr %v", tt.version, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseTLSVersion(%q) = %x, want %x", tt.version, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseCipherSuites(t *testing.T) {
+	ids, err := ParseCipherSuites(nil)
+	if err != nil || ids != nil {
+		t.Errorf("ParseCipherSuites(nil) = %v, %v; want nil, nil", ids, err)
+	}
+
+	ids, err = ParseCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256 {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	if _, err := ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"}); err == nil {
+		t.Error("expected insecure cipher suite to be rejected")
+	}
+	if _, err := ParseCipherSuites([]string{"nope"}); err == nil {
+		t.Error("expected unknown cipher suite to be rejected")
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	nets, err := ParseTrustedProxies([]string{"10.0.0.0/8", "192.168.1.5", "::1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nets) != 3 {
+		t.Fatalf("expected 3 networks, got %d", len(nets))
+	}
+	if ones, _ := nets[1].Mask.Size(); ones != 32 {
+		t.Errorf("bare IPv4 should be /32, got /%d", ones)
+	}
+	if ones, _ := nets[2].Mask.Size(); ones != 128 {
+		t.Errorf("bare IPv6 should be /128, got /%d", ones)
+	}
+
+	if _, err := ParseTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected error for invalid CIDR")
+	}
 }
@@ -0,0 +1,116 @@
+// @chris
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ===== 環境 Profile =====
+
+// EnvProfile 選擇配置 profile（dev / staging / prod…）的環境變數
+const EnvProfile = "HYPGO_ENV"
+
+// LoadProfile 載入基礎配置並疊加 env 的 profile 後解析到 config，合併後才套用預設值與驗證
+// 基礎檔為 configPath 下的 config.yaml（configPath 本身為 .yaml / .yml 檔時即為該檔），
+// profile 檔為同目錄的 config.<env>.yaml；env 為空時使用 HYPGO_ENV，仍為空則只載入基礎檔。
+// 指定了 env 但 profile 檔不存在時回傳錯誤，避免拼錯環境名稱而默默使用基礎配置。
+//
+// 深度合併規則：map 逐鍵合併；純量與清單（如 CORS allowed_origins）整個取代；
+// 值為 null 時移除基礎檔的設定，回到預設值
+//
+// EX：
+//
+//	# config/config.yaml
+//	server:
+//	  addr: ":8080"
+//	api:
+//	  cors:
+//	    allowed_origins: ["http://localhost:3000"]
+//
+//	# config/config.prod.yaml
+//	api:
+//	  cors:
+//	    allowed_origins: ["https://example.com"]
+//
+//	cfg := &config.Config{}
+//	err := config.NewConfigLoader("config").LoadProfile(os.Getenv("HYPGO_ENV"), cfg)
+func (cl *ConfigLoader) LoadProfile(env string, config interface{}) error {
+	base := cl.configPath
+	if ext := filepath.Ext(base); ext != ".yaml" && ext != ".yml" {
+		base = filepath.Join(base, "config.yaml")
+	}
+	data, err := readProfile(base, env)
+	if err != nil {
+		return err
+	}
+	return decodeConfig(data, config)
+}
+
+// ProfilePath 基礎檔 base 對應 env 的 profile 檔路徑（config/config.yaml → config/config.prod.yaml）
+func ProfilePath(base, env string) string {
+	ext := filepath.Ext(base)
+	return strings.TrimSuffix(base, ext) + "." + env + ext
+}
+
+// readProfile 讀取基礎檔並疊加 profile，回傳合併後的 YAML
+func readProfile(base, env string) ([]byte, error) {
+	if env == "" {
+		env = os.Getenv(EnvProfile)
+	}
+	data, err := os.ReadFile(base)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", base, err)
+	}
+	if env == "" {
+		return data, nil
+	}
+	if strings.ContainsAny(env, `/\`) || strings.Contains(env, "..") {
+		return nil, fmt.Errorf("invalid config profile %q", env)
+	}
+
+	overlayFile := ProfilePath(base, env)
+	overlayData, err := os.ReadFile(overlayFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config profile %q: %w", env, err)
+	}
+
+	var merged, overlay map[string]interface{}
+	if err := yaml.Unmarshal(data, &merged); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config %s: %w", base, err)
+	}
+	if err := yaml.Unmarshal(overlayData, &overlay); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config profile %s: %w", overlayFile, err)
+	}
+	if merged == nil {
+		merged = make(map[string]interface{})
+	}
+	mergeConfigMaps(merged, overlay)
+
+	out, err := yaml.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge config profile %q: %w", env, err)
+	}
+	return out, nil
+}
+
+// mergeConfigMaps 將 overlay 深度合併到 dst：map 遞迴合併，其餘取代，null 刪除
+func mergeConfigMaps(dst, overlay map[string]interface{}) {
+	for key, val := range overlay {
+		if val == nil {
+			delete(dst, key)
+			continue
+		}
+		src, srcIsMap := val.(map[string]interface{})
+		cur, curIsMap := dst[key].(map[string]interface{})
+		if srcIsMap && curIsMap {
+			mergeConfigMaps(cur, src)
+			continue
+		}
+		dst[key] = val
+	}
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const profileBaseYAML = `
+server:
+  addr: :8080
+  read_timeout: 30s
+logger:
+  level: debug
+  format: text
+api:
+  cors:
+    enabled: true
+    allowed_origins: ["http://localhost:3000", "http://localhost:5173"]
+    max_age: 600
+`
+
+func writeProfileFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadProfile(t *testing.T) {
+	dir := writeProfileFiles(t, map[string]string{
+		"config.yaml": profileBaseYAML,
+		"config.prod.yaml": `
+server:
+  addr: ":9090"
+logger:
+  level: warning
+  format: null
+api:
+  cors:
+    allowed_origins: ["https://example.com"]
+`,
+	})
+
+	cfg := &Config{}
+	if err := NewConfigLoader(dir).LoadProfile("prod", cfg); err != nil {
+		t.Fatalf("LoadProfile: %v", err)
+	}
+	if cfg.Server.Addr != ":9090" || cfg.Server.ReadTimeout != 30*time.Second {
+		t.Errorf("Expected overlay addr with base read_timeout, got %+v", cfg.Server)
+	}
+	if cfg.Logger.Level != "warning" || cfg.Logger.Format != "json" {
+		t.Errorf("Expected overlay level and null to restore default format, got %+v", cfg.Logger)
+	}
+	// 清單整個取代，同層其他鍵保留
+	if !reflect.DeepEqual(cfg.API.CORS.AllowedOrigins, []string{"https://example.com"}) || !cfg.API.CORS.Enabled || cfg.API.CORS.MaxAge != 600 {
+		t.Errorf("Unexpected merged cors: %+v", cfg.API.CORS)
+	}
+
+	// 未指定 env 時使用 HYPGO_ENV；都沒有時只載入基礎檔
+	t.Setenv(EnvProfile, "prod")
+	cfg = &Config{}
+	if err := NewConfigLoader(filepath.Join(dir, "config.yaml")).LoadProfile("", cfg); err != nil || cfg.Server.Addr != ":9090" {
+		t.Errorf("Expected HYPGO_ENV profile, got addr %q err %v", cfg.Server.Addr, err)
+	}
+	if cfg, err := LoadConfig(filepath.Join(dir, "config.yaml")); err != nil || cfg.Logger.Level != "warning" {
+		t.Errorf("Expected LoadConfig to honor HYPGO_ENV, got %+v err %v", cfg, err)
+	}
+	t.Setenv(EnvProfile, "")
+	cfg = &Config{}
+	if err := NewConfigLoader(dir).LoadProfile("", cfg); err != nil || cfg.Server.Addr != ":8080" {
+		t.Errorf("Expected base config only, got addr %q err %v", cfg.Server.Addr, err)
+	}
+}
+
+func TestLoadProfileErrors(t *testing.T) {
+	dir := writeProfileFiles(t, map[string]string{
+		"config.yaml":         profileBaseYAML,
+		"config.broken.yaml":  "server: [",
+		"config.invalid.yaml": "server:\n  protocol: carrier-pigeon\n",
+	})
+	loader := NewConfigLoader(dir)
+
+	tests := map[string]string{
+		"prdo":    `config profile "prdo"`,
+		"broken":  "config.broken.yaml",
+		"invalid": "config validation failed",
+		"../etc":  "invalid config profile",
+	}
+	for env, want := range tests {
+		err := loader.LoadProfile(env, &Config{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadProfile(%q): expected error containing %q, got %v", env, want, err)
+		}
+	}
+}
+
+func TestReadConfigProfile(t *testing.T) {
+	dir := writeProfileFiles(t, map[string]string{
+		"config.yaml":         profileBaseYAML,
+		"config.staging.yaml": "server:\n  protocol: carrier-pigeon\n",
+	})
+	// 不驗證：仍可檢視無效的合併結果
+	cfg, err := ReadConfigProfile(filepath.Join(dir, "config.yaml"), "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Server.Protocol != "carrier-pigeon" || cfg.Server.Addr != ":8080" {
+		t.Errorf("Unexpected merged server config: %+v", cfg.Server)
+	}
+	if ProfilePath("config/config.yaml", "prod") != filepath.Join("config", "config.prod.yaml") {
+		t.Errorf("Unexpected profile path %q", ProfilePath("config/config.yaml", "prod"))
+	}
+}
@@ -0,0 +1,40 @@
+// @chris
+package config
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ParseTrustedProxies 將 trusted_proxies 設定解析為網段列表
+// 接受 CIDR（如 "10.0.0.0/8"）或單一 IP（視為 /32 或 /128）
+func ParseTrustedProxies(entries []string) ([]*net.IPNet, error) {
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	nets := make([]*net.IPNet, 0, len(entries))
+	for _, entry := range entries {
+		e := strings.TrimSpace(entry)
+		if !strings.Contains(e, "/") {
+			ip := net.ParseIP(e)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy: %q", entry)
+			}
+			bits := 128
+			if v4 := ip.To4(); v4 != nil {
+				ip, bits = v4, 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipNet, err := net.ParseCIDR(e)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy: %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
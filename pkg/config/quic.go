@@ -0,0 +1,97 @@
+// @chris
+package config
+
+import "time"
+
+// QUICConfig HTTP/3（QUIC）調校參數，零值沿用 quic-go 預設
+//
+// 調校建議：
+//   - 高延遲 / 高頻寬鏈路（跨洲、衛星）：調高 max_stream_receive_window 與
+//     max_connection_receive_window（BDP = 頻寬 × RTT），避免流量控制限制吞吐
+//   - 大量並行請求（gRPC、多資源頁面）：調高 max_incoming_streams
+//   - 行動網路：max_idle_timeout 可延長以減少重連，keep_alive_period 需小於 NAT 逾時（約 30s）
+//   - 記憶體受限：降低接收視窗，每連線最壞佔用約 max_connection_receive_window
+type QUICConfig struct {
+	MaxIncomingStreams    int64 `mapstructure:"max_incoming_streams" yaml:"max_incoming_streams"`         // 每連線雙向串流上限（預設 100）
+	MaxIncomingUniStreams int64 `mapstructure:"max_incoming_uni_streams" yaml:"max_incoming_uni_streams"` // 單向串流上限（預設 100）
+
+	// 流量控制視窗（位元組），initial 需 <= max
+	InitialStreamReceiveWindow     uint64 `mapstructure:"initial_stream_receive_window" yaml:"initial_stream_receive_window"`         // 預設 512KB
+	MaxStreamReceiveWindow         uint64 `mapstructure:"max_stream_receive_window" yaml:"max_stream_receive_window"`                 // 預設 6MB
+	InitialConnectionReceiveWindow uint64 `mapstructure:"initial_connection_receive_window" yaml:"initial_connection_receive_window"` // 預設 512KB
+	MaxConnectionReceiveWindow     uint64 `mapstructure:"max_connection_receive_window" yaml:"max_connection_receive_window"`         // 預設 15MB
+
+	// 閒置逾時：與 server.idle_timeout（TCP）分開，QUIC 連線只受此值影響（預設 30s）
+	MaxIdleTimeout       time.Duration `mapstructure:"max_idle_timeout" yaml:"max_idle_timeout"`
+	HandshakeIdleTimeout time.Duration `mapstructure:"handshake_idle_timeout" yaml:"handshake_idle_timeout"` // 預設 5s
+	KeepAlivePeriod      time.Duration `mapstructure:"keep_alive_period" yaml:"keep_alive_period"`           // 0 為不送 keep-alive
+
+	// HTTP/3 datagram（RFC 9297），WebTransport 等擴充需要
+	EnableDatagrams bool `mapstructure:"enable_datagrams" yaml:"enable_datagrams"`
+
+	// 允許 0-RTT：回訪客戶端可在握手完成前送出請求，省下一個 RTT。
+	// 0-RTT 資料可被攻擊者截取後重放，伺服器只對 GET / HEAD / OPTIONS 接受 early data，
+	// 其餘方法回傳 425 Too Early 要求客戶端於握手後重送；有副作用的 GET 需自行以 Context.Is0RTT() 拒絕
+	Allow0RTT bool `mapstructure:"allow_0rtt" yaml:"allow_0rtt"`
+}
+
+// QUIC 協定允許的上限
+const (
+	maxQUICStreams = 1 << 60
+	maxQUICWindow  = 1 << 62
+)
+
+// Validate 檢查 QUIC 參數範圍，回傳 *ValidationError
+func (q QUICConfig) Validate() error {
+	var v ValidationError
+	q.validate(&v)
+	return v.err()
+}
+
+// validate 將所有問題記錄到 v（供 Config.Validate 彙總）
+func (q QUICConfig) validate(v *ValidationError) {
+	const prefix = "server.quic."
+	if q.MaxIncomingStreams < 0 || q.MaxIncomingStreams > maxQUICStreams {
+		v.addf(prefix+"max_incoming_streams", "must be between 0 and 2^60")
+	}
+	if q.MaxIncomingUniStreams < 0 || q.MaxIncomingUniStreams > maxQUICStreams {
+		v.addf(prefix+"max_incoming_uni_streams", "must be between 0 and 2^60")
+	}
+
+	windows := []struct {
+		name             string
+		initial, maximum uint64
+	}{
+		{"stream", q.InitialStreamReceiveWindow, q.MaxStreamReceiveWindow},
+		{"connection", q.InitialConnectionReceiveWindow, q.MaxConnectionReceiveWindow},
+	}
+	for _, w := range windows {
+		if w.initial > maxQUICWindow || w.maximum > maxQUICWindow {
+			v.addf(prefix+"max_"+w.name+"_receive_window", "receive window exceeds 2^62")
+		}
+		if w.initial > 0 && w.maximum > 0 && w.initial > w.maximum {
+			v.addf(prefix+"initial_"+w.name+"_receive_window", "must not exceed max_%s_receive_window", w.name)
+		}
+	}
+	if q.MaxStreamReceiveWindow > 0 && q.MaxConnectionReceiveWindow > 0 &&
+		q.MaxStreamReceiveWindow > q.MaxConnectionReceiveWindow {
+		v.addf(prefix+"max_stream_receive_window", "must not exceed max_connection_receive_window")
+	}
+
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"max_idle_timeout", q.MaxIdleTimeout},
+		{"handshake_idle_timeout", q.HandshakeIdleTimeout},
+		{"keep_alive_period", q.KeepAlivePeriod},
+	}
+	for _, t := range timeouts {
+		if t.d < 0 {
+			v.addf(prefix+t.name, "must not be negative")
+		}
+	}
+	if q.KeepAlivePeriod > 0 && q.MaxIdleTimeout > 0 && q.KeepAlivePeriod >= q.MaxIdleTimeout {
+		v.addf(prefix+"keep_alive_period", "must be shorter than max_idle_timeout")
+	}
+}
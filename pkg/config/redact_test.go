@@ -0,0 +1,49 @@
+package config
+
+import "testing"
+
+func TestRedactDSN(t *testing.T) {
+	tests := []struct {
+		dsn, want string
+	}{
+		{"", ""},
+		{"postgres://app:hunter2@db:5432/app?sslmode=disable", "postgres://app:xxxxx@db:5432/app?sslmode=disable"},
+		{"postgres://app@db:5432/app", "postgres://app@db:5432/app"},
+		{"app:p@ss@tcp(db:3306)/app?parseTime=true", "app:xxxxx@tcp(db:3306)/app?parseTime=true"},
+		{"app@tcp(db:3306)/app", "app@tcp(db:3306)/app"},
+		{"host=db user=app password=hunter2 dbname=app", "host=db user=app password=xxxxx dbname=app"},
+		{"host=db password='a b' dbname=app", "host=db password=xxxxx dbname=app"},
+	}
+	for _, tt := range tests {
+		if got := RedactDSN(tt.dsn); got != tt.want {
+			t.Errorf("RedactDSN(%q) = %q, want %q", tt.dsn, got, tt.want)
+		}
+	}
+}
+
+func TestConfig_Redacted(t *testing.T) {
+	c := Config{}
+	c.Database.DSN = "postgres://app:hunter2@db/app"
+	c.Database.Replicas = []ReplicaConfig{{DSN: "app:hunter2@tcp(replica:3306)/app"}}
+	c.Database.Redis.Password = "redis-secret"
+	c.Redis.Password = "redis-secret"
+	c.API.JWT.Secret = "jwt-secret"
+
+	r := c.Redacted()
+	if r.Database.DSN != "postgres://app:xxxxx@db/app" || r.Database.Replicas[0].DSN != "app:xxxxx@tcp(replica:3306)/app" {
+		t.Errorf("DSNs not redacted: %q, %q", r.Database.DSN, r.Database.Replicas[0].DSN)
+	}
+	if r.Redis.Password != redactedSecret || r.Database.Redis.Password != redactedSecret || r.API.JWT.Secret != redactedSecret {
+		t.Errorf("secrets not redacted: %+v %+v", r.Redis, r.API.JWT)
+	}
+
+	// 原配置不受影響（包括共用底層陣列的 Replicas）
+	if c.Database.Replicas[0].DSN != "app:hunter2@tcp(replica:3306)/app" || c.API.JWT.Secret != "jwt-secret" {
+		t.Error("Redacted modified the original config")
+	}
+
+	// 未設定的值保持為空
+	if r := (Config{}).Redacted(); r.Redis.Password != "" || r.API.JWT.Secret != "" {
+		t.Errorf("empty secrets should stay empty, got %+v", r)
+	}
+}
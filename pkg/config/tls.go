@@ -0,0 +1,64 @@
+// @chris
+package config
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+)
+
+// ParseTLSVersion 將設定檔中的版本字串轉為 crypto/tls 常數
+// 接受 "1.0"、"1.1"、"1.2"、"1.3"（可帶 "TLS" 前綴，不分大小寫）；空字串回傳 0 表示未設定
+func ParseTLSVersion(version string) (uint16, error) {
+	v := strings.ToLower(strings.TrimSpace(version))
+	v = strings.TrimPrefix(v, "tls")
+	v = strings.TrimPrefix(v, "v")
+	v = strings.TrimSpace(v)
+
+	switch v {
+	case "":
+		return 0, nil
+	case "1.0", "10":
+		return tls.VersionTLS10, nil
+	case "1.1", "11":
+		return tls.VersionTLS11, nil
+	case "1.2", "12":
+		return tls.VersionTLS12, nil
+	case "1.3", "13":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("invalid tls min_version: %s, must be 1.0, 1.1, 1.2 or 1.3", version)
+	}
+}
+
+// ParseCipherSuites 將 IANA cipher suite 名稱轉為 crypto/tls ID
+// 僅接受 tls.CipherSuites() 列出的安全套件；不安全或未知的名稱回傳 error。
+// names 為空時回傳 nil，由呼叫端套用預設列表
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	secure := make(map[string]uint16)
+	for _, cs := range tls.CipherSuites() {
+		secure[cs.Name] = cs.ID
+	}
+	insecure := make(map[string]bool)
+	for _, cs := range tls.InsecureCipherSuites() {
+		insecure[cs.Name] = true
+	}
+
+	ids := make([]uint16, 0, len(names))
+	for _, name := range names {
+		n := strings.ToUpper(strings.TrimSpace(name))
+		if id, ok := secure[n]; ok {
+			ids = append(ids, id)
+			continue
+		}
+		if insecure[n] {
+			return nil, fmt.Errorf("insecure tls cipher suite not allowed: %s", name)
+		}
+		return nil, fmt.Errorf("unknown tls cipher suite: %s", name)
+	}
+	return ids, nil
+}
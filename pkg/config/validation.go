@@ -0,0 +1,68 @@
+// @chris
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ===== 驗證錯誤彙總 =====
+
+// FieldError 單一設定欄位的驗證失敗
+type FieldError struct {
+	Field   string // 設定檔路徑，如 "server.tls.cert_file"
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// ValidationError 彙總 Config.Validate 發現的所有問題，讓使用者一次修正
+// 可用 errors.As 取得並逐項檢視 Errors
+type ValidationError struct {
+	Errors []FieldError
+}
+
+// Error 單一問題時為 "field: message"，多個問題時逐行列出
+func (e *ValidationError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d config errors:", len(e.Errors))
+	for _, fe := range e.Errors {
+		b.WriteString("\n  - ")
+		b.WriteString(fe.Error())
+	}
+	return b.String()
+}
+
+// Unwrap 讓 errors.Is / errors.As 可比對個別 FieldError
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// addf 記錄一筆欄位錯誤
+func (e *ValidationError) addf(field, format string, args ...interface{}) {
+	e.Errors = append(e.Errors, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// addErr 記錄 err（nil 時忽略）
+func (e *ValidationError) addErr(field string, err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, FieldError{Field: field, Message: err.Error()})
+	}
+}
+
+// err 沒有任何問題時回傳 nil
+func (e *ValidationError) err() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
@@ -3,7 +3,10 @@ package context
 
 import (
 	"encoding/base64"
+	"html/template"
 	"strings"
+
+	"github.com/maoxiaoyue/hypgo/pkg/signing"
 )
 
 // ===== 認證相關方法 =====
@@ -32,12 +35,12 @@ func (c *Context) BasicAuth() (username, password string, ok bool) {
 	return cs[:s], cs[s+1:], true
 }
 
-// GetAuthToken 獲取 Bearer Token
+// GetAuthToken 獲取 Bearer Token（scheme 不分大小寫）
 func (c *Context) GetAuthToken() string {
 	auth := c.GetHeader("Authorization")
 	const prefix = "Bearer "
-	if strings.HasPrefix(auth, prefix) {
-		return auth[len(prefix):]
+	if len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) {
+		return strings.TrimSpace(auth[len(prefix):])
 	}
 	return ""
 }
@@ -60,7 +63,11 @@ func (c *Context) CheckAPIKey(headerName, expectedKey string) bool {
 	return c.GetAPIKey(headerName) == expectedKey
 }
 
-// GetJWT 獲取 JWT Token（從 Authorization header 或 cookie）
+// JWTCookieName GetJWT / SetJWT 使用的 cookie 名稱，應於啟動時設定
+var JWTCookieName = "jwt"
+
+// GetJWT 獲取 JWT Token
+// 依序從 Authorization: Bearer header、JWTCookieName cookie、token 查詢參數取得，皆無時回傳空字串
 func (c *Context) GetJWT() string {
 	// 先嘗試從 Authorization header 獲取
 	if token := c.GetAuthToken(); token != "" {
@@ -68,7 +75,7 @@ func (c *Context) GetJWT() string {
 	}
 
 	// 嘗試從 cookie 獲取
-	if token, err := c.Cookie("jwt"); err == nil && token != "" {
+	if token, err := c.Cookie(JWTCookieName); err == nil && token != "" {
 		return token
 	}
 
@@ -82,12 +89,17 @@ func (c *Context) GetJWT() string {
 
 // SetJWT 設置 JWT Token 到 cookie
 func (c *Context) SetJWT(token string, maxAge int) {
-	c.SetCookie("jwt", token, maxAge, "/", "", false, true)
+	c.SetCookie(JWTCookieName, token, maxAge, "/", "", false, true)
 }
 
 // ClearJWT 清除 JWT Token
 func (c *Context) ClearJWT() {
-	c.SetCookie("jwt", "", -1, "/", "", false, true)
+	c.SetCookie(JWTCookieName, "", -1, "/", "", false, true)
+}
+
+// BearerToken 獲取 Bearer Token（同 GetJWT）
+func (c *Context) BearerToken() string {
+	return c.GetJWT()
 }
 
 // GetOAuth2Token 獲取 OAuth2 Token
@@ -128,6 +140,13 @@ func (c *Context) HasRole(role string) bool {
 					return true
 				}
 			}
+		case []interface{}:
+			// JWT claims 解析後的角色陣列
+			for _, r := range v {
+				if s, ok := r.(string); ok && s == role {
+					return true
+				}
+			}
 		case string:
 			return v == role
 		}
@@ -135,6 +154,16 @@ func (c *Context) HasRole(role string) bool {
 	return false
 }
 
+// HasAnyRole 檢查是否具有任一角色
+func (c *Context) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		if c.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
 // SetRoles 設置用戶角色
 func (c *Context) SetRoles(roles []string) {
 	c.Set("roles", roles)
@@ -235,3 +264,79 @@ func (c *Context) SetAuthError(err string) {
 func (c *Context) GetAuthError() string {
 	return c.GetString("auth_error")
 }
+
+// ===== 身分識別 =====
+// 以下為 Get* 系列的簡短別名，作為認證中間件與 controller 之間的標準介面
+
+// UserID 獲取用戶 ID（同 GetUserID）
+func (c *Context) UserID() interface{} {
+	return c.GetUserID()
+}
+
+// User 獲取當前用戶（同 GetUser）
+func (c *Context) User() interface{} {
+	return c.GetUser()
+}
+
+// SetUsername 設置用戶名稱
+func (c *Context) SetUsername(username string) {
+	c.Set("username", username)
+}
+
+// Username 獲取用戶名稱；未設置時若 SetUser 存入的是字串則回傳該值
+func (c *Context) Username() string {
+	if name := c.GetString("username"); name != "" {
+		return name
+	}
+	return c.GetString("user")
+}
+
+// Roles 獲取用戶角色（同 GetRoles）
+func (c *Context) Roles() []string {
+	return c.GetRoles()
+}
+
+// TokenClaims 獲取 Token Claims（同 GetTokenClaims）
+func (c *Context) TokenClaims() interface{} {
+	return c.GetTokenClaims()
+}
+
+// AuthError 獲取認證錯誤（同 GetAuthError）
+func (c *Context) AuthError() string {
+	return c.GetAuthError()
+}
+
+// ===== CSRF =====
+
+// CSRFToken 獲取 CSRF 中間件簽發的 token，未掛載時回傳空字串
+func (c *Context) CSRFToken() string {
+	return c.GetString("csrf_token")
+}
+
+// CSRFField 產生表單用的隱藏欄位，可直接放入 html/template
+// EX：<form method="post">{{ .csrfField }}...</form>
+func (c *Context) CSRFField() template.HTML {
+	return template.HTML(`<input type="hidden" name="csrf_token" value="` +
+		template.HTMLEscapeString(c.CSRFToken()) + `">`)
+}
+
+// ===== 簽章網址 =====
+
+// VerifySignature 驗證目前請求網址的簽章與到期時間（由 signing.SignURL 產生）
+// 失敗時回傳 signing.ErrMissingSignature、signing.ErrInvalidSignature 或 signing.ErrExpired
+//
+// EX：
+//
+//	r.GET("/reset", func(c *context.Context) {
+//	    if err := c.VerifySignature(secret); err != nil {
+//	        c.AbortWithStatus(http.StatusForbidden)
+//	        return
+//	    }
+//	    userID := c.Query("user")
+//	})
+func (c *Context) VerifySignature(secret []byte) error {
+	if c.Request == nil || c.Request.URL == nil {
+		return signing.ErrMissingSignature
+	}
+	return signing.Verify(c.Request.URL, secret)
+}
@@ -11,6 +11,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"reflect"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -28,38 +30,26 @@ func (c *Context) BindJSON(obj interface{}) error {
 	return c.MustBindWith(obj, bindingJSON{})
 }
 
+// BindJSONStrict 以嚴格模式綁定 JSON（拒絕未知欄位、限制 body 大小）
+// 失敗時中止：body 過大回應 413，charset 不支援回應 415，其餘回應 400
+func (c *Context) BindJSONStrict(obj interface{}) error {
+	if err := c.ShouldBindJSONStrict(obj); err != nil {
+		c.AbortWithError(bindErrorStatus(err, http.StatusBadRequest), err).SetType(ErrorTypeBind)
+		return err
+	}
+	return nil
+}
+
 // BindXML 綁定 XML 資料到結構體
 func (c *Context) BindXML(obj interface{}) error {
 	return c.MustBindWith(obj, bindingXML{})
 }
 
-// BindQuery 綁定查詢參數到結構體
-func (c *Context) BindQuery(obj interface{}) error {
-	return c.MustBindWith(obj, bindingQuery{})
-}
-
 // BindYAML 綁定 YAML 資料到結構體
 func (c *Context) BindYAML(obj interface{}) error {
 	return c.MustBindWith(obj, bindingYAML{})
 }
 
-// BindHeader 綁定 Header 到結構體
-func (c *Context) BindHeader(obj interface{}) error {
-	return c.MustBindWith(obj, bindingHeader{})
-}
-
-// BindUri 綁定 URI 參數到結構體
-func (c *Context) BindUri(obj interface{}) error {
-	if c.Params != nil {
-		m := make(map[string][]string)
-		for _, p := range c.Params {
-			m[p.Key] = []string{p.Value}
-		}
-		return bindingUri{}.BindUri(m, obj)
-	}
-	return nil
-}
-
 // BindWith 綁定請求數據（舊版兼容）
 func (c *Context) BindWith(obj interface{}, b Binding) error {
 	return c.MustBindWith(obj, b)
@@ -68,7 +58,7 @@ func (c *Context) BindWith(obj interface{}, b Binding) error {
 // MustBindWith 強制綁定（失敗會 abort）
 func (c *Context) MustBindWith(obj interface{}, b Binding) error {
 	if err := c.ShouldBindWith(obj, b); err != nil {
-		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind)
+		c.AbortWithError(bindErrorStatus(err, http.StatusBadRequest), err).SetType(ErrorTypeBind)
 		return err
 	}
 	return nil
@@ -87,44 +77,27 @@ func (c *Context) ShouldBindJSON(obj interface{}) error {
 	return c.ShouldBindWith(obj, bindingJSON{})
 }
 
+// ShouldBindJSONStrict 以嚴格模式嘗試綁定 JSON（不會 abort）
+func (c *Context) ShouldBindJSONStrict(obj interface{}) error {
+	return c.ShouldBindWith(obj, JSONStrict)
+}
+
 // ShouldBindXML 嘗試綁定 XML（不會 abort）
 func (c *Context) ShouldBindXML(obj interface{}) error {
 	return c.ShouldBindWith(obj, bindingXML{})
 }
 
-// ShouldBindQuery 嘗試綁定查詢參數（不會 abort）
-func (c *Context) ShouldBindQuery(obj interface{}) error {
-	return c.ShouldBindWith(obj, bindingQuery{})
-}
-
 // ShouldBindYAML 嘗試綁定 YAML（不會 abort）
 func (c *Context) ShouldBindYAML(obj interface{}) error {
 	return c.ShouldBindWith(obj, bindingYAML{})
 }
 
-// ShouldBindHeader 嘗試綁定 Header（不會 abort）
-func (c *Context) ShouldBindHeader(obj interface{}) error {
-	return c.ShouldBindWith(obj, bindingHeader{})
-}
-
-// ShouldBindUri 嘗試綁定 URI 參數（不會 abort）
-func (c *Context) ShouldBindUri(obj interface{}) error {
-	if c.Params != nil {
-		m := make(map[string][]string)
-		for _, p := range c.Params {
-			m[p.Key] = []string{p.Value}
-		}
-		return bindingUri{}.BindUri(m, obj)
-	}
-	return nil
-}
-
 // ShouldBindWith 使用指定的綁定器綁定
 func (c *Context) ShouldBindWith(obj interface{}, b Binding) error {
 	return b.Bind(c.Request, obj)
 }
 
-// ShouldBindWithQuery 使用查詢綁定器
+// ShouldBindWithQuery 使用查詢綁定器（不驗證）
 func (c *Context) ShouldBindWithQuery(obj interface{}) error {
 	return c.ShouldBindWith(obj, bindingQuery{})
 }
@@ -145,6 +118,9 @@ func (c *Context) ShouldBindBodyWith(obj interface{}, bb BindingBody) error {
 		c.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
 	}
 
+	if body, err = decodeBodyCharset(c.Request, body); err != nil {
+		return err
+	}
 	return bb.BindBody(body, obj)
 }
 
@@ -228,6 +204,7 @@ func (defaultBinding) Default(method, contentType string) Binding {
 // 各種綁定器常量
 var (
 	JSON          = bindingJSON{}
+	JSONStrict    = bindingJSONStrict{}
 	XML           = bindingXML{}
 	Form          = bindingForm{}
 	Query         = bindingQuery{}
@@ -252,7 +229,11 @@ func (bindingJSON) Bind(req *http.Request, obj interface{}) error {
 	if req == nil || req.Body == nil {
 		return fmt.Errorf("invalid request")
 	}
-	return decodeJSON(req.Body, obj)
+	body, err := requestBody(req, req.Body)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(body, obj)
 }
 
 func (bindingJSON) BindBody(body []byte, obj interface{}) error {
@@ -269,7 +250,24 @@ func (bindingXML) Bind(req *http.Request, obj interface{}) error {
 	if req.Body == nil {
 		return fmt.Errorf("invalid request")
 	}
-	return xml.NewDecoder(req.Body).Decode(obj)
+	body, err := requestBody(req, req.Body)
+	if err != nil {
+		return err
+	}
+	decoder := xml.NewDecoder(body)
+	// 標頭已宣告 charset 時 body 已轉為 UTF-8，忽略 XML 宣告的 encoding；否則依 XML 宣告解碼
+	converted := !isUTF8Charset(requestCharset(req))
+	decoder.CharsetReader = func(label string, input io.Reader) (io.Reader, error) {
+		if converted || isUTF8Charset(strings.ToLower(label)) {
+			return input, nil
+		}
+		enc, err := lookupCharset(strings.ToLower(label))
+		if err != nil {
+			return nil, err
+		}
+		return enc.NewDecoder().Reader(input), nil
+	}
+	return decoder.Decode(obj)
 }
 
 func (bindingXML) BindBody(body []byte, obj interface{}) error {
@@ -286,7 +284,7 @@ func (bindingForm) Bind(req *http.Request, obj interface{}) error {
 	if err := req.ParseForm(); err != nil {
 		return err
 	}
-	if err := req.ParseMultipartForm(defaultMemory); err != nil && !errors.Is(err, http.ErrNotMultipart) {
+	if err := req.ParseMultipartForm(maxMultipartMemory(req)); err != nil && !errors.Is(err, http.ErrNotMultipart) {
 		return err
 	}
 	return mapFormToStruct(req.Form, obj)
@@ -323,7 +321,7 @@ type bindingFormMultipart struct{}
 func (bindingFormMultipart) Name() string { return "multipart/form-data" }
 
 func (bindingFormMultipart) Bind(req *http.Request, obj interface{}) error {
-	if err := req.ParseMultipartForm(defaultMemory); err != nil {
+	if err := req.ParseMultipartForm(maxMultipartMemory(req)); err != nil {
 		return err
 	}
 	return mapFormToStruct(req.PostForm, obj)
@@ -371,7 +369,11 @@ func (bindingYAML) Bind(req *http.Request, obj interface{}) error {
 	if req.Body == nil {
 		return fmt.Errorf("invalid request")
 	}
-	return yaml.NewDecoder(req.Body).Decode(obj)
+	body, err := requestBody(req, req.Body)
+	if err != nil {
+		return err
+	}
+	return yaml.NewDecoder(body).Decode(obj)
 }
 
 func (bindingYAML) BindBody(body []byte, obj interface{}) error {
@@ -384,8 +386,9 @@ type bindingUri struct{}
 
 func (bindingUri) Name() string { return "uri" }
 
+// BindUri 依 `uri` tag 綁定（不驗證）
 func (bindingUri) BindUri(m map[string][]string, obj interface{}) error {
-	return mapFormToStruct(url.Values(m), obj)
+	return mapTagged(m, obj, "uri", nil)
 }
 
 // ===== Header 綁定器 =====
@@ -394,24 +397,95 @@ type bindingHeader struct{}
 
 func (bindingHeader) Name() string { return "header" }
 
+// Bind 依 `header` tag 綁定（名稱不分大小寫，不驗證）
 func (bindingHeader) Bind(req *http.Request, obj interface{}) error {
-	return mapFormToStruct(url.Values(req.Header), obj)
+	return mapTagged(req.Header, obj, "header", http.CanonicalHeaderKey)
 }
 
 // ===== 輔助函數 =====
 
+// EnableDecoderDisallowUnknownFields 為 true 時預設 JSON 綁定器也拒絕未知欄位（預設寬鬆）
+var EnableDecoderDisallowUnknownFields = false
+
+// StrictJSONMaxBytes 嚴格 JSON 綁定器允許的最大 body 大小（預設 1MB，<= 0 表示不限制）
+var StrictJSONMaxBytes int64 = 1 << 20
+
+// ErrBodyTooLarge 請求 body 超過大小限制
+var ErrBodyTooLarge = errors.New("request body too large")
+
 // decodeJSON 解碼 JSON
 func decodeJSON(r io.Reader, obj interface{}) error {
 	decoder := json.NewDecoder(r)
+	if EnableDecoderDisallowUnknownFields {
+		decoder.DisallowUnknownFields()
+	}
 	return decoder.Decode(obj)
 }
 
-// mapFormToStruct 將表單映射到結構體（簡化版）
+// ===== 嚴格 JSON 綁定器 =====
+
+type bindingJSONStrict struct{}
+
+func (bindingJSONStrict) Name() string { return "json-strict" }
+
+func (bindingJSONStrict) Bind(req *http.Request, obj interface{}) error {
+	if req == nil || req.Body == nil {
+		return fmt.Errorf("invalid request")
+	}
+	if StrictJSONMaxBytes > 0 && req.ContentLength > StrictJSONMaxBytes {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrBodyTooLarge, req.ContentLength, StrictJSONMaxBytes)
+	}
+
+	body := io.Reader(req.Body)
+	if StrictJSONMaxBytes > 0 {
+		// 多讀 1 byte 以偵測未宣告 Content-Length 的超量 body
+		body = io.LimitReader(req.Body, StrictJSONMaxBytes+1)
+	}
+	body, err := requestBody(req, body)
+	if err != nil {
+		return err
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if StrictJSONMaxBytes > 0 && int64(len(data)) > StrictJSONMaxBytes {
+		return fmt.Errorf("%w: exceeds limit of %d bytes", ErrBodyTooLarge, StrictJSONMaxBytes)
+	}
+	return decodeJSONStrict(data, obj)
+}
+
+func (bindingJSONStrict) BindBody(body []byte, obj interface{}) error {
+	if StrictJSONMaxBytes > 0 && int64(len(body)) > StrictJSONMaxBytes {
+		return fmt.Errorf("%w: exceeds limit of %d bytes", ErrBodyTooLarge, StrictJSONMaxBytes)
+	}
+	return decodeJSONStrict(body, obj)
+}
+
+// decodeJSONStrict 拒絕未知欄位與尾端多餘資料
+func decodeJSONStrict(data []byte, obj interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		// encoding/json 的錯誤格式為 `json: unknown field "name"`
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("unknown field %s in request body", field)
+		}
+		return err
+	}
+	if decoder.More() {
+		return errors.New("unexpected data after JSON value")
+	}
+	return nil
+}
+
+// mapFormToStruct 將表單 / 查詢參數依 `form` tag 映射到結構體（見 mapTagged）
+// obj 不是 struct 指標（如 *map[string]interface{}）時以 JSON 作為中間格式
 func mapFormToStruct(values url.Values, obj interface{}) error {
-	// 這是一個簡化的實現
-	// 實際專案中建議使用成熟的庫如 gorilla/schema 或 mapstructure
+	if rv := reflect.ValueOf(obj); rv.Kind() == reflect.Ptr && !rv.IsNil() && rv.Elem().Kind() == reflect.Struct {
+		return mapTagged(values, obj, "form", nil)
+	}
 
-	// 使用 JSON 作為中間格式（簡化實現）
 	data := make(map[string]interface{})
 	for k, v := range values {
 		if len(v) == 1 {
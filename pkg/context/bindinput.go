@@ -60,6 +60,7 @@ func (c *Context) BindInputCalled() bool {
 //
 // 失敗時自動以 Error Catalog 形狀的 JSON 中斷請求並回傳 false：
 //   - 解析失敗 → 400 {"code":"E0002","message":"Bad request"}
+//   - charset 不支援 → 415 {"code":"E0006","message":"Unsupported media type"}
 //   - 驗證失敗 → 422 {"code":"E1001","message":"Validation failed","details":{欄位:訊息}}
 //
 // 成功回傳 true，呼叫端可直接使用 obj。典型用法：
@@ -88,6 +89,11 @@ func (c *Context) BindInput(obj interface{}) bool {
 
 	// 2. 依 Content-Type 解析（JSON / form / query 等）
 	if err := c.ShouldBind(obj); err != nil {
+		if stderrors.Is(err, ErrUnsupportedCharset) {
+			c.abortBindError(http.StatusUnsupportedMediaType, "E0006", "Unsupported media type",
+				map[string]interface{}{"reason": err.Error()})
+			return false
+		}
 		c.abortBindError(http.StatusBadRequest, "E0002", "Bad request",
 			map[string]interface{}{"reason": err.Error()})
 		return false
@@ -0,0 +1,344 @@
+// @chris
+package context
+
+import (
+	"encoding"
+	stderrors "errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	hypvalidate "github.com/maoxiaoyue/hypgo/pkg/validate"
+)
+
+// ===== Header / URI 綁定 =====
+
+// BindFieldError 標頭、路徑或查詢參數無法轉換為欄位型別
+type BindFieldError struct {
+	Source string // "header"、"uri" 或 "form"
+	Name   string // tag 指定的名稱，如 "X-Page-Size"、"id"、"page"
+	Value  string
+	Type   reflect.Type
+	Err    error
+}
+
+func (e *BindFieldError) Error() string {
+	return fmt.Sprintf("%s %q: cannot convert %q to %s", e.Source, e.Name, e.Value, e.Type)
+}
+
+func (e *BindFieldError) Unwrap() error { return e.Err }
+
+// BindHeader 依 `header:"X-..."` tag 綁定請求標頭並執行 validate tag 驗證
+// 失敗時與 BindAndValidate 相同，以 BindErrorRenderer 回應 422（型別不符或驗證失敗）並中止
+//
+// EX：
+//
+//	var req struct {
+//	    Lang     string `header:"Accept-Language,default=en"`
+//	    PageSize int    `header:"X-Page-Size" validate:"max=100"`
+//	}
+//	if err := c.BindHeader(&req); err != nil {
+//	    return
+//	}
+func (c *Context) BindHeader(obj interface{}) error {
+	return c.abortOnTaggedBindError(c.ShouldBindHeader(obj))
+}
+
+// ShouldBindHeader 依 `header` tag 綁定請求標頭並驗證（不會 abort）
+func (c *Context) ShouldBindHeader(obj interface{}) error {
+	if err := (bindingHeader{}).Bind(c.Request, obj); err != nil {
+		return err
+	}
+	return hypvalidate.Struct(obj)
+}
+
+// BindURI 依 `uri:"id"` tag 綁定路由參數（Params）並執行 validate tag 驗證
+// 失敗時與 BindAndValidate 相同，以 BindErrorRenderer 回應 422（型別不符或驗證失敗）並中止
+//
+// EX：
+//
+//	r.GET("/users/:id", func(c *context.Context) {
+//	    var req struct {
+//	        ID int `uri:"id" validate:"min=1"`
+//	    }
+//	    if err := c.BindURI(&req); err != nil {
+//	        return
+//	    }
+//	})
+func (c *Context) BindURI(obj interface{}) error {
+	return c.abortOnTaggedBindError(c.ShouldBindURI(obj))
+}
+
+// ShouldBindURI 依 `uri` tag 綁定路由參數並驗證（不會 abort）
+func (c *Context) ShouldBindURI(obj interface{}) error {
+	m := make(map[string][]string, len(c.Params))
+	for _, p := range c.Params {
+		m[p.Key] = []string{p.Value}
+	}
+	if err := (bindingUri{}).BindUri(m, obj); err != nil {
+		return err
+	}
+	return hypvalidate.Struct(obj)
+}
+
+// BindUri 綁定 URI 參數到結構體
+//
+// Deprecated: 使用 BindURI
+func (c *Context) BindUri(obj interface{}) error {
+	return c.BindURI(obj)
+}
+
+// ShouldBindUri 嘗試綁定 URI 參數（不會 abort）
+//
+// Deprecated: 使用 ShouldBindURI
+func (c *Context) ShouldBindUri(obj interface{}) error {
+	return c.ShouldBindURI(obj)
+}
+
+// abortOnTaggedBindError 以 BindErrorRenderer 寫出 header / uri 綁定錯誤並中止
+func (c *Context) abortOnTaggedBindError(err error) error {
+	if err == nil {
+		return nil
+	}
+	c.Error(&Error{Err: err, Type: ErrorTypeBind})
+
+	var fieldErr *BindFieldError
+	if stderrors.As(err, &fieldErr) {
+		bindErrorRenderer(c, http.StatusUnprocessableEntity, []FieldError{{
+			Field:   fieldErr.Name,
+			Message: fieldErr.Name + " must be of type " + fieldErr.Type.String(),
+		}})
+		return err
+	}
+	if errs := fieldErrors(err); len(errs) > 0 && errs[0].Field != "" {
+		bindErrorRenderer(c, http.StatusUnprocessableEntity, errs)
+		return err
+	}
+	bindErrorRenderer(c, http.StatusBadRequest, []FieldError{{Message: err.Error()}})
+	return err
+}
+
+// BindQuery 依 `form:"param"` tag 綁定查詢參數並執行 validate tag 驗證，取代一連串的 DefaultQuery
+// 支援純量、slice（重複參數 ?tag=a&tag=b 或逗號分隔 ?tag=a,b）、布林、time.Duration 與 time.Time
+// （RFC 3339 或 2006-01-02）；參數缺少或為空時套用 `default:"..."` tag。沒有 form tag 的欄位依序改用 json tag 名稱、欄位名稱
+// 失敗時與 BindHeader 相同，以 BindErrorRenderer 回應 422（型別不符或驗證失敗）並中止
+//
+// EX：
+//
+//	type ListUsers struct {
+//	    Page     int       `form:"page" default:"1" validate:"min=1"`
+//	    PerPage  int       `form:"per_page" default:"20" validate:"max=100"`
+//	    Sort     string    `form:"sort" default:"created_at" validate:"oneof=created_at name"`
+//	    Roles    []string  `form:"role"`
+//	    Active   *bool     `form:"active"`
+//	    Since    time.Time `form:"since"`
+//	}
+//	var q ListUsers
+//	if err := c.BindQuery(&q); err != nil {
+//	    return
+//	}
+func (c *Context) BindQuery(obj interface{}) error {
+	return c.abortOnTaggedBindError(c.ShouldBindQuery(obj))
+}
+
+// ShouldBindQuery 依 `form` tag 綁定查詢參數並驗證（不會 abort）
+func (c *Context) ShouldBindQuery(obj interface{}) error {
+	if err := (bindingQuery{}).Bind(c.Request, obj); err != nil {
+		return err
+	}
+	return hypvalidate.Struct(obj)
+}
+
+// ===== tag 映射 =====
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// mapTagged 依 tag 將 values 寫入 obj 的欄位，key 經 canonical 正規化（nil 表示原樣比對）
+// tag 格式為 `name` 或 `name,default=值`，預設值也可寫在獨立的 `default:"值"` tag；
+// 缺少或為空的值保持零值（或套用預設值），匿名嵌入的 struct 會遞迴處理
+func mapTagged(values map[string][]string, obj interface{}, tag string, canonical func(string) string) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("%s binding requires a pointer to struct, got %T", tag, obj)
+	}
+	return mapTaggedStruct(values, rv.Elem(), tag, canonical)
+}
+
+func mapTaggedStruct(values map[string][]string, sv reflect.Value, tag string, canonical func(string) string) error {
+	st := sv.Type()
+	for i := 0; i < st.NumField(); i++ {
+		sf := st.Field(i)
+		fv := sv.Field(i)
+		spec, tagged, fold := fieldTag(sf, tag)
+
+		if !tagged && sf.Anonymous {
+			if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+				if fv.IsNil() {
+					if !fv.CanSet() {
+						continue
+					}
+					fv.Set(reflect.New(fv.Type().Elem()))
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				if err := mapTaggedStruct(values, fv, tag, canonical); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if !tagged || !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(spec, ",")
+		if name == "-" || name == "" {
+			continue
+		}
+		key := name
+		if canonical != nil {
+			key = canonical(name)
+		}
+		vals, ok := values[key]
+		if !ok && fold {
+			vals = lookupFold(values, key)
+		}
+		if len(vals) == 0 || len(vals) == 1 && vals[0] == "" {
+			def, ok := strings.CutPrefix(opts, "default=")
+			if !ok {
+				def, ok = sf.Tag.Lookup("default")
+			}
+			if !ok {
+				continue
+			}
+			vals = []string{def}
+		}
+		if err := setTaggedField(fv, vals); err != nil {
+			return &BindFieldError{Source: tag, Name: name, Value: strings.Join(vals, ","), Type: sf.Type, Err: err}
+		}
+	}
+	return nil
+}
+
+// fieldTag 取得欄位的 tag；form tag 缺少時依序退回 json tag 與欄位名稱，
+// 與先前以 JSON 中轉的表單綁定相容（匿名嵌入欄位仍遞迴處理）。
+// 退回的名稱與 encoding/json 相同不分大小寫比對（fold 為 true）
+func fieldTag(sf reflect.StructField, tag string) (spec string, tagged, fold bool) {
+	if spec, ok := sf.Tag.Lookup(tag); ok || tag != "form" || sf.Anonymous {
+		return spec, ok, false
+	}
+	if spec, ok := sf.Tag.Lookup("json"); ok {
+		if name, _, _ := strings.Cut(spec, ","); name != "" {
+			return name, true, true
+		}
+	}
+	return sf.Name, true, true
+}
+
+// lookupFold 不分大小寫取得 key 的值（?name=bob 對應欄位 Name）
+func lookupFold(values map[string][]string, key string) []string {
+	for k, v := range values {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// setTaggedField 將字串值轉換後寫入欄位；slice 欄位接受多個值，每個值也可以逗號分隔（空項略過）
+func setTaggedField(fv reflect.Value, vals []string) error {
+	if fv.Kind() == reflect.Ptr {
+		elem := reflect.New(fv.Type().Elem())
+		if err := setTaggedField(elem.Elem(), vals); err != nil {
+			return err
+		}
+		fv.Set(elem)
+		return nil
+	}
+	if fv.Kind() == reflect.Slice && !fv.Addr().Type().Implements(textUnmarshalerType) {
+		var items []string
+		for _, v := range vals {
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(fv.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := setScalar(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+		fv.Set(slice)
+		return nil
+	}
+	return setScalar(fv, vals[0])
+}
+
+// setScalar 轉換單一值
+func setScalar(fv reflect.Value, s string) error {
+	switch fv.Type() {
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	case timeType:
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			// 查詢參數常見只帶日期
+			var dateErr error
+			if t, dateErr = time.Parse(time.DateOnly, s); dateErr != nil {
+				return err
+			}
+		}
+		fv.Set(reflect.ValueOf(t))
+		return nil
+	}
+	if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported field type %s", fv.Type())
+	}
+	return nil
+}
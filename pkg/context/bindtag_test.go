@@ -0,0 +1,210 @@
+package context
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type tagBase struct {
+	Tenant string `header:"X-Tenant" uri:"tenant"`
+}
+
+type tagRequest struct {
+	tagBase
+	ID       int           `uri:"id" validate:"min=1"`
+	Active   *bool         `uri:"active"`
+	Lang     string        `header:"Accept-Language,default=en"`
+	PageSize uint16        `header:"x-page-size" validate:"max=100"`
+	Ratio    float64       `header:"X-Ratio"`
+	Timeout  time.Duration `header:"X-Timeout"`
+	Tags     []string      `header:"X-Tags"`
+	Ignored  string        `header:"-"`
+}
+
+func newTagContext(headers map[string]string, params Params) (*Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest("GET", "/", nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	c := New(w, req)
+	c.Params = params
+	return c, w
+}
+
+func TestBindHeaderConvertsTypes(t *testing.T) {
+	c, _ := newTagContext(map[string]string{
+		"X-Tenant":    "acme",
+		"X-Page-Size": "50",
+		"X-Ratio":     "0.25",
+		"X-Timeout":   "1.5s",
+		"X-Tags":      "a, b,c",
+	}, nil)
+	defer c.Release()
+
+	req := tagRequest{ID: 1}
+	if err := c.BindHeader(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.Tenant != "acme" || req.PageSize != 50 || req.Ratio != 0.25 || req.Timeout != 1500*time.Millisecond {
+		t.Errorf("unexpected binding %+v", req)
+	}
+	if len(req.Tags) != 3 || req.Tags[1] != "b" || req.Lang != "en" {
+		t.Errorf("expected split tags and default lang, got %q %q", req.Tags, req.Lang)
+	}
+}
+
+func TestBindURIConvertsTypesAndMissingValues(t *testing.T) {
+	c, _ := newTagContext(nil, Params{{Key: "id", Value: "42"}, {Key: "tenant", Value: "acme"}, {Key: "active", Value: "true"}})
+	defer c.Release()
+
+	var req tagRequest
+	if err := c.BindURI(&req); err != nil {
+		t.Fatal(err)
+	}
+	if req.ID != 42 || req.Tenant != "acme" || req.Active == nil || !*req.Active {
+		t.Errorf("unexpected binding %+v", req)
+	}
+
+	// 缺少的參數保持零值：active 未提供時為 nil，id 未提供時由 validate 擋下
+	c2, w := newTagContext(nil, nil)
+	defer c2.Release()
+	var missing tagRequest
+	if err := c2.BindURI(&missing); err == nil || missing.Active != nil {
+		t.Fatalf("expected validation error for missing id, got %v %+v", err, missing)
+	}
+	if w.Code != http.StatusUnprocessableEntity || !c2.IsAborted() {
+		t.Errorf("expected aborted 422, got %d", w.Code)
+	}
+	if errs := decodeFieldErrors(t, w.Body.Bytes()); len(errs) != 1 || errs[0].Field != "ID" {
+		t.Errorf("unexpected field errors %+v", errs)
+	}
+}
+
+func TestBindTaggedTypeErrors(t *testing.T) {
+	c, w := newTagContext(map[string]string{"X-Page-Size": "many"}, nil)
+	defer c.Release()
+
+	var req tagRequest
+	err := c.BindHeader(&req)
+	var fieldErr *BindFieldError
+	if !errors.As(err, &fieldErr) || fieldErr.Name != "x-page-size" || fieldErr.Value != "many" {
+		t.Fatalf("expected BindFieldError, got %v", err)
+	}
+	if w.Code != http.StatusUnprocessableEntity || len(c.Errors.ByType(ErrorTypeBind)) != 1 {
+		t.Errorf("expected 422 with a bind error, got %d %v", w.Code, c.Errors)
+	}
+	errs := decodeFieldErrors(t, w.Body.Bytes())
+	if len(errs) != 1 || errs[0].Message != "x-page-size must be of type uint16" {
+		t.Errorf("unexpected field errors %+v", errs)
+	}
+
+	// 超出範圍與非 struct 目標
+	c2, _ := newTagContext(nil, Params{{Key: "id", Value: "99999999999999999999"}})
+	defer c2.Release()
+	if err := c2.ShouldBindURI(&req); !errors.As(err, &fieldErr) {
+		t.Errorf("expected overflow error, got %v", err)
+	}
+	var n int
+	if err := c2.ShouldBindURI(&n); err == nil {
+		t.Error("expected error for non-struct target")
+	}
+}
+
+type listQuery struct {
+	Page    int       `form:"page" default:"1" validate:"min=1"`
+	PerPage int       `form:"per_page,default=20" validate:"max=100"`
+	Sort    string    `form:"sort" default:"created_at"`
+	Roles   []string  `form:"role"`
+	IDs     []int64   `form:"ids"`
+	Active  *bool     `form:"active"`
+	Since   time.Time `form:"since"`
+	Keyword string    `json:"q"`
+	Status  string
+}
+
+func newQueryContext(rawQuery string) (*Context, *httptest.ResponseRecorder) {
+	w := httptest.NewRecorder()
+	return New(w, httptest.NewRequest("GET", "/users?"+rawQuery, nil)), w
+}
+
+func TestBindQueryConvertsTypes(t *testing.T) {
+	c, _ := newQueryContext("page=3&role=admin&role=editor,viewer&ids=1,,2&active=true&since=2024-05-01&q=bob&Status=open")
+	defer c.Release()
+
+	var q listQuery
+	if err := c.BindQuery(&q); err != nil {
+		t.Fatalf("BindQuery: %v", err)
+	}
+	if q.Page != 3 || q.PerPage != 20 || q.Sort != "created_at" {
+		t.Errorf("unexpected scalars or defaults %+v", q)
+	}
+	if len(q.Roles) != 3 || q.Roles[2] != "viewer" || len(q.IDs) != 2 || q.IDs[1] != 2 {
+		t.Errorf("unexpected slices %v %v", q.Roles, q.IDs)
+	}
+	if q.Active == nil || !*q.Active || !q.Since.Equal(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected bool or time %+v", q)
+	}
+	if q.Keyword != "bob" || q.Status != "open" {
+		t.Errorf("expected json tag and field name fallbacks, got %q %q", q.Keyword, q.Status)
+	}
+
+	// 空值視為缺少，套用預設值
+	c2, _ := newQueryContext("page=&since=2024-05-01T08:00:00Z")
+	defer c2.Release()
+	var q2 listQuery
+	if err := c2.ShouldBindQuery(&q2); err != nil || q2.Page != 1 || q2.Since.Hour() != 8 {
+		t.Errorf("unexpected result %v %+v", err, q2)
+	}
+}
+
+func TestBindQueryFieldNameCaseInsensitive(t *testing.T) {
+	c, _ := newQueryContext("name=bob&Q=x")
+	defer c.Release()
+
+	var q struct {
+		Name    string
+		Keyword string `json:"q"`
+		Sort    string `form:"SORT"`
+	}
+	if err := c.ShouldBindQuery(&q); err != nil {
+		t.Fatal(err)
+	}
+	if q.Name != "bob" || q.Keyword != "x" {
+		t.Errorf("expected untagged fields to match case-insensitively, got %+v", q)
+	}
+
+	// 明確的 form tag 仍區分大小寫
+	c2, _ := newQueryContext("sort=asc")
+	defer c2.Release()
+	q.Sort = ""
+	if err := c2.ShouldBindQuery(&q); err != nil || q.Sort != "" {
+		t.Errorf("expected form tag to match exactly, got %q %v", q.Sort, err)
+	}
+}
+
+func TestBindQueryErrors(t *testing.T) {
+	c, w := newQueryContext("page=two")
+	defer c.Release()
+	var q listQuery
+	var fieldErr *BindFieldError
+	if err := c.BindQuery(&q); !errors.As(err, &fieldErr) || fieldErr.Source != "form" || fieldErr.Name != "page" {
+		t.Fatalf("expected BindFieldError, got %v", err)
+	}
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422, got %d", w.Code)
+	}
+
+	c2, w2 := newQueryContext("per_page=500")
+	defer c2.Release()
+	if err := c2.BindQuery(&q); err == nil {
+		t.Fatal("expected validation error")
+	}
+	errs := decodeFieldErrors(t, w2.Body.Bytes())
+	if w2.Code != http.StatusUnprocessableEntity || len(errs) != 1 || errs[0].Field == "" {
+		t.Errorf("expected 422 validation error, got %d %+v", w2.Code, errs)
+	}
+}
@@ -0,0 +1,95 @@
+// @chris
+package context
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	hypvalidate "github.com/maoxiaoyue/hypgo/pkg/validate"
+)
+
+// ===== 綁定並驗證 =====
+
+// FieldError 單一欄位的錯誤，Field 為 json 路徑（巢狀以 . 連接），無法對應欄位時為空
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// BindErrorRenderer 寫出 BindAndValidate 的失敗回應
+// status 為 400（body 無法解析）、413 / 415（body 過大或 charset 不支援）或 422（型別不符或驗證失敗）
+type BindErrorRenderer func(c *Context, status int, errs []FieldError)
+
+var bindErrorRenderer BindErrorRenderer = RenderFieldErrors
+
+// SetBindErrorRenderer 自訂 BindAndValidate 的錯誤格式，傳入 nil 恢復預設的 RenderFieldErrors
+// 非並行安全，應於程式啟動階段呼叫
+//
+// EX：
+//
+//	context.SetBindErrorRenderer(func(c *context.Context, status int, errs []context.FieldError) {
+//	    c.AbortWithStatusJSON(status, context.H{"success": false, "validation": errs})
+//	})
+func SetBindErrorRenderer(fn BindErrorRenderer) {
+	if fn == nil {
+		fn = RenderFieldErrors
+	}
+	bindErrorRenderer = fn
+}
+
+// RenderFieldErrors 預設錯誤格式：{"errors":[{"field":"email","message":"..."}]}
+func RenderFieldErrors(c *Context, status int, errs []FieldError) {
+	c.AbortWithStatusJSON(status, H{"errors": errs})
+}
+
+// BindAndValidate 依 Content-Type 綁定請求、執行 validate tag 驗證，
+// 失敗時以 BindErrorRenderer 寫出錯誤並中止請求，回傳 false 讓 handler 直接返回
+//
+// EX：
+//
+//	var req CreateUserRequest
+//	if !c.BindAndValidate(&req) {
+//	    return
+//	}
+func (c *Context) BindAndValidate(obj interface{}) bool {
+	if err := c.ShouldBind(obj); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if stderrors.As(err, &typeErr) && typeErr.Field != "" {
+			bindErrorRenderer(c, http.StatusUnprocessableEntity, []FieldError{{
+				Field:   typeErr.Field,
+				Message: typeErr.Field + " must be of type " + typeErr.Type.String(),
+			}})
+			return false
+		}
+		bindErrorRenderer(c, bindErrorStatus(err, http.StatusBadRequest), []FieldError{{Message: err.Error()}})
+		return false
+	}
+
+	if err := hypvalidate.Struct(obj); err != nil {
+		bindErrorRenderer(c, http.StatusUnprocessableEntity, fieldErrors(err))
+		return false
+	}
+	return true
+}
+
+// fieldErrors 將 validator 錯誤轉為依欄位排列的 FieldError
+func fieldErrors(err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if !stderrors.As(err, &verrs) {
+		return []FieldError{{Message: err.Error()}}
+	}
+
+	errs := make([]FieldError, len(verrs))
+	for i, fe := range verrs {
+		// Namespace 形如 "User.address.city"，去掉頂層型別名稱
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		errs[i] = FieldError{Field: field, Message: validationMessage(fe)}
+	}
+	return errs
+}
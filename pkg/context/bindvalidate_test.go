@@ -0,0 +1,98 @@
+package context
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type bvAddress struct {
+	City string `json:"city" validate:"required"`
+}
+
+type bvUser struct {
+	Name    string    `json:"name" validate:"required,min=2"`
+	Email   string    `json:"email" validate:"required,email"`
+	Age     int       `json:"age"`
+	Address bvAddress `json:"address"`
+}
+
+func decodeFieldErrors(t *testing.T, body []byte) []FieldError {
+	t.Helper()
+	var resp struct {
+		Errors []FieldError `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		t.Fatalf("response not JSON: %v (%s)", err, body)
+	}
+	return resp.Errors
+}
+
+func TestBindAndValidateSuccess(t *testing.T) {
+	c, _ := biCtx("POST", `{"name":"alice","email":"alice@test.com","address":{"city":"Taipei"}}`)
+	var u bvUser
+	if !c.BindAndValidate(&u) || u.Address.City != "Taipei" {
+		t.Fatalf("expected success, got %+v", u)
+	}
+}
+
+func TestBindAndValidateFieldErrors(t *testing.T) {
+	c, w := biCtx("POST", `{"name":"a","email":"nope","address":{}}`)
+	var u bvUser
+	if c.BindAndValidate(&u) {
+		t.Fatal("expected validation failure")
+	}
+	if w.Code != http.StatusUnprocessableEntity || !c.IsAborted() {
+		t.Errorf("expected aborted 422, got %d", w.Code)
+	}
+
+	errs := decodeFieldErrors(t, w.Body.Bytes())
+	want := map[string]string{
+		"name":         "name must be at least 2",
+		"email":        "email must be a valid email address",
+		"address.city": "city is required",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %+v", len(want), errs)
+	}
+	for _, e := range errs {
+		if want[e.Field] != e.Message {
+			t.Errorf("field %q: message %q, want %q", e.Field, e.Message, want[e.Field])
+		}
+	}
+}
+
+func TestBindAndValidateTypeAndParseErrors(t *testing.T) {
+	c, w := biCtx("POST", `{"name":"alice","email":"a@b.co","age":"old"}`)
+	var u bvUser
+	if c.BindAndValidate(&u) {
+		t.Fatal("expected type error")
+	}
+	if errs := decodeFieldErrors(t, w.Body.Bytes()); w.Code != http.StatusUnprocessableEntity || len(errs) != 1 || errs[0].Field != "age" {
+		t.Errorf("expected 422 on age, got %d %+v", w.Code, errs)
+	}
+
+	c, w = biCtx("POST", `{broken`)
+	if c.BindAndValidate(&u) {
+		t.Fatal("expected parse error")
+	}
+	if errs := decodeFieldErrors(t, w.Body.Bytes()); w.Code != http.StatusBadRequest || len(errs) != 1 || errs[0].Field != "" {
+		t.Errorf("expected 400 without field, got %d %+v", w.Code, errs)
+	}
+}
+
+func TestSetBindErrorRenderer(t *testing.T) {
+	SetBindErrorRenderer(func(c *Context, status int, errs []FieldError) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, H{"invalid": len(errs)})
+	})
+	defer SetBindErrorRenderer(nil)
+
+	c, w := biCtx("POST", `{}`)
+	var u bvUser
+	if c.BindAndValidate(&u) {
+		t.Fatal("expected failure")
+	}
+	if w.Code != http.StatusBadRequest || w.Body.String() != `{"invalid":3}` {
+		t.Errorf("custom renderer not used: %d %s", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,224 @@
+// @chris
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/htmlindex"
+	"golang.org/x/text/transform"
+)
+
+// ===== 請求 charset =====
+
+// ErrUnsupportedCharset 請求 Content-Type 宣告了無法解碼的 charset
+// Bind 系列方法以 415 回應，避免以 UTF-8 誤解碼後綁定出錯誤的值
+var ErrUnsupportedCharset = errors.New("unsupported charset")
+
+// requestCharset 取得請求 Content-Type 的 charset 參數（小寫），未宣告時為空字串
+func requestCharset(req *http.Request) string {
+	ct := req.Header.Get("Content-Type")
+	if !strings.Contains(ct, ";") {
+		return ""
+	}
+	_, params, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(params["charset"]))
+}
+
+// isUTF8Charset 未宣告、utf-8 與其子集 us-ascii 都不需轉碼
+func isUTF8Charset(name string) bool {
+	switch name {
+	case "", "utf-8", "utf8", "us-ascii", "ascii":
+		return true
+	}
+	return false
+}
+
+// lookupCharset 依 WHATWG 標籤（iso-8859-1、latin1、big5、shift_jis 等）取得編碼
+func lookupCharset(name string) (encoding.Encoding, error) {
+	enc, err := htmlindex.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedCharset, name)
+	}
+	return enc, nil
+}
+
+// requestBody 回傳轉為 UTF-8 的請求 body；宣告的 charset 無法識別時回傳 ErrUnsupportedCharset
+func requestBody(req *http.Request, body io.Reader) (io.Reader, error) {
+	name := requestCharset(req)
+	if isUTF8Charset(name) {
+		return body, nil
+	}
+	enc, err := lookupCharset(name)
+	if err != nil {
+		return nil, err
+	}
+	return transform.NewReader(body, enc.NewDecoder()), nil
+}
+
+// decodeBodyCharset 將已讀出的 body 依請求宣告的 charset 轉為 UTF-8（供 ShouldBindBodyWith）
+func decodeBodyCharset(req *http.Request, body []byte) ([]byte, error) {
+	name := requestCharset(req)
+	if isUTF8Charset(name) {
+		return body, nil
+	}
+	enc, err := lookupCharset(name)
+	if err != nil {
+		return nil, err
+	}
+	out, _, err := transform.Bytes(enc.NewDecoder(), body)
+	return out, err
+}
+
+// bindErrorStatus 依綁定錯誤決定回應狀態碼：body 過大 413、charset 不支援 415，其餘為 fallback
+func bindErrorStatus(err error, fallback int) int {
+	switch {
+	case errors.Is(err, ErrBodyTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, ErrUnsupportedCharset):
+		return http.StatusUnsupportedMediaType
+	}
+	return fallback
+}
+
+// ===== 回應 charset =====
+
+// responseCharsetKey 本請求回應 charset 在 Keys 中的 key
+const responseCharsetKey = "response_charset"
+
+// defaultResponseCharset 全域的回應 charset，空字串表示 UTF-8
+var defaultResponseCharset string
+
+// SetDefaultResponseCharset 設定所有回應的 charset（例如對接只接受 Big5 的舊系統），傳入 "utf-8" 恢復預設
+// 僅作用於經 Render 輸出的文字回應（JSON、XML、YAML、String、HTML 範本等），二進位輸出不轉碼；
+// 無法以目標編碼表示的字元以替代字元輸出。非並行安全，應於程式啟動階段呼叫
+func SetDefaultResponseCharset(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if isUTF8Charset(name) {
+		defaultResponseCharset = ""
+		return nil
+	}
+	if _, err := lookupCharset(name); err != nil {
+		return err
+	}
+	defaultResponseCharset = name
+	return nil
+}
+
+// SetResponseCharset 覆寫本請求的回應 charset，須在輸出回應前呼叫
+//
+// EX：
+//
+//	r.GET("/legacy/report", func(c *context.Context) {
+//	    if err := c.SetResponseCharset("big5"); err != nil {
+//	        c.AbortWithError(http.StatusInternalServerError, err)
+//	        return
+//	    }
+//	    c.String(http.StatusOK, report)
+//	})
+func (c *Context) SetResponseCharset(name string) error {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if !isUTF8Charset(name) {
+		if _, err := lookupCharset(name); err != nil {
+			return err
+		}
+	}
+	c.Set(responseCharsetKey, name)
+	return nil
+}
+
+// responseCharset 本請求的回應 charset，UTF-8 時為空字串
+func (c *Context) responseCharset() string {
+	if v, ok := c.Get(responseCharsetKey); ok {
+		name, _ := v.(string)
+		if isUTF8Charset(name) {
+			return ""
+		}
+		return name
+	}
+	return defaultResponseCharset
+}
+
+// charsetResponseWriter 將 UTF-8 文字輸出轉為目標 charset，並改寫 Content-Type 的 charset 參數
+// 是否轉碼於寫出標頭時依 Content-Type 決定，Data、DataFromReader、ProtoBuf、SSE 等二進位或串流輸出原樣寫出
+type charsetResponseWriter struct {
+	ResponseWriter
+	charset string
+	enc     encoding.Encoding
+	encoder io.WriteCloser // 不轉碼時為 nil
+	decided bool
+}
+
+func newCharsetResponseWriter(w ResponseWriter, name string) (*charsetResponseWriter, error) {
+	enc, err := lookupCharset(name)
+	if err != nil {
+		return nil, err
+	}
+	return &charsetResponseWriter{ResponseWriter: w, charset: name, enc: enc}, nil
+}
+
+// isTextContentType 只有宣告 charset=utf-8 或未宣告 charset 的 text/*（SSE 除外）才需轉碼
+func isTextContentType(ct string) bool {
+	mediaType, params, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return false
+	}
+	if name, ok := params["charset"]; ok {
+		return strings.EqualFold(name, "utf-8")
+	}
+	return strings.HasPrefix(mediaType, "text/") && mediaType != "text/event-stream"
+}
+
+// decide 依 Content-Type 決定是否轉碼；轉碼時改寫 charset 並移除 Content-Length（轉碼後長度不同）
+func (w *charsetResponseWriter) decide() {
+	if w.decided {
+		return
+	}
+	w.decided = true
+	if w.Written() {
+		return
+	}
+	header := w.Header()
+	ct := header.Get("Content-Type")
+	if !isTextContentType(ct) {
+		return
+	}
+	mediaType, params, _ := mime.ParseMediaType(ct)
+	params["charset"] = w.charset
+	header.Set("Content-Type", mime.FormatMediaType(mediaType, params))
+	header.Del("Content-Length")
+	w.encoder = transform.NewWriter(w.ResponseWriter, encoding.ReplaceUnsupported(w.enc.NewEncoder()))
+}
+
+func (w *charsetResponseWriter) WriteHeaderNow() {
+	w.decide()
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *charsetResponseWriter) Write(data []byte) (int, error) {
+	w.WriteHeaderNow()
+	if w.encoder == nil {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.encoder.Write(data)
+}
+
+func (w *charsetResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Close 送出轉碼器緩衝的剩餘內容
+func (w *charsetResponseWriter) Close() error {
+	if w.encoder == nil {
+		return nil
+	}
+	return w.encoder.Close()
+}
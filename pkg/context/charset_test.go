@@ -0,0 +1,176 @@
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// latin1JSON 以 ISO-8859-1 編碼的 {"name":"José Müller"}（é = 0xE9、ü = 0xFC）
+var latin1JSON = []byte("{\"name\":\"Jos\xe9 M\xfcller\"}")
+
+type charsetPayload struct {
+	Name string `json:"name" xml:"name" yaml:"name"`
+}
+
+func newCharsetRequest(contentType string, body []byte) *http.Request {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	return req
+}
+
+func TestBindLatin1JSON(t *testing.T) {
+	for _, ct := range []string{"application/json; charset=ISO-8859-1", "application/json;charset=latin1"} {
+		c := New(httptest.NewRecorder(), newCharsetRequest(ct, latin1JSON))
+		var p charsetPayload
+		if err := c.ShouldBindJSON(&p); err != nil || p.Name != "José Müller" {
+			t.Errorf("%s: got %q, %v", ct, p.Name, err)
+		}
+		c.Release()
+	}
+
+	// 嚴格模式與可重複綁定的路徑同樣轉碼
+	c := New(httptest.NewRecorder(), newCharsetRequest("application/json; charset=iso-8859-1", latin1JSON))
+	defer c.Release()
+	var strict charsetPayload
+	if err := c.ShouldBindBodyWith(&strict, JSONStrict); err != nil || strict.Name != "José Müller" {
+		t.Errorf("ShouldBindBodyWith: got %q, %v", strict.Name, err)
+	}
+}
+
+func TestBindUTF8Unchanged(t *testing.T) {
+	body := []byte(`{"name":"José"}`)
+	c := New(httptest.NewRecorder(), newCharsetRequest("application/json; charset=utf-8", body))
+	defer c.Release()
+	var p charsetPayload
+	if err := c.ShouldBindJSON(&p); err != nil || p.Name != "José" {
+		t.Errorf("got %q, %v", p.Name, err)
+	}
+}
+
+func TestBindLatin1XML(t *testing.T) {
+	body := []byte("<?xml version=\"1.0\" encoding=\"ISO-8859-1\"?><p><name>Jos\xe9</name></p>")
+
+	// 只有 XML 宣告
+	c := New(httptest.NewRecorder(), newCharsetRequest("application/xml", body))
+	var p charsetPayload
+	if err := c.ShouldBindXML(&p); err != nil || p.Name != "José" {
+		t.Errorf("prolog only: got %q, %v", p.Name, err)
+	}
+	c.Release()
+
+	// 標頭與 XML 宣告皆有
+	c = New(httptest.NewRecorder(), newCharsetRequest("application/xml; charset=iso-8859-1", body))
+	defer c.Release()
+	p = charsetPayload{}
+	if err := c.ShouldBindXML(&p); err != nil || p.Name != "José" {
+		t.Errorf("header and prolog: got %q, %v", p.Name, err)
+	}
+}
+
+func TestBindUnsupportedCharset(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := New(w, newCharsetRequest("application/json; charset=x-klingon", latin1JSON))
+	defer c.Release()
+
+	var p charsetPayload
+	if err := c.BindJSON(&p); err == nil || !strings.Contains(err.Error(), "x-klingon") {
+		t.Fatalf("expected unsupported charset error, got %v", err)
+	}
+	if w.Code != http.StatusUnsupportedMediaType || !c.IsAborted() {
+		t.Errorf("expected 415 abort, got %d", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	c2 := New(w, newCharsetRequest("application/json; charset=x-klingon", latin1JSON))
+	defer c2.Release()
+	if c2.BindInput(&p) {
+		t.Fatal("expected BindInput to fail")
+	}
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusUnsupportedMediaType || body["code"] != "E0006" {
+		t.Errorf("expected 415 E0006, got %d %s", w.Code, w.Body.String())
+	}
+}
+
+func TestResponseCharset(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("GET", "/", nil))
+	defer c.Release()
+
+	if err := c.SetResponseCharset("x-klingon"); err == nil {
+		t.Error("expected unknown charset to be rejected")
+	}
+	if err := c.SetResponseCharset("ISO-8859-1"); err != nil {
+		t.Fatal(err)
+	}
+	c.JSON(http.StatusOK, H{"name": "José"})
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=iso-8859-1" {
+		t.Errorf("unexpected Content-Type %q", ct)
+	}
+	if got := w.Body.Bytes(); !bytes.Equal(got, []byte("{\"name\":\"Jos\xe9\"}")) {
+		t.Errorf("unexpected body % x", got)
+	}
+}
+
+func TestDefaultResponseCharset(t *testing.T) {
+	if err := SetDefaultResponseCharset("windows-1252"); err != nil {
+		t.Fatal(err)
+	}
+	defer SetDefaultResponseCharset("utf-8")
+
+	w := httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("GET", "/", nil))
+	defer c.Release()
+	c.String(http.StatusOK, "caf%s", "é")
+	if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=windows-1252" || w.Body.String() != "caf\xe9" {
+		t.Errorf("got %q %q", ct, w.Body.String())
+	}
+}
+
+func TestResponseCharsetBinaryPassthrough(t *testing.T) {
+	png := []byte{0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a, 0xff, 0xd8, 0xe9}
+
+	w := httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("GET", "/", nil))
+	if err := c.SetResponseCharset("iso-8859-1"); err != nil {
+		t.Fatal(err)
+	}
+	c.Data(http.StatusOK, "image/png", png)
+	if !bytes.Equal(w.Body.Bytes(), png) || w.Header().Get("Content-Type") != "image/png" {
+		t.Errorf("Data: got %q % x", w.Header().Get("Content-Type"), w.Body.Bytes())
+	}
+	c.Release()
+
+	// DataFromReader（非 ReadSeeker 經 Render 輸出）保留 Content-Length 與原始位元組
+	w = httptest.NewRecorder()
+	c = New(w, httptest.NewRequest("GET", "/", nil))
+	defer c.Release()
+	c.SetResponseCharset("iso-8859-1")
+	c.DataFromReader(http.StatusOK, int64(len(png)), "application/octet-stream", io.MultiReader(bytes.NewReader(png)), nil)
+	if !bytes.Equal(w.Body.Bytes(), png) || w.Header().Get("Content-Length") != "11" {
+		t.Errorf("DataFromReader: got %q % x", w.Header().Get("Content-Length"), w.Body.Bytes())
+	}
+}
+
+func TestResponseCharsetDropsContentLength(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("GET", "/", nil))
+	defer c.Release()
+	c.SetResponseCharset("iso-8859-1")
+
+	body := "José Müller"
+	c.DataFromReader(http.StatusOK, int64(len(body)), "text/plain; charset=utf-8", io.MultiReader(strings.NewReader(body)), nil)
+	if cl := w.Header().Get("Content-Length"); cl != "" {
+		t.Errorf("expected Content-Length to be dropped, got %q", cl)
+	}
+	if w.Body.String() != "Jos\xe9 M\xfcller" {
+		t.Errorf("unexpected body % x", w.Body.Bytes())
+	}
+}
@@ -18,6 +18,10 @@ const (
 const (
 	// MIMEJSON JSON 內容類型
 	MIMEJSON = "application/json"
+	// MIMEJSONPatch JSON Patch（RFC 6902）內容類型
+	MIMEJSONPatch = "application/json-patch+json"
+	// MIMEMergePatch JSON Merge Patch（RFC 7396）內容類型
+	MIMEMergePatch = "application/merge-patch+json"
 	// MIMEHTML HTML 內容類型
 	MIMEHTML = "text/html"
 	// MIMEXML XML 內容類型
@@ -9,6 +9,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
 	"github.com/quic-go/quic-go/http3"
 )
 
@@ -19,9 +20,28 @@ import (
 type hypContextKey struct{}
 
 // NewContext 將 HypGo *Context 嵌入標準 context.Context 中
-// 讓下游接受 context.Context 的 API 可透過 FromContext 取回 HypGo Context
+// 讓下游接受 context.Context 的 API 可透過 FromContext 取回 HypGo Context，
+// 並能以 string key 讀到 c.Set 存放的值（查詢順序與 Context.Value 相同：Keys 優先於 parent）
 func NewContext(parent stdcontext.Context, c *Context) stdcontext.Context {
-	return stdcontext.WithValue(parent, hypContextKey{}, c)
+	return &bridgeContext{Context: parent, c: c}
+}
+
+// bridgeContext 沿用 parent 的取消、deadline 與 values，另外查詢 HypGo Context 的 Keys
+type bridgeContext struct {
+	stdcontext.Context
+	c *Context
+}
+
+func (b *bridgeContext) Value(key interface{}) interface{} {
+	if _, ok := key.(hypContextKey); ok {
+		return b.c
+	}
+	if keyAsString, ok := key.(string); ok {
+		if val, exists := b.c.Get(keyAsString); exists {
+			return val
+		}
+	}
+	return b.Context.Value(key)
 }
 
 // FromContext 從標準 context.Context 中提取 HypGo *Context
@@ -90,6 +110,9 @@ type Context struct {
 	schemaInput     interface{} // 該路由宣告的 Input 零值實例（nil 表示無 schema）
 	schemaRouteKey  string      // schema RouteKey，用於型別不符回報
 	bindInputCalled bool        // 本請求是否呼叫過 BindInput（供啟動 lint runtime 偵測）
+
+	// 請求範圍的 logger（AttachLogger / Logger 建立）
+	logger *logger.Logger
 }
 
 // QuicConnection 封裝 QUIC 連接資訊
@@ -156,9 +179,11 @@ func New(w http.ResponseWriter, r *http.Request) *Context {
 // Reset 重置 Context 到初始狀態（用於物件池）
 func (c *Context) Reset(w http.ResponseWriter, r *http.Request) {
 	c.Request = r
+	c.metrics = &RequestMetrics{}
 	if w != nil {
-		c.Response = newResponseWriter(w)
-		c.Writer = c.Response
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK, metrics: c.metrics}
+		c.Response = rw
+		c.Writer = rw
 	} else {
 		c.Response = nil
 		c.Writer = nil
@@ -176,9 +201,10 @@ func (c *Context) Reset(w http.ResponseWriter, r *http.Request) {
 	c.schemaInput = nil
 	c.schemaRouteKey = ""
 	c.bindInputCalled = false
+	c.logger = nil
 	c.startTime = time.Now()
-	c.metrics = &RequestMetrics{}
 	if r != nil {
+		c.trackRequestBody()
 		c.detectProtocol()
 		if c.protocol == HTTP3 {
 			c.initQuicConnection()
@@ -186,24 +212,50 @@ func (c *Context) Reset(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// Copy 返回當前 Context 的副本（用於 goroutine）
+// Copy 返回與請求脫鉤的唯讀快照，供 handler 啟動的 goroutine 在請求結束後使用
+// 原 Context 在 handler 返回後會被 Release 回收重用，goroutine 不可再持有它；
+// 副本複製 Keys、路由參數、錯誤與請求資訊，handler 鏈為空（Next 不執行任何處理器），
+// 回應寫入器為 no-op：在副本上 JSON、String、Header 等寫入都會被忽略，不會送到客戶端
+// 副本不屬於物件池，不需要也不應呼叫 Release
+//
+// EX：
+//
+//	r.POST("/orders", func(c *context.Context) {
+//	    cp := c.Copy()
+//	    go func() {
+//	        audit.Record(cp.GetString("request_id"), cp.Param("id"), cp.ClientIP())
+//	    }()
+//	    c.JSON(http.StatusAccepted, order)
+//	})
 func (c *Context) Copy() *Context {
+	rw := newResponseWriter(&discardResponseWriter{header: make(http.Header)})
 	cp := &Context{
-		Request:  c.Request.Clone(c.Request.Context()),
-		Response: nil,
-		Writer:   nil,
-		Params:   make(Params, len(c.Params)),
-		handlers: nil,
-		index:    c.index,
-		fullPath: c.fullPath,
-		protocol: c.protocol,
+		Response:  rw,
+		Writer:    rw,
+		Params:    make(Params, len(c.Params)),
+		rawData:   c.rawData,
+		handlers:  nil,
+		index:     abortIndex,
+		fullPath:  c.fullPath,
+		protocol:  c.protocol,
+		startTime: c.startTime,
+		sameSite:  c.sameSite,
+		logger:    c.logger,
 	}
+	if c.Request != nil {
+		cp.Request = c.Request.Clone(c.Request.Context())
+	}
+	if c.metrics != nil {
+		m := *c.metrics
+		cp.metrics = &m
+	}
+	cp.Accepted = append([]string(nil), c.Accepted...)
 
 	copy(cp.Params, c.Params)
 
 	// 深拷貝 Keys
-	cp.Keys = make(map[string]interface{}, len(c.Keys))
 	c.mu.RLock()
+	cp.Keys = make(map[string]interface{}, len(c.Keys))
 	for k, v := range c.Keys {
 		cp.Keys[k] = v
 	}
@@ -216,6 +268,15 @@ func (c *Context) Copy() *Context {
 	return cp
 }
 
+// discardResponseWriter Copy 使用的 no-op 回應寫入器，寫入的內容直接丟棄
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (w *discardResponseWriter) Header() http.Header         { return w.header }
+func (w *discardResponseWriter) Write(b []byte) (int, error) { return len(b), nil }
+func (w *discardResponseWriter) WriteHeader(int)             {}
+
 // Release 釋放 Context 回物件池
 func (c *Context) Release() {
 	if contextPool != nil {
@@ -225,11 +286,22 @@ func (c *Context) Release() {
 
 // ===== 中間件執行 =====
 
+// SetHandlers 設置處理器鏈並重置執行位置，由 router 在 Next() 前調用
+func (c *Context) SetHandlers(handlers []HandlerFunc) {
+	c.handlers = handlers
+	c.index = -1
+}
+
 // Next 執行下一個中間件
+// 中間件在 c.Next() 之後的程式碼會於後續處理器完成後執行；
+// 呼叫 Abort 系列方法或已寫出回應時停止後續處理器
 func (c *Context) Next() {
 	c.index++
 	for c.index < int8(len(c.handlers)) {
 		c.handlers[c.index](c)
+		if c.IsAborted() || (c.Response != nil && c.Response.Written()) {
+			return
+		}
 		c.index++
 	}
 }
@@ -257,10 +329,17 @@ func (c *Context) AbortWithStatusJSON(code int, jsonObj interface{}) {
 	c.JSON(code, jsonObj)
 }
 
-// AbortWithError 中止並返回錯誤
+// AbortWithError 中止並返回錯誤（不寫出 body）；code >= 500 時交給 ErrorReporter 並將完整錯誤寫入請求日誌
 func (c *Context) AbortWithError(code int, err error) *Error {
 	c.AbortWithStatus(code)
-	return c.Error(err)
+	msg := c.collectError(err)
+	if msg != nil && code >= http.StatusInternalServerError {
+		c.reportCollected(msg, code)
+		if !msg.IsType(ErrorTypePublic) {
+			c.logErrorDetail(code, msg.Error())
+		}
+	}
+	return msg
 }
 
 // HandlerName 返回當前處理器的名稱
@@ -334,7 +413,7 @@ func (c *Context) Err() error {
 }
 
 // Value 實現 context.Context 介面
-// 查詢順序：hypContextKey → Request(key=0) → Keys map → Request.Context()
+// 查詢順序：hypContextKey → Request(key=0) → Keys map → Request.Context()（含 SetValue 存放的值）
 func (c *Context) Value(key interface{}) interface{} {
 	// 允許 FromContext 直接從 *Context 提取（因 *Context 本身實現 context.Context）
 	if _, ok := key.(hypContextKey); ok {
@@ -367,6 +446,17 @@ func (c *Context) StdContext() stdcontext.Context {
 	return NewContext(parent, c)
 }
 
+// WithValue 回傳多帶一組 key/value 的標準 context.Context，不修改 c
+// 適合只在單次呼叫傳遞資料給接受 context.Context 的 stdlib / DB API；需在整個請求中可見時用 SetValue
+//
+// EX：
+//
+//	ctx := c.WithValue(tenantKey{}, tenantID)
+//	rows, err := db.QueryContext(ctx, query)
+func (c *Context) WithValue(key, value interface{}) stdcontext.Context {
+	return stdcontext.WithValue(c.StdContext(), key, value)
+}
+
 // ===== 協議檢測 =====
 
 // detectProtocol 檢測當前使用的協議
@@ -381,7 +471,13 @@ func (c *Context) detectProtocol() {
 }
 
 // Protocol 返回協議字符串
+// 優先採用 c.Set("protocol", ...) 設置的值，否則依偵測結果（Request.ProtoMajor）
 func (c *Context) Protocol() string {
+	if v, exists := c.Get("protocol"); exists {
+		if proto, ok := v.(string); ok && proto != "" {
+			return proto
+		}
+	}
 	switch c.protocol {
 	case HTTP3:
 		return "HTTP/3"
@@ -394,18 +490,27 @@ func (c *Context) Protocol() string {
 
 // ===== 效能監控 =====
 
-// GetMetrics 獲取請求指標
+// GetMetrics 獲取請求指標（更新 Duration 與 HTTP/3 RTT 後回傳內部指標，僅限處理期間使用；需保存請用 Metrics）
 func (c *Context) GetMetrics() *RequestMetrics {
-	c.metrics.Duration = time.Since(c.startTime)
+	c.metrics.Duration = c.Elapsed()
+	c.sampleRTT()
 	return c.metrics
 }
 
-// RecordBytesIn 記錄輸入位元組
+// trackRequestBody 以計數 reader 包裝請求 body，讀取量自動累計至 BytesIn
+func (c *Context) trackRequestBody() {
+	if c.Request == nil || c.Request.Body == nil || c.Request.Body == http.NoBody {
+		return
+	}
+	c.Request.Body = &countingBody{ReadCloser: c.Request.Body, metrics: c.metrics}
+}
+
+// RecordBytesIn 額外記錄輸入位元組（請求 body 已自動計入，僅用於繞過 body 的資料，如 WebSocket 訊息）
 func (c *Context) RecordBytesIn(bytes int64) {
 	c.metrics.BytesIn += bytes
 }
 
-// RecordBytesOut 記錄輸出位元組
+// RecordBytesOut 額外記錄輸出位元組（經 ResponseWriter 寫出的回應已自動計入）
 func (c *Context) RecordBytesOut(bytes int64) {
 	c.metrics.BytesOut += bytes
 }
@@ -1,12 +1,21 @@
 package context
 
 import (
+	"bytes"
 	stdcontext "context"
+	"crypto/tls"
+	"errors"
 	"io"
+	"mime/multipart"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
 )
 
 func TestNewContextAndFromContext(t *testing.T) {
@@ -213,3 +222,910 @@ func TestValueWithHypContextKey(t *testing.T) {
 		t.Error("Value(hypContextKey{}) should return the Context itself")
 	}
 }
+
+func newProxyTestContext(remoteAddr string, proxies ...string) *Context {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = remoteAddr
+	var nets []*net.IPNet
+	for _, p := range proxies {
+		_, n, _ := net.ParseCIDR(p)
+		nets = append(nets, n)
+	}
+	req = req.WithContext(WithTrustedProxies(req.Context(), nets))
+	return New(httptest.NewRecorder(), req)
+}
+
+func TestClientIPUntrustedRemoteIgnoresHeaders(t *testing.T) {
+	c := newProxyTestContext("203.0.113.9:1234")
+	c.Request.Header.Set("X-Forwarded-For", "1.2.3.4")
+	c.Request.Header.Set("X-Real-IP", "1.2.3.4")
+
+	if got := c.ClientIP(); got != "203.0.113.9" {
+		t.Errorf("ClientIP() = %q, want 203.0.113.9", got)
+	}
+	if c.IsFromTrustedProxy() {
+		t.Error("IsFromTrustedProxy() = true, want false")
+	}
+}
+
+func TestClientIPTrustedProxy(t *testing.T) {
+	c := newProxyTestContext("10.0.0.1:1234", "10.0.0.0/8")
+	// 最左側為偽造值，應取最右側第一個不可信位址
+	c.Request.Header.Set("X-Forwarded-For", "6.6.6.6, 198.51.100.7, 10.0.0.2")
+
+	if !c.IsFromTrustedProxy() {
+		t.Error("IsFromTrustedProxy() = false, want true")
+	}
+	if got := c.ClientIP(); got != "198.51.100.7" {
+		t.Errorf("ClientIP() = %q, want 198.51.100.7", got)
+	}
+}
+
+func TestClientIPTrustedProxyXRealIP(t *testing.T) {
+	c := newProxyTestContext("10.0.0.1:1234", "10.0.0.0/8")
+	c.Request.Header.Set("X-Real-IP", "198.51.100.7")
+
+	if got := c.ClientIP(); got != "198.51.100.7" {
+		t.Errorf("ClientIP() = %q, want 198.51.100.7", got)
+	}
+}
+
+// --- 型別化參數解析測試 ---
+
+func TestParamInt(t *testing.T) {
+	c := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/42", nil))
+	c.SetParam("id", "42")
+	c.SetParam("bad", "abc")
+
+	if v, err := c.ParamInt("id"); err != nil || v != 42 {
+		t.Errorf("ParamInt(id) = %d, %v", v, err)
+	}
+	if v, err := c.ParamInt64("id"); err != nil || v != 42 {
+		t.Errorf("ParamInt64(id) = %d, %v", v, err)
+	}
+	if _, err := c.ParamInt("bad"); err == nil {
+		t.Error("ParamInt(bad) should fail")
+	}
+}
+
+func TestMustParamInt(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("GET", "/users/abc", nil))
+	c.SetParam("id", "abc")
+
+	if _, ok := c.MustParamInt("id"); ok {
+		t.Fatal("MustParamInt should fail for non-numeric id")
+	}
+	if w.Code != http.StatusBadRequest || !c.IsAborted() {
+		t.Errorf("expected aborted 400, got %d aborted=%v", w.Code, c.IsAborted())
+	}
+}
+
+func TestQueryTypedHelpers(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?n=7&bad=x&flag=true&ratio=0.5&since=2026-01-02T03:04:05Z&day=2026-01-02", nil)
+	c := New(httptest.NewRecorder(), req)
+
+	if v := c.QueryInt("n", 1); v != 7 {
+		t.Errorf("QueryInt(n) = %d", v)
+	}
+	if v := c.QueryInt("bad", 1); v != 1 {
+		t.Errorf("QueryInt(bad) = %d, want default", v)
+	}
+	if v := c.QueryInt("missing", 3); v != 3 {
+		t.Errorf("QueryInt(missing) = %d, want default", v)
+	}
+	if !c.QueryBool("flag", false) || !c.QueryBool("missing", true) {
+		t.Error("QueryBool returned unexpected value")
+	}
+	if v := c.QueryFloat("ratio", 0); v != 0.5 {
+		t.Errorf("QueryFloat(ratio) = %v", v)
+	}
+
+	if ts, err := c.QueryTime("since", ""); err != nil || ts.Hour() != 3 {
+		t.Errorf("QueryTime(since) = %v, %v", ts, err)
+	}
+	if ts, err := c.QueryTime("day", "2006-01-02"); err != nil || ts.Day() != 2 {
+		t.Errorf("QueryTime(day) = %v, %v", ts, err)
+	}
+	if _, err := c.QueryTime("bad", ""); err == nil {
+		t.Error("QueryTime(bad) should fail")
+	}
+	if ts, err := c.QueryTime("missing", ""); err != nil || !ts.IsZero() {
+		t.Errorf("QueryTime(missing) = %v, %v", ts, err)
+	}
+}
+
+// --- API 回應封裝測試 ---
+
+func TestEnvelopeHelpers(t *testing.T) {
+	w := httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).Success(http.StatusCreated, map[string]int{"id": 1})
+	if w.Code != http.StatusCreated || !strings.Contains(w.Body.String(), `"success":true`) || !strings.Contains(w.Body.String(), `"data":{"id":1}`) {
+		t.Errorf("Success: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("GET", "/", nil))
+	c.Fail(http.StatusNotFound, "not found")
+	if w.Code != http.StatusNotFound || !c.IsAborted() || !strings.Contains(w.Body.String(), `"error":"not found"`) {
+		t.Errorf("Fail: %d %s", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).Paginated([]int{1, 2}, 12, 2, 10)
+	if !strings.Contains(w.Body.String(), `"meta":{"page":2,"page_size":10,"total":12}`) {
+		t.Errorf("Paginated: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).PaginatedPage(stubPage{})
+	if !strings.Contains(w.Body.String(), `"meta":{"page":2,"page_size":10,"pages":2,"total":12}`) {
+		t.Errorf("PaginatedPage: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).PaginatedCursor(stubCursor{next: "abc"})
+	if !strings.Contains(w.Body.String(), `"meta":{"has_more":true,"next_cursor":"abc"}`) {
+		t.Errorf("PaginatedCursor: %s", w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).PaginatedCursor(stubCursor{})
+	if !strings.Contains(w.Body.String(), `"meta":{"has_more":false}`) {
+		t.Errorf("PaginatedCursor last page: %s", w.Body.String())
+	}
+}
+
+type stubPage struct{}
+
+func (stubPage) PageItems() interface{}         { return []int{1, 2} }
+func (stubPage) PageInfo() (int, int, int, int) { return 12, 2, 10, 2 }
+
+type stubCursor struct{ next string }
+
+func (stubCursor) PageItems() interface{}       { return []int{1} }
+func (s stubCursor) CursorInfo() (string, bool) { return s.next, s.next != "" }
+
+func TestSetEnvelopeFields(t *testing.T) {
+	SetEnvelopeFields(EnvelopeFields{Success: "ok", Error: "message"})
+	defer SetEnvelopeFields(EnvelopeFields{})
+
+	w := httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).Fail(http.StatusBadRequest, "bad")
+	if body := w.Body.String(); !strings.Contains(body, `"ok":false`) || !strings.Contains(body, `"message":"bad"`) {
+		t.Errorf("custom fields not applied: %s", body)
+	}
+	// 5xx 的 reference 也使用自訂名稱（AbortWithErrorJSON 經 addErrorDetail）
+	SetEnvelopeFields(EnvelopeFields{Reference: "trace_id"})
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Request-ID", "req-3")
+	c := New(w, req)
+	var out bytes.Buffer
+	log, _ := logger.New("debug", "", &out, false)
+	log.SetFormat("json")
+	c.AttachLogger(log)
+	c.AbortWithErrorJSON(http.StatusInternalServerError, errors.New("db down"))
+	if body := w.Body.String(); !strings.Contains(body, `"trace_id":"req-3"`) || strings.Contains(body, `"reference"`) {
+		t.Errorf("custom reference field not applied: %s", body)
+	}
+	// 日誌的 key 固定為 reference，不受回應欄位名稱影響
+	if logged := out.String(); !strings.Contains(logged, `"reference":"req-3"`) || strings.Contains(logged, `"trace_id"`) {
+		t.Errorf("Expected log key reference, got %s", logged)
+	}
+}
+
+// --- 身分識別測試 ---
+
+func TestIdentityAccessors(t *testing.T) {
+	c := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	c.SetUserID(7)
+	c.SetUser("alice")
+	c.SetRoles([]string{"editor"})
+	c.SetTokenClaims(map[string]interface{}{"sub": "7"})
+
+	if c.UserID() != 7 || c.GetInt("user_id") != 7 {
+		t.Errorf("UserID() = %v", c.UserID())
+	}
+	if c.User() != "alice" || c.Username() != "alice" {
+		t.Errorf("User() = %v, Username() = %q", c.User(), c.Username())
+	}
+	if !c.HasRole("editor") || c.HasRole("admin") || !c.HasAnyRole("admin", "editor") {
+		t.Errorf("role checks failed for %v", c.Roles())
+	}
+	if c.GetTokenClaim("sub") != "7" || c.TokenClaims() == nil {
+		t.Errorf("TokenClaims() = %v", c.TokenClaims())
+	}
+
+	c.Set("roles", []interface{}{"admin"})
+	if !c.HasRole("admin") {
+		t.Error("HasRole should accept []interface{} roles from JWT claims")
+	}
+
+	c.SetAuthError("invalid_token")
+	if c.AuthError() != "invalid_token" {
+		t.Errorf("AuthError() = %q", c.AuthError())
+	}
+}
+
+// --- 協議偵測測試 ---
+
+func TestProtocolAccessors(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.ProtoMajor, req.ProtoMinor = 2, 0
+	c := New(httptest.NewRecorder(), req)
+
+	if c.Protocol() != "HTTP/2" || !c.IsHTTP2() || c.IsHTTP3() || c.IsHTTP1() {
+		t.Errorf("derived protocol = %q", c.Protocol())
+	}
+
+	// server 設置的值優先
+	c.Set("protocol", "HTTP/3")
+	if c.Protocol() != "HTTP/3" || !c.IsHTTP3() || c.IsHTTP2() {
+		t.Errorf("explicit protocol = %q", c.Protocol())
+	}
+}
+
+// --- 請求指標測試 ---
+
+func TestContextMetrics(t *testing.T) {
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader("hello world"))
+	c := New(httptest.NewRecorder(), req)
+	defer c.Release()
+
+	io.ReadAll(c.Request.Body)
+	c.String(http.StatusOK, "accepted")
+	time.Sleep(time.Millisecond)
+
+	snap := c.Metrics()
+	if snap.BytesIn != 11 || snap.BytesOut != 8 {
+		t.Errorf("BytesIn/BytesOut = %d/%d, want 11/8", snap.BytesIn, snap.BytesOut)
+	}
+	if snap.Duration <= 0 || c.Elapsed() < snap.Duration {
+		t.Errorf("Duration = %v, Elapsed = %v", snap.Duration, c.Elapsed())
+	}
+	if snap.RTT != 0 {
+		t.Errorf("RTT = %v, want 0 outside HTTP/3", snap.RTT)
+	}
+
+	// 快照不隨後續寫出變動
+	c.Writer.WriteString("!")
+	if snap.BytesOut != 8 || c.Metrics().BytesOut != 9 {
+		t.Errorf("snapshot BytesOut = %d, live = %d", snap.BytesOut, c.Metrics().BytesOut)
+	}
+}
+
+func TestIs0RTT(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.TLS = &tls.ConnectionState{HandshakeComplete: true}
+	c := New(httptest.NewRecorder(), req)
+	if c.Is0RTT() {
+		t.Error("Expected completed handshake not to be 0-RTT")
+	}
+
+	req.TLS.HandshakeComplete = false
+	if !c.Is0RTT() || !c.IsEarlyData() {
+		t.Error("Expected request before handshake completion to be 0-RTT")
+	}
+
+	// 前端代理以 Early-Data 標頭轉發
+	proxied := httptest.NewRequest("POST", "/", nil)
+	proxied.Header.Set(HeaderEarlyData, "1")
+	if !New(httptest.NewRecorder(), proxied).Is0RTT() {
+		t.Error("Expected Early-Data: 1 to be treated as 0-RTT")
+	}
+}
+
+// --- JWT 擷取測試 ---
+
+func TestGetJWT(t *testing.T) {
+	req := httptest.NewRequest("GET", "/?token=from-query", nil)
+	req.Header.Set("Authorization", "bearer from-header")
+	req.AddCookie(&http.Cookie{Name: "jwt", Value: "from-cookie"})
+	c := New(httptest.NewRecorder(), req)
+	if got := c.GetJWT(); got != "from-header" {
+		t.Errorf("GetJWT() = %q, want header token", got)
+	}
+
+	req = httptest.NewRequest("GET", "/?token=from-query", nil)
+	req.AddCookie(&http.Cookie{Name: "session_jwt", Value: "from-cookie"})
+	JWTCookieName = "session_jwt"
+	defer func() { JWTCookieName = "jwt" }()
+	c = New(httptest.NewRecorder(), req)
+	if got := c.BearerToken(); got != "from-cookie" {
+		t.Errorf("BearerToken() = %q, want cookie token", got)
+	}
+
+	c = New(httptest.NewRecorder(), httptest.NewRequest("GET", "/?token=from-query", nil))
+	if got := c.GetJWT(); got != "from-query" {
+		t.Errorf("GetJWT() = %q, want query token", got)
+	}
+
+	c = New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if got := c.GetJWT(); got != "" {
+		t.Errorf("GetJWT() = %q, want empty", got)
+	}
+}
+
+// --- 運行模式測試 ---
+
+func TestModeControlsJSONIndent(t *testing.T) {
+	if Mode() != ReleaseMode {
+		t.Fatalf("default mode = %q, want release", Mode())
+	}
+
+	w := httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).JSON(http.StatusOK, H{"a": 1})
+	if strings.Contains(w.Body.String(), "\n ") {
+		t.Errorf("release mode should produce compact JSON: %q", w.Body.String())
+	}
+
+	SetMode(DebugMode)
+	defer SetMode(ReleaseMode)
+
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest("GET", "/", nil)).JSON(http.StatusOK, H{"a": 1})
+	if !strings.Contains(w.Body.String(), "\n ") {
+		t.Errorf("debug mode should produce indented JSON: %q", w.Body.String())
+	}
+}
+
+func TestSetModeUnknownPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("SetMode with unknown mode should panic")
+		}
+	}()
+	SetMode("verbose")
+}
+
+func TestEnvModeFallsBackToRelease(t *testing.T) {
+	cases := map[string]string{"": ReleaseMode, "debug": DebugMode, "test": TestMode, "Debug": ReleaseMode, "verbose": ReleaseMode}
+	for env, want := range cases {
+		if got := envMode(env); got != want {
+			t.Errorf("envMode(%q) = %q, want %q", env, got, want)
+		}
+	}
+}
+
+// --- 嚴格 JSON 綁定測試 ---
+
+type strictUser struct {
+	Name string `json:"name"`
+}
+
+func TestBindJSONStrictUnknownField(t *testing.T) {
+	body := `{"name":"alice","nmae":"typo"}`
+
+	// 寬鬆模式維持相容
+	var lenient strictUser
+	c := New(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	if err := c.ShouldBindJSON(&lenient); err != nil || lenient.Name != "alice" {
+		t.Fatalf("ShouldBindJSON = %v, %+v", err, lenient)
+	}
+
+	var strict strictUser
+	w := httptest.NewRecorder()
+	c = New(w, httptest.NewRequest("POST", "/", strings.NewReader(body)))
+	err := c.BindJSONStrict(&strict)
+	if err == nil || !strings.Contains(err.Error(), `"nmae"`) {
+		t.Fatalf("expected unknown field error naming nmae, got %v", err)
+	}
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400", w.Code)
+	}
+}
+
+func TestBindJSONStrictOversized(t *testing.T) {
+	old := StrictJSONMaxBytes
+	StrictJSONMaxBytes = 16
+	defer func() { StrictJSONMaxBytes = old }()
+
+	req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name":"a very long name"}`))
+	req.ContentLength = -1 // 模擬未宣告長度的 chunked body
+	w := httptest.NewRecorder()
+	c := New(w, req)
+
+	var u strictUser
+	if err := c.BindJSONStrict(&u); err == nil || !strings.Contains(err.Error(), ErrBodyTooLarge.Error()) {
+		t.Fatalf("expected ErrBodyTooLarge, got %v", err)
+	}
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+}
+
+func TestDecoderDisallowUnknownFieldsToggle(t *testing.T) {
+	EnableDecoderDisallowUnknownFields = true
+	defer func() { EnableDecoderDisallowUnknownFields = false }()
+
+	var u strictUser
+	c := New(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader(`{"extra":1}`)))
+	if err := c.ShouldBindJSON(&u); err == nil {
+		t.Error("expected default binder to reject unknown fields when toggle is on")
+	}
+}
+
+// --- 串流上傳測試 ---
+
+func newMultipartRequest(t *testing.T, files map[string]string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	mw.WriteField("title", "demo")
+	for name, content := range files {
+		fw, err := mw.CreateFormFile("file", name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		fw.Write([]byte(content))
+	}
+	mw.Close()
+
+	req := httptest.NewRequest("POST", "/upload", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+func TestStreamFormFile(t *testing.T) {
+	req := newMultipartRequest(t, map[string]string{"a.txt": "hello"})
+	c := New(httptest.NewRecorder(), req)
+
+	var got []string
+	err := c.StreamFormFile("file", func(part *UploadPart) error {
+		data, err := io.ReadAll(part)
+		got = append(got, part.FileName()+":"+string(data))
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0] != "a.txt:hello" {
+		t.Errorf("unexpected parts: %v", got)
+	}
+}
+
+func TestStreamFormFileMissing(t *testing.T) {
+	req := newMultipartRequest(t, nil)
+	c := New(httptest.NewRecorder(), req)
+
+	err := c.StreamFormFile("file", func(part *UploadPart) error { return nil })
+	if !errors.Is(err, http.ErrMissingFile) {
+		t.Errorf("expected ErrMissingFile, got %v", err)
+	}
+}
+
+func TestStreamFormFileLimits(t *testing.T) {
+	defer func() { MaxUploadSize, MaxUploadFileSize = 0, 0 }()
+	big := strings.Repeat("x", 64<<10)
+	drain := func(part *UploadPart) error {
+		_, err := io.Copy(io.Discard, part)
+		return err
+	}
+
+	MaxUploadSize = 1 << 10
+	c := New(httptest.NewRecorder(), newMultipartRequest(t, map[string]string{"big.bin": big}))
+	if err := c.StreamFormFile("file", drain); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge for total size, got %v", err)
+	}
+
+	MaxUploadSize, MaxUploadFileSize = 0, 8<<10
+	req := newMultipartRequest(t, map[string]string{"big.bin": big})
+	req.ContentLength = -1
+	c = New(httptest.NewRecorder(), req)
+	if err := c.StreamFormFile("file", drain); !errors.Is(err, ErrUploadTooLarge) {
+		t.Errorf("expected ErrUploadTooLarge for file size, got %v", err)
+	}
+
+	// 單檔上限以檔案內容計算：剛好等於上限可通過，多 1 byte 即拒絕
+	MaxUploadFileSize = 1 << 10
+	exact := strings.Repeat("x", 1<<10)
+	c = New(httptest.NewRecorder(), newMultipartRequest(t, map[string]string{"exact.bin": exact}))
+	if err := c.StreamFormFile("file", drain); err != nil {
+		t.Errorf("expected file at the limit to pass, got %v", err)
+	}
+	var got int64
+	c = New(httptest.NewRecorder(), newMultipartRequest(t, map[string]string{"over.bin": exact + "x"}))
+	err := c.StreamFormFile("file", func(part *UploadPart) error {
+		n, err := io.Copy(io.Discard, part)
+		got = n
+		return err
+	})
+	if !errors.Is(err, ErrUploadTooLarge) || got != 1<<10 {
+		t.Errorf("expected ErrUploadTooLarge after %d bytes for 1 byte over, got %v after %d", 1<<10, err, got)
+	}
+}
+
+type testTenantKey struct{}
+
+func TestSetValuePropagatesToRequestContext(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test", nil)
+	c := New(httptest.NewRecorder(), req)
+
+	c.SetValue(testTenantKey{}, "acme")
+	c.SetValue("request_id", "r-1")
+
+	if got := c.Value(testTenantKey{}); got != "acme" {
+		t.Errorf("Value(typed key) = %v", got)
+	}
+	if got := c.Request.Context().Value(testTenantKey{}); got != "acme" {
+		t.Errorf("Request.Context() should see typed key, got %v", got)
+	}
+	if got := c.StdContext().Value(testTenantKey{}); got != "acme" {
+		t.Errorf("StdContext() should see typed key, got %v", got)
+	}
+	if got := c.GetString("request_id"); got != "r-1" {
+		t.Errorf("string key should also be stored in Keys, got %q", got)
+	}
+}
+
+func TestStdContextSeesKeys(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test", nil)
+	c := New(httptest.NewRecorder(), req)
+	c.Set("user_id", 42)
+
+	if got := c.StdContext().Value("user_id"); got != 42 {
+		t.Errorf("StdContext().Value(string) = %v, want 42", got)
+	}
+}
+
+func TestWithValueDoesNotMutateContext(t *testing.T) {
+	req := httptest.NewRequest("GET", "/test", nil)
+	c := New(httptest.NewRecorder(), req)
+
+	ctx, cancel := stdcontext.WithCancel(req.Context())
+	c.Request = req.WithContext(ctx)
+	derived := c.WithValue(testTenantKey{}, "acme")
+
+	if derived.Value(testTenantKey{}) != "acme" {
+		t.Error("derived context should carry the value")
+	}
+	if got, ok := FromContext(derived); !ok || got != c {
+		t.Error("derived context should still carry the HypGo Context")
+	}
+	if c.Value(testTenantKey{}) != nil {
+		t.Error("WithValue must not modify the HypGo Context")
+	}
+
+	cancel()
+	if derived.Err() == nil {
+		t.Error("derived context should inherit request cancellation")
+	}
+}
+
+// --- 請求範圍 logger 測試 ---
+
+func TestContextLoggerReleased(t *testing.T) {
+	var buf bytes.Buffer
+	base, _ := logger.New("info", "", &buf, false)
+
+	req := httptest.NewRequest("GET", "/orders", nil)
+	req.Header.Set(HeaderXRequestID, "req-1")
+	c := New(httptest.NewRecorder(), req)
+	c.AttachLogger(base)
+	c.Logger().Infow("loaded", "count", 3)
+	if out := buf.String(); !strings.Contains(out, "request_id=req-1 method=GET path=/orders protocol=HTTP/1.1 count=3") {
+		t.Errorf("unexpected log line: %s", out)
+	}
+
+	attached := c.Logger()
+	c.Release()
+	if c.logger != nil {
+		t.Error("Release must drop the request logger")
+	}
+
+	c = New(httptest.NewRecorder(), httptest.NewRequest("GET", "/other", nil))
+	defer c.Release()
+	if c.Logger() == attached {
+		t.Error("pooled Context reused the previous request's logger")
+	}
+}
+
+// --- 錯誤收集測試 ---
+
+func TestErrorsPublicJSON(t *testing.T) {
+	c := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer c.Release()
+
+	if c.Errors.PublicJSON() != nil || c.Errors.Last() != nil {
+		t.Fatal("expected empty error collection")
+	}
+	c.Error(errors.New("db timeout"))
+	c.AddPublicError(errors.New("order not found"))
+	c.Error(errors.New("cache miss"))
+
+	if got := c.Errors.PublicJSON(); len(got) != 1 || got[0] != "order not found" {
+		t.Errorf("PublicJSON = %v", got)
+	}
+	if got := c.Errors.ByType(ErrorTypePrivate); len(got) != 2 {
+		t.Errorf("ByType(private) = %v", got)
+	}
+	if last := c.Errors.Last(); last.Error() != "cache miss" {
+		t.Errorf("Last = %v", last)
+	}
+}
+
+func TestAbortWithErrorJSON(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := New(w, httptest.NewRequest("POST", "/", nil))
+	c.AbortWithErrorJSON(http.StatusConflict, errors.New("duplicate key users_pkey"))
+	c.Writer.WriteHeaderNow()
+	if !c.IsAborted() || len(c.Errors) != 1 {
+		t.Errorf("aborted=%v errors=%d", c.IsAborted(), len(c.Errors))
+	}
+	if w.Code != http.StatusConflict || strings.TrimSpace(w.Body.String()) != `{"errors":["Conflict"]}` {
+		t.Errorf("private error leaked or wrong body: %d %s", w.Code, w.Body.String())
+	}
+	c.Release()
+
+	w = httptest.NewRecorder()
+	c = New(w, httptest.NewRequest("POST", "/", nil))
+	defer c.Release()
+	c.AbortWithErrorJSON(http.StatusConflict, c.AddPublicError(errors.New("email already registered")))
+	if strings.TrimSpace(w.Body.String()) != `{"errors":["email already registered"]}` || len(c.Errors) != 1 {
+		t.Errorf("unexpected public response %s (errors=%d)", w.Body.String(), len(c.Errors))
+	}
+}
+
+func TestErrorDetailsByMode(t *testing.T) {
+	var logs bytes.Buffer
+	log := logger.NewLogger()
+	log.SetOutput(&logs)
+	newCtx := func() (*Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "req-9")
+		c := New(w, req)
+		c.AttachLogger(log)
+		return c, w
+	}
+
+	// release：內部細節不得出現在回應中，改附 reference；日誌保留完整錯誤
+	c, w := newCtx()
+	c.AbortWithErrorJSON(http.StatusInternalServerError, errors.New("pq: password authentication failed"))
+	if body := w.Body.String(); strings.Contains(body, "pq:") || !strings.Contains(body, `"reference":"req-9"`) {
+		t.Errorf("release AbortWithErrorJSON leaked details or missed reference: %s", body)
+	}
+	c, w = newCtx()
+	c.Fail(http.StatusInternalServerError, "dial tcp 10.0.0.3:5432: connection refused")
+	if body := w.Body.String(); strings.Contains(body, "10.0.0.3") || !strings.Contains(body, `"error":"Internal Server Error"`) || !strings.Contains(body, `"reference":"req-9"`) {
+		t.Errorf("release Fail leaked details: %s", body)
+	}
+	c, w = newCtx()
+	c.Fail(http.StatusBadRequest, "name is required")
+	if body := w.Body.String(); !strings.Contains(body, "name is required") || strings.Contains(body, "reference") {
+		t.Errorf("client errors should keep their message: %s", body)
+	}
+	for _, want := range []string{"pq: password authentication failed", "10.0.0.3", "reference=req-9"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected %q in logs: %s", want, logs.String())
+		}
+	}
+
+	// debug / test：回應附上細節
+	SetMode(TestMode)
+	defer SetMode(ReleaseMode)
+	c, w = newCtx()
+	c.AbortWithErrorJSON(http.StatusInternalServerError, errors.New("pq: password authentication failed"))
+	if body := w.Body.String(); !strings.Contains(body, `"detail":"pq: password authentication failed"`) || strings.Contains(body, "reference") {
+		t.Errorf("test mode should expose details: %s", body)
+	}
+	c, w = newCtx()
+	c.Fail(http.StatusInternalServerError, "dial tcp 10.0.0.3:5432: connection refused")
+	if !strings.Contains(w.Body.String(), "10.0.0.3") {
+		t.Errorf("test mode Fail should keep the message: %s", w.Body.String())
+	}
+}
+
+func TestErrorReference(t *testing.T) {
+	c := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	ref := c.ErrorReference()
+	if len(ref) != 16 || c.ErrorReference() != ref {
+		t.Errorf("expected a stable random reference, got %q then %q", ref, c.ErrorReference())
+	}
+
+	c = New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	c.Set("request_id", "abc")
+	if got := c.ErrorReference(); got != "abc" {
+		t.Errorf("expected request_id as reference, got %q", got)
+	}
+}
+
+func TestContextReleaseClearsRequestState(t *testing.T) {
+	c := New(httptest.NewRecorder(), httptest.NewRequest("POST", "/users/1", strings.NewReader(`{"secret":1}`)))
+	c.SetFullPath("/users/:id")
+	if _, err := c.GetRawData(); err != nil {
+		t.Fatal(err)
+	}
+	c.Release()
+	if c.fullPath != "" || c.rawData != nil {
+		t.Errorf("Release must drop the route template and cached body, got %q %q", c.fullPath, c.rawData)
+	}
+}
+
+// recordingReporter 記錄收到的回報
+type recordingReporter struct {
+	errs   []error
+	fields []map[string]interface{}
+}
+
+func (r *recordingReporter) Report(err error, ctx map[string]interface{}) {
+	r.errs = append(r.errs, err)
+	r.fields = append(r.fields, ctx)
+}
+
+func newReportingContext(r ErrorReporter) *Context {
+	req := httptest.NewRequest("GET", "/orders/9", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	return New(httptest.NewRecorder(), req.WithContext(WithErrorReporter(req.Context(), r)))
+}
+
+func TestErrorReporting(t *testing.T) {
+	rec := &recordingReporter{}
+	c := newReportingContext(rec)
+	defer c.Release()
+	c.SetFullPath("/orders/:id")
+	c.SetUserID(7)
+
+	c.Error(errors.New("cache miss"))
+	c.AddPublicError(errors.New("email already registered"))
+	c.AbortWithError(http.StatusBadRequest, errors.New("bad input"))
+	c.AbortWithError(http.StatusBadGateway, errors.New("upstream down"))
+	c.AbortWithErrorJSON(http.StatusInternalServerError, errors.New("db down"))
+
+	if len(rec.errs) != 3 {
+		t.Fatalf("expected private and 5xx errors only, got %v", rec.errs)
+	}
+	fields := rec.fields[0]
+	if fields["method"] != "GET" || fields["path"] != "/orders/9" || fields["route"] != "/orders/:id" ||
+		fields["request_id"] != "req-1" || fields["user_id"] != 7 {
+		t.Errorf("unexpected request fields %v", fields)
+	}
+	if _, ok := fields["status"]; ok {
+		t.Errorf("c.Error should not report a status, got %v", fields)
+	}
+	if rec.errs[1].Error() != "upstream down" || rec.fields[1]["status"] != http.StatusBadGateway {
+		t.Errorf("unexpected AbortWithError report %v %v", rec.errs[1], rec.fields[1])
+	}
+	if rec.fields[2]["status"] != http.StatusInternalServerError {
+		t.Errorf("unexpected AbortWithErrorJSON report %v", rec.fields[2])
+	}
+
+	// 未註冊 reporter 時不做任何事
+	plain := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer plain.Release()
+	plain.ReportError(errors.New("ignored"), nil)
+}
+
+func TestRateLimitedReporter(t *testing.T) {
+	rec := &recordingReporter{}
+	limited := LimitReports(rec, 2, 30*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		limited.Report(errors.New("db down"), map[string]interface{}{"i": i})
+	}
+	limited.Report(errors.New("other"), nil)
+	if len(rec.errs) != 3 {
+		t.Fatalf("expected 2 identical + 1 distinct report, got %d", len(rec.errs))
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	limited.Report(errors.New("db down"), map[string]interface{}{"i": 5})
+	if len(rec.errs) != 4 || rec.fields[3]["suppressed"] != 3 || rec.fields[3]["i"] != 5 {
+		t.Errorf("expected suppressed count on next window, got %v", rec.fields[len(rec.fields)-1])
+	}
+}
+
+func TestCopyOutlivesRelease(t *testing.T) {
+	req := httptest.NewRequest("GET", "/orders/42", nil)
+	req.Header.Set("X-Request-ID", "req-9")
+	w := httptest.NewRecorder()
+	c := New(w, req)
+	c.Params = append(c.Params, Param{Key: "id", Value: "42"})
+	c.Set("user", "alice")
+
+	type snapshot struct{ user, id, header, path string }
+	start := make(chan struct{})
+	done := make(chan snapshot)
+
+	// handler 啟動 goroutine 後立即返回，Context 被回收
+	handler := func(c *Context) {
+		cp := c.Copy()
+		go func() {
+			<-start
+			cp.JSON(http.StatusTeapot, H{"ignored": true})
+			done <- snapshot{cp.GetString("user"), cp.Param("id"), cp.GetHeader("X-Request-ID"), cp.Request.URL.Path}
+		}()
+		c.String(http.StatusOK, "ok")
+	}
+	handler(c)
+	c.Release()
+	close(start)
+
+	got := <-done
+	if got != (snapshot{"alice", "42", "req-9", "/orders/42"}) {
+		t.Errorf("copy lost request data after release: %+v", got)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("writes on the copy must be ignored, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestCopyIsDetached(t *testing.T) {
+	c := New(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	defer c.Release()
+	c.Set("k", "v")
+
+	cp := c.Copy()
+	cp.Set("k", "changed")
+	if c.GetString("k") != "v" {
+		t.Error("Set on the copy must not affect the original")
+	}
+
+	ran := false
+	cp.handlers = HandlersChain{func(*Context) { ran = true }}
+	cp.Next()
+	if ran || !cp.IsAborted() {
+		t.Error("copy must not run handlers")
+	}
+}
+
+func TestMaxMultipartMemory(t *testing.T) {
+	content := strings.Repeat("x", 4<<10)
+
+	// 檔案超出上限時寫入暫存檔，Open 回傳 *os.File；未超出時留在記憶體
+	spilled := func(t *testing.T, fh *multipart.FileHeader) bool {
+		t.Helper()
+		f, err := fh.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+		_, onDisk := f.(*os.File)
+		return onDisk
+	}
+
+	t.Run("default", func(t *testing.T) {
+		c := New(httptest.NewRecorder(), newMultipartRequest(t, map[string]string{"a.txt": content}))
+		defer c.Release()
+		if c.MaxMultipartMemory() != 32<<20 {
+			t.Errorf("expected 32MB default, got %d", c.MaxMultipartMemory())
+		}
+		fh, err := c.FormFile("file")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if spilled(t, fh) {
+			t.Error("4KB upload should stay in memory under the default threshold")
+		}
+	})
+
+	t.Run("context override", func(t *testing.T) {
+		c := New(httptest.NewRecorder(), newMultipartRequest(t, map[string]string{"a.txt": content}))
+		defer c.Release()
+		c.SetMaxMultipartMemory(1 << 10)
+		form, err := c.MultipartForm()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer form.RemoveAll()
+		if !spilled(t, form.File["file"][0]) {
+			t.Error("4KB upload should be written to disk above a 1KB threshold")
+		}
+	})
+
+	t.Run("request context", func(t *testing.T) {
+		req := newMultipartRequest(t, map[string]string{"a.txt": content})
+		req = req.WithContext(WithMaxMultipartMemory(req.Context(), 1<<10))
+		var form struct {
+			Title string `form:"title"`
+		}
+		if err := FormMultipart.Bind(req, &form); err != nil || form.Title != "demo" {
+			t.Fatalf("bind: %v %+v", err, form)
+		}
+		defer req.MultipartForm.RemoveAll()
+		if !spilled(t, req.MultipartForm.File["file"][0]) {
+			t.Error("binding should honor the threshold from the request context")
+		}
+	})
+}
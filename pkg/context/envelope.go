@@ -0,0 +1,185 @@
+// @chris
+package context
+
+import (
+	"net/http"
+	"sync/atomic"
+)
+
+// ===== API 回應封裝 =====
+
+// EnvelopeFields Success / Fail / Paginated 使用的欄位名稱
+type EnvelopeFields struct {
+	Success    string // 預設 "success"
+	Data       string // 預設 "data"
+	Error      string // 預設 "error"
+	Meta       string // 預設 "meta"
+	Total      string // 預設 "total"
+	Page       string // 預設 "page"
+	PageSize   string // 預設 "page_size"
+	Pages      string // 預設 "pages"
+	NextCursor string // 預設 "next_cursor"
+	HasMore    string // 預設 "has_more"
+	Reference  string // 預設 "reference"
+}
+
+// defaultEnvelopeFields 預設欄位名稱
+var defaultEnvelopeFields = EnvelopeFields{
+	Success:    "success",
+	Data:       "data",
+	Error:      "error",
+	Meta:       "meta",
+	Total:      "total",
+	Page:       "page",
+	PageSize:   "page_size",
+	Pages:      "pages",
+	NextCursor: "next_cursor",
+	HasMore:    "has_more",
+	Reference:  "reference",
+}
+
+var envelopeFields atomic.Pointer[EnvelopeFields]
+
+func init() {
+	f := defaultEnvelopeFields
+	envelopeFields.Store(&f)
+}
+
+// SetEnvelopeFields 設定回應封裝的欄位名稱，未填寫的欄位沿用預設值
+// 應於啟動時設定一次
+func SetEnvelopeFields(fields EnvelopeFields) {
+	f := defaultEnvelopeFields
+	if fields.Success != "" {
+		f.Success = fields.Success
+	}
+	if fields.Data != "" {
+		f.Data = fields.Data
+	}
+	if fields.Error != "" {
+		f.Error = fields.Error
+	}
+	if fields.Meta != "" {
+		f.Meta = fields.Meta
+	}
+	if fields.Total != "" {
+		f.Total = fields.Total
+	}
+	if fields.Page != "" {
+		f.Page = fields.Page
+	}
+	if fields.PageSize != "" {
+		f.PageSize = fields.PageSize
+	}
+	if fields.Pages != "" {
+		f.Pages = fields.Pages
+	}
+	if fields.NextCursor != "" {
+		f.NextCursor = fields.NextCursor
+	}
+	if fields.HasMore != "" {
+		f.HasMore = fields.HasMore
+	}
+	if fields.Reference != "" {
+		f.Reference = fields.Reference
+	}
+	envelopeFields.Store(&f)
+}
+
+// GetEnvelopeFields 目前的回應封裝欄位名稱，供中間件組出與 Success / Fail 一致的回應
+func GetEnvelopeFields() EnvelopeFields {
+	return *envelopeFields.Load()
+}
+
+// Success 回應成功封裝：{"success": true, "data": data}
+func (c *Context) Success(code int, data interface{}) {
+	f := envelopeFields.Load()
+	c.JSON(code, map[string]interface{}{
+		f.Success: true,
+		f.Data:    data,
+	})
+}
+
+// Fail 中止並回應失敗封裝：{"success": false, "error": message}
+// 5xx 的 message 一律寫入請求日誌；release 模式改回應狀態碼文字與 "reference"，避免 err.Error() 等內部細節外洩
+func (c *Context) Fail(code int, message string) {
+	f := envelopeFields.Load()
+	body := map[string]interface{}{
+		f.Success: false,
+		f.Error:   message,
+	}
+	if code >= http.StatusInternalServerError {
+		c.logErrorDetail(code, message)
+		if !ExposeErrorDetails() {
+			body[f.Error] = http.StatusText(code)
+			body[f.Reference] = c.ErrorReference()
+		}
+	}
+	c.AbortWithStatusJSON(code, body)
+}
+
+// Paginated 回應分頁封裝（200）：{"success": true, "data": data, "meta": {"total", "page", "page_size"}}
+func (c *Context) Paginated(data interface{}, total, page, pageSize int) {
+	f := envelopeFields.Load()
+	c.JSON(http.StatusOK, map[string]interface{}{
+		f.Success: true,
+		f.Data:    data,
+		f.Meta: map[string]interface{}{
+			f.Total:    total,
+			f.Page:     page,
+			f.PageSize: pageSize,
+		},
+	})
+}
+
+// PageResult 頁碼分頁結果（hidb.Page 實作）
+type PageResult interface {
+	PageItems() interface{}
+	PageInfo() (total, page, pageSize, pages int)
+}
+
+// CursorResult 游標分頁結果（hidb.CursorPage 實作）
+type CursorResult interface {
+	PageItems() interface{}
+	CursorInfo() (nextCursor string, hasMore bool)
+}
+
+// PaginatedPage 以 Paginated 封裝回應頁碼分頁結果，meta 另含總頁數 "pages"
+//
+// EX：
+//
+//	page, err := hidb.Paginate[models.User](ctx, q, c.GetPage(), c.GetPageSize())
+//	if err != nil {
+//	    c.Error(err)
+//	    return
+//	}
+//	c.PaginatedPage(page)
+func (c *Context) PaginatedPage(p PageResult) {
+	f := envelopeFields.Load()
+	total, page, pageSize, pages := p.PageInfo()
+	c.JSON(http.StatusOK, map[string]interface{}{
+		f.Success: true,
+		f.Data:    p.PageItems(),
+		f.Meta: map[string]interface{}{
+			f.Total:    total,
+			f.Page:     page,
+			f.PageSize: pageSize,
+			f.Pages:    pages,
+		},
+	})
+}
+
+// PaginatedCursor 回應游標分頁結果（200）：{"success": true, "data": data, "meta": {"next_cursor", "has_more"}}
+// 沒有下一頁時省略 next_cursor
+func (c *Context) PaginatedCursor(p CursorResult) {
+	f := envelopeFields.Load()
+	next, hasMore := p.CursorInfo()
+	meta := map[string]interface{}{f.HasMore: hasMore}
+	if next != "" {
+		meta[f.NextCursor] = next
+	}
+	c.JSON(http.StatusOK, map[string]interface{}{
+		f.Success: true,
+		f.Data:    p.PageItems(),
+		f.Meta:    meta,
+	})
+}
@@ -0,0 +1,63 @@
+// @chris
+package context
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+)
+
+// ===== 錯誤細節曝露 =====
+
+// errorReferenceKey ErrorReference 在 Context 中的 key
+const errorReferenceKey = "error_reference"
+
+// ExposeErrorDetails 回應中是否附帶內部錯誤細節與堆疊
+// debug / test 模式為 true；release 模式為 false，5xx 只回應通用訊息與 reference，細節寫入日誌
+func ExposeErrorDetails() bool {
+	return Mode() != ReleaseMode
+}
+
+// ErrorReference 本請求錯誤的參考 ID，release 模式回應給客戶端並寫入日誌，供回報問題時對照
+// 優先沿用 request_id（RequestID 中間件或 X-Request-ID 標頭），否則產生隨機 ID；同一請求回傳相同值
+func (c *Context) ErrorReference() string {
+	if ref := c.GetString(errorReferenceKey); ref != "" {
+		return ref
+	}
+	ref := c.GetString("request_id")
+	if ref == "" && c.Request != nil {
+		ref = c.Request.Header.Get(HeaderXRequestID)
+	}
+	if ref == "" {
+		b := make([]byte, 8)
+		rand.Read(b)
+		ref = hex.EncodeToString(b)
+	}
+	c.Set(errorReferenceKey, ref)
+	return ref
+}
+
+// addErrorDetail 依模式為錯誤回應補上欄位：debug / test 附 detail；release 的 5xx 附 reference
+// 5xx 一律以請求 logger 記錄完整錯誤；公開錯誤本來就會回應給客戶端，不另外處理
+func (c *Context) addErrorDetail(body H, code int, msg *Error) {
+	if msg == nil || msg.IsType(ErrorTypePublic) {
+		return
+	}
+	if code >= http.StatusInternalServerError {
+		c.logErrorDetail(code, msg.Error())
+	}
+	if ExposeErrorDetails() {
+		body["detail"] = msg.Error()
+	} else if code >= http.StatusInternalServerError {
+		body[envelopeFields.Load().Reference] = c.ErrorReference()
+	}
+}
+
+// logErrorDetail 記錄回應給客戶端前被隱藏的錯誤細節
+func (c *Context) logErrorDetail(code int, detail string) {
+	c.Logger().Error("request failed",
+		"status", code,
+		"error", detail,
+		"reference", c.ErrorReference(),
+	)
+}
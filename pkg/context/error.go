@@ -4,6 +4,7 @@ package context
 import (
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"strings"
 )
 
@@ -24,8 +25,17 @@ type ErrorType uint64
 
 // ===== Context 錯誤處理方法 =====
 
-// Error 添加錯誤
+// Error 添加錯誤；非公開錯誤會交給 server 註冊的 ErrorReporter
 func (c *Context) Error(err error) *Error {
+	msg := c.collectError(err)
+	if msg != nil {
+		c.reportCollected(msg, 0)
+	}
+	return msg
+}
+
+// collectError 將錯誤加入 c.Errors（不回報）
+func (c *Context) collectError(err error) *Error {
 	if err == nil {
 		return nil
 	}
@@ -146,6 +156,12 @@ func (a errorMsgs) JSON() interface{} {
 	}
 }
 
+// PublicJSON 返回公開錯誤的訊息陣列，可直接回傳給客戶端；沒有公開錯誤時返回 nil
+// JSON() 會包含私有錯誤與 Meta，不適合直接輸出給使用者
+func (a errorMsgs) PublicJSON() []string {
+	return a.ByType(ErrorTypePublic).Errors()
+}
+
 // MarshalJSON 實現 json.Marshaler
 func (a errorMsgs) MarshalJSON() ([]byte, error) {
 	return json.Marshal(a.JSON())
@@ -214,6 +230,66 @@ func (c *Context) AddPrivateError(err error) *Error {
 	return c.AddError(err, ErrorTypePrivate, nil)
 }
 
+// AbortWithErrorJSON 記錄錯誤、中止並以 {"errors":[...]} 回應
+// 回應只包含公開錯誤的訊息；err 不是公開錯誤時（一般 error 預設為私有）改以狀態碼文字代替，
+// 避免內部細節外洩，完整錯誤仍保留在 c.Errors 供日誌使用。
+// debug / test 模式另附 "detail"；release 模式的 5xx 附 "reference"，完整錯誤寫入請求日誌
+//
+// EX：
+//
+//	if err := svc.Create(u); err != nil {
+//	    c.AbortWithErrorJSON(http.StatusConflict, c.AddPublicError(err))
+//	    return
+//	}
+func (c *Context) AbortWithErrorJSON(code int, err error) *Error {
+	msg, collected := err.(*Error)
+	if !collected || !c.Errors.contains(msg) {
+		msg = c.collectError(err)
+		if msg != nil && code >= http.StatusInternalServerError {
+			c.reportCollected(msg, code)
+		}
+	}
+	c.Abort()
+	body := H{"errors": publicMessages(c.Errors, code)}
+	c.addErrorDetail(body, code, msg)
+	c.JSON(code, body)
+	return msg
+}
+
+// contains 是否已收集該錯誤（AddPublicError 的回傳值不重複加入）
+func (a errorMsgs) contains(msg *Error) bool {
+	for _, e := range a {
+		if e == msg {
+			return true
+		}
+	}
+	return false
+}
+
+// publicMessages 公開錯誤訊息，沒有時以狀態碼文字代替
+func publicMessages(errs errorMsgs, code int) []string {
+	if msgs := errs.PublicJSON(); len(msgs) > 0 {
+		return msgs
+	}
+	return []string{http.StatusText(code)}
+}
+
+// RenderErrors 尚未寫出回應且有收集到錯誤時，以 {"errors":[...]} 輸出公開錯誤並返回 true
+// 狀態碼沿用處理器設定的非 2xx 狀態，否則為 500；由 middleware.ErrorHandler 在處理鏈結束後呼叫
+func (c *Context) RenderErrors() bool {
+	if len(c.Errors) == 0 || c.Writer == nil || c.Writer.Written() {
+		return false
+	}
+	code := c.Writer.Status()
+	if code < http.StatusBadRequest {
+		code = http.StatusInternalServerError
+	}
+	body := H{"errors": publicMessages(c.Errors, code)}
+	c.addErrorDetail(body, code, c.Errors.Last())
+	c.JSON(code, body)
+	return true
+}
+
 // ErrorJSON 返回錯誤的 JSON 響應
 func (c *Context) ErrorJSON(code int) {
 	c.JSON(code, c.Errors.JSON())
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -22,6 +23,8 @@ func (c *Context) initQuicConnection() {
 		StreamID: c.extractStreamID(),
 		Priority: c.extractPriority(),
 	}
+
+	c.sampleRTT()
 }
 
 // extractStreamID 提取流 ID
@@ -171,17 +174,17 @@ func (c *Context) UpdateBytesRead(bytes uint64) {
 
 // IsHTTP3 檢查是否為 HTTP/3
 func (c *Context) IsHTTP3() bool {
-	return c.protocol == HTTP3
+	return c.Protocol() == "HTTP/3"
 }
 
 // IsHTTP2 檢查是否為 HTTP/2
 func (c *Context) IsHTTP2() bool {
-	return c.protocol == HTTP2
+	return c.Protocol() == "HTTP/2"
 }
 
 // IsHTTP1 檢查是否為 HTTP/1.x
 func (c *Context) IsHTTP1() bool {
-	return c.protocol == HTTP1
+	return strings.HasPrefix(c.Protocol(), "HTTP/1")
 }
 
 // ===== Server Push =====
@@ -226,13 +229,28 @@ func (c *Context) PushResources(resources map[string]*http.PushOptions) []error
 
 // ===== 0-RTT (Early Data) =====
 
-// IsEarlyData 檢查是否為 0-RTT 早期數據
+// Is0RTT 請求是否以 0-RTT early data 送達（TLS 握手尚未完成），
+// 或由前端代理以 Early-Data: 1 標頭轉發（RFC 8470）
+//
+// 0-RTT 資料可被截取後重放，伺服器已對非安全方法回傳 425；
+// 若 GET 等安全方法仍有副作用（扣點、寄信），需在 handler 自行拒絕，客戶端會於握手完成後重送。
+//
+// EX：
+//
+//	if c.Is0RTT() {
+//		c.AbortWithStatus(context.StatusTooEarly)
+//		return
+//	}
+func (c *Context) Is0RTT() bool {
+	if c.Request.TLS != nil && !c.Request.TLS.HandshakeComplete {
+		return true
+	}
+	return c.GetHeader(HeaderEarlyData) == "1"
+}
+
+// IsEarlyData 檢查是否為 0-RTT 早期數據，等同 Is0RTT
 func (c *Context) IsEarlyData() bool {
-	if c.protocol != HTTP3 {
-		return false
-	}
-	// 檢查 Early-Data header
-	return c.GetHeader("Early-Data") == "1"
+	return c.Is0RTT()
 }
 
 // AcceptEarlyData 接受早期數據
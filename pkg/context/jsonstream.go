@@ -0,0 +1,166 @@
+// @chris
+package context
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// ===== JSON 陣列串流 =====
+
+// streamFlushBytes 累積超過此大小即 Flush，避免每個元素都觸發一次網路寫入
+const streamFlushBytes = 32 << 10
+
+// StreamArray 以 JSON 陣列逐筆輸出回應，資料集不需整個載入記憶體
+// fn 每呼叫一次 enc.Encode 即寫出一個陣列元素；每累積 32KB 及結束時 Flush
+// 標頭在第一次寫入前送出，之後無法再變更狀態碼：
+// fn 回傳錯誤或客戶端斷線時停止輸出且不補上結尾的 ]，讓客戶端得到無效 JSON 而非看似完整的截斷清單
+//
+// EX：
+//
+//	r.GET("/export/orders", func(c *context.Context) {
+//	    rows, _ := db.QueryContext(c.StdContext(), "SELECT ...")
+//	    defer rows.Close()
+//	    err := c.StreamArray(http.StatusOK, func(enc *json.Encoder) error {
+//	        for rows.Next() {
+//	            var o Order
+//	            if err := rows.Scan(&o.ID, &o.Total); err != nil {
+//	                return err
+//	            }
+//	            if err := enc.Encode(o); err != nil {
+//	                return err
+//	            }
+//	        }
+//	        return rows.Err()
+//	    })
+//	    if err != nil {
+//	        c.Logger().Errorw("export aborted", "error", err)
+//	    }
+//	})
+func (c *Context) StreamArray(code int, fn func(enc *json.Encoder) error) error {
+	w := c.beginArrayStream(code)
+	if err := fn(json.NewEncoder(w)); err != nil {
+		w.flush()
+		return err
+	}
+	return w.close()
+}
+
+// JSONStream 以 JSON 陣列逐筆輸出 ch 的元素，直到 ch 關閉
+// ch 暫時沒有資料時先 Flush 已寫出的部分，讓客戶端盡早收到
+// 客戶端斷線時停止輸出並回傳 ctx 錯誤；生產者應同樣監聽 c.Request.Context() 以免阻塞在送出
+//
+// EX：
+//
+//	ch := make(chan interface{})
+//	go func() {
+//	    defer close(ch)
+//	    for _, id := range ids {
+//	        select {
+//	        case ch <- load(id):
+//	        case <-c.Request.Context().Done():
+//	            return
+//	        }
+//	    }
+//	}()
+//	c.JSONStream(http.StatusOK, ch)
+func (c *Context) JSONStream(code int, ch <-chan interface{}) error {
+	w := c.beginArrayStream(code)
+	enc := json.NewEncoder(w)
+	done := c.Request.Context().Done()
+
+	for {
+		var item interface{}
+		var ok bool
+		select {
+		case item, ok = <-ch:
+		default:
+			w.flush()
+			select {
+			case item, ok = <-ch:
+			case <-done:
+				return c.Request.Context().Err()
+			}
+		}
+		if !ok {
+			return w.close()
+		}
+		if err := enc.Encode(item); err != nil {
+			w.flush()
+			return err
+		}
+	}
+}
+
+// beginArrayStream 送出標頭與開頭的 [
+func (c *Context) beginArrayStream(code int) *arrayStreamWriter {
+	header := c.Writer.Header()
+	header.Set("Content-Type", MIMEJSON+"; charset=utf-8")
+	header.Del("Content-Length")
+	c.Status(code)
+	c.Writer.WriteHeaderNow()
+
+	w := &arrayStreamWriter{w: c.Writer, req: c.Request}
+	w.err = w.write([]byte{'['})
+	return w
+}
+
+// arrayStreamWriter 包裝 json.Encoder 的輸出：每次 Write 為一個完整元素（Encode 只呼叫一次 Write），
+// 在元素之間補上逗號並依累積大小 Flush
+type arrayStreamWriter struct {
+	w       ResponseWriter
+	req     *http.Request
+	count   int
+	pending int
+	err     error
+}
+
+func (w *arrayStreamWriter) Write(p []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.req != nil {
+		if err := w.req.Context().Err(); err != nil {
+			w.err = err
+			return 0, err
+		}
+	}
+	if w.count > 0 {
+		if w.err = w.write([]byte{','}); w.err != nil {
+			return 0, w.err
+		}
+	}
+	if w.err = w.write(p); w.err != nil {
+		return 0, w.err
+	}
+	w.count++
+	if w.pending >= streamFlushBytes {
+		w.flush()
+	}
+	return len(p), nil
+}
+
+func (w *arrayStreamWriter) write(p []byte) error {
+	n, err := w.w.Write(p)
+	w.pending += n
+	return err
+}
+
+func (w *arrayStreamWriter) flush() {
+	if w.pending > 0 {
+		w.w.Flush()
+		w.pending = 0
+	}
+}
+
+// close 寫出結尾的 ] 並 Flush
+func (w *arrayStreamWriter) close() error {
+	if w.err != nil {
+		return w.err
+	}
+	if err := w.write([]byte{']', '\n'}); err != nil {
+		return err
+	}
+	w.flush()
+	return nil
+}
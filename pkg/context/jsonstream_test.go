@@ -0,0 +1,150 @@
+package context
+
+import (
+	stdcontext "context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// flushRecorder 記錄 Flush 次數，並在每次 Flush 時回報當下已送出的內容
+type flushRecorder struct {
+	*httptest.ResponseRecorder
+	flushes int
+	onFlush func(body string)
+}
+
+func (r *flushRecorder) Flush() {
+	r.flushes++
+	if r.onFlush != nil {
+		r.onFlush(r.Body.String())
+	}
+	r.ResponseRecorder.Flush()
+}
+
+func newFlushRecorder() *flushRecorder {
+	return &flushRecorder{ResponseRecorder: httptest.NewRecorder()}
+}
+
+func TestStreamArray(t *testing.T) {
+	type row struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}
+
+	w := newFlushRecorder()
+	c := New(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	err := c.StreamArray(http.StatusOK, func(enc *json.Encoder) error {
+		for i := 1; i <= 3; i++ {
+			if err := enc.Encode(row{ID: i, Name: "n"}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("StreamArray: %v", err)
+	}
+
+	var rows []row
+	if err := json.Unmarshal(w.Body.Bytes(), &rows); err != nil {
+		t.Fatalf("Expected valid JSON array, got %q: %v", w.Body.String(), err)
+	}
+	if len(rows) != 3 || rows[2].ID != 3 {
+		t.Errorf("Unexpected rows %+v", rows)
+	}
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+		t.Errorf("Unexpected status/headers %d %v", w.Code, w.Header())
+	}
+
+	// 空陣列
+	w = newFlushRecorder()
+	New(w, httptest.NewRequest(http.MethodGet, "/export", nil)).StreamArray(http.StatusOK, func(*json.Encoder) error { return nil })
+	if strings.TrimSpace(w.Body.String()) != "[]" {
+		t.Errorf("Expected empty array, got %q", w.Body.String())
+	}
+}
+
+func TestStreamArrayFlushesBySize(t *testing.T) {
+	w := newFlushRecorder()
+	c := New(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	item := strings.Repeat("x", 1000)
+	c.StreamArray(http.StatusOK, func(enc *json.Encoder) error {
+		for i := 0; i < 200; i++ {
+			enc.Encode(item)
+		}
+		return nil
+	})
+
+	// 約 200KB：每 32KB 一次，加上結尾
+	if w.flushes < 6 {
+		t.Errorf("Expected incremental flushes, got %d", w.flushes)
+	}
+	var items []string
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil || len(items) != 200 {
+		t.Errorf("Expected 200 items, got %d (%v)", len(items), err)
+	}
+}
+
+func TestStreamArrayErrorLeavesArrayOpen(t *testing.T) {
+	w := newFlushRecorder()
+	c := New(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+	boom := errors.New("scan failed")
+	err := c.StreamArray(http.StatusOK, func(enc *json.Encoder) error {
+		enc.Encode(1)
+		return boom
+	})
+	if err != boom {
+		t.Fatalf("Expected fn error, got %v", err)
+	}
+	if w.Body.String() != "[1\n" || json.Valid(w.Body.Bytes()) {
+		t.Errorf("Expected truncated (invalid) JSON, got %q", w.Body.String())
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	w := newFlushRecorder()
+	ch := make(chan interface{})
+	flushed := make(chan string, 8)
+	w.onFlush = func(body string) { flushed <- body }
+
+	go func() {
+		defer close(ch)
+		ch <- map[string]int{"id": 1}
+		// 生產者閒置時已寫出的部分會先 Flush：開頭的 [ 與第一筆都不必等到第二筆
+		for body := range flushed {
+			if body == "[{\"id\":1}\n" {
+				break
+			}
+			if body != "[" {
+				t.Errorf("Unexpected flushed body %q", body)
+			}
+		}
+		ch <- map[string]int{"id": 2}
+	}()
+
+	c := New(w, httptest.NewRequest(http.MethodGet, "/feed", nil))
+	if err := c.JSONStream(http.StatusOK, ch); err != nil {
+		t.Fatalf("JSONStream: %v", err)
+	}
+
+	var items []map[string]int
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil || len(items) != 2 || items[1]["id"] != 2 {
+		t.Errorf("Expected two items, got %q (%v)", w.Body.String(), err)
+	}
+}
+
+func TestJSONStreamClientGone(t *testing.T) {
+	ctx, cancel := stdcontext.WithCancel(stdcontext.Background())
+	req := httptest.NewRequest(http.MethodGet, "/feed", nil).WithContext(ctx)
+	ch := make(chan interface{})
+	cancel()
+
+	err := New(newFlushRecorder(), req).JSONStream(http.StatusOK, ch)
+	if !errors.Is(err, stdcontext.Canceled) {
+		t.Errorf("Expected context.Canceled when the client disconnects, got %v", err)
+	}
+}
@@ -0,0 +1,42 @@
+// @chris
+package context
+
+import "fmt"
+
+// ===== 語系與翻譯 =====
+
+// translatorKey Translator 在 Context.Keys 中的 key
+const translatorKey = "translator"
+
+// Translator 訊息翻譯介面，由 middleware.Localization 放入 Context.Keys（i18n.Bundle 為內建實作）
+type Translator interface {
+	// Translate 以 locale 查詢 key 的訊息，args 非空時以 fmt.Sprintf 格式化；找不到時回傳 key
+	Translate(locale, key string, args ...interface{}) string
+}
+
+// Locale 本請求解析出的語系（如 "zh-TW"），由 middleware.Localization 設定，未掛載時為空字串
+func (c *Context) Locale() string {
+	return c.GetLocale()
+}
+
+// SetTranslator 設置本請求使用的 Translator
+func (c *Context) SetTranslator(t Translator) {
+	c.Set(translatorKey, t)
+}
+
+// T 以本請求的語系翻譯 key；未掛載 Translator 時回傳 key（args 非空時以其格式化）
+//
+// EX：
+//
+//	c.JSON(http.StatusNotFound, context.H{"error": c.T("user.not_found", id)})
+func (c *Context) T(key string, args ...interface{}) string {
+	if v, exists := c.Get(translatorKey); exists {
+		if t, ok := v.(Translator); ok {
+			return t.Translate(c.Locale(), key, args...)
+		}
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(key, args...)
+	}
+	return key
+}
@@ -0,0 +1,46 @@
+// @chris
+package context
+
+import (
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+)
+
+// ===== 請求範圍的 logger =====
+
+// AttachLogger 以 base 建立帶有 request_id、method、path、protocol 欄位的子 logger 並附加到 Context
+// 通常由 middleware.RequestLogger 呼叫；需放在 RequestID 中間件之後才取得到 request_id
+func (c *Context) AttachLogger(base *logger.Logger) {
+	c.logger = base.With(c.requestLogFields()...)
+}
+
+// Logger 返回本請求的 logger，每一行都帶有請求欄位，方便與存取日誌關聯
+// 未掛上 RequestLogger 中間件時以全域 logger 延遲建立
+//
+// EX：
+//
+//	c.Logger().Infow("created user", "id", id)
+//	// [INFO] created user | request_id=… method=POST path=/users protocol=HTTP/2 id=42
+func (c *Context) Logger() *logger.Logger {
+	if c.logger == nil {
+		c.AttachLogger(logger.GetLogger())
+	}
+	return c.logger
+}
+
+// requestLogFields 請求關聯欄位（request_id 取自 RequestID 中間件，否則取 X-Request-ID 標頭）
+func (c *Context) requestLogFields() []interface{} {
+	requestID := c.GetString("request_id")
+	var method, path string
+	if c.Request != nil {
+		if requestID == "" {
+			requestID = c.Request.Header.Get(HeaderXRequestID)
+		}
+		method, path = c.Request.Method, c.Request.URL.Path
+	}
+	return []interface{}{
+		"request_id", requestID,
+		"method", method,
+		"path", path,
+		"protocol", c.Protocol(),
+	}
+}
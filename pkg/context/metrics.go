@@ -0,0 +1,52 @@
+// @chris
+package context
+
+import (
+	stdcontext "context"
+	"time"
+
+	"github.com/quic-go/quic-go"
+)
+
+// ===== 請求指標 =====
+
+// quicConnKey 於 HTTP/3 連線 context 中存放 *quic.Conn
+type quicConnKey struct{}
+
+// WithQUICConn 將 QUIC 連線附加到標準 context.Context
+// 由 server 作為 http3.Server.ConnContext 注入，Context 據此讀取 RTT
+func WithQUICConn(parent stdcontext.Context, conn *quic.Conn) stdcontext.Context {
+	return stdcontext.WithValue(parent, quicConnKey{}, conn)
+}
+
+// QUICConnFromContext 取得 WithQUICConn 附加的 QUIC 連線
+func QUICConnFromContext(ctx stdcontext.Context) (*quic.Conn, bool) {
+	conn, ok := ctx.Value(quicConnKey{}).(*quic.Conn)
+	return conn, ok && conn != nil
+}
+
+// sampleRTT 讀取 QUIC 連線目前的平滑 RTT，同步至 RequestMetrics 與 GetRTT
+func (c *Context) sampleRTT() {
+	if c.Request == nil {
+		return
+	}
+	conn, ok := QUICConnFromContext(c.Request.Context())
+	if !ok {
+		return
+	}
+	rtt := conn.ConnectionStats().SmoothedRTT
+	c.metrics.RTT = rtt
+	c.SetRTT(rtt)
+}
+
+// Elapsed 自請求進入 router 至今的時間
+func (c *Context) Elapsed() time.Duration {
+	return time.Since(c.startTime)
+}
+
+// Metrics 回傳目前請求指標的快照
+// Duration 為至今耗時；BytesIn / BytesOut 為已讀取的請求 body 與已寫出的回應 body；
+// HTTP/3 的 RTT 於呼叫時重新取樣。回傳複本，可安全保存於日誌或交給其他 goroutine。
+func (c *Context) Metrics() RequestMetrics {
+	return *c.GetMetrics()
+}
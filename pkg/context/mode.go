@@ -0,0 +1,70 @@
+// @chris
+package context
+
+import (
+	"log"
+	"os"
+	"sync/atomic"
+)
+
+// H 便捷的 JSON 物件型別
+// EX：c.JSON(200, context.H{"message": "ok"})
+type H map[string]interface{}
+
+// ===== 運行模式 =====
+
+const (
+	// DebugMode 開發模式：JSON 格式化輸出、Recovery 回應中附帶堆疊
+	DebugMode = "debug"
+	// TestMode 測試模式
+	TestMode = "test"
+	// ReleaseMode 正式模式（預設）
+	ReleaseMode = "release"
+)
+
+// EnvMode 啟動時讀取的模式環境變數
+const EnvMode = "HYPGO_MODE"
+
+var currentMode atomic.Value
+
+func init() {
+	currentMode.Store(envMode(os.Getenv(EnvMode)))
+}
+
+// envMode 解析 HYPGO_MODE；無法識別時退回 release 並警告，不在 main 執行前 panic
+func envMode(mode string) string {
+	if mode == "" {
+		return ReleaseMode
+	}
+	if !isValidMode(mode) {
+		log.Printf("[hypgo] unknown %s %q (available: debug, test, release), using release", EnvMode, mode)
+		return ReleaseMode
+	}
+	return mode
+}
+
+func isValidMode(mode string) bool {
+	switch mode {
+	case DebugMode, TestMode, ReleaseMode:
+		return true
+	}
+	return false
+}
+
+// SetMode 設置運行模式，僅接受 debug / test / release，其他值 panic
+func SetMode(mode string) {
+	if !isValidMode(mode) {
+		panic("hypgo: unknown mode " + mode + " (available: debug, test, release)")
+	}
+	currentMode.Store(mode)
+}
+
+// Mode 返回目前的運行模式
+func Mode() string {
+	return currentMode.Load().(string)
+}
+
+// IsDebugging 是否為 debug 模式
+func IsDebugging() bool {
+	return Mode() == DebugMode
+}
@@ -0,0 +1,52 @@
+// @chris
+package context
+
+import (
+	stdcontext "context"
+	"net/http"
+)
+
+// ===== multipart 記憶體上限 =====
+
+// maxMultipartMemoryKey 用於在 request context 中存放 multipart 記憶體上限的 key
+type maxMultipartMemoryKey struct{}
+
+// WithMaxMultipartMemory 將 multipart 表單的記憶體上限附加到標準 context.Context
+// 由 server 包裝層依 server.max_multipart_memory 注入；n <= 0 時沿用預設 32MB
+func WithMaxMultipartMemory(parent stdcontext.Context, n int64) stdcontext.Context {
+	return stdcontext.WithValue(parent, maxMultipartMemoryKey{}, n)
+}
+
+// SetMaxMultipartMemory 覆寫本請求解析 multipart 表單時保留在記憶體的上限，超出的檔案部分寫入暫存檔
+// 須在 FormFile、MultipartForm、PostForm 或表單綁定第一次解析前呼叫，解析結果會被快取
+//
+// EX：
+//
+//	r.POST("/videos", func(c *context.Context) {
+//	    c.SetMaxMultipartMemory(8 << 20) // 大檔直接落地，避免佔用記憶體
+//	    file, err := c.FormFile("video")
+//	    ...
+//	})
+func (c *Context) SetMaxMultipartMemory(n int64) {
+	if c.Request == nil {
+		return
+	}
+	c.Request = c.Request.WithContext(WithMaxMultipartMemory(c.Request.Context(), n))
+}
+
+// MaxMultipartMemory 本請求的 multipart 記憶體上限
+// 依序採用 SetMaxMultipartMemory、server.max_multipart_memory，皆未設定時為 32MB
+func (c *Context) MaxMultipartMemory() int64 {
+	return maxMultipartMemory(c.Request)
+}
+
+// maxMultipartMemory 取得請求的 multipart 記憶體上限，供 Context 與綁定器共用
+func maxMultipartMemory(req *http.Request) int64 {
+	if req == nil {
+		return defaultMemory
+	}
+	if n, _ := req.Context().Value(maxMultipartMemoryKey{}).(int64); n > 0 {
+		return n
+	}
+	return defaultMemory
+}
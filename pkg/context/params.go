@@ -0,0 +1,94 @@
+// @chris
+package context
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ===== 型別化參數解析 =====
+
+// ParamInt 將路由參數解析為 int
+func (c *Context) ParamInt(key string) (int, error) {
+	v, err := strconv.Atoi(c.Param(key))
+	if err != nil {
+		return 0, fmt.Errorf("param %q: %w", key, err)
+	}
+	return v, nil
+}
+
+// ParamInt64 將路由參數解析為 int64
+func (c *Context) ParamInt64(key string) (int64, error) {
+	v, err := strconv.ParseInt(c.Param(key), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("param %q: %w", key, err)
+	}
+	return v, nil
+}
+
+// MustParamInt 解析路由參數為 int，失敗時回應 400 並中止，ok 為 false 時 handler 應直接返回
+// EX：
+//
+//	id, ok := c.MustParamInt("id")
+//	if !ok {
+//		return
+//	}
+func (c *Context) MustParamInt(key string) (int, bool) {
+	v, err := c.ParamInt(key)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{
+			"error": fmt.Sprintf("invalid parameter: %s", key),
+		})
+		return 0, false
+	}
+	return v, true
+}
+
+// QueryInt 將查詢參數解析為 int，不存在或格式錯誤時回傳 defaultValue
+func (c *Context) QueryInt(key string, defaultValue int) int {
+	if s, ok := c.GetQuery(key); ok {
+		if v, err := strconv.Atoi(s); err == nil {
+			return v
+		}
+	}
+	return defaultValue
+}
+
+// QueryBool 將查詢參數解析為 bool（接受 1/0、true/false、t/f 等），不存在或格式錯誤時回傳 defaultValue
+func (c *Context) QueryBool(key string, defaultValue bool) bool {
+	if s, ok := c.GetQuery(key); ok {
+		if v, err := strconv.ParseBool(s); err == nil {
+			return v
+		}
+	}
+	return defaultValue
+}
+
+// QueryFloat 將查詢參數解析為 float64，不存在或格式錯誤時回傳 defaultValue
+func (c *Context) QueryFloat(key string, defaultValue float64) float64 {
+	if s, ok := c.GetQuery(key); ok {
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return v
+		}
+	}
+	return defaultValue
+}
+
+// QueryTime 依 layout 解析查詢參數為 time.Time（layout 為空時使用 RFC3339）
+// 參數不存在時回傳零值與 nil，格式錯誤時回傳錯誤
+func (c *Context) QueryTime(key, layout string) (time.Time, error) {
+	s, ok := c.GetQuery(key)
+	if !ok || s == "" {
+		return time.Time{}, nil
+	}
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("query %q: %w", key, err)
+	}
+	return t, nil
+}
@@ -0,0 +1,491 @@
+// @chris
+package context
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ===== 部分更新綁定 =====
+
+// BindPatch 以 PATCH 語意綁定請求（失敗會 abort 400）
+// 詳見 ShouldBindPatch
+func (c *Context) BindPatch(dest interface{}) (fields []string, err error) {
+	fields, err = c.ShouldBindPatch(dest)
+	if err != nil {
+		c.AbortWithError(http.StatusBadRequest, err).SetType(ErrorTypeBind)
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ShouldBindPatch 以 PATCH 語意綁定請求（不會 abort），只覆寫請求中出現的欄位
+// 回傳被修改欄位的 json 名稱（依結構體欄位順序），供服務層組出只更新這些欄位的 UPDATE。
+//
+// 依 Content-Type 選擇模式：
+//   - application/json-patch+json：RFC 6902 操作陣列（add / remove / replace / move / copy / test），
+//     path 以 JSON Pointer 指向欄位，remove 頂層欄位會將其設為零值
+//   - 其他（application/json、application/merge-patch+json）：JSON 物件，出現的鍵即為要更新的欄位，
+//     值為零值或 null 也算出現（null 將欄位設為零值）；巢狀物件整體取代
+//
+// dest 通常先載入既有資料，再套用修補。
+//
+// EX：
+//
+//	user, _ := svc.Get(id)
+//	fields, err := c.BindPatch(&user)
+//	if err != nil { return }
+//	svc.Update(id, user, fields) // UPDATE users SET <fields> WHERE id = ?
+func (c *Context) ShouldBindPatch(dest interface{}) ([]string, error) {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("patch destination must be a non-nil pointer to struct, got %T", dest)
+	}
+	target := rv.Elem()
+	index := patchFieldIndex(target.Type())
+
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes map[string]json.RawMessage
+	if c.ContentType() == MIMEJSONPatch {
+		changes, err = applyJSONPatch(body, target, index)
+	} else {
+		changes, err = decodeMergePatch(body, index)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 先套用到副本，全部成功才寫回 dest，避免失敗時留下修補到一半的資料
+	work := reflect.New(target.Type()).Elem()
+	work.Set(target)
+	var fields []string
+	for _, f := range index.fields {
+		raw, ok := changes[f.name]
+		if !ok {
+			continue
+		}
+		field, err := patchFieldValue(work, f.index)
+		if err == nil {
+			err = setPatchField(field, raw)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name, err)
+		}
+		fields = append(fields, f.name)
+	}
+	target.Set(work)
+	return fields, nil
+}
+
+// patchFieldValue 取得 v 中 index 路徑的欄位以供寫入
+// 途經的嵌入指標一律換成新配置的副本（nil 時為零值），寫入不會經由共用指標改到原結構體
+func patchFieldValue(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if !v.CanSet() {
+				return reflect.Value{}, fmt.Errorf("cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+			}
+			elem := reflect.New(v.Type().Elem())
+			if !v.IsNil() {
+				elem.Elem().Set(v.Elem())
+			}
+			v.Set(elem)
+			v = elem.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// patchField 可修補的結構體欄位
+type patchField struct {
+	name  string // json 名稱
+	index []int  // reflect.Value.FieldByIndex 路徑（含嵌入結構體）
+}
+
+type patchIndex struct {
+	fields []patchField
+	byName map[string]int
+}
+
+// patchFieldIndex 依 encoding/json 的命名規則建立欄位索引：採用 json 標籤名稱、略過 "-" 與未匯出欄位、
+// 展開匿名嵌入結構體
+func patchFieldIndex(t reflect.Type) patchIndex {
+	idx := patchIndex{byName: make(map[string]int)}
+	for _, sf := range reflect.VisibleFields(t) {
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if sf.Anonymous && name == "" && derefType(sf.Type).Kind() == reflect.Struct {
+			continue // 由 VisibleFields 提升的子欄位代表
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if _, dup := idx.byName[name]; dup {
+			continue // 外層欄位優先
+		}
+		idx.byName[name] = len(idx.fields)
+		idx.fields = append(idx.fields, patchField{name: name, index: sf.Index})
+	}
+	return idx
+}
+
+// lookup 以 json 名稱查找欄位，找不到時與 encoding/json 一樣退回不分大小寫比對
+func (idx patchIndex) lookup(key string) (string, bool) {
+	if _, ok := idx.byName[key]; ok {
+		return key, true
+	}
+	for _, f := range idx.fields {
+		if strings.EqualFold(f.name, key) {
+			return f.name, true
+		}
+	}
+	return "", false
+}
+
+// decodeMergePatch 解析 JSON 物件，回傳以欄位名稱為鍵的原始值
+func decodeMergePatch(body []byte, idx patchIndex) (map[string]json.RawMessage, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, err
+	}
+	if raw == nil {
+		return nil, errors.New("patch body must be a JSON object")
+	}
+
+	changes := make(map[string]json.RawMessage, len(raw))
+	for key, value := range raw {
+		name, ok := idx.lookup(key)
+		if !ok {
+			if EnableDecoderDisallowUnknownFields {
+				return nil, fmt.Errorf("unknown field %q in request body", key)
+			}
+			continue
+		}
+		changes[name] = value
+	}
+	return changes, nil
+}
+
+// setPatchField 將原始 JSON 值寫入欄位；null 設為零值，其餘整體取代原值
+func setPatchField(field reflect.Value, raw json.RawMessage) error {
+	if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+		field.Set(reflect.Zero(field.Type()))
+		return nil
+	}
+	v := reflect.New(field.Type())
+	if err := json.Unmarshal(raw, v.Interface()); err != nil {
+		return err
+	}
+	field.Set(v.Elem())
+	return nil
+}
+
+// ===== RFC 6902 JSON Patch =====
+
+// jsonPatchOp 單一 JSON Patch 操作
+type jsonPatchOp struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// applyJSONPatch 將結構體轉為 JSON 文件後依序套用操作，回傳被觸及的頂層欄位的新值；
+// 被移除的欄位以 null 表示。任一操作失敗則不修改 dest
+func applyJSONPatch(body []byte, target reflect.Value, idx patchIndex) (map[string]json.RawMessage, error) {
+	var ops []jsonPatchOp
+	if err := json.Unmarshal(body, &ops); err != nil {
+		return nil, fmt.Errorf("json patch: %w", err)
+	}
+
+	// 逐欄位序列化，避免 omitempty 讓零值欄位從文件中消失
+	doc := make(map[string]interface{}, len(idx.fields))
+	for _, f := range idx.fields {
+		field, err := target.FieldByIndexErr(f.index)
+		if err != nil {
+			// 經過 nil 嵌入指標的欄位視為零值
+			field = reflect.Zero(target.Type().FieldByIndex(f.index).Type)
+		}
+		v, err := toJSONValue(field.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", f.name, err)
+		}
+		doc[f.name] = v
+	}
+
+	touched := make(map[string]bool)
+	for i, op := range ops {
+		if err := applyPatchOp(doc, op, idx, touched); err != nil {
+			return nil, fmt.Errorf("json patch operation %d (%s %s): %w", i, op.Op, op.Path, err)
+		}
+	}
+
+	changes := make(map[string]json.RawMessage, len(touched))
+	for name := range touched {
+		v, ok := doc[name]
+		if !ok {
+			changes[name] = json.RawMessage("null")
+			continue
+		}
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		changes[name] = raw
+	}
+	return changes, nil
+}
+
+func applyPatchOp(doc map[string]interface{}, op jsonPatchOp, idx patchIndex, touched map[string]bool) error {
+	path, err := parsePointer(op.Path, idx)
+	if err != nil {
+		return err
+	}
+
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.Value == nil {
+			return errors.New("missing value")
+		}
+		value, err := toJSONValue(op.Value)
+		if err != nil {
+			return err
+		}
+		switch op.Op {
+		case "add":
+			err = pointerAdd(doc, path, value)
+		case "replace":
+			err = pointerReplace(doc, path, value)
+		default:
+			var current interface{}
+			if current, err = pointerGet(doc, path); err == nil && !reflect.DeepEqual(current, value) {
+				err = errors.New("test failed: value does not match")
+			}
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+	case "remove":
+		if _, err := pointerRemove(doc, path); err != nil {
+			return err
+		}
+
+	case "move", "copy":
+		from, err := parsePointer(op.From, idx)
+		if err != nil {
+			return fmt.Errorf("from: %w", err)
+		}
+		var value interface{}
+		if op.Op == "move" {
+			if len(from) < len(path) && pointerHasPrefix(path, from) {
+				return errors.New("cannot move a value into one of its children")
+			}
+			value, err = pointerRemove(doc, from)
+			touched[from[0]] = true
+		} else {
+			// 深拷貝，避免之後的操作同時修改來源與副本
+			if value, err = pointerGet(doc, from); err == nil {
+				value, err = toJSONValue(value)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if err := pointerAdd(doc, path, value); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unsupported op %q", op.Op)
+	}
+
+	touched[path[0]] = true
+	return nil
+}
+
+// parsePointer 解析 RFC 6901 JSON Pointer；第一段必須是 dest 的欄位，不支援對整份文件操作
+func parsePointer(ptr string, idx patchIndex) ([]string, error) {
+	if ptr == "" {
+		return nil, errors.New("operations on the document root are not supported")
+	}
+	if !strings.HasPrefix(ptr, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer %q", ptr)
+	}
+	tokens := strings.Split(ptr[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	if _, ok := idx.byName[tokens[0]]; !ok {
+		return nil, fmt.Errorf("unknown field %q", tokens[0])
+	}
+	return tokens, nil
+}
+
+func pointerHasPrefix(path, prefix []string) bool {
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// toJSONValue 轉為 encoding/json 的通用表示（map / []interface{} / float64 ...），同時完成深拷貝
+func toJSONValue(v interface{}) (interface{}, error) {
+	raw, ok := v.(json.RawMessage)
+	if !ok {
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+	var out interface{}
+	err := json.Unmarshal(raw, &out)
+	return out, err
+}
+
+// pointerParent 走到 path 最後一段的容器
+func pointerParent(doc map[string]interface{}, path []string) (interface{}, error) {
+	var node interface{} = doc
+	for _, token := range path[:len(path)-1] {
+		child, err := pointerChild(node, token)
+		if err != nil {
+			return nil, err
+		}
+		node = child
+	}
+	return node, nil
+}
+
+func pointerChild(node interface{}, token string) (interface{}, error) {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		child, ok := n[token]
+		if !ok {
+			return nil, fmt.Errorf("path segment %q not found", token)
+		}
+		return child, nil
+	case []interface{}:
+		i, err := arrayIndex(token, len(n)-1)
+		if err != nil {
+			return nil, err
+		}
+		return n[i], nil
+	default:
+		return nil, fmt.Errorf("path segment %q is not inside an object or array", token)
+	}
+}
+
+// arrayIndex 解析陣列索引並檢查 0 <= i <= max
+func arrayIndex(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && token[0] == '0') {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("array index %d out of range", i)
+	}
+	return i, nil
+}
+
+func pointerGet(doc map[string]interface{}, path []string) (interface{}, error) {
+	parent, err := pointerParent(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	return pointerChild(parent, path[len(path)-1])
+}
+
+// pointerSet 將容器中的陣列替換為新 slice（插入 / 刪除元素後長度改變）
+func pointerSet(doc map[string]interface{}, path []string, value interface{}) error {
+	parent, err := pointerParent(doc, path)
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+	case []interface{}:
+		i, err := arrayIndex(last, len(p)-1)
+		if err != nil {
+			return err
+		}
+		p[i] = value
+	}
+	return nil
+}
+
+func pointerAdd(doc map[string]interface{}, path []string, value interface{}) error {
+	parent, err := pointerParent(doc, path)
+	if err != nil {
+		return err
+	}
+	last := path[len(path)-1]
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		p[last] = value
+		return nil
+	case []interface{}:
+		i := len(p)
+		if last != "-" {
+			if i, err = arrayIndex(last, len(p)); err != nil {
+				return err
+			}
+		}
+		grown := make([]interface{}, 0, len(p)+1)
+		grown = append(append(append(grown, p[:i]...), value), p[i:]...)
+		return pointerSet(doc, path[:len(path)-1], grown)
+	default:
+		return fmt.Errorf("path segment %q is not inside an object or array", last)
+	}
+}
+
+func pointerReplace(doc map[string]interface{}, path []string, value interface{}) error {
+	if _, err := pointerGet(doc, path); err != nil {
+		return err
+	}
+	return pointerSet(doc, path, value)
+}
+
+func pointerRemove(doc map[string]interface{}, path []string) (interface{}, error) {
+	parent, err := pointerParent(doc, path)
+	if err != nil {
+		return nil, err
+	}
+	last := path[len(path)-1]
+	removed, err := pointerChild(parent, last)
+	if err != nil {
+		return nil, err
+	}
+	switch p := parent.(type) {
+	case map[string]interface{}:
+		delete(p, last)
+	case []interface{}:
+		i, _ := arrayIndex(last, len(p)-1)
+		shrunk := append(append(make([]interface{}, 0, len(p)-1), p[:i]...), p[i+1:]...)
+		if err := pointerSet(doc, path[:len(path)-1], shrunk); err != nil {
+			return nil, err
+		}
+	}
+	return removed, nil
+}
@@ -0,0 +1,198 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type patchAudit struct {
+	UpdatedBy string `json:"updated_by"`
+}
+
+type patchUser struct {
+	patchAudit
+	Name     string            `json:"name"`
+	Age      int               `json:"age,omitempty"`
+	Active   bool              `json:"active"`
+	Nickname *string           `json:"nickname"`
+	Tags     []string          `json:"tags"`
+	Meta     map[string]string `json:"meta,omitempty"`
+	Secret   string            `json:"-"`
+}
+
+func patchCtx(contentType, body string) (*Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(http.MethodPatch, "/api/users/1", strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	return New(w, req), w
+}
+
+func existingPatchUser() patchUser {
+	nick := "ally"
+	return patchUser{
+		patchAudit: patchAudit{UpdatedBy: "system"},
+		Name:       "alice",
+		Age:        30,
+		Active:     true,
+		Nickname:   &nick,
+		Tags:       []string{"a", "b"},
+		Secret:     "keep",
+	}
+}
+
+func TestBindPatchMergePresence(t *testing.T) {
+	// 零值與 null 也算出現；未出現的欄位保持原值
+	c, _ := patchCtx(MIMEJSON, `{"age":0,"active":false,"nickname":null,"updated_by":"bob","unknown":1,"-":"x"}`)
+	u := existingPatchUser()
+
+	fields, err := c.BindPatch(&u)
+	if err != nil {
+		t.Fatalf("BindPatch: %v", err)
+	}
+	if want := []string{"updated_by", "age", "active", "nickname"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	if u.Age != 0 || u.Active || u.Nickname != nil || u.UpdatedBy != "bob" {
+		t.Errorf("Expected zero values applied, got %+v", u)
+	}
+	if u.Name != "alice" || len(u.Tags) != 2 || u.Secret != "keep" {
+		t.Errorf("Expected absent fields untouched, got %+v", u)
+	}
+}
+
+func TestBindPatchMergeEmptyAndInvalid(t *testing.T) {
+	c, _ := patchCtx(MIMEMergePatch, `{}`)
+	u := existingPatchUser()
+	fields, err := c.ShouldBindPatch(&u)
+	if err != nil || len(fields) != 0 {
+		t.Errorf("Expected no fields for empty object, got %v (%v)", fields, err)
+	}
+
+	c, w := patchCtx(MIMEJSON, `{"age":"old"}`)
+	if _, err := c.BindPatch(&u); err == nil || w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 on type mismatch, got %d (%v)", w.Code, err)
+	}
+	if u.Age != 30 {
+		t.Errorf("Expected age untouched after failure, got %d", u.Age)
+	}
+
+	c, _ = patchCtx(MIMEJSON, `[1]`)
+	if _, err := c.ShouldBindPatch(&u); err == nil {
+		t.Error("Expected error for non-object body")
+	}
+	if _, err := c.ShouldBindPatch(u); err == nil {
+		t.Error("Expected error for non-pointer destination")
+	}
+}
+
+func TestBindPatchJSONPatch(t *testing.T) {
+	c, _ := patchCtx(MIMEJSONPatch, `[
+		{"op":"test","path":"/name","value":"alice"},
+		{"op":"replace","path":"/age","value":0},
+		{"op":"add","path":"/tags/1","value":"x"},
+		{"op":"add","path":"/tags/-","value":"z"},
+		{"op":"remove","path":"/nickname"},
+		{"op":"add","path":"/meta","value":{"k":"v"}},
+		{"op":"copy","from":"/meta/k","path":"/meta/k2"},
+		{"op":"move","from":"/updated_by","path":"/name"}
+	]`)
+	u := existingPatchUser()
+
+	fields, err := c.ShouldBindPatch(&u)
+	if err != nil {
+		t.Fatalf("ShouldBindPatch: %v", err)
+	}
+	if want := []string{"updated_by", "name", "age", "nickname", "tags", "meta"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	if u.Name != "system" || u.UpdatedBy != "" || u.Age != 0 || u.Nickname != nil {
+		t.Errorf("Unexpected scalar fields: %+v", u)
+	}
+	if !reflect.DeepEqual(u.Tags, []string{"a", "x", "b", "z"}) {
+		t.Errorf("tags = %v", u.Tags)
+	}
+	if !reflect.DeepEqual(u.Meta, map[string]string{"k": "v", "k2": "v"}) {
+		t.Errorf("meta = %v", u.Meta)
+	}
+	if !u.Active {
+		t.Error("Expected untouched field to keep its value")
+	}
+}
+
+func TestBindPatchJSONPatchErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{"failed test", `[{"op":"replace","path":"/age","value":1},{"op":"test","path":"/name","value":"bob"}]`},
+		{"unknown field", `[{"op":"add","path":"/password","value":"x"}]`},
+		{"document root", `[{"op":"replace","path":"","value":{}}]`},
+		{"replace missing", `[{"op":"replace","path":"/meta/k","value":"v"}]`},
+		{"index out of range", `[{"op":"add","path":"/tags/5","value":"x"}]`},
+		{"missing value", `[{"op":"add","path":"/name"}]`},
+		{"unsupported op", `[{"op":"merge","path":"/name","value":"x"}]`},
+		{"not an array", `{"name":"x"}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, _ := patchCtx(MIMEJSONPatch, tc.body)
+			u := existingPatchUser()
+			if _, err := c.ShouldBindPatch(&u); err == nil {
+				t.Fatal("Expected error")
+			}
+			// 任一操作失敗時 dest 不被修改
+			if !reflect.DeepEqual(u, existingPatchUser()) {
+				t.Errorf("Expected destination untouched, got %+v", u)
+			}
+		})
+	}
+}
+
+type PatchBase struct {
+	Owner string `json:"owner"`
+}
+
+type patchDoc struct {
+	*PatchBase
+	Title string `json:"title"`
+	Count int    `json:"count"`
+}
+
+func TestBindPatchNilEmbeddedPointer(t *testing.T) {
+	for _, tc := range []struct{ contentType, body string }{
+		{MIMEJSON, `{"owner":"bob"}`},
+		{MIMEJSONPatch, `[{"op":"replace","path":"/owner","value":"bob"}]`},
+	} {
+		c, _ := patchCtx(tc.contentType, tc.body)
+		var doc patchDoc
+		fields, err := c.ShouldBindPatch(&doc)
+		if err != nil || doc.PatchBase == nil || doc.Owner != "bob" || !reflect.DeepEqual(fields, []string{"owner"}) {
+			t.Errorf("%s: got %+v %v %v", tc.contentType, doc.PatchBase, fields, err)
+		}
+		c.Release()
+	}
+}
+
+func TestBindPatchFailureLeavesDestUnchanged(t *testing.T) {
+	base := &PatchBase{Owner: "alice"}
+	doc := patchDoc{PatchBase: base, Title: "draft", Count: 1}
+
+	c, _ := patchCtx(MIMEJSON, `{"owner":"bob","title":"final","count":"many"}`)
+	defer c.Release()
+	if _, err := c.ShouldBindPatch(&doc); err == nil {
+		t.Fatal("expected type error")
+	}
+	if doc.Title != "draft" || doc.Count != 1 || doc.PatchBase != base || base.Owner != "alice" {
+		t.Errorf("dest modified by failed patch: %+v %+v", doc, *base)
+	}
+
+	// 成功時也不經由共用的嵌入指標修改呼叫端的其他資料
+	c2, _ := patchCtx(MIMEJSON, `{"owner":"bob"}`)
+	defer c2.Release()
+	if _, err := c2.ShouldBindPatch(&doc); err != nil || doc.Owner != "bob" || base.Owner != "alice" {
+		t.Errorf("got %+v %+v %v", *doc.PatchBase, *base, err)
+	}
+}
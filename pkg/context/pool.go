@@ -75,9 +75,10 @@ func AcquireContext(w http.ResponseWriter, r *http.Request) *Context {
 	c := contextPool.Get().(*Context)
 	c.reset()
 	c.Request = r
-	c.Response = acquireResponseWriter(w)
-	c.Writer = c.Response // Gin 兼容別名，必須設置
 	c.metrics = acquireMetrics()
+	c.Response = acquireResponseWriter(w, c.metrics)
+	c.Writer = c.Response // Gin 兼容別名，必須設置
+	c.trackRequestBody()
 	c.startTime = time.Now()
 
 	// 檢測並設置協議
@@ -137,6 +138,11 @@ func (c *Context) reset() {
 	// 清理快取：直接置 nil，下次使用時延遲初始化
 	c.queryCache = nil
 	c.formCache = nil
+	c.rawData = nil
+	c.Accepted = nil
+
+	// 路由模板由 router 匹配時設定；未匹配（404）的請求必須為空
+	c.fullPath = ""
 
 	c.index = -1
 	c.protocol = 0
@@ -146,16 +152,20 @@ func (c *Context) reset() {
 	c.schemaInput = nil
 	c.schemaRouteKey = ""
 	c.bindInputCalled = false
+
+	// 請求範圍的 logger 不可帶到下一個請求
+	c.logger = nil
 }
 
 // ===== ResponseWriter 池操作 =====
 
-// acquireResponseWriter 從池中獲取 ResponseWriter
-func acquireResponseWriter(w http.ResponseWriter) ResponseWriter {
+// acquireResponseWriter 從池中獲取 ResponseWriter，寫出量同步累計至 metrics.BytesOut
+func acquireResponseWriter(w http.ResponseWriter, metrics *RequestMetrics) ResponseWriter {
 	rw := responseWriterPool.Get().(*responseWriter)
 	rw.reset()
 	rw.ResponseWriter = w
 	rw.status = http.StatusOK
+	rw.metrics = metrics
 	return rw
 }
 
@@ -175,6 +185,7 @@ func (w *responseWriter) reset() {
 	w.size = 0
 	w.written = false
 	w.streamID = 0
+	w.metrics = nil
 }
 
 // ===== RequestMetrics 池操作 =====
@@ -348,6 +359,8 @@ func (c *Context) initQuicConnectionFromPool() {
 	c.streamInfo = acquireStreamInfo()
 	c.streamInfo.StreamID = c.extractStreamID()
 	c.streamInfo.Priority = c.extractPriority()
+
+	c.sampleRTT()
 }
 
 // ===== 優化的 JSON 處理 =====
@@ -0,0 +1,39 @@
+// @chris
+package context
+
+import (
+	stdcontext "context"
+	"net"
+)
+
+// trustedProxiesKey 用於在 request context 中存放可信代理網段的 key
+type trustedProxiesKey struct{}
+
+// WithTrustedProxies 將可信代理網段附加到標準 context.Context
+// 由 server 包裝層在每個請求進入 router 前注入，ClientIP / IsFromTrustedProxy 據此判斷
+func WithTrustedProxies(parent stdcontext.Context, proxies []*net.IPNet) stdcontext.Context {
+	return stdcontext.WithValue(parent, trustedProxiesKey{}, proxies)
+}
+
+// trustedProxies 取得目前請求的可信代理網段；未設定時為 nil（不信任任何代理）
+func (c *Context) trustedProxies() []*net.IPNet {
+	if c.Request == nil {
+		return nil
+	}
+	proxies, _ := c.Request.Context().Value(trustedProxiesKey{}).([]*net.IPNet)
+	return proxies
+}
+
+// isTrustedIP 檢查 IP 字串是否落在可信代理網段內
+func (c *Context) isTrustedIP(ip string) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range c.trustedProxies() {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
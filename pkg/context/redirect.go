@@ -0,0 +1,127 @@
+// @chris
+package context
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ===== 重定向安全策略 =====
+
+// RedirectPolicy Redirect 的安全策略
+type RedirectPolicy struct {
+	SameHost     bool     // 只允許重定向到目前請求的 host 或 AllowedHosts，其餘改導向 Fallback
+	AllowedHosts []string // SameHost 時額外允許的 host（可含 port）
+	Fallback     string   // 位置不安全時改導向的路徑，預設 "/"
+	PushTarget   bool     // HTTP/2、HTTP/3 下對同站重定向先推送目標，省去一次往返
+}
+
+var redirectPolicy = RedirectPolicy{Fallback: "/"}
+
+// SetRedirectPolicy 設定 Redirect 的安全策略
+// 非並行安全，應於程式啟動階段呼叫
+//
+// EX：
+//
+//	context.SetRedirectPolicy(context.RedirectPolicy{
+//	    SameHost:     true,
+//	    AllowedHosts: []string{"accounts.example.com"},
+//	})
+func SetRedirectPolicy(policy RedirectPolicy) {
+	if policy.Fallback == "" {
+		policy.Fallback = "/"
+	}
+	redirectPolicy = policy
+}
+
+// RedirectPermanent 永久重定向：GET / HEAD 使用 301，其他方法使用 308 以保留方法與 body
+func (c *Context) RedirectPermanent(location string) {
+	if c.isSafeMethodRequest() {
+		c.Redirect(http.StatusMovedPermanently, location)
+	} else {
+		c.Redirect(http.StatusPermanentRedirect, location)
+	}
+}
+
+// RedirectTemporary 暫時重定向：GET / HEAD 使用 302，其他方法使用 307 以保留方法與 body
+// 表單送出後導向結果頁（POST → GET）請改用 c.Redirect(http.StatusSeeOther, location)
+func (c *Context) RedirectTemporary(location string) {
+	if c.isSafeMethodRequest() {
+		c.Redirect(http.StatusFound, location)
+	} else {
+		c.Redirect(http.StatusTemporaryRedirect, location)
+	}
+}
+
+func (c *Context) isSafeMethodRequest() bool {
+	return c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+}
+
+// checkRedirectCode 只接受 3xx 與 201（Created 搭配 Location）
+func checkRedirectCode(code int) {
+	if (code < http.StatusMultipleChoices || code > http.StatusPermanentRedirect) && code != http.StatusCreated {
+		panic(fmt.Sprintf("Cannot redirect with status code %d", code))
+	}
+}
+
+// safeRedirectLocation 回傳可安全寫入 Location 的位置，以及是否為同站位置
+//
+// 以 / 開頭卻被瀏覽器視為外部網址的寫法（//evil.com、/\evil.com、\\evil.com）
+// 收斂為站內路徑；含控制字元或非 http(s) scheme 的位置改導向 Fallback；
+// SameHost 時外部 host 也改導向 Fallback
+func (c *Context) safeRedirectLocation(location string) (string, bool) {
+	if strings.ContainsFunc(location, func(r rune) bool { return r < 0x20 || r == 0x7f }) {
+		return redirectPolicy.Fallback, true
+	}
+	if strings.HasPrefix(location, "/") || strings.HasPrefix(location, `\`) {
+		return "/" + strings.TrimLeft(location, `/\`), true
+	}
+
+	u, err := url.Parse(location)
+	if err != nil {
+		return redirectPolicy.Fallback, true
+	}
+	if u.Scheme == "" && u.Host == "" {
+		// 相對路徑（如 "edit"、"?page=2"）
+		return location, true
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return redirectPolicy.Fallback, true
+	}
+
+	sameHost := strings.EqualFold(u.Host, c.Request.Host)
+	if redirectPolicy.SameHost && !sameHost {
+		allowed := false
+		for _, host := range redirectPolicy.AllowedHosts {
+			if strings.EqualFold(u.Host, host) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return redirectPolicy.Fallback, true
+		}
+	}
+	return location, sameHost
+}
+
+// pushRedirectTarget 瀏覽器會以 GET 跟隨時（非 307 / 308，或原本就是 GET），先推送同站目標
+func (c *Context) pushRedirectTarget(code int, location string) {
+	if code == http.StatusCreated || !c.CanPush() {
+		return
+	}
+	if (code == http.StatusTemporaryRedirect || code == http.StatusPermanentRedirect) && c.Request.Method != http.MethodGet {
+		return
+	}
+	if u, err := url.Parse(location); err == nil && u.Path != "" {
+		target := u.Path
+		if u.RawQuery != "" {
+			target += "?" + u.RawQuery
+		}
+		if strings.HasPrefix(target, "/") {
+			c.Push(target, nil)
+		}
+	}
+}
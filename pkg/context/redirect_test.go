@@ -0,0 +1,123 @@
+package context
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func redirectCtx(method, target string) (*Context, *httptest.ResponseRecorder) {
+	req := httptest.NewRequest(method, target, nil)
+	w := httptest.NewRecorder()
+	return New(w, req), w
+}
+
+func TestRedirectOpenRedirectPrevention(t *testing.T) {
+	cases := map[string]string{
+		"/dashboard":                "/dashboard",
+		"//evil.com/path":           "/evil.com/path",
+		`/\evil.com`:                "/evil.com",
+		`\\evil.com`:                "/evil.com",
+		"edit?id=1":                 "/users/edit?id=1",
+		"javascript:alert(1)":       "/",
+		"/ok\r\nSet-Cookie: x=1":    "/",
+		"https://partner.com/login": "https://partner.com/login",
+	}
+	for location, want := range cases {
+		c, w := redirectCtx(http.MethodGet, "/users/1")
+		c.Redirect(http.StatusFound, location)
+		if got := w.Header().Get("Location"); got != want {
+			t.Errorf("Redirect(%q): Location = %q, want %q", location, got, want)
+		}
+	}
+}
+
+func TestRedirectSameHostPolicy(t *testing.T) {
+	SetRedirectPolicy(RedirectPolicy{SameHost: true, AllowedHosts: []string{"accounts.example.com"}, Fallback: "/home"})
+	defer SetRedirectPolicy(RedirectPolicy{})
+
+	cases := map[string]string{
+		"http://example.com/a":          "http://example.com/a",
+		"https://accounts.example.com/": "https://accounts.example.com/",
+		"https://evil.com/phish":        "/home",
+		"//evil.com":                    "/evil.com",
+	}
+	for location, want := range cases {
+		c, w := redirectCtx(http.MethodGet, "http://example.com/")
+		c.Redirect(http.StatusFound, location)
+		if got := w.Header().Get("Location"); got != want {
+			t.Errorf("Redirect(%q): Location = %q, want %q", location, got, want)
+		}
+	}
+}
+
+func TestRedirectPermanentAndTemporary(t *testing.T) {
+	for _, tc := range []struct {
+		method    string
+		permanent bool
+		want      int
+	}{
+		{http.MethodGet, true, http.StatusMovedPermanently},
+		{http.MethodPost, true, http.StatusPermanentRedirect},
+		{http.MethodHead, false, http.StatusFound},
+		{http.MethodPut, false, http.StatusTemporaryRedirect},
+	} {
+		c, w := redirectCtx(tc.method, "/old")
+		if tc.permanent {
+			c.RedirectPermanent("/new")
+		} else {
+			c.RedirectTemporary("/new")
+		}
+		// 非 GET 的重定向沒有 body，狀態碼由 router 在請求結束時寫出
+		if c.Writer.Status() != tc.want || w.Header().Get("Location") != "/new" {
+			t.Errorf("%s permanent=%v: got %d %q", tc.method, tc.permanent, c.Writer.Status(), w.Header().Get("Location"))
+		}
+	}
+}
+
+func TestRedirectInvalidCodePanics(t *testing.T) {
+	c, w := redirectCtx(http.MethodGet, "/")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for non-redirect status")
+		}
+		if w.Header().Get("Location") != "" {
+			t.Error("Expected nothing written before the panic")
+		}
+	}()
+	c.Redirect(http.StatusOK, "/")
+}
+
+type pushRecorder struct {
+	*httptest.ResponseRecorder
+	pushed []string
+}
+
+func (p *pushRecorder) Push(target string, _ *http.PushOptions) error {
+	p.pushed = append(p.pushed, target)
+	return nil
+}
+
+func TestRedirectPushTarget(t *testing.T) {
+	SetRedirectPolicy(RedirectPolicy{PushTarget: true})
+	defer SetRedirectPolicy(RedirectPolicy{})
+
+	push := func(method string, code int, location string) []string {
+		req := httptest.NewRequest(method, "/login", nil)
+		req.ProtoMajor = 3
+		w := &pushRecorder{ResponseRecorder: httptest.NewRecorder()}
+		New(w, req).Redirect(code, location)
+		return w.pushed
+	}
+
+	if got := push(http.MethodPost, http.StatusSeeOther, "/dashboard?tab=1"); len(got) != 1 || got[0] != "/dashboard?tab=1" {
+		t.Errorf("Expected target pushed, got %v", got)
+	}
+	// 307 會以 POST 重送，推送 GET 回應沒有意義
+	if got := push(http.MethodPost, http.StatusTemporaryRedirect, "/dashboard"); len(got) != 0 {
+		t.Errorf("Expected no push for 307 POST, got %v", got)
+	}
+	if got := push(http.MethodGet, http.StatusFound, "https://other.com/"); len(got) != 0 {
+		t.Errorf("Expected no push for external target, got %v", got)
+	}
+}
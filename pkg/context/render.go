@@ -223,9 +223,7 @@ type redirectRender struct {
 }
 
 func (r redirectRender) Render(w http.ResponseWriter) error {
-	if (r.Code < http.StatusMultipleChoices || r.Code > http.StatusPermanentRedirect) && r.Code != http.StatusCreated {
-		panic(fmt.Sprintf("Cannot redirect with status code %d", r.Code))
-	}
+	checkRedirectCode(r.Code)
 	http.Redirect(w, r.Request, r.Location, r.Code)
 	return nil
 }
@@ -0,0 +1,193 @@
+// @chris
+package context
+
+import (
+	stdcontext "context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ===== 錯誤回報 =====
+
+// ErrorReporter 將錯誤送往外部追蹤服務（Sentry 等）
+// Report 在請求 goroutine 中同步呼叫，實作應自行非同步送出，避免拖慢回應
+type ErrorReporter interface {
+	Report(err error, ctx map[string]interface{})
+}
+
+// ErrorReporterFunc 以函數實作 ErrorReporter
+type ErrorReporterFunc func(err error, ctx map[string]interface{})
+
+// Report 呼叫 f(err, ctx)
+func (f ErrorReporterFunc) Report(err error, ctx map[string]interface{}) { f(err, ctx) }
+
+// NopErrorReporter 不做任何事的 ErrorReporter（未註冊時等同此行為）
+var NopErrorReporter ErrorReporter = nopErrorReporter{}
+
+type nopErrorReporter struct{}
+
+func (nopErrorReporter) Report(error, map[string]interface{}) {}
+
+// errorReporterKey 用於在 request context 中存放 ErrorReporter 的 key
+type errorReporterKey struct{}
+
+// WithErrorReporter 將 ErrorReporter 附加到標準 context.Context
+// 由 server 包裝層在每個請求進入 router 前注入（Server.SetErrorReporter）
+func WithErrorReporter(parent stdcontext.Context, r ErrorReporter) stdcontext.Context {
+	return stdcontext.WithValue(parent, errorReporterKey{}, r)
+}
+
+// errorReporter 取得目前請求的 ErrorReporter，未設定時回傳 nil
+func (c *Context) errorReporter() ErrorReporter {
+	if c.Request == nil {
+		return nil
+	}
+	r, _ := c.Request.Context().Value(errorReporterKey{}).(ErrorReporter)
+	return r
+}
+
+// ReportError 附上請求資訊（method、path、route、request_id、user_id）後交給 ErrorReporter
+// c.Error 與 5xx 的 AbortWithError / AbortWithErrorJSON 會自動呼叫；extra 的欄位覆蓋預設欄位
+//
+// EX：
+//
+//	if err := mailer.Send(msg); err != nil {
+//	    c.ReportError(err, map[string]interface{}{"recipient": msg.To})
+//	}
+func (c *Context) ReportError(err error, extra map[string]interface{}) {
+	if err == nil {
+		return
+	}
+	reporter := c.errorReporter()
+	if reporter == nil {
+		return
+	}
+
+	fields := make(map[string]interface{}, 6+len(extra))
+	if c.Request != nil {
+		fields["method"] = c.Request.Method
+		fields["path"] = c.Request.URL.Path
+		requestID := c.GetString("request_id")
+		if requestID == "" {
+			requestID = c.GetHeader("X-Request-ID")
+		}
+		if requestID != "" {
+			fields["request_id"] = requestID
+		}
+	}
+	if route := c.FullPath(); route != "" {
+		fields["route"] = route
+	}
+	if userID := c.GetUserID(); userID != nil {
+		fields["user_id"] = userID
+	}
+	for k, v := range extra {
+		fields[k] = v
+	}
+	reporter.Report(err, fields)
+}
+
+// reportCollected 回報已收集的錯誤，公開錯誤與綁定錯誤屬於用戶端問題，不回報
+func (c *Context) reportCollected(msg *Error, status int) {
+	if msg.IsType(ErrorTypePublic) || msg.IsType(ErrorTypeBind) {
+		return
+	}
+	var extra map[string]interface{}
+	if status > 0 {
+		extra = map[string]interface{}{"status": status}
+	}
+	c.ReportError(msg.Err, extra)
+}
+
+// ===== 回報限流 =====
+
+// 預設限流：同一錯誤每分鐘最多回報 5 次
+const (
+	DefaultReportLimit  = 5
+	DefaultReportWindow = time.Minute
+)
+
+// maxTrackedReports 同時追蹤的錯誤指紋上限，超過時清除過期的指紋
+const maxTrackedReports = 1024
+
+// RateLimitedReporter 依錯誤指紋（型別 + 訊息）限流的 ErrorReporter
+// 每個視窗內同一錯誤最多回報 limit 次，被略過的次數在下一個視窗第一次回報時以 "suppressed" 欄位附上
+type RateLimitedReporter struct {
+	next   ErrorReporter
+	limit  int
+	window time.Duration
+
+	mu   sync.Mutex
+	seen map[string]*reportWindow
+}
+
+type reportWindow struct {
+	start      time.Time
+	count      int
+	suppressed int
+}
+
+// LimitReports 以限流包裝 ErrorReporter；limit / window <= 0 時使用預設值
+func LimitReports(r ErrorReporter, limit int, window time.Duration) *RateLimitedReporter {
+	if limit <= 0 {
+		limit = DefaultReportLimit
+	}
+	if window <= 0 {
+		window = DefaultReportWindow
+	}
+	return &RateLimitedReporter{
+		next:   r,
+		limit:  limit,
+		window: window,
+		seen:   make(map[string]*reportWindow),
+	}
+}
+
+// Report 未超過限額時轉交下一個 ErrorReporter
+func (l *RateLimitedReporter) Report(err error, ctx map[string]interface{}) {
+	key := fmt.Sprintf("%T:%s", err, err.Error())
+	now := time.Now()
+
+	l.mu.Lock()
+	w := l.seen[key]
+	carried := 0
+	if w == nil || now.Sub(w.start) >= l.window {
+		if w != nil {
+			carried = w.suppressed
+		} else if len(l.seen) >= maxTrackedReports {
+			l.prune(now)
+		}
+		w = &reportWindow{start: now}
+		l.seen[key] = w
+	}
+	w.count++
+	if w.count > l.limit {
+		w.suppressed++
+		l.mu.Unlock()
+		return
+	}
+	l.mu.Unlock()
+
+	if carried > 0 {
+		fields := make(map[string]interface{}, len(ctx)+1)
+		for k, v := range ctx {
+			fields[k] = v
+		}
+		fields["suppressed"] = carried
+		ctx = fields
+	}
+	l.next.Report(err, ctx)
+}
+
+// prune 移除視窗已過期的指紋；仍超過上限時全部清除，呼叫端需持有 l.mu
+func (l *RateLimitedReporter) prune(now time.Time) {
+	for key, w := range l.seen {
+		if now.Sub(w.start) >= l.window {
+			delete(l.seen, key)
+		}
+	}
+	if len(l.seen) >= maxTrackedReports {
+		l.seen = make(map[string]*reportWindow)
+	}
+}
@@ -172,7 +172,7 @@ func (c *Context) GetFormValue(key string) string {
 // initFormCache 初始化表單快取
 func (c *Context) initFormCache() {
 	c.Request.ParseForm()
-	c.Request.ParseMultipartForm(defaultMemory)
+	c.Request.ParseMultipartForm(c.MaxMultipartMemory())
 	c.formCache = c.Request.PostForm
 }
 
@@ -220,7 +220,7 @@ func (c *Context) SetRawData(data []byte) {
 // FormFile 獲取上傳的檔案
 func (c *Context) FormFile(name string) (*multipart.FileHeader, error) {
 	if c.Request.MultipartForm == nil {
-		if err := c.Request.ParseMultipartForm(defaultMemory); err != nil {
+		if err := c.Request.ParseMultipartForm(c.MaxMultipartMemory()); err != nil {
 			return nil, err
 		}
 	}
@@ -240,7 +240,7 @@ func (c *Context) GetFormFile(name string) (*multipart.FileHeader, error) {
 // GetFormFiles 獲取多個上傳的文件
 func (c *Context) GetFormFiles(name string) ([]*multipart.FileHeader, error) {
 	if c.Request.MultipartForm == nil {
-		if err := c.Request.ParseMultipartForm(defaultMemory); err != nil {
+		if err := c.Request.ParseMultipartForm(c.MaxMultipartMemory()); err != nil {
 			return nil, err
 		}
 	}
@@ -252,7 +252,7 @@ func (c *Context) GetFormFiles(name string) ([]*multipart.FileHeader, error) {
 
 // MultipartForm 獲取多部分表單
 func (c *Context) MultipartForm() (*multipart.Form, error) {
-	err := c.Request.ParseMultipartForm(defaultMemory)
+	err := c.Request.ParseMultipartForm(c.MaxMultipartMemory())
 	return c.Request.MultipartForm, err
 }
 
@@ -282,15 +282,30 @@ func (c *Context) SaveUploadedFile(file *multipart.FileHeader, dst string) error
 // ===== 客戶端信息 =====
 
 // ClientIP 獲取客戶端 IP
+// 僅當 RemoteAddr 屬於可信代理時才採信轉發標頭；X-Forwarded-For 由右至左取第一個不可信的位址
 func (c *Context) ClientIP() string {
+	remoteIP := c.RemoteIP()
+	if !c.isTrustedIP(remoteIP) {
+		return remoteIP
+	}
+
 	// 檢查 X-Forwarded-For
 	if xForwardedFor := c.GetHeader("X-Forwarded-For"); xForwardedFor != "" {
 		parts := strings.Split(xForwardedFor, ",")
+		clientIP := ""
 		for i := len(parts) - 1; i >= 0; i-- {
 			ip := strings.TrimSpace(parts[i])
-			if ip != "" && isValidIP(ip) {
-				return ip
+			if ip == "" || !isValidIP(ip) {
+				continue
 			}
+			clientIP = ip
+			if !c.isTrustedIP(ip) {
+				break
+			}
+		}
+		// 全部皆為可信代理時回傳最左側位址
+		if clientIP != "" {
+			return clientIP
 		}
 	}
 
@@ -302,16 +317,11 @@ func (c *Context) ClientIP() string {
 	}
 
 	// 檢查 X-Appengine-Remote-Addr (App Engine)
-	if appEngine := c.GetHeader("X-Appengine-Remote-Addr"); appEngine != "" {
+	if appEngine := c.GetHeader("X-Appengine-Remote-Addr"); appEngine != "" && isValidIP(appEngine) {
 		return appEngine
 	}
 
-	// 從連接獲取
-	if ip, _, err := net.SplitHostPort(strings.TrimSpace(c.Request.RemoteAddr)); err == nil {
-		return ip
-	}
-
-	return ""
+	return remoteIP
 }
 
 // GetClientIP 獲取客戶端 IP（別名）
@@ -354,10 +364,9 @@ func (c *Context) RemoteIP() string {
 }
 
 // IsFromTrustedProxy 檢查請求是否來自可信代理
+// 可信代理網段由 server.trusted_proxies 設定
 func (c *Context) IsFromTrustedProxy() bool {
-	// 實現可信代理檢查邏輯
-	// 這裡可以維護一個可信代理 IP 列表
-	return false
+	return c.isTrustedIP(c.RemoteIP())
 }
 
 // ContentType 獲取內容類型
@@ -5,17 +5,22 @@ import (
 	"encoding/json"
 	"encoding/xml"
 	"errors"
-	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 )
 
 // ===== JSON 響應 =====
 
 // JSON 回應 JSON 資料
+// debug 模式下以格式化 JSON 輸出，方便開發時閱讀
 func (c *Context) JSON(code int, obj interface{}) {
+	if IsDebugging() {
+		c.Render(code, indentedJSONRender{Data: obj})
+		return
+	}
 	c.Render(code, jsonRender{obj})
 }
 
@@ -123,12 +128,58 @@ func (c *Context) FileFromFS(filepath string, fs http.FileSystem) {
 	http.FileServer(fs).ServeHTTP(c.Writer, c.Request)
 }
 
-// FileAttachment 回應檔案作為附件下載
+// FileAttachment 回應檔案作為附件下載，瀏覽器以 filename 存檔
+// 支援 Range 請求（續傳）與 If-Modified-Since
 func (c *Context) FileAttachment(filepath, filename string) {
-	c.Writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	c.Writer.Header().Set(HeaderContentDisposition, ContentDisposition("attachment", filename))
 	http.ServeFile(c.Writer, c.Request, filepath)
 }
 
+// ContentDisposition 組出 Content-Disposition 值（RFC 6266）
+// filename 含非 ASCII 或特殊字元時，filename 為 ASCII 替代名稱，並以 filename*（RFC 5987）帶上 UTF-8 原名
+//
+// EX：
+//
+//	context.ContentDisposition("attachment", "報表 2026.csv")
+//	// attachment; filename="__ 2026.csv"; filename*=UTF-8''%E5%A0%B1%E8%A1%A8%202026.csv
+func ContentDisposition(dispositionType, filename string) string {
+	if filename == "" {
+		return dispositionType
+	}
+
+	fallback := make([]byte, 0, len(filename))
+	for _, r := range filename {
+		if r < 0x20 || r >= 0x7f || r == '"' || r == '\\' {
+			fallback = append(fallback, '_')
+		} else {
+			fallback = append(fallback, byte(r))
+		}
+	}
+	value := dispositionType + `; filename="` + string(fallback) + `"`
+	if string(fallback) != filename {
+		value += "; filename*=UTF-8''" + encodeRFC5987(filename)
+	}
+	return value
+}
+
+// encodeRFC5987 依 RFC 5987 attr-char 百分比編碼
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if ('a' <= ch && ch <= 'z') || ('A' <= ch && ch <= 'Z') || ('0' <= ch && ch <= '9') ||
+			strings.IndexByte("!#$&+-.^_`|~", ch) >= 0 {
+			b.WriteByte(ch)
+		} else {
+			b.WriteByte('%')
+			b.WriteByte(hex[ch>>4])
+			b.WriteByte(hex[ch&0x0f])
+		}
+	}
+	return b.String()
+}
+
 // ===== 數據響應 =====
 
 // Data 回應原始資料
@@ -140,8 +191,27 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 		})
 }
 
-// DataFromReader 從 Reader 回應資料
+// DataFromReader 從 Reader 串流回應資料，contentLength 未知時傳 -1
+// reader 實作 io.ReadSeeker 且 code 為 200 時支援 Range 請求（續傳），長度由 Seek 取得
+//
+// EX：
+//
+//	c.DataFromReader(http.StatusOK, -1, "text/csv", pr, map[string]string{
+//	    "Content-Disposition": context.ContentDisposition("attachment", "orders.csv"),
+//	})
 func (c *Context) DataFromReader(code int, contentLength int64, contentType string, reader io.Reader, extraHeaders map[string]string) {
+	if rs, ok := reader.(io.ReadSeeker); ok && code == http.StatusOK {
+		header := c.Writer.Header()
+		if contentType != "" {
+			header.Set("Content-Type", contentType)
+		}
+		for k, v := range extraHeaders {
+			header.Set(k, v)
+		}
+		http.ServeContent(c.Writer, c.Request, "", time.Time{}, rs)
+		return
+	}
+
 	c.Render(code, readerRender{
 		Headers:       extraHeaders,
 		ContentType:   contentType,
@@ -162,8 +232,19 @@ func (c *Context) SSEvent(name string, message interface{}) {
 
 // ===== 重定向 =====
 
-// Redirect 重定向
+// Redirect 重定向，code 須為 3xx（或 201），否則 panic
+// 位置依 RedirectPolicy 檢查：//evil.com 這類偽裝成站內路徑的外部網址會收斂為站內路徑，
+// 啟用 SameHost 時外部 host 改導向 Fallback，避免 open redirect
+//
+// EX：
+//
+//	c.Redirect(http.StatusSeeOther, "/orders/"+id)
 func (c *Context) Redirect(code int, location string) {
+	checkRedirectCode(code)
+	location, local := c.safeRedirectLocation(location)
+	if local && redirectPolicy.PushTarget {
+		c.pushRedirectTarget(code, location)
+	}
 	c.Render(-1, redirectRender{
 		Code:     code,
 		Location: location,
@@ -177,13 +258,22 @@ func (c *Context) Redirect(code int, location string) {
 func (c *Context) Render(code int, r Render) {
 	c.Status(code)
 
+	// 設定了非 UTF-8 的回應 charset（SetResponseCharset / SetDefaultResponseCharset）時轉碼輸出
+	var w ResponseWriter = c.Writer
+	if name := c.responseCharset(); name != "" {
+		if cw, err := newCharsetResponseWriter(c.Writer, name); err == nil {
+			defer cw.Close()
+			w = cw
+		}
+	}
+
 	if !bodyAllowedForStatus(code) {
-		r.WriteContentType(c.Writer)
-		c.Writer.WriteHeaderNow()
+		r.WriteContentType(w)
+		w.WriteHeaderNow()
 		return
 	}
 
-	if err := r.Render(c.Writer); err != nil {
+	if err := r.Render(w); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,68 @@
+package context
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentDisposition(t *testing.T) {
+	cases := []struct{ filename, want string }{
+		{"report.csv", `attachment; filename="report.csv"`},
+		{"報表 2026.csv", `attachment; filename="__ 2026.csv"; filename*=UTF-8''%E5%A0%B1%E8%A1%A8%202026.csv`},
+		{`a"b\c.txt`, `attachment; filename="a_b_c.txt"; filename*=UTF-8''a%22b%5Cc.txt`},
+		{"evil\r\nX-Injected: 1.txt", `attachment; filename="evil__X-Injected: 1.txt"; filename*=UTF-8''evil%0D%0AX-Injected%3A%201.txt`},
+		{"", "attachment"},
+	}
+	for _, tc := range cases {
+		if got := ContentDisposition("attachment", tc.filename); got != tc.want {
+			t.Errorf("ContentDisposition(%q)\n got %s\nwant %s", tc.filename, got, tc.want)
+		}
+	}
+}
+
+func TestFileAttachmentRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(path, []byte("0123456789"), 0644)
+
+	req := httptest.NewRequest(http.MethodGet, "/download", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	w := httptest.NewRecorder()
+	New(w, req).FileAttachment(path, "資料.bin")
+
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2345" {
+		t.Errorf("Expected 206 with bytes 2-5, got %d %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get(HeaderContentDisposition); !strings.Contains(got, "filename*=UTF-8''%E8%B3%87%E6%96%99.bin") {
+		t.Errorf("Unexpected disposition %q", got)
+	}
+}
+
+func TestDataFromReader(t *testing.T) {
+	headers := map[string]string{HeaderContentDisposition: ContentDisposition("attachment", "orders.csv")}
+
+	// 可 Seek 的 reader 支援續傳
+	req := httptest.NewRequest(http.MethodGet, "/export", nil)
+	req.Header.Set("Range", "bytes=5-")
+	w := httptest.NewRecorder()
+	New(w, req).DataFromReader(http.StatusOK, -1, "text/csv", strings.NewReader("id\n1\n2\n"), headers)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "2\n" || w.Header().Get("Content-Type") != "text/csv" {
+		t.Errorf("Expected ranged CSV, got %d %q %q", w.Code, w.Body.String(), w.Header().Get("Content-Type"))
+	}
+
+	// 純串流 reader 完整輸出
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("id\n1\n"))
+		pw.Close()
+	}()
+	w = httptest.NewRecorder()
+	New(w, httptest.NewRequest(http.MethodGet, "/export", nil)).DataFromReader(http.StatusOK, -1, "text/csv", pr, headers)
+	if w.Code != http.StatusOK || w.Body.String() != "id\n1\n" || w.Header().Get(HeaderContentDisposition) != `attachment; filename="orders.csv"` {
+		t.Errorf("Unexpected streamed response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+}
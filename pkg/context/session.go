@@ -0,0 +1,36 @@
+// @chris
+package context
+
+// ===== Session =====
+
+// sessionKey Session 在 Context.Keys 中的 key
+const sessionKey = "session"
+
+// Session 請求級 Session，由 middleware.Session 建立並放入 Context.Keys
+// 修改後須呼叫 Save 才會寫回儲存層與 cookie
+type Session interface {
+	// ID 目前的 session id
+	ID() string
+	// Get 取得值，不存在時回傳 nil
+	Get(key string) interface{}
+	// Set 設置值
+	Set(key string, value interface{})
+	// Delete 刪除值
+	Delete(key string)
+	// Clear 清空所有值
+	Clear()
+	// RegenerateID 標記於下次 Save 時更換 session id（登入、權限變更後呼叫以防 session fixation）
+	RegenerateID()
+	// Save 寫回儲存層並設置 cookie，須在寫出回應前呼叫
+	Save() error
+}
+
+// Session 獲取當前請求的 Session，未掛載 session 中間件時回傳 nil
+func (c *Context) Session() Session {
+	if v, exists := c.Get(sessionKey); exists {
+		if s, ok := v.(Session); ok {
+			return s
+		}
+	}
+	return nil
+}
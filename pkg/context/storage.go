@@ -2,6 +2,7 @@
 package context
 
 import (
+	stdcontext "context"
 	"fmt"
 	"time"
 )
@@ -18,6 +19,26 @@ func (c *Context) Set(key string, value interface{}) {
 	c.Keys[key] = value
 }
 
+// SetValue 以任意型別的 key 存放請求範圍的資料，並寫入 Request.Context()
+// 讓取得 c、c.StdContext() 或 c.Request.Context() 的第三方套件（追蹤、DB driver）以自己的型別 key 讀到；
+// key 為 string 時同時寫入 Keys，c.Get 也能取得。
+// 會替換 c.Request，不可與其他讀取 c.Request 的 goroutine 並行呼叫
+//
+// EX：
+//
+//	type tenantKey struct{}
+//	c.SetValue(tenantKey{}, "acme")
+//	c.Value(tenantKey{})                   // "acme"
+//	c.Request.Context().Value(tenantKey{}) // "acme"
+func (c *Context) SetValue(key, value interface{}) {
+	if keyAsString, ok := key.(string); ok {
+		c.Set(keyAsString, value)
+	}
+	if c.Request != nil {
+		c.Request = c.Request.WithContext(stdcontext.WithValue(c.Request.Context(), key, value))
+	}
+}
+
 // Get 從上下文獲取資料
 func (c *Context) Get(key string) (value interface{}, exists bool) {
 	c.mu.RLock()
@@ -139,10 +160,14 @@ func (c *Context) GetStringMapStringSlice(key string) (smss map[string][]string)
 }
 
 // ===== Session 相關（簡化版）=====
+// 掛載 middleware.Session 時委派給 Session()，否則退回請求內的 map
 
 // GetSession 獲取 Session（需要 session 中間件）
 func (c *Context) GetSession(key string) interface{} {
-	if session, exists := c.Get("session"); exists {
+	if sess := c.Session(); sess != nil {
+		return sess.Get(key)
+	}
+	if session, exists := c.Get(sessionKey); exists {
 		if s, ok := session.(map[string]interface{}); ok {
 			return s[key]
 		}
@@ -152,10 +177,14 @@ func (c *Context) GetSession(key string) interface{} {
 
 // SetSession 設置 Session（需要 session 中間件）
 func (c *Context) SetSession(key string, value interface{}) {
-	session, exists := c.Get("session")
+	if sess := c.Session(); sess != nil {
+		sess.Set(key, value)
+		return
+	}
+	session, exists := c.Get(sessionKey)
 	if !exists {
 		session = make(map[string]interface{})
-		c.Set("session", session)
+		c.Set(sessionKey, session)
 	}
 	if s, ok := session.(map[string]interface{}); ok {
 		s[key] = value
@@ -164,7 +193,11 @@ func (c *Context) SetSession(key string, value interface{}) {
 
 // DeleteSession 刪除 Session 項目
 func (c *Context) DeleteSession(key string) {
-	if session, exists := c.Get("session"); exists {
+	if sess := c.Session(); sess != nil {
+		sess.Delete(key)
+		return
+	}
+	if session, exists := c.Get(sessionKey); exists {
 		if s, ok := session.(map[string]interface{}); ok {
 			delete(s, key)
 		}
@@ -173,7 +206,11 @@ func (c *Context) DeleteSession(key string) {
 
 // ClearSession 清空 Session
 func (c *Context) ClearSession() {
-	c.Set("session", make(map[string]interface{}))
+	if sess := c.Session(); sess != nil {
+		sess.Clear()
+		return
+	}
+	c.Set(sessionKey, make(map[string]interface{}))
 }
 
 // ===== 認證相關 =====
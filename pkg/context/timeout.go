@@ -0,0 +1,48 @@
+// @chris
+package context
+
+import (
+	stdcontext "context"
+	"sync"
+)
+
+// requestTimeoutKey 用於在 request context 中存放 RequestTimeoutControl 的 key
+type requestTimeoutKey struct{}
+
+// RequestTimeoutControl server 層 request_timeout 的請求級控制
+// 由 server 包裝層建立並注入，路由可透過 Context.DisableRequestTimeout 豁免逾時
+type RequestTimeoutControl struct {
+	once     sync.Once
+	disabled chan struct{}
+}
+
+// NewRequestTimeoutControl 創建請求逾時控制
+func NewRequestTimeoutControl() *RequestTimeoutControl {
+	return &RequestTimeoutControl{disabled: make(chan struct{})}
+}
+
+// Disable 豁免本次請求的 server 層逾時（可重複呼叫）
+func (t *RequestTimeoutControl) Disable() {
+	t.once.Do(func() { close(t.disabled) })
+}
+
+// Disabled 回傳在 Disable 被呼叫後關閉的 channel
+func (t *RequestTimeoutControl) Disabled() <-chan struct{} {
+	return t.disabled
+}
+
+// WithRequestTimeoutControl 將逾時控制附加到標準 context.Context
+func WithRequestTimeoutControl(parent stdcontext.Context, t *RequestTimeoutControl) stdcontext.Context {
+	return stdcontext.WithValue(parent, requestTimeoutKey{}, t)
+}
+
+// DisableRequestTimeout 讓目前請求不受 server 層 request_timeout 限制
+// 適用於 SSE、長輪詢、大檔下載等路由；須在逾時觸發前呼叫，通常放在路由的第一個處理器
+func (c *Context) DisableRequestTimeout() {
+	if c.Request == nil {
+		return
+	}
+	if t, ok := c.Request.Context().Value(requestTimeoutKey{}).(*RequestTimeoutControl); ok {
+		t.Disable()
+	}
+}
@@ -0,0 +1,126 @@
+// @chris
+package context
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+)
+
+// ===== 串流上傳 =====
+
+// MaxUploadSize StreamFormFile 允許的整個請求 body 上限（預設 0 表示不限制）
+var MaxUploadSize int64 = 0
+
+// MaxUploadFileSize StreamFormFile 允許的單一檔案上限（預設 0 表示不限制）
+var MaxUploadFileSize int64 = 0
+
+// ErrUploadTooLarge 上傳內容超過 MaxUploadSize 或 MaxUploadFileSize
+var ErrUploadTooLarge = errors.New("upload too large")
+
+// StreamFormFile 逐一串流處理表單欄位 name 的上傳檔案，不經 ParseMultipartForm 緩衝
+// 每個符合的檔案都會呼叫一次 handler，適合將大檔直接寫入磁碟或物件儲存。
+// 超過 MaxUploadSize / MaxUploadFileSize 時讀取會失敗並回傳 ErrUploadTooLarge；
+// 單檔上限以檔案內容的位元組數計算。
+//
+// EX：
+//
+//	err := c.StreamFormFile("video", func(part *context.UploadPart) error {
+//		dst, err := os.Create(filepath.Join(dir, filepath.Base(part.FileName())))
+//		if err != nil {
+//			return err
+//		}
+//		defer dst.Close()
+//		_, err = io.Copy(dst, part)
+//		return err
+//	})
+func (c *Context) StreamFormFile(name string, handler func(part *UploadPart) error) error {
+	if MaxUploadSize > 0 && c.Request.ContentLength > MaxUploadSize {
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrUploadTooLarge, c.Request.ContentLength, MaxUploadSize)
+	}
+
+	counter := &uploadCounter{r: c.Request.Body, total: MaxUploadSize}
+	c.Request.Body = counter
+
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		if part.FormName() != name || part.FileName() == "" {
+			continue
+		}
+
+		found = true
+		err = handler(newUploadPart(part, MaxUploadFileSize))
+		part.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	if !found {
+		return http.ErrMissingFile
+	}
+	return nil
+}
+
+// UploadPart StreamFormFile 傳給 handler 的檔案，讀取時套用 MaxUploadFileSize
+// 內嵌 *multipart.Part，FileName、FormName 與 Header 可直接使用
+type UploadPart struct {
+	*multipart.Part
+	r     io.Reader
+	limit int64 // 0 為不限制
+	read  int64
+}
+
+func newUploadPart(part *multipart.Part, limit int64) *UploadPart {
+	u := &UploadPart{Part: part, r: part, limit: limit}
+	if limit > 0 {
+		// 最多多讀 1 byte，用來判斷是否超過上限
+		u.r = io.LimitReader(part, limit+1)
+	}
+	return u
+}
+
+// Read 讀取檔案內容；超過上限時只回傳上限內的資料並回傳 ErrUploadTooLarge
+func (u *UploadPart) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+	if u.limit > 0 && u.read > u.limit {
+		return n - int(u.read-u.limit), fmt.Errorf("%w: file exceeds limit of %d bytes", ErrUploadTooLarge, u.limit)
+	}
+	return n, err
+}
+
+// uploadCounter 計算請求 body 讀取量，用於整體大小限制
+type uploadCounter struct {
+	r     io.ReadCloser
+	total int64 // 整體上限，0 為不限制
+	read  int64
+}
+
+func (u *uploadCounter) Read(p []byte) (int, error) {
+	n, err := u.r.Read(p)
+	u.read += int64(n)
+
+	if u.total > 0 && u.read > u.total {
+		return n, fmt.Errorf("%w: exceeds limit of %d bytes", ErrUploadTooLarge, u.total)
+	}
+	return n, err
+}
+
+func (u *uploadCounter) Close() error {
+	return u.r.Close()
+}
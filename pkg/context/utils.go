@@ -72,6 +72,8 @@ type responseWriter struct {
 	written  bool
 	streamID uint64
 	mu       sync.Mutex
+	// 所屬請求的指標，寫出量同步累計至 BytesOut（可為 nil）
+	metrics *RequestMetrics
 }
 
 // newResponseWriter 創建新的 responseWriter
@@ -109,7 +111,7 @@ func (w *responseWriter) WriteHeaderNow() {
 func (w *responseWriter) Write(data []byte) (int, error) {
 	w.WriteHeaderNow()
 	n, err := w.ResponseWriter.Write(data)
-	w.size += n
+	w.addSize(n)
 	return n, err
 }
 
@@ -117,7 +119,27 @@ func (w *responseWriter) Write(data []byte) (int, error) {
 func (w *responseWriter) WriteString(s string) (int, error) {
 	w.WriteHeaderNow()
 	n, err := io.WriteString(w.ResponseWriter, s)
+	w.addSize(n)
+	return n, err
+}
+
+// addSize 累計已寫出大小並同步至請求指標
+func (w *responseWriter) addSize(n int) {
 	w.size += n
+	if w.metrics != nil {
+		w.metrics.BytesOut += int64(n)
+	}
+}
+
+// countingBody 包裝請求 body，讀取量累計至 metrics.BytesIn
+type countingBody struct {
+	io.ReadCloser
+	metrics *RequestMetrics
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.metrics.BytesIn += int64(n)
 	return n, err
 }
 
@@ -154,7 +176,7 @@ func (w *responseWriter) ReadFrom(src io.Reader) (n int64, err error) {
 		// 包裝以避免 io.Copy 遞迴檢查 ReaderFrom
 		n, err = io.Copy(writerOnly{w.ResponseWriter}, src)
 	}
-	w.size += int(n)
+	w.addSize(int(n))
 	return
 }
 
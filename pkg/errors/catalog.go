@@ -188,6 +188,8 @@ var (
 	ErrBadRequest       = Define("E0002", http.StatusBadRequest, "Bad request", "general")
 	ErrInternalError    = Define("E0003", http.StatusInternalServerError, "Internal server error", "general")
 	ErrMethodNotAllowed = Define("E0004", http.StatusMethodNotAllowed, "Method not allowed", "general")
+	ErrDuplicate        = Define("E0005", http.StatusConflict, "Resource already exists", "general")
+	ErrUnsupportedMedia = Define("E0006", http.StatusUnsupportedMediaType, "Unsupported media type", "general")
 
 	// 驗證
 	ErrValidationFailed = Define("E1001", http.StatusUnprocessableEntity, "Validation failed", "validation")
@@ -0,0 +1,247 @@
+// @chris
+package errors
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== HTTP 錯誤回報器 =====
+
+// HTTPReporterConfig HTTPReporter 配置
+type HTTPReporterConfig struct {
+	// URL 接收回報的端點（必填）
+	URL string
+	// Client 送出用的 HTTP client，預設 5 秒逾時
+	Client *http.Client
+	// Header 每次送出附加的標頭（如驗證 token）
+	Header http.Header
+	// QueueSize 待送佇列大小，佇列滿時丟棄新的回報，預設 256
+	QueueSize int
+	// Encode 將錯誤編碼為請求 body，預設為 {"error","type","time","context"} 的 JSON
+	Encode func(err error, ctx map[string]interface{}) ([]byte, error)
+}
+
+// HTTPReporter 以 HTTP POST 送出錯誤的 hypcontext.ErrorReporter
+// Report 只做編碼並放入佇列，由背景 goroutine 送出，不會阻塞請求
+//
+// EX：
+//
+//	reporter := errors.NewHTTPReporter(errors.HTTPReporterConfig{URL: "https://alerts.example.com/hook"})
+//	defer reporter.Close(context.Background())
+//	srv.SetErrorReporter(reporter)
+type HTTPReporter struct {
+	url     string
+	client  *http.Client
+	header  http.Header
+	encode  func(err error, ctx map[string]interface{}) ([]byte, error)
+	queue   chan []byte
+	dropped atomic.Int64
+	mu      sync.RWMutex // 保護 closed 與關閉 queue
+	closed  bool
+	done    chan struct{}
+}
+
+var _ hypcontext.ErrorReporter = (*HTTPReporter)(nil)
+
+// NewHTTPReporter 建立 HTTPReporter 並啟動背景送出 goroutine
+func NewHTTPReporter(config HTTPReporterConfig) *HTTPReporter {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 256
+	}
+	if config.Encode == nil {
+		config.Encode = encodeReport
+	}
+	r := &HTTPReporter{
+		url:    config.URL,
+		client: config.Client,
+		header: config.Header,
+		encode: config.Encode,
+		queue:  make(chan []byte, config.QueueSize),
+		done:   make(chan struct{}),
+	}
+	go r.loop()
+	return r
+}
+
+// Report 編碼後放入佇列；佇列已滿、已關閉或編碼失敗時丟棄並計數
+func (r *HTTPReporter) Report(err error, ctx map[string]interface{}) {
+	if err == nil {
+		return
+	}
+	body, encErr := r.encode(err, ctx)
+	if encErr != nil {
+		r.dropped.Add(1)
+		return
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- body:
+	default:
+		r.dropped.Add(1)
+	}
+}
+
+// Dropped 因佇列滿或編碼失敗而丟棄的回報數
+func (r *HTTPReporter) Dropped() int64 {
+	return r.dropped.Load()
+}
+
+// Close 停止接受回報並等待佇列送完，ctx 取消時提前返回
+func (r *HTTPReporter) Close(ctx context.Context) error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// loop 依序送出佇列中的回報，失敗不重試
+func (r *HTTPReporter) loop() {
+	defer close(r.done)
+	for body := range r.queue {
+		if err := r.send(body); err != nil {
+			r.dropped.Add(1)
+		}
+	}
+}
+
+func (r *HTTPReporter) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, r.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range r.header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("error reporter: %s responded %d", r.url, resp.StatusCode)
+	}
+	return nil
+}
+
+// reportType 錯誤的分類名稱，AppError 使用錯誤碼
+func reportType(err error) string {
+	if appErr, ok := err.(*AppError); ok {
+		return appErr.Code
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// encodeReport 預設的 JSON 編碼
+func encodeReport(err error, ctx map[string]interface{}) ([]byte, error) {
+	return json.Marshal(map[string]interface{}{
+		"error":   err.Error(),
+		"type":    reportType(err),
+		"time":    time.Now().UTC().Format(time.RFC3339Nano),
+		"context": ctx,
+	})
+}
+
+// ===== Sentry =====
+
+// NewSentryReporter 依 Sentry DSN（https://<key>@<host>/<project>）建立送往 store API 的 HTTPReporter
+// 範例用途的輕量實作：method / route / status / request_id 轉為 tag，user_id 轉為 user，其餘欄位放入 extra
+func NewSentryReporter(dsn string) (*HTTPReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sentry: invalid DSN: %w", err)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("sentry: DSN has no public key")
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndex(path, "/")
+	projectID := path[idx+1:]
+	if idx < 0 || projectID == "" {
+		return nil, fmt.Errorf("sentry: DSN has no project id")
+	}
+
+	endpoint := fmt.Sprintf("%s://%s%s/api/%s/store/", u.Scheme, u.Host, path[:idx], projectID)
+	header := http.Header{}
+	header.Set("X-Sentry-Auth", fmt.Sprintf("Sentry sentry_version=7, sentry_client=hypgo, sentry_key=%s", u.User.Username()))
+	return NewHTTPReporter(HTTPReporterConfig{
+		URL:    endpoint,
+		Header: header,
+		Encode: encodeSentryEvent,
+	}), nil
+}
+
+// sentryTags 轉為 Sentry tag 的欄位
+var sentryTags = []string{"method", "route", "status", "request_id"}
+
+// encodeSentryEvent 將錯誤編碼為 Sentry event
+func encodeSentryEvent(err error, ctx map[string]interface{}) ([]byte, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	tags := map[string]string{}
+	extra := map[string]interface{}{}
+	for k, v := range ctx {
+		extra[k] = v
+	}
+	for _, k := range sentryTags {
+		if v, ok := extra[k]; ok {
+			tags[k] = fmt.Sprint(v)
+			delete(extra, k)
+		}
+	}
+
+	event := map[string]interface{}{
+		"event_id":  hex.EncodeToString(id),
+		"timestamp": time.Now().UTC().Format(time.RFC3339Nano),
+		"level":     "error",
+		"platform":  "go",
+		"logger":    "hypgo",
+		"exception": map[string]interface{}{
+			"values": []map[string]string{{"type": reportType(err), "value": err.Error()}},
+		},
+		"tags":  tags,
+		"extra": extra,
+	}
+	if v, ok := extra["user_id"]; ok {
+		event["user"] = map[string]string{"id": fmt.Sprint(v)}
+		delete(extra, "user_id")
+	}
+	if path, ok := extra["path"].(string); ok {
+		event["request"] = map[string]interface{}{"url": path, "method": tags["method"]}
+		delete(extra, "path")
+	}
+	return json.Marshal(event)
+}
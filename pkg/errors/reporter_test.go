@@ -0,0 +1,103 @@
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// captureServer 記錄收到的請求 body 與標頭
+func captureServer(t *testing.T) (*httptest.Server, func() ([]map[string]interface{}, []http.Header)) {
+	t.Helper()
+	var mu sync.Mutex
+	var bodies []map[string]interface{}
+	var headers []http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("invalid JSON body %s", data)
+		}
+		mu.Lock()
+		bodies = append(bodies, body)
+		headers = append(headers, r.Header.Clone())
+		mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return srv, func() ([]map[string]interface{}, []http.Header) {
+		mu.Lock()
+		defer mu.Unlock()
+		return bodies, headers
+	}
+}
+
+func TestHTTPReporter(t *testing.T) {
+	srv, received := captureServer(t)
+	reporter := NewHTTPReporter(HTTPReporterConfig{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer t"}}})
+
+	reporter.Report(ErrInternalError.With("op", "charge"), map[string]interface{}{"route": "/pay"})
+	reporter.Report(errors.New("plain"), nil)
+	if err := reporter.Close(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	reporter.Report(errors.New("after close"), nil)
+
+	bodies, headers := received()
+	if len(bodies) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(bodies))
+	}
+	if bodies[0]["type"] != ErrInternalError.Code || bodies[0]["context"].(map[string]interface{})["route"] != "/pay" {
+		t.Errorf("unexpected body %v", bodies[0])
+	}
+	if bodies[1]["type"] != "*errors.errorString" || headers[0].Get("Authorization") != "Bearer t" {
+		t.Errorf("unexpected body %v / headers %v", bodies[1], headers[0])
+	}
+	if reporter.Dropped() != 0 {
+		t.Errorf("expected no drops, got %d", reporter.Dropped())
+	}
+}
+
+func TestSentryReporter(t *testing.T) {
+	var gotPath, gotAuth string
+	var event map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("X-Sentry-Auth")
+		json.NewDecoder(r.Body).Decode(&event)
+	}))
+	defer srv.Close()
+
+	dsn := strings.Replace(srv.URL, "://", "://pubkey@", 1) + "/sentry/42"
+	reporter, err := NewSentryReporter(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reporter.Report(errors.New("db down"), map[string]interface{}{
+		"method": "GET", "path": "/orders/9", "route": "/orders/:id", "status": 500, "user_id": 7, "stack": "trace",
+	})
+	reporter.Close(context.Background())
+
+	if gotPath != "/sentry/api/42/store/" || !strings.Contains(gotAuth, "sentry_key=pubkey") {
+		t.Errorf("unexpected endpoint %s auth %q", gotPath, gotAuth)
+	}
+	tags, _ := event["tags"].(map[string]interface{})
+	extra, _ := event["extra"].(map[string]interface{})
+	user, _ := event["user"].(map[string]interface{})
+	if tags["route"] != "/orders/:id" || tags["status"] != "500" || user["id"] != "7" || extra["stack"] != "trace" {
+		t.Errorf("unexpected event %v", event)
+	}
+	if _, leaked := extra["user_id"]; leaked {
+		t.Errorf("user_id should move to user, got extra %v", extra)
+	}
+
+	for _, bad := range []string{"https://sentry.io/42", "https://key@sentry.io/", "::"} {
+		if _, err := NewSentryReporter(bad); err == nil {
+			t.Errorf("expected error for DSN %q", bad)
+		}
+	}
+}
@@ -0,0 +1,336 @@
+// Package events 提供進程內的事件匯流排（pub/sub），讓元件以 topic 解耦
+// 例如 "user.created" 發佈後，寄信、更新搜尋索引各自訂閱，不必手動串接 channel。
+// 同步訂閱者在 Publish 的 goroutine 依訂閱順序執行並彙整錯誤；非同步訂閱者交由 worker pool 執行，
+// 同一訂閱者的事件依發佈順序逐一處理。WebSocket Hub 與 messaging Broker 可透過各自的橋接函數接上匯流排。
+//
+// @chris
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrClosed 匯流排已關閉
+var ErrClosed = errors.New("events: bus closed")
+
+// maxCollectedErrors 未設定 OnError 時保留的非同步錯誤上限，超過的只計數
+const maxCollectedErrors = 100
+
+// Event 一次發佈的事件
+type Event struct {
+	Topic   string
+	Payload interface{}
+	Time    time.Time
+}
+
+// Handler 事件處理函數
+type Handler func(ctx context.Context, e Event) error
+
+// HandlerError 單一訂閱者處理事件失敗
+type HandlerError struct {
+	Topic      string
+	Subscriber string // Named 指定的名稱，未指定時為 "#序號"
+	Err        error
+}
+
+func (e *HandlerError) Error() string {
+	return fmt.Sprintf("events: %s subscriber %s: %v", e.Topic, e.Subscriber, e.Err)
+}
+
+func (e *HandlerError) Unwrap() error { return e.Err }
+
+// ===== 配置 =====
+
+// Config 匯流排配置，零值欄位使用預設值
+type Config struct {
+	// Workers 非同步投遞的 worker 數，預設 runtime.NumCPU()
+	Workers int
+	// QueueSize 每個 worker 的佇列長度，預設 256；佇列滿時 Publish 阻塞直到有空位或 ctx 結束
+	QueueSize int
+	// OnError 非同步訂閱者的錯誤回呼（於 worker goroutine 呼叫）
+	// 未設定時錯誤由 Close 彙整回傳（最多保留 100 筆），長時間運行的服務建議設定
+	OnError func(e Event, err error)
+}
+
+// SubscribeOption 訂閱選項
+type SubscribeOption func(*subscription)
+
+// Async 以 worker pool 非同步處理：Publish 不等待，錯誤交給 Config.OnError
+// 同一訂閱者的事件固定由同一個 worker 依發佈順序處理
+func Async() SubscribeOption {
+	return func(s *subscription) { s.async = true }
+}
+
+// Named 設定訂閱者名稱，出現在 HandlerError 中以利追查
+func Named(name string) SubscribeOption {
+	return func(s *subscription) { s.name = name }
+}
+
+type subscription struct {
+	id      uint64
+	name    string
+	topic   string
+	handler Handler
+	async   bool
+	removed atomic.Bool
+}
+
+type delivery struct {
+	ctx   context.Context
+	sub   *subscription
+	event Event
+}
+
+// ===== 匯流排 =====
+
+// Bus 事件匯流排，並行安全
+type Bus struct {
+	mu     sync.RWMutex
+	subs   map[string][]*subscription
+	nextID uint64
+	closed bool
+
+	onError func(Event, error)
+	queues  []chan delivery
+	workers sync.WaitGroup
+	// pending 已進入佇列但尚未處理完的投遞，Publish 在送入佇列前加一，供 Close 等待
+	pending sync.WaitGroup
+
+	errMu   sync.Mutex
+	errs    []error
+	dropped int
+}
+
+// New 建立匯流排並啟動 worker
+//
+// EX：
+//
+//	bus := events.New(events.Config{Workers: 4})
+//	srv.OnShutdown(bus.Close)
+//
+//	bus.Subscribe("user.created", sendWelcomeEmail, events.Async())
+//	bus.Subscribe("user.created", indexUser, events.Async(), events.Named("search-index"))
+//
+//	if err := bus.Publish(ctx, "user.created", user); err != nil {
+//	    return err // 同步訂閱者的錯誤
+//	}
+func New(cfg Config) *Bus {
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 256
+	}
+	b := &Bus{
+		subs:    make(map[string][]*subscription),
+		onError: cfg.OnError,
+		queues:  make([]chan delivery, cfg.Workers),
+	}
+	for i := range b.queues {
+		b.queues[i] = make(chan delivery, cfg.QueueSize)
+		b.workers.Add(1)
+		go b.work(b.queues[i])
+	}
+	return b
+}
+
+// Subscribe 訂閱 topic，回傳取消訂閱的函數（可重複呼叫）
+// 預設為同步處理；取消後佇列中尚未處理的非同步事件不再投遞，已關閉的匯流排上訂閱不會收到任何事件
+func (b *Bus) Subscribe(topic string, handler Handler, opts ...SubscribeOption) (unsubscribe func()) {
+	b.mu.Lock()
+	b.nextID++
+	s := &subscription{id: b.nextID, topic: topic, handler: handler}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.name == "" {
+		s.name = fmt.Sprintf("#%d", s.id)
+	}
+	b.subs[topic] = append(b.subs[topic], s)
+	b.mu.Unlock()
+
+	return func() { b.unsubscribe(s) }
+}
+
+// On 以 payload 型別 T 訂閱 topic，payload 不是 T 時該訂閱者回報錯誤
+//
+// EX：
+//
+//	events.On(bus, "user.created", func(ctx context.Context, u *models.User) error {
+//	    return mailer.SendWelcome(ctx, u.Email)
+//	}, events.Async())
+func On[T any](b *Bus, topic string, handler func(ctx context.Context, payload T) error, opts ...SubscribeOption) (unsubscribe func()) {
+	return b.Subscribe(topic, func(ctx context.Context, e Event) error {
+		payload, ok := e.Payload.(T)
+		if !ok {
+			var want T
+			return fmt.Errorf("payload is %T, want %T", e.Payload, want)
+		}
+		return handler(ctx, payload)
+	}, opts...)
+}
+
+func (b *Bus) unsubscribe(s *subscription) {
+	if s.removed.Swap(true) {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	subs := b.subs[s.topic]
+	for i, existing := range subs {
+		if existing == s {
+			// 複製而非原地修改，進行中的 Publish 持有的切片不受影響
+			next := make([]*subscription, 0, len(subs)-1)
+			next = append(append(next, subs[:i]...), subs[i+1:]...)
+			b.subs[s.topic] = next
+			break
+		}
+	}
+	if len(b.subs[s.topic]) == 0 {
+		delete(b.subs, s.topic)
+	}
+}
+
+// Publish 發佈事件：同步訂閱者依訂閱順序在呼叫端執行，全部執行完才回傳，
+// 失敗的以 *HandlerError 經 errors.Join 彙整；非同步訂閱者送入佇列後即返回
+// ctx 會傳給處理函數；非同步處理時 ctx 的取消不影響已排入的事件（以 context.WithoutCancel 保留其值）
+func (b *Bus) Publish(ctx context.Context, topic string, payload interface{}) error {
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	subs := b.subs[topic]
+	// 持有讀鎖期間登記待處理數，Close 取得寫鎖後即可安全等待
+	async := 0
+	for _, s := range subs {
+		if s.async {
+			async++
+		}
+	}
+	b.pending.Add(async)
+	b.mu.RUnlock()
+
+	e := Event{Topic: topic, Payload: payload, Time: time.Now()}
+	var errs []error
+	detached := context.WithoutCancel(ctx)
+	for _, s := range subs {
+		if !s.async {
+			if s.removed.Load() {
+				continue
+			}
+			if err := call(ctx, s, e); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		select {
+		case b.queues[s.queue(len(b.queues))] <- delivery{ctx: detached, sub: s, event: e}:
+			async--
+		case <-ctx.Done():
+			// 尚未排入的投遞不再處理
+			b.pending.Add(-async)
+			return errors.Join(append(errs, ctx.Err())...)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// queue 依訂閱者固定對應到一個 worker，確保同一訂閱者依序處理
+func (s *subscription) queue(n int) int {
+	return int(s.id % uint64(n))
+}
+
+func (b *Bus) work(queue chan delivery) {
+	defer b.workers.Done()
+	for d := range queue {
+		if !d.sub.removed.Load() {
+			if err := call(d.ctx, d.sub, d.event); err != nil {
+				b.reportError(d.event, err)
+			}
+		}
+		b.pending.Done()
+	}
+}
+
+// call 執行處理函數，panic 轉為錯誤
+func call(ctx context.Context, s *subscription, e Event) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = &HandlerError{Topic: e.Topic, Subscriber: s.name, Err: fmt.Errorf("panic: %v", rec)}
+		}
+	}()
+	if err := s.handler(ctx, e); err != nil {
+		return &HandlerError{Topic: e.Topic, Subscriber: s.name, Err: err}
+	}
+	return nil
+}
+
+func (b *Bus) reportError(e Event, err error) {
+	if b.onError != nil {
+		b.onError(e, err)
+		return
+	}
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	if len(b.errs) < maxCollectedErrors {
+		b.errs = append(b.errs, err)
+	} else {
+		b.dropped++
+	}
+}
+
+// Close 停止接受新事件並等待佇列中的非同步事件處理完畢（優雅排空），可直接註冊為 srv.OnShutdown
+// ctx 結束前未排空時回傳 ctx 的錯誤，剩餘事件仍會在背景處理完；
+// 未設定 OnError 時一併回傳期間收集的非同步錯誤。重複呼叫回傳 ErrClosed
+func (b *Bus) Close(ctx context.Context) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		b.pending.Wait()
+		for _, q := range b.queues {
+			close(q)
+		}
+		b.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return fmt.Errorf("events: drain: %w", ctx.Err())
+	}
+
+	b.errMu.Lock()
+	defer b.errMu.Unlock()
+	errs := b.errs
+	if b.dropped > 0 {
+		errs = append(errs, fmt.Errorf("events: %d more async handler errors", b.dropped))
+	}
+	return errors.Join(errs...)
+}
+
+// Topics 各 topic 目前的訂閱者數（除錯用）
+func (b *Bus) Topics() map[string]int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	out := make(map[string]int, len(b.subs))
+	for topic, subs := range b.subs {
+		out[topic] = len(subs)
+	}
+	return out
+}
@@ -0,0 +1,159 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSyncOrderingAndErrorAggregation(t *testing.T) {
+	bus := New(Config{Workers: 2})
+	defer bus.Close(context.Background())
+
+	var order []string
+	bus.Subscribe("user.created", func(ctx context.Context, e Event) error {
+		order = append(order, "email")
+		return errors.New("smtp down")
+	}, Named("email"))
+	bus.Subscribe("user.created", func(ctx context.Context, e Event) error {
+		order = append(order, "index")
+		return nil
+	})
+	bus.Subscribe("user.created", func(ctx context.Context, e Event) error {
+		order = append(order, "audit")
+		panic("boom")
+	}, Named("audit"))
+
+	err := bus.Publish(context.Background(), "user.created", "alice")
+	if len(order) != 3 || order[0] != "email" || order[1] != "index" || order[2] != "audit" {
+		t.Fatalf("expected subscription order, got %v", order)
+	}
+	var herr *HandlerError
+	if !errors.As(err, &herr) || herr.Subscriber != "email" || herr.Topic != "user.created" {
+		t.Fatalf("expected HandlerError for email, got %v", err)
+	}
+	if got := err.Error(); !strings.Contains(got, "smtp down") || !strings.Contains(got, "audit: panic: boom") {
+		t.Errorf("expected both failures joined, got %q", got)
+	}
+
+	if err := bus.Publish(context.Background(), "nobody.listens", 1); err != nil {
+		t.Errorf("publishing without subscribers should succeed, got %v", err)
+	}
+}
+
+func TestAsyncPreservesPerSubscriberOrder(t *testing.T) {
+	bus := New(Config{Workers: 4, QueueSize: 8})
+
+	var mu sync.Mutex
+	got := map[string][]int{}
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		On(bus, "tick", func(ctx context.Context, n int) error {
+			mu.Lock()
+			got[name] = append(got[name], n)
+			mu.Unlock()
+			return nil
+		}, Async())
+	}
+	for i := 0; i < 200; i++ {
+		if err := bus.Publish(context.Background(), "tick", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	for name, seq := range got {
+		if len(seq) != 200 {
+			t.Fatalf("%s: expected 200 events after drain, got %d", name, len(seq))
+		}
+		for i, n := range seq {
+			if n != i {
+				t.Fatalf("%s: event %d out of order (got %d)", name, i, n)
+			}
+		}
+	}
+}
+
+func TestAsyncErrors(t *testing.T) {
+	// 未設定 OnError：Close 彙整回傳，並處理錯誤 payload 型別
+	bus := New(Config{Workers: 1})
+	On(bus, "order.paid", func(ctx context.Context, id int) error {
+		if id%2 == 1 {
+			return errors.New("odd order")
+		}
+		return nil
+	}, Async(), Named("billing"))
+
+	for i := 0; i < 4; i++ {
+		if err := bus.Publish(context.Background(), "order.paid", i); err != nil {
+			t.Fatalf("async failures must not reach Publish: %v", err)
+		}
+	}
+	bus.Publish(context.Background(), "order.paid", "not-an-int")
+
+	err := bus.Close(context.Background())
+	var herr *HandlerError
+	if !errors.As(err, &herr) || herr.Subscriber != "billing" {
+		t.Fatalf("expected aggregated HandlerError, got %v", err)
+	}
+	if got := err.Error(); !strings.Contains(got, "odd order") || !strings.Contains(got, "payload is string, want int") {
+		t.Errorf("unexpected aggregated error %q", got)
+	}
+	if errors.Is(bus.Close(context.Background()), ErrClosed) == false {
+		t.Error("second Close should return ErrClosed")
+	}
+	if err := bus.Publish(context.Background(), "order.paid", 1); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed after Close, got %v", err)
+	}
+
+	// 設定 OnError：錯誤交給回呼，Close 不再回傳
+	var mu sync.Mutex
+	var reported []error
+	bus = New(Config{Workers: 2, OnError: func(e Event, err error) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}})
+	bus.Subscribe("x", func(ctx context.Context, e Event) error { return errors.New("fail") }, Async())
+	bus.Publish(context.Background(), "x", nil)
+	if err := bus.Close(context.Background()); err != nil {
+		t.Errorf("expected no error with OnError set, got %v", err)
+	}
+	if len(reported) != 1 {
+		t.Errorf("expected one reported error, got %v", reported)
+	}
+}
+
+func TestCloseDrainTimeoutAndUnsubscribe(t *testing.T) {
+	bus := New(Config{Workers: 1})
+	release := make(chan struct{})
+	bus.Subscribe("slow", func(ctx context.Context, e Event) error {
+		<-release
+		return nil
+	}, Async())
+	bus.Publish(context.Background(), "slow", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Close(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected drain timeout, got %v", err)
+	}
+	close(release)
+
+	bus = New(Config{Workers: 1})
+	defer bus.Close(context.Background())
+	calls := 0
+	unsubscribe := bus.Subscribe("t", func(ctx context.Context, e Event) error { calls++; return nil })
+	bus.Publish(context.Background(), "t", nil)
+	unsubscribe()
+	unsubscribe()
+	bus.Publish(context.Background(), "t", nil)
+	if calls != 1 || len(bus.Topics()) != 0 {
+		t.Errorf("expected no deliveries after unsubscribe, calls=%d topics=%v", calls, bus.Topics())
+	}
+}
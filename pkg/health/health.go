@@ -0,0 +1,360 @@
+// Package health 提供健康檢查註冊中心
+// 各子系統（資料庫、副本、Redis、Cassandra、Kafka、Elasticsearch…）在啟動時
+// 註冊自己的檢查函數，由 Handler 並行執行並彙總結果。
+//
+// @chris
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// DefaultTimeout 未指定 WithTimeout 時每個檢查的逾時
+const DefaultTimeout = 5 * time.Second
+
+// 狀態字串
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
+// Checker 健康檢查函數，回傳 nil 表示健康；應遵守 ctx 的逾時
+type Checker func(ctx context.Context) error
+
+// Kind 檢查類型
+type Kind int
+
+const (
+	// Readiness 依賴就緒檢查（預設），失敗表示暫時不應接收流量
+	Readiness Kind = iota
+	// Liveness 存活檢查，失敗表示進程需要重啟；同時也會納入 readiness
+	Liveness
+)
+
+// String 回傳類型名稱
+func (k Kind) String() string {
+	if k == Liveness {
+		return "liveness"
+	}
+	return "readiness"
+}
+
+// Option 註冊選項
+type Option func(*check)
+
+// WithTimeout 設定單一檢查的逾時
+func WithTimeout(d time.Duration) Option {
+	return func(c *check) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithKind 設定檢查類型
+func WithKind(kind Kind) Option {
+	return func(c *check) {
+		c.kind = kind
+	}
+}
+
+type check struct {
+	name    string
+	fn      Checker
+	kind    Kind
+	timeout time.Duration
+}
+
+// Result 單一檢查結果
+type Result struct {
+	Name    string                 `json:"name"`
+	Kind    string                 `json:"kind"`
+	Status  string                 `json:"status"`
+	Error   string                 `json:"error,omitempty"`
+	Details map[string]interface{} `json:"details,omitempty"`
+	Latency time.Duration          `json:"-"`
+}
+
+// ===== 降級與附加資訊 =====
+
+// degradedError 標記依賴仍可連線但尚未就緒（如 migration 未套用完成）
+type degradedError struct{ err error }
+
+func (e *degradedError) Error() string { return e.err.Error() }
+func (e *degradedError) Unwrap() error { return e.err }
+
+// Degraded 將檢查錯誤標記為降級：該項狀態為 degraded 而非 unhealthy，
+// 報告整體為 degraded，readiness 仍回應 503；err 為 nil 時回傳 nil
+func Degraded(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &degradedError{err: err}
+}
+
+// IsDegraded err 是否經 Degraded 標記
+func IsDegraded(err error) bool {
+	var d *degradedError
+	return errors.As(err, &d)
+}
+
+type detailsKey struct{}
+
+// details 檢查函數寫入的附加資訊；檢查逾時後函數仍可能寫入，故以鎖保護
+type details struct {
+	mu     sync.Mutex
+	values map[string]interface{}
+}
+
+// SetDetail 在檢查結果附加資訊（如版本號），健康與否都會出現在回應的 details 欄位
+// 僅在 Registry 執行的檢查函數內有效，其餘情況忽略
+//
+// EX：
+//
+//	health.Register("schema", func(ctx context.Context) error {
+//	    health.SetDetail(ctx, "version", version)
+//	    return nil
+//	})
+func SetDetail(ctx context.Context, key string, value interface{}) {
+	d, ok := ctx.Value(detailsKey{}).(*details)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.values == nil {
+		d.values = make(map[string]interface{})
+	}
+	d.values[key] = value
+}
+
+// snapshot 複製目前的附加資訊，沒有時為 nil
+func (d *details) snapshot() map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.values) == 0 {
+		return nil
+	}
+	out := make(map[string]interface{}, len(d.values))
+	for k, v := range d.values {
+		out[k] = v
+	}
+	return out
+}
+
+// Report 彙總結果
+type Report struct {
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	Checks    []Result  `json:"checks"`
+}
+
+// Healthy 是否所有檢查都通過（degraded 不算通過）
+func (r Report) Healthy() bool {
+	return r.Status == StatusHealthy
+}
+
+// Registry 健康檢查註冊中心
+type Registry struct {
+	mu     sync.RWMutex
+	checks map[string]*check
+}
+
+// NewRegistry 創建空的註冊中心
+func NewRegistry() *Registry {
+	return &Registry{checks: make(map[string]*check)}
+}
+
+// Default 預設的全域註冊中心
+var Default = NewRegistry()
+
+// Register 註冊檢查，同名檢查會被覆蓋
+func (r *Registry) Register(name string, fn Checker, opts ...Option) {
+	c := &check{name: name, fn: fn, kind: Readiness, timeout: DefaultTimeout}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks[name] = c
+}
+
+// Unregister 移除檢查
+func (r *Registry) Unregister(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.checks, name)
+}
+
+// Names 回傳已註冊的檢查名稱（排序後）
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.checks))
+	for name := range r.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Check 並行執行指定類型的檢查
+// kind 為 Liveness 時只執行存活檢查；Readiness 時執行全部檢查
+func (r *Registry) Check(ctx context.Context, kind Kind) Report {
+	r.mu.RLock()
+	checks := make([]*check, 0, len(r.checks))
+	for _, c := range r.checks {
+		if kind == Readiness || c.kind == Liveness {
+			checks = append(checks, c)
+		}
+	}
+	r.mu.RUnlock()
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			results[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	// 任一 unhealthy 即為 unhealthy；否則任一 degraded 即為 degraded
+	report := Report{Status: StatusHealthy, Timestamp: time.Now(), Checks: results}
+	for _, res := range results {
+		switch res.Status {
+		case StatusUnhealthy:
+			report.Status = StatusUnhealthy
+		case StatusDegraded:
+			if report.Status == StatusHealthy {
+				report.Status = StatusDegraded
+			}
+		}
+	}
+	return report
+}
+
+// run 在逾時內執行檢查；檢查函數忽略 ctx 或 panic 時亦能回報
+func (c *check) run(parent context.Context) Result {
+	d := &details{}
+	ctx, cancel := context.WithTimeout(context.WithValue(parent, detailsKey{}, d), c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				errCh <- fmt.Errorf("panic: %v", rec)
+			}
+		}()
+		errCh <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = fmt.Errorf("timed out after %s", c.timeout)
+	}
+
+	res := Result{Name: c.name, Kind: c.kind.String(), Status: StatusHealthy, Details: d.snapshot(), Latency: time.Since(start)}
+	if err != nil {
+		res.Status = StatusUnhealthy
+		if IsDegraded(err) {
+			res.Status = StatusDegraded
+		}
+		res.Error = err.Error()
+	}
+	return res
+}
+
+// Handler 回傳執行全部檢查的 HTTP handler（readiness）
+// 健康回應 200，degraded 或 unhealthy 回應 503；帶 ?verbose 時附上每項檢查耗時
+//
+// EX：
+//
+//	health.Register("cassandra", cass.Ping)
+//	health.Register("process", func(ctx context.Context) error { return nil }, health.WithKind(health.Liveness))
+//	r.GET("/health", health.Handler())
+//	r.GET("/health/live", health.LivenessHandler())
+func (r *Registry) Handler() hypcontext.HandlerFunc {
+	return r.handler(Readiness)
+}
+
+// LivenessHandler 回傳只執行存活檢查的 HTTP handler
+func (r *Registry) LivenessHandler() hypcontext.HandlerFunc {
+	return r.handler(Liveness)
+}
+
+func (r *Registry) handler(kind Kind) hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		report := r.Check(c.Request.Context(), kind)
+
+		code := http.StatusOK
+		if !report.Healthy() {
+			code = http.StatusServiceUnavailable
+		}
+		c.Header("Cache-Control", "no-store")
+
+		_, verbose := c.GetQuery("verbose")
+		checks := make([]hypcontext.H, 0, len(report.Checks))
+		for _, res := range report.Checks {
+			item := hypcontext.H{"name": res.Name, "status": res.Status}
+			if res.Error != "" {
+				item["error"] = res.Error
+			}
+			if res.Details != nil {
+				item["details"] = res.Details
+			}
+			if verbose {
+				item["kind"] = res.Kind
+				item["latency_ms"] = float64(res.Latency.Microseconds()) / 1000
+			}
+			checks = append(checks, item)
+		}
+
+		body := hypcontext.H{"status": report.Status, "checks": checks}
+		if verbose {
+			body["probe"] = kind.String()
+			body["timestamp"] = report.Timestamp.Unix()
+		}
+		c.JSON(code, body)
+	}
+}
+
+// ===== 預設註冊中心捷徑 =====
+
+// Register 註冊檢查至 Default
+func Register(name string, fn Checker, opts ...Option) {
+	Default.Register(name, fn, opts...)
+}
+
+// Unregister 從 Default 移除檢查
+func Unregister(name string) {
+	Default.Unregister(name)
+}
+
+// Handler 回傳 Default 的 readiness handler
+func Handler() hypcontext.HandlerFunc {
+	return Default.Handler()
+}
+
+// LivenessHandler 回傳 Default 的 liveness handler
+func LivenessHandler() hypcontext.HandlerFunc {
+	return Default.LivenessHandler()
+}
@@ -0,0 +1,147 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func TestRegistryCheck(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error { return nil })
+	reg.Register("cache", func(ctx context.Context) error { return errors.New("down") })
+	reg.Register("process", func(ctx context.Context) error { return nil }, WithKind(Liveness))
+
+	report := reg.Check(context.Background(), Readiness)
+	if report.Healthy() || len(report.Checks) != 3 {
+		t.Fatalf("unexpected readiness report: %+v", report)
+	}
+	if report.Checks[0].Name != "cache" || report.Checks[0].Error != "down" {
+		t.Errorf("Expected sorted results with cache error, got %+v", report.Checks[0])
+	}
+
+	live := reg.Check(context.Background(), Liveness)
+	if !live.Healthy() || len(live.Checks) != 1 {
+		t.Errorf("Expected only liveness checks, got %+v", live)
+	}
+}
+
+func TestRegistryTimeoutAndPanic(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, WithTimeout(20*time.Millisecond))
+	reg.Register("panics", func(ctx context.Context) error { panic("boom") })
+
+	start := time.Now()
+	report := reg.Check(context.Background(), Readiness)
+	if time.Since(start) > 500*time.Millisecond {
+		t.Error("Slow check should be cut off by its timeout")
+	}
+	for _, res := range report.Checks {
+		if res.Status != StatusUnhealthy {
+			t.Errorf("Expected %s to be unhealthy, got %+v", res.Name, res)
+		}
+	}
+}
+
+func TestDegradedAndDetails(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error {
+		SetDetail(ctx, "pool", 4)
+		return nil
+	})
+	reg.Register("schema", func(ctx context.Context) error {
+		SetDetail(ctx, "current_version", 1)
+		SetDetail(ctx, "target_version", 2)
+		return Degraded(errors.New("1 pending migration"))
+	})
+
+	report := reg.Check(context.Background(), Readiness)
+	if report.Status != StatusDegraded || report.Healthy() {
+		t.Fatalf("Expected degraded report, got %+v", report)
+	}
+	db, schema := report.Checks[0], report.Checks[1]
+	if db.Status != StatusHealthy || db.Details["pool"] != 4 {
+		t.Errorf("Expected healthy check with details, got %+v", db)
+	}
+	if schema.Status != StatusDegraded || schema.Error != "1 pending migration" || schema.Details["target_version"] != 2 {
+		t.Errorf("Unexpected degraded result %+v", schema)
+	}
+
+	// unhealthy 優先於 degraded
+	reg.Register("cache", func(ctx context.Context) error { return errors.New("down") })
+	if report = reg.Check(context.Background(), Readiness); report.Status != StatusUnhealthy {
+		t.Errorf("Expected unhealthy to win over degraded, got %s", report.Status)
+	}
+
+	if Degraded(nil) != nil {
+		t.Error("Degraded(nil) should be nil")
+	}
+	SetDetail(context.Background(), "ignored", 1) // 檢查函數外呼叫不應 panic
+
+	reg.Unregister("cache")
+	r := router.New()
+	r.GET("/ready", reg.Handler())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503 for degraded readiness, got %d", w.Code)
+	}
+	var body struct {
+		Status string `json:"status"`
+		Checks []struct {
+			Name    string                 `json:"name"`
+			Status  string                 `json:"status"`
+			Details map[string]interface{} `json:"details"`
+		} `json:"checks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != StatusDegraded || body.Checks[1].Details["current_version"] != float64(1) {
+		t.Errorf("Unexpected body %s", w.Body.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	reg := NewRegistry()
+	reg.Register("db", func(ctx context.Context) error { return nil })
+
+	r := router.New()
+	r.GET("/health", reg.Handler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/health?verbose", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", w.Code)
+	}
+
+	var body struct {
+		Status string                   `json:"status"`
+		Checks []map[string]interface{} `json:"checks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Status != StatusHealthy || len(body.Checks) != 1 {
+		t.Fatalf("unexpected body: %s", w.Body.String())
+	}
+	if _, ok := body.Checks[0]["latency_ms"]; !ok {
+		t.Error("Expected latency_ms in verbose output")
+	}
+
+	reg.Register("cache", func(ctx context.Context) error { return errors.New("down") })
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %d", w.Code)
+	}
+}
@@ -3,7 +3,10 @@ package cassandra
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
 
 	"github.com/gocql/gocql"
 )
@@ -70,7 +73,9 @@ func (b *BatchBuilder) Exec(ctx context.Context) error {
 	if b.timestamp > 0 {
 		b.batch.WithTimestamp(b.timestamp)
 	}
-	return b.db.session.ExecuteBatch(b.batch.WithContext(ctx))
+	return b.db.run(ctx, "batch", func(ctx context.Context) error {
+		return b.db.session.ExecuteBatch(b.batch.WithContext(ctx))
+	})
 }
 
 // ExecCAS executes the batch as a conditional batch (LWT) returning
@@ -85,6 +90,224 @@ func (b *BatchBuilder) ExecCAS(ctx context.Context, dest ...interface{}) (bool,
 	if b.timestamp > 0 {
 		b.batch.WithTimestamp(b.timestamp)
 	}
-	applied, _, err := b.db.session.ExecuteBatchCAS(b.batch.WithContext(ctx), dest...)
+	var applied bool
+	err := b.db.run(ctx, "batch", func(ctx context.Context) error {
+		var err error
+		applied, _, err = b.db.session.ExecuteBatchCAS(b.batch.WithContext(ctx), dest...)
+		return err
+	})
 	return applied, err
 }
+
+// Batch size defaults. Cassandra warns at batch_size_warn_threshold (5KB) and
+// rejects at batch_size_fail_threshold (50KB) by default; large multi-partition
+// logged batches also put heavy load on the coordinator.
+const (
+	DefaultBatchMaxStatements = 100
+	DefaultBatchMaxBytes      = 48 << 10
+)
+
+// BatchInsertOption customises BatchInsert.
+type BatchInsertOption func(*batchInsertConfig)
+
+type batchInsertConfig struct {
+	maxStatements int
+	maxBytes      int
+	partitionKey  string
+	concurrency   int
+}
+
+// BatchMaxStatements caps the number of statements per sub-batch (default 100).
+func BatchMaxStatements(n int) BatchInsertOption {
+	return func(c *batchInsertConfig) { c.maxStatements = n }
+}
+
+// BatchMaxBytes caps the estimated payload size per sub-batch (default 48KB,
+// just under Cassandra's default 50KB fail threshold).
+func BatchMaxBytes(n int) BatchInsertOption {
+	return func(c *batchInsertConfig) { c.maxBytes = n }
+}
+
+// BatchPartitionKey hints the partition key column. Rows are grouped by its
+// value and each group is written with UnloggedBatch: a single-partition batch
+// is applied atomically by one replica set without the batchlog overhead.
+func BatchPartitionKey(col string) BatchInsertOption {
+	return func(c *batchInsertConfig) { c.partitionKey = col }
+}
+
+// BatchConcurrency executes up to n sub-batches at the same time (default 1,
+// sequential).
+func BatchConcurrency(n int) BatchInsertOption {
+	return func(c *batchInsertConfig) { c.concurrency = n }
+}
+
+// batchStmt is one rendered INSERT with its estimated size.
+type batchStmt struct {
+	cql  string
+	args []interface{}
+	size int
+	row  int // index in dataList, for error reporting
+}
+
+// batchChunk is one sub-batch to execute.
+type batchChunk struct {
+	typ   gocql.BatchType
+	stmts []batchStmt
+}
+
+// BatchInsert inserts dataList into table, split into sub-batches that stay
+// under the statement-count and byte thresholds. Without a partition-key hint
+// each sub-batch is a LoggedBatch. Every sub-batch is attempted; failures are
+// joined into the returned error with the affected row range.
+//
+// Example:
+//
+//	err := db.BatchInsert(ctx, "app.events", rows,
+//	    cassandra.BatchPartitionKey("device_id"),
+//	    cassandra.BatchConcurrency(4))
+func (c *CassandraDB) BatchInsert(ctx context.Context, table string, dataList []map[string]interface{}, opts ...BatchInsertOption) error {
+	cfg := batchInsertConfig{
+		maxStatements: DefaultBatchMaxStatements,
+		maxBytes:      DefaultBatchMaxBytes,
+		concurrency:   1,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.maxStatements <= 0 {
+		cfg.maxStatements = DefaultBatchMaxStatements
+	}
+	if cfg.concurrency <= 0 {
+		cfg.concurrency = 1
+	}
+
+	chunks, err := c.planBatchInsert(table, dataList, cfg)
+	if err != nil {
+		return err
+	}
+
+	errs := make([]error, len(chunks))
+	sem := make(chan struct{}, cfg.concurrency)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int, chunk batchChunk) {
+			defer func() { <-sem; wg.Done() }()
+			b := c.NewBatch(chunk.typ)
+			for _, s := range chunk.stmts {
+				b.AddRaw(s.cql, s.args...)
+			}
+			if err := b.Exec(ctx); err != nil {
+				first, last := chunk.rowRange()
+				errs[i] = fmt.Errorf("cassandra: batch insert rows %d-%d: %w", first, last, err)
+			}
+		}(i, chunk)
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// planBatchInsert renders every row and splits the statements into chunks.
+func (c *CassandraDB) planBatchInsert(table string, dataList []map[string]interface{}, cfg batchInsertConfig) ([]batchChunk, error) {
+	groups := [][]batchStmt{nil}
+	groupIndex := map[string]int{}
+	for row, data := range dataList {
+		if len(data) == 0 {
+			return nil, fmt.Errorf("cassandra: batch insert row %d is empty", row)
+		}
+		cols := make([]string, 0, len(data))
+		for col := range data {
+			cols = append(cols, col)
+		}
+		sort.Strings(cols)
+
+		ins := c.Insert(table)
+		for _, col := range cols {
+			ins.Value(col, data[col])
+		}
+		cql, args := ins.CQL()
+		stmt := batchStmt{cql: cql, args: args, size: estimateStmtSize(cql, args), row: row}
+
+		if cfg.partitionKey == "" {
+			groups[0] = append(groups[0], stmt)
+			continue
+		}
+		pk, ok := data[cfg.partitionKey]
+		if !ok {
+			return nil, fmt.Errorf("cassandra: batch insert row %d has no partition key %q", row, cfg.partitionKey)
+		}
+		key := fmt.Sprintf("%T:%v", pk, pk)
+		gi, seen := groupIndex[key]
+		if !seen {
+			gi = len(groups)
+			groupIndex[key] = gi
+			groups = append(groups, nil)
+		}
+		groups[gi] = append(groups[gi], stmt)
+	}
+
+	typ := gocql.LoggedBatch
+	if cfg.partitionKey != "" {
+		typ = gocql.UnloggedBatch
+	}
+	var chunks []batchChunk
+	for _, group := range groups {
+		for _, stmts := range splitBatch(group, cfg.maxStatements, cfg.maxBytes) {
+			chunks = append(chunks, batchChunk{typ: typ, stmts: stmts})
+		}
+	}
+	return chunks, nil
+}
+
+// splitBatch cuts stmts into consecutive chunks of at most maxStatements and,
+// when maxBytes > 0, at most maxBytes; a single oversized statement gets its
+// own chunk.
+func splitBatch(stmts []batchStmt, maxStatements, maxBytes int) [][]batchStmt {
+	var chunks [][]batchStmt
+	start, size := 0, 0
+	for i, s := range stmts {
+		n := i - start
+		if n > 0 && (n >= maxStatements || (maxBytes > 0 && size+s.size > maxBytes)) {
+			chunks = append(chunks, stmts[start:i])
+			start, size = i, 0
+		}
+		size += s.size
+	}
+	if start < len(stmts) {
+		chunks = append(chunks, stmts[start:])
+	}
+	return chunks
+}
+
+// rowRange returns the first and last dataList index in the chunk.
+func (b batchChunk) rowRange() (int, int) {
+	first, last := b.stmts[0].row, b.stmts[0].row
+	for _, s := range b.stmts[1:] {
+		if s.row < first {
+			first = s.row
+		}
+		if s.row > last {
+			last = s.row
+		}
+	}
+	return first, last
+}
+
+// estimateStmtSize approximates the serialized size of a statement: the CQL
+// text plus its bind values (fixed-width types count as 8 bytes).
+func estimateStmtSize(cql string, args []interface{}) int {
+	size := len(cql)
+	for _, a := range args {
+		switch v := a.(type) {
+		case string:
+			size += len(v)
+		case []byte:
+			size += len(v)
+		case nil:
+		default:
+			size += 8
+		}
+	}
+	return size
+}
@@ -33,9 +33,10 @@ type Config struct {
 	Port             int           `mapstructure:"port" yaml:"port"`               // 預設 9042
 	Username         string        `mapstructure:"username" yaml:"username"`
 	Password         string        `mapstructure:"password" yaml:"password"`
-	ConnectTimeout   time.Duration `mapstructure:"connect_timeout" yaml:"connect_timeout"` // 預設 5s
-	Timeout          time.Duration `mapstructure:"timeout" yaml:"timeout"`                 // 預設 10s
-	NumConns         int           `mapstructure:"num_conns" yaml:"num_conns"`             // 每個主機的連接數
+	ConnectTimeout   time.Duration `mapstructure:"connect_timeout" yaml:"connect_timeout"`     // 預設 5s
+	Timeout          time.Duration `mapstructure:"timeout" yaml:"timeout"`                     // 預設 10s
+	OperationTimeout time.Duration `mapstructure:"operation_timeout" yaml:"operation_timeout"` // 未帶 deadline 的操作上限，預設 30s，負值關閉
+	NumConns         int           `mapstructure:"num_conns" yaml:"num_conns"`                 // 每個主機的連接數
 	MaxPreparedStmts int           `mapstructure:"max_prepared_stmts" yaml:"max_prepared_stmts"`
 	ProtoVersion     int           `mapstructure:"proto_version" yaml:"proto_version"` // 預設 4
 
@@ -56,8 +57,20 @@ type Config struct {
 	// StrictConsistency=true 時，Consistency 字串無法解析會直接回 error。
 	// 預設 false → 無法解析時 fallback 為 LocalOne（並非 Quorum，更利於單節點 dev）。
 	StrictConsistency bool `mapstructure:"strict_consistency" yaml:"strict_consistency"`
+
+	// ShardAware=true 時優先連線 ScyllaDB 的 shard-aware 埠（ShardAwarePort，預設 19042）。
+	// ScyllaDB 每個 CPU 核心（shard）各自擁有一段 token，請求送到非擁有者的 shard 會多一次跨核轉送；
+	// shard-aware 連線讓每個請求直達擁有該 token 的 shard，省下這一跳，p99 延遲通常明顯下降。
+	// 依 shard 選擇連線需搭配 ScyllaDB 的 gocql 分支（go.mod 加上
+	// replace github.com/gocql/gocql => github.com/scylladb/gocql），上游 gocql 僅會做 token-aware 路由。
+	// 連線失敗（如 Cassandra 或未開放該埠）時自動退回一般埠，可由 ShardAwareActive 確認。
+	ShardAware     bool `mapstructure:"shard_aware" yaml:"shard_aware"`
+	ShardAwarePort int  `mapstructure:"shard_aware_port" yaml:"shard_aware_port"`
 }
 
+// DefaultShardAwarePort ScyllaDB shard-aware CQL 埠
+const DefaultShardAwarePort = 19042
+
 // CassandraDB Cassandra 數據庫管理器
 type CassandraDB struct {
 	cluster *gocql.ClusterConfig
@@ -66,6 +79,8 @@ type CassandraDB struct {
 
 	mu     sync.Mutex
 	closed bool
+	// 目前 session 是否經由 shard-aware 埠建立
+	shardAware bool
 }
 
 // New 創建 Cassandra 實例並建立連接。
@@ -232,6 +247,9 @@ func (c *CassandraDB) init() error {
 		cluster.QueryObserver = c.config.QueryObserver
 	}
 
+	// token-aware 路由：請求直接送往持有該 partition 的副本
+	cluster.PoolConfig.HostSelectionPolicy = tokenAwarePolicy()
+
 	c.cluster = cluster
 	return nil
 }
@@ -284,7 +302,7 @@ func (c *CassandraDB) Connect() error {
 		c.session = nil
 	}
 
-	session, err := c.cluster.CreateSession()
+	session, err := c.createSession()
 	if err != nil {
 		return fmt.Errorf("cassandra: failed to create session: %w", err)
 	}
@@ -292,6 +310,54 @@ func (c *CassandraDB) Connect() error {
 	return nil
 }
 
+// tokenAwarePolicy token-aware 路由，找不到副本時以 round-robin 選擇主機
+// gocql 不支援多個 session 共用同一個 policy，每次建立 session 前都要重新產生
+func tokenAwarePolicy() gocql.HostSelectionPolicy {
+	return gocql.TokenAwareHostPolicy(gocql.RoundRobinHostPolicy())
+}
+
+// createSession ShardAware 時先連線 shard-aware 埠，失敗則以一般埠建立 token-aware session
+// 呼叫端需持有 c.mu
+func (c *CassandraDB) createSession() (*gocql.Session, error) {
+	c.shardAware = false
+	if !c.config.ShardAware {
+		c.cluster.PoolConfig.HostSelectionPolicy = tokenAwarePolicy()
+		return c.cluster.CreateSession()
+	}
+
+	shard := c.shardAwareCluster()
+	session, shardErr := shard.CreateSession()
+	if shardErr == nil {
+		c.shardAware = true
+		return session, nil
+	}
+
+	c.cluster.PoolConfig.HostSelectionPolicy = tokenAwarePolicy()
+	session, err := c.cluster.CreateSession()
+	if err != nil {
+		return nil, fmt.Errorf("shard-aware port %d: %v; fallback: %w", shard.Port, shardErr, err)
+	}
+	return session, nil
+}
+
+// shardAwareCluster 以 shard-aware 埠複製 cluster 設定
+func (c *CassandraDB) shardAwareCluster() *gocql.ClusterConfig {
+	shard := *c.cluster
+	shard.Port = c.config.ShardAwarePort
+	if shard.Port <= 0 {
+		shard.Port = DefaultShardAwarePort
+	}
+	shard.PoolConfig.HostSelectionPolicy = tokenAwarePolicy()
+	return &shard
+}
+
+// ShardAwareActive 目前 session 是否經由 shard-aware 埠建立（ShardAware 未啟用或已退回一般埠時為 false）
+func (c *CassandraDB) ShardAwareActive() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.session != nil && c.shardAware
+}
+
 // Session 獲取 gocql.Session（用戶直接操作 CQL）
 func (c *CassandraDB) Session() *gocql.Session {
 	resource.MarkCassandra()
@@ -329,8 +395,10 @@ func (c *CassandraDB) Ping(ctx context.Context) error {
 		return fmt.Errorf("cassandra: session not connected")
 	}
 
-	iter := sess.Query("SELECT now() FROM system.local").WithContext(ctx).Iter()
-	if err := iter.Close(); err != nil {
+	err := c.run(ctx, "ping", func(ctx context.Context) error {
+		return sess.Query("SELECT now() FROM system.local").WithContext(ctx).Iter().Close()
+	})
+	if err != nil {
 		return fmt.Errorf("cassandra: ping failed: %w", err)
 	}
 	return nil
@@ -398,6 +466,9 @@ func (c *CassandraDB) Init(config map[string]interface{}) error {
 	if v, ok := asDuration(config["timeout"]); ok {
 		c.config.Timeout = v
 	}
+	if v, ok := asDuration(config["operation_timeout"]); ok {
+		c.config.OperationTimeout = v
+	}
 	if v, ok := config["num_conns"].(int); ok {
 		c.config.NumConns = v
 	}
@@ -425,6 +496,12 @@ func (c *CassandraDB) Init(config map[string]interface{}) error {
 	if v, ok := config["strict_consistency"].(bool); ok {
 		c.config.StrictConsistency = v
 	}
+	if v, ok := config["shard_aware"].(bool); ok {
+		c.config.ShardAware = v
+	}
+	if v, ok := config["shard_aware_port"].(int); ok {
+		c.config.ShardAwarePort = v
+	}
 
 	// nested tls block
 	if tlsRaw, ok := config["tls"].(map[string]interface{}); ok {
@@ -473,13 +550,16 @@ func asDuration(v interface{}) (time.Duration, bool) {
 
 // ===== Cassandra 特殊功能 =====
 
-// Query 執行 CQL 查詢（便捷方法）
+// Query 執行 CQL 查詢（便捷方法）；Query 交由呼叫端執行，不套用預設 OperationTimeout
 func (c *CassandraDB) Query(stmt string, values ...interface{}) *gocql.Query {
-	return c.session.Query(stmt, values...)
+	return c.session.Query(stmt, values...).WithContext(context.Background())
 }
 
-// QueryContext 執行帶 context 的 CQL 查詢
+// QueryContext 執行帶 context 的 CQL 查詢；Query 交由呼叫端執行，逾時由 ctx 決定，不套用預設 OperationTimeout
 func (c *CassandraDB) QueryContext(ctx context.Context, stmt string, values ...interface{}) *gocql.Query {
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	return c.session.Query(stmt, values...).WithContext(ctx)
 }
 
@@ -497,14 +577,19 @@ func (c *CassandraDB) Exec(ctx context.Context, stmt string) error {
 	if stmt == "" {
 		return nil
 	}
-	return c.session.Query(stmt).WithContext(ctx).Exec()
+	return c.run(ctx, "exec", func(ctx context.Context) error {
+		return c.session.Query(stmt).WithContext(ctx).Exec()
+	})
 }
 
 // ExecScript executes a multi-statement CQL script, ignoring empty segments.
 // Bind args are not supported; use separate Exec calls for parameterised CQL.
 func (c *CassandraDB) ExecScript(ctx context.Context, script string) error {
 	for i, p := range splitStatements(script) {
-		if err := c.session.Query(p).WithContext(ctx).Exec(); err != nil {
+		err := c.run(ctx, "exec", func(ctx context.Context) error {
+			return c.session.Query(p).WithContext(ctx).Exec()
+		})
+		if err != nil {
 			return fmt.Errorf("cassandra: stmt %d failed: %w", i+1, err)
 		}
 	}
@@ -554,8 +639,11 @@ func (c *CassandraDB) Batch(batchType gocql.BatchType) *gocql.Batch {
 }
 
 // ExecuteBatch 執行批次操作（gocql 原生介面）
+// batch 未帶 deadline 時套用預設 OperationTimeout
 func (c *CassandraDB) ExecuteBatch(batch *gocql.Batch) error {
-	return c.session.ExecuteBatch(batch)
+	return c.run(batch.Context(), "batch", func(ctx context.Context) error {
+		return c.session.ExecuteBatch(batch.WithContext(ctx))
+	})
 }
 
 // KeyspaceName returns the current session keyspace (from config).
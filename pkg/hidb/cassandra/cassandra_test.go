@@ -2,6 +2,8 @@ package cassandra
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"testing"
@@ -801,6 +803,58 @@ func TestInitDisableRetryAndReconnect(t *testing.T) {
 	}
 }
 
+func TestShardAwareCluster(t *testing.T) {
+	c := &CassandraDB{}
+	if err := c.Init(map[string]interface{}{
+		"hosts":       []interface{}{"127.0.0.1"},
+		"port":        9042,
+		"shard_aware": true,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if c.cluster.PoolConfig.HostSelectionPolicy == nil {
+		t.Error("expected token-aware host selection policy")
+	}
+
+	shard := c.shardAwareCluster()
+	if shard.Port != DefaultShardAwarePort || c.cluster.Port != 9042 {
+		t.Errorf("shard port = %d, base port = %d", shard.Port, c.cluster.Port)
+	}
+	if shard.PoolConfig.HostSelectionPolicy == c.cluster.PoolConfig.HostSelectionPolicy {
+		t.Error("shard-aware session must not share the base host selection policy")
+	}
+
+	c.config.ShardAwarePort = 29042
+	if got := c.shardAwareCluster().Port; got != 29042 {
+		t.Errorf("custom shard port = %d", got)
+	}
+}
+
+func TestShardAwareFallbackError(t *testing.T) {
+	c, err := NewWithoutConnect(Config{
+		Hosts:          []string{"127.0.0.1"},
+		Port:           1,
+		ShardAware:     true,
+		ShardAwarePort: 2,
+		ConnectTimeout: 200 * time.Millisecond,
+		NumRetries:     -1,
+		ReconnectMax:   -1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Connect()
+	if err == nil {
+		t.Fatal("expected connection failure without a cluster")
+	}
+	if !strings.Contains(err.Error(), "shard-aware port 2") || !strings.Contains(err.Error(), "fallback") {
+		t.Errorf("expected both attempts in error, got %v", err)
+	}
+	if c.ShardAwareActive() {
+		t.Error("ShardAwareActive must be false without a session")
+	}
+}
+
 func TestInitTLSEnabled(t *testing.T) {
 	c := &CassandraDB{config: Config{
 		Hosts: []string{"127.0.0.1"},
@@ -934,3 +988,337 @@ func TestVectorDimMismatch(t *testing.T) {
 		t.Error("expected dim<=0 error")
 	}
 }
+
+func batchRows(n int, pk func(i int) interface{}) []map[string]interface{} {
+	rows := make([]map[string]interface{}, n)
+	for i := range rows {
+		rows[i] = map[string]interface{}{"id": i, "device_id": pk(i), "payload": "x"}
+	}
+	return rows
+}
+
+func chunkSizes(chunks []batchChunk) []int {
+	sizes := make([]int, len(chunks))
+	for i, ch := range chunks {
+		sizes[i] = len(ch.stmts)
+	}
+	return sizes
+}
+
+func TestBatchInsertChunkBoundaries(t *testing.T) {
+	db := &CassandraDB{}
+	same := func(int) interface{} { return "dev-1" }
+	cfg := batchInsertConfig{maxStatements: DefaultBatchMaxStatements}
+
+	for _, tc := range []struct {
+		rows int
+		want string
+	}{
+		{1, "[1]"},
+		{100, "[100]"},
+		{101, "[100 1]"},
+		{250, "[100 100 50]"},
+	} {
+		chunks, err := db.planBatchInsert("app.events", batchRows(tc.rows, same), cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(chunkSizes(chunks)); got != tc.want {
+			t.Errorf("%d rows: chunks = %s, want %s", tc.rows, got, tc.want)
+		}
+		if chunks[0].typ != gocql.LoggedBatch {
+			t.Errorf("expected LoggedBatch without partition hint")
+		}
+	}
+
+	if chunks, _ := db.planBatchInsert("t", nil, cfg); len(chunks) != 0 {
+		t.Errorf("empty dataList produced %d chunks", len(chunks))
+	}
+
+	// 第 3 列後超過位元組上限
+	stmts, _ := db.planBatchInsert("t", batchRows(1, same), cfg)
+	one := stmts[0].stmts[0].size
+	chunks, _ := db.planBatchInsert("t", batchRows(7, same), batchInsertConfig{maxStatements: 100, maxBytes: one * 3})
+	if got := fmt.Sprint(chunkSizes(chunks)); got != "[3 3 1]" {
+		t.Errorf("byte threshold chunks = %s", got)
+	}
+	// 單一超大語句自成一批
+	chunks, _ = db.planBatchInsert("t", batchRows(2, same), batchInsertConfig{maxStatements: 100, maxBytes: 1})
+	if got := fmt.Sprint(chunkSizes(chunks)); got != "[1 1]" {
+		t.Errorf("oversized statement chunks = %s", got)
+	}
+	if first, last := chunks[1].rowRange(); first != 1 || last != 1 {
+		t.Errorf("rowRange = %d-%d", first, last)
+	}
+}
+
+func TestBatchInsertPartitionHint(t *testing.T) {
+	db := &CassandraDB{}
+	rows := batchRows(5, func(i int) interface{} { return fmt.Sprintf("dev-%d", i%2) })
+	chunks, err := db.planBatchInsert("events", rows, batchInsertConfig{maxStatements: 2, partitionKey: "device_id"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// dev-0: 0,2,4 → [2 1]；dev-1: 1,3 → [2]
+	if got := fmt.Sprint(chunkSizes(chunks)); got != "[2 1 2]" {
+		t.Fatalf("chunks = %s", got)
+	}
+	for _, ch := range chunks {
+		if ch.typ != gocql.UnloggedBatch {
+			t.Error("expected UnloggedBatch for same-partition chunks")
+		}
+		pk := ch.stmts[0].row % 2
+		for _, s := range ch.stmts {
+			if s.row%2 != pk {
+				t.Errorf("chunk mixes partitions: %v", chunkSizes(chunks))
+			}
+		}
+	}
+	if cql := chunks[0].stmts[0].cql; cql != `INSERT INTO events (device_id, id, payload) VALUES (?, ?, ?)` {
+		t.Errorf("unexpected CQL %q", cql)
+	}
+
+	rows[3] = map[string]interface{}{"id": 3}
+	if _, err := db.planBatchInsert("events", rows, batchInsertConfig{maxStatements: 2, partitionKey: "device_id"}); err == nil ||
+		!strings.Contains(err.Error(), "row 3") {
+		t.Errorf("expected missing partition key error, got %v", err)
+	}
+	if _, err := db.planBatchInsert("events", []map[string]interface{}{{}}, batchInsertConfig{maxStatements: 2}); err == nil {
+		t.Error("expected error for empty row")
+	}
+}
+
+func TestTableQueryCQL(t *testing.T) {
+	db := &CassandraDB{}
+	stmt, args, err := db.Table("app.users").PartitionKey("id").
+		Select("id", "email").Where("id", "=", 7).Limit(10).CQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stmt != `SELECT id, email FROM app.users WHERE id = ? LIMIT 10` || fmt.Sprint(args) != "[7]" {
+		t.Errorf("got %q %v", stmt, args)
+	}
+
+	events := db.Table("events").PartitionKey("device_id", "day").ClusteringKey("ts", "seq")
+	stmt, args, err = events.Select().
+		Where("device_id", "=", "d1").Where("day", "in", []string{"mon", "tue"}).
+		Where("ts", ">=", 100).OrderBy("ts", Desc).CQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `SELECT * FROM events WHERE device_id = ? AND day IN (?, ?) AND ts >= ? ORDER BY ts DESC`
+	if stmt != want || fmt.Sprint(args) != "[d1 mon tue 100]" {
+		t.Errorf("got %q %v", stmt, args)
+	}
+}
+
+func TestTableQueryValidation(t *testing.T) {
+	db := &CassandraDB{}
+	events := func() *TableBuilder {
+		return db.Table("events").PartitionKey("device_id", "day").ClusteringKey("ts", "seq").Indexed("status")
+	}
+	cases := []struct {
+		name  string
+		query *TableQuery
+		err   string
+	}{
+		{"full scan", events().Select(), ""},
+		{"index only", events().Select().Where("status", "=", "open"), ""},
+		{"clustering prefix", events().Select().Where("device_id", "=", 1).Where("day", "=", 2).Where("ts", "=", 3).Where("seq", ">", 4), ""},
+		{"undeclared column", db.Table("users").Select().Where("email", "=", "a@b"), `column "email" is not a declared`},
+		{"partial partition key", events().Select().Where("device_id", "=", 1), "full partition key"},
+		{"range on partition key", events().Select().Where("device_id", ">", 1).Where("day", "=", 2), "only supports = or IN"},
+		{"clustering gap", events().Select().Where("device_id", "=", 1).Where("day", "=", 2).Where("seq", "=", 4), "preceding clustering column"},
+		{"after range", events().Select().Where("device_id", "=", 1).Where("day", "=", 2).Where("ts", ">", 3).Where("seq", "=", 4), "preceding clustering column"},
+		{"range on index", events().Select().Where("status", ">", "a"), "indexed column"},
+		{"order without partition", events().Select().OrderBy("ts", Asc), "ORDER BY requires"},
+		{"order by regular", events().Select().Where("device_id", "=", 1).Where("day", "=", 2).OrderBy("status", Asc), "clustering column"},
+		{"bad operator", events().Select().Where("status", "LIKE", "a%"), "unsupported operator"},
+		{"empty IN", events().Select().Where("device_id", "IN", []int{}), "non-empty slice"},
+	}
+	for _, tc := range cases {
+		_, err := tc.query.Build()
+		if tc.err == "" && err != nil {
+			t.Errorf("%s: unexpected error %v", tc.name, err)
+		}
+		if tc.err != "" && (err == nil || !strings.Contains(err.Error(), tc.err)) {
+			t.Errorf("%s: error = %v, want %q", tc.name, err, tc.err)
+		}
+	}
+
+	stmt, _, err := db.Table("users").Select().Where("email", "=", "a@b").AllowFiltering().CQL()
+	if err != nil || !strings.HasSuffix(stmt, "ALLOW FILTERING") {
+		t.Errorf("AllowFiltering should bypass validation: %q %v", stmt, err)
+	}
+}
+
+func TestTableQueryFromModel(t *testing.T) {
+	type ticket struct {
+		ID     int    `cql:"id,pk"`
+		Status string `cql:"status,index"`
+		Note   string `cql:"note"`
+	}
+	tb, err := (&CassandraDB{}).TableFromModel(&ticket{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tb.Select().Where("status", "=", "open").Build(); err != nil {
+		t.Errorf("indexed tag not honoured: %v", err)
+	}
+	if _, err := tb.Select().Where("note", "=", "x").Build(); err == nil {
+		t.Error("expected error for unindexed column")
+	}
+}
+
+// slowQuery simulates a query that never answers until its context ends.
+func slowQuery(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestOperationTimeoutDefault(t *testing.T) {
+	c := &CassandraDB{config: Config{OperationTimeout: 20 * time.Millisecond}}
+
+	start := time.Now()
+	err := c.run(context.Background(), "select", slowQuery)
+	var te *TimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected *TimeoutError, got %v", err)
+	}
+	if te.Op != "select" || te.After != 20*time.Millisecond || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("unexpected timeout error: %+v", te)
+	}
+	if !IsTimeout(err) {
+		t.Error("IsTimeout should recognise the default timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("default timeout not applied, took %v", elapsed)
+	}
+}
+
+func TestOperationTimeoutCallerDeadlineWins(t *testing.T) {
+	c := &CassandraDB{config: Config{OperationTimeout: time.Hour}}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := c.run(ctx, "select", func(ctx context.Context) error {
+		if d, _ := ctx.Deadline(); time.Until(d) > time.Second {
+			t.Error("caller deadline was replaced by the default timeout")
+		}
+		return slowQuery(ctx)
+	})
+	var te *TimeoutError
+	if errors.As(err, &te) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline error unwrapped, got %v", err)
+	}
+	if !IsTimeout(err) {
+		t.Error("IsTimeout should recognise a caller deadline")
+	}
+}
+
+func TestOperationTimeoutDisabled(t *testing.T) {
+	c := &CassandraDB{config: Config{OperationTimeout: -1}}
+	err := c.run(context.Background(), "exec", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); ok {
+			t.Error("negative OperationTimeout should not add a deadline")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := (&CassandraDB{}).operationTimeout(); got != DefaultOperationTimeout {
+		t.Errorf("default operation timeout = %v", got)
+	}
+}
+
+func TestIsTimeout(t *testing.T) {
+	for _, err := range []error{
+		gocql.ErrTimeoutNoResponse,
+		&gocql.RequestErrReadTimeout{},
+		fmt.Errorf("wrapped: %w", &gocql.RequestErrWriteTimeout{}),
+	} {
+		if !IsTimeout(err) {
+			t.Errorf("IsTimeout(%v) = false", err)
+		}
+	}
+	if IsTimeout(nil) || IsTimeout(gocql.ErrNotFound) || IsTimeout(context.Canceled) {
+		t.Error("IsTimeout reported a non-timeout error")
+	}
+}
+
+func TestSelectInPlanChunksAndDedupes(t *testing.T) {
+	keys := []interface{}{1, 2, 3, 2, 4, 5, 1, 6, 7}
+	groups := planSelectIn(keys, 3)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 groups, got %v", groups)
+	}
+	if fmt.Sprint(groups) != "[[1 2 3] [4 5 6] [7]]" {
+		t.Errorf("unexpected groups %v", groups)
+	}
+	if groups := planSelectIn(keys, 1); len(groups) != 7 || groups[6][0] != 7 {
+		t.Errorf("expected one key per group, got %v", groups)
+	}
+	if groups := planSelectIn(nil, 5); len(groups) != 0 {
+		t.Errorf("expected no groups for no keys, got %v", groups)
+	}
+}
+
+func TestSelectInMerge(t *testing.T) {
+	groups := [][]interface{}{{3, 1, 2}, {9}}
+	// Cassandra returns IN results in token order, not in key order
+	results := [][]map[string]interface{}{
+		{
+			{"id": int64(1), "v": "a"},
+			{"id": int64(2), "v": "b"},
+			{"id": int64(3), "v": "c1"},
+			{"id": int64(3), "v": "c2"},
+		},
+		{{"id": int64(9), "v": "z"}},
+	}
+	values := func(rows []map[string]interface{}) string {
+		out := make([]string, len(rows))
+		for i, r := range rows {
+			out[i] = fmt.Sprint(r["v"])
+		}
+		return strings.Join(out, ",")
+	}
+
+	if got := values(mergeSelectIn(groups, results, "id", false, false)); got != "a,b,c1,c2,z" {
+		t.Errorf("unordered merge = %s", got)
+	}
+	merged := mergeSelectIn(groups, results, "id", true, true)
+	if got := values(merged); got != "c1,c2,a,b,z" {
+		t.Errorf("ordered merge = %s", got)
+	}
+	if _, ok := merged[0]["id"]; ok {
+		t.Error("expected the added key column to be dropped")
+	}
+}
+
+func TestSelectInColumnsAndKeyCheck(t *testing.T) {
+	if cols, drop := selectInColumns([]string{"email"}, "id", true); !drop || strings.Join(cols, ",") != "email,id" {
+		t.Errorf("expected key column appended, got %v %v", cols, drop)
+	}
+	if cols, drop := selectInColumns([]string{"id", "email"}, "id", true); drop || len(cols) != 2 {
+		t.Errorf("key already selected, got %v %v", cols, drop)
+	}
+	if _, drop := selectInColumns([]string{"email"}, "id", false); drop {
+		t.Error("key column not needed without ordering")
+	}
+
+	if err := checkSelectInKey("users", "id", []string{"id"}); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := checkSelectInKey("users", "email", []string{"id"}); err == nil || !strings.Contains(err.Error(), "ALLOW FILTERING") {
+		t.Errorf("expected filtering error, got %v", err)
+	}
+	if err := checkSelectInKey("events", "device_id", []string{"device_id", "day"}); err == nil || !strings.Contains(err.Error(), "composite") {
+		t.Errorf("expected composite key error, got %v", err)
+	}
+
+	db := &CassandraDB{}
+	if _, err := db.SelectIn(context.Background(), "users", nil, "id", []interface{}{1}); err == nil {
+		t.Error("expected error without a session")
+	}
+}
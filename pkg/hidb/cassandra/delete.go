@@ -123,21 +123,28 @@ func (d *DeleteBuilder) CQL() (string, []interface{}) {
 // Exec runs the DELETE.
 func (d *DeleteBuilder) Exec(ctx context.Context) error {
 	stmt, args := d.CQL()
-	q := d.db.session.Query(stmt, args...).WithContext(ctx)
+	q := d.db.session.Query(stmt, args...)
 	if d.consistency != nil {
 		q = q.Consistency(*d.consistency)
 	}
-	return q.Exec()
+	return d.db.run(ctx, "delete", func(ctx context.Context) error {
+		return q.WithContext(ctx).Exec()
+	})
 }
 
 // ExecCAS executes as lightweight transaction.
 func (d *DeleteBuilder) ExecCAS(ctx context.Context, dest ...interface{}) (bool, error) {
 	stmt, args := d.CQL()
-	q := d.db.session.Query(stmt, args...).WithContext(ctx)
+	q := d.db.session.Query(stmt, args...)
 	if d.consistency != nil {
 		q = q.Consistency(*d.consistency)
 	}
-	return q.ScanCAS(dest...)
+	var applied bool
+	err := d.db.run(ctx, "delete", func(ctx context.Context) (err error) {
+		applied, err = q.WithContext(ctx).ScanCAS(dest...)
+		return err
+	})
+	return applied, err
 }
 
 // DeleteModel deletes the row identified by the model's primary key.
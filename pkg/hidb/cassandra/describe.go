@@ -84,6 +84,8 @@ func (c *CassandraDB) DescribeKeyspaces(ctx context.Context) ([]KeyspaceInfo, er
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, replication, durable_writes FROM system_schema.keyspaces`,
 	).WithContext(ctx).Iter()
@@ -110,6 +112,8 @@ func (c *CassandraDB) DescribeKeyspace(ctx context.Context, ks string) (*Keyspac
 	var name string
 	var repl map[string]string
 	var durable bool
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	err := c.session.Query(
 		`SELECT keyspace_name, replication, durable_writes FROM system_schema.keyspaces WHERE keyspace_name = ?`,
 		ks,
@@ -132,6 +136,8 @@ func (c *CassandraDB) DescribeTables(ctx context.Context, keyspace string) ([]Ta
 	if ks == "" {
 		return nil, fmt.Errorf("cassandra: keyspace required")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, comment FROM system_schema.tables WHERE keyspace_name = ?`,
 		ks,
@@ -161,6 +167,8 @@ func (c *CassandraDB) DescribeColumns(ctx context.Context, keyspace, table strin
 	if ks == "" || table == "" {
 		return nil, fmt.Errorf("cassandra: keyspace and table are required")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, column_name, kind, position, type, clustering_order
 		 FROM system_schema.columns WHERE keyspace_name = ? AND table_name = ?`,
@@ -193,6 +201,8 @@ func (c *CassandraDB) DescribeTable(ctx context.Context, keyspace, table string)
 		return nil, nil, fmt.Errorf("cassandra: keyspace and table are required")
 	}
 	var kn, tn, comment string
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	err := c.session.Query(
 		`SELECT keyspace_name, table_name, comment FROM system_schema.tables
 		 WHERE keyspace_name = ? AND table_name = ?`,
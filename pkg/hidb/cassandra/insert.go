@@ -103,21 +103,28 @@ func (i *InsertBuilder) CQL() (string, []interface{}) {
 // Exec runs the INSERT.
 func (i *InsertBuilder) Exec(ctx context.Context) error {
 	stmt, args := i.CQL()
-	q := i.db.session.Query(stmt, args...).WithContext(ctx)
+	q := i.db.session.Query(stmt, args...)
 	if i.consistency != nil {
 		q = q.Consistency(*i.consistency)
 	}
-	return q.Exec()
+	return i.db.run(ctx, "insert", func(ctx context.Context) error {
+		return q.WithContext(ctx).Exec()
+	})
 }
 
 // ExecCAS executes a lightweight transaction and returns applied + existing row.
 func (i *InsertBuilder) ExecCAS(ctx context.Context, dest ...interface{}) (bool, error) {
 	stmt, args := i.CQL()
-	q := i.db.session.Query(stmt, args...).WithContext(ctx)
+	q := i.db.session.Query(stmt, args...)
 	if i.consistency != nil {
 		q = q.Consistency(*i.consistency)
 	}
-	return q.ScanCAS(dest...)
+	var applied bool
+	err := i.db.run(ctx, "insert", func(ctx context.Context) (err error) {
+		applied, err = q.WithContext(ctx).ScanCAS(dest...)
+		return err
+	})
+	return applied, err
 }
 
 // Save inserts a model struct. Zero-valued fields tagged with omitempty are
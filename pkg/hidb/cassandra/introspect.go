@@ -277,6 +277,8 @@ func (s *Schema) MarshalJSON() ([]byte, error) {
 
 // keyspaces
 func (c *CassandraDB) introspectKeyspaces(ctx context.Context, opts IntrospectOptions) ([]KeyspaceSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, replication, durable_writes FROM system_schema.keyspaces`,
 	).WithContext(ctx).Iter()
@@ -300,6 +302,8 @@ func (c *CassandraDB) introspectKeyspaces(ctx context.Context, opts IntrospectOp
 
 // tables
 func (c *CassandraDB) introspectTables(ctx context.Context, opts IntrospectOptions) ([]TableSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, comment, flags, id FROM system_schema.tables`,
 	).WithContext(ctx).Iter()
@@ -330,6 +334,8 @@ type columnRow struct {
 }
 
 func (c *CassandraDB) introspectColumns(ctx context.Context, opts IntrospectOptions) ([]columnRow, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, column_name, kind, position, type, clustering_order
 		 FROM system_schema.columns`,
@@ -359,6 +365,8 @@ func (c *CassandraDB) introspectColumns(ctx context.Context, opts IntrospectOpti
 
 // types
 func (c *CassandraDB) introspectTypes(ctx context.Context, opts IntrospectOptions) ([]TypeSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, type_name, field_names, field_types FROM system_schema.types`,
 	).WithContext(ctx).Iter()
@@ -385,6 +393,8 @@ func (c *CassandraDB) introspectTypes(ctx context.Context, opts IntrospectOption
 
 // views
 func (c *CassandraDB) introspectViews(ctx context.Context, opts IntrospectOptions) ([]ViewSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, view_name, base_table_name, include_all_columns, where_clause
 		 FROM system_schema.views`,
@@ -411,6 +421,8 @@ func (c *CassandraDB) introspectViews(ctx context.Context, opts IntrospectOption
 
 // indexes
 func (c *CassandraDB) introspectIndexes(ctx context.Context, opts IntrospectOptions) ([]IndexSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, index_name, kind, options FROM system_schema.indexes`,
 	).WithContext(ctx).Iter()
@@ -436,6 +448,8 @@ func (c *CassandraDB) introspectIndexes(ctx context.Context, opts IntrospectOpti
 
 // functions
 func (c *CassandraDB) introspectFunctions(ctx context.Context, opts IntrospectOptions) ([]FunctionSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, function_name, argument_names, argument_types, return_type,
 		        language, body, called_on_null_input FROM system_schema.functions`,
@@ -466,6 +480,8 @@ func (c *CassandraDB) introspectFunctions(ctx context.Context, opts IntrospectOp
 
 // aggregates
 func (c *CassandraDB) introspectAggregates(ctx context.Context, opts IntrospectOptions) ([]AggregateSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, aggregate_name, argument_types, state_func, state_type,
 		        final_func, initcond, return_type FROM system_schema.aggregates`,
@@ -494,6 +510,8 @@ func (c *CassandraDB) introspectAggregates(ctx context.Context, opts IntrospectO
 
 // triggers
 func (c *CassandraDB) introspectTriggers(ctx context.Context, opts IntrospectOptions) ([]TriggerSchema, error) {
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, trigger_name, options FROM system_schema.triggers`,
 	).WithContext(ctx).Iter()
@@ -67,6 +67,8 @@ func (m *Migrator) Init(ctx context.Context) error {
 // applied returns the set of already-applied versions.
 func (m *Migrator) applied(ctx context.Context) (map[int64]bool, error) {
 	stmt := fmt.Sprintf("SELECT version FROM %s", m.trackingRef())
+	ctx, cancel := m.db.operationContext(ctx)
+	defer cancel()
 	iter := m.db.session.Query(stmt).WithContext(ctx).Iter()
 	defer iter.Close()
 	var v int64
@@ -107,7 +109,10 @@ func (m *Migrator) Up(ctx context.Context) error {
 			return fmt.Errorf("cassandra: migration %d (%s) failed: %w", mig.Version, mig.Name, err)
 		}
 		stmt := fmt.Sprintf("INSERT INTO %s (version, name, applied_at) VALUES (?, ?, ?)", m.trackingRef())
-		if err := m.db.session.Query(stmt, mig.Version, mig.Name, time.Now()).WithContext(ctx).Exec(); err != nil {
+		err := m.db.run(ctx, "migration", func(ctx context.Context) error {
+			return m.db.session.Query(stmt, mig.Version, mig.Name, time.Now()).WithContext(ctx).Exec()
+		})
+		if err != nil {
 			return err
 		}
 	}
@@ -152,7 +157,9 @@ func (m *Migrator) Down(ctx context.Context) error {
 		return err
 	}
 	stmt := fmt.Sprintf("DELETE FROM %s WHERE version = ?", m.trackingRef())
-	return m.db.session.Query(stmt, latest).WithContext(ctx).Exec()
+	return m.db.run(ctx, "migration", func(ctx context.Context) error {
+		return m.db.session.Query(stmt, latest).WithContext(ctx).Exec()
+	})
 }
 
 // Status returns one line per migration (applied or pending).
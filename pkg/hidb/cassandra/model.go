@@ -33,6 +33,7 @@ type ModelField struct {
 	OmitEmpty bool
 	Counter   bool
 	IsStatic  bool
+	Indexed   bool // covered by a secondary index / SAI (tag flag "index")
 }
 
 // ModelInfo is the parsed schema of a struct.
@@ -202,6 +203,8 @@ func parseFieldTag(f reflect.StructField, tag string) (ModelField, error) {
 			field.Counter = true
 		case "omitempty":
 			field.OmitEmpty = true
+		case "index", "indexed":
+			field.Indexed = true
 		default:
 			return field, fmt.Errorf("unknown cql tag flag %q", p)
 		}
@@ -49,6 +49,8 @@ func (c *CassandraDB) LocalInfo(ctx context.Context) (*NodetoolInfo, error) {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
 	var info NodetoolInfo
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	err := c.session.Query(
 		`SELECT host_id, cluster_name, data_center, rack, release_version,
 		        cql_version, partitioner, broadcast_address, listen_address,
@@ -70,6 +72,8 @@ func (c *CassandraDB) Peers(ctx context.Context) ([]PeerInfo, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT peer, peer_port, data_center, rack, host_id, release_version, schema_version, tokens
 		 FROM system.peers_v2`,
@@ -120,6 +124,8 @@ func (c *CassandraDB) ThreadPools(ctx context.Context) ([]ThreadPoolStat, error)
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT name, active_tasks, pending_tasks, completed_tasks,
 		        blocked_tasks, total_blocked_tasks, max_pool_size, active_tasks_limit
@@ -155,6 +161,8 @@ func (c *CassandraDB) CompactionTasks(ctx context.Context) ([]SSTableTask, error
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, task_id, kind, progress, total, unit
 		 FROM system_views.sstable_tasks`,
@@ -193,6 +201,8 @@ func (c *CassandraDB) Clients(ctx context.Context) ([]ClientConnection, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT address, port, hostname, username, connection_stage, driver_name,
 		        driver_version, protocol_version, ssl_enabled, ssl_protocol,
@@ -225,6 +235,8 @@ func (c *CassandraDB) Settings(ctx context.Context) ([]Setting, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT name, value FROM system_views.settings`,
 	).WithContext(ctx).Iter()
@@ -247,6 +259,8 @@ func (c *CassandraDB) Setting(ctx context.Context, name string) (string, error)
 		return "", fmt.Errorf("cassandra: session not connected")
 	}
 	var v string
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	err := c.session.Query(
 		`SELECT value FROM system_views.settings WHERE name = ?`, name,
 	).WithContext(ctx).Scan(&v)
@@ -270,6 +284,8 @@ func (c *CassandraDB) Caches(ctx context.Context) ([]CacheStat, error) {
 	if c.session == nil {
 		return nil, fmt.Errorf("cassandra: session not connected")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT name, capacity_bytes, entry_count, size_bytes, hit_ratio,
 		        hit_count, request_count, recent_hit_rate_per_second
@@ -312,6 +328,8 @@ func (c *CassandraDB) TableSizeEstimates(ctx context.Context, keyspace, table st
 	if ks == "" || table == "" {
 		return nil, fmt.Errorf("cassandra: keyspace and table are required")
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(
 		`SELECT keyspace_name, table_name, range_start, range_end,
 		        mean_partition_size, partitions_count
@@ -379,6 +397,8 @@ func (c *CassandraDB) RawSystemViewRow(ctx context.Context, table string, where
 			args = append(args, v)
 		}
 	}
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
 	iter := c.session.Query(stmt, args...).WithContext(ctx).Iter()
 	defer iter.Close()
 
@@ -204,6 +204,9 @@ func formatVectorLiteral(v []float32) string {
 // query returns a gocql.Query configured with the builder state.
 func (s *SelectBuilder) query(ctx context.Context) *gocql.Query {
 	stmt, args := s.CQL()
+	if ctx == nil {
+		ctx = context.Background()
+	}
 	q := s.db.session.Query(stmt, args...).WithContext(ctx)
 	if s.consistency != nil {
 		q = q.Consistency(*s.consistency)
@@ -217,7 +220,8 @@ func (s *SelectBuilder) query(ctx context.Context) *gocql.Query {
 	return q
 }
 
-// Iter returns a gocql.Iter for manual row consumption.
+// Iter returns a gocql.Iter for manual row consumption. The default operation
+// timeout does not apply: a long scan is bounded only by ctx.
 func (s *SelectBuilder) Iter(ctx context.Context) *gocql.Iter {
 	return s.query(ctx).Iter()
 }
@@ -243,6 +247,13 @@ func (s *SelectBuilder) All(ctx context.Context, dest interface{}) error {
 	if err != nil {
 		return err
 	}
+	return s.db.run(ctx, "select", func(ctx context.Context) error {
+		return s.scanAll(ctx, slice, structType, isPtr, info)
+	})
+}
+
+// scanAll appends every row of the query to slice.
+func (s *SelectBuilder) scanAll(ctx context.Context, slice reflect.Value, structType reflect.Type, isPtr bool, info *ModelInfo) error {
 	iter := s.Iter(ctx)
 	columns := iter.Columns()
 	defer iter.Close()
@@ -291,6 +302,13 @@ func (s *SelectBuilder) One(ctx context.Context, dest interface{}) error {
 		return err
 	}
 	s.Limit(1)
+	return s.db.run(ctx, "select", func(ctx context.Context) error {
+		return s.scanOne(ctx, elem, info)
+	})
+}
+
+// scanOne scans the first row of the query into elem.
+func (s *SelectBuilder) scanOne(ctx context.Context, elem reflect.Value, info *ModelInfo) error {
 	iter := s.Iter(ctx)
 	defer iter.Close()
 	columns := iter.Columns()
@@ -322,7 +340,9 @@ func (s *SelectBuilder) Count(ctx context.Context) (int64, error) {
 	clone.limit = 0
 	stmt, args := clone.CQL()
 	var n int64
-	err := s.db.session.Query(stmt, args...).WithContext(ctx).Scan(&n)
+	err := s.db.run(ctx, "select", func(ctx context.Context) error {
+		return s.db.session.Query(stmt, args...).WithContext(ctx).Scan(&n)
+	})
 	return n, err
 }
 
@@ -334,3 +354,232 @@ func (s *SelectBuilder) PageStateOut(iter *gocql.Iter) []byte {
 	}
 	return iter.PageState()
 }
+
+// TableQuery is a SELECT builder bound to a declared table schema. Unlike
+// SelectBuilder it takes typed column/operator/value predicates and refuses,
+// at Build time, restrictions that Cassandra could only serve with ALLOW
+// FILTERING: every partition key column must be restricted with = or IN,
+// clustering columns must be restricted in declaration order, and other
+// columns need an index declared with TableBuilder.Indexed.
+//
+// Example:
+//
+//	q := db.Table("users").PartitionKey("id").
+//	    Select("id", "email").Where("id", "=", id).Limit(10)
+//	sel, err := q.Build() // *SelectBuilder, ready for All / One / Iter
+type TableQuery struct {
+	table          *TableBuilder
+	columns        []string
+	conds          []queryCond
+	orderBy        []Column
+	limit          int
+	allowFiltering bool
+	err            error
+}
+
+type queryCond struct {
+	column string
+	op     string
+	args   []interface{}
+}
+
+// queryOps lists the supported relation operators.
+var queryOps = map[string]bool{
+	"=": true, "<": true, ">": true, "<=": true, ">=": true,
+	"IN": true, "CONTAINS": true, "CONTAINS KEY": true,
+}
+
+// Indexed declares columns covered by a secondary index or SAI so that
+// TableQuery may restrict them. It does not create the index; use Index().
+func (t *TableBuilder) Indexed(columns ...string) *TableBuilder {
+	if t.indexed == nil {
+		t.indexed = make(map[string]bool, len(columns))
+	}
+	for _, col := range columns {
+		t.indexed[col] = true
+	}
+	return t
+}
+
+// Select starts a validated SELECT against this table's declared keys.
+// No columns selects *.
+func (t *TableBuilder) Select(columns ...string) *TableQuery {
+	if len(columns) == 0 {
+		columns = []string{"*"}
+	}
+	return &TableQuery{table: t, columns: columns}
+}
+
+// Where adds "column op ?". op is one of =, <, >, <=, >=, IN, CONTAINS or
+// CONTAINS KEY; for IN, value must be a slice whose elements are bound
+// individually.
+func (q *TableQuery) Where(column, op string, value interface{}) *TableQuery {
+	op = strings.ToUpper(strings.Join(strings.Fields(op), " "))
+	if !queryOps[op] {
+		q.fail(fmt.Errorf("cassandra: unsupported operator %q on %q", op, column))
+		return q
+	}
+	args := []interface{}{value}
+	if op == "IN" {
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array || rv.Len() == 0 {
+			q.fail(fmt.Errorf("cassandra: IN on %q needs a non-empty slice", column))
+			return q
+		}
+		args = make([]interface{}, rv.Len())
+		for i := range args {
+			args[i] = rv.Index(i).Interface()
+		}
+	}
+	q.conds = append(q.conds, queryCond{column: column, op: op, args: args})
+	return q
+}
+
+// OrderBy orders by a clustering column.
+func (q *TableQuery) OrderBy(column string, order ClusteringOrder) *TableQuery {
+	q.orderBy = append(q.orderBy, Column{Name: column, Order: order})
+	return q
+}
+
+// Limit sets the LIMIT clause.
+func (q *TableQuery) Limit(n int) *TableQuery { q.limit = n; return q }
+
+// AllowFiltering skips the data-model validation and appends ALLOW FILTERING.
+// The query may scan every partition; prefer a table or index that matches it.
+func (q *TableQuery) AllowFiltering() *TableQuery { q.allowFiltering = true; return q }
+
+func (q *TableQuery) fail(err error) {
+	if q.err == nil {
+		q.err = err
+	}
+}
+
+// Build validates the query and returns the equivalent SelectBuilder.
+func (q *TableQuery) Build() (*SelectBuilder, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	if !q.allowFiltering {
+		if err := q.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	t := q.table
+	s := &SelectBuilder{db: t.db, keyspace: t.keyspace, table: t.name, columns: q.columns, limit: q.limit}
+	for _, c := range q.conds {
+		expr := quoteIdent(c.column) + " " + c.op + " ?"
+		if c.op == "IN" {
+			expr = fmt.Sprintf("%s IN (%s)", quoteIdent(c.column), strings.TrimSuffix(strings.Repeat("?, ", len(c.args)), ", "))
+		}
+		s.Where(expr, c.args...)
+	}
+	s.orderBy = append(s.orderBy, q.orderBy...)
+	s.allowFiltering = q.allowFiltering
+	return s, nil
+}
+
+// CQL validates the query and renders it with its bind arguments.
+func (q *TableQuery) CQL() (string, []interface{}, error) {
+	s, err := q.Build()
+	if err != nil {
+		return "", nil, err
+	}
+	stmt, args := s.CQL()
+	return stmt, args, nil
+}
+
+// validate applies Cassandra's restriction rules for queries without
+// ALLOW FILTERING against the declared primary key and indexes.
+func (q *TableQuery) validate() error {
+	pk := q.table.primaryKey
+	byCol := make(map[string][]queryCond, len(q.conds))
+	for _, c := range q.conds {
+		byCol[c.column] = append(byCol[c.column], c)
+	}
+	for col, conds := range byCol {
+		if !q.table.indexed[col] || isKeyColumn(pk, col) {
+			continue
+		}
+		for _, c := range conds {
+			if c.op != "=" && c.op != "CONTAINS" && c.op != "CONTAINS KEY" {
+				return fmt.Errorf("cassandra: indexed column %q only supports =, CONTAINS or CONTAINS KEY without ALLOW FILTERING", col)
+			}
+		}
+	}
+
+	partitionRestricted := len(pk.Partition) > 0
+	for _, col := range pk.Partition {
+		conds, ok := byCol[col]
+		if !ok {
+			partitionRestricted = false
+			continue
+		}
+		for _, c := range conds {
+			if c.op != "=" && c.op != "IN" {
+				return fmt.Errorf("cassandra: partition key %q only supports = or IN", col)
+			}
+		}
+	}
+	if !partitionRestricted {
+		for col := range byCol {
+			if isKeyColumn(pk, col) {
+				return fmt.Errorf("cassandra: query restricts %q but not the full partition key (%s); add the missing columns or call AllowFiltering",
+					col, strings.Join(pk.Partition, ", "))
+			}
+		}
+	}
+
+	// clustering 欄位必須依宣告順序限制；範圍條件之後不能再有限制
+	if partitionRestricted {
+		prefixOpen := true
+		for _, col := range pk.Clustering {
+			conds, ok := byCol[col]
+			if !ok {
+				prefixOpen = false
+				continue
+			}
+			if !prefixOpen {
+				return fmt.Errorf("cassandra: clustering column %q is restricted but a preceding clustering column is not", col)
+			}
+			for _, c := range conds {
+				switch c.op {
+				case "=", "IN":
+				case "<", ">", "<=", ">=":
+					prefixOpen = false
+				default:
+					return fmt.Errorf("cassandra: clustering column %q does not support %s", col, c.op)
+				}
+			}
+		}
+	}
+
+	for col := range byCol {
+		if !isKeyColumn(pk, col) && !q.table.indexed[col] {
+			return fmt.Errorf("cassandra: column %q is not a declared partition key, clustering key or indexed column; declare it with PartitionKey/ClusteringKey/Indexed or call AllowFiltering", col)
+		}
+	}
+
+	for _, o := range q.orderBy {
+		if !contains(pk.Clustering, o.Name) {
+			return fmt.Errorf("cassandra: ORDER BY %q requires a clustering column", o.Name)
+		}
+		if !partitionRestricted {
+			return fmt.Errorf("cassandra: ORDER BY requires the partition key to be restricted with = or IN")
+		}
+	}
+	return nil
+}
+
+func isKeyColumn(pk PrimaryKey, col string) bool {
+	return contains(pk.Partition, col) || contains(pk.Clustering, col)
+}
+
+func contains(xs []string, s string) bool {
+	for _, x := range xs {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
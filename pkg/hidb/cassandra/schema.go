@@ -24,6 +24,9 @@ func (c *CassandraDB) TableFromModel(model interface{}, opts ...TableOption) (*T
 		default:
 			t.Column(f.Name, f.Type)
 		}
+		if f.Indexed {
+			t.Indexed(f.Name)
+		}
 	}
 	t.PartitionKey(info.PartitionKey...)
 	t.ClusteringKey(info.Clustering...)
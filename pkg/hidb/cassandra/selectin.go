@@ -0,0 +1,278 @@
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SelectIn defaults. A group size of 1 issues pure single-partition reads,
+// which the token-aware policy routes straight to a replica.
+const (
+	DefaultSelectInGroupSize   = 1
+	DefaultSelectInConcurrency = 8
+)
+
+// SelectInOption customises SelectIn.
+type SelectInOption func(*selectInConfig)
+
+type selectInConfig struct {
+	groupSize     int
+	concurrency   int
+	preserveOrder bool
+}
+
+// SelectInGroupSize sends up to n keys per query as a small IN (default 1).
+// Keep it small: every key in an IN is another partition the coordinator
+// has to fetch from its replicas.
+func SelectInGroupSize(n int) SelectInOption {
+	return func(c *selectInConfig) { c.groupSize = n }
+}
+
+// SelectInConcurrency runs up to n queries at the same time (default 8).
+func SelectInConcurrency(n int) SelectInOption {
+	return func(c *selectInConfig) { c.concurrency = n }
+}
+
+// SelectInPreserveOrder returns rows in the order of keys. Without it rows of
+// one group come back in Cassandra's token order; groups are always merged in
+// key order. With a group size above 1 the key column is read to sort rows
+// and dropped again if it was not requested.
+func SelectInPreserveOrder() SelectInOption {
+	return func(c *selectInConfig) { c.preserveOrder = true }
+}
+
+// SelectIn fetches the rows whose partition key keyColumn is one of keys,
+// without sending one large IN (...) query.
+//
+// A large IN on the partition key makes a single coordinator fan out to the
+// replicas of every key, hold all of their rows in memory until the slowest
+// replica answers, and bypass token-aware routing, since the query has no
+// single routing key. SelectIn instead splits the keys into groups (one key
+// per query by default) and runs them concurrently with a bounded number in
+// flight: each query goes directly to a replica that owns the partition, the
+// load spreads across the cluster, and one slow partition only delays its
+// own group. Duplicate keys are queried once.
+//
+// keyColumn must be the table's whole partition key, checked against the
+// session's schema metadata, so no query ever needs ALLOW FILTERING. The
+// first failing group cancels the rest and its error is returned.
+//
+// Example:
+//
+//	rows, err := db.SelectIn(ctx, "app.users", []string{"id", "email"}, "id", ids,
+//	    cassandra.SelectInConcurrency(16),
+//	    cassandra.SelectInPreserveOrder())
+func (c *CassandraDB) SelectIn(ctx context.Context, table string, columns []string, keyColumn string, keys []interface{}, opts ...SelectInOption) ([]map[string]interface{}, error) {
+	cfg := selectInConfig{
+		groupSize:   DefaultSelectInGroupSize,
+		concurrency: DefaultSelectInConcurrency,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.groupSize <= 0 {
+		cfg.groupSize = DefaultSelectInGroupSize
+	}
+	if cfg.concurrency <= 0 {
+		cfg.concurrency = DefaultSelectInConcurrency
+	}
+
+	if keyColumn == "" {
+		return nil, fmt.Errorf("cassandra: SelectIn on %s needs a key column", table)
+	}
+	if c.session == nil {
+		return nil, fmt.Errorf("cassandra: session not connected")
+	}
+	partitionKey, err := c.partitionKeyOf(table)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkSelectInKey(table, keyColumn, partitionKey); err != nil {
+		return nil, err
+	}
+
+	groups := planSelectIn(keys, cfg.groupSize)
+	if len(groups) == 0 {
+		return nil, nil
+	}
+	queryColumns, dropKey := selectInColumns(columns, keyColumn, cfg.preserveOrder && cfg.groupSize > 1)
+
+	results := make([][]map[string]interface{}, len(groups))
+	err = c.run(ctx, "select", func(ctx context.Context) error {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		errs := make([]error, len(groups))
+		sem := make(chan struct{}, cfg.concurrency)
+		var wg sync.WaitGroup
+		for i, group := range groups {
+			sem <- struct{}{}
+			if ctx.Err() != nil {
+				<-sem
+				break
+			}
+			wg.Add(1)
+			go func(i int, group []interface{}) {
+				defer func() { <-sem; wg.Done() }()
+				rows, err := c.selectGroup(ctx, table, queryColumns, keyColumn, group)
+				if err != nil {
+					errs[i] = fmt.Errorf("cassandra: select in %s group %d: %w", table, i, err)
+					cancel()
+					return
+				}
+				results[i] = rows
+			}(i, group)
+		}
+		wg.Wait()
+		// Groups cancelled after the first failure only report context.Canceled;
+		// return the failure that caused it.
+		for _, err := range errs {
+			if err != nil && !errors.Is(err, context.Canceled) {
+				return err
+			}
+		}
+		return errors.Join(errs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return mergeSelectIn(groups, results, keyColumn, cfg.preserveOrder, dropKey), nil
+}
+
+// selectGroup runs one point query (or small IN) and collects its rows.
+func (c *CassandraDB) selectGroup(ctx context.Context, table string, columns []string, keyColumn string, group []interface{}) ([]map[string]interface{}, error) {
+	sel := c.Select(table, columns...)
+	if len(group) == 1 {
+		sel.WhereEq(keyColumn, group[0])
+	} else {
+		sel.WhereIn(keyColumn, group...)
+	}
+	iter := sel.Iter(ctx)
+	var rows []map[string]interface{}
+	for {
+		row := make(map[string]interface{})
+		if !iter.MapScan(row) {
+			break
+		}
+		rows = append(rows, row)
+	}
+	return rows, iter.Close()
+}
+
+// partitionKeyOf returns the partition key columns of table from the
+// session's schema metadata; an unqualified table uses the session keyspace.
+func (c *CassandraDB) partitionKeyOf(table string) ([]string, error) {
+	ks, t := splitQualified(table)
+	if ks == "" {
+		ks = c.KeyspaceName()
+	}
+	meta, err := c.session.KeyspaceMetadata(ks)
+	if err != nil {
+		return nil, fmt.Errorf("cassandra: read metadata of keyspace %q: %w", ks, err)
+	}
+	tm, ok := meta.Tables[t]
+	if !ok {
+		return nil, fmt.Errorf("cassandra: table %s.%s not found", ks, t)
+	}
+	cols := make([]string, len(tm.PartitionKey))
+	for i, col := range tm.PartitionKey {
+		cols[i] = col.Name
+	}
+	return cols, nil
+}
+
+// checkSelectInKey refuses key columns that Cassandra could only look up
+// with ALLOW FILTERING: the key column must be the whole partition key.
+func checkSelectInKey(table, keyColumn string, partitionKey []string) error {
+	switch {
+	case len(partitionKey) == 1 && partitionKey[0] == keyColumn:
+		return nil
+	case len(partitionKey) > 1 && contains(partitionKey, keyColumn):
+		return fmt.Errorf("cassandra: SelectIn on %s: %q is one column of the composite partition key (%s); every component is needed to locate a partition",
+			table, keyColumn, strings.Join(partitionKey, ", "))
+	default:
+		return fmt.Errorf("cassandra: SelectIn on %s: %q is not the partition key (%s); looking it up would need ALLOW FILTERING",
+			table, keyColumn, strings.Join(partitionKey, ", "))
+	}
+}
+
+// planSelectIn drops duplicate keys (first occurrence wins) and splits the
+// rest into consecutive groups of at most size keys.
+func planSelectIn(keys []interface{}, size int) [][]interface{} {
+	seen := make(map[string]bool, len(keys))
+	unique := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		id := keyID(k)
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, k)
+	}
+
+	var groups [][]interface{}
+	for start := 0; start < len(unique); start += size {
+		end := start + size
+		if end > len(unique) {
+			end = len(unique)
+		}
+		groups = append(groups, unique[start:end])
+	}
+	return groups
+}
+
+// selectInColumns adds the key column to the query when rows have to be
+// sorted by it; dropKey reports that it must be removed from the results.
+func selectInColumns(columns []string, keyColumn string, needKey bool) (query []string, dropKey bool) {
+	if len(columns) == 0 {
+		return nil, false
+	}
+	if !needKey || contains(columns, "*") || contains(columns, keyColumn) {
+		return columns, false
+	}
+	query = append(append(query, columns...), keyColumn)
+	return query, true
+}
+
+// mergeSelectIn concatenates the rows of every group in key order. With
+// preserveOrder the rows inside a group are stably sorted by the position of
+// their key; rows whose key cannot be matched keep their place at the end.
+func mergeSelectIn(groups [][]interface{}, results [][]map[string]interface{}, keyColumn string, preserveOrder, dropKey bool) []map[string]interface{} {
+	total := 0
+	for _, rows := range results {
+		total += len(rows)
+	}
+	merged := make([]map[string]interface{}, 0, total)
+	for i, rows := range results {
+		if preserveOrder && len(groups[i]) > 1 {
+			pos := make(map[string]int, len(groups[i]))
+			for j, k := range groups[i] {
+				pos[keyID(k)] = j
+			}
+			rank := func(row map[string]interface{}) int {
+				if p, ok := pos[keyID(row[keyColumn])]; ok {
+					return p
+				}
+				return len(pos)
+			}
+			sort.SliceStable(rows, func(a, b int) bool { return rank(rows[a]) < rank(rows[b]) })
+		}
+		for _, row := range rows {
+			if dropKey {
+				delete(row, keyColumn)
+			}
+			merged = append(merged, row)
+		}
+	}
+	return merged
+}
+
+// keyID identifies a key by its printed value, so a bound int and the int64
+// (or a UUID string and the gocql.UUID) scanned back from Cassandra match.
+func keyID(k interface{}) string {
+	return fmt.Sprint(k)
+}
@@ -186,6 +186,7 @@ type TableBuilder struct {
 	options    TableOptions
 	ifNotExist bool
 	noDefaults bool
+	indexed    map[string]bool // columns declared via Indexed, used by TableQuery
 }
 
 // NoDefaults disables automatic filling of DefaultCompaction / DefaultCompression /
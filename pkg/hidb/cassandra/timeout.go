@@ -0,0 +1,94 @@
+// @chris
+package cassandra
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// DefaultOperationTimeout bounds every operation whose context carries no
+// deadline. Config.OperationTimeout overrides it; a negative value disables it.
+const DefaultOperationTimeout = 30 * time.Second
+
+// TimeoutError is returned when an operation exceeds the default operation
+// timeout. Deadlines set by the caller are not rewritten: those surface as
+// context.DeadlineExceeded, which IsTimeout also recognises.
+type TimeoutError struct {
+	Op    string
+	After time.Duration
+	Err   error
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("cassandra: %s timed out after %s: %v", e.Op, e.After, e.Err)
+}
+
+func (e *TimeoutError) Unwrap() error { return e.Err }
+
+// Timeout reports true, matching the net.Error convention.
+func (e *TimeoutError) Timeout() bool { return true }
+
+// IsTimeout reports whether err is any kind of timeout: the default operation
+// timeout, a caller deadline, the gocql client-side request timeout, or a
+// coordinator read/write timeout.
+func IsTimeout(err error) bool {
+	if err == nil {
+		return false
+	}
+	var te *TimeoutError
+	var rt *gocql.RequestErrReadTimeout
+	var wt *gocql.RequestErrWriteTimeout
+	return errors.As(err, &te) || errors.As(err, &rt) || errors.As(err, &wt) ||
+		errors.Is(err, context.DeadlineExceeded) || errors.Is(err, gocql.ErrTimeoutNoResponse)
+}
+
+// operationTimeout returns the effective default timeout (0 when disabled).
+func (c *CassandraDB) operationTimeout() time.Duration {
+	switch d := c.config.OperationTimeout; {
+	case d < 0:
+		return 0
+	case d == 0:
+		return DefaultOperationTimeout
+	default:
+		return d
+	}
+}
+
+// withOperationTimeout applies the default timeout when ctx has no deadline.
+// The returned duration is 0 when the caller's context was left untouched.
+func (c *CassandraDB) withOperationTimeout(ctx context.Context) (context.Context, context.CancelFunc, time.Duration) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	d := c.operationTimeout()
+	if _, ok := ctx.Deadline(); ok || d <= 0 {
+		return ctx, func() {}, 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, d
+}
+
+// run executes fn under the default operation timeout and converts an expiry
+// of that timeout into a *TimeoutError.
+func (c *CassandraDB) run(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	ctx, cancel, timeout := c.withOperationTimeout(ctx)
+	defer cancel()
+	err := fn(ctx)
+	if err != nil && timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &TimeoutError{Op: op, After: timeout, Err: err}
+	}
+	return err
+}
+
+// operationContext applies the default timeout to an operation that consumes
+// its Query or Iter before returning; callers must defer cancel. APIs that hand
+// a Query or Iter back to the caller use ctx unchanged instead, so long scans
+// are not cut off by the default timeout.
+func (c *CassandraDB) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel, _ := c.withOperationTimeout(ctx)
+	return ctx, cancel
+}
@@ -66,6 +66,9 @@ func (c *CassandraDB) GetTrace(ctx context.Context, sessionID gocql.UUID) (*Trac
 
 	t := &Trace{Session: TraceSession{SessionID: sessionID}}
 
+	ctx, cancel := c.operationContext(ctx)
+	defer cancel()
+
 	// sessions row
 	err := c.session.Query(
 		`SELECT command, coordinator, duration, parameters, request, started_at, client
@@ -166,21 +166,28 @@ func (u *UpdateBuilder) CQL() (string, []interface{}) {
 // Exec runs the UPDATE.
 func (u *UpdateBuilder) Exec(ctx context.Context) error {
 	stmt, args := u.CQL()
-	q := u.db.session.Query(stmt, args...).WithContext(ctx)
+	q := u.db.session.Query(stmt, args...)
 	if u.consistency != nil {
 		q = q.Consistency(*u.consistency)
 	}
-	return q.Exec()
+	return u.db.run(ctx, "update", func(ctx context.Context) error {
+		return q.WithContext(ctx).Exec()
+	})
 }
 
 // ExecCAS executes as lightweight transaction.
 func (u *UpdateBuilder) ExecCAS(ctx context.Context, dest ...interface{}) (bool, error) {
 	stmt, args := u.CQL()
-	q := u.db.session.Query(stmt, args...).WithContext(ctx)
+	q := u.db.session.Query(stmt, args...)
 	if u.consistency != nil {
 		q = q.Consistency(*u.consistency)
 	}
-	return q.ScanCAS(dest...)
+	var applied bool
+	err := u.db.run(ctx, "update", func(ctx context.Context) (err error) {
+		applied, err = q.WithContext(ctx).ScanCAS(dest...)
+		return err
+	})
+	return applied, err
 }
 
 // UpdateModel generates an UPDATE from a model using its primary key fields
@@ -4,15 +4,19 @@ package hidb
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"github.com/maoxiaoyue/hypgo/pkg/config"
+	"github.com/maoxiaoyue/hypgo/pkg/health"
+	"github.com/maoxiaoyue/hypgo/pkg/migrate"
 	"github.com/maoxiaoyue/hypgo/pkg/resource"
 	"github.com/redis/go-redis/v9"
 	"github.com/uptrace/bun"
 	"github.com/uptrace/bun/schema"
+	"go.opentelemetry.io/otel/metric"
 )
 
 // Dialect SQL 方言介面（類似 bun 的 dialect 模式）
@@ -52,6 +56,17 @@ type Database struct {
 	// 讀寫分離
 	replicaPool *ReplicaPool // 讀取副本池
 
+	// 為查詢建立追蹤 span（由 WithTracing 設定）
+	tracing bool
+
+	// 查詢結果快取（由 WithQueryCache 設定，首次使用時建立）
+	queryCacheConfig *QueryCacheConfig
+	queryCacheOnce   sync.Once
+	queryCacheState  *queryCache
+
+	// 連接池指標註冊（由 RegisterPoolMetrics 設定）
+	poolMetrics metric.Registration
+
 	// 插件系統
 	plugins map[string]DatabasePlugin
 	mu      sync.RWMutex
@@ -238,6 +253,9 @@ func (d *Database) initSQL() (*Database, error) {
 
 	d.sqlDB = db
 	d.hypDB = bun.NewDB(db, d.dialect.BunDialect())
+	if d.tracing {
+		d.hypDB.AddQueryHook(newQueryTracingHook(d.dialect.DriverName()))
+	}
 
 	// 初始化讀取副本
 	if err := d.initReplicas(); err != nil {
@@ -274,6 +292,9 @@ func (d *Database) initReplicas() error {
 			d.replicaPool = nil
 			return fmt.Errorf("failed to init read replica %d: %w", i, err)
 		}
+		if d.tracing {
+			replica.hypDB.AddQueryHook(newQueryTracingHook(d.dialect.DriverName()))
+		}
 		d.replicaPool.Add(replica)
 	}
 
@@ -299,6 +320,9 @@ func (d *Database) initRedis() (*Database, error) {
 	})
 	// 掛上資源追蹤 hook：每條命令自動累計操作數與觸及的 key 數量
 	client.AddHook(redisResourceHook{})
+	if d.tracing {
+		client.AddHook(redisTracingHook{})
+	}
 
 	// 測試連接
 	ctx := context.Background()
@@ -424,6 +448,10 @@ func (d *Database) ReplicaCount() int {
 func (d *Database) Close() error {
 	var errs []error
 
+	if err := d.unregisterPoolMetrics(); err != nil {
+		errs = append(errs, fmt.Errorf("failed to unregister pool metrics: %w", err))
+	}
+
 	// 先關閉讀取副本池
 	if d.replicaPool != nil {
 		if replicaErrs := d.replicaPool.Close(); len(replicaErrs) > 0 {
@@ -553,6 +581,60 @@ func (d *Database) HypDBTransaction(ctx context.Context, fn func(context.Context
 	})
 }
 
+// RegisterHealthChecks 將主庫、讀取副本、Redis 與各插件分別註冊至健康檢查中心
+// 配置啟用 database.migration_check 時一併註冊 "migrations" 檢查（見 migrationCheck）
+// reg 為 nil 時使用 health.Default
+func (d *Database) RegisterHealthChecks(reg *health.Registry) {
+	if reg == nil {
+		reg = health.Default
+	}
+
+	if d.sqlDB != nil {
+		reg.Register("database", d.sqlDB.PingContext)
+		if check := d.migrationCheck(); check != nil {
+			reg.Register("migrations", check)
+		}
+	}
+	if d.replicaPool != nil {
+		reg.Register("replicas", func(ctx context.Context) error {
+			if errs := d.replicaPool.PingAll(); len(errs) > 0 {
+				return errors.Join(errs...)
+			}
+			return nil
+		})
+	}
+	if d.redisDB != nil {
+		reg.Register("redis", func(ctx context.Context) error {
+			return d.redisDB.Ping(ctx).Err()
+		})
+	}
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	for name, plugin := range d.plugins {
+		reg.Register(name, plugin.Ping)
+	}
+}
+
+// migrationCheck 使用可選介面 MigrationCheckProvider，啟用時回傳比對 schema_migrations 與最新 migration 的檢查
+// migration 目錄無法讀取或驅動不支援時，檢查固定回報該錯誤，讓配置問題在 readiness 中浮現
+func (d *Database) migrationCheck() health.Checker {
+	provider, ok := d.config.(config.MigrationCheckProvider)
+	if !ok || !provider.IsMigrationCheckEnabled() {
+		return nil
+	}
+
+	dialect, err := migrate.DialectFor(d.config.GetDriver())
+	var migrations []migrate.Migration
+	if err == nil {
+		migrations, err = migrate.LoadDir(provider.GetMigrationsDir())
+	}
+	if err != nil {
+		return func(context.Context) error { return err }
+	}
+	return migrate.New(d.sqlDB, dialect, migrations).HealthCheck
+}
+
 // HealthCheck 健康檢查（主庫 + 讀取副本 + Redis + 插件）
 // 接受任何 context.Context，包括 HypGo *context.Context
 func (d *Database) HealthCheck(ctx context.Context) error {
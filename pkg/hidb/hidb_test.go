@@ -0,0 +1,38 @@
+package hidb
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/config"
+)
+
+func TestMigrationCheckConfig(t *testing.T) {
+	cfg := &config.DatabaseConfig{Driver: "postgres"}
+	d := &Database{config: cfg}
+	if d.migrationCheck() != nil {
+		t.Error("Expected no migration check when migration_check is disabled")
+	}
+
+	// 目錄不存在：檢查固定回報錯誤，而非靜默略過
+	cfg.MigrationCheck = true
+	cfg.MigrationsDir = filepath.Join(t.TempDir(), "missing")
+	check := d.migrationCheck()
+	if check == nil || check(context.Background()) == nil {
+		t.Error("Expected a failing check for a missing migrations dir")
+	}
+
+	cfg.MigrationsDir = t.TempDir()
+	os.WriteFile(filepath.Join(cfg.MigrationsDir, "001_init.up.sql"), []byte("SELECT 1;"), 0644)
+	cfg.Driver = "cassandra"
+	if check := d.migrationCheck(); check == nil || check(context.Background()) == nil {
+		t.Error("Expected a failing check for an unsupported driver")
+	}
+
+	cfg.Driver = "mysql"
+	if d.migrationCheck() == nil {
+		t.Error("Expected a migration check for a supported driver")
+	}
+}
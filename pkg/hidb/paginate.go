@@ -0,0 +1,213 @@
+// @chris
+package hidb
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// 分頁預設值，與 Context.GetPageSize 一致
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// ErrInvalidCursor 游標無法解碼（遭竄改或欄位型別不符），通常應回應 400
+var ErrInvalidCursor = errors.New("hidb: invalid cursor")
+
+// ===== 頁碼分頁 =====
+
+// Page 頁碼分頁結果
+type Page[T any] struct {
+	Items    []T `json:"items"`
+	Total    int `json:"total"`
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Pages    int `json:"pages"`
+}
+
+// PageItems 本頁資料（供 Context.PaginatedPage 使用）
+func (p Page[T]) PageItems() interface{} { return p.Items }
+
+// PageInfo 分頁資訊（供 Context.PaginatedPage 使用）
+func (p Page[T]) PageInfo() (total, page, pageSize, pages int) {
+	return p.Total, p.Page, p.PageSize, p.Pages
+}
+
+// Paginate 對 query 套用 LIMIT / OFFSET 並執行 COUNT，回傳本頁資料與總數
+// page 從 1 開始；size <= 0 時使用 DefaultPageSize，上限 MaxPageSize
+// query 未設定 Model 時以 []T 作為 Model；排序需由呼叫端指定，否則各頁順序不保證穩定
+//
+// EX：
+//
+//	q := db.HypDB().NewSelect().Model((*models.User)(nil)).Where("is_active").Order("id")
+//	page, err := hidb.Paginate[models.User](ctx, q, c.GetPage(), c.GetPageSize())
+//	if err != nil {
+//	    c.Error(err)
+//	    return
+//	}
+//	c.PaginatedPage(page)
+func Paginate[T any](ctx context.Context, query *bun.SelectQuery, page, size int) (Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	items := make([]T, 0, size)
+	query = query.Limit(size).Offset((page - 1) * size)
+	var (
+		total int
+		err   error
+	)
+	if query.GetModel() == nil {
+		total, err = query.Model(&items).ScanAndCount(ctx)
+	} else {
+		total, err = query.ScanAndCount(ctx, &items)
+	}
+	if err != nil {
+		return Page[T]{}, err
+	}
+
+	return Page[T]{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: size,
+		Pages:    (total + size - 1) / size,
+	}, nil
+}
+
+// ===== 游標分頁 =====
+
+// CursorPage 游標分頁結果，NextCursor 為空表示沒有下一頁
+type CursorPage[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	HasMore    bool   `json:"has_more"`
+}
+
+// PageItems 本頁資料（供 Context.PaginatedCursor 使用）
+func (p CursorPage[T]) PageItems() interface{} { return p.Items }
+
+// CursorInfo 下一頁游標（供 Context.PaginatedCursor 使用）
+func (p CursorPage[T]) CursorInfo() (nextCursor string, hasMore bool) {
+	return p.NextCursor, p.HasMore
+}
+
+// PaginateCursor 以 keyset（WHERE column > 游標值）分頁，不需 COUNT 與 OFFSET，適合大型資料表
+// T 需為 bun model；column 為排序欄位（須唯一且非 NULL），空字串表示主鍵，前綴 "-" 表示遞減排序。
+// cursor 為上一頁的 NextCursor，第一頁傳空字串；無法解碼時回傳 ErrInvalidCursor
+//
+// EX：
+//
+//	q := db.HypDB().NewSelect().Model((*models.Event)(nil)).Where("tenant_id = ?", tenantID)
+//	page, err := hidb.PaginateCursor[models.Event](ctx, q, c.Query("cursor"), c.GetPageSize(), "-id")
+//	if errors.Is(err, hidb.ErrInvalidCursor) {
+//	    c.Fail(http.StatusBadRequest, "invalid cursor")
+//	    return
+//	}
+//	c.PaginatedCursor(page)
+func PaginateCursor[T any](ctx context.Context, query *bun.SelectQuery, cursor string, size int, column string) (CursorPage[T], error) {
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	field, desc, err := cursorField[T](query, column)
+	if err != nil {
+		return CursorPage[T]{}, err
+	}
+	op, order := ">", "ASC"
+	if desc {
+		op, order = "<", "DESC"
+	}
+
+	if cursor != "" {
+		after, err := decodeCursor(cursor, field)
+		if err != nil {
+			return CursorPage[T]{}, err
+		}
+		query = query.Where("?TableAlias.? "+op+" ?", bun.Ident(field.Name), after)
+	}
+
+	items := make([]T, 0, size+1)
+	query = query.OrderExpr("?TableAlias.? "+order, bun.Ident(field.Name)).Limit(size + 1)
+	if query.GetModel() == nil {
+		err = query.Model(&items).Scan(ctx)
+	} else {
+		err = query.Scan(ctx, &items)
+	}
+	if err != nil {
+		return CursorPage[T]{}, err
+	}
+
+	result := CursorPage[T]{Items: items}
+	if len(items) > size {
+		result.Items = items[:size]
+		result.HasMore = true
+		last := reflect.ValueOf(&result.Items[size-1]).Elem()
+		if result.NextCursor, err = encodeCursor(field.Value(last).Interface()); err != nil {
+			return CursorPage[T]{}, err
+		}
+	}
+	return result, nil
+}
+
+// cursorField 解析排序欄位（"-" 前綴為遞減）
+func cursorField[T any](query *bun.SelectQuery, column string) (*schema.Field, bool, error) {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() != reflect.Struct {
+		return nil, false, fmt.Errorf("hidb: cursor pagination requires a struct model, got %s", typ)
+	}
+	table := query.Dialect().Tables().Get(typ)
+
+	column, desc := strings.CutPrefix(column, "-")
+	if column == "" {
+		if len(table.PKs) != 1 {
+			return nil, false, fmt.Errorf("hidb: %s must have exactly one primary key for cursor pagination", table.TypeName)
+		}
+		return table.PKs[0], desc, nil
+	}
+	field, ok := table.FieldMap[column]
+	if !ok {
+		return nil, false, fmt.Errorf("hidb: %s has no column %q", table.TypeName, column)
+	}
+	return field, desc, nil
+}
+
+// encodeCursor 游標為排序欄位值的 JSON，再以 URL-safe base64 編碼
+func encodeCursor(v interface{}) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor 依欄位型別還原游標值，避免大整數經 float64 失真
+func decodeCursor(cursor string, field *schema.Field) (interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	v := reflect.New(field.IndirectType)
+	if err := json.Unmarshal(data, v.Interface()); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return v.Elem().Interface(), nil
+}
@@ -0,0 +1,151 @@
+package hidb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// pageConnector 依 SQL 回應查詢並記錄執行過的 SQL
+type pageConnector struct {
+	queries *[]string
+	respond func(query string) ([]string, [][]driver.Value)
+}
+
+func (c pageConnector) Connect(context.Context) (driver.Conn, error) { return pageConn(c), nil }
+func (c pageConnector) Driver() driver.Driver                        { return nil }
+
+type pageConn pageConnector
+
+func (pageConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (pageConn) Close() error                        { return nil }
+func (pageConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c pageConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	*c.queries = append(*c.queries, query)
+	columns, rows := c.respond(query)
+	return &pageRows{columns: columns, rows: rows}, nil
+}
+
+type pageRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *pageRows) Columns() []string { return r.columns }
+func (r *pageRows) Close() error      { return nil }
+func (r *pageRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type pageItem struct {
+	bun.BaseModel `bun:"table:items,alias:i"`
+
+	ID   int64  `bun:"id,pk"`
+	Name string `bun:"name"`
+}
+
+func newPageDB(t *testing.T, respond func(query string) ([]string, [][]driver.Value)) (*bun.DB, *[]string) {
+	t.Helper()
+	queries := &[]string{}
+	db := bun.NewDB(sql.OpenDB(pageConnector{queries: queries, respond: respond}), pgdialect.New())
+	t.Cleanup(func() { db.Close() })
+	return db, queries
+}
+
+func itemRows(ids ...int64) [][]driver.Value {
+	rows := make([][]driver.Value, 0, len(ids))
+	for _, id := range ids {
+		rows = append(rows, []driver.Value{id, "item"})
+	}
+	return rows
+}
+
+func TestPaginate(t *testing.T) {
+	db, queries := newPageDB(t, func(query string) ([]string, [][]driver.Value) {
+		if strings.Contains(query, "count(*)") {
+			return []string{"count"}, [][]driver.Value{{int64(45)}}
+		}
+		return []string{"id", "name"}, itemRows(21, 22)
+	})
+
+	page, err := Paginate[pageItem](context.Background(), db.NewSelect().Model((*pageItem)(nil)).Order("id"), 3, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 45 || page.Page != 3 || page.PageSize != 10 || page.Pages != 5 || len(page.Items) != 2 {
+		t.Errorf("unexpected page %+v", page)
+	}
+	if q := strings.Join(*queries, "\n"); !strings.Contains(q, "LIMIT 10 OFFSET 20") {
+		t.Errorf("expected limit/offset, got %s", q)
+	}
+
+	// 未設定 Model 時以 []T 為 Model；size 超過上限時截斷
+	*queries = nil
+	page, err = Paginate[pageItem](context.Background(), db.NewSelect(), 0, 500)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Page != 1 || page.PageSize != MaxPageSize || page.Pages != 1 {
+		t.Errorf("unexpected defaults %+v", page)
+	}
+	if q := strings.Join(*queries, "\n"); !strings.Contains(q, `FROM "items"`) || !strings.Contains(q, "LIMIT 100") {
+		t.Errorf("expected model from T, got %s", q)
+	}
+}
+
+func TestPaginateCursor(t *testing.T) {
+	db, queries := newPageDB(t, func(query string) ([]string, [][]driver.Value) {
+		return []string{"id", "name"}, itemRows(9, 8, 7)
+	})
+	ctx := context.Background()
+
+	page, err := PaginateCursor[pageItem](ctx, db.NewSelect().Model((*pageItem)(nil)), "", 2, "-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 2 || !page.HasMore || page.NextCursor == "" {
+		t.Fatalf("unexpected page %+v", page)
+	}
+	if q := (*queries)[0]; !strings.Contains(q, `ORDER BY "i"."id" DESC`) || !strings.Contains(q, "LIMIT 3") {
+		t.Errorf("unexpected first query %s", q)
+	}
+
+	next, err := PaginateCursor[pageItem](ctx, db.NewSelect().Model((*pageItem)(nil)), page.NextCursor, 5, "-id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q := (*queries)[1]; !strings.Contains(q, `"i"."id" < 8`) {
+		t.Errorf("expected keyset condition after id 8, got %s", q)
+	}
+	if next.HasMore || next.NextCursor != "" {
+		t.Errorf("expected last page, got %+v", next)
+	}
+
+	// 預設為主鍵遞增
+	if _, err := PaginateCursor[pageItem](ctx, db.NewSelect(), page.NextCursor, 2, ""); err != nil {
+		t.Fatal(err)
+	}
+	if q := (*queries)[2]; !strings.Contains(q, `"i"."id" > 8`) || !strings.Contains(q, `ORDER BY "i"."id" ASC`) {
+		t.Errorf("unexpected ascending query %s", q)
+	}
+
+	if _, err := PaginateCursor[pageItem](ctx, db.NewSelect(), "!!", 2, ""); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := PaginateCursor[pageItem](ctx, db.NewSelect(), "", 2, "missing"); err == nil {
+		t.Error("expected error for unknown column")
+	}
+}
@@ -0,0 +1,161 @@
+// @chris
+package hidb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ===== 連接池統計 =====
+
+// PoolStats 單一連接池的統計，Name 為 "primary" 或 "replica-N"
+type PoolStats struct {
+	Name string
+	sql.DBStats
+}
+
+// DatabaseStats 主庫與各讀取副本的連接池統計
+type DatabaseStats struct {
+	// Pools 主庫（若有）在前，其後依序為各副本
+	Pools []PoolStats
+	// Total 各連接池加總；MaxOpenConnections 為各池上限之和，任一池不限制時為 0
+	Total sql.DBStats
+}
+
+// Stats 讀取主庫與各副本的 sql.DB.Stats()
+// 連線使用量接近 MaxOpenConnections 且 WaitCount 持續增加即表示連接池不足
+func (d *Database) Stats() DatabaseStats {
+	var stats DatabaseStats
+	if d.sqlDB != nil {
+		stats.Pools = append(stats.Pools, PoolStats{Name: "primary", DBStats: d.sqlDB.Stats()})
+	}
+	if d.replicaPool != nil {
+		stats.Pools = append(stats.Pools, d.replicaPool.Stats()...)
+	}
+
+	unlimited := false
+	for _, p := range stats.Pools {
+		if p.MaxOpenConnections == 0 {
+			unlimited = true
+		}
+		stats.Total.MaxOpenConnections += p.MaxOpenConnections
+		stats.Total.OpenConnections += p.OpenConnections
+		stats.Total.InUse += p.InUse
+		stats.Total.Idle += p.Idle
+		stats.Total.WaitCount += p.WaitCount
+		stats.Total.WaitDuration += p.WaitDuration
+		stats.Total.MaxIdleClosed += p.MaxIdleClosed
+		stats.Total.MaxIdleTimeClosed += p.MaxIdleTimeClosed
+		stats.Total.MaxLifetimeClosed += p.MaxLifetimeClosed
+	}
+	if unlimited {
+		stats.Total.MaxOpenConnections = 0
+	}
+	return stats
+}
+
+// Stats 各副本的連接池統計（名稱與熔斷器一致，為 replica-N）
+func (rp *ReplicaPool) Stats() []PoolStats {
+	replicas := *rp.replicas.Load()
+	stats := make([]PoolStats, 0, len(replicas))
+	for i, replica := range replicas {
+		if replica.sqlDB == nil {
+			continue
+		}
+		stats = append(stats, PoolStats{Name: fmt.Sprintf("replica-%d", i), DBStats: replica.sqlDB.Stats()})
+	}
+	return stats
+}
+
+// poolNameKey 連接池名稱屬性（OpenTelemetry 資料庫語意慣例）
+const poolNameKey = attribute.Key("db.client.connection.pool.name")
+
+// RegisterPoolMetrics 以 OpenTelemetry 非同步儀表匯出主庫與各副本的連接池統計，
+// 每次收集（如 Prometheus 抓取）時讀取 Stats()，依 db.client.connection.pool.name 分組：
+//
+//	db.client.connection.count          目前連線數（state=used / idle）
+//	db.client.connection.max            最大開啟連線數（0 表示不限制）
+//	db.client.connection.wait.count     等待可用連線的累計次數
+//	db.client.connection.wait.duration  等待可用連線的累計時間（秒）
+//
+// provider 為 nil 時使用全域 provider，應與 middleware.MetricsConfig 使用同一個以一併輸出；
+// 重複呼叫會取代先前的註冊，Close 時自動取消
+//
+// EX：
+//
+//	provider, _ := middleware.NewPrometheusMeterProvider(reg)
+//	srv.Use(middleware.Metrics(middleware.MetricsConfig{MeterProvider: provider}))
+//	db.RegisterPoolMetrics(provider)
+func (d *Database) RegisterPoolMetrics(provider metric.MeterProvider) error {
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(tracing.InstrumentationName)
+
+	count, err := meter.Int64ObservableUpDownCounter("db.client.connection.count",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("Number of connections currently in the pool, by state."))
+	if err != nil {
+		return err
+	}
+	maxConns, err := meter.Int64ObservableUpDownCounter("db.client.connection.max",
+		metric.WithUnit("{connection}"),
+		metric.WithDescription("Maximum number of open connections allowed (0 means unlimited)."))
+	if err != nil {
+		return err
+	}
+	waitCount, err := meter.Int64ObservableCounter("db.client.connection.wait.count",
+		metric.WithUnit("{wait}"),
+		metric.WithDescription("Total number of times a request waited for a free connection."))
+	if err != nil {
+		return err
+	}
+	waitDuration, err := meter.Float64ObservableCounter("db.client.connection.wait.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Total time spent waiting for a free connection."))
+	if err != nil {
+		return err
+	}
+
+	reg, err := meter.RegisterCallback(func(_ context.Context, o metric.Observer) error {
+		for _, p := range d.Stats().Pools {
+			pool := poolNameKey.String(p.Name)
+			o.ObserveInt64(count, int64(p.InUse), metric.WithAttributes(pool, attribute.String("db.client.connection.state", "used")))
+			o.ObserveInt64(count, int64(p.Idle), metric.WithAttributes(pool, attribute.String("db.client.connection.state", "idle")))
+			o.ObserveInt64(maxConns, int64(p.MaxOpenConnections), metric.WithAttributes(pool))
+			o.ObserveInt64(waitCount, p.WaitCount, metric.WithAttributes(pool))
+			o.ObserveFloat64(waitDuration, p.WaitDuration.Seconds(), metric.WithAttributes(pool))
+		}
+		return nil
+	}, count, maxConns, waitCount, waitDuration)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	previous := d.poolMetrics
+	d.poolMetrics = reg
+	d.mu.Unlock()
+	if previous != nil {
+		return previous.Unregister()
+	}
+	return nil
+}
+
+// unregisterPoolMetrics 取消連接池指標的註冊
+func (d *Database) unregisterPoolMetrics() error {
+	d.mu.Lock()
+	reg := d.poolMetrics
+	d.poolMetrics = nil
+	d.mu.Unlock()
+	if reg == nil {
+		return nil
+	}
+	return reg.Unregister()
+}
@@ -0,0 +1,143 @@
+package hidb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// stubDriver 僅支援建立連線的 database/sql 驅動，用於產生真實的連接池統計
+type stubDriver struct{}
+
+type stubConn struct{}
+
+func (stubDriver) Open(string) (driver.Conn, error) { return stubConn{}, nil }
+
+func (stubConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (stubConn) Close() error                        { return nil }
+func (stubConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func init() {
+	sql.Register("hidb-stub", stubDriver{})
+}
+
+func openStub(t *testing.T, maxOpen int) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("hidb-stub", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(maxOpen)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// holdConns 從連接池取出 n 條連線，測試結束時歸還
+func holdConns(t *testing.T, db *sql.DB, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { conn.Close() })
+	}
+}
+
+func newStatsDatabase(t *testing.T) *Database {
+	primary := openStub(t, 10)
+	replica := openStub(t, 4)
+	holdConns(t, primary, 3)
+	holdConns(t, replica, 1)
+
+	pool := NewReplicaPool()
+	pool.Add(ReadReplica{sqlDB: replica})
+	return &Database{sqlDB: primary, replicaPool: pool, plugins: map[string]DatabasePlugin{}}
+}
+
+func TestDatabaseStats(t *testing.T) {
+	db := newStatsDatabase(t)
+
+	stats := db.Stats()
+	if len(stats.Pools) != 2 || stats.Pools[0].Name != "primary" || stats.Pools[1].Name != "replica-0" {
+		t.Fatalf("unexpected pools: %+v", stats.Pools)
+	}
+	if stats.Pools[0].InUse != 3 || stats.Pools[1].InUse != 1 {
+		t.Errorf("in use = %d/%d, want 3/1", stats.Pools[0].InUse, stats.Pools[1].InUse)
+	}
+	if stats.Total.InUse != 4 || stats.Total.OpenConnections != 4 || stats.Total.MaxOpenConnections != 14 {
+		t.Errorf("unexpected total: %+v", stats.Total)
+	}
+
+	// 任一連接池不限制時，總上限視為不限制
+	db.replicaPool.Add(ReadReplica{sqlDB: openStub(t, 0)})
+	if total := db.Stats().Total.MaxOpenConnections; total != 0 {
+		t.Errorf("expected unlimited total, got %d", total)
+	}
+
+	if got := (&Database{}).Stats(); len(got.Pools) != 0 || got.Total.OpenConnections != 0 {
+		t.Errorf("expected empty stats without connections, got %+v", got)
+	}
+}
+
+func TestRegisterPoolMetrics(t *testing.T) {
+	db := newStatsDatabase(t)
+	reader := sdkmetric.NewManualReader()
+	if err := db.RegisterPoolMetrics(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))); err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func() map[string]int64 {
+		var rm metricdata.ResourceMetrics
+		if err := reader.Collect(context.Background(), &rm); err != nil {
+			t.Fatal(err)
+		}
+		values := make(map[string]int64)
+		for _, sm := range rm.ScopeMetrics {
+			for _, m := range sm.Metrics {
+				sum, ok := m.Data.(metricdata.Sum[int64])
+				if !ok {
+					continue
+				}
+				for _, dp := range sum.DataPoints {
+					pool, _ := dp.Attributes.Value(poolNameKey)
+					key := m.Name + "/" + pool.AsString()
+					if state, ok := dp.Attributes.Value(attribute.Key("db.client.connection.state")); ok {
+						key += "/" + state.AsString()
+					}
+					values[key] = dp.Value
+				}
+			}
+		}
+		return values
+	}
+
+	values := collect()
+	want := map[string]int64{
+		"db.client.connection.count/primary/used":   3,
+		"db.client.connection.count/primary/idle":   0,
+		"db.client.connection.count/replica-0/used": 1,
+		"db.client.connection.max/primary":          10,
+		"db.client.connection.max/replica-0":        4,
+		"db.client.connection.wait.count/primary":   0,
+	}
+	for key, v := range want {
+		if got, ok := values[key]; !ok || got != v {
+			t.Errorf("%s = %d (present %v), want %d", key, got, ok, v)
+		}
+	}
+
+	// Close 後不再回報
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if values := collect(); len(values) != 0 {
+		t.Errorf("expected no pool metrics after Close, got %v", values)
+	}
+}
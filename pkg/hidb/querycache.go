@@ -0,0 +1,376 @@
+// @chris
+package hidb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
+	"github.com/redis/go-redis/v9"
+	"github.com/uptrace/bun"
+	"golang.org/x/sync/singleflight"
+)
+
+// ErrCacheMiss 快取中沒有該 key（QueryCacheStore.Get 回傳）
+var ErrCacheMiss = errors.New("hidb: cache miss")
+
+// ===== 查詢結果快取 =====
+
+// QueryCacheStore 查詢結果快取後端
+type QueryCacheStore interface {
+	// Get 取得快取內容，不存在時回傳 ErrCacheMiss
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set 寫入快取並登記到各 tag，供 DeleteTags 一次失效
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error
+	// Delete 刪除指定 key
+	Delete(ctx context.Context, keys ...string) error
+	// DeleteTags 刪除登記在這些 tag 下的全部 key
+	DeleteTags(ctx context.Context, tags ...string) error
+}
+
+// QueryCacheCodec 查詢結果的序列化方式
+type QueryCacheCodec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSONQueryCodec 以 encoding/json 序列化（預設）
+type JSONQueryCodec struct{}
+
+func (JSONQueryCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (JSONQueryCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+
+// QueryCacheConfig 查詢快取配置
+type QueryCacheConfig struct {
+	// Store 快取後端；nil 時使用本實例的 Redis 連線（以 "qcache:" 為前綴），皆無則不快取
+	Store QueryCacheStore
+	// Codec 序列化方式，預設 JSONQueryCodec
+	Codec QueryCacheCodec
+	// DefaultTTL CachedQuery 傳入 ttl <= 0 時使用，預設 5 分鐘
+	DefaultTTL time.Duration
+	// Breaker 快取後端的熔斷器；後端故障時 CachedQuery 直接查詢資料庫，預設 Name 為 "query-cache"
+	Breaker resilience.BreakerConfig
+}
+
+// WithQueryCache 啟用 CachedQuery 的查詢結果快取
+func WithQueryCache(cfg QueryCacheConfig) Option {
+	return func(db *Database) {
+		db.queryCacheConfig = &cfg
+	}
+}
+
+// queryCache CachedQuery 使用的快取狀態
+type queryCache struct {
+	store      QueryCacheStore
+	codec      QueryCacheCodec
+	defaultTTL time.Duration
+	breaker    *resilience.CircuitBreaker
+	group      singleflight.Group
+}
+
+// queryCache 延遲建立快取狀態（預設後端需要初始化後的 Redis 連線）
+// 沒有可用的後端時回傳 nil
+func (d *Database) queryCache() *queryCache {
+	d.queryCacheOnce.Do(func() {
+		var cfg QueryCacheConfig
+		if d.queryCacheConfig != nil {
+			cfg = *d.queryCacheConfig
+		}
+		if cfg.Store == nil {
+			if d.redisDB == nil {
+				return
+			}
+			cfg.Store = NewRedisQueryCacheStore(d.redisDB, "qcache:")
+		}
+		if cfg.Codec == nil {
+			cfg.Codec = JSONQueryCodec{}
+		}
+		if cfg.DefaultTTL <= 0 {
+			cfg.DefaultTTL = 5 * time.Minute
+		}
+		if cfg.Breaker.Name == "" {
+			cfg.Breaker.Name = "query-cache"
+		}
+		if cfg.Breaker.IsFailure == nil {
+			cfg.Breaker.IsFailure = func(err error) bool {
+				return !errors.Is(err, ErrCacheMiss) && resilience.DefaultIsFailure(err)
+			}
+		}
+		d.queryCacheState = &queryCache{
+			store:      cfg.Store,
+			codec:      cfg.Codec,
+			defaultTTL: cfg.DefaultTTL,
+			breaker:    resilience.NewCircuitBreaker(cfg.Breaker),
+		}
+	})
+	return d.queryCacheState
+}
+
+// CachedQuery 快取查詢結果：命中時將快取內容解碼到 dest，未命中時以讀取副本（無則主庫）執行 query
+// 並快取 dest；query 需將結果寫入 dest。同一 key 同時未命中時只執行一次查詢。
+// 快取後端故障或熔斷時直接查詢資料庫，不回傳快取錯誤；tags 供 InvalidateCacheTags 批次失效
+//
+// EX：
+//
+//	var users []User
+//	err := db.CachedQuery(ctx, "users:active", time.Minute, &users, func(ctx context.Context, q bun.IDB) error {
+//		return q.NewSelect().Model(&users).Where("active").Scan(ctx)
+//	}, "users")
+//	// 寫入後：db.InvalidateCacheTags(ctx, "users")
+func (d *Database) CachedQuery(ctx context.Context, key string, ttl time.Duration, dest interface{}, query func(ctx context.Context, db bun.IDB) error, tags ...string) error {
+	qc := d.queryCache()
+	if qc == nil {
+		return query(ctx, d.ReadHypDB())
+	}
+	if ttl <= 0 {
+		ttl = qc.defaultTTL
+	}
+
+	err := qc.breaker.Execute(ctx, func(ctx context.Context) error {
+		data, err := qc.store.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		return qc.codec.Unmarshal(data, dest)
+	})
+	if err == nil {
+		return nil
+	}
+
+	// 未命中、解碼失敗或後端不可用：查詢資料庫
+	leader := false
+	v, err, _ := qc.group.Do(key, func() (interface{}, error) {
+		leader = true
+		if err := query(ctx, d.ReadHypDB()); err != nil {
+			return nil, err
+		}
+		data, err := qc.codec.Marshal(dest)
+		if err != nil {
+			return nil, fmt.Errorf("hidb: encode cached query %q: %w", key, err)
+		}
+		// 寫入失敗由熔斷器記錄，不影響本次結果
+		_ = qc.breaker.Execute(ctx, func(ctx context.Context) error {
+			return qc.store.Set(ctx, key, data, ttl, tags)
+		})
+		return data, nil
+	})
+	if err != nil || leader {
+		return err
+	}
+	return qc.codec.Unmarshal(v.([]byte), dest)
+}
+
+// InvalidateCache 刪除指定 key 的快取
+// 與 CachedQuery 不同，失效失敗會回傳錯誤，避免呼叫端誤以為舊資料已清除
+func (d *Database) InvalidateCache(ctx context.Context, keys ...string) error {
+	qc := d.queryCache()
+	if qc == nil || len(keys) == 0 {
+		return nil
+	}
+	return qc.breaker.Execute(ctx, func(ctx context.Context) error {
+		return qc.store.Delete(ctx, keys...)
+	})
+}
+
+// InvalidateCacheTags 刪除登記在這些 tag 下的全部快取
+func (d *Database) InvalidateCacheTags(ctx context.Context, tags ...string) error {
+	qc := d.queryCache()
+	if qc == nil || len(tags) == 0 {
+		return nil
+	}
+	return qc.breaker.Execute(ctx, func(ctx context.Context) error {
+		return qc.store.DeleteTags(ctx, tags...)
+	})
+}
+
+// ===== Memory =====
+
+// memoryQueryCacheSweepInterval Set 清除過期項目的最短間隔
+const memoryQueryCacheSweepInterval = time.Minute
+
+// MemoryQueryCacheStore 行程內快取後端（單機部署或測試）
+// 過期項目於 Set 時每分鐘最多清除一次，移除項目時一併自 tag 索引移除，不另外啟動背景 goroutine
+type MemoryQueryCacheStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryCacheEntry
+	tags      map[string]map[string]struct{}
+	lastSweep time.Time
+}
+
+type memoryCacheEntry struct {
+	data      []byte
+	expiresAt time.Time
+	tags      []string
+}
+
+// NewMemoryQueryCacheStore 創建行程內快取後端
+func NewMemoryQueryCacheStore() *MemoryQueryCacheStore {
+	return &MemoryQueryCacheStore{
+		entries: make(map[string]memoryCacheEntry),
+		tags:    make(map[string]map[string]struct{}),
+	}
+}
+
+func (s *MemoryQueryCacheStore) Get(_ context.Context, key string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, ErrCacheMiss
+	}
+	if time.Now().After(entry.expiresAt) {
+		s.removeLocked(key)
+		return nil, ErrCacheMiss
+	}
+	return entry.data, nil
+}
+
+func (s *MemoryQueryCacheStore) Set(_ context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= memoryQueryCacheSweepInterval {
+		s.sweepLocked(now)
+	}
+	// 覆寫時先移除舊的 tag 關聯，新項目的 tag 可能不同
+	s.removeLocked(key)
+	s.entries[key] = memoryCacheEntry{data: value, expiresAt: now.Add(ttl), tags: append([]string(nil), tags...)}
+	for _, tag := range tags {
+		keys := s.tags[tag]
+		if keys == nil {
+			keys = make(map[string]struct{})
+			s.tags[tag] = keys
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+func (s *MemoryQueryCacheStore) Delete(_ context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		s.removeLocked(key)
+	}
+	return nil
+}
+
+func (s *MemoryQueryCacheStore) DeleteTags(_ context.Context, tags ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, tag := range tags {
+		for key := range s.tags[tag] {
+			s.removeLocked(key)
+		}
+		delete(s.tags, tag)
+	}
+	return nil
+}
+
+// removeLocked 移除項目並自其 tag 索引移除，tag 下已無 key 時刪除該 tag
+func (s *MemoryQueryCacheStore) removeLocked(key string) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	delete(s.entries, key)
+	for _, tag := range entry.tags {
+		keys := s.tags[tag]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(s.tags, tag)
+		}
+	}
+}
+
+// sweepLocked 移除所有已過期的項目
+func (s *MemoryQueryCacheStore) sweepLocked(now time.Time) {
+	for key, entry := range s.entries {
+		if now.After(entry.expiresAt) {
+			s.removeLocked(key)
+		}
+	}
+	s.lastSweep = now
+}
+
+// ===== Redis =====
+
+// RedisQueryCacheStore Redis 快取後端
+// 每個 tag 以一個 set 記錄其下的 key，set 的存活時間延長到最晚過期的成員
+type RedisQueryCacheStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisQueryCacheStore 創建 Redis 快取後端
+func NewRedisQueryCacheStore(client redis.UniversalClient, prefix string) *RedisQueryCacheStore {
+	return &RedisQueryCacheStore{client: client, prefix: prefix}
+}
+
+// tagAddScript 加入 tag 成員，只在新 TTL 較長時延長 set 的存活時間
+var tagAddScript = redis.NewScript(`
+redis.call('SADD', KEYS[1], ARGV[1])
+if redis.call('PTTL', KEYS[1]) < tonumber(ARGV[2]) then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 1`)
+
+func (s *RedisQueryCacheStore) tagKey(tag string) string {
+	return s.prefix + "tag:" + tag
+}
+
+func (s *RedisQueryCacheStore) Get(ctx context.Context, key string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, ErrCacheMiss
+	}
+	return data, err
+}
+
+func (s *RedisQueryCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration, tags []string) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, s.prefix+key, value, ttl)
+		for _, tag := range tags {
+			tagAddScript.Eval(ctx, pipe, []string{s.tagKey(tag)}, s.prefix+key, ttl.Milliseconds())
+		}
+		return nil
+	})
+	return err
+}
+
+// Delete 逐一刪除（pipeline），key 分散在不同 cluster slot 時仍可用
+func (s *RedisQueryCacheStore) Delete(ctx context.Context, keys ...string) error {
+	_, err := s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, s.prefix+key)
+		}
+		return nil
+	})
+	return err
+}
+
+func (s *RedisQueryCacheStore) DeleteTags(ctx context.Context, tags ...string) error {
+	for _, tag := range tags {
+		tagKey := s.tagKey(tag)
+		members, err := s.client.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			return err
+		}
+		// set 中已是帶前綴的完整 key
+		_, err = s.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range members {
+				pipe.Del(ctx, key)
+			}
+			pipe.Del(ctx, tagKey)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
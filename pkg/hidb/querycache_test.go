@@ -0,0 +1,214 @@
+package hidb
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
+	"github.com/redis/go-redis/v9"
+	"github.com/uptrace/bun"
+)
+
+type cachedUser struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// countingQuery 回傳一個計算執行次數的查詢
+func countingQuery(calls *atomic.Int32, name string, dest *cachedUser) func(context.Context, bun.IDB) error {
+	return func(context.Context, bun.IDB) error {
+		calls.Add(1)
+		*dest = cachedUser{ID: 1, Name: name}
+		return nil
+	}
+}
+
+func TestCachedQueryHitMissInvalidate(t *testing.T) {
+	db := &Database{}
+	WithQueryCache(QueryCacheConfig{Store: NewMemoryQueryCacheStore()})(db)
+	ctx := t.Context()
+	var calls atomic.Int32
+
+	var first, second cachedUser
+	if err := db.CachedQuery(ctx, "user:1", time.Minute, &first, countingQuery(&calls, "alice", &first), "users"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.CachedQuery(ctx, "user:1", time.Minute, &second, countingQuery(&calls, "bob", &second), "users"); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 1 || second.Name != "alice" {
+		t.Fatalf("Expected second call served from cache, got %d queries and %+v", calls.Load(), second)
+	}
+
+	if err := db.InvalidateCache(ctx, "user:1"); err != nil {
+		t.Fatal(err)
+	}
+	var third cachedUser
+	db.CachedQuery(ctx, "user:1", time.Minute, &third, countingQuery(&calls, "carol", &third), "users")
+	if calls.Load() != 2 || third.Name != "carol" {
+		t.Errorf("Expected query after key invalidation, got %d queries and %+v", calls.Load(), third)
+	}
+
+	if err := db.InvalidateCacheTags(ctx, "users"); err != nil {
+		t.Fatal(err)
+	}
+	var fourth cachedUser
+	db.CachedQuery(ctx, "user:1", time.Minute, &fourth, countingQuery(&calls, "dave", &fourth))
+	if calls.Load() != 3 || fourth.Name != "dave" {
+		t.Errorf("Expected query after tag invalidation, got %d queries and %+v", calls.Load(), fourth)
+	}
+}
+
+func TestCachedQueryExpiresAndSkipsErrors(t *testing.T) {
+	db := &Database{}
+	WithQueryCache(QueryCacheConfig{Store: NewMemoryQueryCacheStore()})(db)
+	ctx := t.Context()
+	var calls atomic.Int32
+
+	var u cachedUser
+	db.CachedQuery(ctx, "short", 10*time.Millisecond, &u, countingQuery(&calls, "alice", &u))
+	time.Sleep(20 * time.Millisecond)
+	db.CachedQuery(ctx, "short", 10*time.Millisecond, &u, countingQuery(&calls, "alice", &u))
+	if calls.Load() != 2 {
+		t.Errorf("Expected expired entry to be re-queried, got %d queries", calls.Load())
+	}
+
+	// 查詢失敗不寫入快取
+	queryErr := errors.New("db down")
+	err := db.CachedQuery(ctx, "failing", time.Minute, &u, func(context.Context, bun.IDB) error { return queryErr })
+	if !errors.Is(err, queryErr) {
+		t.Fatalf("Expected query error, got %v", err)
+	}
+	db.CachedQuery(ctx, "failing", time.Minute, &u, countingQuery(&calls, "alice", &u))
+	if calls.Load() != 3 {
+		t.Errorf("Expected failed query not to be cached, got %d queries", calls.Load())
+	}
+}
+
+func TestCachedQueryCoalescesMisses(t *testing.T) {
+	db := &Database{}
+	WithQueryCache(QueryCacheConfig{Store: NewMemoryQueryCacheStore()})(db)
+	var calls atomic.Int32
+	release := make(chan struct{})
+
+	var wg sync.WaitGroup
+	results := make([]cachedUser, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(dest *cachedUser) {
+			defer wg.Done()
+			db.CachedQuery(context.Background(), "hot", time.Minute, dest, func(context.Context, bun.IDB) error {
+				calls.Add(1)
+				<-release
+				*dest = cachedUser{ID: 7, Name: "hot"}
+				return nil
+			})
+		}(&results[i])
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("Expected concurrent misses to share one query, got %d", calls.Load())
+	}
+	for i, u := range results {
+		if u.Name != "hot" {
+			t.Errorf("caller %d: expected shared result, got %+v", i, u)
+		}
+	}
+}
+
+func TestCachedQueryDegradesWhenRedisDown(t *testing.T) {
+	// 無法連線的 Redis：快取錯誤不回傳給呼叫端，熔斷後不再嘗試連線
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1, DialTimeout: 50 * time.Millisecond})
+	defer client.Close()
+
+	db := &Database{redisDB: client}
+	WithQueryCache(QueryCacheConfig{Breaker: resilience.BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}})(db)
+	ctx := t.Context()
+	var calls atomic.Int32
+
+	for i := 0; i < 5; i++ {
+		var u cachedUser
+		if err := db.CachedQuery(ctx, "user:1", time.Minute, &u, countingQuery(&calls, "alice", &u)); err != nil || u.Name != "alice" {
+			t.Fatalf("Expected direct query while Redis is down, got %v %+v", err, u)
+		}
+	}
+	if calls.Load() != 5 {
+		t.Errorf("Expected every call to query the database, got %d", calls.Load())
+	}
+	if state := db.queryCache().breaker.State(); state != resilience.StateOpen {
+		t.Errorf("Expected breaker open, got %v", state)
+	}
+	if err := db.InvalidateCache(ctx, "user:1"); err == nil {
+		t.Error("Expected invalidation to report the outage")
+	}
+}
+
+func TestCachedQueryWithoutStore(t *testing.T) {
+	db := &Database{}
+	var calls atomic.Int32
+	var u cachedUser
+	for i := 0; i < 2; i++ {
+		if err := db.CachedQuery(t.Context(), "k", time.Minute, &u, countingQuery(&calls, "alice", &u)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected queries to run directly without a cache store, got %d", calls.Load())
+	}
+	if err := db.InvalidateCacheTags(t.Context(), "users"); err != nil {
+		t.Errorf("Expected no-op invalidation, got %v", err)
+	}
+}
+
+func TestMemoryQueryCacheStorePrunesTags(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryQueryCacheStore()
+	tagCount := func() int {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.tags)
+	}
+
+	// 覆寫後舊 tag 不再指向該 key
+	s.Set(ctx, "k1", []byte("a"), time.Minute, []string{"users", "user:1"})
+	s.Set(ctx, "k1", []byte("b"), time.Minute, []string{"orders"})
+	if n := tagCount(); n != 1 {
+		t.Errorf("Expected overwritten tags to be dropped, got %d tags", n)
+	}
+	s.Delete(ctx, "k1")
+	if n := tagCount(); n != 0 {
+		t.Errorf("Expected Delete to drop empty tags, got %d tags", n)
+	}
+
+	// 讀取時過期
+	s.Set(ctx, "k2", []byte("c"), time.Millisecond, []string{"users"})
+	time.Sleep(5 * time.Millisecond)
+	if _, err := s.Get(ctx, "k2"); !errors.Is(err, ErrCacheMiss) {
+		t.Fatalf("Expected miss for expired entry, got %v", err)
+	}
+	if n := tagCount(); n != 0 {
+		t.Errorf("Expected expired entry to leave its tags, got %d tags", n)
+	}
+
+	// 未再讀取的過期項目於 Set 時清除
+	s.Set(ctx, "k3", []byte("d"), time.Millisecond, []string{"stale"})
+	time.Sleep(5 * time.Millisecond)
+	s.mu.Lock()
+	s.lastSweep = time.Time{}
+	s.mu.Unlock()
+	s.Set(ctx, "k4", []byte("e"), time.Minute, []string{"fresh"})
+	s.mu.Lock()
+	_, stale := s.tags["stale"]
+	entries := len(s.entries)
+	s.mu.Unlock()
+	if stale || entries != 1 {
+		t.Errorf("Expected sweep to remove expired entry and tag, got %d entries, stale=%v", entries, stale)
+	}
+}
@@ -2,6 +2,7 @@
 package hidb
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"sync"
@@ -9,13 +10,19 @@ import (
 	"time"
 
 	"github.com/maoxiaoyue/hypgo/pkg/config"
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
 	"github.com/uptrace/bun"
 )
 
+// ReplicaBreaker 每個讀取副本的熔斷器配置（Name 由副本池填入 replica-N）
+// 副本連續查詢失敗達門檻即暫時移出輪詢，全部熔斷時讀取回退到主庫；應在初始化資料庫前設定
+var ReplicaBreaker = resilience.BreakerConfig{}
+
 // ReadReplica 讀取副本連接
 type ReadReplica struct {
-	sqlDB *sql.DB
-	hypDB *bun.DB
+	sqlDB   *sql.DB
+	hypDB   *bun.DB
+	breaker *resilience.CircuitBreaker
 }
 
 // ReplicaPool 讀取副本連接池（支持輪詢負載均衡）
@@ -37,37 +44,72 @@ func NewReplicaPool() *ReplicaPool {
 
 // Add 添加讀取副本
 // 寫操作：使用 Mutex 保護，copy-on-write 更新 atomic.Pointer
+// 為副本掛上熔斷器：經由 HypDB ORM 執行的查詢結果會回報給熔斷器
 func (rp *ReplicaPool) Add(replica ReadReplica) {
 	rp.mu.Lock()
 	defer rp.mu.Unlock()
 
 	old := rp.replicas.Load()
+	if replica.breaker == nil {
+		cfg := ReplicaBreaker
+		cfg.Name = fmt.Sprintf("replica-%d", len(*old))
+		replica.breaker = resilience.NewCircuitBreaker(cfg)
+		if replica.hypDB != nil {
+			replica.hypDB.AddQueryHook(replicaBreakerHook{breaker: replica.breaker})
+		}
+	}
 	newSlice := make([]ReadReplica, len(*old)+1)
 	copy(newSlice, *old)
 	newSlice[len(*old)] = replica
 	rp.replicas.Store(&newSlice)
 }
 
-// Next 獲取下一個讀取副本的 HypDB ORM 實例（輪詢）
+// Next 獲取下一個讀取副本的 HypDB ORM 實例（輪詢，跳過熔斷中的副本）
+// 全部副本熔斷時返回 nil，由呼叫端回退到主庫
 // GC 優化：讀路徑完全無鎖，使用 atomic.Pointer 讀取
 func (rp *ReplicaPool) Next() *bun.DB {
-	replicas := *rp.replicas.Load()
-	if len(replicas) == 0 {
-		return nil
+	if replica := rp.next(); replica != nil {
+		return replica.hypDB
 	}
-	idx := rp.counter.Add(1) - 1
-	return replicas[idx%uint64(len(replicas))].hypDB
+	return nil
 }
 
-// NextSQL 獲取下一個讀取副本的原始 SQL 連接（輪詢）
+// NextSQL 獲取下一個讀取副本的原始 SQL 連接（輪詢，跳過熔斷中的副本）
+// 原始 SQL 查詢不經過查詢 hook，結果不會回報給熔斷器
 // GC 優化：讀路徑完全無鎖
 func (rp *ReplicaPool) NextSQL() *sql.DB {
+	if replica := rp.next(); replica != nil {
+		return replica.sqlDB
+	}
+	return nil
+}
+
+// next 從輪詢位置起找出第一個熔斷器放行的副本
+func (rp *ReplicaPool) next() *ReadReplica {
 	replicas := *rp.replicas.Load()
-	if len(replicas) == 0 {
+	n := uint64(len(replicas))
+	if n == 0 {
 		return nil
 	}
 	idx := rp.counter.Add(1) - 1
-	return replicas[idx%uint64(len(replicas))].sqlDB
+	for i := uint64(0); i < n; i++ {
+		replica := &replicas[(idx+i)%n]
+		if replica.breaker == nil || replica.breaker.Allow() == nil {
+			return replica
+		}
+	}
+	return nil
+}
+
+// Available 返回目前未熔斷的副本數量
+func (rp *ReplicaPool) Available() int {
+	available := 0
+	for _, replica := range *rp.replicas.Load() {
+		if replica.breaker == nil || replica.breaker.State() != resilience.StateOpen {
+			available++
+		}
+	}
+	return available
 }
 
 // Len 返回副本數量
@@ -100,7 +142,11 @@ func (rp *ReplicaPool) PingAll() []error {
 	var errs []error
 	for i, replica := range replicas {
 		if replica.sqlDB != nil {
-			if err := replica.sqlDB.Ping(); err != nil {
+			err := replica.sqlDB.Ping()
+			if replica.breaker != nil {
+				replica.breaker.Record(err)
+			}
+			if err != nil {
 				errs = append(errs, fmt.Errorf("read replica %d unhealthy: %w", i, err))
 			}
 		}
@@ -144,3 +190,18 @@ func initReplica(dialect Dialect, replicaCfg config.ReplicaConfig) (ReadReplica,
 		hypDB: hypDB,
 	}, nil
 }
+
+// replicaBreakerHook 將副本的查詢結果回報給熔斷器
+type replicaBreakerHook struct {
+	breaker *resilience.CircuitBreaker
+}
+
+var _ bun.QueryHook = replicaBreakerHook{}
+
+func (h replicaBreakerHook) BeforeQuery(ctx context.Context, _ *bun.QueryEvent) context.Context {
+	return ctx
+}
+
+func (h replicaBreakerHook) AfterQuery(_ context.Context, e *bun.QueryEvent) {
+	h.breaker.Record(e.Err)
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
 	"github.com/uptrace/bun"
 )
 
@@ -286,3 +287,32 @@ func TestReplicaPoolSingleReplica(t *testing.T) {
 		}
 	}
 }
+
+func TestReplicaPoolSkipsOpenBreaker(t *testing.T) {
+	pool := NewReplicaPool()
+	for i := 0; i < 2; i++ {
+		pool.Add(newMockReplica(i))
+	}
+	replicas := *pool.replicas.Load()
+	errDown := fmt.Errorf("connection refused")
+
+	for i := 0; i < resilience.DefaultFailureThreshold; i++ {
+		replicas[0].breaker.Record(errDown)
+	}
+	if pool.Available() != 1 {
+		t.Fatalf("expected 1 available replica, got %d", pool.Available())
+	}
+	for i := 0; i < 4; i++ {
+		if got := pool.Next(); got != replicas[1].hypDB {
+			t.Fatalf("call %d: expected the healthy replica", i)
+		}
+	}
+
+	// 全部熔斷時返回 nil，由 Database 回退到主庫
+	for i := 0; i < resilience.DefaultFailureThreshold; i++ {
+		replicas[1].breaker.Record(errDown)
+	}
+	if got := pool.Next(); got != nil {
+		t.Errorf("expected nil when every replica is open, got %v", got)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/maoxiaoyue/hypgo/pkg/cache"
 	"github.com/maoxiaoyue/hypgo/pkg/resource"
 	"github.com/redis/go-redis/v9"
 )
@@ -314,6 +315,16 @@ func (d *Database) RedisScan(ctx context.Context, cursor uint64, match string, c
 	return d.redisDB.Scan(ctx, cursor, match, count).Result()
 }
 
+// --- 分散式鎖 ---
+
+// RedisLock 取得 key 的分散式鎖（見 cache.Lock），已被佔用時 acquired 為 false
+func (d *Database) RedisLock(ctx context.Context, key string, ttl time.Duration, opts ...cache.LockOption) (unlock func(), acquired bool, err error) {
+	if d.redisDB == nil {
+		return func() {}, false, fmt.Errorf("redis not initialized")
+	}
+	return cache.Lock(ctx, d.redisDB, key, ttl, opts...)
+}
+
 // --- 輔助方法 ---
 
 // RedisIsNil 檢查 error 是否為 redis.Nil（key 不存在）
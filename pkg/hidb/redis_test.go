@@ -2,6 +2,7 @@ package hidb
 
 import (
 	"testing"
+	"time"
 
 	"github.com/redis/go-redis/v9"
 )
@@ -114,6 +115,9 @@ func TestRedisNilGuard(t *testing.T) {
 	if _, _, err := db.RedisScan(ctx, 0, "*", 10); err == nil {
 		t.Error("RedisScan should error")
 	}
+	if _, ok, err := db.RedisLock(ctx, "key", time.Second); err == nil || ok {
+		t.Error("RedisLock should error")
+	}
 }
 
 // TestRedisIsNil 驗證 RedisIsNil 工具函式
@@ -0,0 +1,127 @@
+// @chris
+package hidb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+	"github.com/redis/go-redis/v9"
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TraceQueryText 是否在 span 記錄完整 SQL
+// bun 會將參數值內嵌於語句中，預設關閉以免敏感資料流入追蹤後端
+var TraceQueryText = false
+
+// maxTracedQueryLen 記錄 SQL 時的最大長度
+const maxTracedQueryLen = 2048
+
+// WithTracing 為 SQL（含讀取副本）與 Redis 查詢建立子 span
+// 需搭配 tracing.Setup 與 middleware.Tracing，並以 c.StdContext() 傳入查詢
+func WithTracing() Option {
+	return func(db *Database) {
+		db.tracing = true
+	}
+}
+
+// ===== SQL =====
+
+// queryTracingHook bun 查詢 hook，每條查詢一個 client span
+type queryTracingHook struct {
+	system attribute.KeyValue
+}
+
+var _ bun.QueryHook = queryTracingHook{}
+
+func newQueryTracingHook(driverName string) queryTracingHook {
+	switch driverName {
+	case "postgres", "pgx":
+		return queryTracingHook{system: semconv.DBSystemNamePostgreSQL}
+	case "mysql":
+		return queryTracingHook{system: semconv.DBSystemNameMySQL}
+	default:
+		return queryTracingHook{system: semconv.DBSystemNameKey.String(driverName)}
+	}
+}
+
+func (h queryTracingHook) BeforeQuery(ctx context.Context, e *bun.QueryEvent) context.Context {
+	operation := e.Operation()
+	attrs := []attribute.KeyValue{h.system, semconv.DBOperationName(operation)}
+	if TraceQueryText {
+		query := e.Query
+		if len(query) > maxTracedQueryLen {
+			query = query[:maxTracedQueryLen]
+		}
+		attrs = append(attrs, semconv.DBQueryText(query))
+	}
+
+	ctx, _ = tracing.Tracer().Start(ctx, operation,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+	return ctx
+}
+
+func (h queryTracingHook) AfterQuery(ctx context.Context, e *bun.QueryEvent) {
+	span := trace.SpanFromContext(ctx)
+	if e.Err != nil && !errors.Is(e.Err, sql.ErrNoRows) {
+		span.RecordError(e.Err)
+		span.SetStatus(codes.Error, e.Err.Error())
+	}
+	span.End()
+}
+
+// ===== Redis =====
+
+// redisTracingHook redis client hook，每條命令（或每個 pipeline）一個 client span
+type redisTracingHook struct{}
+
+func (redisTracingHook) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (redisTracingHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		operation := strings.ToUpper(cmd.Name())
+		ctx, span := tracing.Tracer().Start(ctx, operation,
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(semconv.DBSystemNameRedis, semconv.DBOperationName(operation)),
+		)
+		defer span.End()
+
+		err := next(ctx, cmd)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+func (redisTracingHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		ctx, span := tracing.Tracer().Start(ctx, "PIPELINE",
+			trace.WithSpanKind(trace.SpanKindClient),
+			trace.WithAttributes(
+				semconv.DBSystemNameRedis,
+				semconv.DBOperationName("PIPELINE"),
+				semconv.DBOperationBatchSize(len(cmds)),
+			),
+		)
+		defer span.End()
+
+		err := next(ctx, cmds)
+		recordRedisError(span, err)
+		return err
+	}
+}
+
+// recordRedisError 記錄錯誤；redis.Nil（key 不存在）不視為錯誤
+func recordRedisError(span trace.Span, err error) {
+	if err != nil && !errors.Is(err, redis.Nil) {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
@@ -0,0 +1,45 @@
+package hidb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/uptrace/bun"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestQueryTracingHook(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	defer otel.SetTracerProvider(prev)
+
+	hook := newQueryTracingHook("postgres")
+
+	e := &bun.QueryEvent{Query: "SELECT * FROM users WHERE id = 1", Err: sql.ErrNoRows}
+	hook.AfterQuery(hook.BeforeQuery(context.Background(), e), e)
+
+	e = &bun.QueryEvent{Query: "UPDATE users SET name = 'x'", Err: errors.New("deadlock")}
+	hook.AfterQuery(hook.BeforeQuery(context.Background(), e), e)
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(spans))
+	}
+	if spans[0].Name() != "SELECT" || spans[0].Status().Code == codes.Error {
+		t.Errorf("Expected SELECT span without error (ErrNoRows), got %q %v", spans[0].Name(), spans[0].Status())
+	}
+	if spans[1].Name() != "UPDATE" || spans[1].Status().Code != codes.Error {
+		t.Errorf("Expected UPDATE span with error, got %q %v", spans[1].Name(), spans[1].Status())
+	}
+	for _, kv := range spans[0].Attributes() {
+		if kv.Key == "db.query.text" {
+			t.Error("Expected query text to be omitted by default")
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package httpclient 提供呼叫下游服務用的 *http.Client
+// 自動將目前請求的 request id 與 W3C traceparent 帶到外送請求，並套用逾時與冪等方法的重試，
+// 讓跨服務呼叫仍能以同一個 request id / trace 串接日誌與 span。
+//
+// EX：
+//
+//	client := httpclient.New(httpclient.Config{Timeout: 5 * time.Second, Retries: 2})
+//	r.GET("/orders/:id", func(c *context.Context) {
+//	    req, _ := http.NewRequestWithContext(c, http.MethodGet, inventoryURL+"/items/"+c.Param("id"), nil)
+//	    resp, err := client.Do(req) // 帶上 X-Request-ID 與 traceparent
+//	    ...
+//	})
+//
+// @chris
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Config 下游呼叫配置，零值欄位使用預設值；可直接嵌入應用的配置檔
+type Config struct {
+	// Timeout 單次呼叫（含重試與讀取 body）的總期限，預設 10s；-1 停用
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	// Retries 失敗後的重試次數，預設 0（不重試）
+	// 僅重試冪等方法（GET、HEAD、OPTIONS、PUT、DELETE）且 body 可重送的請求，
+	// 條件為連線錯誤或 502 / 503 / 504 回應
+	Retries int `mapstructure:"retries" yaml:"retries"`
+	// RetryBackoff 第一次重試前的等待時間，之後指數成長，預設 100ms
+	RetryBackoff time.Duration `mapstructure:"retry_backoff" yaml:"retry_backoff"`
+	// RequestIDHeader 傳遞 request id 的標頭，預設 X-Request-ID
+	RequestIDHeader string `mapstructure:"request_id_header" yaml:"request_id_header"`
+	// Transport 底層 RoundTripper，預設 http.DefaultTransport
+	Transport http.RoundTripper `mapstructure:"-" yaml:"-"`
+	// TracerProvider 建立 client span 的 provider，預設使用全域 tracing.Tracer()
+	TracerProvider trace.TracerProvider `mapstructure:"-" yaml:"-"`
+	// Propagator 寫入追蹤標頭的 propagator，預設使用全域 tracing.Propagator()（Setup 後為 W3C traceparent）
+	Propagator propagation.TextMapPropagator `mapstructure:"-" yaml:"-"`
+}
+
+// New 建立帶有關聯標頭注入、逾時與重試的 *http.Client
+func New(cfg Config) *http.Client {
+	timeout := cfg.Timeout
+	switch {
+	case timeout == 0:
+		timeout = 10 * time.Second
+	case timeout < 0:
+		timeout = 0
+	}
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewTransport(cfg),
+	}
+}
+
+// Transport 注入 request id 與追蹤標頭並負責重試的 http.RoundTripper
+type Transport struct {
+	base       http.RoundTripper
+	header     string
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+	retry      resilience.RetryConfig
+	attempts   int
+}
+
+// NewTransport 建立 Transport，可用於包裝既有 http.Client 的 Transport
+func NewTransport(cfg Config) *Transport {
+	t := &Transport{
+		base:     cfg.Transport,
+		header:   cfg.RequestIDHeader,
+		attempts: cfg.Retries + 1,
+	}
+	if t.base == nil {
+		t.base = http.DefaultTransport
+	}
+	if t.header == "" {
+		t.header = hypcontext.HeaderXRequestID
+	}
+	if t.attempts < 1 {
+		t.attempts = 1
+	}
+	if cfg.TracerProvider != nil {
+		t.tracer = cfg.TracerProvider.Tracer(tracing.InstrumentationName)
+	} else {
+		t.tracer = tracing.Tracer()
+	}
+	t.propagator = cfg.Propagator
+	if t.propagator == nil {
+		t.propagator = tracing.Propagator()
+	}
+	t.retry = resilience.RetryConfig{
+		Attempts:       t.attempts,
+		InitialBackoff: cfg.RetryBackoff,
+		RetryIf:        isRetryable,
+	}
+	return t
+}
+
+// errRetryableStatus 502 / 503 / 504 回應，轉為錯誤以觸發重試
+type errRetryableStatus struct{ code int }
+
+func (e *errRetryableStatus) Error() string {
+	return fmt.Sprintf("upstream responded %d", e.code)
+}
+
+// RoundTrip 實作 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	ctx, span := t.tracer.Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPRequestMethodKey.String(req.Method),
+			semconv.URLFull(req.URL.Redacted()),
+			semconv.ServerAddress(req.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	// RoundTripper 不應修改呼叫端的請求，標頭寫在複本上
+	out := req.Clone(ctx)
+	if id := RequestID(ctx); id != "" && out.Header.Get(t.header) == "" {
+		out.Header.Set(t.header, id)
+	}
+	t.propagator.Inject(ctx, propagation.HeaderCarrier(out.Header))
+
+	resp, err := t.roundTripWithRetry(ctx, out)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return nil, err
+	}
+	span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+	}
+	return resp, nil
+}
+
+// roundTripWithRetry 冪等且 body 可重送的請求才重試，最後一次的回應原樣回傳
+func (t *Transport) roundTripWithRetry(ctx context.Context, req *http.Request) (*http.Response, error) {
+	if t.attempts == 1 || !canRetry(req) {
+		return t.base.RoundTrip(req)
+	}
+
+	var resp *http.Response
+	attempt := 0
+	err := resilience.Retry(ctx, t.retry, func(ctx context.Context) error {
+		attempt++
+		r := req
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return resilience.Permanent(err)
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+
+		res, err := t.base.RoundTrip(r)
+		if err != nil {
+			return err
+		}
+		if isRetryableStatus(res.StatusCode) && attempt < t.attempts {
+			// 讀完並關閉 body 讓連線可重用
+			io.Copy(io.Discard, io.LimitReader(res.Body, 64<<10))
+			res.Body.Close()
+			return &errRetryableStatus{code: res.StatusCode}
+		}
+		resp = res
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// RequestID 取得 ctx 所屬請求的 request id
+// ctx 可為 *context.Context、其 StdContext()，或任何帶有 "request_id" 值的 context；
+// 都沒有時退回 HypGo Context 的 X-Request-ID 請求標頭
+func RequestID(ctx context.Context) string {
+	if id, ok := ctx.Value("request_id").(string); ok && id != "" {
+		return id
+	}
+	if c, ok := hypcontext.FromContext(ctx); ok && c.Request != nil {
+		return c.Request.Header.Get(hypcontext.HeaderXRequestID)
+	}
+	return ""
+}
+
+// canRetry 冪等方法且 body 為空或可透過 GetBody 重新取得
+func canRetry(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		return false
+	}
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func isRetryableStatus(code int) bool {
+	return code == http.StatusBadGateway || code == http.StatusServiceUnavailable || code == http.StatusGatewayTimeout
+}
+
+// isRetryable 連線錯誤與 502 / 503 / 504 可重試；ctx 結束時不重試
+func isRetryable(err error) bool {
+	var status *errRetryableStatus
+	if errors.As(err, &status) {
+		return true
+	}
+	return resilience.DefaultRetryIf(err)
+}
@@ -0,0 +1,109 @@
+package httpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/middleware"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestInjectsRequestIDAndTraceContext(t *testing.T) {
+	var gotID, gotTraceparent string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = r.Header.Get("X-Request-ID")
+		gotTraceparent = r.Header.Get("traceparent")
+	}))
+	defer downstream.Close()
+
+	sr := tracetest.NewSpanRecorder()
+	client := New(Config{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)),
+		Propagator:     propagation.TraceContext{},
+	})
+
+	r := router.New()
+	r.Use(middleware.RequestID(middleware.RequestIDConfig{}))
+	r.GET("/orders", func(c *context.Context) {
+		req, _ := http.NewRequestWithContext(c, http.MethodGet, downstream.URL, nil)
+		resp, err := client.Do(req)
+		if err != nil {
+			c.AbortWithError(http.StatusBadGateway, err)
+			return
+		}
+		resp.Body.Close()
+		if req.Header.Get("X-Request-ID") != "" {
+			t.Error("caller's request must not be modified")
+		}
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest("GET", "/orders", nil)
+	req.Header.Set("X-Request-ID", "req-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotID != "req-123" {
+		t.Errorf("X-Request-ID = %q, want req-123", gotID)
+	}
+	spans := sr.Ended()
+	if len(spans) != 1 || !strings.Contains(gotTraceparent, spans[0].SpanContext().TraceID().String()) {
+		t.Errorf("traceparent %q does not carry the client span", gotTraceparent)
+	}
+}
+
+func TestRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer downstream.Close()
+
+	client := New(Config{Retries: 2, RetryBackoff: time.Millisecond})
+	resp, err := client.Get(downstream.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || calls.Load() != 3 {
+		t.Errorf("status %d after %d calls, want 200 after 3", resp.StatusCode, calls.Load())
+	}
+
+	// 重試用盡時回傳最後一次的回應；POST 不重試
+	calls.Store(-10)
+	resp, err = client.Get(downstream.URL)
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != -7 {
+		t.Errorf("expected last 503 after 3 calls, got %v %v calls=%d", resp, err, calls.Load())
+	}
+	resp.Body.Close()
+
+	calls.Store(0)
+	resp, err = client.Post(downstream.URL, "text/plain", strings.NewReader("x"))
+	if err != nil || resp.StatusCode != http.StatusServiceUnavailable || calls.Load() != 1 {
+		t.Errorf("POST should not be retried, got %v calls=%d", err, calls.Load())
+	}
+	resp.Body.Close()
+}
+
+func TestTimeout(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+	}))
+	defer downstream.Close()
+
+	client := New(Config{Timeout: 20 * time.Millisecond})
+	if _, err := client.Get(downstream.URL); err == nil {
+		t.Error("expected timeout error")
+	}
+}
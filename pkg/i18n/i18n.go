@@ -0,0 +1,195 @@
+// Package i18n 提供訊息翻譯檔（JSON / YAML）的載入與查詢
+// Bundle 實作 context.Translator，搭配 middleware.Localization 後可在 handler 以 c.T(key, args...) 取得翻譯。
+//
+// @chris
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Bundle 各語系的訊息表，並行安全
+// 查詢順序：完整語系（zh-TW）→ 主要語言（zh）→ 預設語系 → key 本身
+type Bundle struct {
+	mu            sync.RWMutex
+	defaultLocale string
+	messages      map[string]map[string]string // locale -> key -> message
+}
+
+// NewBundle 建立以 defaultLocale 為最終後備的 Bundle
+func NewBundle(defaultLocale string) *Bundle {
+	return &Bundle{
+		defaultLocale: defaultLocale,
+		messages:      make(map[string]map[string]string),
+	}
+}
+
+// DefaultLocale 預設語系
+func (b *Bundle) DefaultLocale() string {
+	return b.defaultLocale
+}
+
+// Add 加入 locale 的訊息，與既有 key 重複時覆寫
+// 巢狀 map 以 . 連接為扁平 key，例如 {"user": {"not_found": "..."}} 為 "user.not_found"
+func (b *Bundle) Add(locale string, messages map[string]interface{}) {
+	flat := make(map[string]string)
+	flatten("", messages, flat)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := normalizeLocale(locale)
+	if b.messages[key] == nil {
+		b.messages[key] = make(map[string]string, len(flat))
+	}
+	for k, v := range flat {
+		b.messages[key][k] = v
+	}
+}
+
+// LoadFile 載入單一翻譯檔，語系取自檔名（locales/zh-TW.yaml → zh-TW）
+// 支援 .json、.yaml、.yml
+func (b *Bundle) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var messages map[string]interface{}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, &messages)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &messages)
+	default:
+		return fmt.Errorf("i18n: unsupported file type %q: %s", ext, path)
+	}
+	if err != nil {
+		return fmt.Errorf("i18n: parse %s: %w", path, err)
+	}
+
+	locale := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	b.Add(locale, messages)
+	return nil
+}
+
+// LoadDir 載入目錄下所有 .json / .yaml / .yml 翻譯檔（不遞迴）
+//
+// EX：
+//
+//	bundle := i18n.NewBundle("en")
+//	if err := bundle.LoadDir("locales"); err != nil {
+//	    log.Fatal(err)
+//	}
+func (b *Bundle) LoadDir(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".json", ".yaml", ".yml":
+			if err := b.LoadFile(filepath.Join(dir, e.Name())); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Locales 已載入訊息的語系（正規化為小寫、以 - 分隔），依字母排序
+func (b *Bundle) Locales() []string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	locales := make([]string, 0, len(b.messages))
+	for l := range b.messages {
+		locales = append(locales, l)
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Translate 查詢 locale 的 key 訊息，args 非空時以 fmt.Sprintf 格式化；所有後備皆無時回傳 key
+func (b *Bundle) Translate(locale, key string, args ...interface{}) string {
+	msg, ok := b.lookup(locale, key)
+	if !ok {
+		msg = key
+	}
+	if len(args) > 0 {
+		return fmt.Sprintf(msg, args...)
+	}
+	return msg
+}
+
+// Has 是否存在 locale（含後備）的 key 訊息
+func (b *Bundle) Has(locale, key string) bool {
+	_, ok := b.lookup(locale, key)
+	return ok
+}
+
+func (b *Bundle) lookup(locale, key string) (string, bool) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, l := range fallbacks(normalizeLocale(locale), normalizeLocale(b.defaultLocale)) {
+		if msg, ok := b.messages[l][key]; ok {
+			return msg, true
+		}
+	}
+	return "", false
+}
+
+// fallbacks 語系的查詢順序，例如 zh-tw → zh → en
+func fallbacks(locale, defaultLocale string) []string {
+	chain := make([]string, 0, 4)
+	add := func(l string) {
+		if l == "" {
+			return
+		}
+		for _, existing := range chain {
+			if existing == l {
+				return
+			}
+		}
+		chain = append(chain, l)
+	}
+	for _, l := range []string{locale, defaultLocale} {
+		add(l)
+		if base, _, ok := strings.Cut(l, "-"); ok {
+			add(base)
+		}
+	}
+	return chain
+}
+
+// normalizeLocale 統一大小寫與分隔符號（zh_TW、zh-tw → zh-tw），僅用於內部查表
+func normalizeLocale(locale string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"))
+}
+
+// flatten 將巢狀 map 攤平成以 . 連接的 key
+func flatten(prefix string, in map[string]interface{}, out map[string]string) {
+	for k, v := range in {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		switch val := v.(type) {
+		case map[string]interface{}:
+			flatten(key, val, out)
+		case string:
+			out[key] = val
+		default:
+			out[key] = fmt.Sprint(val)
+		}
+	}
+}
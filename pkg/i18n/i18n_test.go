@@ -0,0 +1,59 @@
+package i18n
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadDirAndTranslate(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "en.json"), []byte(`{"greeting":"Hello, %s","user":{"not_found":"User %d not found"},"only_en":"fallback"}`), 0644)
+	os.WriteFile(filepath.Join(dir, "zh-TW.yaml"), []byte("greeting: 你好，%s\nuser:\n  not_found: 找不到使用者 %d\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "zh.yml"), []byte("only_zh: 中文\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "README.md"), []byte("ignored"), 0644)
+
+	b := NewBundle("en")
+	if err := b.LoadDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if got := b.Locales(); !reflect.DeepEqual(got, []string{"en", "zh", "zh-tw"}) {
+		t.Errorf("unexpected locales %v", got)
+	}
+
+	tests := []struct {
+		locale, key string
+		args        []interface{}
+		want        string
+	}{
+		{"zh-TW", "greeting", []interface{}{"Amy"}, "你好，Amy"},
+		{"zh_tw", "user.not_found", []interface{}{7}, "找不到使用者 7"},
+		{"zh-TW", "only_zh", nil, "中文"},       // 主要語言後備
+		{"zh-TW", "only_en", nil, "fallback"}, // 預設語系後備
+		{"ja", "greeting", []interface{}{"Ken"}, "Hello, Ken"},
+		{"en", "missing.key", nil, "missing.key"},
+	}
+	for _, tt := range tests {
+		if got := b.Translate(tt.locale, tt.key, tt.args...); got != tt.want {
+			t.Errorf("Translate(%s, %s) = %q, want %q", tt.locale, tt.key, got, tt.want)
+		}
+	}
+	if b.Has("ja", "missing.key") || !b.Has("ja", "greeting") {
+		t.Error("unexpected Has result")
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "en.json")
+	os.WriteFile(bad, []byte(`{"greeting":`), 0644)
+
+	b := NewBundle("en")
+	if err := b.LoadFile(bad); err == nil {
+		t.Error("expected parse error")
+	}
+	if err := b.LoadFile(filepath.Join(dir, "en.toml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,47 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleNext(t *testing.T) {
+	base := time.Date(2026, 1, 30, 10, 17, 30, 0, time.UTC) // 週五
+	tests := []struct {
+		spec string
+		want string
+	}{
+		{"*/15 * * * *", "2026-01-30T10:30:00Z"},
+		{"0 3 * * *", "2026-01-31T03:00:00Z"},
+		{"@hourly", "2026-01-30T11:00:00Z"},
+		{"30 9 * * mon-fri", "2026-02-02T09:30:00Z"},
+		{"0 0 1 feb *", "2026-02-01T00:00:00Z"},
+		{"0 12 * * 7", "2026-02-01T12:00:00Z"},
+		{"0 0 13 * 5", "2026-02-06T00:00:00Z"}, // 日與週都有限定時任一符合
+		{"5,10 8-9/1 * * *", "2026-01-31T08:05:00Z"},
+		{"@every 90s", "2026-01-30T10:18:00Z"},
+		{"@every 1h", "2026-01-30T11:00:00Z"},
+	}
+	for _, tt := range tests {
+		s, err := ParseSchedule(tt.spec)
+		if err != nil {
+			t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+		}
+		if got := s.Next(base).Format(time.RFC3339); got != tt.want {
+			t.Errorf("%q: Next = %s, want %s", tt.spec, got, tt.want)
+		}
+	}
+
+	s, _ := ParseSchedule("0 0 30 2 *")
+	if next := s.Next(base); !next.IsZero() {
+		t.Errorf("Expected no run for Feb 30, got %s", next)
+	}
+}
+
+func TestParseScheduleErrors(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "* * * foo *", "@every 10ms", "@every soon"} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,493 @@
+// Package jobs 提供背景工作排程：立即（Enqueue）、延遲（Delay / At）與 cron 週期（Schedule）工作
+// 由固定數量的 worker 執行，每個工作獨立回收 panic，失敗可依設定重試，關閉時排空已到期的工作。
+// 佇列預設在記憶體中，需要跨重啟保存或多實例共用時改用 NewRedisQueue。
+//
+// @chris
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
+)
+
+// ErrClosed 排程器已關閉
+var ErrClosed = errors.New("jobs: scheduler closed")
+
+// Job 一個待執行的工作，欄位會序列化保存於佇列中
+type Job struct {
+	ID         string          `json:"id"`
+	Name       string          `json:"name"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Attempt    int             `json:"attempt"` // 已失敗的次數
+	MaxRetries int             `json:"max_retries,omitempty"`
+	Unique     string          `json:"unique,omitempty"`
+	RunAt      time.Time       `json:"run_at"`
+	EnqueuedAt time.Time       `json:"enqueued_at"`
+}
+
+// Decode 將 Payload 解碼到 v
+func (j *Job) Decode(v interface{}) error {
+	if len(j.Payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return fmt.Errorf("jobs: decode %s payload: %w", j.Name, err)
+	}
+	return nil
+}
+
+// Handler 工作處理函數；回傳 resilience.Permanent(err) 表示不需重試
+type Handler func(ctx context.Context, job *Job) error
+
+// ===== 配置 =====
+
+// Config 排程器配置，零值欄位使用預設值
+type Config struct {
+	// Queue 工作佇列，預設 NewMemoryQueue()
+	Queue Queue
+	// Workers 同時執行的工作數上限，預設 runtime.NumCPU()
+	Workers int
+	// PollInterval 閒置 worker 檢查佇列的間隔，預設 1s
+	// 本實例加入的工作會立即喚醒 worker，此間隔影響的是其他實例加入 Redis 佇列的工作
+	PollInterval time.Duration
+	// JobTimeout 單一工作的執行期限，預設 0（不限）
+	JobTimeout time.Duration
+	// MaxRetries 失敗後的預設重試次數，預設 0；可用 Retries 逐一覆寫
+	MaxRetries int
+	// RetryBackoff 第一次重試前的等待時間，之後指數成長（上限 10 分鐘），預設 1s
+	RetryBackoff time.Duration
+	// Location cron 排程使用的時區，預設 time.Local
+	Location *time.Location
+	// Logger 記錄失敗與佇列錯誤，nil 時不記錄
+	Logger *logger.Logger
+	// OnError 工作最終失敗（重試用盡或不可重試）時呼叫，於 worker goroutine 執行
+	OnError func(job *Job, err error)
+	// Locker 多實例部署時的分散式鎖（如 cache.NewLocker(rdb)）
+	// 設定後週期工作每次觸發只有取得鎖的實例會加入工作，各實例使用記憶體佇列時仍只執行一次
+	Locker Locker
+}
+
+// Locker 分散式鎖，acquired 為 false 表示已由其他實例持有
+type Locker interface {
+	Lock(ctx context.Context, key string, ttl time.Duration) (unlock func(), acquired bool, err error)
+}
+
+// EnqueueOption 加入工作的選項
+type EnqueueOption func(*Job)
+
+// Delay 延遲 d 後執行
+func Delay(d time.Duration) EnqueueOption {
+	return func(j *Job) { j.RunAt = time.Now().Add(d) }
+}
+
+// At 於 t 執行
+func At(t time.Time) EnqueueOption {
+	return func(j *Job) { j.RunAt = t }
+}
+
+// Retries 失敗後最多重試 n 次，覆寫 Config.MaxRetries
+func Retries(n int) EnqueueOption {
+	return func(j *Job) { j.MaxRetries = n }
+}
+
+// Unique 以 key 去重：保留期限（24 小時）內相同 key 的工作只加入一次，多實例共用 Redis 佇列時亦同
+func Unique(key string) EnqueueOption {
+	return func(j *Job) { j.Unique = key }
+}
+
+// ===== 排程器 =====
+
+// Scheduler 背景工作排程器，並行安全
+type Scheduler struct {
+	cfg   Config
+	queue Queue
+	retry resilience.RetryConfig
+
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	schedules []*recurring
+	started   bool
+	closed    bool
+
+	wake     chan struct{}
+	stopping chan struct{} // 關閉時 close：停止 cron 並在佇列無到期工作後結束 worker
+	abort    chan struct{} // 排空逾時時 close：不再取出新工作
+	jobCtx   context.Context
+	cancel   context.CancelFunc
+	workers  sync.WaitGroup
+
+	metrics metrics
+}
+
+// recurring Schedule 註冊的週期工作
+type recurring struct {
+	schedule *Schedule
+	name     string
+	payload  interface{}
+	opts     []EnqueueOption
+}
+
+// New 建立排程器，Start 後開始執行工作
+//
+// EX：
+//
+//	sched := jobs.New(jobs.Config{Workers: 4, MaxRetries: 3, Logger: log})
+//	sched.Register("report.generate", func(ctx context.Context, job *jobs.Job) error {
+//	    var req ReportRequest
+//	    if err := job.Decode(&req); err != nil {
+//	        return resilience.Permanent(err)
+//	    }
+//	    return reports.Generate(ctx, req)
+//	})
+//	sched.Register("sessions.cleanup", cleanupSessions)
+//	sched.Schedule("0 3 * * *", "sessions.cleanup", nil)
+//
+//	srv.OnStart("jobs", func(context.Context) error { return sched.Start() })
+//	srv.OnShutdown(sched.Shutdown)
+//
+//	sched.Enqueue(ctx, "report.generate", ReportRequest{Month: "2026-01"}, jobs.Delay(time.Minute))
+func New(cfg Config) *Scheduler {
+	if cfg.Queue == nil {
+		cfg.Queue = NewMemoryQueue()
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = runtime.NumCPU()
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = time.Second
+	}
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cfg:      cfg,
+		queue:    cfg.Queue,
+		retry:    resilience.RetryConfig{InitialBackoff: cfg.RetryBackoff, MaxBackoff: 10 * time.Minute},
+		handlers: make(map[string]Handler),
+		wake:     make(chan struct{}, cfg.Workers),
+		stopping: make(chan struct{}),
+		abort:    make(chan struct{}),
+		jobCtx:   ctx,
+		cancel:   cancel,
+		metrics:  metrics{jobs: make(map[string]*jobCounters)},
+	}
+}
+
+// Register 註冊工作名稱的處理函數；同名重複註冊會 panic
+// 使用 Redis 佇列時，所有取用同一佇列的實例都需註冊相同的名稱
+func (s *Scheduler) Register(name string, handler Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if handler == nil {
+		panic("jobs: Register handler is nil")
+	}
+	if _, dup := s.handlers[name]; dup {
+		panic("jobs: Register called twice for " + name)
+	}
+	s.handlers[name] = handler
+}
+
+// Enqueue 加入工作，payload 以 JSON 編碼保存，預設立即執行
+func (s *Scheduler) Enqueue(ctx context.Context, name string, payload interface{}, opts ...EnqueueOption) (*Job, error) {
+	s.mu.RLock()
+	closed := s.closed
+	s.mu.RUnlock()
+	if closed {
+		return nil, ErrClosed
+	}
+
+	job, err := s.newJob(name, payload, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.queue.Push(ctx, job); err != nil {
+		return nil, fmt.Errorf("jobs: enqueue %s: %w", name, err)
+	}
+	s.metrics.job(name).enqueued.Add(1)
+	s.notifyAt(job.RunAt)
+	return job, nil
+}
+
+func (s *Scheduler) newJob(name string, payload interface{}, opts []EnqueueOption) (*Job, error) {
+	now := time.Now()
+	job := &Job{
+		ID:         newID(),
+		Name:       name,
+		MaxRetries: s.cfg.MaxRetries,
+		RunAt:      now,
+		EnqueuedAt: now,
+	}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("jobs: encode %s payload: %w", name, err)
+		}
+		job.Payload = data
+	}
+	for _, opt := range opts {
+		opt(job)
+	}
+	return job, nil
+}
+
+// Schedule 依 spec（見 ParseSchedule）週期性加入工作，每次觸發的 payload 與選項相同
+// 每次觸發以工作名稱與觸發時間去重，多個實例共用 Redis 佇列或設定 Config.Locker 時同一時間點只會執行一次
+func (s *Scheduler) Schedule(spec, name string, payload interface{}, opts ...EnqueueOption) error {
+	schedule, err := ParseSchedule(spec)
+	if err != nil {
+		return err
+	}
+	r := &recurring{schedule: schedule, name: name, payload: payload, opts: opts}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return ErrClosed
+	}
+	s.schedules = append(s.schedules, r)
+	if s.started {
+		go s.runSchedule(r)
+	}
+	return nil
+}
+
+// Start 啟動 worker 與週期排程，不阻塞；重複呼叫回傳錯誤
+func (s *Scheduler) Start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case s.closed:
+		return ErrClosed
+	case s.started:
+		return errors.New("jobs: scheduler already started")
+	}
+	s.started = true
+	for i := 0; i < s.cfg.Workers; i++ {
+		s.workers.Add(1)
+		go s.work()
+	}
+	for _, r := range s.schedules {
+		go s.runSchedule(r)
+	}
+	return nil
+}
+
+// Shutdown 停止接受新工作與週期排程，等待執行中與已到期的工作完成（優雅排空），可直接註冊為 srv.OnShutdown
+// ctx 結束前未完成時取消執行中工作的 ctx 並回傳錯誤；延遲中的工作留在佇列（記憶體佇列會遺失）
+func (s *Scheduler) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ErrClosed
+	}
+	s.closed = true
+	close(s.stopping)
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		s.cancel()
+		return nil
+	case <-ctx.Done():
+		close(s.abort)
+		s.cancel()
+		return fmt.Errorf("jobs: drain: %w", ctx.Err())
+	}
+}
+
+// notifyAt 喚醒閒置的 worker；未到期的工作於到期時再喚醒
+func (s *Scheduler) notifyAt(runAt time.Time) {
+	if d := time.Until(runAt); d > 0 {
+		time.AfterFunc(d, s.notify)
+		return
+	}
+	s.notify()
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Scheduler) work() {
+	defer s.workers.Done()
+	ticker := time.NewTicker(s.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.abort:
+			return
+		default:
+		}
+
+		job, err := s.queue.Pop(s.jobCtx)
+		if err != nil && !errors.Is(err, context.Canceled) {
+			s.logf("jobs: pop: %v", err)
+		}
+		if job != nil {
+			s.run(job)
+			continue
+		}
+
+		select {
+		case <-s.stopping:
+			// 排空中且已無到期工作
+			return
+		default:
+		}
+		select {
+		case <-s.wake:
+		case <-ticker.C:
+		case <-s.stopping:
+		}
+	}
+}
+
+// run 執行工作，失敗時依設定重新排入或回報
+func (s *Scheduler) run(job *Job) {
+	s.mu.RLock()
+	handler := s.handlers[job.Name]
+	s.mu.RUnlock()
+	counters := s.metrics.job(job.Name)
+
+	s.metrics.running.Add(1)
+	start := time.Now()
+	err := s.call(handler, job, counters)
+	counters.duration.Add(int64(time.Since(start)))
+	s.metrics.running.Add(-1)
+
+	if err == nil {
+		counters.succeeded.Add(1)
+		return
+	}
+	if handler != nil && job.Attempt < job.MaxRetries && resilience.DefaultRetryIf(err) {
+		job.Attempt++
+		job.RunAt = time.Now().Add(s.retry.Backoff(job.Attempt))
+		// 重試不受 Shutdown 影響，留在佇列由下次啟動（Redis 佇列）或其他實例執行
+		pushErr := s.queue.Push(context.WithoutCancel(s.jobCtx), job)
+		if pushErr == nil {
+			counters.retried.Add(1)
+			s.logf("jobs: %s (%s) attempt %d failed, retrying at %s: %v", job.Name, job.ID, job.Attempt, job.RunAt.Format(time.RFC3339), err)
+			s.notifyAt(job.RunAt)
+			return
+		}
+		err = errors.Join(err, fmt.Errorf("jobs: requeue: %w", pushErr))
+	}
+
+	counters.failed.Add(1)
+	s.logf("jobs: %s (%s) failed: %v", job.Name, job.ID, err)
+	if s.cfg.OnError != nil {
+		s.cfg.OnError(job, err)
+	}
+}
+
+// call 執行處理函數，panic 轉為錯誤
+func (s *Scheduler) call(handler Handler, job *Job, counters *jobCounters) (err error) {
+	if handler == nil {
+		return fmt.Errorf("jobs: no handler registered for %q", job.Name)
+	}
+	ctx := s.jobCtx
+	if s.cfg.JobTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.JobTimeout)
+		defer cancel()
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			counters.panics.Add(1)
+			err = fmt.Errorf("jobs: %s panic: %v", job.Name, rec)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// runSchedule 依排程於每次觸發時加入工作，直到 Shutdown
+func (s *Scheduler) runSchedule(r *recurring) {
+	// release 釋放上一次觸發取得的鎖；TTL 本就到下次觸發為止，於此時釋放可停止 AutoRenew 等續期
+	release := func() {}
+	defer func() { release() }()
+	for {
+		now := time.Now().In(s.cfg.Location)
+		next := r.schedule.Next(now)
+		if next.IsZero() {
+			s.logf("jobs: schedule %q for %s has no next run", r.schedule, r.name)
+			return
+		}
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-s.stopping:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		release()
+		unlock, claimed := s.claimTick(r, next)
+		release = unlock
+		if !claimed {
+			continue
+		}
+		opts := append([]EnqueueOption{Unique(fmt.Sprintf("cron:%s:%d", r.name, next.Unix()))}, r.opts...)
+		if _, err := s.Enqueue(s.jobCtx, r.name, r.payload, opts...); err != nil && !errors.Is(err, ErrClosed) {
+			s.logf("jobs: schedule %s: %v", r.name, err)
+		}
+	}
+}
+
+// claimTick 以 Locker 決定由哪個實例負責這次觸發；未設定 Locker 時一律負責
+// 鎖於下次觸發（或 Shutdown）時才由呼叫端以回傳的 unlock 釋放，避免時鐘稍慢的實例在釋放後重複取得同一次觸發
+func (s *Scheduler) claimTick(r *recurring, tick time.Time) (unlock func(), claimed bool) {
+	noop := func() {}
+	if s.cfg.Locker == nil {
+		return noop, true
+	}
+	ttl := time.Minute
+	if following := r.schedule.Next(tick); !following.IsZero() {
+		ttl = following.Sub(tick)
+	}
+	key := fmt.Sprintf("jobs:cron:%s:%d", r.name, tick.Unix())
+	unlock, acquired, err := s.cfg.Locker.Lock(s.jobCtx, key, ttl)
+	if err != nil {
+		s.logf("jobs: schedule %s: lock: %v", r.name, err)
+		return noop, false
+	}
+	if !acquired || unlock == nil {
+		return noop, acquired
+	}
+	return unlock, true
+}
+
+func (s *Scheduler) logf(format string, args ...interface{}) {
+	if s.cfg.Logger != nil {
+		s.cfg.Logger.Warningf(format, args...)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
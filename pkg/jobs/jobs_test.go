@@ -0,0 +1,355 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/resilience"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestEnqueueAndDelay(t *testing.T) {
+	s := New(Config{Workers: 2, PollInterval: time.Hour})
+	var mu sync.Mutex
+	var got []string
+	s.Register("greet", func(ctx context.Context, job *Job) error {
+		var name string
+		if err := job.Decode(&name); err != nil {
+			return err
+		}
+		mu.Lock()
+		got = append(got, name)
+		mu.Unlock()
+		return nil
+	})
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Start(); err == nil {
+		t.Error("Expected error on second Start")
+	}
+
+	start := time.Now()
+	if _, err := s.Enqueue(context.Background(), "greet", "later", Delay(50*time.Millisecond)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Enqueue(context.Background(), "greet", "now"); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "both jobs", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(got) == 2
+	})
+	if got[0] != "now" || got[1] != "later" {
+		t.Errorf("Expected immediate job first, got %v", got)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Delayed job ran after %s, before its delay", elapsed)
+	}
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if _, err := s.Enqueue(context.Background(), "greet", "closed"); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after Shutdown, got %v", err)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	s := New(Config{Workers: 3})
+	var running, peak atomic.Int32
+	var done atomic.Int32
+	s.Register("work", func(ctx context.Context, job *Job) error {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		running.Add(-1)
+		done.Add(1)
+		return nil
+	})
+	for i := 0; i < 12; i++ {
+		s.Enqueue(context.Background(), "work", i)
+	}
+	s.Start()
+	waitFor(t, "all jobs", func() bool { return done.Load() == 12 })
+	s.Shutdown(context.Background())
+	if p := peak.Load(); p > 3 {
+		t.Errorf("Expected at most 3 concurrent jobs, got %d", p)
+	}
+}
+
+func TestRetryPanicAndFailure(t *testing.T) {
+	var mu sync.Mutex
+	var failed []string
+	s := New(Config{Workers: 1, MaxRetries: 2, RetryBackoff: time.Millisecond, OnError: func(job *Job, err error) {
+		mu.Lock()
+		failed = append(failed, job.Name+": "+err.Error())
+		mu.Unlock()
+	}})
+
+	var flaky atomic.Int32
+	s.Register("flaky", func(ctx context.Context, job *Job) error {
+		if flaky.Add(1) < 3 {
+			panic("not yet")
+		}
+		return nil
+	})
+	s.Register("broken", func(ctx context.Context, job *Job) error {
+		return resilience.Permanent(errors.New("bad input"))
+	})
+	s.Start()
+	s.Enqueue(context.Background(), "flaky", nil)
+	s.Enqueue(context.Background(), "broken", nil, Retries(5))
+	s.Enqueue(context.Background(), "missing", nil)
+
+	waitFor(t, "jobs to settle", func() bool {
+		stats := s.Stats()
+		return stats.Jobs["flaky"].Succeeded == 1 && stats.Jobs["broken"].Failed == 1 && stats.Jobs["missing"].Failed == 1
+	})
+	s.Shutdown(context.Background())
+
+	stats := s.Stats()
+	if js := stats.Jobs["flaky"]; js.Retried != 2 || js.Panics != 2 || js.Failed != 0 {
+		t.Errorf("Expected 2 recovered panics and retries for flaky, got %+v", js)
+	}
+	if js := stats.Jobs["broken"]; js.Retried != 0 {
+		t.Errorf("Permanent errors must not be retried, got %+v", js)
+	}
+	if len(failed) != 2 || !strings.Contains(strings.Join(failed, "\n"), `no handler registered for "missing"`) {
+		t.Errorf("Unexpected OnError reports: %v", failed)
+	}
+}
+
+func TestShutdownDrains(t *testing.T) {
+	s := New(Config{Workers: 1})
+	var done atomic.Int32
+	s.Register("slow", func(ctx context.Context, job *Job) error {
+		time.Sleep(20 * time.Millisecond)
+		done.Add(1)
+		return nil
+	})
+	for i := 0; i < 3; i++ {
+		s.Enqueue(context.Background(), "slow", nil)
+	}
+	s.Enqueue(context.Background(), "slow", nil, Delay(time.Hour))
+	s.Start()
+
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	if n := done.Load(); n != 3 {
+		t.Errorf("Expected the 3 due jobs to finish before Shutdown returned, got %d", n)
+	}
+	if n, _ := s.queue.Len(context.Background()); n != 1 {
+		t.Errorf("Expected the delayed job to stay queued, got %d", n)
+	}
+	if err := s.Shutdown(context.Background()); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed on second Shutdown, got %v", err)
+	}
+}
+
+func TestShutdownTimeoutCancelsJobs(t *testing.T) {
+	s := New(Config{Workers: 1})
+	cancelled := make(chan struct{})
+	s.Register("stuck", func(ctx context.Context, job *Job) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	})
+	s.Enqueue(context.Background(), "stuck", nil)
+	s.Start()
+	waitFor(t, "job to start", func() bool { return s.Stats().Running == 1 })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected drain timeout, got %v", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("Expected running job ctx to be cancelled")
+	}
+}
+
+func TestMemoryQueueUnique(t *testing.T) {
+	q := NewMemoryQueue()
+	ctx := context.Background()
+	q.Push(ctx, &Job{ID: "1", Unique: "cron:a:1"})
+	q.Push(ctx, &Job{ID: "2", Unique: "cron:a:1"})
+	q.Push(ctx, &Job{ID: "3", Unique: "cron:a:1", Attempt: 1}) // 重試不去重
+	if n, _ := q.Len(ctx); n != 2 {
+		t.Errorf("Expected duplicate unique key to be ignored, got %d jobs", n)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	s := New(Config{Workers: 1})
+	s.Register("ok", func(ctx context.Context, job *Job) error { return nil })
+	s.Enqueue(context.Background(), "ok", nil)
+	s.Enqueue(context.Background(), "ok", nil, Delay(time.Hour))
+	s.Start()
+	waitFor(t, "job", func() bool { return s.Stats().Jobs["ok"].Succeeded == 1 })
+	defer s.Shutdown(context.Background())
+
+	expected := `
+# HELP hypgo_jobs_enqueued_total Total number of jobs enqueued.
+# TYPE hypgo_jobs_enqueued_total counter
+hypgo_jobs_enqueued_total{job="ok"} 2
+# HELP hypgo_jobs_processed_total Total number of finished jobs by outcome.
+# TYPE hypgo_jobs_processed_total counter
+hypgo_jobs_processed_total{job="ok",status="failed"} 0
+hypgo_jobs_processed_total{job="ok",status="succeeded"} 1
+# HELP hypgo_jobs_queued Number of jobs waiting in the queue, including delayed jobs.
+# TYPE hypgo_jobs_queued gauge
+hypgo_jobs_queued 1
+`
+	if err := testutil.CollectAndCompare(s.Collector(), strings.NewReader(expected),
+		"hypgo_jobs_enqueued_total", "hypgo_jobs_processed_total", "hypgo_jobs_queued"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestScheduleRecurring(t *testing.T) {
+	s := New(Config{Workers: 1})
+	var runs atomic.Int32
+	s.Register("tick", func(ctx context.Context, job *Job) error {
+		if !strings.HasPrefix(job.Unique, "cron:tick:") {
+			t.Errorf("Expected cron unique key, got %q", job.Unique)
+		}
+		runs.Add(1)
+		return nil
+	})
+	if err := s.Schedule("not a spec", "tick", nil); err == nil {
+		t.Error("Expected invalid spec to be rejected")
+	}
+	if err := s.Schedule("@every 1s", "tick", nil); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	waitFor(t, "scheduled run", func() bool { return runs.Load() >= 1 })
+	s.Shutdown(context.Background())
+	if err := s.Schedule("@hourly", "tick", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed after Shutdown, got %v", err)
+	}
+}
+
+// memLocker 進程內的 Locker，模擬多個實例共用的鎖
+type memLocker struct {
+	mu   sync.Mutex
+	held map[string]bool
+}
+
+func (l *memLocker) Lock(_ context.Context, key string, _ time.Duration) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.held[key] {
+		return func() {}, false, nil
+	}
+	l.held[key] = true
+	return func() {}, true, nil
+}
+
+func TestScheduleLockerRunsOnce(t *testing.T) {
+	locker := &memLocker{held: make(map[string]bool)}
+	var runs atomic.Int32
+	var instances []*Scheduler
+	for i := 0; i < 3; i++ {
+		// 各實例使用自己的記憶體佇列，只靠 Locker 互斥
+		s := New(Config{Workers: 1, Locker: locker})
+		s.Register("report", func(ctx context.Context, job *Job) error {
+			runs.Add(1)
+			return nil
+		})
+		s.Schedule("@every 1s", "report", nil)
+		s.Start()
+		instances = append(instances, s)
+	}
+	waitFor(t, "scheduled run", func() bool { return runs.Load() >= 1 })
+	for _, s := range instances {
+		s.Shutdown(context.Background())
+	}
+
+	locker.mu.Lock()
+	ticks := len(locker.held)
+	locker.mu.Unlock()
+	if n := int(runs.Load()); n > ticks {
+		t.Errorf("Expected one run per tick across instances, got %d runs for %d ticks", n, ticks)
+	}
+}
+
+// countingLocker 記錄尚未釋放的鎖數量，模擬 AutoRenew 在 unlock 前持續續期
+type countingLocker struct {
+	mu       sync.Mutex
+	acquired int
+	active   int
+}
+
+func (l *countingLocker) Lock(_ context.Context, _ string, _ time.Duration) (func(), bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.acquired++
+	l.active++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			l.active--
+			l.mu.Unlock()
+		})
+	}, true, nil
+}
+
+func (l *countingLocker) counts() (acquired, active int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.acquired, l.active
+}
+
+func TestScheduleReleasesTickLocks(t *testing.T) {
+	locker := &countingLocker{}
+	s := New(Config{Workers: 1, Locker: locker})
+	s.Register("report", func(ctx context.Context, job *Job) error { return nil })
+	s.Schedule("@every 1s", "report", nil)
+	s.Start()
+
+	waitFor(t, "first tick", func() bool {
+		acquired, _ := locker.counts()
+		return acquired >= 1
+	})
+	waitFor(t, "second tick", func() bool {
+		acquired, _ := locker.counts()
+		return acquired >= 2
+	})
+	if _, active := locker.counts(); active > 1 {
+		t.Errorf("Expected previous tick lock released at the next tick, %d still held", active)
+	}
+
+	s.Shutdown(context.Background())
+	waitFor(t, "lock release on shutdown", func() bool {
+		_, active := locker.counts()
+		return active == 0
+	})
+}
@@ -0,0 +1,146 @@
+// @chris
+package jobs
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ===== 指標 =====
+
+// JobStats 單一工作名稱的累計統計
+type JobStats struct {
+	Enqueued  int64         `json:"enqueued"`
+	Succeeded int64         `json:"succeeded"`
+	Failed    int64         `json:"failed"`  // 最終失敗（重試用盡或不可重試）
+	Retried   int64         `json:"retried"` // 重新排入的次數
+	Panics    int64         `json:"panics"`
+	Duration  time.Duration `json:"duration"` // 累計執行時間
+}
+
+// Stats 排程器統計
+type Stats struct {
+	Running int                 `json:"running"`
+	Jobs    map[string]JobStats `json:"jobs"`
+}
+
+type jobCounters struct {
+	enqueued, succeeded, failed, retried, panics, duration atomic.Int64
+}
+
+type metrics struct {
+	running atomic.Int64
+	mu      sync.Mutex
+	jobs    map[string]*jobCounters
+}
+
+func (m *metrics) job(name string) *jobCounters {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, ok := m.jobs[name]
+	if !ok {
+		c = &jobCounters{}
+		m.jobs[name] = c
+	}
+	return c
+}
+
+// Stats 取得目前統計
+func (s *Scheduler) Stats() Stats {
+	s.metrics.mu.Lock()
+	defer s.metrics.mu.Unlock()
+	stats := Stats{
+		Running: int(s.metrics.running.Load()),
+		Jobs:    make(map[string]JobStats, len(s.metrics.jobs)),
+	}
+	for name, c := range s.metrics.jobs {
+		stats.Jobs[name] = JobStats{
+			Enqueued:  c.enqueued.Load(),
+			Succeeded: c.succeeded.Load(),
+			Failed:    c.failed.Load(),
+			Retried:   c.retried.Load(),
+			Panics:    c.panics.Load(),
+			Duration:  time.Duration(c.duration.Load()),
+		}
+	}
+	return stats
+}
+
+// schedulerCollector 於每次抓取時讀取 Stats 與佇列長度，不另外維護計數
+type schedulerCollector struct {
+	s *Scheduler
+
+	running   *prometheus.Desc
+	queued    *prometheus.Desc
+	enqueued  *prometheus.Desc
+	processed *prometheus.Desc
+	retried   *prometheus.Desc
+	panics    *prometheus.Desc
+	duration  *prometheus.Desc
+}
+
+// Collector 回傳排程器的 Prometheus 收集器，以 job 標籤（工作名稱）區分
+//
+// EX：
+//
+//	reg.MustRegister(sched.Collector())
+func (s *Scheduler) Collector() prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc("hypgo_jobs_"+name, help, labels, nil)
+	}
+	return &schedulerCollector{
+		s:         s,
+		running:   desc("running", "Number of jobs currently running."),
+		queued:    desc("queued", "Number of jobs waiting in the queue, including delayed jobs."),
+		enqueued:  desc("enqueued_total", "Total number of jobs enqueued.", "job"),
+		processed: desc("processed_total", "Total number of finished jobs by outcome.", "job", "status"),
+		retried:   desc("retries_total", "Total number of failed jobs requeued for retry.", "job"),
+		panics:    desc("panics_total", "Total number of recovered job panics.", "job"),
+		duration:  desc("duration_seconds_total", "Total time spent running jobs.", "job"),
+	}
+}
+
+// Describe 實現 prometheus.Collector
+func (c *schedulerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.running
+	ch <- c.queued
+	ch <- c.enqueued
+	ch <- c.processed
+	ch <- c.retried
+	ch <- c.panics
+	ch <- c.duration
+}
+
+// Collect 實現 prometheus.Collector
+func (c *schedulerCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.s.Stats()
+	ch <- prometheus.MustNewConstMetric(c.running, prometheus.GaugeValue, float64(stats.Running))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if n, err := c.s.queue.Len(ctx); err == nil {
+		ch <- prometheus.MustNewConstMetric(c.queued, prometheus.GaugeValue, float64(n))
+	}
+
+	names := make([]string, 0, len(stats.Jobs))
+	for name := range stats.Jobs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...)
+	}
+	for _, name := range names {
+		js := stats.Jobs[name]
+		counter(c.enqueued, float64(js.Enqueued), name)
+		counter(c.processed, float64(js.Succeeded), name, "succeeded")
+		counter(c.processed, float64(js.Failed), name, "failed")
+		counter(c.retried, float64(js.Retried), name)
+		counter(c.panics, float64(js.Panics), name)
+		counter(c.duration, js.Duration.Seconds(), name)
+	}
+}
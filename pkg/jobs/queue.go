@@ -0,0 +1,101 @@
+// @chris
+package jobs
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultUniqueTTL Unique 去重的保留時間
+const DefaultUniqueTTL = 24 * time.Hour
+
+// Queue 工作佇列介面
+// 實作需可被多個 worker 並行呼叫；Redis 等外部佇列可讓多個實例共用同一批工作
+type Queue interface {
+	// Push 加入工作，RunAt 之前不可取出
+	// 第一次加入（Attempt 為 0）且 Unique 非空時，保留期限內重複的 Unique 直接忽略並回傳 nil
+	Push(ctx context.Context, job *Job) error
+	// Pop 取出一個已到期的工作並自佇列移除；沒有時回傳 (nil, nil)，不阻塞
+	Pop(ctx context.Context) (*Job, error)
+	// Len 尚未取出的工作數（含延遲中的工作）
+	Len(ctx context.Context) (int, error)
+}
+
+// ===== 記憶體佇列 =====
+
+// MemoryQueue 記憶體內的佇列，依 RunAt 排序；進程結束時尚未執行的工作會遺失
+type MemoryQueue struct {
+	mu     sync.Mutex
+	items  jobHeap
+	seq    uint64
+	unique map[string]time.Time // Unique -> 過期時間
+}
+
+// NewMemoryQueue 創建記憶體佇列
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{unique: make(map[string]time.Time)}
+}
+
+// Push 加入工作
+func (q *MemoryQueue) Push(_ context.Context, job *Job) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if job.Unique != "" && job.Attempt == 0 {
+		now := time.Now()
+		if exp, ok := q.unique[job.Unique]; ok && now.Before(exp) {
+			return nil
+		}
+		for key, exp := range q.unique {
+			if !now.Before(exp) {
+				delete(q.unique, key)
+			}
+		}
+		q.unique[job.Unique] = now.Add(DefaultUniqueTTL)
+	}
+	q.seq++
+	heap.Push(&q.items, heapItem{job: job, seq: q.seq})
+	return nil
+}
+
+// Pop 取出最早到期的工作
+func (q *MemoryQueue) Pop(_ context.Context) (*Job, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.items) == 0 || q.items[0].job.RunAt.After(time.Now()) {
+		return nil, nil
+	}
+	return heap.Pop(&q.items).(heapItem).job, nil
+}
+
+// Len 尚未取出的工作數
+func (q *MemoryQueue) Len(_ context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.items), nil
+}
+
+// heapItem seq 讓相同 RunAt 的工作依加入順序取出
+type heapItem struct {
+	job *Job
+	seq uint64
+}
+
+type jobHeap []heapItem
+
+func (h jobHeap) Len() int { return len(h) }
+func (h jobHeap) Less(i, j int) bool {
+	if !h[i].job.RunAt.Equal(h[j].job.RunAt) {
+		return h[i].job.RunAt.Before(h[j].job.RunAt)
+	}
+	return h[i].seq < h[j].seq
+}
+func (h jobHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *jobHeap) Push(x interface{}) { *h = append(*h, x.(heapItem)) }
+func (h *jobHeap) Pop() interface{} {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,98 @@
+// @chris
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ===== Redis 佇列 =====
+
+// popScript 原子地取出一個已到期的工作：sorted set 依 RunAt 排序 ID，hash 保存工作內容
+var popScript = redis.NewScript(`
+local ids = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 1)
+if #ids == 0 then
+	return false
+end
+redis.call('ZREM', KEYS[1], ids[1])
+local data = redis.call('HGET', KEYS[2], ids[1])
+redis.call('HDEL', KEYS[2], ids[1])
+return data
+`)
+
+// pushScript 原子地寫入工作：帶 KEYS[3] 時先以 SET NX 佔用唯一鍵，已存在則略過；
+// 唯一鍵與工作內容在同一個 script 內寫入，不會出現唯一鍵已設定而工作未加入的情況
+var pushScript = redis.NewScript(`
+if #KEYS == 3 then
+	if not redis.call('SET', KEYS[3], ARGV[1], 'NX', 'PX', ARGV[4]) then
+		return 0
+	end
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2])
+redis.call('ZADD', KEYS[2], ARGV[3], ARGV[1])
+return 1
+`)
+
+// RedisQueue 以 Redis 保存工作，進程重啟後未執行的工作仍在，多個實例共用同一個 prefix 即共用佇列
+// 工作在 Pop 時即自 Redis 移除，執行中的工作於進程異常終止時會遺失（至多執行一次）
+// Redis Cluster 請在 prefix 使用 hash tag（如 "{jobs}:"）讓所有 key 位於同一個 slot
+type RedisQueue struct {
+	client    redis.UniversalClient
+	prefix    string
+	uniqueTTL time.Duration
+}
+
+// NewRedisQueue 創建 Redis 佇列，prefix 為空時使用 "jobs:"
+//
+// EX：
+//
+//	sched := jobs.New(jobs.Config{Queue: jobs.NewRedisQueue(rdb, "")})
+func NewRedisQueue(client redis.UniversalClient, prefix string) *RedisQueue {
+	if prefix == "" {
+		prefix = "jobs:"
+	}
+	return &RedisQueue{client: client, prefix: prefix, uniqueTTL: DefaultUniqueTTL}
+}
+
+// Push 寫入工作內容並依 RunAt 加入排程
+func (q *RedisQueue) Push(ctx context.Context, job *Job) error {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("jobs: encode job: %w", err)
+	}
+	keys := []string{q.prefix + "data", q.prefix + "queue"}
+	if job.Unique != "" && job.Attempt == 0 {
+		keys = append(keys, q.prefix+"unique:"+job.Unique)
+	}
+	return pushScript.Run(ctx, q.client, keys,
+		job.ID, data, job.RunAt.UnixMilli(), q.uniqueTTL.Milliseconds()).Err()
+}
+
+// Pop 取出一個已到期的工作
+func (q *RedisQueue) Pop(ctx context.Context) (*Job, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	data, err := popScript.Run(ctx, q.client, []string{q.prefix + "queue", q.prefix + "data"}, now).Text()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var job Job
+	if err := json.Unmarshal([]byte(data), &job); err != nil {
+		return nil, fmt.Errorf("jobs: decode job: %w", err)
+	}
+	return &job, nil
+}
+
+// Len 尚未取出的工作數
+func (q *RedisQueue) Len(ctx context.Context) (int, error) {
+	n, err := q.client.ZCard(ctx, q.prefix+"queue").Result()
+	return int(n), err
+}
@@ -45,6 +45,10 @@ type Logger struct {
 	mu       sync.Mutex
 	rotator  *LogRotator
 	colorize bool
+
+	// With 建立的子 logger：輸出、層級與鎖皆沿用 base，每行附加 fields
+	base   *Logger
+	fields []interface{}
 }
 
 // New 建立 Logger（向後相容的建構子）。
@@ -137,11 +141,39 @@ func InitLogger(config interface{}) {
 	defaultLogger = NewLogger()
 }
 
+// With 返回附帶固定 key、value 的子 logger，之後每一行都會帶上這些欄位
+// 子 logger 與父 logger 共用輸出、層級與格式，對子 logger 呼叫 SetXxx 等同設定父 logger；
+// 子 logger 本身不可變，可安全地跨 goroutine 使用
+//
+// EX：
+//
+//	userLog := log.With("module", "user")
+//	userLog.Info("created", "id", id) // [INFO] created | module=user id=42
+func (l *Logger) With(keysAndValues ...interface{}) *Logger {
+	if l == nil {
+		return nil
+	}
+	root := l.root()
+	fields := make([]interface{}, 0, len(l.fields)+len(keysAndValues))
+	fields = append(fields, l.fields...)
+	fields = append(fields, keysAndValues...)
+	return &Logger{base: root, fields: fields}
+}
+
+// root 返回實際持有輸出的 logger（子 logger 的 base）
+func (l *Logger) root() *Logger {
+	if l.base != nil {
+		return l.base
+	}
+	return l
+}
+
 // SetLevel 設定日誌級別
 func (l *Logger) SetLevel(level Level) {
 	if l == nil {
 		return
 	}
+	l = l.root()
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.level = level
@@ -152,6 +184,7 @@ func (l *Logger) SetOutput(w io.Writer) {
 	if l == nil {
 		return
 	}
+	l = l.root()
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.out = w
@@ -167,6 +200,7 @@ func (l *Logger) SetFormat(format string) {
 	if l == nil {
 		return
 	}
+	l = l.root()
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.json = strings.EqualFold(format, "json")
@@ -196,6 +230,7 @@ func (w managedWriter) Write(p []byte) (int, error) {
 
 // SetFile 設定日誌文件
 func (l *Logger) SetFile(filename string) error {
+	l = l.root()
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
@@ -331,6 +366,10 @@ func (l *Logger) getLevelColor(level Level) string {
 
 // log 結構化 KV 日誌的共用實作
 func (l *Logger) log(level Level, msg string, keysAndValues ...interface{}) {
+	if l != nil && l.base != nil {
+		l.base.log(level, msg, l.withFields(keysAndValues)...)
+		return
+	}
 	if l == nil || (l.logger == nil && l.slog == nil) {
 		return
 	}
@@ -356,8 +395,24 @@ func (l *Logger) log(level Level, msg string, keysAndValues ...interface{}) {
 	}
 }
 
+// withFields 將子 logger 的固定欄位放在呼叫端欄位之前
+func (l *Logger) withFields(keysAndValues []interface{}) []interface{} {
+	if len(keysAndValues) == 0 {
+		return l.fields
+	}
+	out := make([]interface{}, 0, len(l.fields)+len(keysAndValues))
+	out = append(out, l.fields...)
+	return append(out, keysAndValues...)
+}
+
 // logf printf-style 日誌的共用實作（msg 含格式動詞，args 為對應參數）
 func (l *Logger) logf(level Level, format string, args ...interface{}) {
+	if l != nil && l.base != nil {
+		if level >= l.base.level {
+			l.base.log(level, fmt.Sprintf(format, args...), l.fields...)
+		}
+		return
+	}
 	if l == nil || (l.logger == nil && l.slog == nil) {
 		return
 	}
@@ -427,6 +482,28 @@ func (l *Logger) Fatal(msg string, keysAndValues ...interface{}) {
 	os.Exit(1)
 }
 
+// ===== KV 模式的 zap 風格別名（…w 結尾），行為與對應的 KV 方法相同 =====
+
+// Debugw 同 Debug
+func (l *Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.log(DEBUG, msg, keysAndValues...)
+}
+
+// Infow 同 Info
+func (l *Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.log(INFO, msg, keysAndValues...)
+}
+
+// Warnw 同 Warning
+func (l *Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.log(WARNING, msg, keysAndValues...)
+}
+
+// Errorw 同 Error
+func (l *Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.log(ERROR, msg, keysAndValues...)
+}
+
 // ===== printf 模式：format 含 %v/%s/%d 等格式動詞，args 為對應參數 =====
 
 // Debugf 以 printf 格式輸出調試日誌
@@ -549,14 +626,15 @@ func (l *Logger) cleanOldBackups() {
 
 // SetRotator 設定日誌輪轉器
 func (l *Logger) SetRotator(rotator *LogRotator) {
+	l = l.root()
 	l.mu.Lock()
 	defer l.mu.Unlock()
 	l.rotator = rotator
 }
 
-// Close 關閉Logger
+// Close 關閉Logger（子 logger 不持有檔案，呼叫無作用）
 func (l *Logger) Close() {
-	if l == nil {
+	if l == nil || l.base != nil {
 		return
 	}
 	l.mu.Lock()
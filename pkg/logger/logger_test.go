@@ -277,3 +277,40 @@ func TestFormatMessageOddKeysAndValues(t *testing.T) {
 		t.Errorf("expected orphanKey=(MISSING) for odd key, got: %s", msg)
 	}
 }
+
+func TestWithFields(t *testing.T) {
+	var buf bytes.Buffer
+	l, _ := New("info", "stdout", &buf, false)
+
+	child := l.With("request_id", "abc").With("module", "user")
+	child.Infow("created user", "id", 42)
+	if out := buf.String(); !strings.Contains(out, "created user | request_id=abc module=user id=42") {
+		t.Errorf("expected child fields before call fields, got: %s", out)
+	}
+
+	buf.Reset()
+	l.Info("plain")
+	if strings.Contains(buf.String(), "request_id") {
+		t.Errorf("parent logger must not carry child fields: %s", buf.String())
+	}
+
+	// 子 logger 共用父 logger 的層級與格式
+	buf.Reset()
+	child.Debugw("hidden")
+	child.SetLevel(DEBUG)
+	child.SetFormat("json")
+	l.Debugf("parent %d", 1)
+	child.Debugf("child %d", 2)
+	out := buf.String()
+	for _, want := range []string{`"msg":"parent 1"`, `"msg":"child 2"`, `"request_id":"abc"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %s in output, got: %s", want, out)
+		}
+	}
+	if strings.Contains(out, "hidden") {
+		t.Errorf("debug line logged before level change: %s", out)
+	}
+
+	var nilLogger *Logger
+	nilLogger.With("k", "v").Infow("no panic")
+}
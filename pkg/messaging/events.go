@@ -0,0 +1,59 @@
+// @chris
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/maoxiaoyue/hypgo/pkg/events"
+)
+
+// ===== 事件匯流排橋接 =====
+
+// PublishEvents 將匯流排 topic 的事件轉發到 Broker 的 brokerTopic，回傳取消橋接的函數
+// payload 為 Message 時原樣發佈；[]byte 作為 Body；其餘以 json.Marshal 編碼為 Body。
+// 以 events.Async() 訂閱，發佈失敗交給 events.Config.OnError
+//
+// EX：
+//
+//	stop := messaging.PublishEvents(bus, "user.created", broker, "users")
+//	defer stop()
+func PublishEvents(bus *events.Bus, topic string, broker Broker, brokerTopic string) (stop func()) {
+	return bus.Subscribe(topic, func(ctx context.Context, e events.Event) error {
+		msg, err := eventMessage(e)
+		if err != nil {
+			return err
+		}
+		return broker.Publish(ctx, brokerTopic, msg)
+	}, events.Async(), events.Named("messaging:"+brokerTopic))
+}
+
+// ConsumeEvents 訂閱 Broker 的 brokerTopic，將收到的 Message 以 payload 發佈到匯流排的 topic
+// 匯流排同步訂閱者的錯誤會回傳給驅動，由驅動決定重試或重新投遞
+//
+// EX：
+//
+//	err := messaging.ConsumeEvents(broker, "users", bus, "user.created")
+//	events.On(bus, "user.created", func(ctx context.Context, msg messaging.Message) error {
+//	    return indexUser(ctx, msg.Body)
+//	})
+func ConsumeEvents(broker Broker, brokerTopic string, bus *events.Bus, topic string) error {
+	return broker.Subscribe(brokerTopic, func(ctx context.Context, msg Message) error {
+		return bus.Publish(ctx, topic, msg)
+	})
+}
+
+func eventMessage(e events.Event) (Message, error) {
+	switch p := e.Payload.(type) {
+	case Message:
+		return p, nil
+	case []byte:
+		return Message{Body: p, Timestamp: e.Time}, nil
+	}
+	body, err := json.Marshal(e.Payload)
+	if err != nil {
+		return Message{}, fmt.Errorf("messaging: encode event payload: %w", err)
+	}
+	return Message{Body: body, Timestamp: e.Time}, nil
+}
@@ -0,0 +1,62 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/events"
+)
+
+func TestPublishEvents(t *testing.T) {
+	b := NewMemoryBroker()
+	bus := events.New(events.Config{Workers: 1})
+	stop := PublishEvents(bus, "user.created", b, "users")
+
+	bus.Publish(context.Background(), "user.created", map[string]string{"name": "alice"})
+	bus.Publish(context.Background(), "user.created", []byte("raw"))
+	bus.Publish(context.Background(), "user.created", Message{Key: []byte("k"), Body: []byte("msg")})
+	if err := bus.Close(context.Background()); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	stop()
+	if n := bus.Topics()["user.created"]; n != 0 {
+		t.Errorf("Expected bridge to unsubscribe, still %d subscribers", n)
+	}
+
+	got := b.Published("users")
+	if len(got) != 3 {
+		t.Fatalf("Expected 3 forwarded messages, got %+v", got)
+	}
+	if string(got[0].Body) != `{"name":"alice"}` || string(got[1].Body) != "raw" || string(got[2].Key) != "k" {
+		t.Errorf("Unexpected forwarded messages: %+v", got)
+	}
+}
+
+func TestConsumeEvents(t *testing.T) {
+	b := NewMemoryBroker()
+	bus := events.New(events.Config{Workers: 1})
+	defer bus.Close(context.Background())
+
+	if err := ConsumeEvents(b, "users", bus, "user.created"); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	events.On(bus, "user.created", func(ctx context.Context, msg Message) error {
+		got = append(got, string(msg.Body))
+		if string(msg.Body) == "bad" {
+			return errors.New("rejected")
+		}
+		return nil
+	})
+
+	if err := b.Publish(context.Background(), "users", Message{Body: []byte("alice")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish(context.Background(), "users", Message{Body: []byte("bad")}); err == nil {
+		t.Error("Expected bus handler error to reach the broker")
+	}
+	if len(got) != 2 || got[0] != "alice" {
+		t.Errorf("Unexpected deliveries: %v", got)
+	}
+}
@@ -0,0 +1,81 @@
+// @chris
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/maoxiaoyue/hypgo/pkg/messaging"
+)
+
+func init() {
+	messaging.Register("kafka", func(config map[string]interface{}) (messaging.Broker, error) {
+		k := &Kafka{}
+		if err := k.Init(config); err != nil {
+			return nil, err
+		}
+		if err := k.Connect(); err != nil {
+			return nil, err
+		}
+		return k.Broker(), nil
+	})
+}
+
+// Broker 以 messaging.Broker 介面操作此實例（訂閱需設定 group_id）
+func (k *Kafka) Broker() messaging.Broker {
+	return &broker{k: k}
+}
+
+type broker struct {
+	k *Kafka
+}
+
+var _ messaging.Broker = (*broker)(nil)
+
+func (b *broker) Publish(ctx context.Context, topic string, msg messaging.Message) error {
+	producer := b.k.Producer()
+	if producer == nil {
+		return fmt.Errorf("kafka: not connected")
+	}
+
+	headers := make([]Header, 0, len(msg.Headers))
+	for k, v := range msg.Headers {
+		headers = append(headers, Header{Key: k, Value: []byte(v)})
+	}
+	return producer.PublishMessage(ctx, Message{
+		Topic:   topic,
+		Key:     msg.Key,
+		Value:   msg.Body,
+		Headers: headers,
+		Time:    msg.Timestamp,
+	})
+}
+
+func (b *broker) Subscribe(topic string, handler messaging.Handler) error {
+	consumer := b.k.Consumer()
+	if consumer == nil {
+		return fmt.Errorf("kafka: not connected")
+	}
+
+	return consumer.Subscribe(topic, func(ctx context.Context, m Message) error {
+		msg := messaging.Message{
+			ID:        strconv.Itoa(m.Partition) + "-" + strconv.FormatInt(m.Offset, 10),
+			Topic:     m.Topic,
+			Key:       m.Key,
+			Body:      m.Value,
+			Timestamp: m.Time,
+		}
+		if len(m.Headers) > 0 {
+			msg.Headers = make(map[string]string, len(m.Headers))
+			for _, h := range m.Headers {
+				msg.Headers[h.Key] = string(h.Value)
+			}
+		}
+		return handler(ctx, msg)
+	})
+}
+
+func (b *broker) Close() error {
+	return b.k.Close()
+}
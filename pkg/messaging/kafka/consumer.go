@@ -0,0 +1,187 @@
+// @chris
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Handler 訊息處理函數；回傳錯誤時依 max_retries 重試
+type Handler func(ctx context.Context, msg Message) error
+
+// Consumer Kafka 消費者，每次 Subscribe 啟動一個 consumer group 迴圈
+type Consumer struct {
+	config Config
+	dialer *kafkago.Dialer
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu      sync.Mutex
+	readers []*kafkago.Reader
+	closed  bool
+}
+
+func newConsumer(cfg Config, dialer *kafkago.Dialer) *Consumer {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Consumer{config: cfg, dialer: dialer, ctx: ctx, cancel: cancel}
+}
+
+// Subscribe 以 group_id 訂閱 topic，於背景持續呼叫 handler
+//
+// auto_commit 為 true 時讀取即提交 offset（依 commit_interval 批次提交）；
+// 否則在 handler 完成後才提交，確保至少處理一次。handler 重試仍失敗時
+// 會回報至 ErrorHandler 並提交 offset 跳過該訊息，避免單一壞訊息卡住分區。
+func (c *Consumer) Subscribe(topic string, handler Handler) error {
+	if c.config.GroupID == "" {
+		return fmt.Errorf("kafka: group_id is required to subscribe")
+	}
+	startOffset, err := parseStartOffset(c.config.StartOffset)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.closed {
+		return fmt.Errorf("kafka: consumer is closed")
+	}
+
+	readerCfg := kafkago.ReaderConfig{
+		Brokers:        c.config.Brokers,
+		GroupID:        c.config.GroupID,
+		Topic:          topic,
+		Dialer:         c.dialer,
+		StartOffset:    startOffset,
+		ReadBackoffMin: c.config.RetryBackoff,
+		ReadBackoffMax: c.config.RetryBackoff * 10,
+	}
+	if c.config.AutoCommit {
+		readerCfg.CommitInterval = c.config.CommitInterval
+	}
+	reader := kafkago.NewReader(readerCfg)
+	c.readers = append(c.readers, reader)
+
+	c.wg.Add(1)
+	go c.run(reader, handler)
+	return nil
+}
+
+// run consumer group 迴圈，直到 Close
+func (c *Consumer) run(reader *kafkago.Reader, handler Handler) {
+	defer c.wg.Done()
+
+	for {
+		var msg kafkago.Message
+		var err error
+		if c.config.AutoCommit {
+			msg, err = reader.ReadMessage(c.ctx)
+		} else {
+			msg, err = reader.FetchMessage(c.ctx)
+		}
+		if err != nil {
+			if c.ctx.Err() != nil || errors.Is(err, io.EOF) {
+				return
+			}
+			c.config.ErrorHandler(fmt.Errorf("kafka: fetch from %s: %w", reader.Config().Topic, err))
+			if !c.sleep(c.config.RetryBackoff) {
+				return
+			}
+			continue
+		}
+
+		if err := c.handle(handler, msg); err != nil {
+			if c.ctx.Err() != nil {
+				// Close 中斷了重試：不提交，下次啟動時重新投遞
+				return
+			}
+			c.config.ErrorHandler(fmt.Errorf("kafka: handle %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err))
+		}
+
+		if !c.config.AutoCommit {
+			// 關閉期間仍提交已處理完成的訊息，避免重複投遞
+			ctx, cancel := context.WithTimeout(context.Background(), c.config.DialTimeout)
+			if err := reader.CommitMessages(ctx, msg); err != nil {
+				c.config.ErrorHandler(fmt.Errorf("kafka: commit %s[%d]@%d: %w", msg.Topic, msg.Partition, msg.Offset, err))
+			}
+			cancel()
+		}
+	}
+}
+
+// handle 呼叫 handler，失敗時以指數退避重試；panic 視為錯誤
+func (c *Consumer) handle(handler Handler, raw kafkago.Message) error {
+	msg := Message{
+		Topic:     raw.Topic,
+		Partition: raw.Partition,
+		Offset:    raw.Offset,
+		Key:       raw.Key,
+		Value:     raw.Value,
+		Headers:   raw.Headers,
+		Time:      raw.Time,
+	}
+
+	backoff := c.config.RetryBackoff
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = callHandler(c.ctx, handler, msg)
+		if err == nil || attempt >= *c.config.MaxRetries {
+			return err
+		}
+		if !c.sleep(backoff) {
+			return err
+		}
+		backoff *= 2
+	}
+}
+
+func callHandler(ctx context.Context, handler Handler, msg Message) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return handler(ctx, msg)
+}
+
+// sleep 等待 d，Close 時提前返回 false
+func (c *Consumer) sleep(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-c.ctx.Done():
+		return false
+	}
+}
+
+// Close 停止所有訂閱，等待處理中的訊息完成後關閉 reader
+func (c *Consumer) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	readers := c.readers
+	c.mu.Unlock()
+
+	c.cancel()
+	c.wg.Wait()
+
+	var errs []error
+	for _, reader := range readers {
+		if err := reader.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
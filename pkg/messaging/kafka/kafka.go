@@ -0,0 +1,412 @@
+// Package kafka 提供 Kafka 生產者 / 消費者實作，並以 hidb.DatabasePlugin 相同的
+// 生命週期（Name / Init / Connect / Close / Ping）集中管理。
+//
+// @chris
+package kafka
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	"gopkg.in/yaml.v3"
+)
+
+// SASLConfig SASL 驗證設定
+type SASLConfig struct {
+	Enabled   bool   `mapstructure:"enabled" yaml:"enabled"`
+	Mechanism string `mapstructure:"mechanism" yaml:"mechanism"` // "plain"、"scram-sha-256"、"scram-sha-512"
+	Username  string `mapstructure:"username" yaml:"username"`
+	Password  string `mapstructure:"password" yaml:"password"`
+}
+
+// TLSConfig TLS 連線設定
+type TLSConfig struct {
+	Enabled            bool   `mapstructure:"enabled" yaml:"enabled"`
+	CertFile           string `mapstructure:"cert_file" yaml:"cert_file"`
+	KeyFile            string `mapstructure:"key_file" yaml:"key_file"`
+	CaFile             string `mapstructure:"ca_file" yaml:"ca_file"`
+	InsecureSkipVerify bool   `mapstructure:"insecure_skip_verify" yaml:"insecure_skip_verify"`
+}
+
+// Config Kafka 配置，欄位對應 kafka.yaml
+type Config struct {
+	Brokers  []string `mapstructure:"brokers" yaml:"brokers"`
+	ClientID string   `mapstructure:"client_id" yaml:"client_id"`
+	GroupID  string   `mapstructure:"group_id" yaml:"group_id"`
+
+	// 生產者
+	Acks         string        `mapstructure:"acks" yaml:"acks"`               // "all"（預設）、"one"、"none"
+	Compression  string        `mapstructure:"compression" yaml:"compression"` // "gzip"、"snappy"、"lz4"、"zstd"，留空不壓縮
+	BatchTimeout time.Duration `mapstructure:"batch_timeout" yaml:"batch_timeout"`
+
+	// 消費者
+	AutoCommit     bool          `mapstructure:"auto_commit" yaml:"auto_commit"`         // false 時處理成功才提交 offset
+	CommitInterval time.Duration `mapstructure:"commit_interval" yaml:"commit_interval"` // 僅 auto_commit，0 為同步提交
+	StartOffset    string        `mapstructure:"start_offset" yaml:"start_offset"`       // "earliest"（預設）或 "latest"
+
+	// 重試
+	MaxRetries   *int          `mapstructure:"max_retries" yaml:"max_retries"`     // 未設定時為 3，0 表示不重試
+	RetryBackoff time.Duration `mapstructure:"retry_backoff" yaml:"retry_backoff"` // 預設 100ms，指數遞增
+
+	DialTimeout time.Duration `mapstructure:"dial_timeout" yaml:"dial_timeout"` // 預設 10s
+
+	SASL SASLConfig `mapstructure:"sasl" yaml:"sasl"`
+	TLS  TLSConfig  `mapstructure:"tls" yaml:"tls"`
+
+	// ErrorHandler 接收消費迴圈中的錯誤（預設寫入標準 log），不從 YAML 解析
+	ErrorHandler func(err error) `mapstructure:"-" yaml:"-"`
+}
+
+// Retries 以程式設定 MaxRetries 時使用，Retries(0) 表示失敗不重試
+//
+// EX：
+//
+//	cfg := kafka.Config{Brokers: []string{"localhost:9092"}, MaxRetries: kafka.Retries(0)}
+func Retries(n int) *int {
+	return &n
+}
+
+// LoadConfig 讀取 kafka.yaml；檔案可為頂層 kafka: 區塊或直接的欄位
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("kafka: read config: %w", err)
+	}
+
+	var wrapped struct {
+		Kafka *Config `yaml:"kafka"`
+	}
+	if err := yaml.Unmarshal(data, &wrapped); err != nil {
+		return Config{}, fmt.Errorf("kafka: parse config: %w", err)
+	}
+	if wrapped.Kafka != nil {
+		return *wrapped.Kafka, nil
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("kafka: parse config: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyDefaults 填入預設值並驗證
+func (c *Config) applyDefaults() error {
+	if len(c.Brokers) == 0 {
+		return fmt.Errorf("kafka: at least one broker is required")
+	}
+	if c.MaxRetries == nil {
+		c.MaxRetries = Retries(3)
+	} else if *c.MaxRetries < 0 {
+		return fmt.Errorf("kafka: max_retries must not be negative")
+	}
+	if c.RetryBackoff <= 0 {
+		c.RetryBackoff = 100 * time.Millisecond
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = 10 * time.Second
+	}
+	if c.ErrorHandler == nil {
+		c.ErrorHandler = func(err error) {
+			log.Printf("[kafka] %v", err)
+		}
+	}
+	if _, err := parseAcks(c.Acks); err != nil {
+		return err
+	}
+	if _, err := parseCompression(c.Compression); err != nil {
+		return err
+	}
+	if _, err := parseStartOffset(c.StartOffset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// parseAcks 將 acks 字串轉為 kafka-go 的 RequiredAcks
+func parseAcks(s string) (kafkago.RequiredAcks, error) {
+	switch strings.ToLower(s) {
+	case "", "all", "-1":
+		return kafkago.RequireAll, nil
+	case "one", "1", "leader":
+		return kafkago.RequireOne, nil
+	case "none", "0":
+		return kafkago.RequireNone, nil
+	default:
+		return 0, fmt.Errorf("kafka: unknown acks %q", s)
+	}
+}
+
+// parseCompression 將 compression 字串轉為 kafka-go 的 Compression
+func parseCompression(s string) (kafkago.Compression, error) {
+	switch strings.ToLower(s) {
+	case "", "none":
+		return 0, nil
+	case "gzip":
+		return kafkago.Gzip, nil
+	case "snappy":
+		return kafkago.Snappy, nil
+	case "lz4":
+		return kafkago.Lz4, nil
+	case "zstd":
+		return kafkago.Zstd, nil
+	default:
+		return 0, fmt.Errorf("kafka: unknown compression %q", s)
+	}
+}
+
+// parseStartOffset 將 start_offset 字串轉為 kafka-go 的起始 offset
+func parseStartOffset(s string) (int64, error) {
+	switch strings.ToLower(s) {
+	case "", "earliest", "first":
+		return kafkago.FirstOffset, nil
+	case "latest", "last":
+		return kafkago.LastOffset, nil
+	default:
+		return 0, fmt.Errorf("kafka: unknown start_offset %q", s)
+	}
+}
+
+// saslMechanism 依設定建立 SASL 機制，未啟用時回傳 nil
+func (c *Config) saslMechanism() (sasl.Mechanism, error) {
+	if !c.SASL.Enabled {
+		return nil, nil
+	}
+	switch strings.ToLower(c.SASL.Mechanism) {
+	case "", "plain":
+		return plain.Mechanism{Username: c.SASL.Username, Password: c.SASL.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, c.SASL.Username, c.SASL.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, c.SASL.Username, c.SASL.Password)
+	default:
+		return nil, fmt.Errorf("kafka: unknown SASL mechanism %q", c.SASL.Mechanism)
+	}
+}
+
+// tlsConfig 依設定建立 *tls.Config，未啟用時回傳 nil
+func (c *Config) tlsConfig() (*tls.Config, error) {
+	if !c.TLS.Enabled {
+		return nil, nil
+	}
+
+	tlsCfg := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: c.TLS.InsecureSkipVerify,
+	}
+	if c.TLS.CertFile != "" && c.TLS.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.TLS.CertFile, c.TLS.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: load client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	if c.TLS.CaFile != "" {
+		pem, err := os.ReadFile(c.TLS.CaFile)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: read CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("kafka: no certificates found in %s", c.TLS.CaFile)
+		}
+		tlsCfg.RootCAs = pool
+	}
+	return tlsCfg, nil
+}
+
+// dialer 建立帶 SASL / TLS 的 Dialer，供消費者與 Ping 使用
+func (c *Config) dialer() (*kafkago.Dialer, error) {
+	mechanism, err := c.saslMechanism()
+	if err != nil {
+		return nil, err
+	}
+	tlsCfg, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	return &kafkago.Dialer{
+		ClientID:      c.ClientID,
+		Timeout:       c.DialTimeout,
+		DualStack:     true,
+		TLS:           tlsCfg,
+		SASLMechanism: mechanism,
+	}, nil
+}
+
+// ===== 插件生命週期 =====
+
+// Kafka 集中管理 Producer 與 Consumer，實作 hidb.DatabasePlugin
+//
+// EX：
+//
+//	cfg, _ := kafka.LoadConfig("config/kafka.yaml")
+//	k, err := kafka.New(cfg)
+//	if err != nil { ... }
+//	defer k.Close()
+//
+//	k.Producer().Publish(ctx, "orders", []byte(id), payload)
+//	k.Consumer().Subscribe("orders", func(ctx context.Context, msg kafka.Message) error {
+//		return handleOrder(msg.Value)
+//	})
+type Kafka struct {
+	config   Config
+	dialer   *kafkago.Dialer
+	producer *Producer
+	consumer *Consumer
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// New 創建 Kafka 實例並確認可連上 broker
+func New(cfg Config) (*Kafka, error) {
+	k := &Kafka{config: cfg}
+	if err := k.Connect(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Name 插件名稱
+func (k *Kafka) Name() string {
+	return "kafka"
+}
+
+// Init 從 map 配置初始化（用於插件系統動態加載），欄位名稱與 kafka.yaml 相同
+func (k *Kafka) Init(config map[string]interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("kafka: encode config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("kafka: decode config: %w", err)
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	cfg.ErrorHandler = k.config.ErrorHandler
+	k.config = cfg
+	return nil
+}
+
+// Connect 建立 Producer / Consumer，並以 Ping 確認 broker 可用
+func (k *Kafka) Connect() error {
+	k.mu.Lock()
+	if k.closed {
+		k.mu.Unlock()
+		return fmt.Errorf("kafka: cannot connect on closed instance")
+	}
+	if k.producer != nil {
+		k.mu.Unlock()
+		return nil
+	}
+
+	if err := k.config.applyDefaults(); err != nil {
+		k.mu.Unlock()
+		return err
+	}
+	dialer, err := k.config.dialer()
+	if err != nil {
+		k.mu.Unlock()
+		return err
+	}
+	producer, err := newProducer(k.config, dialer)
+	if err != nil {
+		k.mu.Unlock()
+		return err
+	}
+	k.dialer = dialer
+	k.producer = producer
+	k.consumer = newConsumer(k.config, dialer)
+	k.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), k.config.DialTimeout)
+	defer cancel()
+	if err := k.Ping(ctx); err != nil {
+		// 保留實例供之後重新 Connect
+		k.mu.Lock()
+		k.consumer.Close()
+		k.producer.Close()
+		k.dialer, k.producer, k.consumer = nil, nil, nil
+		k.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close 停止所有消費者並關閉生產者
+func (k *Kafka) Close() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if k.closed {
+		return nil
+	}
+	k.closed = true
+
+	var errs []error
+	if k.consumer != nil {
+		if err := k.consumer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if k.producer != nil {
+		if err := k.producer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Ping 依序嘗試連線 broker，任一成功即視為健康
+func (k *Kafka) Ping(ctx context.Context) error {
+	k.mu.Lock()
+	dialer, brokers := k.dialer, k.config.Brokers
+	k.mu.Unlock()
+	if dialer == nil {
+		return fmt.Errorf("kafka: not connected")
+	}
+
+	var lastErr error
+	for _, broker := range brokers {
+		conn, err := dialer.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		_, err = conn.Brokers()
+		conn.Close()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+	}
+	return fmt.Errorf("kafka: ping failed: %w", lastErr)
+}
+
+// Producer 取得生產者（Connect 之前為 nil）
+func (k *Kafka) Producer() *Producer {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.producer
+}
+
+// Consumer 取得消費者（Connect 之前為 nil）
+func (k *Kafka) Consumer() *Consumer {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.consumer
+}
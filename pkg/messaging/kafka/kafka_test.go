@@ -0,0 +1,142 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kafka.yaml")
+	data := `kafka:
+  brokers: ["localhost:9092", "localhost:9093"]
+  group_id: orders
+  acks: one
+  compression: snappy
+  auto_commit: false
+  retry_backoff: 250ms
+  sasl:
+    enabled: true
+    mechanism: scram-sha-512
+    username: app
+    password: secret
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cfg.Brokers) != 2 || cfg.GroupID != "orders" || cfg.RetryBackoff != 250*time.Millisecond {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+	if !cfg.SASL.Enabled || cfg.SASL.Mechanism != "scram-sha-512" {
+		t.Errorf("unexpected SASL config: %+v", cfg.SASL)
+	}
+	if _, err := cfg.saslMechanism(); err != nil {
+		t.Errorf("saslMechanism: %v", err)
+	}
+}
+
+func TestInitFromMap(t *testing.T) {
+	k := &Kafka{}
+	err := k.Init(map[string]interface{}{
+		"brokers":      []interface{}{"localhost:9092"},
+		"group_id":     "g",
+		"compression":  "zstd",
+		"dial_timeout": "3s",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Name() != "kafka" || k.config.GroupID != "g" || k.config.DialTimeout != 3*time.Second {
+		t.Errorf("unexpected config: %+v", k.config)
+	}
+}
+
+func TestApplyDefaults(t *testing.T) {
+	cfg := Config{}
+	if err := cfg.applyDefaults(); err == nil {
+		t.Error("Expected error without brokers")
+	}
+
+	cfg = Config{Brokers: []string{"localhost:9092"}}
+	if err := cfg.applyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	if *cfg.MaxRetries != 3 || cfg.RetryBackoff != 100*time.Millisecond || cfg.ErrorHandler == nil {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+
+	// 明確設定 0 表示不重試
+	cfg = Config{Brokers: []string{"localhost:9092"}, MaxRetries: Retries(0)}
+	if err := cfg.applyDefaults(); err != nil || *cfg.MaxRetries != 0 {
+		t.Errorf("explicit max_retries 0 overridden: %v err %v", *cfg.MaxRetries, err)
+	}
+	cfg = Config{Brokers: []string{"localhost:9092"}, MaxRetries: Retries(-1)}
+	if err := cfg.applyDefaults(); err == nil {
+		t.Error("Expected error for negative max_retries")
+	}
+
+	cfg = Config{Brokers: []string{"localhost:9092"}, Acks: "most"}
+	if err := cfg.applyDefaults(); err == nil {
+		t.Error("Expected error for unknown acks")
+	}
+}
+
+func TestParseAcksAndCompression(t *testing.T) {
+	if acks, _ := parseAcks(""); acks != kafkago.RequireAll {
+		t.Errorf("default acks = %v, want all", acks)
+	}
+	if acks, _ := parseAcks("one"); acks != kafkago.RequireOne {
+		t.Errorf("acks one = %v", acks)
+	}
+	if c, _ := parseCompression("lz4"); c != kafkago.Lz4 {
+		t.Errorf("compression lz4 = %v", c)
+	}
+	if _, err := parseCompression("brotli"); err == nil {
+		t.Error("Expected error for unknown compression")
+	}
+}
+
+func TestSubscribeRequiresGroup(t *testing.T) {
+	c := newConsumer(Config{Brokers: []string{"localhost:9092"}}, &kafkago.Dialer{})
+	defer c.Close()
+	if err := c.Subscribe("orders", nil); err == nil {
+		t.Error("Expected error when group_id is empty")
+	}
+}
+
+func TestHandleRetries(t *testing.T) {
+	errFail := errors.New("fail")
+	calls := 0
+	handler := func(context.Context, Message) error {
+		calls++
+		return errFail
+	}
+
+	cfg := Config{Brokers: []string{"localhost:9092"}, MaxRetries: Retries(0), RetryBackoff: time.Millisecond}
+	if err := cfg.applyDefaults(); err != nil {
+		t.Fatal(err)
+	}
+	c := newConsumer(cfg, &kafkago.Dialer{})
+	if err := c.handle(handler, kafkago.Message{}); !errors.Is(err, errFail) || calls != 1 {
+		t.Errorf("max_retries 0: got %d calls, err %v", calls, err)
+	}
+
+	// Close 中斷退避等待後不再重試，run 因 ctx 已取消而不提交
+	calls = 0
+	c.config.MaxRetries = Retries(5)
+	c.config.RetryBackoff = time.Hour
+	c.cancel()
+	if err := c.handle(handler, kafkago.Message{}); err == nil || calls != 1 || c.ctx.Err() == nil {
+		t.Errorf("expected handle to stop after cancel, got %d calls, err %v", calls, err)
+	}
+}
@@ -0,0 +1,94 @@
+// @chris
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	kafkago "github.com/segmentio/kafka-go"
+)
+
+// Header 訊息標頭
+type Header = kafkago.Header
+
+// Message 收送的訊息
+type Message struct {
+	Topic     string
+	Partition int
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Time      time.Time
+}
+
+// Producer Kafka 生產者，可安全地被多個 goroutine 共用
+type Producer struct {
+	writer *kafkago.Writer
+}
+
+// newProducer 依設定建立 Writer；topic 由每則訊息指定
+func newProducer(cfg Config, dialer *kafkago.Dialer) (*Producer, error) {
+	acks, err := parseAcks(cfg.Acks)
+	if err != nil {
+		return nil, err
+	}
+	compression, err := parseCompression(cfg.Compression)
+	if err != nil {
+		return nil, err
+	}
+
+	attempts := *cfg.MaxRetries + 1
+	batchTimeout := cfg.BatchTimeout
+	if batchTimeout <= 0 {
+		// kafka-go 預設 1s，同步 Publish 會被拖慢
+		batchTimeout = 10 * time.Millisecond
+	}
+
+	return &Producer{writer: &kafkago.Writer{
+		Addr:            kafkago.TCP(cfg.Brokers...),
+		Balancer:        &kafkago.Hash{},
+		RequiredAcks:    acks,
+		Compression:     compression,
+		MaxAttempts:     attempts,
+		WriteBackoffMin: cfg.RetryBackoff,
+		WriteBackoffMax: cfg.RetryBackoff * 10,
+		BatchTimeout:    batchTimeout,
+		Transport: &kafkago.Transport{
+			ClientID:    cfg.ClientID,
+			DialTimeout: cfg.DialTimeout,
+			TLS:         dialer.TLS,
+			SASL:        dialer.SASLMechanism,
+		},
+	}}, nil
+}
+
+// Publish 同步發送訊息至 topic，相同 key 會落在同一個 partition
+// 失敗時依 max_retries / retry_backoff 重試，仍失敗則回傳錯誤
+func (p *Producer) Publish(ctx context.Context, topic string, key, value []byte) error {
+	return p.PublishMessage(ctx, Message{Topic: topic, Key: key, Value: value})
+}
+
+// PublishMessage 發送完整訊息（可帶 Headers）
+func (p *Producer) PublishMessage(ctx context.Context, msg Message) error {
+	if msg.Topic == "" {
+		return fmt.Errorf("kafka: topic is required")
+	}
+	err := p.writer.WriteMessages(ctx, kafkago.Message{
+		Topic:   msg.Topic,
+		Key:     msg.Key,
+		Value:   msg.Value,
+		Headers: msg.Headers,
+		Time:    msg.Time,
+	})
+	if err != nil {
+		return fmt.Errorf("kafka: publish to %s: %w", msg.Topic, err)
+	}
+	return nil
+}
+
+// Close 送出緩衝中的訊息並關閉連線
+func (p *Producer) Close() error {
+	return p.writer.Close()
+}
@@ -0,0 +1,86 @@
+// @chris
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ErrClosed Broker 已關閉
+var ErrClosed = errors.New("messaging: broker closed")
+
+// MemoryBroker 記憶體內的 Broker，供測試與單機開發使用
+// Publish 會在呼叫端 goroutine 同步執行所有訂閱者，並回傳第一個錯誤。
+type MemoryBroker struct {
+	mu          sync.RWMutex
+	subscribers map[string][]Handler
+	published   map[string][]Message
+	seq         uint64
+	closed      bool
+}
+
+// NewMemoryBroker 創建記憶體 Broker
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{
+		subscribers: make(map[string][]Handler),
+		published:   make(map[string][]Message),
+	}
+}
+
+// Publish 記錄訊息並同步投遞給訂閱者
+func (b *MemoryBroker) Publish(ctx context.Context, topic string, msg Message) error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return ErrClosed
+	}
+	b.seq++
+	msg.Topic = topic
+	if msg.ID == "" {
+		msg.ID = strconv.FormatUint(b.seq, 10)
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	b.published[topic] = append(b.published[topic], msg)
+	handlers := append([]Handler(nil), b.subscribers[topic]...)
+	b.mu.Unlock()
+
+	for _, handler := range handlers {
+		if err := handler(ctx, msg); err != nil {
+			return fmt.Errorf("messaging: handle %s: %w", topic, err)
+		}
+	}
+	return nil
+}
+
+// Subscribe 訂閱 topic
+func (b *MemoryBroker) Subscribe(topic string, handler Handler) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	b.subscribers[topic] = append(b.subscribers[topic], handler)
+	return nil
+}
+
+// Published 取得 topic 已發佈的訊息（測試斷言用）
+func (b *MemoryBroker) Published(topic string) []Message {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Message(nil), b.published[topic]...)
+}
+
+// Close 關閉 Broker，之後的 Publish / Subscribe 回傳 ErrClosed
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	b.subscribers = make(map[string][]Handler)
+	return nil
+}
@@ -0,0 +1,93 @@
+// Package messaging 定義訊息佇列的共通介面，讓應用程式碼依賴 Broker 而非特定驅動。
+// 驅動套件（kafka、rabbitmq）於 init 時以 Register 註冊，使用方式與 database/sql 相同：
+//
+//	import _ "github.com/maoxiaoyue/hypgo/pkg/messaging/kafka"
+//
+//	broker, err := messaging.NewBroker("kafka", map[string]interface{}{
+//		"brokers":  []string{"localhost:9092"},
+//		"group_id": "orders",
+//	})
+//
+// @chris
+package messaging
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Message 跨驅動的訊息
+type Message struct {
+	ID        string
+	Topic     string
+	Key       []byte // Kafka 分區鍵；RabbitMQ 忽略
+	Body      []byte
+	Headers   map[string]string
+	Timestamp time.Time
+}
+
+// Handler 訊息處理函數；回傳錯誤時由驅動決定重試或重新投遞
+type Handler func(ctx context.Context, msg Message) error
+
+// Broker 訊息佇列共通介面
+//
+// topic 在 Kafka 對應 topic；在 RabbitMQ 對應設定的 exchange 上的 routing key，
+// Subscribe 時會宣告同名 queue 並綁定。
+type Broker interface {
+	Publish(ctx context.Context, topic string, msg Message) error
+	Subscribe(topic string, handler Handler) error
+	Close() error
+}
+
+// Factory 以 map 配置建立 Broker（欄位名稱與各驅動的 yaml 相同）
+type Factory func(config map[string]interface{}) (Broker, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register 註冊驅動；同名重複註冊會 panic
+func Register(kind string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if factory == nil {
+		panic("messaging: Register factory is nil")
+	}
+	if _, dup := factories[kind]; dup {
+		panic("messaging: Register called twice for " + kind)
+	}
+	factories[kind] = factory
+}
+
+// Kinds 回傳已註冊的驅動名稱
+func Kinds() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	kinds := make([]string, 0, len(factories))
+	for kind := range factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// NewBroker 依驅動名稱建立 Broker
+func NewBroker(kind string, config map[string]interface{}) (Broker, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[kind]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("messaging: unknown broker %q (forgotten import?)", kind)
+	}
+	return factory(config)
+}
+
+func init() {
+	Register("memory", func(map[string]interface{}) (Broker, error) {
+		return NewMemoryBroker(), nil
+	})
+}
@@ -0,0 +1,74 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestMemoryBrokerPublishSubscribe(t *testing.T) {
+	b := NewMemoryBroker()
+
+	var got []Message
+	if err := b.Subscribe("orders", func(ctx context.Context, msg Message) error {
+		got = append(got, msg)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := b.Publish(context.Background(), "orders", Message{Body: []byte("hello")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Publish(context.Background(), "other", Message{Body: []byte("ignored")}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != 1 || string(got[0].Body) != "hello" || got[0].Topic != "orders" {
+		t.Fatalf("unexpected deliveries: %+v", got)
+	}
+	if got[0].ID == "" || got[0].Timestamp.IsZero() {
+		t.Errorf("Expected ID and Timestamp to be filled, got %+v", got[0])
+	}
+	if n := len(b.Published("other")); n != 1 {
+		t.Errorf("Expected 1 published message on other, got %d", n)
+	}
+}
+
+func TestMemoryBrokerHandlerError(t *testing.T) {
+	b := NewMemoryBroker()
+	boom := errors.New("boom")
+	b.Subscribe("orders", func(ctx context.Context, msg Message) error {
+		return boom
+	})
+
+	if err := b.Publish(context.Background(), "orders", Message{}); !errors.Is(err, boom) {
+		t.Errorf("Expected handler error, got %v", err)
+	}
+}
+
+func TestMemoryBrokerClose(t *testing.T) {
+	b := NewMemoryBroker()
+	b.Close()
+
+	if err := b.Publish(context.Background(), "orders", Message{}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Publish, got %v", err)
+	}
+	if err := b.Subscribe("orders", nil); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Subscribe, got %v", err)
+	}
+}
+
+func TestNewBroker(t *testing.T) {
+	b, err := NewBroker("memory", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := b.(*MemoryBroker); !ok {
+		t.Errorf("Expected *MemoryBroker, got %T", b)
+	}
+
+	if _, err := NewBroker("unknown", nil); err == nil {
+		t.Error("Expected error for unknown broker")
+	}
+}
@@ -0,0 +1,85 @@
+// @chris
+package rabbitmq
+
+import (
+	"context"
+
+	"github.com/maoxiaoyue/hypgo/pkg/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+func init() {
+	messaging.Register("rabbitmq", func(config map[string]interface{}) (messaging.Broker, error) {
+		r := &RabbitMQ{}
+		if err := r.Init(config); err != nil {
+			return nil, err
+		}
+		if err := r.Connect(); err != nil {
+			return nil, err
+		}
+		return r.Broker(), nil
+	})
+}
+
+// Broker 以 messaging.Broker 介面操作此實例
+// topic 作為設定之 exchange 上的 routing key；Subscribe 會宣告同名 durable queue 並綁定
+func (r *RabbitMQ) Broker() messaging.Broker {
+	return &broker{r: r}
+}
+
+type broker struct {
+	r *RabbitMQ
+}
+
+var _ messaging.Broker = (*broker)(nil)
+
+func (b *broker) Publish(ctx context.Context, topic string, msg messaging.Message) error {
+	var headers amqp.Table
+	if len(msg.Headers) > 0 {
+		headers = make(amqp.Table, len(msg.Headers))
+		for k, v := range msg.Headers {
+			headers[k] = v
+		}
+	}
+	return b.r.PublishMessage(ctx, b.r.config.Exchange, topic, amqp.Publishing{
+		ContentType: "application/octet-stream",
+		MessageId:   msg.ID,
+		Timestamp:   msg.Timestamp,
+		Headers:     headers,
+		Body:        msg.Body,
+	})
+}
+
+func (b *broker) Subscribe(topic string, handler messaging.Handler) error {
+	q := QueueConfig{Name: topic, Durable: true}
+	if exchange := b.r.config.Exchange; exchange != "" {
+		q.Bindings = []BindingConfig{{Exchange: exchange, RoutingKey: topic}}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.r.config.DialTimeout)
+	defer cancel()
+	if err := b.r.DeclareQueue(ctx, q); err != nil {
+		return err
+	}
+
+	return b.r.Consume(topic, func(ctx context.Context, d Delivery) error {
+		msg := messaging.Message{
+			ID:        d.MessageID,
+			Topic:     topic,
+			Body:      d.Body,
+			Timestamp: d.Timestamp,
+		}
+		if len(d.Headers) > 0 {
+			msg.Headers = make(map[string]string, len(d.Headers))
+			for k, v := range d.Headers {
+				if s, ok := v.(string); ok {
+					msg.Headers[k] = s
+				}
+			}
+		}
+		return handler(ctx, msg)
+	})
+}
+
+func (b *broker) Close() error {
+	return b.r.Close()
+}
@@ -0,0 +1,163 @@
+// @chris
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Delivery 收到的訊息
+type Delivery struct {
+	Exchange    string
+	RoutingKey  string
+	MessageID   string
+	ContentType string
+	Headers     map[string]interface{}
+	Timestamp   time.Time
+	Redelivered bool
+	Body        []byte
+}
+
+// Handler 訊息處理函數；auto_ack 為 false 時，回傳 nil 才會 ack，
+// 否則依 requeue 設定 nack
+type Handler func(ctx context.Context, d Delivery) error
+
+// Consume 開始消費 queue，於背景持續呼叫 handler
+// 每個消費者使用獨立 channel 並套用 prefetch_count；連線中斷後會在重連完成時自動重新訂閱。
+// 首次訂閱失敗（例如 queue 不存在）時直接回傳錯誤。
+func (r *RabbitMQ) Consume(queue string, handler Handler) error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+	if r.ctx == nil {
+		r.mu.Unlock()
+		return fmt.Errorf("rabbitmq: not connected")
+	}
+	r.consumers.Add(1)
+	r.mu.Unlock()
+
+	tag := "hypgo-" + strconv.FormatUint(r.consumerSeq.Add(1), 10)
+	started := make(chan error, 1)
+	go r.consumeLoop(queue, tag, handler, started)
+	return <-started
+}
+
+// consumeLoop 訂閱並分派訊息，channel 關閉後等待重連再訂閱
+func (r *RabbitMQ) consumeLoop(queue, tag string, handler Handler, started chan<- error) {
+	defer r.consumers.Done()
+
+	first := true
+	delay := r.config.ReconnectDelay
+	for {
+		ch, deliveries, err := r.subscribe(queue, tag)
+		if first {
+			first = false
+			started <- err
+			if err != nil {
+				return
+			}
+		}
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			r.config.ErrorHandler(fmt.Errorf("rabbitmq: resubscribe %s: %w", queue, err))
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+			delay = min(delay*2, r.config.MaxReconnectDelay)
+			continue
+		}
+		delay = r.config.ReconnectDelay
+
+		if !r.dispatch(ch, tag, deliveries, handler) {
+			return
+		}
+	}
+}
+
+// subscribe 開啟 channel、設定 QoS 並開始消費
+func (r *RabbitMQ) subscribe(queue, tag string) (*amqp.Channel, <-chan amqp.Delivery, error) {
+	conn, err := r.waitReady(r.ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := ch.Qos(r.config.PrefetchCount, 0, false); err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+	deliveries, err := ch.Consume(queue, tag, r.config.AutoAck, false, false, false, nil)
+	if err != nil {
+		ch.Close()
+		return nil, nil, err
+	}
+	return ch, deliveries, nil
+}
+
+// dispatch 處理訊息直到 channel 關閉（回傳 true，需重新訂閱）或 Close（回傳 false）
+func (r *RabbitMQ) dispatch(ch *amqp.Channel, tag string, deliveries <-chan amqp.Delivery, handler Handler) bool {
+	for {
+		select {
+		case <-r.ctx.Done():
+			// 停止接收新訊息；已預取但未 ack 的訊息會由 broker 重新投遞
+			ch.Cancel(tag, false)
+			ch.Close()
+			return false
+		case d, ok := <-deliveries:
+			if !ok {
+				return r.ctx.Err() == nil
+			}
+			r.handle(d, handler)
+		}
+	}
+}
+
+// handle 呼叫 handler 並依結果 ack / nack；panic 視為錯誤
+func (r *RabbitMQ) handle(d amqp.Delivery, handler Handler) {
+	err := callHandler(r.ctx, handler, Delivery{
+		Exchange:    d.Exchange,
+		RoutingKey:  d.RoutingKey,
+		MessageID:   d.MessageId,
+		ContentType: d.ContentType,
+		Headers:     d.Headers,
+		Timestamp:   d.Timestamp,
+		Redelivered: d.Redelivered,
+		Body:        d.Body,
+	})
+	if err != nil {
+		r.config.ErrorHandler(fmt.Errorf("rabbitmq: handle %s/%s: %w", d.Exchange, d.RoutingKey, err))
+	}
+	if r.config.AutoAck {
+		return
+	}
+
+	if err == nil {
+		err = d.Ack(false)
+	} else {
+		err = d.Nack(false, r.config.Requeue)
+	}
+	if err != nil {
+		r.config.ErrorHandler(fmt.Errorf("rabbitmq: ack: %w", err))
+	}
+}
+
+func callHandler(ctx context.Context, handler Handler, d Delivery) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+	return handler(ctx, d)
+}
@@ -0,0 +1,102 @@
+// @chris
+package rabbitmq
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// Publish 發佈訊息至 exchange（持久化投遞）
+// 連線中斷時會等待重連或直到 ctx 取消；mandatory 啟用時等待 broker 確認，
+// 無法路由回傳 ErrUnroutable，被拒絕回傳 ErrNacked。
+func (r *RabbitMQ) Publish(ctx context.Context, exchange, routingKey string, body []byte) error {
+	return r.PublishMessage(ctx, exchange, routingKey, amqp.Publishing{
+		ContentType: "application/octet-stream",
+		Body:        body,
+	})
+}
+
+// PublishMessage 發佈完整的 amqp.Publishing（可自訂 Headers、ContentType 等）
+func (r *RabbitMQ) PublishMessage(ctx context.Context, exchange, routingKey string, msg amqp.Publishing) error {
+	r.publishMu.Lock()
+	defer r.publishMu.Unlock()
+
+	ch, returns, err := r.publishChannel(ctx)
+	if err != nil {
+		return err
+	}
+
+	if msg.DeliveryMode == 0 {
+		msg.DeliveryMode = amqp.Persistent
+	}
+	if msg.Timestamp.IsZero() {
+		msg.Timestamp = time.Now()
+	}
+	if msg.MessageId == "" {
+		msg.MessageId = strconv.FormatUint(r.messageSeq.Add(1), 10)
+	}
+
+	if !r.config.Mandatory {
+		if err := ch.PublishWithContext(ctx, exchange, routingKey, false, false, msg); err != nil {
+			return fmt.Errorf("rabbitmq: publish to %s: %w", exchange, err)
+		}
+		return nil
+	}
+
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, exchange, routingKey, true, false, msg)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: publish to %s: %w", exchange, err)
+	}
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: wait confirm: %w", err)
+	}
+
+	if ret, ok := findReturn(returns, msg.MessageId); ok {
+		return fmt.Errorf("%w: %s (exchange=%s, routing_key=%s)", ErrUnroutable, ret.ReplyText, exchange, routingKey)
+	}
+	if !acked {
+		return ErrNacked
+	}
+	return nil
+}
+
+// findReturn 取出已退回的訊息並比對 MessageId
+// broker 會在 ack 之前送出 basic.return，確認後它必定已在 returns 中
+func findReturn(returns chan amqp.Return, messageID string) (amqp.Return, bool) {
+	for {
+		select {
+		case ret := <-returns:
+			if ret.MessageId == messageID {
+				return ret, true
+			}
+		default:
+			return amqp.Return{}, false
+		}
+	}
+}
+
+// publishChannel 取得發佈 channel；channel 因錯誤被 broker 關閉時重新開啟
+func (r *RabbitMQ) publishChannel(ctx context.Context) (*amqp.Channel, chan amqp.Return, error) {
+	conn, err := r.waitReady(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.pubCh != nil && !r.pubCh.IsClosed() {
+		return r.pubCh, r.returns, nil
+	}
+
+	ch, returns, err := openPublishChannel(conn, r.config.Mandatory)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.pubCh, r.returns = ch, returns
+	return ch, returns, nil
+}
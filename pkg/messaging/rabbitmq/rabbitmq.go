@@ -0,0 +1,517 @@
+// Package rabbitmq 提供 RabbitMQ 發佈 / 消費實作，連線中斷時自動以退避重連並重新宣告
+// exchange / queue，生命週期與 hidb.DatabasePlugin 一致（Name / Init / Connect / Close / Ping）。
+//
+// @chris
+package rabbitmq
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"gopkg.in/yaml.v3"
+)
+
+// ExchangeConfig 額外宣告的 exchange
+type ExchangeConfig struct {
+	Name       string `mapstructure:"name" yaml:"name"`
+	Type       string `mapstructure:"type" yaml:"type"` // "direct"（預設）、"fanout"、"topic"、"headers"
+	Durable    bool   `mapstructure:"durable" yaml:"durable"`
+	AutoDelete bool   `mapstructure:"auto_delete" yaml:"auto_delete"`
+}
+
+// BindingConfig queue 與 exchange 的綁定
+type BindingConfig struct {
+	Exchange   string `mapstructure:"exchange" yaml:"exchange"`
+	RoutingKey string `mapstructure:"routing_key" yaml:"routing_key"`
+}
+
+// QueueConfig 額外宣告的 queue
+type QueueConfig struct {
+	Name       string          `mapstructure:"name" yaml:"name"`
+	Durable    bool            `mapstructure:"durable" yaml:"durable"`
+	AutoDelete bool            `mapstructure:"auto_delete" yaml:"auto_delete"`
+	Exclusive  bool            `mapstructure:"exclusive" yaml:"exclusive"`
+	Bindings   []BindingConfig `mapstructure:"bindings" yaml:"bindings"`
+}
+
+// Config RabbitMQ 配置，欄位對應 rabbitmq.yaml
+// Exchange / Queue 為常用捷徑：兩者皆設定時會宣告為 durable 並以 RoutingKey 綁定
+type Config struct {
+	URL          string `mapstructure:"url" yaml:"url"`
+	Exchange     string `mapstructure:"exchange" yaml:"exchange"`
+	ExchangeType string `mapstructure:"exchange_type" yaml:"exchange_type"` // 預設 "direct"
+	Queue        string `mapstructure:"queue" yaml:"queue"`
+	RoutingKey   string `mapstructure:"routing_key" yaml:"routing_key"` // 預設與 Queue 同名
+
+	Exchanges []ExchangeConfig `mapstructure:"exchanges" yaml:"exchanges"`
+	Queues    []QueueConfig    `mapstructure:"queues" yaml:"queues"`
+
+	// 消費
+	PrefetchCount int  `mapstructure:"prefetch_count" yaml:"prefetch_count"` // 預設 10
+	AutoAck       bool `mapstructure:"auto_ack" yaml:"auto_ack"`             // false 時 handler 成功才 ack
+	Requeue       bool `mapstructure:"requeue" yaml:"requeue"`               // handler 失敗時是否重新入列
+
+	// 發佈：啟用後使用 publisher confirm，無法路由或被 nack 時 Publish 回傳錯誤
+	Mandatory bool `mapstructure:"mandatory" yaml:"mandatory"`
+
+	// 重連
+	DialTimeout       time.Duration `mapstructure:"dial_timeout" yaml:"dial_timeout"`               // 預設 10s
+	ReconnectDelay    time.Duration `mapstructure:"reconnect_delay" yaml:"reconnect_delay"`         // 預設 1s，指數遞增
+	MaxReconnectDelay time.Duration `mapstructure:"max_reconnect_delay" yaml:"max_reconnect_delay"` // 預設 30s
+
+	// ErrorHandler 接收重連與消費錯誤（預設寫入標準 log），不從 YAML 解析
+	ErrorHandler func(err error) `mapstructure:"-" yaml:"-"`
+}
+
+// 發佈錯誤
+var (
+	// ErrUnroutable mandatory 訊息無法路由至任何 queue
+	ErrUnroutable = errors.New("rabbitmq: message unroutable")
+	// ErrNacked broker 拒絕了訊息
+	ErrNacked = errors.New("rabbitmq: message nacked by broker")
+	// ErrClosed 實例已關閉
+	ErrClosed = errors.New("rabbitmq: closed")
+)
+
+// LoadConfig 讀取 rabbitmq.yaml；檔案可為頂層 rabbitmq: 區塊或直接的欄位
+func LoadConfig(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("rabbitmq: read config: %w", err)
+	}
+
+	var wrapped struct {
+		RabbitMQ *Config `yaml:"rabbitmq"`
+	}
+	if err := yaml.Unmarshal(data, &wrapped); err != nil {
+		return Config{}, fmt.Errorf("rabbitmq: parse config: %w", err)
+	}
+	if wrapped.RabbitMQ != nil {
+		return *wrapped.RabbitMQ, nil
+	}
+
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, fmt.Errorf("rabbitmq: parse config: %w", err)
+	}
+	return cfg, nil
+}
+
+// applyDefaults 填入預設值並驗證
+func (c *Config) applyDefaults() error {
+	if c.URL == "" {
+		return fmt.Errorf("rabbitmq: url is required")
+	}
+	if c.ExchangeType == "" {
+		c.ExchangeType = "direct"
+	}
+	if c.RoutingKey == "" {
+		c.RoutingKey = c.Queue
+	}
+	if c.PrefetchCount <= 0 {
+		c.PrefetchCount = 10
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = 10 * time.Second
+	}
+	if c.ReconnectDelay <= 0 {
+		c.ReconnectDelay = time.Second
+	}
+	if c.MaxReconnectDelay < c.ReconnectDelay {
+		c.MaxReconnectDelay = 30 * time.Second
+	}
+	if c.ErrorHandler == nil {
+		c.ErrorHandler = func(err error) {
+			log.Printf("[rabbitmq] %v", err)
+		}
+	}
+	return nil
+}
+
+// topology 彙整需宣告的 exchange 與 queue（含 Exchange / Queue 捷徑）
+func (c *Config) topology() ([]ExchangeConfig, []QueueConfig) {
+	exchanges := append([]ExchangeConfig{}, c.Exchanges...)
+	queues := append([]QueueConfig{}, c.Queues...)
+
+	if c.Exchange != "" {
+		exchanges = append(exchanges, ExchangeConfig{Name: c.Exchange, Type: c.ExchangeType, Durable: true})
+	}
+	if c.Queue != "" {
+		q := QueueConfig{Name: c.Queue, Durable: true}
+		if c.Exchange != "" {
+			q.Bindings = []BindingConfig{{Exchange: c.Exchange, RoutingKey: c.RoutingKey}}
+		}
+		queues = append(queues, q)
+	}
+	return exchanges, queues
+}
+
+// ===== 插件生命週期 =====
+
+// RabbitMQ 管理單一 AMQP 連線、發佈 channel 與所有消費者
+//
+// EX：
+//
+//	cfg, _ := rabbitmq.LoadConfig("config/rabbitmq.yaml")
+//	mq, err := rabbitmq.New(cfg)
+//	if err != nil { ... }
+//	defer mq.Close()
+//
+//	mq.Publish(ctx, "hypgo", "default", body)
+//	mq.Consume("default", func(ctx context.Context, d rabbitmq.Delivery) error {
+//		return handle(d.Body)
+//	})
+type RabbitMQ struct {
+	config Config
+
+	mu      sync.Mutex
+	conn    *amqp.Connection
+	pubCh   *amqp.Channel
+	returns chan amqp.Return
+	ready   chan struct{} // 連線可用時關閉；斷線時換新
+	closed  bool
+
+	ctx       context.Context
+	cancel    context.CancelFunc
+	wg        sync.WaitGroup // 斷線監看
+	consumers sync.WaitGroup
+
+	publishMu   sync.Mutex
+	consumerSeq atomic.Uint64
+	messageSeq  atomic.Uint64
+}
+
+// New 創建 RabbitMQ 實例並建立連線
+func New(cfg Config) (*RabbitMQ, error) {
+	r := &RabbitMQ{config: cfg}
+	if err := r.Connect(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Name 插件名稱
+func (r *RabbitMQ) Name() string {
+	return "rabbitmq"
+}
+
+// Init 從 map 配置初始化（用於插件系統動態加載），欄位名稱與 rabbitmq.yaml 相同
+func (r *RabbitMQ) Init(config map[string]interface{}) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: encode config: %w", err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return fmt.Errorf("rabbitmq: decode config: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	cfg.ErrorHandler = r.config.ErrorHandler
+	r.config = cfg
+	return nil
+}
+
+// Connect 建立連線、宣告 topology 並啟動斷線監看
+func (r *RabbitMQ) Connect() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return ErrClosed
+	}
+	if r.conn != nil {
+		r.mu.Unlock()
+		return nil
+	}
+	if err := r.config.applyDefaults(); err != nil {
+		r.mu.Unlock()
+		return err
+	}
+	if r.ctx == nil {
+		r.ctx, r.cancel = context.WithCancel(context.Background())
+		r.ready = make(chan struct{})
+	}
+	r.mu.Unlock()
+
+	conn, pubCh, returns, err := r.dial()
+	if err != nil {
+		if r.ctx.Err() != nil {
+			return ErrClosed
+		}
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	switch {
+	case r.closed:
+		conn.Close()
+		return ErrClosed
+	case r.conn != nil:
+		// 同時呼叫的 Connect 已先完成
+		conn.Close()
+		return nil
+	}
+	notify := r.install(conn, pubCh, returns)
+	r.wg.Add(1)
+	go r.watch(notify)
+	return nil
+}
+
+// dial 建立連線並完成初始化；不持有 r.mu，連線期間 Publish / Close 不會被阻塞，Close 會中斷進行中的連線
+func (r *RabbitMQ) dial() (*amqp.Connection, *amqp.Channel, chan amqp.Return, error) {
+	r.mu.Lock()
+	cfg, ctx := r.config, r.ctx
+	r.mu.Unlock()
+
+	stop := func() bool { return false }
+	conn, err := amqp.DialConfig(cfg.URL, amqp.Config{
+		Heartbeat: 10 * time.Second,
+		Locale:    "en_US",
+		Dial: func(network, addr string) (net.Conn, error) {
+			d := net.Dialer{Timeout: cfg.DialTimeout}
+			c, err := d.DialContext(ctx, network, addr)
+			if err != nil {
+				return nil, err
+			}
+			// handshake 尚未完成前 Close 時直接關閉底層連線
+			stop = context.AfterFunc(ctx, func() { c.Close() })
+			return c, c.SetDeadline(time.Now().Add(cfg.DialTimeout))
+		},
+	})
+	stop()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("rabbitmq: dial: %w", err)
+	}
+
+	if err := declare(conn, cfg); err != nil {
+		conn.Close()
+		return nil, nil, nil, err
+	}
+	pubCh, returns, err := openPublishChannel(conn, cfg.Mandatory)
+	if err != nil {
+		conn.Close()
+		return nil, nil, nil, err
+	}
+	return conn, pubCh, returns, nil
+}
+
+// install 換上新連線並喚醒等待中的呼叫，回傳斷線通知 channel（必須持有 r.mu）
+func (r *RabbitMQ) install(conn *amqp.Connection, pubCh *amqp.Channel, returns chan amqp.Return) chan *amqp.Error {
+	r.conn, r.pubCh, r.returns = conn, pubCh, returns
+	close(r.ready)
+	return conn.NotifyClose(make(chan *amqp.Error, 1))
+}
+
+// declare 宣告 exchange / queue 並建立綁定；重連後會再次執行
+func declare(conn *amqp.Connection, cfg Config) error {
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("rabbitmq: open channel: %w", err)
+	}
+	defer ch.Close()
+
+	exchanges, queues := cfg.topology()
+	for _, ex := range exchanges {
+		kind := ex.Type
+		if kind == "" {
+			kind = "direct"
+		}
+		if err := ch.ExchangeDeclare(ex.Name, kind, ex.Durable, ex.AutoDelete, false, false, nil); err != nil {
+			return fmt.Errorf("rabbitmq: declare exchange %s: %w", ex.Name, err)
+		}
+	}
+	for _, q := range queues {
+		if err := declareQueue(ch, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declareQueue 宣告單一 queue 及其綁定
+func declareQueue(ch *amqp.Channel, q QueueConfig) error {
+	if _, err := ch.QueueDeclare(q.Name, q.Durable, q.AutoDelete, q.Exclusive, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: declare queue %s: %w", q.Name, err)
+	}
+	for _, b := range q.Bindings {
+		if err := ch.QueueBind(q.Name, b.RoutingKey, b.Exchange, false, nil); err != nil {
+			return fmt.Errorf("rabbitmq: bind queue %s to %s: %w", q.Name, b.Exchange, err)
+		}
+	}
+	return nil
+}
+
+// DeclareQueue 宣告 queue 並建立綁定；設定會被保留，重連後自動重新宣告
+func (r *RabbitMQ) DeclareQueue(ctx context.Context, q QueueConfig) error {
+	conn, err := r.waitReady(ctx)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	exists := false
+	for _, existing := range r.config.Queues {
+		if existing.Name == q.Name {
+			exists = true
+			break
+		}
+	}
+	if !exists {
+		r.config.Queues = append(r.config.Queues, q)
+	}
+	r.mu.Unlock()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return fmt.Errorf("rabbitmq: open channel: %w", err)
+	}
+	defer ch.Close()
+	return declareQueue(ch, q)
+}
+
+// openPublishChannel 開啟發佈用 channel；mandatory 時啟用 confirm 並接收退回訊息
+func openPublishChannel(conn *amqp.Connection, mandatory bool) (*amqp.Channel, chan amqp.Return, error) {
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, nil, fmt.Errorf("rabbitmq: open publish channel: %w", err)
+	}
+	if !mandatory {
+		return ch, nil, nil
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, nil, fmt.Errorf("rabbitmq: enable confirms: %w", err)
+	}
+	returns := ch.NotifyReturn(make(chan amqp.Return, 16))
+	return ch, returns, nil
+}
+
+// watch 監看連線中斷並以指數退避重連
+func (r *RabbitMQ) watch(notify chan *amqp.Error) {
+	defer r.wg.Done()
+
+	for {
+		closeErr, ok := <-notify
+		if !ok || closeErr == nil {
+			// 由 Close 主動關閉
+			return
+		}
+
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			return
+		}
+		r.conn, r.pubCh, r.returns = nil, nil, nil
+		r.ready = make(chan struct{})
+		r.mu.Unlock()
+		r.config.ErrorHandler(fmt.Errorf("rabbitmq: connection lost: %v", closeErr))
+
+		delay := r.config.ReconnectDelay
+		for {
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(delay):
+			}
+
+			// 連線期間不持有 r.mu，成功後才換上新連線
+			conn, pubCh, returns, err := r.dial()
+			if err == nil {
+				r.mu.Lock()
+				if r.closed {
+					r.mu.Unlock()
+					conn.Close()
+					return
+				}
+				notify = r.install(conn, pubCh, returns)
+				r.mu.Unlock()
+				break
+			}
+			if r.ctx.Err() != nil {
+				return
+			}
+
+			r.config.ErrorHandler(fmt.Errorf("rabbitmq: reconnect: %w", err))
+			delay *= 2
+			if delay > r.config.MaxReconnectDelay {
+				delay = r.config.MaxReconnectDelay
+			}
+		}
+	}
+}
+
+// waitReady 等待連線可用
+func (r *RabbitMQ) waitReady(ctx context.Context) (*amqp.Connection, error) {
+	for {
+		r.mu.Lock()
+		if r.closed {
+			r.mu.Unlock()
+			return nil, ErrClosed
+		}
+		if r.ctx == nil {
+			r.mu.Unlock()
+			return nil, fmt.Errorf("rabbitmq: not connected")
+		}
+		conn, ready := r.conn, r.ready
+		r.mu.Unlock()
+
+		if conn != nil && !conn.IsClosed() {
+			return conn, nil
+		}
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-r.ctx.Done():
+			return nil, ErrClosed
+		}
+	}
+}
+
+// Close 取消所有消費者並關閉連線
+func (r *RabbitMQ) Close() error {
+	r.mu.Lock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	conn := r.conn
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.mu.Unlock()
+
+	// 先等消費者 Cancel 各自的 consumer tag 並處理完手上的訊息，再關閉連線
+	r.consumers.Wait()
+	var err error
+	if conn != nil {
+		err = conn.Close()
+	}
+	r.wg.Wait()
+	return err
+}
+
+// Ping 連線可用時回傳 nil
+func (r *RabbitMQ) Ping(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return ErrClosed
+	}
+	if r.conn == nil || r.conn.IsClosed() {
+		return fmt.Errorf("rabbitmq: not connected")
+	}
+	return nil
+}
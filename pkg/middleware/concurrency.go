@@ -0,0 +1,156 @@
+// @chris
+package middleware
+
+import (
+	stdcontext "context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"golang.org/x/sync/semaphore"
+)
+
+// ===== 並行數限制中間件 =====
+
+// ConcurrencyConfig 並行數限制配置
+type ConcurrencyConfig struct {
+	// MaxQueue 名額用盡時最多排隊等待的請求數，0 表示不排隊直接拒絕
+	MaxQueue int
+	// QueueTimeout 排隊的最長等待時間，0 表示只受請求 context 限制
+	QueueTimeout time.Duration
+	// KeyFunc 依鍵分別限制（例如每位使用者），nil 表示掛載的路由共用同一組名額
+	KeyFunc KeyFunc
+	// WeightFunc 請求佔用的名額數（例如依匯出範圍），預設 1，超過 n 時以 n 計
+	WeightFunc func(c *hypcontext.Context) int64
+	// RetryAfter 拒絕時回應的 Retry-After，預設 1s
+	RetryAfter time.Duration
+	// StatusCode 拒絕時的狀態碼，預設 503
+	StatusCode int
+}
+
+// MaxConcurrent 創建並行數限制中間件，限制掛載的路由或群組同時處理的請求數最多 n 個
+// 名額用盡時最多 MaxQueue 個請求排隊等待，佇列已滿、等待逾時或客戶端斷線時回應 503 + Retry-After
+// 與 RateLimiter 限制每秒請求數不同，這裡限制的是同時執行中的數量，適合報表產生等昂貴的端點
+//
+// EX：
+//
+//	reports := r.NewGroup("/reports", middleware.MaxConcurrent(4, middleware.ConcurrencyConfig{
+//	    MaxQueue:     16,
+//	    QueueTimeout: 10 * time.Second,
+//	}))
+//
+//	// 每位使用者最多同時 2 個匯出
+//	r.GET("/export", middleware.MaxConcurrent(2, middleware.ConcurrencyConfig{
+//	    KeyFunc: func(c *context.Context) string { return c.GetString("user_id") },
+//	}), exportHandler)
+func MaxConcurrent(n int, config ConcurrencyConfig) hypcontext.HandlerFunc {
+	if n <= 0 {
+		panic("middleware: MaxConcurrent requires n > 0")
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.StatusCode == 0 {
+		config.StatusCode = http.StatusServiceUnavailable
+	}
+	retryAfter := strconv.Itoa(int(math.Ceil(config.RetryAfter.Seconds())))
+
+	limiter := &concurrencyLimiter{
+		size:     int64(n),
+		maxQueue: config.MaxQueue,
+		entries:  make(map[string]*concurrencyEntry),
+	}
+
+	return func(c *hypcontext.Context) {
+		var key string
+		if config.KeyFunc != nil {
+			key = config.KeyFunc(c)
+		}
+		weight := int64(1)
+		if config.WeightFunc != nil {
+			weight = min(max(config.WeightFunc(c), 1), int64(n))
+		}
+
+		ctx := c.Request.Context()
+		if config.QueueTimeout > 0 {
+			var cancel stdcontext.CancelFunc
+			ctx, cancel = stdcontext.WithTimeout(ctx, config.QueueTimeout)
+			defer cancel()
+		}
+
+		entry := limiter.acquire(key)
+		defer limiter.release(key, entry)
+
+		if !entry.admit(ctx, weight, limiter.maxQueue) {
+			c.Header("Retry-After", retryAfter)
+			c.AbortWithStatus(config.StatusCode)
+			return
+		}
+		defer entry.sem.Release(weight)
+
+		c.Next()
+	}
+}
+
+// concurrencyLimiter 依鍵管理信號量；無人使用的鍵即刪除，不需定期清理
+type concurrencyLimiter struct {
+	size     int64
+	maxQueue int
+
+	mu      sync.Mutex
+	entries map[string]*concurrencyEntry
+}
+
+// concurrencyEntry 單一鍵的信號量與排隊狀態
+type concurrencyEntry struct {
+	sem     *semaphore.Weighted
+	refs    int // 持有或等待名額的請求數，受 concurrencyLimiter.mu 保護
+	mu      sync.Mutex
+	waiting int
+}
+
+func (l *concurrencyLimiter) acquire(key string) *concurrencyEntry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry, ok := l.entries[key]
+	if !ok {
+		entry = &concurrencyEntry{sem: semaphore.NewWeighted(l.size)}
+		l.entries[key] = entry
+	}
+	entry.refs++
+	return entry
+}
+
+func (l *concurrencyLimiter) release(key string, entry *concurrencyEntry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry.refs--
+	if entry.refs == 0 {
+		delete(l.entries, key)
+	}
+}
+
+// admit 立即取得名額，或在佇列未滿時排隊等待
+func (e *concurrencyEntry) admit(ctx stdcontext.Context, weight int64, maxQueue int) bool {
+	if e.sem.TryAcquire(weight) {
+		return true
+	}
+
+	e.mu.Lock()
+	if e.waiting >= maxQueue {
+		e.mu.Unlock()
+		return false
+	}
+	e.waiting++
+	e.mu.Unlock()
+
+	err := e.sem.Acquire(ctx, weight)
+
+	e.mu.Lock()
+	e.waiting--
+	e.mu.Unlock()
+	return err == nil
+}
@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func TestMaxConcurrentQueueAndReject(t *testing.T) {
+	release := make(chan struct{})
+	var entered sync.WaitGroup
+	r := router.New()
+	r.GET("/report", MaxConcurrent(2, ConcurrencyConfig{MaxQueue: 1}), func(c *context.Context) {
+		entered.Done()
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	codes := make(chan int, 3)
+	send := func() {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+		codes <- w.Code
+	}
+
+	// 佔滿 2 個名額
+	entered.Add(2)
+	go send()
+	go send()
+	entered.Wait()
+
+	// 第 3 個排隊
+	entered.Add(1)
+	go send()
+	time.Sleep(20 * time.Millisecond)
+
+	// 第 4 個：名額與佇列皆滿，立即拒絕
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Fatalf("Expected 503 with Retry-After, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// 釋放一個名額後，排隊的請求開始執行
+	release <- struct{}{}
+	entered.Wait()
+	close(release)
+	for i := 0; i < 3; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Errorf("Expected admitted requests to succeed, got %d", code)
+		}
+	}
+}
+
+func TestMaxConcurrentQueueTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	entered := make(chan struct{}, 1)
+	r := router.New()
+	r.GET("/report", MaxConcurrent(1, ConcurrencyConfig{MaxQueue: 4, QueueTimeout: 10 * time.Millisecond}), func(c *context.Context) {
+		entered <- struct{}{}
+		<-release
+	})
+
+	go r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/report", nil))
+	<-entered
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/report", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected queued request to time out with 503, got %d", w.Code)
+	}
+}
+
+func TestMaxConcurrentPerKey(t *testing.T) {
+	release := make(chan struct{})
+	entered := make(chan struct{}, 2)
+	limit := MaxConcurrent(1, ConcurrencyConfig{
+		KeyFunc: func(c *context.Context) string { return c.GetHeader("X-User") },
+	})
+	r := router.New()
+	r.GET("/export", limit, func(c *context.Context) {
+		entered <- struct{}{}
+		<-release
+		c.Status(http.StatusOK)
+	})
+
+	request := func(user string) *http.Request {
+		req := httptest.NewRequest("GET", "/export", nil)
+		req.Header.Set("X-User", user)
+		return req
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	for _, user := range []string{"alice", "bob"} {
+		go func() {
+			defer wg.Done()
+			r.ServeHTTP(httptest.NewRecorder(), request(user))
+		}()
+	}
+	// 不同使用者各自有名額
+	<-entered
+	<-entered
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, request("alice"))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected alice's second export rejected, got %d", w.Code)
+	}
+	close(release)
+	wg.Wait()
+
+	// 名額釋放後可再次取得
+	w = httptest.NewRecorder()
+	go func() { <-entered }()
+	r.ServeHTTP(w, request("alice"))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected alice admitted after release, got %d", w.Code)
+	}
+}
@@ -2,6 +2,7 @@
 package middleware
 
 import (
+	"crypto/hmac"
 	crand "crypto/rand"
 	"crypto/subtle"
 	"encoding/base64"
@@ -15,10 +16,15 @@ import (
 
 // ===== CSRF 中間件 =====
 
+// csrfSessionKey token 存放於 Session 時使用的 key
+const csrfSessionKey = "_csrf"
+
 // CSRFConfig CSRF 配置
 type CSRFConfig struct {
 	TokenLength    int
-	TokenLookup    string // "header:X-CSRF-Token" or "form:csrf_token"
+	TokenLookup    string   // 逗號分隔，依序嘗試，預設 "header:X-CSRF-Token,form:csrf_token"
+	ExemptPaths    []string // 不檢查的路徑，結尾為 "*" 時做前綴比對（如 "/webhooks/*"）
+	Secret         []byte   // 非空時以 HMAC 簽章 cookie 中的 token（未掛 Session 中間件時使用）
 	CookieName     string
 	CookieDomain   string
 	CookiePath     string
@@ -30,12 +36,15 @@ type CSRFConfig struct {
 }
 
 // CSRF 創建 CSRF 保護中間件
+// 已掛載 Session 中間件時 token 綁定於 session，否則使用（可簽章的）double-submit cookie；
+// 安全方法（GET/HEAD/OPTIONS）僅簽發 token，其餘方法驗證失敗時回傳 403。
+// 模板中可使用 c.CSRFToken() / c.CSRFField() 取得 token 與隱藏欄位。
 func CSRF(config CSRFConfig) hypcontext.HandlerFunc {
 	if config.TokenLength == 0 {
 		config.TokenLength = 32
 	}
 	if config.TokenLookup == "" {
-		config.TokenLookup = "header:X-CSRF-Token"
+		config.TokenLookup = "header:X-CSRF-Token,form:csrf_token"
 	}
 	if config.CookieName == "" {
 		config.CookieName = "_csrf"
@@ -46,45 +55,41 @@ func CSRF(config CSRFConfig) hypcontext.HandlerFunc {
 
 	return func(c *hypcontext.Context) {
 		// 檢查是否跳過
-		if config.Skipper != nil && config.Skipper(c) {
+		if (config.Skipper != nil && config.Skipper(c)) || isCSRFExempt(c.Request.URL.Path, config.ExemptPaths) {
 			c.Next()
 			return
 		}
 
-		// 對於安全的方法（GET, HEAD, OPTIONS），只生成 token
+		expected := loadCSRFToken(c, config)
+
+		// 對於安全的方法（GET, HEAD, OPTIONS），只簽發 token
 		if isSafeMethod(c.Request.Method) {
-			token := generateCSRFToken(config.TokenLength)
-			c.Set("csrf_token", token)
-			setCSRFCookie(c, config, token)
+			if expected == "" {
+				expected = generateCSRFToken(config.TokenLength)
+				if err := storeCSRFToken(c, config, expected); err != nil {
+					c.AbortWithError(http.StatusInternalServerError, err)
+					return
+				}
+			}
+			c.Set("csrf_token", expected)
 			c.Next()
 			return
 		}
 
 		// 對於不安全的方法，驗證 token
-		cookie, err := c.Cookie(config.CookieName)
-		if err != nil || cookie == "" {
+		if expected == "" {
 			handleCSRFError(c, config)
 			return
 		}
 
 		// 從請求中提取 token
 		token := extractCSRFToken(c, config.TokenLookup)
-		if token == "" {
+		if token == "" || !validateCSRFToken(token, expected) {
 			handleCSRFError(c, config)
 			return
 		}
 
-		// 驗證 token
-		if !validateCSRFToken(token, cookie) {
-			handleCSRFError(c, config)
-			return
-		}
-
-		// 設置新的 token
-		newToken := generateCSRFToken(config.TokenLength)
-		c.Set("csrf_token", newToken)
-		setCSRFCookie(c, config, newToken)
-
+		c.Set("csrf_token", expected)
 		c.Next()
 	}
 }
@@ -96,6 +101,20 @@ func isSafeMethod(method string) bool {
 		method == http.MethodOptions
 }
 
+// isCSRFExempt 檢查路徑是否在豁免清單中
+func isCSRFExempt(path string, exempt []string) bool {
+	for _, p := range exempt {
+		if prefix, ok := strings.CutSuffix(p, "*"); ok {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		} else if path == p {
+			return true
+		}
+	}
+	return false
+}
+
 // generateCSRFToken 使用 crypto/rand 生成安全 CSRF token
 func generateCSRFToken(length int) string {
 	b := make([]byte, length)
@@ -108,41 +127,77 @@ func generateCSRFToken(length int) string {
 	return base64.URLEncoding.EncodeToString(b)
 }
 
-// extractCSRFToken 從請求中提取 CSRF token
-func extractCSRFToken(c *hypcontext.Context, lookup string) string {
-	parts := strings.Split(lookup, ":")
-	if len(parts) != 2 {
-		return ""
+// loadCSRFToken 取得目前有效的 token：優先從 Session，否則從 cookie（驗證簽章）
+func loadCSRFToken(c *hypcontext.Context, config CSRFConfig) string {
+	if sess := c.Session(); sess != nil {
+		token, _ := sess.Get(csrfSessionKey).(string)
+		return token
 	}
 
-	switch parts[0] {
-	case "header":
-		return c.GetHeader(parts[1])
-	case "form":
-		return c.PostForm(parts[1])
-	case "query":
-		return c.Query(parts[1])
-	default:
+	value, err := c.Cookie(config.CookieName)
+	if err != nil || value == "" {
+		return ""
+	}
+	if len(config.Secret) == 0 {
+		return value
+	}
+	token, sig, ok := strings.Cut(value, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(hmacSign(token, config.Secret))) {
 		return ""
 	}
+	return token
 }
 
-// validateCSRFToken 驗證 CSRF token
-func validateCSRFToken(token, cookie string) bool {
-	return subtle.ConstantTimeCompare([]byte(token), []byte(cookie)) == 1
-}
+// storeCSRFToken 保存新 token 到 Session 或 cookie
+func storeCSRFToken(c *hypcontext.Context, config CSRFConfig, token string) error {
+	if sess := c.Session(); sess != nil {
+		sess.Set(csrfSessionKey, token)
+		return sess.Save()
+	}
 
-// setCSRFCookie 設置 CSRF cookie
-func setCSRFCookie(c *hypcontext.Context, config CSRFConfig, token string) {
+	value := token
+	if len(config.Secret) > 0 {
+		value = token + "." + hmacSign(token, config.Secret)
+	}
 	c.SetCookie(
 		config.CookieName,
-		token,
+		value,
 		config.CookieMaxAge,
 		config.CookiePath,
 		config.CookieDomain,
 		config.CookieSecure,
 		config.CookieHTTPOnly,
 	)
+	return nil
+}
+
+// extractCSRFToken 從請求中提取 CSRF token，依 lookup 順序回傳第一個非空值
+func extractCSRFToken(c *hypcontext.Context, lookup string) string {
+	for _, source := range strings.Split(lookup, ",") {
+		parts := strings.SplitN(strings.TrimSpace(source), ":", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		var token string
+		switch parts[0] {
+		case "header":
+			token = c.GetHeader(parts[1])
+		case "form":
+			token = c.PostForm(parts[1])
+		case "query":
+			token = c.Query(parts[1])
+		}
+		if token != "" {
+			return token
+		}
+	}
+	return ""
+}
+
+// validateCSRFToken 驗證 CSRF token
+func validateCSRFToken(token, expected string) bool {
+	return subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1
 }
 
 // handleCSRFError 處理 CSRF 錯誤
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func newCSRFRouter(handlers ...context.HandlerFunc) *router.Router {
+	r := router.New()
+	r.Use(handlers...)
+	r.GET("/form", func(c *context.Context) {
+		c.String(http.StatusOK, string(c.CSRFField()))
+	})
+	r.POST("/submit", func(c *context.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	r.POST("/webhooks/github", func(c *context.Context) {
+		c.String(http.StatusOK, "hook")
+	})
+	return r
+}
+
+// issueCSRF 以 GET 取得 token 與需回傳的 cookie
+func issueCSRF(t *testing.T, r *router.Router) (string, []*http.Cookie) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/form", nil))
+	body := w.Body.String()
+	const marker = `value="`
+	i := strings.Index(body, marker)
+	if i < 0 {
+		t.Fatalf("csrf field not rendered: %q", body)
+	}
+	token := body[i+len(marker):]
+	token = token[:strings.Index(token, `"`)]
+	return token, w.Result().Cookies()
+}
+
+func TestCSRFDoubleSubmit(t *testing.T) {
+	r := newCSRFRouter(CSRF(CSRFConfig{Secret: []byte("secret")}))
+	token, cookies := issueCSRF(t, r)
+
+	// 無 token → 403
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/submit", nil)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 without token, got %d", w.Code)
+	}
+
+	// header token → 200
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/submit", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 with header token, got %d", w.Code)
+	}
+
+	// form token → 200
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("POST", "/submit", strings.NewReader(url.Values{"csrf_token": {token}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 with form token, got %d", w.Code)
+	}
+}
+
+func TestCSRFForgedCookie(t *testing.T) {
+	r := newCSRFRouter(CSRF(CSRFConfig{Secret: []byte("secret")}))
+
+	// 攻擊者自行設置未簽章的 cookie 與相同 token
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/submit", nil)
+	req.Header.Set("X-CSRF-Token", "forged")
+	req.AddCookie(&http.Cookie{Name: "_csrf", Value: "forged"})
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for unsigned cookie, got %d", w.Code)
+	}
+}
+
+func TestCSRFExemptPaths(t *testing.T) {
+	r := newCSRFRouter(CSRF(CSRFConfig{ExemptPaths: []string{"/webhooks/*"}}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/webhooks/github", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected exempt path to pass, got %d", w.Code)
+	}
+}
+
+func TestCSRFWithSession(t *testing.T) {
+	r := newCSRFRouter(Session(SessionConfig{}), CSRF(CSRFConfig{}))
+	token, cookies := issueCSRF(t, r)
+
+	for _, ck := range cookies {
+		if ck.Name == "_csrf" {
+			t.Fatal("CSRF cookie should not be set when session is available")
+		}
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/submit", nil)
+	req.Header.Set("X-CSRF-Token", token)
+	for _, ck := range cookies {
+		req.AddCookie(ck)
+	}
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected 200 with session-bound token, got %d", w.Code)
+	}
+}
@@ -0,0 +1,113 @@
+// @chris
+package middleware
+
+import (
+	"bufio"
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== 請求解壓縮中間件 =====
+
+// DecompressConfig 請求 body 解壓縮配置
+type DecompressConfig struct {
+	MaxBytes int64 // 解壓縮後的上限（bytes），預設 10MB，防止 zip bomb
+}
+
+var (
+	errDecompressedTooLarge = errors.New("decompressed body too large")
+	errUnsupportedEncoding  = errors.New("unsupported content encoding")
+)
+
+// DecompressRequest 依 Content-Encoding（gzip / x-gzip / deflate）解壓縮請求 body，
+// 之後的 GetRawData、Bind 與直接讀取 Request.Body 都會拿到明文；
+// 解壓縮後超過 MaxBytes 回傳 413，內容損毀回傳 400，不支援的編碼回傳 415
+//
+// EX：
+//
+//	r.Use(middleware.DecompressRequest(middleware.DecompressConfig{MaxBytes: 5 << 20}))
+func DecompressRequest(config DecompressConfig) hypcontext.HandlerFunc {
+	if config.MaxBytes <= 0 {
+		config.MaxBytes = 10 << 20 // 10MB
+	}
+
+	return func(c *hypcontext.Context) {
+		encoding := strings.ToLower(strings.TrimSpace(c.GetHeader("Content-Encoding")))
+		if encoding == "" || encoding == "identity" || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		body, err := decompressBody(c.Request.Body, encoding, config.MaxBytes)
+		c.Request.Body.Close()
+		switch {
+		case errors.Is(err, errDecompressedTooLarge):
+			c.AbortWithStatus(http.StatusRequestEntityTooLarge)
+			return
+		case errors.Is(err, errUnsupportedEncoding):
+			c.AbortWithStatus(http.StatusUnsupportedMediaType)
+			return
+		case err != nil:
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		// 還原為明文 body，移除編碼相關 header 避免下游重複解壓
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+		c.Request.ContentLength = int64(len(body))
+		c.Request.Header.Set("Content-Length", strconv.Itoa(len(body)))
+		c.Request.Header.Del("Content-Encoding")
+		c.Next()
+	}
+}
+
+// decompressBody 讀取並解壓縮 body，最多讀出 max 位元組
+func decompressBody(body io.Reader, encoding string, max int64) ([]byte, error) {
+	var reader io.ReadCloser
+	switch encoding {
+	case "gzip", "x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return nil, err
+		}
+		reader = gz
+	case "deflate":
+		// HTTP 的 deflate 應為 zlib 格式，但不少客戶端送出原始 deflate，依標頭判斷
+		br := bufio.NewReader(body)
+		if header, err := br.Peek(2); err == nil && isZlibHeader(header) {
+			zr, err := zlib.NewReader(br)
+			if err != nil {
+				return nil, err
+			}
+			reader = zr
+		} else {
+			reader = flate.NewReader(br)
+		}
+	default:
+		return nil, errUnsupportedEncoding
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(io.LimitReader(reader, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, errDecompressedTooLarge
+	}
+	return data, nil
+}
+
+// isZlibHeader RFC 1950：CM 為 8 且前兩個位元組組成的值可被 31 整除
+func isZlibHeader(b []byte) bool {
+	return b[0]&0x0f == 8 && (uint16(b[0])<<8|uint16(b[1]))%31 == 0
+}
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func compressBody(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "raw":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	w.Write(data)
+	w.Close()
+	return buf.Bytes()
+}
+
+func newDecompressRouter(config DecompressConfig) *router.Router {
+	r := router.New()
+	r.Use(DecompressRequest(config))
+	r.POST("/users", func(c *context.Context) {
+		var u struct {
+			Name string `json:"name"`
+		}
+		if err := c.ShouldBindJSON(&u); err != nil {
+			c.String(http.StatusBadRequest, err.Error())
+			return
+		}
+		c.String(http.StatusOK, u.Name+"|"+c.GetHeader("Content-Encoding"))
+	})
+	return r
+}
+
+func TestDecompressRequest(t *testing.T) {
+	r := newDecompressRouter(DecompressConfig{})
+	payload := []byte(`{"name":"alice"}`)
+
+	for _, tc := range []struct{ header, format string }{
+		{"gzip", "gzip"},
+		{"x-gzip", "gzip"},
+		{"deflate", "zlib"},
+		{"deflate", "raw"},
+	} {
+		req := httptest.NewRequest("POST", "/users", bytes.NewReader(compressBody(t, tc.format, payload)))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", tc.header)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != "alice|" {
+			t.Errorf("%s/%s: got %d %q", tc.header, tc.format, w.Code, w.Body.String())
+		}
+	}
+
+	// 未壓縮的請求原樣通過
+	req := httptest.NewRequest("POST", "/users", bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "alice|" {
+		t.Errorf("plain body: got %q", w.Body.String())
+	}
+}
+
+func TestDecompressRequestErrors(t *testing.T) {
+	r := newDecompressRouter(DecompressConfig{MaxBytes: 1024})
+	bomb := compressBody(t, "gzip", []byte(strings.Repeat("a", 1<<20)))
+
+	for _, tc := range []struct {
+		name, encoding string
+		body           []byte
+		want           int
+	}{
+		{"zip bomb", "gzip", bomb, http.StatusRequestEntityTooLarge},
+		{"corrupt", "gzip", []byte("not gzip"), http.StatusBadRequest},
+		{"unsupported", "br", []byte("x"), http.StatusUnsupportedMediaType},
+	} {
+		req := httptest.NewRequest("POST", "/users", bytes.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", tc.encoding)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tc.want {
+			t.Errorf("%s: expected %d, got %d", tc.name, tc.want, w.Code)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+// @chris
+package middleware
+
+import (
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== 錯誤收集輸出 =====
+
+// ErrorHandler 處理鏈結束後，若有以 c.Error / c.AddPublicError 收集的錯誤且尚未寫出回應，
+// 以 {"errors":[...]} 輸出公開錯誤；私有錯誤只以狀態碼文字呈現，細節留在 c.Errors
+// 狀態碼沿用處理器以 c.Status 設定的 4xx/5xx，否則為 500
+//
+// EX：
+//
+//	srv.Use(middleware.ErrorHandler())
+//	r.GET("/orders/:id", func(c *hypcontext.Context) {
+//	    if err := load(c.Param("id")); err != nil {
+//	        c.Status(http.StatusNotFound)
+//	        c.AddPublicError(err) // → 404 {"errors":["order not found"]}
+//	    }
+//	})
+func ErrorHandler() hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		c.Next()
+		c.RenderErrors()
+	}
+}
@@ -0,0 +1,341 @@
+// @chris
+package middleware
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/redis/go-redis/v9"
+)
+
+// ===== 冪等鍵中間件 =====
+
+// HeaderIdempotentReplayed 重播已保存回應時附加的標頭
+const HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLen 冪等鍵的最大長度
+const maxIdempotencyKeyLen = 255
+
+// IdempotentResponse 保存的第一次回應
+type IdempotentResponse struct {
+	Status      int         `json:"status"`
+	Header      http.Header `json:"header,omitempty"`
+	Body        []byte      `json:"body,omitempty"`
+	Fingerprint string      `json:"fingerprint"` // 請求 body 的 SHA-256，用於偵測同一個鍵搭配不同內容
+}
+
+// IdempotencyStore 冪等鍵儲存層介面
+type IdempotencyStore interface {
+	// Reserve 原子地將 key 標記為處理中，lockTTL 後自動失效（處理器異常終止時不會永久鎖住）
+	// 成功佔用回傳 (nil, true, nil)；已有保存的回應回傳 (resp, false, nil)；仍在處理中回傳 (nil, false, nil)
+	Reserve(ctx context.Context, key string, lockTTL time.Duration) (*IdempotentResponse, bool, error)
+	// Complete 保存回應並取代處理中標記
+	Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error
+	// Release 移除處理中標記，讓相同的鍵可以重試
+	Release(ctx context.Context, key string) error
+}
+
+// IdempotencyConfig 冪等鍵配置
+type IdempotencyConfig struct {
+	TTL          time.Duration // 回應保存時間，預設 24 小時
+	LockTTL      time.Duration // 處理中標記的存活時間，預設 1 分鐘，應長於處理器的最長執行時間
+	HeaderName   string        // 預設 "Idempotency-Key"
+	Methods      []string      // 套用的方法，預設 POST、PATCH
+	Required     bool          // 套用的方法未帶鍵時回應 400
+	MaxBodyBytes int           // 超過此大小的回應不保存，預設 1MB
+	// UserFunc 以使用者區隔鍵，避免不同使用者的相同鍵互相重播；預設使用 c.GetUserID()
+	UserFunc func(c *hypcontext.Context) string
+}
+
+// Idempotency 創建冪等鍵中間件，讓付款、下單等 POST 可以安全重試
+// 帶有 Idempotency-Key 的請求以「鍵 + 方法 + 路由模板 + 使用者」識別：
+//   - 第一次請求正常執行，回應（狀態碼、標頭、body）保存 TTL
+//   - 重複的鍵直接重播第一次的回應，並附加 Idempotent-Replayed: true
+//   - 第一次仍在處理中時回應 409 + Retry-After
+//   - 相同的鍵搭配不同的請求 body 回應 422
+//
+// 5xx、panic 與過大的回應不保存，鍵會被釋放讓客戶端重試。
+// 儲存層錯誤時回應 503，不冒重複執行的風險。須放在設定使用者的認證中間件之後。
+// store 為 nil 時使用 NewMemoryIdempotencyStore()，多實例部署請使用 NewRedisIdempotencyStore。
+//
+// EX：
+//
+//	store := middleware.NewRedisIdempotencyStore(rdb, "")
+//	orders := r.NewGroup("/orders", authMiddleware, middleware.Idempotency(store, middleware.IdempotencyConfig{}))
+//	orders.POST("", createOrder)
+func Idempotency(store IdempotencyStore, config IdempotencyConfig) hypcontext.HandlerFunc {
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.LockTTL <= 0 {
+		config.LockTTL = time.Minute
+	}
+	if config.HeaderName == "" {
+		config.HeaderName = "Idempotency-Key"
+	}
+	if config.Methods == nil {
+		config.Methods = []string{http.MethodPost, http.MethodPatch}
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 1 << 20
+	}
+	if config.UserFunc == nil {
+		config.UserFunc = func(c *hypcontext.Context) string {
+			if id := c.GetUserID(); id != nil {
+				return fmt.Sprint(id)
+			}
+			return ""
+		}
+	}
+	methods := make(map[string]bool, len(config.Methods))
+	for _, m := range config.Methods {
+		methods[m] = true
+	}
+
+	return func(c *hypcontext.Context) {
+		if !methods[c.Request.Method] {
+			c.Next()
+			return
+		}
+		key := c.GetHeader(config.HeaderName)
+		if key == "" {
+			if config.Required {
+				c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{"error": config.HeaderName + " header is required"})
+				return
+			}
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLen {
+			c.AbortWithStatusJSON(http.StatusBadRequest, map[string]string{"error": config.HeaderName + " is too long"})
+			return
+		}
+
+		body, err := c.GetRawData()
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+		sum := sha256.Sum256(body)
+		fingerprint := hex.EncodeToString(sum[:])
+		storeKey := idempotencyStoreKey(config.UserFunc(c), c.Request.Method, c.FullPath(), key)
+
+		ctx := c.Request.Context()
+		saved, reserved, err := store.Reserve(ctx, storeKey, config.LockTTL)
+		switch {
+		case err != nil:
+			c.Error(fmt.Errorf("idempotency store: %w", err))
+			c.AbortWithStatus(http.StatusServiceUnavailable)
+			return
+		case saved != nil:
+			if saved.Fingerprint != fingerprint {
+				c.AbortWithStatusJSON(http.StatusUnprocessableEntity, map[string]string{
+					"error": config.HeaderName + " was already used with a different request body",
+				})
+				return
+			}
+			replayIdempotentResponse(c, saved)
+			return
+		case !reserved:
+			c.Header("Retry-After", "1")
+			c.AbortWithStatusJSON(http.StatusConflict, map[string]string{
+				"error": "a request with this " + config.HeaderName + " is still being processed",
+			})
+			return
+		}
+
+		respBody := &cappedBuffer{max: config.MaxBodyBytes}
+		original := c.Response
+		recorder := &bodyRecorder{ResponseWriter: original, body: respBody}
+		c.Response, c.Writer = recorder, recorder
+
+		// 請求被取消時仍須更新儲存層，否則鍵會鎖住直到 LockTTL
+		storeCtx := context.WithoutCancel(ctx)
+		completed := false
+		defer func() {
+			// Context 釋放時需取回原始 ResponseWriter 放回物件池；panic 時釋放鍵讓客戶端重試
+			c.Response, c.Writer = original, original
+			if !completed {
+				store.Release(storeCtx, storeKey)
+			}
+		}()
+
+		c.Next()
+
+		status := original.Status()
+		if status >= http.StatusInternalServerError || respBody.total > config.MaxBodyBytes {
+			return
+		}
+		resp := &IdempotentResponse{
+			Status:      status,
+			Header:      replayableHeader(original.Header()),
+			Body:        respBody.buf,
+			Fingerprint: fingerprint,
+		}
+		if err := store.Complete(storeCtx, storeKey, resp, config.TTL); err != nil {
+			c.Error(fmt.Errorf("idempotency store: %w", err))
+			return
+		}
+		completed = true
+	}
+}
+
+// idempotencyStoreKey 以雜湊組合識別欄位，長度固定且不外洩使用者資訊
+func idempotencyStoreKey(user, method, route, key string) string {
+	sum := sha256.Sum256([]byte(user + "\x00" + method + "\x00" + route + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// replayableHeader 複製可重播的回應標頭，排除 cookie 與每次回應各自產生的標頭
+func replayableHeader(h http.Header) http.Header {
+	cp := h.Clone()
+	for _, name := range []string{"Set-Cookie", "Date", "Content-Length", hypcontext.HeaderXRequestID} {
+		cp.Del(name)
+	}
+	return cp
+}
+
+func replayIdempotentResponse(c *hypcontext.Context, resp *IdempotentResponse) {
+	header := c.Writer.Header()
+	for name, values := range resp.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	header.Set(HeaderIdempotentReplayed, "true")
+	c.Status(resp.Status)
+	c.Writer.WriteHeaderNow()
+	if len(resp.Body) > 0 {
+		c.Writer.Write(resp.Body)
+	}
+	c.Abort()
+}
+
+// ===== 記憶體儲存 =====
+
+// memoryIdempotencySweepInterval Reserve 清除過期項目的最短間隔
+const memoryIdempotencySweepInterval = time.Minute
+
+// MemoryIdempotencyStore 以記憶體保存冪等回應，適用於單機與測試環境
+// 過期項目於 Reserve 時每分鐘最多清除一次，不另外啟動背景 goroutine
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	entries   map[string]memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	resp      *IdempotentResponse // nil 表示處理中
+	expiresAt time.Time
+}
+
+// NewMemoryIdempotencyStore 創建記憶體冪等儲存
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{entries: make(map[string]memoryIdempotencyEntry)}
+}
+
+// Reserve 佔用 key，或回傳已保存的回應
+func (m *MemoryIdempotencyStore) Reserve(_ context.Context, key string, lockTTL time.Duration) (*IdempotentResponse, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= memoryIdempotencySweepInterval {
+		m.sweepLocked(now)
+	}
+	if entry, ok := m.entries[key]; ok && now.Before(entry.expiresAt) {
+		return entry.resp, false, nil
+	}
+	m.entries[key] = memoryIdempotencyEntry{expiresAt: now.Add(lockTTL)}
+	return nil, true, nil
+}
+
+// sweepLocked 移除所有已過期的項目
+func (m *MemoryIdempotencyStore) sweepLocked(now time.Time) {
+	for key, entry := range m.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(m.entries, key)
+		}
+	}
+	m.lastSweep = now
+}
+
+// Complete 保存回應
+func (m *MemoryIdempotencyStore) Complete(_ context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[key] = memoryIdempotencyEntry{resp: resp, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release 移除 key
+func (m *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, key)
+	return nil
+}
+
+// ===== Redis 儲存 =====
+
+// RedisIdempotencyStore 以 Redis 保存冪等回應，多實例部署時共用
+// 處理中以空字串標記（SET NX），完成後以 JSON 保存回應
+type RedisIdempotencyStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisIdempotencyStore 創建 Redis 冪等儲存，prefix 為空時使用 "idempotency:"
+func NewRedisIdempotencyStore(client redis.UniversalClient, prefix string) *RedisIdempotencyStore {
+	if prefix == "" {
+		prefix = "idempotency:"
+	}
+	return &RedisIdempotencyStore{client: client, prefix: prefix}
+}
+
+// Reserve 以 SET NX 佔用 key，失敗時讀取已保存的回應
+func (r *RedisIdempotencyStore) Reserve(ctx context.Context, key string, lockTTL time.Duration) (*IdempotentResponse, bool, error) {
+	ok, err := r.client.SetNX(ctx, r.prefix+key, "", lockTTL).Result()
+	if err != nil {
+		return nil, false, err
+	}
+	if ok {
+		return nil, true, nil
+	}
+
+	data, err := r.client.Get(ctx, r.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) || (err == nil && len(data) == 0) {
+		// 處理中（或標記恰好在兩次呼叫間過期），請客戶端稍後重試
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	var resp IdempotentResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, false, fmt.Errorf("decode idempotent response: %w", err)
+	}
+	return &resp, false, nil
+}
+
+// Complete 保存回應並設定 TTL
+func (r *RedisIdempotencyStore) Complete(ctx context.Context, key string, resp *IdempotentResponse, ttl time.Duration) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("encode idempotent response: %w", err)
+	}
+	return r.client.Set(ctx, r.prefix+key, data, ttl).Err()
+}
+
+// Release 刪除 key
+func (r *RedisIdempotencyStore) Release(ctx context.Context, key string) error {
+	return r.client.Del(ctx, r.prefix+key).Err()
+}
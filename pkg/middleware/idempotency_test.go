@@ -0,0 +1,174 @@
+package middleware
+
+import (
+	stdcontext "context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func idempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest("POST", "/orders", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("Idempotency-Key", key)
+	}
+	return req
+}
+
+func TestIdempotencyReplaysFirstResponse(t *testing.T) {
+	calls := 0
+	r := router.New()
+	r.POST("/orders", Idempotency(nil, IdempotencyConfig{}), func(c *context.Context) {
+		calls++
+		c.Header("Location", "/orders/42")
+		c.JSON(http.StatusCreated, map[string]int{"id": 42, "call": calls})
+	})
+
+	first := httptest.NewRecorder()
+	r.ServeHTTP(first, idempotentRequest("k-1", `{"sku":"A"}`))
+	second := httptest.NewRecorder()
+	r.ServeHTTP(second, idempotentRequest("k-1", `{"sku":"A"}`))
+
+	if calls != 1 {
+		t.Fatalf("Expected handler to run once, ran %d times", calls)
+	}
+	if second.Code != first.Code || second.Body.String() != first.Body.String() {
+		t.Errorf("Replay differs from first response:\n first %d %q\nsecond %d %q", first.Code, first.Body.String(), second.Code, second.Body.String())
+	}
+	if second.Header().Get("Location") != "/orders/42" || second.Header().Get("Content-Type") != first.Header().Get("Content-Type") {
+		t.Errorf("Expected replayed headers, got %v", second.Header())
+	}
+	if second.Header().Get(HeaderIdempotentReplayed) != "true" || first.Header().Get(HeaderIdempotentReplayed) != "" {
+		t.Errorf("Expected only the replay to be marked")
+	}
+
+	// 不同的鍵與未帶鍵的請求照常執行
+	r.ServeHTTP(httptest.NewRecorder(), idempotentRequest("k-2", `{"sku":"A"}`))
+	r.ServeHTTP(httptest.NewRecorder(), idempotentRequest("", `{"sku":"A"}`))
+	if calls != 3 {
+		t.Errorf("Expected new key and keyless requests to run, got %d calls", calls)
+	}
+}
+
+func TestIdempotencyConflictAndMismatch(t *testing.T) {
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	r := router.New()
+	r.POST("/orders", Idempotency(nil, IdempotencyConfig{}), func(c *context.Context) {
+		if c.GetHeader("X-Block") != "" {
+			close(entered)
+			<-release
+		}
+		c.String(http.StatusCreated, "ok")
+	})
+
+	// 第一次仍在處理中時，重複請求回應 409
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		req := idempotentRequest("k-1", "{}")
+		req.Header.Set("X-Block", "1")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}()
+	<-entered
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("k-1", "{}"))
+	if w.Code != http.StatusConflict || w.Header().Get("Retry-After") == "" {
+		t.Errorf("Expected 409 for in-flight duplicate, got %d", w.Code)
+	}
+	close(release)
+	<-done
+
+	// 相同的鍵搭配不同 body
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("k-1", `{"other":true}`))
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for reused key with different body, got %d", w.Code)
+	}
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	calls := 0
+	r := router.New()
+	r.POST("/orders", Idempotency(nil, IdempotencyConfig{}), func(c *context.Context) {
+		calls++
+		if calls == 1 {
+			c.String(http.StatusBadGateway, "upstream down")
+			return
+		}
+		c.String(http.StatusCreated, "ok")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), idempotentRequest("k-1", "{}"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("k-1", "{}"))
+	if calls != 2 || w.Code != http.StatusCreated {
+		t.Errorf("Expected retry after 5xx to run again, got %d calls, status %d", calls, w.Code)
+	}
+}
+
+func TestIdempotencyScopedByUser(t *testing.T) {
+	calls := 0
+	r := router.New()
+	r.POST("/orders",
+		func(c *context.Context) { c.SetUserID(c.GetHeader("X-User")) },
+		Idempotency(nil, IdempotencyConfig{Required: true}),
+		func(c *context.Context) {
+			calls++
+			c.String(http.StatusCreated, c.GetHeader("X-User"))
+		},
+	)
+
+	for _, user := range []string{"alice", "bob"} {
+		req := idempotentRequest("same-key", "{}")
+		req.Header.Set("X-User", user)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != user {
+			t.Errorf("Expected %s's own response, got %q", user, w.Body.String())
+		}
+	}
+	if calls != 2 {
+		t.Errorf("Expected the same key from different users to run separately, got %d calls", calls)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, idempotentRequest("", "{}"))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 when the key is required, got %d", w.Code)
+	}
+}
+
+func TestMemoryIdempotencyStoreSweepsExpired(t *testing.T) {
+	store := NewMemoryIdempotencyStore()
+	ctx := stdcontext.Background()
+
+	store.Reserve(ctx, "pending", time.Millisecond)
+	store.Complete(ctx, "done", &IdempotentResponse{Status: http.StatusCreated}, time.Millisecond)
+	store.Complete(ctx, "fresh", &IdempotentResponse{Status: http.StatusCreated}, time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	// 未到清除間隔時不掃描
+	store.Reserve(ctx, "next", time.Hour)
+	if n := len(store.entries); n != 4 {
+		t.Fatalf("Expected no sweep within the interval, got %d entries", n)
+	}
+
+	store.lastSweep = time.Now().Add(-memoryIdempotencySweepInterval)
+	store.Reserve(ctx, "another", time.Hour)
+	if _, ok := store.entries["pending"]; ok {
+		t.Error("Expected expired reservation to be swept")
+	}
+	if _, ok := store.entries["done"]; ok {
+		t.Error("Expected expired response to be swept")
+	}
+	if n := len(store.entries); n != 3 {
+		t.Errorf("Expected fresh, next and another to remain, got %d entries", n)
+	}
+}
@@ -0,0 +1,233 @@
+// @chris
+package middleware
+
+import (
+	stdcontext "context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// ===== 負載卸除中間件 =====
+
+// Priority 請求優先級，過載時由低至高依序拒絕
+type Priority int
+
+const (
+	// PriorityLow 低優先級（報表、匯出、背景同步），壓力達 LowPriorityRatio 即開始拒絕
+	PriorityLow Priority = iota
+	// PriorityNormal 一般請求（預設），達到上限才拒絕
+	PriorityNormal
+	// PriorityCritical 關鍵請求（登入、結帳），永不拒絕
+	PriorityCritical
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityCritical:
+		return "critical"
+	default:
+		return "normal"
+	}
+}
+
+// defaultShedAlwaysAllow 預設永遠放行的健康檢查與指標路徑
+var defaultShedAlwaysAllow = []string{"/livez", "/readyz", "/health", "/metrics"}
+
+// LoadSheddingConfig 負載卸除配置
+type LoadSheddingConfig struct {
+	// MaxInFlight 同時處理中的請求上限，0 表示不以並行數判斷
+	MaxInFlight int
+	// MaxLatency 請求處理時間 EWMA 上限，0 表示不以延遲判斷
+	MaxLatency time.Duration
+	// LowPriorityRatio 低優先級請求在壓力達上限的此比例時即拒絕，預設 0.8
+	LowPriorityRatio float64
+	// EWMAAlpha 延遲 EWMA 的平滑係數（0~1，越大越偏重最近的請求），預設 0.1
+	EWMAAlpha float64
+	// LatencyHalfLife 沒有請求完成時延遲 EWMA 減半所需的時間，預設 1s；
+	// 卸除期間樣本變少，衰減讓壓力隨時間回落而不會停在卸除前的高點
+	LatencyHalfLife time.Duration
+	// RetryAfter 回應的 Retry-After，預設 1s
+	RetryAfter time.Duration
+	// AlwaysAllow 永遠放行的原始路徑，預設 /livez、/readyz、/health、/metrics
+	AlwaysAllow []string
+	// Priorities 依路由模板指定優先級，鍵為 "GET /users/:id" 或不分方法的 "/users/:id"
+	Priorities map[string]Priority
+	// PriorityFunc 自訂優先級判斷（例如依標頭或使用者等級），設定時優先於 Priorities
+	PriorityFunc func(c *hypcontext.Context) Priority
+	// MeterProvider 留空時使用全域 provider（otel.SetMeterProvider）
+	MeterProvider metric.MeterProvider
+}
+
+// LoadShedding 創建負載卸除中間件
+// 追蹤處理中的請求數與處理時間 EWMA，壓力 = max(處理中 / MaxInFlight, EWMA / MaxLatency)；
+// 壓力達 LowPriorityRatio 時拒絕低優先級請求、達 1 時拒絕一般請求，回應 503 + Retry-After，
+// 關鍵請求與 AlwaysAllow 路徑一律放行，讓伺服器在尖峰時仍能回應重要流量而非整體崩潰。
+// 延遲 EWMA 從 0 起算（單一慢請求不會直接推到上限），並依 LatencyHalfLife 隨時間衰減；
+// 延遲只在仍有請求處理中時計入，閒置後不會因過時的 EWMA 持續拒絕。
+// 以 http.server.load_shedding.requests（outcome=admitted|shed、priority）計數，卸除率 = shed / 全部；
+// http.server.load_shedding.pressure 為目前壓力。
+//
+// EX：
+//
+//	srv.Use(middleware.LoadShedding(middleware.LoadSheddingConfig{
+//	    MaxInFlight: 512,
+//	    MaxLatency:  300 * time.Millisecond,
+//	    Priorities: map[string]middleware.Priority{
+//	        "POST /checkout":     middleware.PriorityCritical,
+//	        "GET /reports/:name": middleware.PriorityLow,
+//	    },
+//	}))
+func LoadShedding(config LoadSheddingConfig) hypcontext.HandlerFunc {
+	if config.LowPriorityRatio <= 0 || config.LowPriorityRatio > 1 {
+		config.LowPriorityRatio = 0.8
+	}
+	if config.EWMAAlpha <= 0 || config.EWMAAlpha > 1 {
+		config.EWMAAlpha = 0.1
+	}
+	if config.LatencyHalfLife <= 0 {
+		config.LatencyHalfLife = time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.AlwaysAllow == nil {
+		config.AlwaysAllow = defaultShedAlwaysAllow
+	}
+	alwaysAllow := make(map[string]bool, len(config.AlwaysAllow))
+	for _, path := range config.AlwaysAllow {
+		alwaysAllow[path] = true
+	}
+	retryAfter := strconv.Itoa(int(math.Ceil(config.RetryAfter.Seconds())))
+
+	s := &loadShedder{config: config}
+	s.registerMetrics()
+
+	return func(c *hypcontext.Context) {
+		if alwaysAllow[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		priority := s.priority(c)
+		if priority != PriorityCritical {
+			pressure := s.pressure()
+			if pressure >= 1 || (priority == PriorityLow && pressure >= config.LowPriorityRatio) {
+				s.record(c, priority, "shed")
+				c.Header("Retry-After", retryAfter)
+				c.AbortWithStatus(http.StatusServiceUnavailable)
+				return
+			}
+		}
+		s.record(c, priority, "admitted")
+
+		s.inFlight.Add(1)
+		start := time.Now()
+		defer func() {
+			s.inFlight.Add(-1)
+			s.observe(time.Since(start))
+		}()
+		c.Next()
+	}
+}
+
+// loadShedder 負載卸除狀態
+type loadShedder struct {
+	config   LoadSheddingConfig
+	inFlight atomic.Int64
+
+	mu       sync.Mutex
+	ewma     float64   // 處理時間 EWMA（奈秒）
+	observed time.Time // 最後一次更新 EWMA 的時間
+
+	requests metric.Int64Counter
+}
+
+// priority 依 PriorityFunc、"METHOD 模板"、"模板" 的順序決定優先級
+func (s *loadShedder) priority(c *hypcontext.Context) Priority {
+	if s.config.PriorityFunc != nil {
+		return s.config.PriorityFunc(c)
+	}
+	if route := c.FullPath(); route != "" && len(s.config.Priorities) > 0 {
+		if p, ok := s.config.Priorities[c.Request.Method+" "+route]; ok {
+			return p
+		}
+		if p, ok := s.config.Priorities[route]; ok {
+			return p
+		}
+	}
+	return PriorityNormal
+}
+
+// pressure 目前壓力，1 表示已達上限
+func (s *loadShedder) pressure() float64 {
+	inFlight := s.inFlight.Load()
+	var pressure float64
+	if s.config.MaxInFlight > 0 {
+		pressure = float64(inFlight) / float64(s.config.MaxInFlight)
+	}
+	if s.config.MaxLatency > 0 && inFlight > 0 {
+		s.mu.Lock()
+		latency := s.decayedLocked(time.Now())
+		s.mu.Unlock()
+		pressure = math.Max(pressure, latency/float64(s.config.MaxLatency))
+	}
+	return pressure
+}
+
+// observe 以處理時間更新 EWMA，先套用自上次更新以來的衰減
+func (s *loadShedder) observe(d time.Duration) {
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	old := s.decayedLocked(now)
+	s.ewma = old + s.config.EWMAAlpha*(float64(d)-old)
+	s.observed = now
+}
+
+// decayedLocked 依距上次更新的時間衰減後的 EWMA
+func (s *loadShedder) decayedLocked(now time.Time) float64 {
+	if s.ewma == 0 {
+		return 0
+	}
+	elapsed := now.Sub(s.observed)
+	return s.ewma * math.Exp2(-float64(elapsed)/float64(s.config.LatencyHalfLife))
+}
+
+func (s *loadShedder) registerMetrics() {
+	provider := s.config.MeterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(tracing.InstrumentationName)
+
+	// 建立失敗時 otel 回傳可用的 no-op 儀表，不影響請求處理
+	s.requests, _ = meter.Int64Counter("http.server.load_shedding.requests",
+		metric.WithDescription("Load shedding decisions by outcome and priority."),
+	)
+	meter.Float64ObservableGauge("http.server.load_shedding.pressure",
+		metric.WithDescription("Current load pressure; non-critical requests are shed at 1."),
+		metric.WithFloat64Callback(func(_ stdcontext.Context, o metric.Float64Observer) error {
+			o.Observe(s.pressure())
+			return nil
+		}),
+	)
+}
+
+func (s *loadShedder) record(c *hypcontext.Context, priority Priority, outcome string) {
+	s.requests.Add(c.Request.Context(), 1, metric.WithAttributes(
+		attribute.String("outcome", outcome),
+		attribute.String("priority", priority.String()),
+	))
+}
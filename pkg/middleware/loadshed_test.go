@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	stdcontext "context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// blockingRoutes 註冊會阻塞到 release 關閉的 /slow，以及立即回應的路由
+func blockingRoutes(r *router.Router, release chan struct{}, entered *sync.WaitGroup) {
+	r.GET("/slow", func(c *context.Context) {
+		entered.Done()
+		<-release
+		c.Status(http.StatusOK)
+	})
+	ok := func(c *context.Context) { c.Status(http.StatusOK) }
+	r.GET("/users/:id", ok)
+	r.GET("/reports/:name", ok)
+	r.POST("/checkout", ok)
+	r.GET("/livez", ok)
+}
+
+func serve(r http.Handler, method, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+	return w
+}
+
+func TestLoadSheddingInFlight(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	r := router.New()
+	r.Use(LoadShedding(LoadSheddingConfig{
+		MaxInFlight:      4,
+		LowPriorityRatio: 0.5,
+		RetryAfter:       1500 * time.Millisecond,
+		Priorities: map[string]Priority{
+			"POST /checkout": PriorityCritical,
+			"/reports/:name": PriorityLow,
+		},
+		MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)),
+	}))
+	release := make(chan struct{})
+	var entered sync.WaitGroup
+	blockingRoutes(r, release, &entered)
+
+	var done sync.WaitGroup
+	hold := func(n int) {
+		entered.Add(n)
+		done.Add(n)
+		for i := 0; i < n; i++ {
+			go func() {
+				defer done.Done()
+				serve(r, "GET", "/slow")
+			}()
+		}
+		entered.Wait()
+	}
+
+	// 壓力 0.5：低優先級開始被拒絕，一般請求仍放行
+	hold(2)
+	if w := serve(r, "GET", "/reports/daily"); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "2" {
+		t.Errorf("Expected low priority shed with Retry-After 2, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := serve(r, "GET", "/users/1"); w.Code != http.StatusOK {
+		t.Errorf("Expected normal request admitted at half load, got %d", w.Code)
+	}
+
+	// 達上限：一般請求被拒絕，關鍵請求與健康檢查仍放行
+	hold(2)
+	if w := serve(r, "GET", "/users/1"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected normal request shed at capacity, got %d", w.Code)
+	}
+	if w := serve(r, "POST", "/checkout"); w.Code != http.StatusOK {
+		t.Errorf("Expected critical request admitted, got %d", w.Code)
+	}
+	if w := serve(r, "GET", "/livez"); w.Code != http.StatusOK {
+		t.Errorf("Expected health path admitted, got %d", w.Code)
+	}
+
+	close(release)
+	done.Wait()
+	if w := serve(r, "GET", "/reports/daily"); w.Code != http.StatusOK {
+		t.Errorf("Expected recovery once load drops, got %d", w.Code)
+	}
+
+	// 卸除計數：低優先級 1 次、一般 1 次
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(stdcontext.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	shed := map[string]int64{}
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			sum, ok := m.Data.(metricdata.Sum[int64])
+			if m.Name != "http.server.load_shedding.requests" || !ok {
+				continue
+			}
+			for _, dp := range sum.DataPoints {
+				if dp.Attributes.HasValue("outcome") {
+					if v, _ := dp.Attributes.Value("outcome"); v.AsString() == "shed" {
+						p, _ := dp.Attributes.Value(attribute.Key("priority"))
+						shed[p.AsString()] += dp.Value
+					}
+				}
+			}
+		}
+	}
+	if shed["low"] != 1 || shed["normal"] != 1 || shed["critical"] != 0 {
+		t.Errorf("Unexpected shed counts %v", shed)
+	}
+}
+
+func TestLoadSheddingLatency(t *testing.T) {
+	r := router.New()
+	r.Use(LoadShedding(LoadSheddingConfig{
+		MaxLatency: 5 * time.Millisecond,
+		EWMAAlpha:  0.5,
+	}))
+	release := make(chan struct{})
+	var entered sync.WaitGroup
+	blockingRoutes(r, release, &entered)
+
+	// 一個 40ms 的慢請求完成，EWMA 由 0 拉到約 20ms；之後的快速請求只把它拉低一半
+	entered.Add(1)
+	go func() {
+		time.Sleep(40 * time.Millisecond)
+		release <- struct{}{}
+	}()
+	serve(r, "GET", "/slow")
+
+	// 閒置時不以過時的 EWMA 拒絕
+	if w := serve(r, "GET", "/users/1"); w.Code != http.StatusOK {
+		t.Fatalf("Expected admission while idle, got %d", w.Code)
+	}
+
+	// 仍有請求處理中且延遲超標時拒絕
+	entered.Add(1)
+	var done sync.WaitGroup
+	done.Add(1)
+	go func() {
+		defer done.Done()
+		serve(r, "GET", "/slow")
+	}()
+	entered.Wait()
+	if w := serve(r, "GET", "/users/1"); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected shedding on high latency, got %d", w.Code)
+	}
+	close(release)
+	done.Wait()
+}
+
+func TestLoadSheddingLatencyRecovers(t *testing.T) {
+	s := &loadShedder{config: LoadSheddingConfig{
+		MaxLatency:      10 * time.Millisecond,
+		EWMAAlpha:       0.5,
+		LatencyHalfLife: 20 * time.Millisecond,
+	}}
+	s.inFlight.Store(1) // 卸除期間仍有關鍵請求處理中
+
+	// 單一樣本不會直接推到上限
+	s.observe(15 * time.Millisecond)
+	if p := s.pressure(); p >= 1 {
+		t.Errorf("Expected a single sample not to saturate, got pressure %.2f", p)
+	}
+
+	s.observe(100 * time.Millisecond)
+	s.observe(100 * time.Millisecond)
+	if p := s.pressure(); p < 1 {
+		t.Fatalf("Expected overload, got pressure %.2f", p)
+	}
+
+	// 沒有新的樣本（一般請求都被卸除）時壓力隨時間回落
+	deadline := time.Now().Add(2 * time.Second)
+	for s.pressure() >= 1 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Pressure did not recover, still %.2f", s.pressure())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,133 @@
+// @chris
+package middleware
+
+import (
+	"fmt"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"golang.org/x/text/language"
+)
+
+// ===== 語系解析中間件 =====
+
+// LocalizationConfig 語系解析配置
+type LocalizationConfig struct {
+	// Supported 支援的語系（BCP 47，如 "en"、"zh-TW"），第一個為預設語系；必填
+	Supported []string
+	// Default 無法匹配時使用的語系，預設為 Supported[0]
+	Default string
+	// Translator 供 c.T 使用的翻譯表（如 *i18n.Bundle），nil 時 c.T 直接回傳 key
+	Translator hypcontext.Translator
+	// QueryParam 覆寫語系的查詢參數，預設 "lang"；設為 "-" 停用
+	QueryParam string
+	// CookieName 記住語系的 cookie，預設 "lang"；設為 "-" 停用
+	// 以查詢參數切換語系時會寫入此 cookie，後續請求不必再帶參數
+	CookieName   string
+	CookieMaxAge int // 秒，預設一年
+	CookiePath   string
+	CookieSecure bool
+	Skipper      func(c *hypcontext.Context) bool
+}
+
+// Localization 創建語系解析中間件，依序採用 ?lang=、cookie、Accept-Language，
+// 以 Supported 做最佳匹配（en-GB 匹配 en、zh-Hant-TW 匹配 zh-TW），都無法匹配時使用 Default
+// 結果以 c.Locale() 取得，並寫入 Content-Language 與 Vary: Accept-Language 回應標頭；
+// Supported 為空或含無效語系時 panic
+//
+// EX：
+//
+//	bundle := i18n.NewBundle("en")
+//	if err := bundle.LoadDir("locales"); err != nil {
+//	    log.Fatal(err)
+//	}
+//	r.Use(middleware.Localization(middleware.LocalizationConfig{
+//	    Supported:  []string{"en", "zh-TW", "ja"},
+//	    Translator: bundle,
+//	}))
+//	r.GET("/hello", func(c *context.Context) {
+//	    c.String(http.StatusOK, c.T("greeting", c.Query("name")))
+//	})
+func Localization(config LocalizationConfig) hypcontext.HandlerFunc {
+	if len(config.Supported) == 0 {
+		panic("middleware.Localization: Supported must list at least one locale")
+	}
+	tags := make([]language.Tag, len(config.Supported))
+	for i, s := range config.Supported {
+		tag, err := language.Parse(s)
+		if err != nil {
+			panic(fmt.Sprintf("middleware.Localization: invalid locale %q: %v", s, err))
+		}
+		tags[i] = tag
+	}
+	if config.Default == "" {
+		config.Default = config.Supported[0]
+	}
+	if config.QueryParam == "" {
+		config.QueryParam = "lang"
+	}
+	if config.CookieName == "" {
+		config.CookieName = "lang"
+	}
+	if config.CookieMaxAge == 0 {
+		config.CookieMaxAge = 365 * 24 * 60 * 60
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	matcher := language.NewMatcher(tags)
+
+	// match 回傳與 Supported 中原始寫法一致的語系
+	match := func(desired ...language.Tag) (string, bool) {
+		if len(desired) == 0 {
+			return "", false
+		}
+		_, i, conf := matcher.Match(desired...)
+		if conf == language.No {
+			return "", false
+		}
+		return config.Supported[i], true
+	}
+
+	return func(c *hypcontext.Context) {
+		if config.Skipper != nil && config.Skipper(c) {
+			c.Next()
+			return
+		}
+
+		locale, ok := "", false
+		if config.QueryParam != "-" {
+			if q := c.Query(config.QueryParam); q != "" {
+				if tag, err := language.Parse(q); err == nil {
+					if locale, ok = match(tag); ok && config.CookieName != "-" {
+						c.SetCookie(config.CookieName, locale, config.CookieMaxAge, config.CookiePath, "", config.CookieSecure, false)
+					}
+				}
+			}
+		}
+		if !ok && config.CookieName != "-" {
+			if v, err := c.Cookie(config.CookieName); err == nil && v != "" {
+				if tag, err := language.Parse(v); err == nil {
+					locale, ok = match(tag)
+				}
+			}
+		}
+		if !ok {
+			if header := c.GetHeader("Accept-Language"); header != "" {
+				if desired, _, err := language.ParseAcceptLanguage(header); err == nil {
+					locale, ok = match(desired...)
+				}
+			}
+		}
+		if !ok {
+			locale = config.Default
+		}
+
+		c.SetLocale(locale)
+		if config.Translator != nil {
+			c.SetTranslator(config.Translator)
+		}
+		c.Header("Content-Language", locale)
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/i18n"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func TestLocalization(t *testing.T) {
+	bundle := i18n.NewBundle("en")
+	bundle.Add("en", map[string]interface{}{"greeting": "Hello"})
+	bundle.Add("zh-TW", map[string]interface{}{"greeting": "你好"})
+
+	r := router.New()
+	r.Use(Localization(LocalizationConfig{
+		Supported:  []string{"en", "zh-TW", "ja"},
+		Translator: bundle,
+	}))
+	r.GET("/", func(c *context.Context) {
+		c.String(http.StatusOK, c.Locale()+":"+c.T("greeting"))
+	})
+
+	tests := []struct {
+		name, url, acceptLanguage, cookie string
+		want                              string
+	}{
+		{"default", "/", "", "", "en:Hello"},
+		{"accept-language", "/", "fr-FR, zh-Hant-TW;q=0.8, en;q=0.5", "", "zh-TW:你好"},
+		{"regional variant", "/", "en-GB", "", "en:Hello"},
+		{"unsupported", "/", "fr", "", "en:Hello"},
+		{"cookie", "/", "en", "ja", "ja:Hello"},
+		{"query overrides cookie", "/?lang=zh-tw", "en", "ja", "zh-TW:你好"},
+		{"invalid query ignored", "/?lang=!!", "ja", "", "ja:Hello"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.url, nil)
+		if tt.acceptLanguage != "" {
+			req.Header.Set("Accept-Language", tt.acceptLanguage)
+		}
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "lang", Value: tt.cookie})
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.name, w.Body.String(), tt.want)
+		}
+	}
+
+	// 以查詢參數切換時寫入 cookie，並帶上 Content-Language / Vary
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/?lang=ja", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != "lang" || cookies[0].Value != "ja" {
+		t.Errorf("expected lang cookie, got %v", cookies)
+	}
+	if w.Header().Get("Content-Language") != "ja" || w.Header().Get("Vary") != "Accept-Language" {
+		t.Errorf("unexpected headers %v", w.Header())
+	}
+}
+
+func TestLocalizationWithoutTranslator(t *testing.T) {
+	r := router.New()
+	r.Use(Localization(LocalizationConfig{Supported: []string{"en"}, QueryParam: "-", CookieName: "-"}))
+	r.GET("/", func(c *context.Context) {
+		c.String(http.StatusOK, c.T("Hello %s", "Bob"))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/?lang=en", nil))
+	if w.Body.String() != "Hello Bob" || len(w.Result().Cookies()) != 0 {
+		t.Errorf("got %q, cookies %v", w.Body.String(), w.Result().Cookies())
+	}
+}
+
+func TestLocalizationInvalidConfig(t *testing.T) {
+	for _, supported := range [][]string{nil, {"en", "not a locale"}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %v", supported)
+				}
+			}()
+			Localization(LocalizationConfig{Supported: supported})
+		}()
+	}
+}
@@ -0,0 +1,128 @@
+// @chris
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== 日誌 body 擷取 =====
+
+// DefaultRedactFields 預設遮蔽的欄位名稱（不分大小寫）
+var DefaultRedactFields = []string{
+	"password", "passwd", "secret", "token",
+	"access_token", "refresh_token", "api_key", "authorization",
+}
+
+const redactedValue = "[REDACTED]"
+
+// cappedBuffer 只保留前 max 位元組，但 Write 永遠回報完整長度，
+// 避免 TeeReader 因截斷而回傳錯誤
+type cappedBuffer struct {
+	buf   []byte
+	max   int
+	total int
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	b.total += len(p)
+	if room := b.max - len(b.buf); room > 0 {
+		if len(p) > room {
+			p = p[:room]
+		}
+		b.buf = append(b.buf, p...)
+	}
+	return len(p), nil
+}
+
+// teeReadCloser 讀取時同步寫入 cappedBuffer，Close 關閉原始 body
+type teeReadCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bodyRecorder 包裝 ResponseWriter，同步記錄寫出的回應
+type bodyRecorder struct {
+	hypcontext.ResponseWriter
+	body *cappedBuffer
+}
+
+func (r *bodyRecorder) Write(data []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(data)
+	r.body.Write(data[:n])
+	return n, err
+}
+
+func (r *bodyRecorder) WriteString(s string) (int, error) {
+	n, err := r.ResponseWriter.WriteString(s)
+	r.body.Write([]byte(s[:n]))
+	return n, err
+}
+
+// captureBodies 依配置掛上請求 tee 與回應 recorder
+// 未啟用的一方回傳 nil；restore 需在 c.Next() 之後呼叫
+func captureBodies(c *hypcontext.Context, config LoggerConfig) (reqBody, respBody *cappedBuffer, restore func()) {
+	restore = func() {}
+	if config.LogRequestBody && c.Request.Body != nil && c.Request.Body != http.NoBody {
+		reqBody = &cappedBuffer{max: config.MaxBodyBytes}
+		c.Request.Body = &teeReadCloser{
+			Reader: io.TeeReader(c.Request.Body, reqBody),
+			Closer: c.Request.Body,
+		}
+	}
+	if config.LogResponseBody {
+		respBody = &cappedBuffer{max: config.MaxBodyBytes}
+		original := c.Response
+		recorder := &bodyRecorder{ResponseWriter: original, body: respBody}
+		c.Response, c.Writer = recorder, recorder
+		// Context 釋放時需取回原始 ResponseWriter 放回物件池
+		restore = func() { c.Response, c.Writer = original, original }
+	}
+	return reqBody, respBody, restore
+}
+
+// bodyRedactor 以欄位名稱遮蔽 JSON 與 form 內容
+// 以正則處理而非完整解析，因此對截斷的 body 同樣有效
+type bodyRedactor struct {
+	json *regexp.Regexp
+	form *regexp.Regexp
+}
+
+func newBodyRedactor(fields []string) *bodyRedactor {
+	if len(fields) == 0 {
+		return &bodyRedactor{}
+	}
+	quoted := make([]string, len(fields))
+	for i, f := range fields {
+		quoted[i] = regexp.QuoteMeta(f)
+	}
+	names := strings.Join(quoted, "|")
+	return &bodyRedactor{
+		json: regexp.MustCompile(`(?i)("(?:` + names + `)"\s*:\s*)("(?:[^"\\]|\\.)*"?|[^,}\]\s]+)`),
+		form: regexp.MustCompile(`(?i)((?:^|&)(?:` + names + `)=)[^&]*`),
+	}
+}
+
+// format 遮蔽敏感欄位並以單行引號字串輸出，截斷時附上原始長度
+func (r *bodyRedactor) format(b *cappedBuffer, contentType string) string {
+	body := string(b.buf)
+	if r.json != nil {
+		switch {
+		case strings.Contains(contentType, "json"):
+			body = r.json.ReplaceAllString(body, `${1}"`+redactedValue+`"`)
+		case strings.Contains(contentType, "x-www-form-urlencoded"):
+			body = r.form.ReplaceAllString(body, "${1}"+redactedValue)
+		}
+	}
+
+	out := strconv.Quote(body)
+	if b.total > len(b.buf) {
+		out += " (truncated, " + strconv.Itoa(b.total) + " bytes)"
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+// @chris
+package middleware
+
+import (
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	otelprom "go.opentelemetry.io/otel/exporters/prometheus"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// ===== 指標中間件 =====
+
+// byteBuckets 傳輸量直方圖邊界：1KB 至 1GB，每級 ×4，涵蓋 API 回應到大型媒體
+var byteBuckets = []float64{
+	0, 1 << 10, 4 << 10, 16 << 10, 64 << 10, 256 << 10,
+	1 << 20, 4 << 20, 16 << 20, 64 << 20, 256 << 20, 1 << 30,
+}
+
+// durationBuckets 請求時間直方圖邊界（秒），延伸至 5 分鐘以涵蓋長時間上傳 / 下載
+var durationBuckets = []float64{
+	0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 300,
+}
+
+// MetricsConfig 指標配置
+type MetricsConfig struct {
+	// MeterProvider 留空時使用全域 provider（otel.SetMeterProvider）
+	MeterProvider metric.MeterProvider
+	SkipPaths     []string
+}
+
+// Metrics 創建指標中間件
+// 以 OpenTelemetry 直方圖記錄每個請求的 body 傳輸量與處理時間，依路由模板、方法、狀態碼與協議分組。
+// 位元組數來自 Context 自動累計的 BytesIn / BytesOut，HTTP/1.1、HTTP/2、HTTP/3 與串流 / SSE 回應皆適用；
+// 串流回應於結束時才記錄一次。路由平均吞吐量可由 response.body.size 與 request.duration 的總和相除取得。
+//
+// EX：
+//
+//	srv.Use(middleware.Metrics(middleware.MetricsConfig{SkipPaths: []string{"/livez", "/readyz"}}))
+func Metrics(config MetricsConfig) hypcontext.HandlerFunc {
+	skipPaths := make(map[string]bool)
+	for _, path := range config.SkipPaths {
+		skipPaths[path] = true
+	}
+
+	provider := config.MeterProvider
+	if provider == nil {
+		provider = otel.GetMeterProvider()
+	}
+	meter := provider.Meter(tracing.InstrumentationName)
+
+	// 建立失敗時 otel 回傳可用的 no-op 儀表，不影響請求處理
+	requestSize, _ := meter.Int64Histogram("http.server.request.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server request bodies."),
+		metric.WithExplicitBucketBoundaries(byteBuckets...),
+	)
+	responseSize, _ := meter.Int64Histogram("http.server.response.body.size",
+		metric.WithUnit("By"),
+		metric.WithDescription("Size of HTTP server response bodies."),
+		metric.WithExplicitBucketBoundaries(byteBuckets...),
+	)
+	duration, _ := meter.Float64Histogram("http.server.request.duration",
+		metric.WithUnit("s"),
+		metric.WithDescription("Duration of HTTP server requests."),
+		metric.WithExplicitBucketBoundaries(durationBuckets...),
+	)
+
+	return func(c *hypcontext.Context) {
+		if skipPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		c.Next()
+
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.HTTPResponseStatusCode(c.Response.Status()),
+			semconv.NetworkProtocolVersion(protocolVersion(c.Request)),
+		}
+		// 未匹配路由時不帶 http.route，避免以原始路徑造成高基數
+		if route := c.FullPath(); route != "" {
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+		set := metric.WithAttributeSet(attribute.NewSet(attrs...))
+
+		m := c.GetMetrics()
+		ctx := c.Request.Context()
+		requestSize.Record(ctx, m.BytesIn, set)
+		responseSize.Record(ctx, m.BytesOut, set)
+		duration.Record(ctx, time.Since(start).Seconds(), set)
+	}
+}
+
+// ===== Prometheus 匯出 =====
+
+// NewPrometheusMeterProvider 建立匯出到 Prometheus registry 的 MeterProvider，供 MetricsConfig.MeterProvider 使用。
+// 同一 registry 可再註冊其他收集器（如 websocket.Hub.Collector()），由 PrometheusHandler 一併輸出。
+// reg 為 nil 時使用 prometheus.DefaultRegisterer。
+func NewPrometheusMeterProvider(reg prometheus.Registerer) (*sdkmetric.MeterProvider, error) {
+	if reg == nil {
+		reg = prometheus.DefaultRegisterer
+	}
+	exporter, err := otelprom.New(otelprom.WithRegisterer(reg))
+	if err != nil {
+		return nil, err
+	}
+	return sdkmetric.NewMeterProvider(sdkmetric.WithReader(exporter)), nil
+}
+
+// PrometheusHandler 以 Prometheus 文字格式輸出 registry 內容，g 為 nil 時使用 prometheus.DefaultGatherer
+//
+// EX：
+//
+//	srv.Router().GET("/metrics", middleware.PrometheusHandler(reg))
+func PrometheusHandler(g prometheus.Gatherer) hypcontext.HandlerFunc {
+	if g == nil {
+		g = prometheus.DefaultGatherer
+	}
+	handler := promhttp.HandlerFor(g, promhttp.HandlerOpts{})
+	return func(c *hypcontext.Context) {
+		handler.ServeHTTP(c.Writer, c.Request)
+	}
+}
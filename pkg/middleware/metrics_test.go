@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	stdcontext "context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+// collectHistograms 依指標名稱回傳各 data point（只取第一個屬性組合）
+func collectHistograms(t *testing.T, reader *sdkmetric.ManualReader) map[string]metricdata.HistogramDataPoint[int64] {
+	t.Helper()
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(stdcontext.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	points := make(map[string]metricdata.HistogramDataPoint[int64])
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			if h, ok := m.Data.(metricdata.Histogram[int64]); ok && len(h.DataPoints) > 0 {
+				points[m.Name] = h.DataPoints[0]
+			}
+		}
+	}
+	return points
+}
+
+func TestMetricsByteHistograms(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	r := router.New()
+	r.Use(Metrics(MetricsConfig{MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))}))
+	r.POST("/media/:id", func(c *context.Context) {
+		io.Copy(io.Discard, c.Request.Body)
+		c.String(http.StatusCreated, strings.Repeat("x", 300))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/media/7", strings.NewReader(strings.Repeat("a", 2048))))
+
+	points := collectHistograms(t, reader)
+	in, out := points["http.server.request.body.size"], points["http.server.response.body.size"]
+	if in.Sum != 2048 || out.Sum != 300 {
+		t.Errorf("request/response sizes = %d/%d, want 2048/300", in.Sum, out.Sum)
+	}
+	if route, _ := out.Attributes.Value("http.route"); route.AsString() != "/media/:id" {
+		t.Errorf("http.route = %q, want /media/:id", route.AsString())
+	}
+	if status, _ := out.Attributes.Value(attribute.Key("http.response.status_code")); status.AsInt64() != 201 {
+		t.Errorf("status attribute = %d, want 201", status.AsInt64())
+	}
+}
+
+func TestMetricsStreamingResponse(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	r := router.New()
+	r.Use(Metrics(MetricsConfig{MeterProvider: sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))}))
+	r.GET("/events", func(c *context.Context) {
+		sent := 0
+		c.Stream(func(w io.Writer) bool {
+			io.WriteString(w, "data: tick\n\n")
+			sent++
+			return sent < 5
+		})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+
+	out := collectHistograms(t, reader)["http.server.response.body.size"]
+	if want := int64(5 * len("data: tick\n\n")); out.Sum != want {
+		t.Errorf("streamed bytes = %d, want %d", out.Sum, want)
+	}
+}
+
+func TestPrometheusExport(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	provider, err := NewPrometheusMeterProvider(reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	extra := prometheus.NewCounter(prometheus.CounterOpts{Name: "app_events_total", Help: "Test counter."})
+	reg.MustRegister(extra)
+	extra.Inc()
+
+	r := router.New()
+	r.Use(Metrics(MetricsConfig{MeterProvider: provider, SkipPaths: []string{"/metrics"}}))
+	r.GET("/users/:id", func(c *context.Context) {
+		c.String(200, "ok")
+	})
+	r.GET("/metrics", PrometheusHandler(reg))
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	body := w.Body.String()
+	for _, want := range []string{
+		"http_server_request_duration_seconds_count{",
+		`http_route="/users/:id"`,
+		"app_events_total 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q:\n%s", want, body)
+		}
+	}
+}
@@ -10,9 +10,11 @@ import (
 	"net/http"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
 	"golang.org/x/time/rate"
 )
 
@@ -38,18 +40,29 @@ type LoggerConfig struct {
 	Output        io.Writer
 	EnableLatency bool
 	EnableSize    bool
+
+	// 除錯用 body 紀錄（預設關閉）：僅保留前 MaxBodyBytes 位元組，
+	// 名稱符合 RedactFields 的 JSON / form 欄位值以 [REDACTED] 取代
+	LogRequestBody  bool
+	LogResponseBody bool
+	MaxBodyBytes    int      // 預設 4096
+	RedactFields    []string // 預設 DefaultRedactFields
+
+	// 降低日誌量（與 SkipPaths 併用）：SampleRate > 1 時成功請求（< 400）僅記錄 1/N；
+	// MinStatus 只記錄狀態碼 >= 門檻的請求，OnlyErrors 等同 MinStatus = 400。5xx 一律記錄。
+	SampleRate int
+	MinStatus  int
+	OnlyErrors bool
+
+	// 慢請求門檻（0 為停用）：超過者不受取樣與狀態碼門檻影響，以 warning 層級並標記 slow=true；
+	// HTTP/3 另附扣除 RTT 後的伺服器處理時間，以區分網路延遲
+	SlowThreshold time.Duration
 }
 
-// Logger 創建日誌中間件
+// Logger 創建日誌中間件（以單行文字寫入 config.Output，預設 stdout）
+// 需要層級、JSON 格式或輪轉時改用 LoggerWith
 func Logger(config LoggerConfig) hypcontext.HandlerFunc {
-	skipPaths := make(map[string]bool)
-	for _, path := range config.SkipPaths {
-		skipPaths[path] = true
-	}
-
-	if config.TimeFormat == "" {
-		config.TimeFormat = time.RFC3339
-	}
+	skipPaths, redactor, filter := prepareLoggerConfig(&config)
 
 	return func(c *hypcontext.Context) {
 		// 跳過特定路徑
@@ -62,6 +75,10 @@ func Logger(config LoggerConfig) hypcontext.HandlerFunc {
 		path := c.Request.URL.Path
 		raw := c.Request.URL.RawQuery
 
+		// body 擷取：請求以 tee 記錄 handler 實際讀取的內容，回應包裝 ResponseWriter
+		reqBody, respBody, restore := captureBodies(c, config)
+		defer restore()
+
 		// 處理請求
 		c.Next()
 
@@ -74,15 +91,13 @@ func Logger(config LoggerConfig) hypcontext.HandlerFunc {
 		// 獲取狀態碼和回應大小
 		statusCode := c.Response.Status()
 		bodySize := c.Response.Size()
+		slow := config.SlowThreshold > 0 && latency > config.SlowThreshold
+		if !slow && !filter.allow(statusCode) {
+			return
+		}
 
 		// 記錄協議版本
-		protocol := "HTTP/1.1"
-		// 從 context 中獲取協議資訊
-		if protoValue, exists := c.Get("protocol"); exists {
-			if proto, ok := protoValue.(string); ok {
-				protocol = proto
-			}
-		}
+		protocol := c.Protocol()
 
 		// 格式化日誌
 		if raw != "" {
@@ -108,6 +123,20 @@ func Logger(config LoggerConfig) hypcontext.HandlerFunc {
 			logMessage += fmt.Sprintf(" | RTT: %v | CongWin: %d", rtt, congWin)
 		}
 
+		if slow {
+			logMessage += fmt.Sprintf(" | WARNING slow=true threshold=%v", config.SlowThreshold)
+			if protocol == "HTTP/3" {
+				logMessage += fmt.Sprintf(" server=%v", serverTime(latency, c.GetRTT()))
+			}
+		}
+
+		if reqBody != nil {
+			logMessage += " | req: " + redactor.format(reqBody, c.ContentType())
+		}
+		if respBody != nil {
+			logMessage += " | resp: " + redactor.format(respBody, c.Response.Header().Get("Content-Type"))
+		}
+
 		// 輸出日誌
 		if config.Output != nil {
 			fmt.Fprintln(config.Output, logMessage)
@@ -117,6 +146,158 @@ func Logger(config LoggerConfig) hypcontext.HandlerFunc {
 	}
 }
 
+// LoggerWith 創建經由 pkg/logger 輸出的結構化存取日誌中間件
+// 欄位為 method、path、status、latency_ms、ip、request_id、protocol、size、user_agent，
+// HTTP/3 另附 rtt_ms、cong_win；logger 為 json 格式時輸出 JSON，否則為 key=value 文字。
+// 5xx 以 Error、4xx 以 Warning、其餘以 Info 層級記錄（config.Output / TimeFormat 不適用）。
+//
+// EX：
+//
+//	srv.Use(middleware.LoggerWith(log, middleware.LoggerConfig{SkipPaths: []string{"/livez"}}))
+func LoggerWith(log *logger.Logger, config LoggerConfig) hypcontext.HandlerFunc {
+	skipPaths, redactor, filter := prepareLoggerConfig(&config)
+
+	return func(c *hypcontext.Context) {
+		if skipPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		start := time.Now()
+		path := c.Request.URL.Path
+		if raw := c.Request.URL.RawQuery; raw != "" {
+			path = path + "?" + raw
+		}
+
+		reqBody, respBody, restore := captureBodies(c, config)
+		defer restore()
+
+		c.Next()
+
+		latency := time.Since(start)
+		status := c.Response.Status()
+		slow := config.SlowThreshold > 0 && latency > config.SlowThreshold
+		if !slow && !filter.allow(status) {
+			return
+		}
+		protocol := c.Protocol()
+
+		requestID := c.GetString("request_id")
+		if requestID == "" {
+			requestID = c.GetHeader("X-Request-ID")
+		}
+
+		fields := []interface{}{
+			"method", c.Request.Method,
+			"path", path,
+			"status", status,
+			"latency_ms", float64(latency.Microseconds()) / 1000,
+			"ip", c.ClientIP(),
+			"request_id", requestID,
+			"protocol", protocol,
+			"size", c.Response.Size(),
+			"user_agent", c.GetHeader("User-Agent"),
+		}
+		if protocol == "HTTP/3" {
+			fields = append(fields,
+				"rtt_ms", float64(c.GetRTT().Microseconds())/1000,
+				"cong_win", c.GetCongestionWindow(),
+			)
+		}
+		if slow {
+			fields = append(fields, "slow", true)
+			if protocol == "HTTP/3" {
+				fields = append(fields, "server_ms", float64(serverTime(latency, c.GetRTT()).Microseconds())/1000)
+			}
+		}
+		if reqBody != nil {
+			fields = append(fields, "req_body", redactor.format(reqBody, c.ContentType()))
+		}
+		if respBody != nil {
+			fields = append(fields, "resp_body", redactor.format(respBody, c.Response.Header().Get("Content-Type")))
+		}
+
+		switch {
+		case status >= http.StatusInternalServerError:
+			log.Error("access", fields...)
+		case status >= http.StatusBadRequest || slow:
+			log.Warning("access", fields...)
+		default:
+			log.Info("access", fields...)
+		}
+	}
+}
+
+// RequestLogger 為每個請求掛上帶有 request_id、method、path、protocol 欄位的 logger
+// handler 以 c.Logger() 取得，輸出的每一行都能與該請求關聯；需放在 RequestID 之後
+//
+// EX：
+//
+//	srv.Use(middleware.RequestID(middleware.RequestIDConfig{}), middleware.RequestLogger(log))
+//	// handler 內：c.Logger().Infow("created user", "id", id)
+func RequestLogger(log *logger.Logger) hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		c.AttachLogger(log)
+		c.Next()
+	}
+}
+
+// prepareLoggerConfig 套用預設值並建立跳過路徑表、遮蔽器與取樣過濾器
+func prepareLoggerConfig(config *LoggerConfig) (map[string]bool, *bodyRedactor, *accessLogFilter) {
+	skipPaths := make(map[string]bool)
+	for _, path := range config.SkipPaths {
+		skipPaths[path] = true
+	}
+
+	if config.TimeFormat == "" {
+		config.TimeFormat = time.RFC3339
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 4096
+	}
+	if config.RedactFields == nil {
+		config.RedactFields = DefaultRedactFields
+	}
+	if config.OnlyErrors && config.MinStatus < http.StatusBadRequest {
+		config.MinStatus = http.StatusBadRequest
+	}
+
+	filter := &accessLogFilter{minStatus: config.MinStatus}
+	if config.SampleRate > 1 {
+		filter.sampleRate = uint64(config.SampleRate)
+	}
+	return skipPaths, newBodyRedactor(config.RedactFields), filter
+}
+
+// serverTime 扣除一次 RTT 估算伺服器處理時間（HTTP/3 慢請求用）
+func serverTime(latency, rtt time.Duration) time.Duration {
+	if rtt <= 0 || rtt >= latency {
+		return latency
+	}
+	return latency - rtt
+}
+
+// accessLogFilter 依狀態碼門檻與取樣率決定是否記錄
+type accessLogFilter struct {
+	minStatus  int
+	sampleRate uint64
+	counter    atomic.Uint64
+}
+
+// allow 5xx 一律記錄；成功請求每 sampleRate 筆記錄第一筆
+func (f *accessLogFilter) allow(status int) bool {
+	if status >= http.StatusInternalServerError {
+		return true
+	}
+	if status < f.minStatus {
+		return false
+	}
+	if f.sampleRate > 1 && status < http.StatusBadRequest {
+		return f.counter.Add(1)%f.sampleRate == 1
+	}
+	return true
+}
+
 // ===== 速率限制中間件 =====
 
 // RateLimiterConfig 速率限制配置
@@ -188,15 +369,13 @@ func RateLimiter(config RateLimiterConfig) hypcontext.HandlerFunc {
 		// HTTP/3 優化：使用 QUIC 的流控制特性
 		if config.UseHTTP3 {
 			// 檢查是否為 HTTP/3
-			if protoValue, exists := c.Get("protocol"); exists {
-				if proto, ok := protoValue.(string); ok && proto == "HTTP/3" {
-					// 根據 RTT 動態調整速率
-					rtt := c.GetRTT()
-					if rtt > 100*time.Millisecond {
-						// 高延遲時稍微放寬限制 (修正: 使用浮點數計算)
-						adjustedRate := float64(config.Rate) * 1.2
-						limiter.SetLimit(rate.Limit(adjustedRate))
-					}
+			if c.IsHTTP3() {
+				// 根據 RTT 動態調整速率
+				rtt := c.GetRTT()
+				if rtt > 100*time.Millisecond {
+					// 高延遲時稍微放寬限制 (修正: 使用浮點數計算)
+					adjustedRate := float64(config.Rate) * 1.2
+					limiter.SetLimit(rate.Limit(adjustedRate))
 				}
 			}
 		}
@@ -238,13 +417,11 @@ func Timeout(config TimeoutConfig) hypcontext.HandlerFunc {
 	return func(c *hypcontext.Context) {
 		// HTTP/3 優化：根據 RTT 動態調整超時
 		timeout := config.Timeout
-		if protoValue, exists := c.Get("protocol"); exists {
-			if proto, ok := protoValue.(string); ok && proto == "HTTP/3" {
-				rtt := c.GetRTT()
-				if rtt > 0 {
-					// 根據 RTT 調整超時時間
-					timeout = timeout + rtt*2
-				}
+		if c.IsHTTP3() {
+			rtt := c.GetRTT()
+			if rtt > 0 {
+				// 根據 RTT 調整超時時間
+				timeout = timeout + rtt*2
 			}
 		}
 
@@ -285,6 +462,15 @@ func Timeout(config TimeoutConfig) hypcontext.HandlerFunc {
 	}
 }
 
+// NoRequestTimeout 讓路由豁免 server 層的 request_timeout
+// 用法：r.GET("/events", middleware.NoRequestTimeout(), handler)
+func NoRequestTimeout() hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		c.DisableRequestTimeout()
+		c.Next()
+	}
+}
+
 // ===== BodyLimit 中間件 =====
 
 // BodyLimitConfig 請求 body 大小限制配置
@@ -332,6 +518,25 @@ func MethodOverride() hypcontext.HandlerFunc {
 	}
 }
 
+// ===== EarlyDataGuard 中間件 =====
+
+// EarlyDataGuard 拒絕以 0-RTT early data 送達的非安全方法請求，回傳 425 Too Early（RFC 8470），
+// 客戶端會在握手完成後以 1-RTT 重送。0-RTT 資料可被截取重放，改變狀態的請求不應在此時處理；
+// GET / HEAD / OPTIONS 照常放行。
+func EarlyDataGuard() hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		if c.Is0RTT() {
+			switch c.Request.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+			default:
+				c.AbortWithStatus(hypcontext.StatusTooEarly)
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
 // ===== 預設中間件配置 =====
 
 // DefaultMiddleware 創建預設中間件組合
@@ -369,6 +574,8 @@ func HTTP3Middleware() []hypcontext.HandlerFunc {
 			StackSize:         4 << 10,
 			DisablePrintStack: false,
 		}),
+		// 0-RTT 重放防護
+		EarlyDataGuard(),
 		// 日誌
 		Logger(LoggerConfig{
 			TimeFormat:    time.RFC3339,
@@ -1,12 +1,23 @@
 package middleware
 
 import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
 	"github.com/maoxiaoyue/hypgo/pkg/router"
+	"github.com/maoxiaoyue/hypgo/pkg/signing"
 )
 
 func TestSecurity(t *testing.T) {
@@ -54,3 +65,413 @@ func TestDefaultMiddleware(t *testing.T) {
 		t.Fatal("DefaultMiddleware() returned empty handlers")
 	}
 }
+
+// --- BasicAuth 測試 ---
+
+func newBasicAuthRouter(auth context.HandlerFunc) *router.Router {
+	r := router.New()
+	r.GET("/admin", auth, func(c *context.Context) {
+		c.String(200, c.GetString("user"))
+	})
+	return r
+}
+
+func TestBasicAuth(t *testing.T) {
+	r := newBasicAuthRouter(BasicAuth(map[string]string{"admin": "secret"}, "Admin Area"))
+
+	tests := []struct {
+		name     string
+		user     string
+		pass     string
+		noHeader bool
+		want     int
+	}{
+		{"valid", "admin", "secret", false, http.StatusOK},
+		{"wrong password", "admin", "nope", false, http.StatusUnauthorized},
+		{"unknown user", "guest", "secret", false, http.StatusUnauthorized},
+		{"missing credentials", "", "", true, http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest("GET", "/admin", nil)
+			if !tt.noHeader {
+				req.SetBasicAuth(tt.user, tt.pass)
+			}
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Fatalf("Expected %d, got %d", tt.want, w.Code)
+			}
+			if tt.want == http.StatusOK && w.Body.String() != "admin" {
+				t.Errorf("Expected user 'admin' in context, got %q", w.Body.String())
+			}
+			if tt.want == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") != `Basic realm="Admin Area"` {
+				t.Errorf("Unexpected WWW-Authenticate: %q", w.Header().Get("WWW-Authenticate"))
+			}
+		})
+	}
+}
+
+func TestBasicAuthFunc(t *testing.T) {
+	r := newBasicAuthRouter(BasicAuthFunc(func(user, pass string) bool {
+		return user == "db-user" && pass == "db-pass"
+	}))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/admin", nil)
+	req.SetBasicAuth("db-user", "db-pass")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "db-user" {
+		t.Errorf("Expected 200 db-user, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "/admin", nil)
+	req.SetBasicAuth("db-user", "wrong")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+}
+
+// --- Logger body 擷取測試 ---
+
+func TestLoggerBodyCapture(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{
+		Output:          &out,
+		LogRequestBody:  true,
+		LogResponseBody: true,
+	}))
+	r.POST("/login", func(c *context.Context) {
+		io.ReadAll(c.Request.Body)
+		c.JSON(200, map[string]string{"access_token": "abc123", "user": "alice"})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"user":"alice","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	line := out.String()
+	if strings.Contains(line, "hunter2") || strings.Contains(line, "abc123") {
+		t.Errorf("Expected secrets to be redacted, got %s", line)
+	}
+	if !strings.Contains(line, `\"user\":\"alice\"`) || strings.Count(line, "[REDACTED]") != 2 {
+		t.Errorf("Expected redacted request and response bodies, got %s", line)
+	}
+	if w.Body.String() == "" {
+		t.Error("Expected response to still reach the client")
+	}
+}
+
+func TestLoggerBodyTruncate(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{Output: &out, LogRequestBody: true, MaxBodyBytes: 8}))
+	r.POST("/upload", func(c *context.Context) {
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(200, strconv.Itoa(len(body)))
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/upload", strings.NewReader("password=secret&data=0123456789"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != "31" {
+		t.Errorf("Expected handler to read the full body, got %q", w.Body.String())
+	}
+	line := out.String()
+	if !strings.Contains(line, "(truncated, 31 bytes)") || strings.Contains(line, "secret") {
+		t.Errorf("Expected truncated redacted body, got %s", line)
+	}
+}
+
+func TestLoggerBodyOffByDefault(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{Output: &out}))
+	r.POST("/", func(c *context.Context) {
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/", strings.NewReader("password=x")))
+	if strings.Contains(out.String(), "req:") || strings.Contains(out.String(), "resp:") {
+		t.Errorf("Expected no body logging by default, got %s", out.String())
+	}
+}
+
+func TestLoggerWith(t *testing.T) {
+	var out bytes.Buffer
+	log, _ := logger.New("debug", "", &out, false)
+	log.SetFormat("json")
+
+	r := router.New()
+	r.Use(RequestID(RequestIDConfig{}), LoggerWith(log, LoggerConfig{}))
+	r.GET("/missing", func(c *context.Context) {
+		c.String(404, "nope")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/missing?q=1", nil))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", out.String(), err)
+	}
+	if record["msg"] != "access" || record["level"] != "WARN" {
+		t.Errorf("Expected WARN access record, got %v", record)
+	}
+	if record["method"] != "GET" || record["path"] != "/missing?q=1" || record["status"] != float64(404) {
+		t.Errorf("unexpected fields: %v", record)
+	}
+	if id, _ := record["request_id"].(string); id == "" || id != w.Header().Get("X-Request-ID") {
+		t.Errorf("Expected request_id to match response header, got %v", record["request_id"])
+	}
+}
+
+func TestRequestLogger(t *testing.T) {
+	var out bytes.Buffer
+	log, _ := logger.New("debug", "", &out, false)
+	log.SetFormat("json")
+
+	r := router.New()
+	r.Use(RequestID(RequestIDConfig{}), RequestLogger(log))
+	r.POST("/users", func(c *context.Context) {
+		c.Logger().Infow("created user", "id", 42)
+		c.Status(201)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("POST", "/users", nil))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", out.String(), err)
+	}
+	if record["msg"] != "created user" || record["id"] != float64(42) {
+		t.Errorf("unexpected record: %v", record)
+	}
+	if record["method"] != "POST" || record["path"] != "/users" || record["protocol"] != "HTTP/1.1" {
+		t.Errorf("unexpected request fields: %v", record)
+	}
+	if id, _ := record["request_id"].(string); id == "" || id != w.Header().Get("X-Request-ID") {
+		t.Errorf("Expected request_id to match response header, got %v", record["request_id"])
+	}
+}
+
+// --- ErrorHandler 測試 ---
+
+func TestErrorHandler(t *testing.T) {
+	r := router.New()
+	r.Use(ErrorHandler())
+	r.GET("/missing", func(c *context.Context) {
+		c.Status(404)
+		c.AddPublicError(errors.New("order not found"))
+		c.Error(errors.New("sql: no rows"))
+	})
+	r.GET("/private", func(c *context.Context) {
+		c.Error(errors.New("dial tcp: refused"))
+	})
+	r.GET("/written", func(c *context.Context) {
+		c.Error(errors.New("ignored"))
+		c.String(200, "ok")
+	})
+
+	cases := []struct {
+		path, body string
+		code       int
+	}{
+		{"/missing", `{"errors":["order not found"]}`, 404},
+		{"/private", `{"errors":["Internal Server Error"],"reference":"req-1"}`, 500},
+		{"/written", "ok", 200},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", tc.path, nil)
+		req.Header.Set("X-Request-ID", "req-1")
+		r.ServeHTTP(w, req)
+		if w.Code != tc.code || strings.TrimSpace(w.Body.String()) != tc.body {
+			t.Errorf("%s: got %d %q, want %d %q", tc.path, w.Code, w.Body.String(), tc.code, tc.body)
+		}
+	}
+}
+
+// --- Logger 取樣測試 ---
+
+func TestLoggerSampling(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{Output: &out, SampleRate: 5}))
+	r.GET("/status/:code", func(c *context.Context) {
+		code, _ := strconv.Atoi(c.Param("code"))
+		c.String(code, "x")
+	})
+
+	for i := 0; i < 10; i++ {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/200", nil))
+	}
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/404", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/503", nil))
+
+	if n := strings.Count(out.String(), "\n"); n != 4 {
+		t.Errorf("Expected 2 sampled successes plus 404 and 503, got %d lines:\n%s", n, out.String())
+	}
+}
+
+func TestLoggerOnlyErrors(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{Output: &out, OnlyErrors: true}))
+	r.GET("/status/:code", func(c *context.Context) {
+		code, _ := strconv.Atoi(c.Param("code"))
+		c.String(code, "x")
+	})
+
+	for _, code := range []string{"200", "302", "404", "500"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/status/"+code, nil))
+	}
+
+	lines := out.String()
+	if strings.Count(lines, "\n") != 2 || !strings.Contains(lines, "| 404 |") || !strings.Contains(lines, "| 500 |") {
+		t.Errorf("Expected only 404 and 500 to be logged, got:\n%s", lines)
+	}
+}
+
+func TestLoggerMinStatusKeeps5xx(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{Output: &out, MinStatus: 600}))
+	r.GET("/boom", func(c *context.Context) {
+		c.String(500, "x")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/boom", nil))
+	if !strings.Contains(out.String(), "| 500 |") {
+		t.Errorf("Expected 5xx to always be logged, got %q", out.String())
+	}
+}
+
+// --- 慢請求測試 ---
+
+func TestLoggerSlowRequest(t *testing.T) {
+	var out bytes.Buffer
+	r := router.New()
+	r.Use(Logger(LoggerConfig{Output: &out, OnlyErrors: true, SlowThreshold: 10 * time.Millisecond}))
+	r.GET("/fast", func(c *context.Context) {
+		c.String(200, "ok")
+	})
+	r.GET("/slow", func(c *context.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.String(200, "ok")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fast", nil))
+	if out.Len() != 0 {
+		t.Fatalf("Expected fast success to be filtered, got %q", out.String())
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	if !strings.Contains(out.String(), "slow=true") {
+		t.Errorf("Expected slow request to bypass filters with slow=true, got %q", out.String())
+	}
+}
+
+func TestLoggerWithSlowRequest(t *testing.T) {
+	var out bytes.Buffer
+	log, _ := logger.New("debug", "", &out, false)
+	log.SetFormat("json")
+
+	r := router.New()
+	r.Use(LoggerWith(log, LoggerConfig{SampleRate: 1000, SlowThreshold: 10 * time.Millisecond}))
+	r.GET("/slow", func(c *context.Context) {
+		time.Sleep(30 * time.Millisecond)
+		c.String(200, "ok")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/slow", nil))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected every slow request to be logged despite sampling, got %d", len(lines))
+	}
+	var record map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record["level"] != "WARN" || record["slow"] != true {
+		t.Errorf("Expected WARN record with slow=true, got %v", record)
+	}
+}
+
+func TestServerTime(t *testing.T) {
+	if got := serverTime(100*time.Millisecond, 30*time.Millisecond); got != 70*time.Millisecond {
+		t.Errorf("Expected 70ms, got %v", got)
+	}
+	if got := serverTime(100*time.Millisecond, 0); got != 100*time.Millisecond {
+		t.Errorf("Expected latency when RTT unknown, got %v", got)
+	}
+}
+
+// --- EarlyDataGuard 測試 ---
+
+func TestEarlyDataGuard(t *testing.T) {
+	r := router.New()
+	r.Use(EarlyDataGuard())
+	handler := func(c *context.Context) { c.String(http.StatusOK, "ok") }
+	r.GET("/items", handler)
+	r.POST("/items", handler)
+
+	tests := []struct {
+		method    string
+		earlyData bool
+		handshake bool
+		want      int
+	}{
+		{"POST", true, true, context.StatusTooEarly},   // 代理轉發的 Early-Data: 1
+		{"POST", false, false, context.StatusTooEarly}, // 握手未完成即送達
+		{"GET", true, false, http.StatusOK},
+		{"POST", false, true, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/items", nil)
+		req.TLS = &tls.ConnectionState{HandshakeComplete: tt.handshake}
+		if tt.earlyData {
+			req.Header.Set(context.HeaderEarlyData, "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("%s earlyData=%v handshake=%v: status = %d, want %d",
+				tt.method, tt.earlyData, tt.handshake, w.Code, tt.want)
+		}
+	}
+}
+
+func TestSignedURL(t *testing.T) {
+	secret := []byte("download-secret")
+	r := router.New()
+	r.GET("/downloads/:file", SignedURL(secret), func(c *context.Context) {
+		c.String(http.StatusOK, c.Param("file"))
+	})
+
+	link, _ := signing.SignURL("https://cdn.example.com/downloads/report.pdf", nil, time.Minute, secret)
+	u, _ := url.Parse(link)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", u.RequestURI(), nil))
+	if w.Code != http.StatusOK || w.Body.String() != "report.pdf" {
+		t.Errorf("Expected signed link to be served, got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", strings.Replace(u.RequestURI(), "report", "payroll", 1), nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected tampered link to be rejected with 403, got %d", w.Code)
+	}
+}
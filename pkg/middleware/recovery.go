@@ -7,12 +7,21 @@ import (
 	"fmt"
 	"net/http"
 	"runtime"
+	"strings"
+	"time"
 
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
 )
 
 // ===== Recovery 中間件 =====
 
+// DefaultRedactHeaders panic 報告預設遮蔽的請求標頭（不分大小寫）
+var DefaultRedactHeaders = []string{
+	"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie",
+	"X-Api-Key", "X-Auth-Token", "X-CSRF-Token",
+}
+
 // RecoveryConfig Recovery 配置
 type RecoveryConfig struct {
 	StackSize         int
@@ -20,15 +29,94 @@ type RecoveryConfig struct {
 	DisablePrintStack bool
 	LogLevel          string
 	ErrorHandler      func(c *hypcontext.Context, err interface{})
+
+	// Logger 輸出 panic 報告的結構化 logger，預設為全域 logger
+	Logger *logger.Logger
+	// ReportFunc 額外的回報出口（如 Sentry），在回應錯誤前同步呼叫
+	ReportFunc func(report *PanicReport)
+	// RedactHeaders 報告中遮蔽的標頭，預設 DefaultRedactHeaders
+	RedactHeaders []string
+	// IncludeBody 報告附上請求 body（依 RedactFields 遮蔽 JSON / form 欄位），預設關閉
+	IncludeBody bool
+	// MaxBodyBytes 附上的 body 上限，預設 4096
+	MaxBodyBytes int
+	// RedactFields body 中遮蔽的欄位名稱，預設 DefaultRedactFields
+	RedactFields []string
+}
+
+// PanicReport panic 的結構化報告，包含請求資訊以利除錯
+type PanicReport struct {
+	Time      time.Time
+	Panic     interface{}
+	Stack     string
+	Method    string
+	Path      string
+	Route     string // 路由模板，未匹配時為空
+	RequestID string
+	UserID    interface{}
+	ClientIP  string
+	Protocol  string
+	Headers   map[string]string // 已遮蔽敏感標頭
+	Body      string            // 僅在 IncludeBody 時填入，已遮蔽
+}
+
+// fields 轉為 logger 的 key/value
+func (r *PanicReport) fields() []interface{} {
+	fields := []interface{}{
+		"panic", fmt.Sprint(r.Panic),
+		"method", r.Method,
+		"path", r.Path,
+		"route", r.Route,
+		"request_id", r.RequestID,
+		"user_id", r.UserID,
+		"ip", r.ClientIP,
+		"protocol", r.Protocol,
+		"headers", r.Headers,
+	}
+	if r.Body != "" {
+		fields = append(fields, "body", r.Body)
+	}
+	return append(fields, "stack", r.Stack)
 }
 
 // Recovery 創建錯誤恢復中間件
+// panic 時建立 PanicReport（方法、路徑、路由、request id、使用者、遮蔽後的標頭與堆疊），
+// 以結構化 logger 的 Error 層級記錄並交給 ReportFunc 與 server 註冊的 ErrorReporter，再回應 500：
+// debug / test 模式附錯誤與堆疊，release 模式只有通用訊息與 reference（與日誌中的 reference 對應）
+//
+// EX：
+//
+//	srv.Use(middleware.Recovery(middleware.RecoveryConfig{
+//		Logger:     log,
+//		ReportFunc: func(r *middleware.PanicReport) { errorSink.Capture(r) },
+//	}))
 func Recovery(config RecoveryConfig) hypcontext.HandlerFunc {
 	if config.StackSize == 0 {
 		config.StackSize = 4 << 10 // 4KB
 	}
+	if config.RedactHeaders == nil {
+		config.RedactHeaders = DefaultRedactHeaders
+	}
+	redactHeaders := make(map[string]bool, len(config.RedactHeaders))
+	for _, h := range config.RedactHeaders {
+		redactHeaders[http.CanonicalHeaderKey(h)] = true
+	}
+	if config.MaxBodyBytes <= 0 {
+		config.MaxBodyBytes = 4096
+	}
+	if config.RedactFields == nil {
+		config.RedactFields = DefaultRedactFields
+	}
+	redactor := newBodyRedactor(config.RedactFields)
 
 	return func(c *hypcontext.Context) {
+		var body *cappedBuffer
+		if config.IncludeBody {
+			var restore func()
+			body, _, restore = captureBodies(c, LoggerConfig{LogRequestBody: true, MaxBodyBytes: config.MaxBodyBytes})
+			defer restore()
+		}
+
 		defer func() {
 			if err := recover(); err != nil {
 				// 獲取堆疊資訊
@@ -36,24 +124,46 @@ func Recovery(config RecoveryConfig) hypcontext.HandlerFunc {
 				length := runtime.Stack(stack, !config.DisableStackAll)
 				stack = stack[:length]
 
+				report := newPanicReport(c, err, stack, redactHeaders)
+				if body != nil {
+					report.Body = redactor.format(body, c.ContentType())
+				}
+
 				// 記錄錯誤
 				if !config.DisablePrintStack {
-					fmt.Printf("[Recovery] panic recovered:\n%s\n%s\n", err, stack)
+					log := config.Logger
+					if log == nil {
+						log = logger.GetLogger()
+					}
+					log.Error("panic recovered", append(report.fields(), "reference", c.ErrorReference())...)
 				}
+				if config.ReportFunc != nil {
+					config.ReportFunc(report)
+				}
+				c.ReportError(panicError(err), report.reporterFields())
 
 				// HTTP/3 特定處理：確保流正確關閉
-				if protoValue, exists := c.Get("protocol"); exists {
-					if proto, ok := protoValue.(string); ok && proto == "HTTP/3" {
-						// 關閉 QUIC 流
-						// 這裡需要實際的流關閉邏輯
-					}
+				if c.IsHTTP3() {
+					// 關閉 QUIC 流
+					// 這裡需要實際的流關閉邏輯
 				}
 
 				// 執行自定義錯誤處理器
 				if config.ErrorHandler != nil {
 					config.ErrorHandler(c, err)
+				} else if c.Response.Written() {
+					c.Abort()
+				} else if hypcontext.ExposeErrorDetails() {
+					// debug / test 模式回傳錯誤與堆疊，方便開發時排查
+					c.AbortWithStatusJSON(http.StatusInternalServerError, hypcontext.H{
+						"error": fmt.Sprint(err),
+						"stack": string(stack),
+					})
 				} else {
-					c.AbortWithStatus(http.StatusInternalServerError)
+					// release 模式只回應通用訊息與 reference，對照日誌中的完整堆疊
+					resp := hypcontext.H{"error": http.StatusText(http.StatusInternalServerError)}
+					resp[hypcontext.GetEnvelopeFields().Reference] = c.ErrorReference()
+					c.AbortWithStatusJSON(http.StatusInternalServerError, resp)
 				}
 			}
 		}()
@@ -62,6 +172,58 @@ func Recovery(config RecoveryConfig) hypcontext.HandlerFunc {
 	}
 }
 
+// newPanicReport 由請求建立 panic 報告
+func newPanicReport(c *hypcontext.Context, err interface{}, stack []byte, redactHeaders map[string]bool) *PanicReport {
+	requestID := c.GetString("request_id")
+	if requestID == "" {
+		requestID = c.GetHeader("X-Request-ID")
+	}
+	headers := make(map[string]string, len(c.Request.Header))
+	for name, values := range c.Request.Header {
+		if redactHeaders[name] {
+			headers[name] = redactedValue
+		} else {
+			headers[name] = strings.Join(values, ", ")
+		}
+	}
+	return &PanicReport{
+		Time:      time.Now(),
+		Panic:     err,
+		Stack:     string(stack),
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Route:     c.FullPath(),
+		RequestID: requestID,
+		UserID:    c.GetUserID(),
+		ClientIP:  c.ClientIP(),
+		Protocol:  c.Protocol(),
+		Headers:   headers,
+	}
+}
+
+// reporterFields ErrorReporter 的附加欄位（請求資訊由 Context.ReportError 補上）
+func (r *PanicReport) reporterFields() map[string]interface{} {
+	fields := map[string]interface{}{
+		"panic":    true,
+		"stack":    r.Stack,
+		"ip":       r.ClientIP,
+		"protocol": r.Protocol,
+		"headers":  r.Headers,
+	}
+	if r.Body != "" {
+		fields["body"] = r.Body
+	}
+	return fields
+}
+
+// panicError 將 panic 值轉為 error，保留原始 error 以供 errors.Is / As
+func panicError(v interface{}) error {
+	if err, ok := v.(error); ok {
+		return fmt.Errorf("panic: %w", err)
+	}
+	return fmt.Errorf("panic: %v", v)
+}
+
 // SimpleErrorHandler 簡單的錯誤處理中間件
 func SimpleErrorHandler() hypcontext.HandlerFunc {
 	return func(c *hypcontext.Context) {
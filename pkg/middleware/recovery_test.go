@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func TestRecoveryPanicReport(t *testing.T) {
+	var out bytes.Buffer
+	log, _ := logger.New("debug", "", &out, false)
+	log.SetFormat("json")
+
+	var report *PanicReport
+	r := router.New()
+	r.Use(RequestID(RequestIDConfig{}), Recovery(RecoveryConfig{
+		Logger:      log,
+		ReportFunc:  func(rep *PanicReport) { report = rep },
+		IncludeBody: true,
+	}))
+	r.POST("/orders/:id", func(c *context.Context) {
+		c.SetUserID("u-42")
+		c.GetRawData()
+		panic("boom")
+	})
+
+	req := httptest.NewRequest("POST", "/orders/7", strings.NewReader(`{"item":"book","password":"hunter2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer secret-token")
+	req.Header.Set("X-Trace", "abc")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", w.Code)
+	}
+	if report == nil {
+		t.Fatal("Expected ReportFunc to receive a report")
+	}
+	if report.Route != "/orders/:id" || report.Path != "/orders/7" || report.Method != "POST" {
+		t.Errorf("unexpected request fields: %+v", report)
+	}
+	if report.RequestID == "" || report.RequestID != w.Header().Get("X-Request-ID") {
+		t.Errorf("Expected request id %q, got %q", w.Header().Get("X-Request-ID"), report.RequestID)
+	}
+	if report.UserID != "u-42" || report.Panic != "boom" || !strings.Contains(report.Stack, "goroutine") {
+		t.Errorf("unexpected panic fields: user=%v panic=%v", report.UserID, report.Panic)
+	}
+	if report.Headers["Authorization"] != redactedValue || report.Headers["X-Trace"] != "abc" {
+		t.Errorf("Expected sanitized headers, got %v", report.Headers)
+	}
+	if !strings.Contains(report.Body, "book") || !strings.Contains(report.Body, redactedValue) || strings.Contains(report.Body, "hunter2") {
+		t.Errorf("Expected redacted body, got %s", report.Body)
+	}
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", out.String(), err)
+	}
+	if record["msg"] != "panic recovered" || record["level"] != "ERROR" || record["route"] != "/orders/:id" || record["request_id"] != report.RequestID {
+		t.Errorf("unexpected log record: %v", record)
+	}
+	if strings.Contains(out.String(), "secret-token") {
+		t.Error("Authorization header leaked into the log")
+	}
+}
+
+func TestRecoveryOmitsBodyByDefault(t *testing.T) {
+	var report *PanicReport
+	r := router.New()
+	r.Use(Recovery(RecoveryConfig{DisablePrintStack: true, ReportFunc: func(rep *PanicReport) { report = rep }}))
+	r.POST("/", func(c *context.Context) {
+		c.GetRawData()
+		panic("boom")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/", strings.NewReader("secret body")))
+	if report == nil || report.Body != "" {
+		t.Errorf("Expected report without body, got %+v", report)
+	}
+}
+
+func TestRecoveryHidesPanicInRelease(t *testing.T) {
+	r := router.New()
+	r.Use(Recovery(RecoveryConfig{DisablePrintStack: true}))
+	r.GET("/", func(c *context.Context) { panic("secret token abc123") })
+
+	serve := func() string {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", "req-7")
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("Expected 500, got %d", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	if body := serve(); strings.Contains(body, "abc123") || strings.Contains(body, "goroutine") || !strings.Contains(body, `"reference":"req-7"`) {
+		t.Errorf("release response leaked panic details: %s", body)
+	}
+
+	context.SetEnvelopeFields(context.EnvelopeFields{Reference: "trace_id"})
+	body := serve()
+	context.SetEnvelopeFields(context.EnvelopeFields{})
+	if !strings.Contains(body, `"trace_id":"req-7"`) {
+		t.Errorf("custom reference field not applied: %s", body)
+	}
+
+	context.SetMode(context.TestMode)
+	defer context.SetMode(context.ReleaseMode)
+	if body := serve(); !strings.Contains(body, "abc123") || !strings.Contains(body, "goroutine") {
+		t.Errorf("test mode response should include panic and stack: %s", body)
+	}
+}
+
+func TestRecoveryReportsToErrorReporter(t *testing.T) {
+	var reported error
+	var fields map[string]interface{}
+	reporter := context.ErrorReporterFunc(func(err error, ctx map[string]interface{}) { reported, fields = err, ctx })
+
+	sentinel := errors.New("nil map write")
+	r := router.New()
+	r.Use(Recovery(RecoveryConfig{DisablePrintStack: true}))
+	r.GET("/jobs/:id", func(c *context.Context) { panic(sentinel) })
+
+	req := httptest.NewRequest("GET", "/jobs/3", nil)
+	req = req.WithContext(context.WithErrorReporter(req.Context(), reporter))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !errors.Is(reported, sentinel) {
+		t.Fatalf("Expected panic error to wrap the panic value, got %v", reported)
+	}
+	if fields["route"] != "/jobs/:id" || fields["panic"] != true || !strings.Contains(fields["stack"].(string), "goroutine") {
+		t.Errorf("Unexpected reporter fields %v", fields)
+	}
+}
@@ -4,9 +4,7 @@
 package middleware
 
 import (
-	"bytes"
 	"crypto/subtle"
-	"encoding/base64"
 	"fmt"
 	"net"
 	"net/http"
@@ -90,55 +88,55 @@ type AuthConfig struct {
 // AuthValidator 認證驗證器
 type AuthValidator func(username, password string, c *hypcontext.Context) bool
 
-// BasicAuth 創建基本認證中間件
-func BasicAuth(config AuthConfig) hypcontext.HandlerFunc {
+// BasicAuth 以帳號密碼表進行 HTTP Basic 認證，realm 為空時使用 "Restricted"
+// 通過後以 c.Set("user", username) 設置使用者；失敗回傳 401 並附 WWW-Authenticate
+//
+// EX：
+//
+//	admin := r.NewGroup("/admin", middleware.BasicAuth(map[string]string{"admin": "secret"}, "Admin"))
+func BasicAuth(accounts map[string]string, realm string) hypcontext.HandlerFunc {
+	return BasicAuthWithConfig(AuthConfig{Realm: realm, Authorized: accounts})
+}
+
+// BasicAuthFunc 以自訂驗證函式進行 HTTP Basic 認證（如查詢資料庫）
+func BasicAuthFunc(validate func(username, password string) bool) hypcontext.HandlerFunc {
+	return BasicAuthWithConfig(AuthConfig{
+		Validator: func(username, password string, _ *hypcontext.Context) bool {
+			return validate(username, password)
+		},
+	})
+}
+
+// BasicAuthWithConfig 創建基本認證中間件
+func BasicAuthWithConfig(config AuthConfig) hypcontext.HandlerFunc {
 	if config.Realm == "" {
 		config.Realm = "Restricted"
 	}
+	challenge := fmt.Sprintf(`Basic realm=%q`, config.Realm)
 
-	return func(c *hypcontext.Context) {
-		// 獲取認證頭
-		auth := c.GetHeader("Authorization")
-		if auth == "" {
-			c.Header("WWW-Authenticate", fmt.Sprintf(`Basic realm="%s"`, config.Realm))
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
+	unauthorized := func(c *hypcontext.Context) {
+		c.Header("WWW-Authenticate", challenge)
+		c.AbortWithStatus(http.StatusUnauthorized)
+	}
 
+	return func(c *hypcontext.Context) {
 		// 解析認證資訊
-		const prefix = "Basic "
-		if !strings.HasPrefix(auth, prefix) {
-			c.AbortWithStatus(http.StatusUnauthorized)
+		username, password, ok := c.BasicAuth()
+		if !ok {
+			unauthorized(c)
 			return
 		}
 
-		// 解碼認證資訊
-		decoded, err := base64.StdEncoding.DecodeString(auth[len(prefix):])
-		if err != nil {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		// 分離使用者名稱和密碼
-		parts := bytes.SplitN(decoded, []byte(":"), 2)
-		if len(parts) != 2 {
-			c.AbortWithStatus(http.StatusUnauthorized)
-			return
-		}
-
-		username := string(parts[0])
-		password := string(parts[1])
-
 		// 驗證認證資訊
 		valid := false
 		if config.Validator != nil {
 			valid = config.Validator(username, password, c)
-		} else if expectedPass, ok := config.Authorized[username]; ok {
-			valid = subtle.ConstantTimeCompare([]byte(password), []byte(expectedPass)) == 1
+		} else {
+			valid = matchBasicAccount(config.Authorized, username, password)
 		}
 
 		if !valid {
-			c.AbortWithStatus(http.StatusUnauthorized)
+			unauthorized(c)
 			return
 		}
 
@@ -148,6 +146,17 @@ func BasicAuth(config AuthConfig) hypcontext.HandlerFunc {
 	}
 }
 
+// matchBasicAccount 以常數時間比對帳號密碼，逐一比對所有帳號以免洩漏帳號是否存在
+func matchBasicAccount(accounts map[string]string, username, password string) bool {
+	matched := 0
+	for user, pass := range accounts {
+		userOK := subtle.ConstantTimeCompare([]byte(username), []byte(user))
+		passOK := subtle.ConstantTimeCompare([]byte(password), []byte(pass))
+		matched |= userOK & passOK
+	}
+	return matched == 1
+}
+
 // ===== JWT 中間件 =====
 
 // JWTConfig JWT 配置
@@ -0,0 +1,358 @@
+// @chris
+package middleware
+
+import (
+	"context"
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/redis/go-redis/v9"
+)
+
+// ===== Session 中間件 =====
+
+// SessionStore Session 儲存層介面
+// Load 在 id 不存在或已過期時回傳 (nil, nil)
+type SessionStore interface {
+	Load(ctx context.Context, id string) (map[string]interface{}, error)
+	Save(ctx context.Context, id string, values map[string]interface{}, ttl time.Duration) error
+	Delete(ctx context.Context, id string) error
+}
+
+// SessionConfig Session 配置
+type SessionConfig struct {
+	Store          SessionStore  // 預設 NewMemorySessionStore()
+	Secret         []byte        // 非空時以 HMAC-SHA256 簽章 cookie 中的 session id
+	TTL            time.Duration // 預設 24 小時
+	CookieName     string        // 預設 "hypgo_session"
+	CookieDomain   string
+	CookiePath     string // 預設 "/"
+	CookieSecure   bool
+	CookieHTTPOnly bool
+	CookieSameSite http.SameSite // 預設 Lax
+}
+
+// Session 創建 Session 中間件，透過 c.Session() 存取
+//
+// EX：
+//
+//	r.Use(middleware.Session(middleware.SessionConfig{
+//		Secret:         []byte(os.Getenv("SESSION_SECRET")),
+//		CookieHTTPOnly: true,
+//	}))
+//
+//	r.POST("/login", func(c *context.Context) {
+//		sess := c.Session()
+//		sess.Set("user_id", id)
+//		sess.RegenerateID() // 權限變更後更換 id
+//		if err := sess.Save(); err != nil { ... }
+//	})
+func Session(config SessionConfig) hypcontext.HandlerFunc {
+	if config.Store == nil {
+		config.Store = NewMemorySessionStore()
+	}
+	if config.TTL <= 0 {
+		config.TTL = 24 * time.Hour
+	}
+	if config.CookieName == "" {
+		config.CookieName = "hypgo_session"
+	}
+	if config.CookiePath == "" {
+		config.CookiePath = "/"
+	}
+	if config.CookieSameSite == 0 {
+		config.CookieSameSite = http.SameSiteLaxMode
+	}
+
+	return func(c *hypcontext.Context) {
+		sess := &session{c: c, config: &config, values: make(map[string]interface{})}
+
+		if id, ok := readSessionCookie(c, &config); ok {
+			values, err := config.Store.Load(c.Request.Context(), id)
+			if err != nil {
+				c.Error(fmt.Errorf("session load: %w", err))
+			}
+			if values != nil {
+				sess.id = id
+				sess.values = values
+			}
+		}
+		if sess.id == "" {
+			sess.id = newSessionID()
+			sess.isNew = true
+		}
+
+		c.Set("session", sess)
+		c.Next()
+	}
+}
+
+// session hypcontext.Session 的實作
+type session struct {
+	mu         sync.Mutex
+	c          *hypcontext.Context
+	config     *SessionConfig
+	id         string
+	values     map[string]interface{}
+	isNew      bool
+	regenerate bool
+}
+
+// ID 目前的 session id
+func (s *session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// Get 取得值
+func (s *session) Get(key string) interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.values[key]
+}
+
+// Set 設置值
+func (s *session) Set(key string, value interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = value
+}
+
+// Delete 刪除值
+func (s *session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+}
+
+// Clear 清空所有值
+func (s *session) Clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values = make(map[string]interface{})
+}
+
+// RegenerateID 標記於下次 Save 時更換 session id
+func (s *session) RegenerateID() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.regenerate = true
+}
+
+// Save 寫回儲存層並設置 cookie；若標記過 RegenerateID 則刪除舊 id 並改用新 id
+func (s *session) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ctx := s.c.Request.Context()
+	if s.regenerate {
+		if !s.isNew {
+			if err := s.config.Store.Delete(ctx, s.id); err != nil {
+				return fmt.Errorf("session delete: %w", err)
+			}
+		}
+		s.id = newSessionID()
+		s.regenerate = false
+	}
+
+	if err := s.config.Store.Save(ctx, s.id, s.values, s.config.TTL); err != nil {
+		return fmt.Errorf("session save: %w", err)
+	}
+	s.isNew = false
+
+	http.SetCookie(s.c.Writer, &http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    signSessionID(s.id, s.config.Secret),
+		Path:     s.config.CookiePath,
+		Domain:   s.config.CookieDomain,
+		MaxAge:   int(s.config.TTL / time.Second),
+		Secure:   s.config.CookieSecure,
+		HttpOnly: s.config.CookieHTTPOnly,
+		SameSite: s.config.CookieSameSite,
+	})
+	return nil
+}
+
+// newSessionID 使用 crypto/rand 生成 session id
+func newSessionID() string {
+	b := make([]byte, 32)
+	if _, err := crand.Read(b); err != nil {
+		panic("session: crypto/rand failed: " + err.Error())
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// signSessionID 產生 cookie 值：未設定 secret 時為 id 本身，否則為 "id.簽章"
+func signSessionID(id string, secret []byte) string {
+	if len(secret) == 0 {
+		return id
+	}
+	return id + "." + hmacSign(id, secret)
+}
+
+// hmacSign 計算 value 的 HMAC-SHA256 簽章（base64url）
+func hmacSign(value string, secret []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// readSessionCookie 讀取並驗證 cookie 中的 session id
+func readSessionCookie(c *hypcontext.Context, config *SessionConfig) (string, bool) {
+	value, err := c.Cookie(config.CookieName)
+	if err != nil || value == "" {
+		return "", false
+	}
+	if len(config.Secret) == 0 {
+		return value, true
+	}
+
+	id, sig, ok := strings.Cut(value, ".")
+	if !ok || id == "" {
+		return "", false
+	}
+	if !hmac.Equal([]byte(sig), []byte(hmacSign(id, config.Secret))) {
+		return "", false
+	}
+	return id, true
+}
+
+// ===== 記憶體儲存 =====
+
+// memorySessionSweepInterval Save 清除過期 session 的最短間隔
+const memorySessionSweepInterval = time.Minute
+
+// MemorySessionStore 以記憶體保存 session，適用於單機與測試環境
+// 過期 session 於 Save 時每分鐘最多清除一次，不另外啟動背景 goroutine
+type MemorySessionStore struct {
+	mu        sync.RWMutex
+	sessions  map[string]memorySessionEntry
+	lastSweep time.Time
+}
+
+type memorySessionEntry struct {
+	values    map[string]interface{}
+	expiresAt time.Time
+}
+
+// NewMemorySessionStore 創建記憶體 session 儲存
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]memorySessionEntry)}
+}
+
+// Load 讀取 session，過期時一併刪除
+func (m *MemorySessionStore) Load(_ context.Context, id string) (map[string]interface{}, error) {
+	m.mu.RLock()
+	entry, ok := m.sessions[id]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, nil
+	}
+	if time.Now().After(entry.expiresAt) {
+		m.mu.Lock()
+		delete(m.sessions, id)
+		m.mu.Unlock()
+		return nil, nil
+	}
+	return copySessionValues(entry.values), nil
+}
+
+// Save 保存 session
+func (m *MemorySessionStore) Save(_ context.Context, id string, values map[string]interface{}, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= memorySessionSweepInterval {
+		m.sweepLocked(now)
+	}
+	m.sessions[id] = memorySessionEntry{
+		values:    copySessionValues(values),
+		expiresAt: now.Add(ttl),
+	}
+	return nil
+}
+
+// sweepLocked 移除所有已過期的 session
+func (m *MemorySessionStore) sweepLocked(now time.Time) {
+	for id, entry := range m.sessions {
+		if now.After(entry.expiresAt) {
+			delete(m.sessions, id)
+		}
+	}
+	m.lastSweep = now
+}
+
+// Delete 刪除 session
+func (m *MemorySessionStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.sessions, id)
+	return nil
+}
+
+func copySessionValues(values map[string]interface{}) map[string]interface{} {
+	cp := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		cp[k] = v
+	}
+	return cp
+}
+
+// ===== Redis 儲存 =====
+
+// RedisSessionStore 以 Redis 保存 session，值以 JSON 序列化
+// 注意：經 JSON 往返後數字會變為 float64
+type RedisSessionStore struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+// NewRedisSessionStore 創建 Redis session 儲存，prefix 為空時使用 "session:"
+func NewRedisSessionStore(client redis.UniversalClient, prefix string) *RedisSessionStore {
+	if prefix == "" {
+		prefix = "session:"
+	}
+	return &RedisSessionStore{client: client, prefix: prefix}
+}
+
+// Load 讀取 session
+func (r *RedisSessionStore) Load(ctx context.Context, id string) (map[string]interface{}, error) {
+	data, err := r.client.Get(ctx, r.prefix+id).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]interface{})
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("decode session: %w", err)
+	}
+	return values, nil
+}
+
+// Save 保存 session 並設定 TTL
+func (r *RedisSessionStore) Save(ctx context.Context, id string, values map[string]interface{}, ttl time.Duration) error {
+	data, err := json.Marshal(values)
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+	return r.client.Set(ctx, r.prefix+id, data, ttl).Err()
+}
+
+// Delete 刪除 session
+func (r *RedisSessionStore) Delete(ctx context.Context, id string) error {
+	return r.client.Del(ctx, r.prefix+id).Err()
+}
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func newSessionRouter(config SessionConfig) *router.Router {
+	r := router.New()
+	r.Use(Session(config))
+	r.GET("/set", func(c *context.Context) {
+		sess := c.Session()
+		sess.Set("user", c.Query("user"))
+		if c.Query("login") != "" {
+			sess.RegenerateID()
+		}
+		if err := sess.Save(); err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		c.String(http.StatusOK, sess.ID())
+	})
+	r.GET("/get", func(c *context.Context) {
+		user, _ := c.Session().Get("user").(string)
+		c.String(http.StatusOK, user)
+	})
+	return r
+}
+
+func sessionCookie(t *testing.T, w *httptest.ResponseRecorder, name string) *http.Cookie {
+	t.Helper()
+	for _, ck := range w.Result().Cookies() {
+		if ck.Name == name {
+			return ck
+		}
+	}
+	t.Fatalf("cookie %q not set", name)
+	return nil
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	r := newSessionRouter(SessionConfig{Secret: []byte("secret"), CookieHTTPOnly: true})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/set?user=alice", nil))
+	ck := sessionCookie(t, w, "hypgo_session")
+	if !ck.HttpOnly || ck.SameSite != http.SameSiteLaxMode {
+		t.Errorf("unexpected cookie flags: httponly=%v samesite=%v", ck.HttpOnly, ck.SameSite)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/get", nil)
+	req.AddCookie(ck)
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "alice" {
+		t.Errorf("Expected session value 'alice', got %q", w.Body.String())
+	}
+}
+
+func TestSessionTamperedCookie(t *testing.T) {
+	r := newSessionRouter(SessionConfig{Secret: []byte("secret")})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/set?user=alice", nil))
+	ck := sessionCookie(t, w, "hypgo_session")
+	ck.Value += "x"
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/get", nil)
+	req.AddCookie(ck)
+	r.ServeHTTP(w, req)
+	if w.Body.String() != "" {
+		t.Errorf("Tampered cookie should not load session, got %q", w.Body.String())
+	}
+}
+
+func TestSessionRegenerateID(t *testing.T) {
+	store := NewMemorySessionStore()
+	r := newSessionRouter(SessionConfig{Store: store})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/set?user=guest", nil))
+	oldCookie := sessionCookie(t, w, "hypgo_session")
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/set?user=admin&login=1", nil)
+	req.AddCookie(oldCookie)
+	r.ServeHTTP(w, req)
+	newCookie := sessionCookie(t, w, "hypgo_session")
+
+	if newCookie.Value == oldCookie.Value {
+		t.Fatal("Expected session id to change after RegenerateID")
+	}
+	if values, _ := store.Load(t.Context(), oldCookie.Value); values != nil {
+		t.Error("Old session id should be removed from store")
+	}
+	if values, _ := store.Load(t.Context(), newCookie.Value); values["user"] != "admin" {
+		t.Errorf("Expected values carried to new id, got %v", values)
+	}
+}
+
+func TestMemorySessionStoreExpiry(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := t.Context()
+
+	if err := store.Save(ctx, "id", map[string]interface{}{"k": "v"}, time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if values, _ := store.Load(ctx, "id"); values != nil {
+		t.Errorf("Expected expired session to be gone, got %v", values)
+	}
+}
+
+func TestMemorySessionStoreSweepsExpired(t *testing.T) {
+	store := NewMemorySessionStore()
+	ctx := t.Context()
+
+	store.Save(ctx, "expired", map[string]interface{}{"k": "v"}, time.Millisecond)
+	store.Save(ctx, "fresh", map[string]interface{}{"k": "v"}, time.Hour)
+	time.Sleep(5 * time.Millisecond)
+
+	// 未到清除間隔時不掃描
+	store.Save(ctx, "next", nil, time.Hour)
+	if n := len(store.sessions); n != 3 {
+		t.Fatalf("Expected no sweep within the interval, got %d sessions", n)
+	}
+
+	// 未曾被讀取的過期 session 也會在 Save 時清除
+	store.lastSweep = time.Now().Add(-memorySessionSweepInterval)
+	store.Save(ctx, "another", nil, time.Hour)
+	if _, ok := store.sessions["expired"]; ok {
+		t.Error("Expected expired session to be swept")
+	}
+	if n := len(store.sessions); n != 3 {
+		t.Errorf("Expected fresh, next and another to remain, got %d sessions", n)
+	}
+}
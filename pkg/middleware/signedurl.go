@@ -0,0 +1,37 @@
+// @chris
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/signing"
+)
+
+// ===== 簽章網址驗證 =====
+
+// SignedURL 創建簽章網址驗證中間件，缺少簽章、簽章不符或已過期時回應 403
+// 網址由 signing.SignURL 產生；錯誤訊息只區分「已過期」與「無效」，不透露比對細節
+//
+// EX：
+//
+//	r.GET("/downloads/:file", middleware.SignedURL(secret), serveDownload)
+//
+//	link, _ := signing.SignURL("https://cdn.example.com/downloads/report.pdf", nil, 15*time.Minute, secret)
+func SignedURL(secret []byte) hypcontext.HandlerFunc {
+	if len(secret) == 0 {
+		panic("middleware: SignedURL requires a secret")
+	}
+	return func(c *hypcontext.Context) {
+		if err := c.VerifySignature(secret); err != nil {
+			message := "invalid signature"
+			if errors.Is(err, signing.ErrExpired) {
+				message = "link expired"
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, map[string]string{"error": message})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,116 @@
+// @chris
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ===== 追蹤中間件 =====
+
+// TracingConfig 追蹤配置
+type TracingConfig struct {
+	// TracerProvider 留空時使用全域 provider（由 tracing.Setup 設定）
+	TracerProvider trace.TracerProvider
+	// Propagator 留空時使用全域 propagator（W3C traceparent / baggage）
+	Propagator propagation.TextMapPropagator
+	SkipPaths  []string
+	// SpanNameFormatter 自訂 span 名稱，預設為 "GET /users/:id"（未匹配路由時僅方法名）
+	SpanNameFormatter func(c *hypcontext.Context) string
+}
+
+// Tracing 創建追蹤中間件
+// 從請求標頭延續上游 trace，為每個請求建立 server span，並將 span 放入 Request.Context()，
+// 讓 handler 以 c.StdContext() 發出的 DB / HTTP 呼叫成為子 span。
+//
+// EX：
+//
+//	srv.Use(middleware.Tracing(middleware.TracingConfig{}))
+func Tracing(config TracingConfig) hypcontext.HandlerFunc {
+	skipPaths := make(map[string]bool)
+	for _, path := range config.SkipPaths {
+		skipPaths[path] = true
+	}
+
+	var tracer trace.Tracer
+	if config.TracerProvider != nil {
+		tracer = config.TracerProvider.Tracer(tracing.InstrumentationName)
+	} else {
+		tracer = tracing.Tracer()
+	}
+	if config.Propagator == nil {
+		config.Propagator = tracing.Propagator()
+	}
+	if config.SpanNameFormatter == nil {
+		config.SpanNameFormatter = defaultSpanName
+	}
+
+	return func(c *hypcontext.Context) {
+		if skipPaths[c.Request.URL.Path] {
+			c.Next()
+			return
+		}
+
+		ctx := config.Propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+		attrs := []attribute.KeyValue{
+			semconv.HTTPRequestMethodKey.String(c.Request.Method),
+			semconv.URLPath(c.Request.URL.Path),
+			semconv.ClientAddress(c.ClientIP()),
+			semconv.NetworkProtocolVersion(protocolVersion(c.Request)),
+		}
+		if route := c.FullPath(); route != "" {
+			attrs = append(attrs, semconv.HTTPRoute(route))
+		}
+		if ua := c.Request.UserAgent(); ua != "" {
+			attrs = append(attrs, semconv.UserAgentOriginal(ua))
+		}
+
+		ctx, span := tracer.Start(ctx, config.SpanNameFormatter(c),
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(attrs...),
+		)
+		defer span.End()
+
+		c.Request = c.Request.WithContext(ctx)
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Response.Status()
+		span.SetAttributes(
+			semconv.HTTPResponseStatusCode(status),
+			attribute.Int64("http.server.latency_ms", time.Since(start).Milliseconds()),
+		)
+		for _, e := range c.Errors {
+			span.RecordError(e.Err)
+		}
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	}
+}
+
+// defaultSpanName 依路由模板命名 span，避免路徑參數造成高基數
+func defaultSpanName(c *hypcontext.Context) string {
+	if route := c.FullPath(); route != "" {
+		return c.Request.Method + " " + route
+	}
+	return c.Request.Method
+}
+
+// protocolVersion 回傳 "1.1"、"2"、"3"
+func protocolVersion(r *http.Request) string {
+	if r.ProtoMajor >= 2 {
+		return strconv.Itoa(r.ProtoMajor)
+	}
+	return strconv.Itoa(r.ProtoMajor) + "." + strconv.Itoa(r.ProtoMinor)
+}
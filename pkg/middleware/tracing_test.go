@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTracingRouter(sr *tracetest.SpanRecorder, handler context.HandlerFunc) *router.Router {
+	r := router.New()
+	r.Use(Tracing(TracingConfig{
+		TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)),
+		Propagator:     propagation.TraceContext{},
+		SkipPaths:      []string{"/healthz"},
+	}))
+	r.GET("/users/:id", handler)
+	r.GET("/healthz", handler)
+	return r
+}
+
+func TestTracingSpan(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	var handlerSpan trace.SpanContext
+	r := newTracingRouter(sr, func(c *context.Context) {
+		handlerSpan = trace.SpanContextFromContext(c.StdContext())
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(w, req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected 1 span, got %d", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "GET /users/:id" {
+		t.Errorf("Expected span name 'GET /users/:id', got %q", span.Name())
+	}
+	if span.SpanKind() != trace.SpanKindServer {
+		t.Errorf("Expected server span, got %v", span.SpanKind())
+	}
+	if got := span.Parent().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected trace to continue from traceparent, got %s", got)
+	}
+	if handlerSpan.SpanID() != span.SpanContext().SpanID() {
+		t.Error("Expected request context to carry the server span")
+	}
+
+	attrs := make(map[string]string)
+	for _, kv := range span.Attributes() {
+		attrs[string(kv.Key)] = kv.Value.Emit()
+	}
+	if attrs["http.route"] != "/users/:id" || attrs["http.response.status_code"] != "200" {
+		t.Errorf("unexpected attributes: %v", attrs)
+	}
+}
+
+func TestTracingServerError(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	r := newTracingRouter(sr, func(c *context.Context) {
+		c.String(500, "boom")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/1", nil)
+	r.ServeHTTP(w, req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 || spans[0].Status().Code != codes.Error {
+		t.Errorf("Expected one span with error status, got %+v", spans)
+	}
+}
+
+func TestTracingSkipPaths(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	r := newTracingRouter(sr, func(c *context.Context) {
+		c.String(200, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/healthz", nil)
+	r.ServeHTTP(w, req)
+
+	if n := len(sr.Ended()); n != 0 {
+		t.Errorf("Expected skipped path to produce no spans, got %d", n)
+	}
+}
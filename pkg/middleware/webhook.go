@@ -0,0 +1,146 @@
+// @chris
+package middleware
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== Webhook 簽章驗證 =====
+
+// WebhookConfig Webhook 簽章驗證配置
+type WebhookConfig struct {
+	// Secret HMAC-SHA256 金鑰（必填）
+	Secret []byte
+	// Header 簽章標頭，預設 "X-Signature"
+	Header string
+	// Prefix 簽章值的前綴，例如 GitHub 的 "sha256="；Timestamped 時不使用
+	Prefix string
+	// Timestamped 使用 "t=<unix>,v1=<hex>" 格式，簽章內容為 "<t>.<body>"（Stripe 等）
+	// 可帶多個 v1（金鑰輪替期間），任一相符即通過
+	Timestamped bool
+	// Tolerance Timestamped 時允許的時間差，超過視為重放攻擊，預設 5 分鐘
+	Tolerance time.Duration
+}
+
+// GitHubWebhook GitHub webhook 的驗證配置（X-Hub-Signature-256: sha256=<hex>）
+func GitHubWebhook(secret []byte) WebhookConfig {
+	return WebhookConfig{Secret: secret, Header: "X-Hub-Signature-256", Prefix: "sha256="}
+}
+
+// StripeWebhook Stripe webhook 的驗證配置（Stripe-Signature: t=<unix>,v1=<hex>）
+func StripeWebhook(secret []byte) WebhookConfig {
+	return WebhookConfig{Secret: secret, Header: "Stripe-Signature", Timestamped: true}
+}
+
+// WebhookVerify 創建 webhook 簽章驗證中間件
+// 以 c.GetRawData 讀取原始 body（處理器仍可再次讀取或綁定），計算 HMAC-SHA256 並以常數時間比對；
+// 簽章缺少、不符或時間戳過期時回應 401
+//
+// EX：
+//
+//	r.POST("/webhooks/github", middleware.WebhookVerify(middleware.GitHubWebhook(secret)), onPush)
+//	r.POST("/webhooks/stripe", middleware.WebhookVerify(middleware.StripeWebhook(secret)), onPayment)
+func WebhookVerify(config WebhookConfig) hypcontext.HandlerFunc {
+	if len(config.Secret) == 0 {
+		panic("middleware: WebhookVerify requires a secret")
+	}
+	if config.Header == "" {
+		config.Header = "X-Signature"
+	}
+	if config.Tolerance <= 0 {
+		config.Tolerance = 5 * time.Minute
+	}
+
+	return func(c *hypcontext.Context) {
+		header := c.GetHeader(config.Header)
+		if header == "" {
+			abortWebhook(c, "missing webhook signature")
+			return
+		}
+		body, err := c.GetRawData()
+		if err != nil {
+			c.AbortWithStatus(http.StatusBadRequest)
+			return
+		}
+
+		if config.Timestamped {
+			if reason := verifyTimestampedSignature(header, body, config); reason != "" {
+				abortWebhook(c, reason)
+				return
+			}
+		} else if !webhookSignatureEqual(strings.TrimPrefix(header, config.Prefix), webhookHMAC(config.Secret, body)) {
+			abortWebhook(c, "invalid webhook signature")
+			return
+		}
+		c.Next()
+	}
+}
+
+// verifyTimestampedSignature 驗證 "t=...,v1=..." 格式，失敗時回傳原因
+func verifyTimestampedSignature(header string, body []byte, config WebhookConfig) string {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return "malformed webhook signature"
+	}
+
+	payload := make([]byte, 0, len(timestamp)+1+len(body))
+	payload = append(payload, timestamp...)
+	payload = append(payload, '.')
+	payload = append(payload, body...)
+	expected := webhookHMAC(config.Secret, payload)
+
+	matched := false
+	for _, sig := range signatures {
+		// 不提前結束，比對時間不因第幾個相符而不同
+		if webhookSignatureEqual(sig, expected) {
+			matched = true
+		}
+	}
+	if !matched {
+		return "invalid webhook signature"
+	}
+
+	// 簽章通過後才檢查時間，時間戳無法被竄改
+	age := time.Since(time.Unix(ts, 0))
+	if age > config.Tolerance || age < -config.Tolerance {
+		return "webhook timestamp outside tolerance"
+	}
+	return ""
+}
+
+func webhookHMAC(secret, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// webhookSignatureEqual 常數時間比對十六進位簽章（不分大小寫）
+func webhookSignatureEqual(got, expected string) bool {
+	return hmac.Equal([]byte(strings.ToLower(got)), []byte(expected))
+}
+
+func abortWebhook(c *hypcontext.Context, reason string) {
+	c.AbortWithStatusJSON(http.StatusUnauthorized, map[string]string{"error": reason})
+}
@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func webhookRouter(config WebhookConfig) *router.Router {
+	r := router.New()
+	r.POST("/hook", WebhookVerify(config), func(c *context.Context) {
+		// 驗證後處理器仍可讀取原始 body
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, string(body))
+	})
+	return r
+}
+
+func postWebhook(r http.Handler, header, value, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/hook", strings.NewReader(body))
+	if value != "" {
+		req.Header.Set(header, value)
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestWebhookVerifyGitHub(t *testing.T) {
+	secret := []byte("gh-secret")
+	r := webhookRouter(GitHubWebhook(secret))
+	body := `{"action":"opened"}`
+	valid := "sha256=" + webhookHMAC(secret, []byte(body))
+
+	if w := postWebhook(r, "X-Hub-Signature-256", valid, body); w.Code != http.StatusOK || w.Body.String() != body {
+		t.Errorf("Expected valid signature accepted with body intact, got %d %q", w.Code, w.Body.String())
+	}
+	if w := postWebhook(r, "X-Hub-Signature-256", valid, `{"action":"closed"}`); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected tampered body rejected, got %d", w.Code)
+	}
+	if w := postWebhook(r, "X-Hub-Signature-256", "sha256="+webhookHMAC([]byte("wrong"), []byte(body)), body); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected wrong secret rejected, got %d", w.Code)
+	}
+	if w := postWebhook(r, "X-Hub-Signature-256", "", body); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected missing signature rejected, got %d", w.Code)
+	}
+}
+
+func TestWebhookVerifyTimestamped(t *testing.T) {
+	secret := []byte("whsec")
+	r := webhookRouter(StripeWebhook(secret))
+	body := `{"type":"payment_intent.succeeded"}`
+	signed := func(ts time.Time, key []byte) string {
+		t := strconv.FormatInt(ts.Unix(), 10)
+		return "t=" + t + ",v1=" + webhookHMAC(key, []byte(t+"."+body))
+	}
+
+	if w := postWebhook(r, "Stripe-Signature", signed(time.Now(), secret), body); w.Code != http.StatusOK {
+		t.Errorf("Expected valid signature accepted, got %d %s", w.Code, w.Body.String())
+	}
+
+	// 金鑰輪替期間帶多個 v1，任一相符即可
+	rotating := signed(time.Now(), []byte("old")) + ",v1=" + strings.SplitN(signed(time.Now(), secret), "v1=", 2)[1]
+	if w := postWebhook(r, "Stripe-Signature", rotating, body); w.Code != http.StatusOK {
+		t.Errorf("Expected any matching v1 accepted, got %d %s", w.Code, w.Body.String())
+	}
+
+	cases := map[string]string{
+		"stale":     signed(time.Now().Add(-10*time.Minute), secret),
+		"future":    signed(time.Now().Add(10*time.Minute), secret),
+		"invalid":   signed(time.Now(), []byte("other")),
+		"malformed": "v1=" + webhookHMAC(secret, []byte(body)),
+		// 把舊簽章的時間戳改成現在：簽章涵蓋時間戳，必須失敗
+		"replayed": strings.Replace(signed(time.Now().Add(-time.Hour), secret),
+			"t="+strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10),
+			"t="+strconv.FormatInt(time.Now().Unix(), 10), 1),
+	}
+	for name, header := range cases {
+		if w := postWebhook(r, "Stripe-Signature", header, body); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected 401, got %d", name, w.Code)
+		}
+	}
+}
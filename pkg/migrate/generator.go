@@ -31,8 +31,9 @@ func GenerateSQL(changes []Change, dialect string) (up, down string) {
 }
 
 // MigrationFiles 回傳建議的檔案名稱
+// 版本為不含分隔的時間戳記，讓 Load 能以檔名前綴解析出唯一版本
 func MigrationFiles(prefix string) (upFile, downFile string) {
-	ts := time.Now().Format("20060102_150405")
+	ts := time.Now().Format("20060102150405")
 	return fmt.Sprintf("%s%s_auto.up.sql", prefix, ts),
 		fmt.Sprintf("%s%s_auto.down.sql", prefix, ts)
 }
@@ -0,0 +1,406 @@
+// @chris
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ===== Migration 執行 =====
+
+// Dialect 執行 migration 的資料庫方言
+type Dialect string
+
+const (
+	DialectMySQL    Dialect = "mysql"
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// DialectFor 依設定檔的 database.driver 取得方言
+func DialectFor(driver string) (Dialect, error) {
+	switch strings.ToLower(driver) {
+	case "mysql", "tidb":
+		return DialectMySQL, nil
+	case "postgres", "postgresql", "pgx":
+		return DialectPostgres, nil
+	case "sqlite", "sqlite3":
+		return DialectSQLite, nil
+	default:
+		return "", fmt.Errorf("migrate: unsupported driver %q (mysql, postgres or sqlite)", driver)
+	}
+}
+
+// DefaultTable 記錄目前版本的資料表，結構與 golang-migrate 相同，可直接接手既有資料庫
+const DefaultTable = "schema_migrations"
+
+// NilVersion 尚未套用任何 migration
+const NilVersion int64 = -1
+
+// mysqlLockTimeout 等待其他實例釋放 migration 鎖的上限（秒）
+const mysqlLockTimeout = 60
+
+// Migration 一組 up / down SQL，檔名格式為 {version}_{name}.up.sql 與 {version}_{name}.down.sql
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string // 空字串表示不可回滾
+}
+
+// DirtyError 上次執行在 migration 中途失敗，需人工修正資料庫後以 Force 設定版本
+type DirtyError struct {
+	Version int64
+}
+
+func (e *DirtyError) Error() string {
+	return fmt.Sprintf("migrate: database is dirty at version %d, fix it manually and run force", e.Version)
+}
+
+var (
+	migrationFilePattern = regexp.MustCompile(`^(\d+)(?:_(.*))?\.(up|down)\.sql$`)
+	migrationNamePattern = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+)
+
+// LoadDir 讀取目錄中的 migration 檔案
+func LoadDir(dir string) ([]Migration, error) {
+	return Load(os.DirFS(dir))
+}
+
+// Load 讀取 fsys 根目錄中的 migration 檔案並依版本排序；不符合命名格式的檔案略過
+// 搭配 embed.FS 可將 migration 編進執行檔，由伺服器啟動時執行
+//
+// EX：
+//
+//	//go:embed migrations/*.sql
+//	var migrationFS embed.FS
+//
+//	sub, _ := fs.Sub(migrationFS, "migrations")
+//	migrations, err := migrate.Load(sub)
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	hasUp := make(map[int64]bool)
+	for _, entry := range entries {
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migrate: invalid version in %s: %w", entry.Name(), err)
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+
+		mig, ok := byVersion[version]
+		if !ok {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("migrate: version %d used by both %q and %q", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up, hasUp[version] = string(data), true
+		} else {
+			mig.Down = string(data)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for version, mig := range byVersion {
+		if !hasUp[version] {
+			return nil, fmt.Errorf("migrate: version %d has a down file but no up file", version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Migrator 依版本套用 / 回滾 migration，目前版本記錄在 schema_migrations
+// 每個 migration 檔案以單次 Exec 執行：MySQL 的 DSN 需加上 multiStatements=true
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+	table      string
+	logf       func(format string, args ...interface{})
+}
+
+// Option Migrator 選項
+type Option func(*Migrator)
+
+// WithTable 改用其他版本記錄表
+func WithTable(table string) Option {
+	return func(m *Migrator) {
+		m.table = table
+	}
+}
+
+// WithLogger 設定每個 migration 執行時的輸出
+func WithLogger(logf func(format string, args ...interface{})) Option {
+	return func(m *Migrator) {
+		m.logf = logf
+	}
+}
+
+// New 建立 Migrator，migrations 通常來自 Load / LoadDir
+// 資料庫驅動由呼叫端匯入（如 go-sql-driver/mysql、lib/pq 或任一 SQLite 驅動）
+//
+// EX：
+//
+//	migrations, _ := migrate.LoadDir("migrations")
+//	m := migrate.New(db, migrate.DialectPostgres, migrations)
+//	n, err := m.Up(ctx)
+func New(db *sql.DB, dialect Dialect, migrations []Migration, opts ...Option) *Migrator {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+
+	m := &Migrator{
+		db:         db,
+		dialect:    dialect,
+		migrations: sorted,
+		table:      DefaultTable,
+		logf:       func(string, ...interface{}) {},
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Up 依序套用所有尚未執行的 migration，回傳套用數量
+func (m *Migrator) Up(ctx context.Context) (applied int, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.currentClean(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			if mig.Version <= current {
+				continue
+			}
+			m.logf("Applying %d_%s", mig.Version, mig.Name)
+			if err := m.step(ctx, conn, mig, mig.Up, mig.Version); err != nil {
+				return fmt.Errorf("migrate: %d_%s up: %w", mig.Version, mig.Name, err)
+			}
+			applied++
+		}
+		return nil
+	})
+	return applied, err
+}
+
+// Down 回滾最近 n 個 migration，回傳回滾數量
+func (m *Migrator) Down(ctx context.Context, n int) (reverted int, err error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("migrate: down step count must be positive, got %d", n)
+	}
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		current, err := m.currentClean(ctx, conn)
+		if err != nil || current == NilVersion {
+			return err
+		}
+
+		i := sort.Search(len(m.migrations), func(i int) bool { return m.migrations[i].Version >= current })
+		if i == len(m.migrations) || m.migrations[i].Version != current {
+			return fmt.Errorf("migrate: current version %d has no migration file", current)
+		}
+		for ; i >= 0 && reverted < n; i-- {
+			mig := m.migrations[i]
+			if mig.Down == "" {
+				return fmt.Errorf("migrate: %d_%s has no down migration", mig.Version, mig.Name)
+			}
+			target := NilVersion
+			if i > 0 {
+				target = m.migrations[i-1].Version
+			}
+			m.logf("Reverting %d_%s", mig.Version, mig.Name)
+			if err := m.step(ctx, conn, mig, mig.Down, target); err != nil {
+				return fmt.Errorf("migrate: %d_%s down: %w", mig.Version, mig.Name, err)
+			}
+			reverted++
+		}
+		return nil
+	})
+	return reverted, err
+}
+
+// Version 回傳目前版本與是否處於 dirty 狀態；未執行過任何 migration 時為 NilVersion
+func (m *Migrator) Version(ctx context.Context) (version int64, dirty bool, err error) {
+	err = m.withLock(ctx, func(conn *sql.Conn) error {
+		version, dirty, err = m.readVersion(ctx, conn)
+		return err
+	})
+	return version, dirty, err
+}
+
+// Force 直接設定版本並清除 dirty，不執行任何 SQL；用於手動修復失敗的 migration 後
+func (m *Migrator) Force(ctx context.Context, version int64) error {
+	if version < NilVersion {
+		return fmt.Errorf("migrate: invalid version %d", version)
+	}
+	return m.withLock(ctx, func(conn *sql.Conn) error {
+		return m.setVersion(ctx, conn, version, false)
+	})
+}
+
+// step 先標記 dirty 再執行 SQL，成功後寫入目標版本；失敗時保留 dirty 讓下次執行拒絕繼續
+func (m *Migrator) step(ctx context.Context, conn *sql.Conn, mig Migration, query string, target int64) error {
+	if err := m.setVersion(ctx, conn, mig.Version, true); err != nil {
+		return err
+	}
+	if strings.TrimSpace(query) != "" {
+		if _, err := conn.ExecContext(ctx, query); err != nil {
+			return err
+		}
+	}
+	return m.setVersion(ctx, conn, target, false)
+}
+
+func (m *Migrator) currentClean(ctx context.Context, conn *sql.Conn) (int64, error) {
+	version, dirty, err := m.readVersion(ctx, conn)
+	if err == nil && dirty {
+		err = &DirtyError{Version: version}
+	}
+	return version, err
+}
+
+func (m *Migrator) readVersion(ctx context.Context, q queryer) (version int64, dirty bool, err error) {
+	err = q.QueryRowContext(ctx, "SELECT version, dirty FROM "+m.table+" LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return NilVersion, false, nil
+	}
+	return version, dirty, err
+}
+
+// setVersion 版本表只保留一列；NilVersion 時清空
+func (m *Migrator) setVersion(ctx context.Context, conn *sql.Conn, version int64, dirty bool) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.table); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if version != NilVersion {
+		insert := fmt.Sprintf("INSERT INTO %s (version, dirty) VALUES (%s, %s)", m.table, m.dialect.placeholder(1), m.dialect.placeholder(2))
+		if _, err := tx.ExecContext(ctx, insert, version, dirty); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// placeholder 第 n 個參數的佔位符
+func (d Dialect) placeholder(n int) string {
+	if d == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// withLock 取得 migration 鎖並確保版本表存在
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *sql.Conn) error) error {
+	create := "CREATE TABLE IF NOT EXISTS " + m.table + " (version BIGINT NOT NULL PRIMARY KEY, dirty BOOLEAN NOT NULL)"
+	return withTableLock(ctx, m.db, m.dialect, m.table, create, fn)
+}
+
+// withTableLock 以同一條連線取得資料庫層級的鎖（以記錄表名稱區分）並建立記錄表，
+// 避免多個實例同時啟動時重複執行；SQLite 為單一檔案且寫入互斥，不另外加鎖
+func withTableLock(ctx context.Context, db *sql.DB, dialect Dialect, table, create string, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("migrate: %w", err)
+	}
+	defer conn.Close()
+
+	h := fnv.New64a()
+	h.Write([]byte(table))
+	lockKey := int64(h.Sum64() >> 1)
+	lockName := "hypgo_migrate_" + table
+
+	switch dialect {
+	case DialectPostgres:
+		if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", lockKey); err != nil {
+			return fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", lockKey)
+
+	case DialectMySQL:
+		var ok sql.NullInt64
+		if err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", lockName, mysqlLockTimeout).Scan(&ok); err != nil {
+			return fmt.Errorf("migrate: acquire lock: %w", err)
+		}
+		if ok.Int64 != 1 {
+			return fmt.Errorf("migrate: timed out after %ds waiting for lock %s", mysqlLockTimeout, lockName)
+		}
+		defer conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", lockName)
+
+	case DialectSQLite:
+	default:
+		return fmt.Errorf("migrate: unsupported dialect %q", dialect)
+	}
+
+	if _, err := conn.ExecContext(ctx, create); err != nil {
+		return fmt.Errorf("migrate: create %s: %w", table, err)
+	}
+	return fn(conn)
+}
+
+// Create 在 dir 建立下一個版本的空白 up / down 檔案，版本號沿用既有檔案的位數（預設 3 位）
+func Create(dir, name string) (upFile, downFile string, err error) {
+	if !migrationNamePattern.MatchString(name) {
+		return "", "", fmt.Errorf("migrate: invalid migration name %q (letters, digits and _ only)", name)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+
+	var next int64 = 1
+	width := 3
+	for _, entry := range entries {
+		m := migrationFilePattern.FindStringSubmatch(entry.Name())
+		if m == nil {
+			continue
+		}
+		if v, err := strconv.ParseInt(m[1], 10, 64); err == nil && v >= next {
+			next, width = v+1, len(m[1])
+		}
+	}
+
+	base := filepath.Join(dir, fmt.Sprintf("%0*d_%s", width, next, name))
+	upFile, downFile = base+".up.sql", base+".down.sql"
+	header := fmt.Sprintf("-- %s: %s\n", name, time.Now().Format("2006-01-02 15:04:05"))
+	if err := os.WriteFile(upFile, []byte(header), 0644); err != nil {
+		return "", "", err
+	}
+	if err := os.WriteFile(downFile, []byte(header), 0644); err != nil {
+		return "", "", err
+	}
+	return upFile, downFile, nil
+}
@@ -0,0 +1,346 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+)
+
+// --- 測試用 database/sql 驅動：記錄執行的 SQL，以記憶體模擬 schema_migrations ---
+
+type fakeStore struct {
+	mu       sync.Mutex
+	hasTable bool
+	hasRow   bool
+	version  int64
+	dirty    bool
+	executed []string
+	failOn   string
+	seeds    []string // schema_seeds 中的名稱
+}
+
+var (
+	fakeStoresMu sync.Mutex
+	fakeStores   = map[string]*fakeStore{}
+)
+
+func init() {
+	sql.Register("migratefake", fakeDriver{})
+}
+
+// openFake 每個測試使用獨立的 DSN 與狀態
+func openFake(t *testing.T) (*sql.DB, *fakeStore) {
+	t.Helper()
+	store := &fakeStore{}
+	fakeStoresMu.Lock()
+	fakeStores[t.Name()] = store
+	fakeStoresMu.Unlock()
+
+	db, err := sql.Open("migratefake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, store
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(dsn string) (driver.Conn, error) {
+	fakeStoresMu.Lock()
+	defer fakeStoresMu.Unlock()
+	return &fakeConn{store: fakeStores[dsn]}, nil
+}
+
+type fakeConn struct{ store *fakeStore }
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return fakeTx{}, nil }
+
+func (c *fakeConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failOn != "" && strings.Contains(query, s.failOn) {
+		return nil, errors.New("syntax error")
+	}
+	s.executed = append(s.executed, query)
+	switch {
+	case strings.HasPrefix(query, "CREATE TABLE IF NOT EXISTS schema_migrations"):
+		s.hasTable = true
+	case strings.HasPrefix(query, "DELETE FROM schema_migrations"):
+		s.hasRow = false
+	case strings.HasPrefix(query, "INSERT INTO schema_migrations"):
+		s.hasRow, s.version, s.dirty = true, args[0].Value.(int64), args[1].Value.(bool)
+	case strings.HasPrefix(query, "INSERT INTO schema_seeds"):
+		s.seeds = append(s.seeds, args[0].Value.(string))
+	}
+	return driver.RowsAffected(0), nil
+}
+
+func (c *fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	s := c.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.executed = append(s.executed, query)
+	switch {
+	case strings.HasPrefix(query, "SELECT version, dirty"):
+		rows := &fakeRows{cols: []string{"version", "dirty"}}
+		if s.hasRow {
+			rows.data = [][]driver.Value{{s.version, s.dirty}}
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT name FROM schema_seeds"):
+		rows := &fakeRows{cols: []string{"name"}}
+		for _, name := range s.seeds {
+			rows.data = append(rows.data, []driver.Value{name})
+		}
+		return rows, nil
+	case strings.HasPrefix(query, "SELECT COUNT(*) FROM sqlite_master"):
+		n := int64(0)
+		if s.hasTable {
+			n = 1
+		}
+		return &fakeRows{cols: []string{"n"}, data: [][]driver.Value{{n}}}, nil
+	case strings.HasPrefix(query, "SELECT GET_LOCK"):
+		return &fakeRows{cols: []string{"ok"}, data: [][]driver.Value{{int64(1)}}}, nil
+	}
+	return nil, errors.New("unexpected query: " + query)
+}
+
+type fakeTx struct{}
+
+func (fakeTx) Commit() error   { return nil }
+func (fakeTx) Rollback() error { return nil }
+
+type fakeRows struct {
+	cols []string
+	data [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.cols }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.data) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.data[0])
+	r.data = r.data[1:]
+	return nil
+}
+
+// migrationSQL 回傳 store 中執行過的 migration SQL（略過版本表操作與鎖）
+func (s *fakeStore) migrationSQL() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var out []string
+	for _, q := range s.executed {
+		if !strings.Contains(q, "schema_") && !strings.HasPrefix(q, "SELECT") {
+			out = append(out, q)
+		}
+	}
+	return out
+}
+
+// find 回傳第一個符合前綴的語句
+func (s *fakeStore) find(prefix string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, q := range s.executed {
+		if strings.HasPrefix(q, prefix) {
+			return q
+		}
+	}
+	return ""
+}
+
+var testMigrationFS = fstest.MapFS{
+	"001_create_users.up.sql":   {Data: []byte("CREATE TABLE users;")},
+	"001_create_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	"002_create_roles.up.sql":   {Data: []byte("CREATE TABLE roles;")},
+	"002_create_roles.down.sql": {Data: []byte("DROP TABLE roles;")},
+	"003_seed.up.sql":           {Data: []byte("INSERT INTO roles;")},
+	"README.md":                 {Data: []byte("ignored")},
+}
+
+// --- Load ---
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := Load(testMigrationFS)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(migrations) != 3 {
+		t.Fatalf("Expected 3 migrations, got %d", len(migrations))
+	}
+	first := migrations[0]
+	if first.Version != 1 || first.Name != "create_users" || first.Up != "CREATE TABLE users;" || first.Down != "DROP TABLE users;" {
+		t.Errorf("Unexpected first migration: %+v", first)
+	}
+	if migrations[2].Version != 3 || migrations[2].Down != "" {
+		t.Errorf("Expected 003 without down, got %+v", migrations[2])
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	cases := map[string]fstest.MapFS{
+		"down without up": {"001_a.down.sql": {}},
+		"duplicate version": {
+			"001_a.up.sql": {},
+			"001_b.up.sql": {},
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+// --- Migrator ---
+
+func TestMigratorUpDown(t *testing.T) {
+	db, store := openFake(t)
+	migrations, _ := Load(testMigrationFS)
+	m := New(db, DialectSQLite, migrations)
+	ctx := context.Background()
+
+	if v, dirty, err := m.Version(ctx); err != nil || v != NilVersion || dirty {
+		t.Fatalf("Expected NilVersion on fresh database, got %d %v %v", v, dirty, err)
+	}
+
+	n, err := m.Up(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("Up = %d, %v", n, err)
+	}
+	if v, _, _ := m.Version(ctx); v != 3 {
+		t.Errorf("Expected version 3, got %d", v)
+	}
+	if n, _ := m.Up(ctx); n != 0 {
+		t.Errorf("Expected second Up to be a no-op, applied %d", n)
+	}
+
+	// 003 沒有 down 檔案，無法回滾
+	if _, err := m.Down(ctx, 1); err == nil || !strings.Contains(err.Error(), "no down migration") {
+		t.Errorf("Expected missing down error, got %v", err)
+	}
+
+	if err := m.Force(ctx, 2); err != nil {
+		t.Fatalf("Force: %v", err)
+	}
+	n, err = m.Down(ctx, 5)
+	if err != nil || n != 2 {
+		t.Fatalf("Down = %d, %v", n, err)
+	}
+	if v, _, _ := m.Version(ctx); v != NilVersion {
+		t.Errorf("Expected NilVersion after full rollback, got %d", v)
+	}
+
+	want := []string{"CREATE TABLE users;", "CREATE TABLE roles;", "INSERT INTO roles;", "DROP TABLE roles;", "DROP TABLE users;"}
+	if got := store.migrationSQL(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("executed = %v, want %v", got, want)
+	}
+
+	if _, err := m.Down(ctx, 0); err == nil {
+		t.Error("Expected error for non-positive step count")
+	}
+}
+
+func TestMigratorDirty(t *testing.T) {
+	db, store := openFake(t)
+	store.failOn = "roles"
+	migrations, _ := Load(testMigrationFS)
+	m := New(db, DialectSQLite, migrations)
+	ctx := context.Background()
+
+	n, err := m.Up(ctx)
+	if err == nil || n != 1 || !strings.Contains(err.Error(), "2_create_roles up") {
+		t.Fatalf("Expected failure at 002 after applying 1, got %d %v", n, err)
+	}
+	if v, dirty, _ := m.Version(ctx); v != 2 || !dirty {
+		t.Errorf("Expected dirty version 2, got %d %v", v, dirty)
+	}
+
+	var dirtyErr *DirtyError
+	if _, err := m.Up(ctx); !errors.As(err, &dirtyErr) || dirtyErr.Version != 2 {
+		t.Errorf("Expected DirtyError, got %v", err)
+	}
+
+	// 手動修復後 Force 回 1 再重新執行
+	store.failOn = ""
+	if err := m.Force(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := m.Up(ctx); err != nil || n != 2 {
+		t.Errorf("Up after force = %d, %v", n, err)
+	}
+}
+
+func TestMigratorLocking(t *testing.T) {
+	for _, tc := range []struct {
+		dialect Dialect
+		lock    string
+		insert  string
+	}{
+		{DialectPostgres, "SELECT pg_advisory_lock($1)", "VALUES ($1, $2)"},
+		{DialectMySQL, "SELECT GET_LOCK(?, ?)", "VALUES (?, ?)"},
+	} {
+		t.Run(string(tc.dialect), func(t *testing.T) {
+			db, store := openFake(t)
+			m := New(db, tc.dialect, []Migration{{Version: 1, Up: "CREATE TABLE t;"}})
+			if _, err := m.Up(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if store.executed[0] != tc.lock {
+				t.Errorf("Expected lock %q first, got %q", tc.lock, store.executed[0])
+			}
+			if insert := store.find("INSERT INTO schema_migrations"); !strings.HasSuffix(insert, tc.insert) {
+				t.Errorf("Unexpected version insert %q", insert)
+			}
+		})
+	}
+}
+
+func TestDialectFor(t *testing.T) {
+	for driver, want := range map[string]Dialect{
+		"mysql": DialectMySQL, "tidb": DialectMySQL, "postgres": DialectPostgres,
+		"postgresql": DialectPostgres, "sqlite3": DialectSQLite,
+	} {
+		if got, err := DialectFor(driver); err != nil || got != want {
+			t.Errorf("DialectFor(%q) = %q, %v", driver, got, err)
+		}
+	}
+	if _, err := DialectFor("redis"); err == nil {
+		t.Error("Expected error for redis")
+	}
+}
+
+func TestCreateMigration(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "0007_existing.up.sql"), nil, 0644)
+
+	up, down, err := Create(dir, "add_index")
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if filepath.Base(up) != "0008_add_index.up.sql" || filepath.Base(down) != "0008_add_index.down.sql" {
+		t.Errorf("Unexpected files %s %s", up, down)
+	}
+	if _, _, err := Create(dir, "bad name"); err == nil {
+		t.Error("Expected error for invalid name")
+	}
+
+	up, _, _ = Create(t.TempDir(), "init")
+	if filepath.Base(up) != "001_init.up.sql" {
+		t.Errorf("Expected default 3-digit version, got %s", up)
+	}
+}
@@ -0,0 +1,225 @@
+// @chris
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ===== Seed 資料 =====
+
+// DefaultSeedTable 記錄已執行 seed 的資料表
+const DefaultSeedTable = "schema_seeds"
+
+// SeedFunc 在交易中寫入 seed 資料；回傳錯誤時整筆交易回滾，下次仍會重新執行
+// bun 使用者可以 db.NewInsert().Conn(tx) 在同一交易中操作
+type SeedFunc func(ctx context.Context, tx *sql.Tx) error
+
+// SeedEntry 一個具名的 seed，Envs 為空表示所有環境都執行
+type SeedEntry struct {
+	Name string
+	Envs []string
+	Run  SeedFunc
+}
+
+// runsIn 是否在 env 執行
+func (s SeedEntry) runsIn(env string) bool {
+	if len(s.Envs) == 0 {
+		return true
+	}
+	for _, e := range s.Envs {
+		if strings.EqualFold(e, env) {
+			return true
+		}
+	}
+	return false
+}
+
+// SeedRegistry 儲存以 Go 定義的 seed
+type SeedRegistry struct {
+	seeds []SeedEntry
+}
+
+// globalSeeds 全域 seed 註冊表
+var globalSeeds = &SeedRegistry{}
+
+// GlobalSeeds 返回全域 seed 註冊表，Seed 未指定 Registry 時使用
+func GlobalSeeds() *SeedRegistry {
+	return globalSeeds
+}
+
+// NewSeedRegistry 建立新的 SeedRegistry
+func NewSeedRegistry() *SeedRegistry {
+	return &SeedRegistry{}
+}
+
+// Register 註冊 seed；envs 限定執行環境，不傳表示所有環境
+// 名稱即執行順序與記錄鍵，建議以數字開頭（與 seeds/ 目錄的 SQL 檔一起依名稱排序）
+//
+// 使用範例：
+//
+//	migrate.GlobalSeeds().Register("001_default_roles", func(ctx context.Context, tx *sql.Tx) error {
+//	    _, err := tx.ExecContext(ctx, `INSERT INTO roles (name) VALUES ('admin'), ('user')`)
+//	    return err
+//	})
+//	migrate.GlobalSeeds().Register("100_demo_users", seedDemoUsers, "local", "dev")
+func (r *SeedRegistry) Register(name string, fn SeedFunc, envs ...string) {
+	r.seeds = append(r.seeds, SeedEntry{Name: name, Envs: envs, Run: fn})
+}
+
+// Seeds 返回所有已註冊的 seed
+func (r *SeedRegistry) Seeds() []SeedEntry {
+	return r.seeds
+}
+
+// seedFilePattern seeds/ 目錄中的 SQL 檔：{name}.sql 或限定環境的 {name}.{env}.sql
+var seedFilePattern = regexp.MustCompile(`^([^.]+)(?:\.([A-Za-z0-9_-]+))?\.sql$`)
+
+// LoadSeeds 讀取 fsys 根目錄中的 SQL seed；檔名為 001_roles.sql（所有環境）或 002_demo.dev.sql（僅 dev）
+// 同名的 SQL 檔限定多個環境時各自成為獨立的 seed（如 002_demo.dev.sql 與 002_demo.prod.sql）
+func LoadSeeds(fsys fs.FS) ([]SeedEntry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("migrate: %w", err)
+	}
+
+	var seeds []SeedEntry
+	for _, entry := range entries {
+		m := seedFilePattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || m == nil {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("migrate: %w", err)
+		}
+
+		seed := SeedEntry{Name: m[1], Run: execSQL(string(data))}
+		if m[2] != "" {
+			seed.Name += "." + m[2]
+			seed.Envs = []string{m[2]}
+		}
+		seeds = append(seeds, seed)
+	}
+	return seeds, nil
+}
+
+func execSQL(query string) SeedFunc {
+	return func(ctx context.Context, tx *sql.Tx) error {
+		if strings.TrimSpace(query) == "" {
+			return nil
+		}
+		_, err := tx.ExecContext(ctx, query)
+		return err
+	}
+}
+
+// SeedOptions Seed 的執行選項
+type SeedOptions struct {
+	Env      string        // 目前環境，只執行未限定環境或限定包含此環境的 seed
+	Dir      string        // SQL seed 目錄，空字串表示不讀取；不存在時略過
+	Registry *SeedRegistry // Go seed，nil 時使用 GlobalSeeds()
+	Table    string        // 記錄表，預設 schema_seeds
+	Logf     func(format string, args ...interface{})
+}
+
+// Seed 依名稱順序執行尚未執行過的 seed，並記錄在 schema_seeds，重複執行是安全的
+// 每個 seed 與其記錄在同一交易中提交；回傳本次執行的 seed 名稱
+//
+// EX：
+//
+//	ran, err := migrate.Seed(ctx, db, migrate.DialectPostgres, migrate.SeedOptions{
+//	    Env: "dev",
+//	    Dir: "seeds",
+//	})
+func Seed(ctx context.Context, db *sql.DB, dialect Dialect, opts SeedOptions) (ran []string, err error) {
+	if opts.Registry == nil {
+		opts.Registry = GlobalSeeds()
+	}
+	if opts.Table == "" {
+		opts.Table = DefaultSeedTable
+	}
+	if opts.Logf == nil {
+		opts.Logf = func(string, ...interface{}) {}
+	}
+
+	seeds := append([]SeedEntry(nil), opts.Registry.Seeds()...)
+	if opts.Dir != "" {
+		if _, statErr := os.Stat(opts.Dir); statErr == nil {
+			fileSeeds, err := LoadSeeds(os.DirFS(opts.Dir))
+			if err != nil {
+				return nil, err
+			}
+			seeds = append(seeds, fileSeeds...)
+		}
+	}
+	sort.SliceStable(seeds, func(i, j int) bool { return seeds[i].Name < seeds[j].Name })
+	for i := 1; i < len(seeds); i++ {
+		if seeds[i].Name == seeds[i-1].Name {
+			return nil, fmt.Errorf("migrate: duplicate seed name %q", seeds[i].Name)
+		}
+	}
+
+	create := "CREATE TABLE IF NOT EXISTS " + opts.Table +
+		" (name VARCHAR(255) NOT NULL PRIMARY KEY, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP)"
+	err = withTableLock(ctx, db, dialect, opts.Table, create, func(conn *sql.Conn) error {
+		applied, err := appliedSeeds(ctx, conn, opts.Table)
+		if err != nil {
+			return err
+		}
+
+		insert := fmt.Sprintf("INSERT INTO %s (name) VALUES (%s)", opts.Table, dialect.placeholder(1))
+		for _, seed := range seeds {
+			if applied[seed.Name] || !seed.runsIn(opts.Env) {
+				continue
+			}
+			opts.Logf("Seeding %s", seed.Name)
+			if err := runSeed(ctx, conn, seed, insert); err != nil {
+				return fmt.Errorf("migrate: seed %s: %w", seed.Name, err)
+			}
+			ran = append(ran, seed.Name)
+		}
+		return nil
+	})
+	return ran, err
+}
+
+func appliedSeeds(ctx context.Context, conn *sql.Conn, table string) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, "SELECT name FROM "+table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		applied[name] = true
+	}
+	return applied, rows.Err()
+}
+
+func runSeed(ctx context.Context, conn *sql.Conn, seed SeedEntry, insert string) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := seed.Run(ctx, tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, insert, seed.Name); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
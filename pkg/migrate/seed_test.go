@@ -0,0 +1,97 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadSeeds(t *testing.T) {
+	seeds, err := LoadSeeds(fstest.MapFS{
+		"001_roles.sql":      {Data: []byte("INSERT INTO roles;")},
+		"002_demo.dev.sql":   {Data: []byte("INSERT INTO users;")},
+		"002_demo.local.sql": {Data: []byte("INSERT INTO users;")},
+		"notes.txt":          {},
+	})
+	if err != nil {
+		t.Fatalf("LoadSeeds: %v", err)
+	}
+	var names []string
+	for _, s := range seeds {
+		names = append(names, s.Name)
+	}
+	if want := []string{"001_roles", "002_demo.dev", "002_demo.local"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("names = %v, want %v", names, want)
+	}
+	if len(seeds[0].Envs) != 0 || !reflect.DeepEqual(seeds[1].Envs, []string{"dev"}) {
+		t.Errorf("Unexpected env gating: %+v / %+v", seeds[0].Envs, seeds[1].Envs)
+	}
+}
+
+func TestSeedRunsOnceAndGatesByEnv(t *testing.T) {
+	db, store := openFake(t)
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "002_roles.sql"), []byte("INSERT INTO roles;"), 0644)
+	os.WriteFile(filepath.Join(dir, "010_demo.dev.sql"), []byte("INSERT INTO demo;"), 0644)
+
+	calls := 0
+	registry := NewSeedRegistry()
+	registry.Register("001_settings", func(ctx context.Context, tx *sql.Tx) error {
+		calls++
+		_, err := tx.ExecContext(ctx, "INSERT INTO settings;")
+		return err
+	})
+	registry.Register("020_fixtures", func(context.Context, *sql.Tx) error { return nil }, "test")
+
+	ctx := context.Background()
+	opts := SeedOptions{Env: "prod", Dir: dir, Registry: registry}
+	ran, err := Seed(ctx, db, DialectSQLite, opts)
+	if err != nil {
+		t.Fatalf("Seed: %v", err)
+	}
+	if want := []string{"001_settings", "002_roles"}; !reflect.DeepEqual(ran, want) {
+		t.Errorf("prod ran %v, want %v", ran, want)
+	}
+
+	// 重複執行只補上新環境的 seed
+	opts.Env = "dev"
+	ran, err = Seed(ctx, db, DialectSQLite, opts)
+	if err != nil || !reflect.DeepEqual(ran, []string{"010_demo.dev"}) {
+		t.Errorf("dev ran %v, %v", ran, err)
+	}
+	if ran, _ := Seed(ctx, db, DialectSQLite, opts); len(ran) != 0 || calls != 1 {
+		t.Errorf("Expected nothing to re-run, ran %v (calls %d)", ran, calls)
+	}
+
+	want := []string{"INSERT INTO settings;", "INSERT INTO roles;", "INSERT INTO demo;"}
+	if got := store.migrationSQL(); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("executed = %v, want %v", got, want)
+	}
+}
+
+func TestSeedFailureIsNotRecorded(t *testing.T) {
+	db, store := openFake(t)
+	fail := errors.New("boom")
+	registry := NewSeedRegistry()
+	registry.Register("001_ok", func(context.Context, *sql.Tx) error { return nil })
+	registry.Register("002_bad", func(context.Context, *sql.Tx) error { return fail })
+
+	ran, err := Seed(context.Background(), db, DialectSQLite, SeedOptions{Registry: registry})
+	if !errors.Is(err, fail) || !reflect.DeepEqual(ran, []string{"001_ok"}) {
+		t.Fatalf("Seed = %v, %v", ran, err)
+	}
+	if !reflect.DeepEqual(store.seeds, []string{"001_ok"}) {
+		t.Errorf("Expected only successful seed recorded, got %v", store.seeds)
+	}
+
+	registry.Register("001_ok", func(context.Context, *sql.Tx) error { return nil })
+	if _, err := Seed(context.Background(), db, DialectSQLite, SeedOptions{Registry: registry}); err == nil {
+		t.Error("Expected duplicate seed name error")
+	}
+}
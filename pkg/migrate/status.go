@@ -0,0 +1,114 @@
+// @chris
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/maoxiaoyue/hypgo/pkg/health"
+)
+
+// ===== 版本狀態與就緒檢查 =====
+
+// Status 資料庫目前版本與 migration 檔案最新版本的比對結果
+type Status struct {
+	Current int64 // 資料庫目前版本，未套用任何 migration 時為 NilVersion
+	Target  int64 // 最新 migration 的版本，沒有 migration 時為 NilVersion
+	Dirty   bool  // 上次執行在 migration 中途失敗
+	Pending int   // 尚未套用的 migration 數量
+}
+
+// UpToDate 是否已套用所有 migration 且不處於 dirty 狀態
+// 資料庫版本比檔案新（滾動部署時舊版實例尚未替換）也視為最新
+func (s Status) UpToDate() bool {
+	return !s.Dirty && s.Current >= s.Target
+}
+
+// Latest 最新 migration 的版本，沒有 migration 時為 NilVersion
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return NilVersion
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Status 讀取目前版本並與最新 migration 比對
+// 不取得 migration 鎖也不建立版本表，執行中的 Up 不會阻塞呼叫端；版本表不存在時視為 NilVersion
+func (m *Migrator) Status(ctx context.Context) (Status, error) {
+	st := Status{Current: NilVersion, Target: m.Latest()}
+	exists, err := m.tableExists(ctx, m.db)
+	if err != nil {
+		return st, fmt.Errorf("migrate: %w", err)
+	}
+	if exists {
+		if st.Current, st.Dirty, err = m.readVersion(ctx, m.db); err != nil {
+			return st, fmt.Errorf("migrate: read %s: %w", m.table, err)
+		}
+	}
+	for _, mig := range m.migrations {
+		if mig.Version > st.Current {
+			st.Pending++
+		}
+	}
+	return st, nil
+}
+
+// HealthCheck 就緒檢查，可直接作為 health.Checker
+// 回應的 details 帶有 current_version 與 target_version；版本落後時回報 degraded，dirty 或無法讀取時 unhealthy
+func (m *Migrator) HealthCheck(ctx context.Context) error {
+	st, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	health.SetDetail(ctx, "current_version", st.Current)
+	health.SetDetail(ctx, "target_version", st.Target)
+	switch {
+	case st.Dirty:
+		health.SetDetail(ctx, "dirty", true)
+		return &DirtyError{Version: st.Current}
+	case !st.UpToDate():
+		health.SetDetail(ctx, "pending", st.Pending)
+		return health.Degraded(fmt.Errorf("migrate: %d pending migration(s), database is at version %d, latest is %d", st.Pending, st.Current, st.Target))
+	}
+	return nil
+}
+
+// RegisterHealthCheck 以 "migrations" 為名註冊 readiness 檢查，資料庫套用完所有 migration 前不接收流量
+// reg 為 nil 時使用 health.Default
+//
+// EX：
+//
+//	migrations, _ := migrate.LoadDir("migrations")
+//	migrate.New(db, migrate.DialectPostgres, migrations).RegisterHealthCheck(srv.HealthRegistry())
+func (m *Migrator) RegisterHealthCheck(reg *health.Registry) {
+	if reg == nil {
+		reg = health.Default
+	}
+	reg.Register("migrations", m.HealthCheck)
+}
+
+// queryer *sql.DB 與 *sql.Conn 共用的查詢介面
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// tableExists 版本表是否存在（查詢系統目錄，避免以錯誤訊息判斷）
+func (m *Migrator) tableExists(ctx context.Context, q queryer) (bool, error) {
+	var query string
+	switch m.dialect {
+	case DialectPostgres:
+		query = "SELECT CASE WHEN to_regclass($1) IS NULL THEN 0 ELSE 1 END"
+	case DialectMySQL:
+		query = "SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?"
+	case DialectSQLite:
+		query = "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?"
+	default:
+		return false, fmt.Errorf("unsupported dialect %q", m.dialect)
+	}
+	var n int
+	if err := q.QueryRowContext(ctx, query, m.table).Scan(&n); err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
@@ -0,0 +1,88 @@
+package migrate
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/health"
+)
+
+func TestMigratorStatus(t *testing.T) {
+	db, store := openFake(t)
+	migrations, _ := Load(testMigrationFS)
+	m := New(db, DialectSQLite, migrations)
+	ctx := context.Background()
+
+	// 版本表尚未建立：視為 NilVersion，且不建立資料表
+	st, err := m.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if st.Current != NilVersion || st.Target != 3 || st.Pending != 3 || st.UpToDate() {
+		t.Errorf("Unexpected status before migrating: %+v", st)
+	}
+	if store.hasTable {
+		t.Error("Status must not create the version table")
+	}
+
+	store.hasTable, store.hasRow, store.version = true, true, 1
+	if st, _ = m.Status(ctx); st.Current != 1 || st.Pending != 2 || st.UpToDate() {
+		t.Errorf("Unexpected status at version 1: %+v", st)
+	}
+
+	store.version = 3
+	if st, _ = m.Status(ctx); !st.UpToDate() || st.Pending != 0 {
+		t.Errorf("Expected up to date at version 3: %+v", st)
+	}
+
+	// 資料庫比檔案新（滾動部署中的舊版實例）也視為最新
+	store.version = 4
+	if st, _ = m.Status(ctx); !st.UpToDate() {
+		t.Errorf("Expected newer database to be up to date: %+v", st)
+	}
+
+	store.version, store.dirty = 2, true
+	if st, _ = m.Status(ctx); !st.Dirty || st.UpToDate() {
+		t.Errorf("Expected dirty status: %+v", st)
+	}
+}
+
+func TestMigratorHealthCheck(t *testing.T) {
+	db, store := openFake(t)
+	migrations, _ := Load(testMigrationFS)
+	m := New(db, DialectSQLite, migrations)
+	reg := health.NewRegistry()
+	m.RegisterHealthCheck(reg)
+
+	store.hasTable, store.hasRow, store.version = true, true, 1
+	report := reg.Check(context.Background(), health.Readiness)
+	if report.Status != health.StatusDegraded || report.Healthy() {
+		t.Fatalf("Expected degraded report, got %+v", report)
+	}
+	res := report.Checks[0]
+	if res.Name != "migrations" || res.Status != health.StatusDegraded {
+		t.Errorf("Unexpected result %+v", res)
+	}
+	if res.Details["current_version"] != int64(1) || res.Details["target_version"] != int64(3) || res.Details["pending"] != 2 {
+		t.Errorf("Unexpected details %v", res.Details)
+	}
+
+	if _, err := m.Up(context.Background()); err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	report = reg.Check(context.Background(), health.Readiness)
+	if !report.Healthy() || report.Checks[0].Details["current_version"] != int64(3) {
+		t.Errorf("Expected healthy after Up, got %+v", report)
+	}
+
+	store.dirty = true
+	err := m.HealthCheck(context.Background())
+	var dirty *DirtyError
+	if !errors.As(err, &dirty) || health.IsDegraded(err) {
+		t.Errorf("Expected unhealthy DirtyError, got %v", err)
+	}
+	if report = reg.Check(context.Background(), health.Readiness); report.Status != health.StatusUnhealthy {
+		t.Errorf("Expected unhealthy report for dirty database, got %s", report.Status)
+	}
+}
@@ -0,0 +1,323 @@
+// Package repository 提供以 bun 實作的泛型 CRUD Repository，
+// 統一以 pkg/errors 的 ErrNotFound / ErrDuplicate 回報錯誤，取代各 model 重複的 service 樣板
+//
+// @chris
+package repository
+
+import (
+	"context"
+	"database/sql"
+	stderrors "errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/maoxiaoyue/hypgo/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+)
+
+// 分頁預設值，與 hidb.Paginate、Context.GetPageSize 一致
+const (
+	DefaultPageSize = 10
+	MaxPageSize     = 100
+)
+
+// Repository 單一 model 的 CRUD 操作，T 為 bun model（需有單一主鍵）
+// model 含 `bun:",soft_delete,nullzero"` 欄位時，Delete 改為軟刪除，查詢自動排除已刪除資料
+//
+// EX：
+//
+//	type UserService struct {
+//	    *repository.Repository[models.User]
+//	}
+//
+//	svc := &UserService{Repository: repository.New[models.User](db.HypDB())}
+//	user, err := svc.FindByID(ctx, 42)
+//	if errors.Is(err, errors.ErrNotFound) { ... }
+type Repository[T any] struct {
+	db    bun.IDB
+	table *schema.Table
+}
+
+// New 建立 Repository，db 可為 *bun.DB、bun.Tx 或 bun.Conn
+func New[T any](db bun.IDB) *Repository[T] {
+	return &Repository[T]{
+		db:    db,
+		table: db.Dialect().Tables().Get(reflect.TypeOf((*T)(nil)).Elem()),
+	}
+}
+
+// DB 底層的 bun.IDB，供自訂查詢使用
+func (r *Repository[T]) DB() bun.IDB {
+	return r.db
+}
+
+// WithTx 回傳在交易中操作的副本
+//
+// EX：
+//
+//	db.RunInTx(ctx, nil, func(ctx context.Context, tx bun.Tx) error {
+//	    return users.WithTx(tx).Delete(ctx, id)
+//	})
+func (r *Repository[T]) WithTx(tx bun.IDB) *Repository[T] {
+	return &Repository[T]{db: tx, table: r.table}
+}
+
+// Create 寫入新資料（自動遞增主鍵會回填到 model），違反唯一約束時回傳 ErrDuplicate
+func (r *Repository[T]) Create(ctx context.Context, model *T) error {
+	_, err := r.db.NewInsert().Model(model).Exec(ctx)
+	return translate(err)
+}
+
+// FindByID 依主鍵讀取，不存在（或已軟刪除）時回傳 ErrNotFound
+func (r *Repository[T]) FindByID(ctx context.Context, id interface{}) (*T, error) {
+	if err := r.checkPK(); err != nil {
+		return nil, err
+	}
+	model := new(T)
+	err := r.db.NewSelect().Model(model).Where("?TablePKs = ?", id).Limit(1).Scan(ctx)
+	if stderrors.Is(err, sql.ErrNoRows) {
+		return nil, errors.ErrNotFound.With("id", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+// List 分頁列出資料並回傳總數，依主鍵排序
+// filters 的 key 為欄位名稱（column 或 json 名稱），值為 nil 時比對 IS NULL，為 slice 時比對 IN；
+// 未知欄位回傳 ErrBadRequest。page 從 1 開始，size 預設 DefaultPageSize、上限 MaxPageSize
+func (r *Repository[T]) List(ctx context.Context, page, size int, filters map[string]interface{}) ([]T, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if size <= 0 {
+		size = DefaultPageSize
+	}
+	if size > MaxPageSize {
+		size = MaxPageSize
+	}
+
+	items := make([]T, 0)
+	q := r.db.NewSelect().Model(&items)
+	for _, key := range sortedKeys(filters) {
+		field, err := r.field(key)
+		if err != nil {
+			return nil, 0, err
+		}
+		switch v := filters[key]; {
+		case v == nil:
+			q.Where("?TableAlias.? IS NULL", bun.Ident(field.Name))
+		case isList(v):
+			q.Where("?TableAlias.? IN (?)", bun.Ident(field.Name), bun.In(v))
+		default:
+			q.Where("?TableAlias.? = ?", bun.Ident(field.Name), v)
+		}
+	}
+	total, err := q.OrderExpr("?TablePKs").Limit(size).Offset((page - 1) * size).ScanAndCount(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	return items, total, nil
+}
+
+// Update 以部分欄位更新（PATCH 語意）並回傳更新後的資料
+// patch 的 key 同 List 的 filters；主鍵與軟刪除欄位不可更新，model 有 updated_at 欄位時自動更新時間
+// 不存在時回傳 ErrNotFound，違反唯一約束時回傳 ErrDuplicate
+func (r *Repository[T]) Update(ctx context.Context, id interface{}, patch map[string]interface{}) (*T, error) {
+	if err := r.checkPK(); err != nil {
+		return nil, err
+	}
+	if len(patch) == 0 {
+		return r.FindByID(ctx, id)
+	}
+
+	q := r.db.NewUpdate().Model((*T)(nil)).Where("?PKs = ?", id)
+	touched := false
+	for _, key := range sortedKeys(patch) {
+		field, err := r.field(key)
+		if err != nil {
+			return nil, err
+		}
+		if field.IsPK || field == r.table.SoftDeleteField {
+			return nil, errors.ErrBadRequest.With("field", key).With("reason", "field is not updatable")
+		}
+		if field.Name == "updated_at" {
+			touched = true
+		}
+		q.Set("? = ?", bun.Ident(field.Name), patch[key])
+	}
+	if f, ok := r.table.FieldMap["updated_at"]; ok && !touched {
+		q.Set("? = ?", bun.Ident(f.Name), time.Now())
+	}
+
+	// MySQL 的 RowsAffected 只計算值有變動的資料列，0 不代表不存在，由 FindByID 確認並回傳 ErrNotFound
+	if _, err := q.Exec(ctx); err != nil {
+		return nil, translate(err)
+	}
+	return r.FindByID(ctx, id)
+}
+
+// Delete 依主鍵刪除（軟刪除 model 只標記 deleted_at），不存在時回傳 ErrNotFound
+func (r *Repository[T]) Delete(ctx context.Context, id interface{}) error {
+	return r.delete(ctx, id, false)
+}
+
+// ForceDelete 依主鍵實際刪除資料列（包含已軟刪除的資料）
+func (r *Repository[T]) ForceDelete(ctx context.Context, id interface{}) error {
+	return r.delete(ctx, id, true)
+}
+
+func (r *Repository[T]) delete(ctx context.Context, id interface{}, force bool) error {
+	if err := r.checkPK(); err != nil {
+		return err
+	}
+	q := r.db.NewDelete().Model((*T)(nil)).Where("?PKs = ?", id)
+	if force {
+		q.ForceDelete()
+	}
+	res, err := q.Exec(ctx)
+	if err != nil {
+		return translate(err)
+	}
+	return notFoundIfNone(res, id)
+}
+
+// Restore 還原已軟刪除的資料，model 沒有軟刪除欄位時回傳錯誤
+func (r *Repository[T]) Restore(ctx context.Context, id interface{}) error {
+	if err := r.checkPK(); err != nil {
+		return err
+	}
+	field := r.table.SoftDeleteField
+	if field == nil {
+		return fmt.Errorf("repository: %s has no soft delete field", r.table.TypeName)
+	}
+	res, err := r.db.NewUpdate().Model((*T)(nil)).WhereDeleted().
+		Where("?PKs = ?", id).
+		Set("? = NULL", bun.Ident(field.Name)).
+		Exec(ctx)
+	if err != nil {
+		return translate(err)
+	}
+	return notFoundIfNone(res, id)
+}
+
+// checkPK 目前只支援單一主鍵
+func (r *Repository[T]) checkPK() error {
+	if len(r.table.PKs) != 1 {
+		return fmt.Errorf("repository: %s must have exactly one primary key, has %d", r.table.TypeName, len(r.table.PKs))
+	}
+	return nil
+}
+
+// field 依 column 名稱或 json 名稱找出欄位
+func (r *Repository[T]) field(key string) (*schema.Field, error) {
+	if f, ok := r.table.FieldMap[key]; ok {
+		return f, nil
+	}
+	for _, f := range r.table.Fields {
+		if name, _, _ := strings.Cut(f.StructField.Tag.Get("json"), ","); name == key {
+			return f, nil
+		}
+	}
+	return nil, errors.ErrBadRequest.With("field", key).With("reason", "unknown field")
+}
+
+func notFoundIfNone(res sql.Result, id interface{}) error {
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return errors.ErrNotFound.With("id", id)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// isList 值是否為 IN 條件（[]byte 視為單一值）
+func isList(v interface{}) bool {
+	t := reflect.TypeOf(v)
+	return (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() != reflect.Uint8
+}
+
+// translate 將唯一約束違反轉為 ErrDuplicate（不附資料庫訊息，避免洩漏 schema），其餘錯誤原樣回傳
+func translate(err error) error {
+	if err != nil && isDuplicate(err) {
+		return errors.ErrDuplicate
+	}
+	return err
+}
+
+// uniqueViolation PostgreSQL 的唯一約束違反 SQLSTATE
+const uniqueViolation = "23505"
+
+// isDuplicate 判斷 PostgreSQL（pgx / lib/pq / bun pgdriver）、MySQL 與 SQLite 的唯一約束錯誤
+func isDuplicate(err error) bool {
+	var state interface{ SQLState() string }
+	if stderrors.As(err, &state) && state.SQLState() == uniqueViolation {
+		return true
+	}
+	var pgField interface{ Field(byte) string }
+	if stderrors.As(err, &pgField) && pgField.Field('C') == uniqueViolation {
+		return true
+	}
+	var myErr *mysql.MySQLError
+	if stderrors.As(err, &myErr) && myErr.Number == 1062 {
+		return true
+	}
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// Patch 將更新請求 struct 轉為 Update 使用的欄位 map（key 為 json 名稱）
+// 指標欄位為 nil、或帶 omitempty 且為零值的欄位視為未提供，其餘欄位一律更新（PUT 語意用非指標、無 omitempty 的欄位）
+//
+// EX：
+//
+//	type UpdateUserReq struct {
+//	    Name   string `json:"name,omitempty"`
+//	    Active *bool  `json:"active,omitempty"`
+//	}
+//	user, err := users.Update(ctx, id, repository.Patch(req))
+func Patch(v interface{}) map[string]interface{} {
+	patch := make(map[string]interface{})
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return patch
+	}
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fv := rv.Field(i)
+		switch {
+		case fv.Kind() == reflect.Ptr:
+			if fv.IsNil() {
+				continue
+			}
+			fv = fv.Elem()
+		case strings.Contains(","+opts+",", ",omitempty,") && fv.IsZero():
+			continue
+		}
+		patch[name] = fv.Interface()
+	}
+	return patch
+}
@@ -0,0 +1,322 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	stderrors "errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/maoxiaoyue/hypgo/pkg/errors"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+)
+
+// ===== 腳本化的 database/sql 驅動 =====
+
+// result 單次查詢的回應
+type result struct {
+	columns  []string
+	rows     [][]driver.Value
+	affected int64
+	err      error
+}
+
+// script 依 SQL 決定回應，並記錄所有執行過的 SQL
+type script struct {
+	mu      sync.Mutex
+	queries []string
+	respond func(query string) result
+}
+
+func (s *script) run(query string) result {
+	s.mu.Lock()
+	s.queries = append(s.queries, query)
+	s.mu.Unlock()
+	return s.respond(query)
+}
+
+func (s *script) last() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries[len(s.queries)-1]
+}
+
+type scriptConnector struct{ s *script }
+
+func (c scriptConnector) Connect(context.Context) (driver.Conn, error) { return scriptConn(c), nil }
+func (c scriptConnector) Driver() driver.Driver                        { return nil }
+
+type scriptConn struct{ s *script }
+
+func (scriptConn) Prepare(string) (driver.Stmt, error) { return nil, stderrors.New("not supported") }
+func (scriptConn) Close() error                        { return nil }
+func (scriptConn) Begin() (driver.Tx, error)           { return nil, stderrors.New("not supported") }
+
+func (c scriptConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	res := c.s.run(query)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return driver.RowsAffected(res.affected), nil
+}
+
+func (c scriptConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	res := c.s.run(query)
+	if res.err != nil {
+		return nil, res.err
+	}
+	return &scriptRows{columns: res.columns, rows: res.rows}, nil
+}
+
+type scriptRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *scriptRows) Columns() []string { return r.columns }
+func (r *scriptRows) Close() error      { return nil }
+func (r *scriptRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newScriptDB(t *testing.T, respond func(query string) result) (*bun.DB, *script) {
+	t.Helper()
+	s := &script{respond: respond}
+	db := bun.NewDB(sql.OpenDB(scriptConnector{s}), pgdialect.New())
+	t.Cleanup(func() { db.Close() })
+	return db, s
+}
+
+// ===== 測試 model =====
+
+type article struct {
+	bun.BaseModel `bun:"table:articles,alias:a"`
+
+	ID        int64     `bun:"id,pk,autoincrement" json:"id"`
+	Title     string    `bun:"title,notnull" json:"title"`
+	AuthorID  int64     `bun:"author_id" json:"authorId"`
+	UpdatedAt time.Time `bun:"updated_at,nullzero" json:"updated_at"`
+	DeletedAt time.Time `bun:",soft_delete,nullzero" json:"-"`
+}
+
+type tag struct {
+	ID   int64  `bun:"id,pk"`
+	Name string `bun:"name"`
+}
+
+var articleColumns = []string{"id", "title", "author_id", "updated_at", "deleted_at"}
+
+func articleRow(id int64, title string) []driver.Value {
+	return []driver.Value{id, title, int64(7), nil, nil}
+}
+
+// pgError 模擬 pgx / lib/pq 的 SQLState 介面
+type pgError struct{ code string }
+
+func (e pgError) Error() string    { return "pg error " + e.code }
+func (e pgError) SQLState() string { return e.code }
+
+func TestCreateAndFindByID(t *testing.T) {
+	db, s := newScriptDB(t, func(query string) result {
+		switch {
+		case strings.HasPrefix(query, "INSERT"):
+			return result{columns: []string{"id"}, rows: [][]driver.Value{{int64(5)}}}
+		case strings.Contains(query, `"a"."id" = 5`):
+			return result{columns: articleColumns, rows: [][]driver.Value{articleRow(5, "hello")}}
+		default:
+			return result{columns: articleColumns}
+		}
+	})
+	repo := New[article](db)
+	ctx := context.Background()
+
+	a := &article{Title: "hello"}
+	if err := repo.Create(ctx, a); err != nil || a.ID != 5 {
+		t.Fatalf("Create: %v (id %d)", err, a.ID)
+	}
+
+	found, err := repo.FindByID(ctx, 5)
+	if err != nil || found.Title != "hello" {
+		t.Fatalf("FindByID: %v %+v", err, found)
+	}
+	if !strings.Contains(s.last(), `"a"."deleted_at" IS NULL`) {
+		t.Errorf("expected soft-deleted rows to be excluded: %s", s.last())
+	}
+
+	if _, err := repo.FindByID(ctx, 6); !stderrors.Is(err, errors.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCreateDuplicate(t *testing.T) {
+	for name, dupErr := range map[string]error{
+		"postgres": pgError{code: "23505"},
+		"mysql":    &mysql.MySQLError{Number: 1062, Message: "Duplicate entry"},
+		"sqlite":   stderrors.New("UNIQUE constraint failed: articles.title"),
+	} {
+		db, _ := newScriptDB(t, func(string) result { return result{err: dupErr} })
+		err := New[article](db).Create(context.Background(), &article{Title: "x"})
+		if !stderrors.Is(err, errors.ErrDuplicate) {
+			t.Errorf("%s: expected ErrDuplicate, got %v", name, err)
+		}
+	}
+
+	other := pgError{code: "23503"}
+	db, _ := newScriptDB(t, func(string) result { return result{err: other} })
+	if err := New[article](db).Create(context.Background(), &article{}); !stderrors.Is(err, other) {
+		t.Errorf("expected other errors unchanged, got %v", err)
+	}
+}
+
+func TestList(t *testing.T) {
+	db, s := newScriptDB(t, func(query string) result {
+		if strings.Contains(query, "count(*)") {
+			return result{columns: []string{"count"}, rows: [][]driver.Value{{int64(42)}}}
+		}
+		return result{columns: articleColumns, rows: [][]driver.Value{articleRow(21, "a"), articleRow(22, "b")}}
+	})
+	repo := New[article](db)
+
+	items, total, err := repo.List(context.Background(), 2, 500, map[string]interface{}{
+		"authorId":   7,
+		"title":      []string{"a", "b"},
+		"updated_at": nil,
+	})
+	if err != nil || total != 42 || len(items) != 2 || items[1].ID != 22 {
+		t.Fatalf("List: %v total=%d items=%+v", err, total, items)
+	}
+
+	var selectQuery string
+	for _, q := range s.queries {
+		if !strings.Contains(q, "count(*)") {
+			selectQuery = q
+		}
+	}
+	for _, want := range []string{
+		`"a"."author_id" = 7`, `"a"."title" IN ('a', 'b')`, `"a"."updated_at" IS NULL`,
+		`ORDER BY "a"."id"`, "LIMIT 100 OFFSET 100",
+	} {
+		if !strings.Contains(selectQuery, want) {
+			t.Errorf("expected %q in %s", want, selectQuery)
+		}
+	}
+
+	if _, _, err := repo.List(context.Background(), 1, 10, map[string]interface{}{"password": "x"}); !stderrors.Is(err, errors.ErrBadRequest) {
+		t.Errorf("expected ErrBadRequest for unknown filter, got %v", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	affected, exists := int64(1), true
+	db, s := newScriptDB(t, func(query string) result {
+		if strings.HasPrefix(query, "UPDATE") {
+			return result{affected: affected}
+		}
+		if !exists {
+			return result{columns: articleColumns}
+		}
+		return result{columns: articleColumns, rows: [][]driver.Value{articleRow(5, "renamed")}}
+	})
+	repo := New[article](db)
+	ctx := context.Background()
+
+	updated, err := repo.Update(ctx, 5, map[string]interface{}{"title": "renamed"})
+	if err != nil || updated.Title != "renamed" {
+		t.Fatalf("Update: %v %+v", err, updated)
+	}
+	update := s.queries[0]
+	for _, want := range []string{`"title" = 'renamed'`, `"updated_at" = '`, `"id" = 5`, `"deleted_at" IS NULL`} {
+		if !strings.Contains(update, want) {
+			t.Errorf("expected %q in %s", want, update)
+		}
+	}
+
+	if _, err := repo.Update(ctx, 5, map[string]interface{}{"id": 9}); !stderrors.Is(err, errors.ErrBadRequest) {
+		t.Errorf("expected primary key update to be rejected, got %v", err)
+	}
+
+	// MySQL 值未變動時 RowsAffected 為 0，資料仍存在
+	affected = 0
+	if updated, err := repo.Update(ctx, 5, map[string]interface{}{"title": "renamed"}); err != nil || updated.Title != "renamed" {
+		t.Errorf("expected no-op update to succeed, got %v %+v", err, updated)
+	}
+
+	exists = false
+	if _, err := repo.Update(ctx, 6, map[string]interface{}{"title": "x"}); !stderrors.Is(err, errors.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestDeleteSoftAndForce(t *testing.T) {
+	affected := int64(1)
+	db, s := newScriptDB(t, func(string) result { return result{affected: affected} })
+	repo := New[article](db)
+	ctx := context.Background()
+
+	if err := repo.Delete(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if q := s.last(); !strings.HasPrefix(q, "UPDATE") || !strings.Contains(q, `SET "deleted_at" = '`) {
+		t.Errorf("expected soft delete, got %s", q)
+	}
+
+	if err := repo.Restore(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if q := s.last(); !strings.Contains(q, `"deleted_at" = NULL`) || !strings.Contains(q, `"deleted_at" IS NOT NULL`) {
+		t.Errorf("unexpected restore query %s", q)
+	}
+
+	if err := repo.ForceDelete(ctx, 5); err != nil {
+		t.Fatal(err)
+	}
+	if q := s.last(); !strings.HasPrefix(q, "DELETE") {
+		t.Errorf("expected hard delete, got %s", q)
+	}
+
+	affected = 0
+	if err := repo.Delete(ctx, 6); !stderrors.Is(err, errors.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+
+	// 沒有軟刪除欄位的 model 直接刪除，且不可還原
+	affected = 1
+	tags := New[tag](db)
+	if err := tags.Delete(ctx, 1); err != nil || !strings.HasPrefix(s.last(), "DELETE") {
+		t.Errorf("expected hard delete for plain model: %v %s", err, s.last())
+	}
+	if err := tags.Restore(ctx, 1); err == nil {
+		t.Error("expected Restore to fail without a soft delete field")
+	}
+}
+
+func TestPatch(t *testing.T) {
+	title, zero := "new", int64(0)
+	req := struct {
+		Title    *string `json:"title,omitempty"`
+		AuthorID *int64  `json:"authorId,omitempty"`
+		Summary  string  `json:"summary,omitempty"`
+		Pinned   bool    `json:"pinned"`
+		Secret   string  `json:"-"`
+		skipped  string
+	}{Title: &title, AuthorID: &zero, Secret: "x", skipped: "y"}
+
+	patch := Patch(&req)
+	if len(patch) != 3 || patch["title"] != "new" || patch["authorId"] != int64(0) || patch["pinned"] != false {
+		t.Errorf("unexpected patch %v", patch)
+	}
+	if got := Patch(42); len(got) != 0 {
+		t.Errorf("expected empty patch for non-struct, got %v", got)
+	}
+}
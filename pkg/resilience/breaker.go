@@ -0,0 +1,269 @@
+// Package resilience 提供失敗隔離的共用元件：熔斷器（CircuitBreaker）與重試（Retry）
+// Redis、讀取副本、訊息代理、下游 HTTP 等子系統共用同一套狀態機與指標 hook，
+// 不必各自實作零散的熔斷邏輯。
+//
+// @chris
+package resilience
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrOpen 熔斷中，呼叫未執行即被拒絕
+var ErrOpen = errors.New("resilience: circuit breaker is open")
+
+// 預設值
+const (
+	DefaultFailureThreshold = 5
+	DefaultSuccessThreshold = 1
+	DefaultCooldown         = 30 * time.Second
+	DefaultHalfOpenMaxCalls = 1
+)
+
+// State 熔斷器狀態
+type State int
+
+const (
+	// StateClosed 正常放行，累計連續失敗
+	StateClosed State = iota
+	// StateOpen 熔斷中，冷卻期內一律拒絕
+	StateOpen
+	// StateHalfOpen 冷卻結束，放行有限的探測呼叫
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// Hooks 指標 hook，任一欄位為 nil 即略過
+// 於熔斷器鎖外呼叫，可在 hook 內查詢 State()；應盡快返回
+type Hooks struct {
+	// OnStateChange 狀態轉換
+	OnStateChange func(name string, from, to State)
+	// OnSuccess 呼叫成功（IsFailure 判定為非失敗）
+	OnSuccess func(name string)
+	// OnFailure 呼叫失敗
+	OnFailure func(name string, err error)
+	// OnReject 熔斷中被拒絕
+	OnReject func(name string)
+}
+
+// BreakerConfig 熔斷器配置，零值欄位使用預設值
+type BreakerConfig struct {
+	// Name 用於日誌與指標的名稱，例如 "redis"、"replica-0"
+	Name string
+	// FailureThreshold 連續失敗幾次後熔斷，預設 5
+	FailureThreshold int
+	// SuccessThreshold 半開狀態下連續成功幾次後恢復，預設 1
+	SuccessThreshold int
+	// Cooldown 熔斷持續時間，預設 30s
+	Cooldown time.Duration
+	// HalfOpenMaxCalls 半開狀態下放行的探測呼叫數，預設 1
+	HalfOpenMaxCalls int
+	// IsFailure 判定錯誤是否計入失敗，預設見 DefaultIsFailure
+	IsFailure func(err error) bool
+	// Hooks 指標 hook
+	Hooks Hooks
+}
+
+// DefaultIsFailure 預設失敗判定：呼叫端取消（context.Canceled）與 sql.ErrNoRows 不算下游故障
+func DefaultIsFailure(err error) bool {
+	return err != nil && !errors.Is(err, context.Canceled) && !errors.Is(err, sql.ErrNoRows)
+}
+
+// CircuitBreaker 熔斷器
+// 連續失敗達門檻即熔斷 Cooldown，期間呼叫直接回傳 ErrOpen；
+// 冷卻結束後進入半開，放行 HalfOpenMaxCalls 個探測，連續成功 SuccessThreshold 次即恢復，任一失敗則再次熔斷
+//
+// EX：
+//
+//	cb := resilience.NewCircuitBreaker(resilience.BreakerConfig{Name: "billing-api"})
+//	err := cb.Execute(ctx, func(ctx context.Context) error {
+//	    return callBilling(ctx)
+//	})
+//	if errors.Is(err, resilience.ErrOpen) {
+//	    // 降級處理
+//	}
+type CircuitBreaker struct {
+	cfg BreakerConfig
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	successes int
+	admitted  int
+	changedAt time.Time
+}
+
+// NewCircuitBreaker 創建熔斷器
+func NewCircuitBreaker(cfg BreakerConfig) *CircuitBreaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultFailureThreshold
+	}
+	if cfg.SuccessThreshold <= 0 {
+		cfg.SuccessThreshold = DefaultSuccessThreshold
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = DefaultCooldown
+	}
+	if cfg.HalfOpenMaxCalls <= 0 {
+		cfg.HalfOpenMaxCalls = DefaultHalfOpenMaxCalls
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = DefaultIsFailure
+	}
+	return &CircuitBreaker{cfg: cfg, changedAt: time.Now()}
+}
+
+// Name 熔斷器名稱
+func (b *CircuitBreaker) Name() string {
+	return b.cfg.Name
+}
+
+// State 目前狀態；冷卻已結束的熔斷視為半開
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == StateOpen && time.Since(b.changedAt) >= b.cfg.Cooldown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Execute 經由熔斷器執行 fn；熔斷中回傳 ErrOpen，ctx 已結束時回傳 ctx.Err()，兩者都不執行 fn
+func (b *CircuitBreaker) Execute(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if err := b.Allow(); err != nil {
+		return err
+	}
+	err := fn(ctx)
+	b.Record(err)
+	return err
+}
+
+// Allow 判斷是否放行一次呼叫，拒絕時回傳 ErrOpen
+// 放行後須以 Record 回報結果；適用於結果非同步取得的場景（例如查詢 hook）
+func (b *CircuitBreaker) Allow() error {
+	b.mu.Lock()
+	var from State
+	changed, allowed := false, true
+	switch b.state {
+	case StateOpen:
+		if time.Since(b.changedAt) < b.cfg.Cooldown {
+			allowed = false
+			break
+		}
+		from, changed = b.transition(StateHalfOpen)
+		b.admitted = 1
+	case StateHalfOpen:
+		// 探測名額用盡；若探測結果遲遲未回報（超過一個冷卻期），重新發放名額以免卡在半開
+		if b.admitted >= b.cfg.HalfOpenMaxCalls {
+			if time.Since(b.changedAt) < b.cfg.Cooldown {
+				allowed = false
+				break
+			}
+			b.changedAt = time.Now()
+			b.admitted = 0
+		}
+		b.admitted++
+	}
+	b.mu.Unlock()
+
+	if changed {
+		b.notifyStateChange(from, StateHalfOpen)
+	}
+	if !allowed {
+		if h := b.cfg.Hooks.OnReject; h != nil {
+			h(b.cfg.Name)
+		}
+		return ErrOpen
+	}
+	return nil
+}
+
+// Record 回報一次呼叫的結果
+func (b *CircuitBreaker) Record(err error) {
+	failed := b.cfg.IsFailure(err)
+
+	b.mu.Lock()
+	var from, to State
+	changed := false
+	switch b.state {
+	case StateClosed:
+		if !failed {
+			b.failures = 0
+			break
+		}
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			to = StateOpen
+			from, changed = b.transition(to)
+		}
+	case StateHalfOpen:
+		if failed {
+			to = StateOpen
+			from, changed = b.transition(to)
+			break
+		}
+		b.successes++
+		if b.successes >= b.cfg.SuccessThreshold {
+			to = StateClosed
+			from, changed = b.transition(to)
+		} else if b.admitted > 0 {
+			// 釋放探測名額，讓下一個探測通過
+			b.admitted--
+		}
+	}
+	// StateOpen：熔斷前已放行的呼叫延遲回報，不影響狀態
+	b.mu.Unlock()
+
+	if failed {
+		if h := b.cfg.Hooks.OnFailure; h != nil {
+			h(b.cfg.Name, err)
+		}
+	} else if h := b.cfg.Hooks.OnSuccess; h != nil {
+		h(b.cfg.Name)
+	}
+	if changed {
+		b.notifyStateChange(from, to)
+	}
+}
+
+// Reset 強制恢復為關閉狀態
+func (b *CircuitBreaker) Reset() {
+	b.mu.Lock()
+	from, changed := b.transition(StateClosed)
+	b.mu.Unlock()
+	if changed {
+		b.notifyStateChange(from, StateClosed)
+	}
+}
+
+// transition 切換狀態並重置計數，需持有 b.mu
+func (b *CircuitBreaker) transition(to State) (from State, changed bool) {
+	from = b.state
+	b.state = to
+	b.failures, b.successes, b.admitted = 0, 0, 0
+	b.changedAt = time.Now()
+	return from, from != to
+}
+
+func (b *CircuitBreaker) notifyStateChange(from, to State) {
+	if h := b.cfg.Hooks.OnStateChange; h != nil {
+		h(b.cfg.Name, from, to)
+	}
+}
@@ -0,0 +1,174 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+var errDown = errors.New("down")
+
+func TestCircuitBreakerTransitions(t *testing.T) {
+	var transitions []string
+	cb := NewCircuitBreaker(BreakerConfig{
+		Name:             "redis",
+		FailureThreshold: 2,
+		SuccessThreshold: 2,
+		Cooldown:         20 * time.Millisecond,
+		Hooks: Hooks{
+			OnStateChange: func(name string, from, to State) {
+				transitions = append(transitions, fmt.Sprintf("%s:%s->%s", name, from, to))
+			},
+		},
+	})
+	ctx := context.Background()
+	fail := func(context.Context) error { return errDown }
+	ok := func(context.Context) error { return nil }
+
+	cb.Execute(ctx, fail)
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed below threshold, got %s", cb.State())
+	}
+	cb.Execute(ctx, fail)
+	if cb.State() != StateOpen {
+		t.Fatalf("Expected open at threshold, got %s", cb.State())
+	}
+
+	called := false
+	if err := cb.Execute(ctx, func(context.Context) error { called = true; return nil }); !errors.Is(err, ErrOpen) || called {
+		t.Fatalf("Expected ErrOpen without calling fn, got %v (called=%v)", err, called)
+	}
+
+	// 冷卻後半開：探測失敗立即再次熔斷
+	time.Sleep(25 * time.Millisecond)
+	if err := cb.Execute(ctx, fail); !errors.Is(err, errDown) || cb.State() != StateOpen {
+		t.Fatalf("Expected failed probe to reopen, got %v / %s", err, cb.State())
+	}
+
+	// 半開需連續成功兩次才恢復，期間同時只放行一個探測
+	time.Sleep(25 * time.Millisecond)
+	if err := cb.Allow(); err != nil {
+		t.Fatalf("Expected probe to be admitted, got %v", err)
+	}
+	if err := cb.Allow(); !errors.Is(err, ErrOpen) {
+		t.Fatalf("Expected second concurrent probe to be rejected, got %v", err)
+	}
+	cb.Record(nil)
+	if cb.State() != StateHalfOpen {
+		t.Fatalf("Expected half-open after first success, got %s", cb.State())
+	}
+	cb.Execute(ctx, ok)
+	if cb.State() != StateClosed {
+		t.Fatalf("Expected closed after success threshold, got %s", cb.State())
+	}
+
+	want := []string{
+		"redis:closed->open", "redis:open->half-open", "redis:half-open->open",
+		"redis:open->half-open", "redis:half-open->closed",
+	}
+	if fmt.Sprint(transitions) != fmt.Sprint(want) {
+		t.Errorf("Unexpected transitions:\n got %v\nwant %v", transitions, want)
+	}
+}
+
+func TestCircuitBreakerIgnoresNonFailures(t *testing.T) {
+	rejects := 0
+	cb := NewCircuitBreaker(BreakerConfig{
+		FailureThreshold: 1,
+		Hooks:            Hooks{OnReject: func(string) { rejects++ }},
+	})
+
+	cb.Record(context.Canceled)
+	cb.Record(fmt.Errorf("lookup: %w", context.Canceled))
+	if cb.State() != StateClosed {
+		t.Fatalf("Caller cancellation must not open the breaker")
+	}
+
+	// ctx 已結束時不呼叫 fn，也不影響狀態
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cb.Execute(ctx, func(context.Context) error { return errDown }); !errors.Is(err, context.Canceled) || cb.State() != StateClosed {
+		t.Fatalf("Expected context error without state change, got %v / %s", err, cb.State())
+	}
+
+	cb.Record(errDown)
+	cb.Allow()
+	if rejects != 1 {
+		t.Errorf("Expected OnReject once, got %d", rejects)
+	}
+	cb.Reset()
+	if cb.State() != StateClosed || cb.Allow() != nil {
+		t.Errorf("Expected Reset to close the breaker")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	var waits []time.Duration
+	err := Retry(context.Background(), RetryConfig{
+		Attempts:       4,
+		InitialBackoff: time.Millisecond,
+		Jitter:         -1,
+		OnRetry:        func(attempt int, err error, wait time.Duration) { waits = append(waits, wait) },
+	}, func(context.Context) error {
+		calls++
+		if calls < 3 {
+			return errDown
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Fatalf("Expected success on third attempt, got %v after %d calls", err, calls)
+	}
+	if fmt.Sprint(waits) != fmt.Sprint([]time.Duration{time.Millisecond, 2 * time.Millisecond}) {
+		t.Errorf("Expected exponential backoff without jitter, got %v", waits)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), RetryConfig{Attempts: 2, InitialBackoff: time.Millisecond}, func(context.Context) error {
+		calls++
+		return errDown
+	})
+	if !errors.Is(err, errDown) || calls != 2 {
+		t.Errorf("Expected last error after attempts exhausted, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	err = Retry(context.Background(), RetryConfig{}, func(context.Context) error {
+		calls++
+		return Permanent(errDown)
+	})
+	if err != errDown || calls != 1 {
+		t.Errorf("Expected permanent error to stop immediately, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := Retry(ctx, RetryConfig{Attempts: 10, InitialBackoff: time.Second}, func(context.Context) error {
+		return errDown
+	})
+	if !errors.Is(err, errDown) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected last error joined with deadline, got %v", err)
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("Retry should stop waiting when ctx is done")
+	}
+}
+
+func TestBackoffBounds(t *testing.T) {
+	cfg := RetryConfig{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second, Jitter: 0.5}
+	for attempt := 1; attempt <= 10; attempt++ {
+		for i := 0; i < 20; i++ {
+			d := cfg.Backoff(attempt)
+			if d < 50*time.Millisecond || d > time.Second {
+				t.Fatalf("attempt %d: backoff %v out of bounds", attempt, d)
+			}
+		}
+	}
+}
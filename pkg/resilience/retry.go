@@ -0,0 +1,138 @@
+// @chris
+package resilience
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"time"
+)
+
+// RetryConfig 重試配置，零值欄位使用預設值
+type RetryConfig struct {
+	// Attempts 總嘗試次數（含第一次），預設 3
+	Attempts int
+	// InitialBackoff 第一次重試前的等待時間，預設 100ms
+	InitialBackoff time.Duration
+	// MaxBackoff 等待時間上限，預設 10s
+	MaxBackoff time.Duration
+	// Multiplier 每次重試的等待倍數，預設 2
+	Multiplier float64
+	// Jitter 等待時間的隨機浮動比例（0~1），預設 0.2 即 ±20%，負值停用；避免大量客戶端同時重試
+	Jitter float64
+	// RetryIf 判定錯誤是否值得重試，預設見 DefaultRetryIf
+	RetryIf func(err error) bool
+	// OnRetry 每次重試前呼叫（attempt 從 1 起算，為剛失敗的那一次），可用於日誌與指標
+	OnRetry func(attempt int, err error, wait time.Duration)
+}
+
+// DefaultRetryIf 預設重試判定：context 結束、熔斷中（ErrOpen）與 Permanent 錯誤不重試
+func DefaultRetryIf(err error) bool {
+	var p *permanentError
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, ErrOpen) &&
+		!errors.As(err, &p)
+}
+
+type permanentError struct{ err error }
+
+func (e *permanentError) Error() string { return e.err.Error() }
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent 標記錯誤為不可重試；Retry 會立即停止並回傳原錯誤（errors.Is/As 仍可比對）
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Retry 以指數退避 + 隨機抖動重試 fn，直到成功、錯誤不可重試、次數用盡或 ctx 結束
+// ctx 在等待期間結束時回傳最後一次錯誤與 ctx.Err() 的組合
+//
+// EX：
+//
+//	err := resilience.Retry(ctx, resilience.RetryConfig{Attempts: 5}, func(ctx context.Context) error {
+//	    return cb.Execute(ctx, publish)
+//	})
+func Retry(ctx context.Context, cfg RetryConfig, fn func(ctx context.Context) error) error {
+	cfg = cfg.withDefaults()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			if err == nil {
+				return ctxErr
+			}
+			return errors.Join(err, ctxErr)
+		}
+
+		err = fn(ctx)
+		if err == nil || attempt >= cfg.Attempts || !cfg.RetryIf(err) {
+			if p, ok := err.(*permanentError); ok {
+				return p.err
+			}
+			return err
+		}
+
+		wait := cfg.backoff(attempt)
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return errors.Join(err, ctx.Err())
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff 第 attempt 次失敗後的等待時間（含抖動）
+func (cfg RetryConfig) Backoff(attempt int) time.Duration {
+	return cfg.withDefaults().backoff(attempt)
+}
+
+func (cfg RetryConfig) backoff(attempt int) time.Duration {
+	wait := float64(cfg.InitialBackoff)
+	for i := 1; i < attempt && wait < float64(cfg.MaxBackoff); i++ {
+		wait *= cfg.Multiplier
+	}
+	if cfg.Jitter > 0 {
+		wait *= 1 - cfg.Jitter + 2*cfg.Jitter*rand.Float64()
+	}
+	if wait > float64(cfg.MaxBackoff) {
+		wait = float64(cfg.MaxBackoff)
+	}
+	return time.Duration(wait)
+}
+
+func (cfg RetryConfig) withDefaults() RetryConfig {
+	if cfg.Attempts <= 0 {
+		cfg.Attempts = 3
+	}
+	if cfg.InitialBackoff <= 0 {
+		cfg.InitialBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = 10 * time.Second
+	}
+	if cfg.Multiplier < 1 {
+		cfg.Multiplier = 2
+	}
+	switch {
+	case cfg.Jitter < 0:
+		cfg.Jitter = 0
+	case cfg.Jitter == 0:
+		cfg.Jitter = 0.2
+	case cfg.Jitter > 1:
+		cfg.Jitter = 1
+	}
+	if cfg.RetryIf == nil {
+		cfg.RetryIf = DefaultRetryIf
+	}
+	return cfg
+}
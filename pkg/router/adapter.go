@@ -0,0 +1,115 @@
+// @chris
+package router
+
+import (
+	"io"
+	"net/http"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== net/http 轉接 =====
+
+// WrapHTTPHandler 將標準 http.Handler 轉為 HandlerFunc，作為路由的最終處理器
+// 請求的 context 帶有 HypGo Context，可在 handler 內以 hypcontext.FromContext(r.Context()) 取得
+//
+// EX：
+//
+//	r.GET("/debug/pprof/*path", router.WrapHTTPHandler(http.DefaultServeMux))
+//	r.GET("/metrics", router.WrapHTTPHandler(promhttp.Handler()))
+func WrapHTTPHandler(h http.Handler) hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		h.ServeHTTP(c.Writer, c.Request.WithContext(c.StdContext()))
+	}
+}
+
+// WrapHTTPMiddleware 將 func(http.Handler) http.Handler 形式的中間件轉為 HandlerFunc
+//
+// 中間件呼叫 next 時繼續執行後續的 HypGo 處理器鏈：中間件替換的 *http.Request
+// （如加入 context 值）會寫回 c.Request，包裝的 ResponseWriter（如壓縮）會成為 c.Writer，
+// 兩者在中間件返回後還原；中間件未呼叫 next（如驗證失敗直接回應）時中止處理器鏈
+//
+// EX：
+//
+//	r.Use(router.WrapHTTPMiddleware(cors.Default().Handler))
+//	api.Use(router.WrapHTTPMiddleware(middleware.RealIP))
+func WrapHTTPMiddleware(mw func(http.Handler) http.Handler) hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		origRequest, origWriter, origResponse := c.Request, c.Writer, c.Response
+		called := false
+
+		next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called = true
+			c.Request = r
+			if w != http.ResponseWriter(origWriter) {
+				wrapped := &wrappedWriter{ResponseWriter: origWriter, w: w}
+				c.Writer, c.Response = wrapped, wrapped
+			}
+			c.Next()
+			if wrapped, ok := c.Writer.(*wrappedWriter); ok {
+				wrapped.WriteHeaderNow()
+			}
+		})
+
+		mw(next).ServeHTTP(origWriter, c.Request.WithContext(c.StdContext()))
+		c.Request, c.Writer, c.Response = origRequest, origWriter, origResponse
+		if !called {
+			c.Abort()
+		}
+	}
+}
+
+// wrappedWriter 讓 HypGo 處理器透過中間件包裝後的 ResponseWriter 寫出
+// 保留 HypGo 延遲寫出狀態碼的語意：WriteHeader 只記錄，第一次寫入 body 時才送出；
+// 狀態、Hijack 等其餘方法沿用原本的 writer
+type wrappedWriter struct {
+	hypcontext.ResponseWriter
+	w      http.ResponseWriter
+	status int
+	wrote  bool
+}
+
+func (ww *wrappedWriter) Header() http.Header {
+	return ww.w.Header()
+}
+
+func (ww *wrappedWriter) WriteHeader(code int) {
+	if code > 0 && !ww.wrote {
+		ww.status = code
+	}
+}
+
+func (ww *wrappedWriter) WriteHeaderNow() {
+	if !ww.wrote {
+		ww.wrote = true
+		ww.w.WriteHeader(ww.Status())
+	}
+}
+
+func (ww *wrappedWriter) Write(data []byte) (int, error) {
+	ww.WriteHeaderNow()
+	return ww.w.Write(data)
+}
+
+func (ww *wrappedWriter) WriteString(s string) (int, error) {
+	ww.WriteHeaderNow()
+	return io.WriteString(ww.w, s)
+}
+
+func (ww *wrappedWriter) Status() int {
+	if ww.status != 0 {
+		return ww.status
+	}
+	return ww.ResponseWriter.Status()
+}
+
+func (ww *wrappedWriter) Written() bool {
+	return ww.wrote || ww.ResponseWriter.Written()
+}
+
+func (ww *wrappedWriter) Flush() {
+	ww.WriteHeaderNow()
+	if flusher, ok := ww.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
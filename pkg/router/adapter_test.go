@@ -0,0 +1,112 @@
+package router
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+type adapterKey struct{}
+
+// upperWriter 模擬會包裝 ResponseWriter 的中間件（如壓縮）
+type upperWriter struct {
+	http.ResponseWriter
+}
+
+func (u upperWriter) Write(b []byte) (int, error) {
+	return u.ResponseWriter.Write([]byte(strings.ToUpper(string(b))))
+}
+
+func TestWrapHTTPMiddleware(t *testing.T) {
+	withValue := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Std", "1")
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), adapterKey{}, "from-std")))
+		})
+	}
+	upper := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(upperWriter{w}, r)
+		})
+	}
+
+	r := New()
+	r.Use(WrapHTTPMiddleware(withValue))
+	r.GET("/value", func(c *hypcontext.Context) {
+		v, _ := c.Request.Context().Value(adapterKey{}).(string)
+		c.String(http.StatusAccepted, v)
+	})
+	r.GET("/upper", WrapHTTPMiddleware(upper), func(c *hypcontext.Context) {
+		c.String(http.StatusCreated, "hello")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/value", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "from-std" || w.Header().Get("X-Std") != "1" {
+		t.Errorf("Unexpected response %d %q %v", w.Code, w.Body.String(), w.Header())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/upper", nil))
+	if w.Code != http.StatusCreated || w.Body.String() != "HELLO" {
+		t.Errorf("Expected write through wrapped writer, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestWrapHTTPMiddlewareShortCircuit(t *testing.T) {
+	deny := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+
+	r := New()
+	reached := false
+	r.GET("/secret", WrapHTTPMiddleware(deny), func(c *hypcontext.Context) {
+		reached = true
+		c.String(http.StatusOK, "secret")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/secret", nil))
+	if w.Code != http.StatusUnauthorized || reached {
+		t.Errorf("Expected 401 without reaching handler, got %d reached=%v", w.Code, reached)
+	}
+
+	req := httptest.NewRequest("GET", "/secret", nil)
+	req.Header.Set("Authorization", "Bearer x")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || !reached {
+		t.Errorf("Expected handler to run with credentials, got %d", w.Code)
+	}
+}
+
+func TestWrapHTTPHandler(t *testing.T) {
+	std := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, ok := hypcontext.FromContext(r.Context())
+		if !ok {
+			http.Error(w, "no context", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("id=" + c.Param("id")))
+	})
+
+	r := New()
+	r.GET("/items/:id", WrapHTTPHandler(std))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/items/9", nil))
+	if w.Code != http.StatusTeapot || w.Body.String() != "id=9" {
+		t.Errorf("Unexpected response %d %q", w.Code, w.Body.String())
+	}
+}
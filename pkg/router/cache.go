@@ -3,12 +3,14 @@ package router
 
 import (
 	"sync"
+	"sync/atomic"
 
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
 )
 
 // routeCache LRU 路由快取
-// 用於快取靜態路由的查找結果，減少 Radix Tree 遍歷
+// 以 method+path 為鍵快取匹配到的路由模板與 handler 鏈，減少 Radix Tree 遍歷；
+// 參數值不快取，命中時依模板從路徑重新提取。路由在註冊後不再變動，因此不需失效機制
 type routeCache struct {
 	mu       sync.RWMutex
 	items    map[string]*cacheItem
@@ -16,15 +18,21 @@ type routeCache struct {
 	tail     *cacheItem // 最久未使用
 	capacity int
 	size     int
+	gen      atomic.Uint64 // 每次 clear 遞增，避免查到舊樹的請求在 clear 後寫回過期結果
+}
+
+// cachedRoute 快取命中的路由
+type cachedRoute struct {
+	handlers []hypcontext.HandlerFunc
+	fullPath string // 路由模板，如 /users/:id
 }
 
 // cacheItem 快取項目（雙向鏈表節點）
 type cacheItem struct {
-	key      string
-	handlers []hypcontext.HandlerFunc
-	params   []Param
-	prev     *cacheItem
-	next     *cacheItem
+	cachedRoute
+	key  string
+	prev *cacheItem
+	next *cacheItem
 }
 
 // cacheItemPool GC 優化：快取項目池，避免每次 cache miss 都分配新 struct
@@ -42,33 +50,58 @@ func newRouteCache(capacity int) *routeCache {
 	}
 }
 
-// get 從快取中取出項目（命中時移到頭部）
-func (c *routeCache) get(key string) *cacheItem {
+// get 從快取中取出路由（命中時移到頭部）
+// 返回副本：被淘汰的 cacheItem 會回收到 pool 重用，不能在鎖外持有指標
+func (c *routeCache) get(key string) (cachedRoute, bool) {
+	// 熱門路由通常已在頭部，只需讀鎖
 	c.mu.RLock()
 	entry, exists := c.items[key]
+	if exists && entry == c.head {
+		route := entry.cachedRoute
+		c.mu.RUnlock()
+		return route, true
+	}
 	c.mu.RUnlock()
-
 	if !exists {
-		return nil
+		return cachedRoute{}, false
 	}
 
-	// 移到頭部（LRU）
+	// 移到頭部（LRU）；釋放讀鎖期間可能已被淘汰，需重新查找
 	c.mu.Lock()
+	defer c.mu.Unlock()
+	if entry, exists = c.items[key]; !exists {
+		return cachedRoute{}, false
+	}
 	c.moveToHead(entry)
-	c.mu.Unlock()
-
-	return entry
+	return entry.cachedRoute, true
 }
 
 // put 放入快取（已存在則更新並移到頭部，超容量時淘汰尾部）
-func (c *routeCache) put(key string, handlers []hypcontext.HandlerFunc, params []Param) {
+func (c *routeCache) put(key string, handlers []hypcontext.HandlerFunc, fullPath string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.putLocked(key, handlers, fullPath)
+}
+
+// generation 返回目前的快取世代，需在查找路由樹之前取得
+func (c *routeCache) generation() uint64 {
+	return c.gen.Load()
+}
+
+// putIfGeneration 快取世代未變（期間沒有 clear）時才放入
+func (c *routeCache) putIfGeneration(gen uint64, key string, handlers []hypcontext.HandlerFunc, fullPath string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	if c.gen.Load() == gen {
+		c.putLocked(key, handlers, fullPath)
+	}
+}
 
+func (c *routeCache) putLocked(key string, handlers []hypcontext.HandlerFunc, fullPath string) {
 	// 已存在 → 更新
 	if entry, exists := c.items[key]; exists {
 		entry.handlers = handlers
-		entry.params = params
+		entry.fullPath = fullPath
 		c.moveToHead(entry)
 		return
 	}
@@ -77,7 +110,7 @@ func (c *routeCache) put(key string, handlers []hypcontext.HandlerFunc, params [
 	entry := cacheItemPool.Get().(*cacheItem)
 	entry.key = key
 	entry.handlers = handlers
-	entry.params = params
+	entry.fullPath = fullPath
 	entry.prev = nil
 	entry.next = nil
 
@@ -91,6 +124,16 @@ func (c *routeCache) put(key string, handlers []hypcontext.HandlerFunc, params [
 	}
 }
 
+// clear 清空快取（路由變更後使用）
+func (c *routeCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.items = make(map[string]*cacheItem, c.capacity)
+	c.head, c.tail = nil, nil
+	c.size = 0
+	c.gen.Add(1)
+}
+
 // 雙向鏈表操作
 func (c *routeCache) moveToHead(entry *cacheItem) {
 	c.removeEntry(entry)
@@ -136,9 +179,35 @@ func (c *routeCache) removeTail() {
 
 	// GC 優化：歸還被淘汰的 cacheItem 到 pool
 	evicted.key = ""
-	evicted.handlers = nil
-	evicted.params = nil
+	evicted.cachedRoute = cachedRoute{}
 	evicted.prev = nil
 	evicted.next = nil
 	cacheItemPool.Put(evicted)
 }
+
+// extractParams 依路由模板從已匹配的路徑提取參數（快取命中時使用，不需遍歷 Radix Tree）
+// 提取規則與 lookup 相同：:param 取到下一個 '/'，*catchAll 取剩餘路徑並補回前導 '/'
+func extractParams(template, path string, params []Param) []Param {
+	i, j := 0, 0
+	for i < len(template) && j <= len(path) {
+		switch template[i] {
+		case ':':
+			nameEnd := i + 1
+			for nameEnd < len(template) && template[nameEnd] != '/' {
+				nameEnd++
+			}
+			valueEnd := j
+			for valueEnd < len(path) && path[valueEnd] != '/' {
+				valueEnd++
+			}
+			params = append(params, Param{Key: template[i+1 : nameEnd], Value: path[j:valueEnd]})
+			i, j = nameEnd, valueEnd
+		case '*':
+			return append(params, Param{Key: template[i+1:], Value: "/" + path[j:]})
+		default:
+			i++
+			j++
+		}
+	}
+	return params
+}
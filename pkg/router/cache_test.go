@@ -1,6 +1,11 @@
 package router
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
@@ -12,47 +17,185 @@ func TestRouteCache(t *testing.T) {
 	dummyHandler := func(c *hypcontext.Context) {}
 
 	// Test Get on empty cache
-	if entry := cache.get("/test"); entry != nil {
+	if _, ok := cache.get("/test"); ok {
 		t.Errorf("Expected nil for non-existent key")
 	}
 
 	// Test Put
-	cache.put("/a", []hypcontext.HandlerFunc{dummyHandler}, nil)
-	if entry := cache.get("/a"); entry == nil {
+	cache.put("/a", []hypcontext.HandlerFunc{dummyHandler}, "")
+	if _, ok := cache.get("/a"); !ok {
 		t.Errorf("Expected entry for key '/a'")
 	}
 
 	// Test Capacity and Eviction (LRU)
-	cache.put("/b", []hypcontext.HandlerFunc{dummyHandler}, nil)
-	cache.put("/c", []hypcontext.HandlerFunc{dummyHandler}, nil)
+	cache.put("/b", []hypcontext.HandlerFunc{dummyHandler}, "")
+	cache.put("/c", []hypcontext.HandlerFunc{dummyHandler}, "")
 
 	// Since capacity is 2, "/a" should be evicted
-	if entry := cache.get("/a"); entry != nil {
+	if _, ok := cache.get("/a"); ok {
 		t.Errorf("Expected '/a' to be evicted")
 	}
 
-	if entry := cache.get("/b"); entry == nil {
+	if _, ok := cache.get("/b"); !ok {
 		t.Errorf("Expected entry for key '/b'")
 	}
-	if entry := cache.get("/c"); entry == nil {
+	if _, ok := cache.get("/c"); !ok {
 		t.Errorf("Expected entry for key '/c'")
 	}
 
 	// Test LRU update on Get
-	cache.get("/b")                                              // "/b" is now recently used
-	cache.put("/d", []hypcontext.HandlerFunc{dummyHandler}, nil) // should evict "/c"
+	cache.get("/b")                                             // "/b" is now recently used
+	cache.put("/d", []hypcontext.HandlerFunc{dummyHandler}, "") // should evict "/c"
 
-	if entry := cache.get("/c"); entry != nil {
+	if _, ok := cache.get("/c"); ok {
 		t.Errorf("Expected '/c' to be evicted")
 	}
-	if entry := cache.get("/b"); entry == nil {
+	if _, ok := cache.get("/b"); !ok {
 		t.Errorf("Expected entry for key '/b'")
 	}
 
 	// Test Update existing key
-	cache.put("/b", []hypcontext.HandlerFunc{dummyHandler, dummyHandler}, nil)
-	entry := cache.get("/b")
-	if len(entry.handlers) != 2 {
+	cache.put("/b", []hypcontext.HandlerFunc{dummyHandler, dummyHandler}, "")
+	route, _ := cache.get("/b")
+	if len(route.handlers) != 2 {
 		t.Errorf("Expected entry to be updated with 2 handlers")
 	}
 }
+
+func TestExtractParams(t *testing.T) {
+	tests := []struct {
+		template, path string
+		want           []Param
+	}{
+		{"/health", "/health", nil},
+		{"/users/:id", "/users/42", []Param{{"id", "42"}}},
+		{"/a/:x/b/:y/c", "/a/1/b/2/c", []Param{{"x", "1"}, {"y", "2"}}},
+		{"/:a/:b", "/x/y", []Param{{"a", "x"}, {"b", "y"}}},
+		{"/static/*filepath", "/static/css/a.css", []Param{{"filepath", "/css/a.css"}}},
+		{"/users/:id/*rest", "/users/7/", []Param{{"id", "7"}, {"rest", "/"}}},
+	}
+	for _, tt := range tests {
+		got := extractParams(tt.template, tt.path, nil)
+		if len(got) != len(tt.want) {
+			t.Errorf("%s on %s: got %v, want %v", tt.template, tt.path, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s on %s: got %v, want %v", tt.template, tt.path, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestRouterCacheParamRoutes(t *testing.T) {
+	r := New(WithCache(2), WithParamRouteCache())
+	r.GET("/users/:id/posts/:pid", func(c *hypcontext.Context) {
+		c.String(200, c.FullPath()+"|"+c.Param("id")+"|"+c.Param("pid"))
+	})
+
+	// 連續請求相同與不同路徑，命中快取時參數仍為本次請求的值
+	for _, path := range []string{"/users/1/posts/2", "/users/1/posts/2", "/users/3/posts/4", "/users/1/posts/2", "/users/5/posts/6"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		parts := strings.Split(path, "/")
+		if want := "/users/:id/posts/:pid|" + parts[2] + "|" + parts[4]; w.Body.String() != want {
+			t.Errorf("%s: got %q, want %q", path, w.Body.String(), want)
+		}
+	}
+	if _, ok := r.cache.get("GET/users/5/posts/6"); !ok {
+		t.Error("Expected parameterized route to be cached")
+	}
+}
+
+// BenchmarkRouterParamRoute /users/:id 流量：啟用參數路由快取時同一路徑重複命中（hit）
+// 與每次請求不同 id（distinct，快取只會 miss）；nocache 為預設行為，每次遍歷樹
+func BenchmarkRouterParamRoute(b *testing.B) {
+	handler := func(c *hypcontext.Context) {}
+	paths := make([]string, 4096)
+	for i := range paths {
+		paths[i] = "/users/" + strconv.Itoa(i)
+	}
+
+	for _, bc := range []struct {
+		name     string
+		cache    bool // WithParamRouteCache
+		distinct bool
+	}{
+		{"cache/hit", true, false},
+		{"cache/distinct", true, true},
+		{"nocache/same", false, false},
+		{"nocache/distinct", false, true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			r := New()
+			r.cacheParams = bc.cache
+			for _, path := range []string{"/users", "/users/:id", "/users/:id/posts", "/orders/:id", "/products/:sku/reviews/:rid"} {
+				r.GET(path, handler)
+			}
+			reqs := make([]*http.Request, len(paths))
+			for i, path := range paths {
+				reqs[i] = httptest.NewRequest("GET", path, nil)
+			}
+			w := httptest.NewRecorder()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				req := reqs[0]
+				if bc.distinct {
+					req = reqs[i%len(reqs)]
+				}
+				r.ServeHTTP(w, req)
+			}
+		})
+	}
+}
+
+// TestRouteCacheConcurrent 以 -race 執行：get / put / clear 並行時鏈表與回傳值保持一致
+func TestRouteCacheConcurrent(t *testing.T) {
+	cache := newRouteCache(8)
+	handler := []hypcontext.HandlerFunc{func(c *hypcontext.Context) {}}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < 2000; i++ {
+				key := "/k/" + strconv.Itoa((g*7+i)%32)
+				if route, ok := cache.get(key); ok && route.fullPath != key {
+					t.Errorf("get(%q) returned route for %q", key, route.fullPath)
+					return
+				}
+				cache.putIfGeneration(cache.generation(), key, handler, key)
+				if i%500 == 0 {
+					cache.clear()
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	if cache.size > cache.capacity || cache.size != len(cache.items) {
+		t.Errorf("Inconsistent cache: size=%d items=%d capacity=%d", cache.size, len(cache.items), cache.capacity)
+	}
+	n := 0
+	for e := cache.head; e != nil; e = e.next {
+		n++
+	}
+	if n != cache.size {
+		t.Errorf("Linked list has %d entries, size is %d", n, cache.size)
+	}
+}
+
+func TestRouteCacheStaleGeneration(t *testing.T) {
+	cache := newRouteCache(4)
+	gen := cache.generation()
+	cache.clear()
+	cache.putIfGeneration(gen, "/a", nil, "/*path")
+	if _, ok := cache.get("/a"); ok {
+		t.Error("Expected put from a stale generation to be dropped")
+	}
+}
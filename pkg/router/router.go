@@ -2,11 +2,13 @@
 package router
 
 import (
+	"fmt"
 	"net/http"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"unsafe"
 
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
@@ -14,16 +16,23 @@ import (
 )
 
 // Router 主結構
+//
+// 路由建議在開始服務前註冊完畢；服務中動態新增路由或全域中間件也是安全的：
+// 註冊以 mu 序列化，開始服務後改為 copy-on-write，複製樹、修改後以原子指標發布，
+// 請求查找只讀取已發布的樹，不需加鎖
 type Router struct {
-	Group                              // 嵌入根路由組
-	trees     map[string]*radixNode    // 每個 HTTP 方法一棵 Radix Tree
-	cache     *routeCache              // LRU 路由快取
-	paramPool *sync.Pool               // 參數對象池
-	globalMW  []hypcontext.HandlerFunc // 全域中間件（獨立於 Group 的中間件）
+	Group                                              // 嵌入根路由組
+	trees     atomic.Pointer[methodTrees]              // 每個 HTTP 方法一棵 Radix Tree
+	cache     *routeCache                              // LRU 路由快取
+	paramPool *sync.Pool                               // 參數對象池
+	globalMW  atomic.Pointer[[]hypcontext.HandlerFunc] // 全域中間件（獨立於 Group 的中間件）
+	mu        sync.Mutex                               // 序列化路由與全域中間件的註冊
+	serving   atomic.Bool                              // 已開始服務，之後的註冊改為 copy-on-write
 
 	// 配置
 	maxParams              int
 	enableCache            bool
+	cacheParams            bool // 參數路由也放入快取
 	cacheSize              int
 	caseSensitive          bool
 	strictSlash            bool
@@ -35,8 +44,15 @@ type Router struct {
 	// 404/405 處理器
 	notFound         hypcontext.HandlerFunc
 	methodNotAllowed hypcontext.HandlerFunc
+
+	// 內建 panic 恢復（WithRecovery 啟用）
+	recoveryEnabled bool
+	recoveryHandler RecoveryHandler
 }
 
+// RecoveryHandler 路由器內建恢復的錯誤處理器，err 為 recover() 取得的值
+type RecoveryHandler func(c *hypcontext.Context, err interface{})
+
 // HTTP3Config HTTP/3 配置
 type HTTP3Config struct {
 	Enabled            bool
@@ -65,6 +81,16 @@ func WithCache(size int) RouterOption {
 	}
 }
 
+// WithParamRouteCache 參數路由也放入快取（預設只快取靜態路由）
+// 快取以實際路徑為鍵，只存路由模板與 handler 鏈，參數值於命中時依模板重新提取。
+// Radix Tree 查找本身已很快，/users/:id 這類高基數路徑幾乎不會命中，反而增加淘汰成本
+// （見 BenchmarkRouterParamRoute）；適合參數值種類少且重複的流量，如 /lang/:code
+func WithParamRouteCache() RouterOption {
+	return func(r *Router) {
+		r.cacheParams = true
+	}
+}
+
 // WithMaxParams 設置最大參數數
 func WithMaxParams(n int) RouterOption {
 	return func(r *Router) {
@@ -86,6 +112,15 @@ func WithMethodNotAllowed(enabled bool) RouterOption {
 	}
 }
 
+// WithRecovery 啟用路由器內建的 panic 恢復，保護未掛 Recovery 中間件的路由
+// handler 為 nil 時回傳 500；已掛 middleware.Recovery 時由中間件優先處理
+func WithRecovery(handler RecoveryHandler) RouterOption {
+	return func(r *Router) {
+		r.recoveryEnabled = true
+		r.recoveryHandler = handler
+	}
+}
+
 // New 創建新的路由器
 // EX:
 //
@@ -96,9 +131,7 @@ func WithMethodNotAllowed(enabled bool) RouterOption {
 //	api.GET("/users", listUsers)
 func New(opts ...RouterOption) *Router {
 	r := &Router{
-		trees:                  make(map[string]*radixNode),
 		cache:                  newRouteCache(1000),
-		globalMW:               make([]hypcontext.HandlerFunc, 0),
 		maxParams:              10,
 		enableCache:            true,
 		cacheSize:              1000,
@@ -113,6 +146,9 @@ func New(opts ...RouterOption) *Router {
 		},
 	}
 
+	r.trees.Store(&methodTrees{})
+	r.globalMW.Store(&[]hypcontext.HandlerFunc{})
+
 	// 初始化嵌入的根 Group
 	r.Group = Group{
 		basePath:   "/",
@@ -142,13 +178,34 @@ func (r *Router) addRoute(method, absolutePath string, handlers []hypcontext.Han
 		panic("router: must provide at least one handler")
 	}
 
-	// 獲取或創建方法樹
-	if r.trees[method] == nil {
-		r.trees[method] = &radixNode{nType: root}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	trees := *r.trees.Load()
+	tree := trees[method]
+
+	// 尚未服務時沒有讀者，直接修改；服務中則複製後再發布，衝突 panic 時已發布的樹不受影響
+	if r.serving.Load() {
+		next := make(methodTrees, len(trees)+1)
+		for m, t := range trees {
+			next[m] = t
+		}
+		trees = next
+		if tree != nil {
+			tree = tree.clone()
+		}
+	}
+	if tree == nil {
+		tree = &radixNode{nType: root}
 	}
+	tree.addRoute(absolutePath, handlers)
+	trees[method] = tree
+	r.trees.Store(&trees)
 
-	root := r.trees[method]
-	root.addRoute(absolutePath, handlers)
+	// 新路由可能改變既有路徑的匹配結果（如原本落入 catch-all 的路徑）
+	if r.serving.Load() && r.cache != nil {
+		r.cache.clear()
+	}
 
 	// 更新最大參數數
 	if pc := countParams(absolutePath); pc > r.maxParams {
@@ -156,41 +213,68 @@ func (r *Router) addRoute(method, absolutePath string, handlers []hypcontext.Han
 	}
 }
 
+// methodTrees HTTP 方法 → Radix Tree；開始服務後已發布的 map 與樹不再修改
+type methodTrees map[string]*radixNode
+
+// tree 返回方法對應的樹
+func (r *Router) tree(method string) *radixNode {
+	return (*r.trees.Load())[method]
+}
+
+// markServing 第一次服務請求時呼叫；取得 mu 可確保進行中的原地註冊先完成
+func (r *Router) markServing() {
+	if !r.serving.Load() {
+		r.mu.Lock()
+		r.serving.Store(true)
+		r.mu.Unlock()
+	}
+}
+
 // ServeHTTP 實現 http.Handler 介面
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.markServing()
 	c := hypcontext.New(w, req)
 	defer c.Release()
 
 	urlPath := req.URL.Path
 	method := req.Method
 
-	// HTTP/3 Alt-Svc 標頭
+	// HTTP/3 Alt-Svc 標頭（server 已依實際 UDP 埠處理時不覆寫）
 	if r.http3Config != nil && r.http3Config.Enabled {
-		w.Header().Set("Alt-Svc", `h3=":443"; ma=2592000`)
+		if _, set := w.Header()["Alt-Svc"]; !set {
+			w.Header().Set("Alt-Svc", `h3=":443"; ma=2592000`)
+		}
 	}
 
 	// 快取查找
 	if r.enableCache {
-		cacheKey := method + urlPath
-		if entry := r.cache.get(cacheKey); entry != nil {
-			c.Params = r.makeContextParams(entry.params)
-			r.executeHandlers(c, entry.handlers)
+		if route, ok := r.cache.get(method + urlPath); ok {
+			params := extractParams(route.fullPath, urlPath, r.getParams())
+			c.Params = r.makeContextParams(params)
+			c.SetFullPath(route.fullPath)
+			r.executeHandlers(c, route.handlers)
+			r.putParams(params)
 			return
 		}
 	}
 
-	// Radix Tree 查找
-	if root := r.trees[method]; root != nil {
-		handlers, params := root.search(urlPath, r.getParams())
-		if handlers != nil {
+	// Radix Tree 查找（快取世代須在讀取樹之前取得）
+	var cacheGen uint64
+	if r.enableCache {
+		cacheGen = r.cache.generation()
+	}
+	if root := r.tree(method); root != nil {
+		leaf, params := root.lookup(urlPath, r.getParams())
+		if leaf != nil {
 			c.Params = r.makeContextParams(params)
+			c.SetFullPath(leaf.fullPath)
 
-			// 只快取靜態路由（無參數）
-			if r.enableCache && len(params) == 0 {
-				r.cache.put(method+urlPath, handlers, params)
+			// 快取模板與 handler 鏈，參數值於命中時重新提取
+			if r.enableCache && (len(params) == 0 || r.cacheParams) {
+				r.cache.putIfGeneration(cacheGen, method+urlPath, leaf.handlers, leaf.fullPath)
 			}
 
-			r.executeHandlers(c, handlers)
+			r.executeHandlers(c, leaf.handlers)
 			r.putParams(params)
 			return
 		}
@@ -199,11 +283,12 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 	// HEAD 自動回應：若無 HEAD handler，使用 GET handler
 	if method == "HEAD" {
-		if root := r.trees["GET"]; root != nil {
-			handlers, params := root.search(urlPath, r.getParams())
-			if handlers != nil {
+		if root := r.tree("GET"); root != nil {
+			leaf, params := root.lookup(urlPath, r.getParams())
+			if leaf != nil {
 				c.Params = r.makeContextParams(params)
-				r.executeHandlers(c, handlers)
+				c.SetFullPath(leaf.fullPath)
+				r.executeHandlers(c, leaf.handlers)
 				r.putParams(params)
 				return
 			}
@@ -219,7 +304,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		} else {
 			tryPath = urlPath + "/"
 		}
-		if root := r.trees[method]; root != nil {
+		if root := r.tree(method); root != nil {
 			if handlers, _ := root.search(tryPath, nil); handlers != nil {
 				req.URL.Path = tryPath
 				http.Redirect(w, req, tryPath, http.StatusMovedPermanently)
@@ -230,7 +315,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 
 	// 405 Method Not Allowed
 	if r.handleMethodNotAllowed {
-		for m, tree := range r.trees {
+		for m, tree := range *r.trees.Load() {
 			if m == method {
 				continue
 			}
@@ -262,27 +347,63 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 //	r := router.New()
 //	r.Use(loggerMiddleware, recoveryMiddleware)
 func (r *Router) Use(middleware ...hypcontext.HandlerFunc) {
-	r.globalMW = append(r.globalMW, middleware...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := *r.globalMW.Load()
+	next := make([]hypcontext.HandlerFunc, 0, len(current)+len(middleware))
+	next = append(next, current...)
+	next = append(next, middleware...)
+	r.globalMW.Store(&next)
 }
 
 // executeHandlers 執行處理器鏈
 // 順序：全域中間件 → (Group 中間件 + 路由 Handler)，其中 Group 中間件已在 Group.handle() 中與 Handler 合併
+// 整條鏈交由 c.Next() 驅動，中間件可在 c.Next() 前後執行邏輯
 func (r *Router) executeHandlers(c *hypcontext.Context, handlers []hypcontext.HandlerFunc) {
-	// 1. 全域中間件
-	for _, h := range r.globalMW {
-		h(c)
-		if c.Response.Written() {
-			return
-		}
+	if r.recoveryEnabled {
+		defer r.recoverPanic(c)
 	}
 
-	// 2. Group 中間件 + 路由 Handler（已合併為一個 slice）
-	for _, h := range handlers {
-		h(c)
-		if c.Response.Written() {
-			return
-		}
+	chain := handlers
+	if globalMW := *r.globalMW.Load(); len(globalMW) > 0 {
+		chain = make([]hypcontext.HandlerFunc, 0, len(globalMW)+len(handlers))
+		chain = append(chain, globalMW...)
+		chain = append(chain, handlers...)
 	}
+
+	c.SetHandlers(chain)
+	c.Next()
+}
+
+// recoverPanic 內建恢復：記錄 panic 與堆疊，交由 recoveryHandler 或回傳 500
+// http.ErrAbortHandler 為 net/http 的中止信號，不攔截
+func (r *Router) recoverPanic(c *hypcontext.Context) {
+	err := recover()
+	if err == nil {
+		return
+	}
+	if err == http.ErrAbortHandler {
+		panic(err)
+	}
+
+	stack := make([]byte, 4<<10)
+	stack = stack[:runtime.Stack(stack, false)]
+	// 經請求 logger 輸出（含 request_id / method / path），與應用的日誌設定一致
+	c.Logger().Error("panic recovered",
+		"panic", fmt.Sprint(err),
+		"stack", string(stack),
+	)
+
+	if r.recoveryHandler != nil {
+		r.recoveryHandler(c, err)
+		return
+	}
+	if !c.Response.Written() {
+		c.AbortWithStatus(http.StatusInternalServerError)
+		return
+	}
+	c.Abort()
 }
 
 // NotFound 設置 404 處理器
@@ -362,7 +483,7 @@ type RouteInfo struct {
 // Routes 返回已註冊的所有路由信息
 func (r *Router) Routes() []RouteInfo {
 	routes := make([]RouteInfo, 0)
-	for method, root := range r.trees {
+	for method, root := range *r.trees.Load() {
 		routes = collectRoutes("", method, routes, root)
 	}
 	return routes
@@ -389,7 +510,7 @@ func collectRoutes(prefix, method string, routes []RouteInfo, n *radixNode) []Ro
 	for _, child := range n.children {
 		routes = collectRoutes(fullPath, method, routes, child)
 	}
-	return routes
+	return collectRoutes(fullPath, method, routes, n.catchAllChild)
 }
 
 // Schema 開始 schema-first 路由註冊
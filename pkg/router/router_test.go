@@ -1,11 +1,18 @@
 package router
 
 import (
+	"bytes"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
 )
 
 func TestRouter_Use(t *testing.T) {
@@ -153,6 +160,43 @@ func TestRouter_CatchAllWithParam(t *testing.T) {
 	}
 }
 
+func TestRouter_FullPath(t *testing.T) {
+	r := New()
+	echo := func(c *hypcontext.Context) {
+		c.String(200, c.FullPath())
+	}
+	r.GET("/users/:id", echo)
+	r.GET("/users/:id/posts", echo)
+	r.GET("/static/app.js", echo)
+	r.GET("/files/*filepath", echo)
+
+	cases := map[string]string{
+		"/users/42":       "/users/:id",
+		"/users/42/posts": "/users/:id/posts",
+		"/static/app.js":  "/static/app.js",
+		"/files/a/b.txt":  "/files/*filepath",
+	}
+	for path, want := range cases {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		r.ServeHTTP(w, req)
+		if w.Body.String() != want {
+			t.Errorf("%s: expected FullPath %q, got %q", path, want, w.Body.String())
+		}
+	}
+
+	// 未匹配（404）時為空，避免以具體路徑作為指標標籤
+	r.NotFound(func(c *hypcontext.Context) {
+		c.String(404, "["+c.FullPath()+"]")
+	})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/missing/42", nil)
+	r.ServeHTTP(w, req)
+	if w.Code != 404 || w.Body.String() != "[]" {
+		t.Errorf("Expected empty FullPath for unmatched request, got %d %q", w.Code, w.Body.String())
+	}
+}
+
 func TestRouter_EnableHTTP3(t *testing.T) {
 	r := New()
 	r.EnableHTTP3(nil) // Default config
@@ -188,3 +232,313 @@ func TestRouter_Routes(t *testing.T) {
 		t.Errorf("Expected 2 routes, got %d", len(routes))
 	}
 }
+
+func TestRouter_WithRecovery(t *testing.T) {
+	r := New(WithRecovery(nil))
+	r.GET("/panic", func(c *hypcontext.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("Expected 500, got %d", w.Code)
+	}
+}
+
+func TestRouter_WithRecoveryLogsPanic(t *testing.T) {
+	var out bytes.Buffer
+	log, _ := logger.New("debug", "", &out, false)
+	log.SetFormat("json")
+
+	r := New(WithRecovery(nil))
+	r.Use(func(c *hypcontext.Context) {
+		c.AttachLogger(log)
+		c.Next()
+	})
+	r.GET("/panic", func(c *hypcontext.Context) {
+		panic("boom")
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	var record map[string]interface{}
+	if err := json.Unmarshal(out.Bytes(), &record); err != nil {
+		t.Fatalf("Expected one JSON record, got %q: %v", out.String(), err)
+	}
+	stack, _ := record["stack"].(string)
+	if record["msg"] != "panic recovered" || record["panic"] != "boom" || record["path"] != "/panic" || !strings.Contains(stack, "goroutine") {
+		t.Errorf("unexpected log record: %v", record)
+	}
+}
+
+func TestRouter_WithRecoveryCustomHandler(t *testing.T) {
+	var recovered interface{}
+	r := New(WithRecovery(func(c *hypcontext.Context, err interface{}) {
+		recovered = err
+		c.String(http.StatusServiceUnavailable, "recovered")
+	}))
+	r.GET("/panic", func(c *hypcontext.Context) {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if recovered != "boom" {
+		t.Errorf("Expected recovered value 'boom', got %v", recovered)
+	}
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "recovered" {
+		t.Errorf("Expected 503 'recovered', got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRouter_WithoutRecoveryPanics(t *testing.T) {
+	r := New()
+	r.GET("/panic", func(c *hypcontext.Context) {
+		panic("boom")
+	})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic to propagate without WithRecovery")
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+}
+
+func TestRouter_MiddlewareAfterNext(t *testing.T) {
+	r := New()
+	var order []string
+	var elapsed time.Duration
+
+	r.Use(func(c *hypcontext.Context) {
+		start := time.Now()
+		order = append(order, "global-before")
+		c.Next()
+		elapsed = time.Since(start)
+		order = append(order, "global-after")
+	})
+
+	api := r.NewGroup("/api", func(c *hypcontext.Context) {
+		order = append(order, "group-before")
+		c.Next()
+		order = append(order, "group-after")
+	})
+	api.GET("/slow", func(c *hypcontext.Context) {
+		time.Sleep(5 * time.Millisecond)
+		order = append(order, "handler")
+		c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/slow", nil))
+
+	want := []string{"global-before", "group-before", "handler", "group-after", "global-after"}
+	if strings.Join(order, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected order %v, got %v", want, order)
+	}
+	if elapsed < 5*time.Millisecond {
+		t.Errorf("Expected duration recorded after Next to include handler time, got %v", elapsed)
+	}
+}
+
+func TestRouter_AbortStopsChain(t *testing.T) {
+	r := New()
+	protectedHit := false
+
+	auth := func(c *hypcontext.Context) {
+		if c.GetHeader("Authorization") == "" {
+			c.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+		c.Next()
+	}
+	r.GET("/protected", auth, func(c *hypcontext.Context) {
+		protectedHit = true
+		c.String(http.StatusOK, "secret")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/protected", nil))
+
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d", w.Code)
+	}
+	if protectedHit {
+		t.Error("Protected handler should not run after AbortWithStatus")
+	}
+}
+
+func TestRouter_AbortWithoutWriteStopsChain(t *testing.T) {
+	r := New()
+	handlerHit := false
+
+	// 僅呼叫 Abort、不寫出回應，也必須中止後續處理器
+	r.Use(func(c *hypcontext.Context) {
+		c.Abort()
+	})
+	r.GET("/test", func(c *hypcontext.Context) {
+		handlerHit = true
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	if handlerHit {
+		t.Error("Handler should not run after Abort")
+	}
+}
+
+func TestRouter_RouteConflictMessages(t *testing.T) {
+	h := func(c *hypcontext.Context) {}
+	cases := []struct {
+		existing, added string
+	}{
+		{"/users/:id", "/users/:name"},
+		{"/users/:id", "/users/new"},
+		{"/users/new", "/users/:id"},
+		{"/static/*filepath", "/static/*path"},
+		{"/orders/:id/items", "/orders/:oid"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.added, func(t *testing.T) {
+			r := New()
+			r.GET(tc.existing, h)
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, "'"+tc.added+"'") || !strings.Contains(msg, "'"+tc.existing+"'") {
+					t.Errorf("Expected panic naming both %q and %q, got %q", tc.existing, tc.added, msg)
+				}
+			}()
+			r.GET(tc.added, h)
+		})
+	}
+}
+
+func TestRouter_DuplicateRoute(t *testing.T) {
+	h := func(c *hypcontext.Context) {}
+	for _, path := range []string{"/health", "/users/:id", "/assets/*filepath"} {
+		r := New()
+		r.GET(path, h)
+		r.POST(path, h) // 不同方法不衝突
+		func() {
+			defer func() {
+				msg, _ := recover().(string)
+				if !strings.Contains(msg, "duplicate route '"+path+"'") {
+					t.Errorf("Expected duplicate route panic for %q, got %q", path, msg)
+				}
+			}()
+			r.GET(path, h)
+		}()
+	}
+}
+
+// TestRouter_TreeTopologies 多參數段、參數後接多個靜態子節點、catch-all 與靜態路由並存
+func TestRouter_TreeTopologies(t *testing.T) {
+	tests := []struct {
+		name   string
+		routes []string
+		path   string
+		want   string // 命中的路由模板，空字串表示 404
+		params string // key=value 以逗號連接
+	}{
+		{"consecutive params", []string{"/:a/:b/:c"}, "/x/y/z", "/:a/:b/:c", "a=x,b=y,c=z"},
+		{"params between statics", []string{"/a/:x/b/:y/c", "/a/:x/b"}, "/a/1/b/2/c", "/a/:x/b/:y/c", "x=1,y=2"},
+		{"params between statics prefix", []string{"/a/:x/b/:y/c", "/a/:x/b"}, "/a/1/b", "/a/:x/b", "x=1"},
+		{"params between statics partial", []string{"/a/:x/b/:y/c"}, "/a/1/b/2", "", ""},
+		{"param with static children", []string{"/users/:id", "/users/:id/posts", "/users/:id/profile"}, "/users/7/profile", "/users/:id/profile", "id=7"},
+		{"param with static children leaf", []string{"/users/:id", "/users/:id/posts", "/users/:id/profile"}, "/users/7", "/users/:id", "id=7"},
+		{"param with static children miss", []string{"/users/:id", "/users/:id/posts"}, "/users/7/other", "", ""},
+		{"empty param", []string{"/users/:id"}, "/users/", "", ""},
+		{"catch-all sibling static wins", []string{"/api/users", "/*path"}, "/api/users", "/api/users", ""},
+		{"catch-all sibling fallback", []string{"/api/users", "/*path"}, "/api/orders", "/*path", "path=/api/orders"},
+		{"catch-all sibling root", []string{"/api/users", "/*path"}, "/", "/*path", "path=/"},
+		{"catch-all registered first", []string{"/*path", "/api/users", "/v1/:id/items"}, "/v1/9/other", "/*path", "path=/v1/9/other"},
+		{"catch-all drops params on fallback", []string{"/files/*filepath", "/files/:id/meta"}, "/files/1/data", "/files/*filepath", "filepath=/1/data"},
+		{"param beats catch-all", []string{"/files/*filepath", "/files/:id/meta"}, "/files/1/meta", "/files/:id/meta", "id=1"},
+		{"deepest catch-all wins", []string{"/*path", "/static/*filepath"}, "/static/css/a.css", "/static/*filepath", "filepath=/css/a.css"},
+		{"catch-all trailing slash", []string{"/static/*filepath"}, "/static/", "/static/*filepath", "filepath=/"},
+		{"catch-all needs its prefix", []string{"/static/*filepath"}, "/staticx", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			for _, route := range tt.routes {
+				r.GET(route, func(c *hypcontext.Context) {
+					var params []string
+					for _, p := range c.Params {
+						params = append(params, p.Key+"="+p.Value)
+					}
+					c.String(200, c.FullPath()+"|"+strings.Join(params, ","))
+				})
+			}
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if tt.want == "" {
+				if w.Code != http.StatusNotFound {
+					t.Errorf("%s: expected 404, got %d %q", tt.path, w.Code, w.Body.String())
+				}
+				return
+			}
+			if got := w.Body.String(); got != tt.want+"|"+tt.params {
+				t.Errorf("%s: got %q, want %q", tt.path, got, tt.want+"|"+tt.params)
+			}
+		})
+	}
+}
+
+// TestRouter_AddRouteWhileServing 以 -race 執行：服務中新增路由與全域中間件
+func TestRouter_AddRouteWhileServing(t *testing.T) {
+	r := New(WithParamRouteCache())
+	r.GET("/*path", func(c *hypcontext.Context) { c.String(200, "fallback") })
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, httptest.NewRequest("GET", "/dyn/3", nil))
+				if w.Code != 200 {
+					t.Errorf("Unexpected status %d", w.Code)
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		path := "/dyn/" + strconv.Itoa(i)
+		r.GET(path, func(c *hypcontext.Context) { c.String(200, path) })
+		r.Use(func(c *hypcontext.Context) { c.Next() })
+	}
+	close(stop)
+	wg.Wait()
+
+	// 新增的靜態路由優先於先前快取的 catch-all 結果
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/dyn/3", nil))
+	if w.Body.String() != "/dyn/3" {
+		t.Errorf("Expected newly added route after cache reset, got %q", w.Body.String())
+	}
+
+	// 服務中註冊衝突路由會 panic，但已發布的樹不受影響
+	func() {
+		defer func() { recover() }()
+		r.GET("/dyn/:id", func(c *hypcontext.Context) {})
+	}()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/dyn/7", nil))
+	if w.Body.String() != "/dyn/7" {
+		t.Errorf("Expected tree intact after failed registration, got %q", w.Body.String())
+	}
+}
@@ -18,95 +18,121 @@ const (
 
 // radixNode Radix Tree 節點
 type radixNode struct {
-	path      string                   // 該節點對應的路徑段
-	indices   string                   // 子節點的第一個字元索引（用於快速查找）
-	wildChild bool                     // 是否有通配符子節點
-	nType     nodeType                 // 節點類型
-	priority  uint32                   // 優先級（命中次數，用於子節點排序）
-	children  []*radixNode             // 子節點列表
-	handlers  []hypcontext.HandlerFunc // 處理器鏈
-	fullPath  string                   // 完整路徑（用於衝突提示）
+	path          string                   // 該節點對應的路徑段
+	indices       string                   // 子節點的第一個字元索引（用於快速查找）
+	wildChild     bool                     // 是否有 :param 子節點（與靜態子節點互斥）
+	nType         nodeType                 // 節點類型
+	priority      uint32                   // 優先級（命中次數，用於子節點排序）
+	children      []*radixNode             // 子節點列表
+	catchAllChild *radixNode               // *catchAll 子節點，可與靜態 / 參數子節點並存，其他匹配失敗時才使用
+	handlers      []hypcontext.HandlerFunc // 處理器鏈
+	fullPath      string                   // 完整路由模板（用於衝突提示與 Context.FullPath）
 }
 
 // search 在 Radix Tree 中搜索匹配的路由
 // 返回匹配的 handlers 和提取的路徑參數
 func (n *radixNode) search(path string, params []Param) ([]hypcontext.HandlerFunc, []Param) {
+	leaf, p := n.lookup(path, params)
+	if leaf == nil {
+		return nil, p
+	}
+	return leaf.handlers, p
+}
+
+// lookup 搜索匹配的節點，未匹配時返回 nil
+// 靜態與參數匹配優先；途經的 catch-all 會被記下，之後任何分支失敗都退回最深的那一個
+func (n *radixNode) lookup(path string, params []Param) (*radixNode, []Param) {
 	p := params
 
+	var (
+		fallback     *radixNode // 最近途經的 catch-all
+		fallbackPath string     // 該 catch-all 要捕獲的剩餘路徑
+		fallbackLen  int        // 當時已提取的參數數
+	)
+	miss := func() (*radixNode, []Param) {
+		if fallback == nil {
+			return nil, p
+		}
+		p = append(p[:fallbackLen], Param{
+			Key:   fallback.path[1:],  // 去掉前導 '*'
+			Value: "/" + fallbackPath, // 補回被父節點消費的前導 '/'
+		})
+		return fallback, p
+	}
+
 walk:
 	for {
-		if len(path) > len(n.path) {
-			if path[:len(n.path)] == n.path {
-				path = path[len(n.path):]
-
-				// 非通配符子節點 → 用 indices 快速查找
-				if !n.wildChild {
-					c := path[0]
-					for i, index := range []byte(n.indices) {
-						if c == index {
-							n = n.children[i]
-							continue walk
-						}
-					}
-					// 沒找到匹配的子節點
-					return nil, p
+		if len(path) < len(n.path) || path[:len(n.path)] != n.path {
+			return miss()
+		}
+		path = path[len(n.path):]
+
+		if n.catchAllChild != nil {
+			fallback, fallbackPath, fallbackLen = n.catchAllChild, path, len(p)
+		}
+
+		if path == "" {
+			// 完全匹配
+			if n.handlers != nil {
+				return n, p
+			}
+			return miss()
+		}
+
+		// 非通配符子節點 → 用 indices 快速查找
+		if !n.wildChild {
+			c := path[0]
+			for i, index := range []byte(n.indices) {
+				if c == index {
+					n = n.children[i]
+					continue walk
 				}
+			}
+			return miss()
+		}
 
-				// 處理通配符子節點
+		// 提取 :param 值（到下一個 / 為止）
+		n = n.children[0]
+		end := 0
+		for end < len(path) && path[end] != '/' {
+			end++
+		}
+		if end == 0 {
+			return miss() // 參數不可為空
+		}
+
+		if p == nil {
+			p = make([]Param, 0, 4)
+		}
+		p = append(p, Param{
+			Key:   n.path[1:], // 去掉前導 ':'
+			Value: path[:end],
+		})
+
+		if end < len(path) {
+			// 還有剩餘路徑，繼續向下匹配
+			if len(n.children) > 0 {
+				path = path[end:]
 				n = n.children[0]
-				switch n.nType {
-				case param:
-					// 提取 :param 值（到下一個 / 為止）
-					end := 0
-					for end < len(path) && path[end] != '/' {
-						end++
-					}
-
-					if p == nil {
-						p = make([]Param, 0, 4)
-					}
-					p = append(p, Param{
-						Key:   n.path[1:], // 去掉前導 ':'
-						Value: path[:end],
-					})
-
-					if end < len(path) {
-						// 還有剩餘路徑，繼續向下匹配
-						if len(n.children) > 0 {
-							path = path[end:]
-							n = n.children[0]
-							continue walk
-						}
-						return nil, p
-					}
-
-					return n.handlers, p
-
-				case catchAll:
-					// 捕獲所有剩餘路徑
-					if p == nil {
-						p = make([]Param, 0, 4)
-					}
-					p = append(p, Param{
-						Key:   n.path[1:], // 去掉前導 '*'
-						Value: "/" + path, // 補回被父節點消費的前導 '/'
-					})
-					return n.handlers, p
-				}
+				continue walk
 			}
-		} else if path == n.path {
-			// 完全匹配
-			return n.handlers, p
+			return miss()
 		}
 
-		// 不匹配
-		return nil, p
+		if n.handlers != nil {
+			return n, p
+		}
+		return miss()
 	}
 }
 
 // addRoute 添加路由到樹
 func (n *radixNode) addRoute(path string, handlers []hypcontext.HandlerFunc) {
-	fullPath := path
+	n.add(path, path, handlers)
+}
+
+// add 插入剩餘路徑 path；fullPath 為完整路由模板
+func (n *radixNode) add(path, fullPath string, handlers []hypcontext.HandlerFunc) {
 	n.priority++
 
 	// 空樹：直接插入
@@ -129,6 +155,9 @@ func (n *radixNode) addRoute(path string, handlers []hypcontext.HandlerFunc) {
 			children:  n.children,
 			handlers:  n.handlers,
 			priority:  n.priority - 1,
+			fullPath:  n.fullPath,
+
+			catchAllChild: n.catchAllChild,
 		}
 
 		n.children = []*radixNode{child}
@@ -136,26 +165,38 @@ func (n *radixNode) addRoute(path string, handlers []hypcontext.HandlerFunc) {
 		n.path = path[:i]
 		n.handlers = nil
 		n.wildChild = false
+		n.catchAllChild = nil
 	}
 
 	// 還有剩餘路徑需要插入
 	if i < len(path) {
 		path = path[i:]
 
-		// 當前節點有通配符子節點
+		// catch-all 掛在當前節點旁，不影響靜態與參數子節點
+		if path[0] == '*' {
+			if existing := n.catchAllChild; existing != nil {
+				if existing.path == path {
+					panic("router: duplicate route '" + fullPath + "': handlers already registered")
+				}
+				panic("router: route '" + fullPath + "' conflicts with existing route '" + existing.fullPath +
+					"': catch-all '" + path + "' collides with '" + existing.path + "'")
+			}
+			n.insertChild(path, fullPath, handlers)
+			return
+		}
+
+		// 當前節點有參數子節點
 		if n.wildChild {
 			n = n.children[0]
 			n.priority++
 
 			// 檢查通配符相容性
 			if len(path) >= len(n.path) && n.path == path[:len(n.path)] &&
-				n.nType != catchAll &&
 				(len(n.path) >= len(path) || path[len(n.path)] == '/') {
-				n.addRoute(path, handlers)
+				n.add(path, fullPath, handlers)
 			} else {
-				panic("router: path segment '" + path +
-					"' conflicts with existing wildcard '" + n.path +
-					"' in path '" + fullPath + "'")
+				panic("router: route '" + fullPath + "' conflicts with existing route '" + n.firstRoute() +
+					"': segment '" + pathSegment(path) + "' collides with wildcard '" + n.path + "'")
 			}
 			return
 		}
@@ -166,7 +207,7 @@ func (n *radixNode) addRoute(path string, handlers []hypcontext.HandlerFunc) {
 		if n.nType == param && c == '/' && len(n.children) == 1 {
 			n = n.children[0]
 			n.priority++
-			n.addRoute(path, handlers)
+			n.add(path, fullPath, handlers)
 			return
 		}
 
@@ -175,13 +216,19 @@ func (n *radixNode) addRoute(path string, handlers []hypcontext.HandlerFunc) {
 			if c == index {
 				i = n.incrementChildPrio(i)
 				n = n.children[i]
-				n.addRoute(path, handlers)
+				n.add(path, fullPath, handlers)
 				return
 			}
 		}
 
+		// 參數不能與既有的靜態子節點並存（否則既有路由會被覆蓋）
+		if c == ':' && len(n.children) > 0 {
+			panic("router: route '" + fullPath + "' conflicts with existing route '" + n.firstRoute() +
+				"': wildcard '" + pathSegment(path) + "' cannot share a segment with static paths")
+		}
+
 		// 插入新的靜態子節點
-		if c != ':' && c != '*' {
+		if c != ':' {
 			n.indices += string(c)
 			child := &radixNode{fullPath: fullPath}
 			n.addChild(child)
@@ -195,9 +242,54 @@ func (n *radixNode) addRoute(path string, handlers []hypcontext.HandlerFunc) {
 
 	// 路徑完全匹配到當前節點
 	if n.handlers != nil {
-		panic("router: handlers already registered for path '" + fullPath + "'")
+		if n.fullPath == fullPath {
+			panic("router: duplicate route '" + fullPath + "': handlers already registered")
+		}
+		panic("router: route '" + fullPath + "' conflicts with existing route '" + n.fullPath + "'")
 	}
 	n.handlers = handlers
+	n.fullPath = fullPath
+}
+
+// clone 深複製子樹（handlers 切片共用，註冊後不會被修改）
+func (n *radixNode) clone() *radixNode {
+	cp := *n
+	if n.children != nil {
+		cp.children = make([]*radixNode, len(n.children))
+		for i, child := range n.children {
+			cp.children[i] = child.clone()
+		}
+	}
+	if n.catchAllChild != nil {
+		cp.catchAllChild = n.catchAllChild.clone()
+	}
+	return &cp
+}
+
+// firstRoute 返回子樹中第一個已註冊的路由模板，用於衝突提示
+func (n *radixNode) firstRoute() string {
+	if n.handlers != nil {
+		return n.fullPath
+	}
+	for _, child := range n.children {
+		if route := child.firstRoute(); route != "" {
+			return route
+		}
+	}
+	if n.catchAllChild != nil {
+		return n.catchAllChild.fullPath
+	}
+	return n.fullPath
+}
+
+// pathSegment 返回 path 第一個 '/' 之前的片段
+func pathSegment(path string) string {
+	for i := 0; i < len(path); i++ {
+		if path[i] == '/' {
+			return path[:i]
+		}
+	}
+	return path
 }
 
 // insertChild 插入含通配符的子節點，處理通配符插入
@@ -264,20 +356,14 @@ func (n *radixNode) insertChild(path, fullPath string, handlers []hypcontext.Han
 				n.path = path[:i]
 			}
 
-			// 標記父節點擁有通配符子節點（與 :param 路徑一致）
-			n.wildChild = true
-
-			// 直接創建 catchAll 節點作為子節點
-			// 無需中間節點，search 透過 wildChild 直接跳到 children[0]
-			child := &radixNode{
+			// catch-all 不佔用 children，lookup 在其他分支都失敗時才退回
+			n.catchAllChild = &radixNode{
 				path:     path[i:],
 				nType:    catchAll,
 				handlers: handlers,
 				priority: 1,
 				fullPath: fullPath,
 			}
-			n.children = []*radixNode{child}
-			n.indices = string('/')
 			return
 		}
 	}
@@ -392,13 +392,17 @@ func GenerateProto(baseDir, name, moduleName string) error {
 	return nil
 }
 
-// GenerateService 生成使用 Error Catalog 的 service
+// GenerateService 生成內嵌 repository.Repository 的 service（需搭配同名 model）
 // 產生的所有 struct 與 func 均強制加入 // @ai: madeby <provider> 註解，不可關閉。
-func GenerateService(dir, name string) error {
+func GenerateService(dir, name, moduleName string) error {
 	if err := validateName(name); err != nil {
 		return err
 	}
+	if moduleName == "" {
+		moduleName = "myapp"
+	}
 	data := templateData(name)
+	data["ModuleName"] = moduleName
 	data["AIProvider"] = resolveAIProvider()
 	return generateFile(dir, strings.ToLower(name)+"_service.go", serviceTemplate, data)
 }
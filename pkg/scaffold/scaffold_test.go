@@ -292,7 +292,7 @@ func TestGenerateService(t *testing.T) {
 	defer chdir(t, origDir)
 
 	dir := t.TempDir()
-	if err := GenerateService(dir, "Payment"); err != nil {
+	if err := GenerateService(dir, "Payment", "shop"); err != nil {
 		t.Fatalf("GenerateService failed: %v", err)
 	}
 
@@ -302,8 +302,8 @@ func TestGenerateService(t *testing.T) {
 	}
 
 	s := string(content)
-	if !strings.Contains(s, "errors.Define") {
-		t.Error("should use errors.Define")
+	if !strings.Contains(s, "*repository.Repository[models.Payment]") || !strings.Contains(s, `"shop/app/models"`) {
+		t.Error("should embed repository.Repository for the model")
 	}
 	if !strings.Contains(s, "PaymentService") {
 		t.Error("should contain PaymentService")
@@ -313,8 +313,8 @@ func TestGenerateService(t *testing.T) {
 	if !strings.Contains(s, wantPrefix) {
 		t.Errorf("no config: should contain %q", wantPrefix)
 	}
-	if count := strings.Count(s, wantPrefix); count < 7 {
-		t.Errorf("expected at least 7 @ai:generated annotations, got %d", count)
+	if count := strings.Count(s, wantPrefix); count < 5 {
+		t.Errorf("expected at least 5 @ai:generated annotations, got %d", count)
 	}
 	// 必填欄位
 	for _, required := range []string{"@ai:purpose", "@ai:input", "@ai:output", "@ai:sideeffect"} {
@@ -337,7 +337,7 @@ func TestGenerateServiceFromLLMConfig(t *testing.T) {
 	defer chdir(t, origDir)
 
 	dir := t.TempDir()
-	if err := GenerateService(dir, "Invoice"); err != nil {
+	if err := GenerateService(dir, "Invoice", ""); err != nil {
 		t.Fatalf("GenerateService failed: %v", err)
 	}
 	s, _ := os.ReadFile(filepath.Join(dir, "invoice_service.go"))
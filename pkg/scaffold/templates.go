@@ -243,7 +243,7 @@ func APIMiddleware() []hypcontext.HandlerFunc {
 func WebMiddleware() []hypcontext.HandlerFunc {
 	return []hypcontext.HandlerFunc{
 		middleware.Logger(middleware.LoggerConfig{}),
-		// middleware.CSRF(middleware.CSRFConfig{...}),
+		middleware.CSRF(middleware.CSRFConfig{}),
 	}
 }
 `
@@ -267,6 +267,7 @@ type {{.Name}} struct {
 	Active      bool      ` + "`" + `bun:"active,notnull,default:true" json:"active"` + "`" + `
 	CreatedAt   time.Time ` + "`" + `bun:"created_at,nullzero,notnull,default:current_timestamp" json:"created_at"` + "`" + `
 	UpdatedAt   time.Time ` + "`" + `bun:"updated_at,nullzero,notnull,default:current_timestamp" json:"updated_at"` + "`" + `
+	DeletedAt   time.Time ` + "`" + `bun:",soft_delete,nullzero" json:"-"` + "`" + `
 }
 
 // Create{{.Name}}Req 建立 {{.Name}} 的請求（Schema Input）
@@ -299,7 +300,7 @@ type {{.Name}}ListResp struct {
 }
 `
 
-// serviceTemplate — 業務邏輯 + Error Catalog
+// serviceTemplate — 內嵌 repository.Repository 的業務邏輯層
 // 符合 AI_CODING_RULES v1.0：每個 exported struct/func 必備完整 @ai 區塊。
 const serviceTemplate = `// Package services 提供 {{.LowerName}} 業務邏輯層。
 //
@@ -310,27 +311,24 @@ package services
 import (
 	"context"
 
-	"github.com/maoxiaoyue/hypgo/pkg/errors"
 	"github.com/maoxiaoyue/hypgo/pkg/hidb"
 	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/repository"
+	"{{.ModuleName}}/app/models"
 )
 
 // {{.Name}}Service 處理 {{.LowerName}} 業務邏輯。
+// 內嵌的 Repository 提供 Create / FindByID / List / Update / Delete / Restore，
+// 找不到時回傳 errors.ErrNotFound、違反唯一約束時回傳 errors.ErrDuplicate（以 errors.Is 判斷）。
 //
 // @ai:generated by={{.AIProvider}} date={{.Date}}
 // @ai:purpose 封裝 {{.LowerName}} 的 CRUD + 業務規則，供 controller 呼叫
 // @ai:owner team=unassigned
 type {{.Name}}Service struct {
-	db     *hidb.Database
+	*repository.Repository[models.{{.Name}}]
 	logger *logger.Logger
 }
 
-// 預定義錯誤（Error Catalog）
-var (
-	ErrSvc{{.Name}}NotFound = errors.Define("E_svc_{{.LowerName}}_001", 404, "{{.Name}} not found", "{{.LowerName}}")
-	ErrSvc{{.Name}}Create   = errors.Define("E_svc_{{.LowerName}}_002", 500, "Failed to create {{.LowerName}}", "{{.LowerName}}")
-)
-
 // New{{.Name}}Service 建立新的 {{.Name}}Service 實例。
 //
 // @ai:generated by={{.AIProvider}} date={{.Date}}
@@ -339,72 +337,42 @@ var (
 // @ai:output *{{.Name}}Service 指標
 // @ai:sideeffect none
 func New{{.Name}}Service(db *hidb.Database, logger *logger.Logger) *{{.Name}}Service {
-	return &{{.Name}}Service{db: db, logger: logger}
+	return &{{.Name}}Service{
+		Repository: repository.New[models.{{.Name}}](db.HypDB()),
+		logger:     logger,
+	}
 }
 
-// Create 建立新的 {{.LowerName}}。
+// CreateFromRequest 以建立請求寫入新的 {{.LowerName}}。
 //
 // @ai:generated by={{.AIProvider}} date={{.Date}}
-// @ai:purpose 驗證輸入、寫入資料庫、回傳 error
-// @ai:input ctx 請求上下文、data 欄位 map
-// @ai:output error（nil 表示成功）
+// @ai:purpose 將 Create{{.Name}}Req 轉為 model 並寫入資料庫
+// @ai:input ctx 請求上下文、req 建立請求
+// @ai:output 新建的 {{.Name}} + error（唯一約束衝突時為 errors.ErrDuplicate）
 // @ai:sideeffect DB insert to {{.LowerName}}s；寫入 info log
-func (s *{{.Name}}Service) Create(ctx context.Context, data map[string]interface{}) error {
-	s.logger.Info("Creating {{.LowerName}}")
-	// TODO: implement
-	return nil
-}
-
-// GetByID 根據 ID 讀取單一 {{.LowerName}}。
-//
-// @ai:generated by={{.AIProvider}} date={{.Date}}
-// @ai:purpose 供 controller 的 Get handler 取得單筆資料
-// @ai:input ctx 請求上下文、id 主鍵
-// @ai:output 資料物件 + error（找不到時回傳 ErrSvc{{.Name}}NotFound）
-// @ai:sideeffect DB read from {{.LowerName}}s；寫入 info log
-func (s *{{.Name}}Service) GetByID(ctx context.Context, id string) (interface{}, error) {
-	s.logger.Infof("Getting {{.LowerName}} by ID: %s", id)
-	// TODO: implement
-	return nil, ErrSvc{{.Name}}NotFound.With("id", id)
+func (s *{{.Name}}Service) CreateFromRequest(ctx context.Context, req models.Create{{.Name}}Req) (*models.{{.Name}}, error) {
+	m := &models.{{.Name}}{Name: req.Name, Description: req.Description, Active: true}
+	if err := s.Create(ctx, m); err != nil {
+		return nil, err
+	}
+	s.logger.Infof("Created {{.LowerName}} %d", m.ID)
+	return m, nil
 }
 
-// Update 更新既有 {{.LowerName}}。
+// UpdateFromRequest 以更新請求部分更新 {{.LowerName}}（PATCH 語意，只更新有提供的欄位）。
 //
 // @ai:generated by={{.AIProvider}} date={{.Date}}
-// @ai:purpose 驗證 ID 存在、更新欄位、回傳 error
-// @ai:input ctx 請求上下文、id 主鍵、data 欲更新欄位 map
-// @ai:output error
+// @ai:purpose 將 Update{{.Name}}Req 轉為欄位 map 後更新，回傳更新後的資料
+// @ai:input ctx 請求上下文、id 主鍵、req 更新請求
+// @ai:output 更新後的 {{.Name}} + error（不存在時為 errors.ErrNotFound）
 // @ai:sideeffect DB update to {{.LowerName}}s；寫入 info log
-func (s *{{.Name}}Service) Update(ctx context.Context, id string, data map[string]interface{}) error {
-	s.logger.Infof("Updating {{.LowerName}} ID: %s", id)
-	// TODO: implement
-	return nil
-}
-
-// Delete 刪除 {{.LowerName}}。
-//
-// @ai:generated by={{.AIProvider}} date={{.Date}}
-// @ai:purpose 刪除指定主鍵的資料列
-// @ai:input ctx 請求上下文、id 主鍵
-// @ai:output error
-// @ai:sideeffect DB delete from {{.LowerName}}s；寫入 info log
-func (s *{{.Name}}Service) Delete(ctx context.Context, id string) error {
-	s.logger.Infof("Deleting {{.LowerName}} ID: %s", id)
-	// TODO: implement
-	return nil
-}
-
-// List 列出所有 {{.LowerName}}。
-//
-// @ai:generated by={{.AIProvider}} date={{.Date}}
-// @ai:purpose 回傳完整 {{.LowerName}} 清單供 controller 輸出
-// @ai:input ctx 請求上下文
-// @ai:output 資料切片 + error
-// @ai:sideeffect DB read from {{.LowerName}}s；寫入 info log
-func (s *{{.Name}}Service) List(ctx context.Context) ([]interface{}, error) {
-	s.logger.Info("Listing {{.LowerName}}s")
-	// TODO: implement
-	return nil, nil
+func (s *{{.Name}}Service) UpdateFromRequest(ctx context.Context, id int64, req models.Update{{.Name}}Req) (*models.{{.Name}}, error) {
+	m, err := s.Update(ctx, id, repository.Patch(req))
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Infof("Updated {{.LowerName}} %d", id)
+	return m, nil
 }
 `
 
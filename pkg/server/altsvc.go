@@ -0,0 +1,52 @@
+// @chris
+package server
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+)
+
+// ===== HTTP/3 Alt-Svc 發佈 =====
+
+// http3Addr HTTP/3 的 UDP 監聽位址：未設定 http3_addr 時與 TCP 主位址相同
+func (s *Server) http3Addr() string {
+	if s.config.Server.HTTP3Addr != "" {
+		return s.config.Server.HTTP3Addr
+	}
+	return s.listenAddr()
+}
+
+// advertiseHTTP3 依實際綁定的 UDP 位址設定 Alt-Svc 值；addr 為 nil 時停止發佈
+func (s *Server) advertiseHTTP3(addr net.Addr) {
+	if addr == nil {
+		s.altSvc.Store(nil)
+		return
+	}
+	value := altSvcValue(addr, s.config.Server.AltSvcMaxAge)
+	s.altSvc.Store(&value)
+}
+
+// altSvcValue 組出 Alt-Svc 標頭值，例如 h3=":8443"; ma=86400
+// 只帶埠號不帶主機：替代服務沿用原請求的主機名，憑證才能通過驗證
+func altSvcValue(addr net.Addr, maxAge int) string {
+	port := addr.String()
+	if _, p, err := net.SplitHostPort(port); err == nil {
+		port = p
+	}
+	return `h3=":` + port + `"; ma=` + strconv.Itoa(maxAge)
+}
+
+// setAltSvc 於 TCP（HTTP/1.1、HTTP/2）回應加上 Alt-Svc
+// HTTP/3 尚未開始服務時不發佈，並佔住標頭避免 router 的預設值誤導客戶端
+func (s *Server) setAltSvc(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor >= 3 {
+		return
+	}
+	if value := s.altSvc.Load(); value != nil {
+		w.Header().Set("Alt-Svc", *value)
+		return
+	}
+	// nil 值不會寫出，只作為「已由 server 決定」的標記
+	w.Header()["Alt-Svc"] = nil
+}
@@ -0,0 +1,112 @@
+// @chris
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// ===== 關閉時的請求排空 =====
+
+// OnDrain 註冊排空開始時的 hook
+// Shutdown 會先將 readiness 標記為失敗並依序呼叫 hook（例如從服務發現註銷），
+// 再等待 drain_delay 讓負載平衡器移除本實例，之後才停止接受新連線。
+func (s *Server) OnDrain(fn func(ctx context.Context)) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	s.drainHooks = append(s.drainHooks, fn)
+}
+
+// startDrain 呼叫 hook 並等待 drain_delay（ctx 取消時提前結束）
+func (s *Server) startDrain(ctx context.Context) {
+	s.drainMu.Lock()
+	hooks := append([]func(context.Context){}, s.drainHooks...)
+	s.drainMu.Unlock()
+
+	for _, fn := range hooks {
+		fn(ctx)
+	}
+
+	delay := s.config.Server.DrainDelay
+	if delay <= 0 {
+		return
+	}
+	s.logger.Infof("Draining for %s before closing listeners", delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
+
+// withDrainGate 關閉期間的請求閘門
+// 已在處理中的請求不受影響；新請求一律帶上 Connection: close，
+// 啟用 drain_reject 時則直接回傳 503，讓客戶端改連其他實例。
+func (s *Server) withDrainGate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.shuttingDown.Load() {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		if r.ProtoMajor < 3 {
+			w.Header().Set("Connection", "close")
+		}
+		if s.config.Server.DrainReject {
+			w.Header().Set("Retry-After", "1")
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// ===== 關閉後的資源清理 =====
+
+// OnShutdown 註冊關閉 hook，用於集中釋放應用資源（flush 佇列、關閉 broker 連線等）
+// Shutdown 在 HTTP / HTTP/3 / gRPC 停止服務後、送出剩餘追蹤資料前，以註冊的相反順序呼叫（後建立的資源先關閉），
+// ctx 帶有 Shutdown 的期限；所有 hook 都會執行，錯誤（含 panic）彙整後由 Shutdown 回傳
+//
+// EX：
+//
+//	producer := kafka.NewProducer(cfg)
+//	srv.OnShutdown(func(ctx context.Context) error {
+//	    return producer.Flush(ctx)
+//	})
+func (s *Server) OnShutdown(fn func(ctx context.Context) error) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	s.shutdownHooks = append(s.shutdownHooks, fn)
+}
+
+// runShutdownHooks 以相反順序執行關閉 hook，回傳彙整的錯誤；每個 hook 只會執行一次
+func (s *Server) runShutdownHooks(ctx context.Context) error {
+	s.drainMu.Lock()
+	hooks := s.shutdownHooks
+	s.shutdownHooks = nil
+	s.drainMu.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := callShutdownHook(ctx, hooks[i]); err != nil {
+			s.logger.Warningf("Shutdown hook failed: %v", err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// callShutdownHook 將 hook 的 panic 轉為錯誤，避免中斷其餘 hook
+func callShutdownHook(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("shutdown hook panicked: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
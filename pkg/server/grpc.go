@@ -0,0 +1,68 @@
+// @chris
+package server
+
+import (
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
+)
+
+// ===== gRPC 與 HTTP 共用埠 =====
+
+// RegisterGRPC 讓 gRPC 服務與 HypGo 路由共用同一個埠，需在 Start 之前呼叫
+// Content-Type 為 application/grpc 的 HTTP/2 請求交給 grpcServer，其餘請求照常進入路由。
+//
+// gRPC 只能跑在 HTTP/2 上，因此 protocol 需為 http2 或 auto（HTTP/1.1 模式會略過 gRPC 並記錄警告）：
+//   - 未啟用 TLS：以 h2c 處理，客戶端需使用 prior knowledge 的明文 HTTP/2（grpc-go 搭配 insecure.NewCredentials() 即是）
+//   - 啟用 TLS：ALPN 必須協商出 "h2"（伺服器已宣告 h2 與 http/1.1），客戶端使用 credentials.NewTLS；
+//     前方若有終止 TLS 的代理，需確認其以 HTTP/2 轉發到本服務
+//   - HTTP/3 不支援 gRPC，gRPC 客戶端不應依 Alt-Svc 升級
+//
+// 這裡使用 grpc.Server.ServeHTTP（grpc-go 標示為實驗性），效能與功能較原生 transport 略少；
+// gRPC 請求不經過 request_timeout（其緩衝回應會吃掉 trailer），逾時請改用 gRPC 本身的 deadline。
+// 關閉時由 Shutdown 處理，不要另外呼叫 grpcServer.GracefulStop，ServeHTTP 模式下它會 panic。
+//
+// EX：
+//
+//	gs := grpc.NewServer()
+//	pb.RegisterGreeterServer(gs, &greeter{})
+//	srv := server.New(cfg, log)
+//	srv.RegisterGRPC(gs)
+//	srv.Start()
+func (s *Server) RegisterGRPC(grpcServer *grpc.Server) {
+	s.grpcServer = grpcServer
+}
+
+// isGRPCRequest 標準的 gRPC 判斷：HTTP/2 且 Content-Type 為 application/grpc（含 +proto 等子型別）
+// grpc-web 需要額外轉換，不在此列
+func isGRPCRequest(r *http.Request) bool {
+	if r.ProtoMajor != 2 {
+		return false
+	}
+	ct := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(ct, "application/grpc") {
+		return false
+	}
+	rest := ct[len("application/grpc"):]
+	return rest == "" || rest[0] == '+' || rest[0] == ';'
+}
+
+// h2Handler 建立 HTTP/2 伺服器的處理器
+// 註冊 gRPC 後必須在 h2c 內分流，明文 HTTP/2 的串流才會經過判斷；gRPC 請求不套用 wrapHandler
+func (s *Server) h2Handler(h2s *http2.Server) http.Handler {
+	if s.grpcServer == nil {
+		return s.wrapHandler(h2c.NewHandler(s.router, h2s))
+	}
+
+	app := s.wrapHandler(s.router)
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if isGRPCRequest(r) {
+			s.grpcServer.ServeHTTP(w, r)
+			return
+		}
+		app.ServeHTTP(w, r)
+	}), h2s)
+}
@@ -0,0 +1,93 @@
+// @chris
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"time"
+)
+
+// ===== HTTP/3 啟動與綁定重試 =====
+
+// HTTP3Error HTTP/3 無法開始服務
+// Transient 為 true 表示暫時性的 UDP 綁定錯誤（如埠被占用），已重試 Attempts 次仍失敗；
+// 為 false 表示設定問題（未啟用 TLS、憑證無法載入、位址無效或權限不足），重試不會成功
+type HTTP3Error struct {
+	Addr      string
+	Attempts  int
+	Transient bool
+	Err       error
+}
+
+func (e *HTTP3Error) Error() string {
+	if e.Transient {
+		return fmt.Sprintf("HTTP/3 unavailable on UDP %s after %d attempt(s): %v", e.Addr, e.Attempts, e.Err)
+	}
+	return fmt.Sprintf("HTTP/3 unavailable on UDP %s: %v", e.Addr, e.Err)
+}
+
+func (e *HTTP3Error) Unwrap() error { return e.Err }
+
+// HTTP3Active HTTP/3 是否正在 UDP 上服務（此時 TCP 回應才會帶 Alt-Svc）
+func (s *Server) HTTP3Active() bool {
+	return s.altSvc.Load() != nil
+}
+
+// listenHTTP3 綁定 HTTP/3 的 UDP 位址，暫時性錯誤依 http3_bind_retries / http3_bind_retry_delay 重試
+// 關閉期間不再重試
+func (s *Server) listenHTTP3() (net.PacketConn, error) {
+	addr := s.http3Addr()
+	retries := s.config.Server.HTTP3BindRetries
+	if retries < 0 {
+		retries = 0
+	}
+
+	for attempt := 1; ; attempt++ {
+		conn, err := net.ListenPacket("udp", addr)
+		if err == nil {
+			if attempt > 1 {
+				s.logger.Infof("HTTP/3 bound UDP %s on attempt %d", addr, attempt)
+			}
+			return conn, nil
+		}
+		if !isTransientBindError(err) {
+			return nil, &HTTP3Error{Addr: addr, Attempts: attempt, Err: err}
+		}
+		if attempt > retries || s.shuttingDown.Load() {
+			return nil, &HTTP3Error{Addr: addr, Attempts: attempt, Transient: true, Err: err}
+		}
+
+		s.logger.Warningf("HTTP/3 failed to bind UDP %s (attempt %d/%d): %v; retrying in %s",
+			addr, attempt, retries+1, err, s.config.Server.HTTP3BindRetryDelay)
+		select {
+		case <-time.After(s.config.Server.HTTP3BindRetryDelay):
+		case <-s.shutdownChan:
+			return nil, &HTTP3Error{Addr: addr, Attempts: attempt, Transient: true, Err: err}
+		}
+	}
+}
+
+// isTransientBindError 埠被占用或系統暫時缺乏資源時值得重試；位址無效、權限不足等屬於設定錯誤
+func isTransientBindError(err error) bool {
+	return errors.Is(err, syscall.EADDRINUSE) ||
+		errors.Is(err, syscall.ENOBUFS) ||
+		errors.Is(err, syscall.ENOMEM)
+}
+
+// logHTTP3Failure auto 模式下 HTTP/3 停止或無法啟動時，清楚說明已降級為僅 HTTP/1.1 + HTTP/2
+func (s *Server) logHTTP3Failure(err error) {
+	if s.shuttingDown.Load() {
+		return
+	}
+	var h3Err *HTTP3Error
+	switch {
+	case errors.As(err, &h3Err) && h3Err.Transient:
+		s.logger.Errorf("%v; serving HTTP/1.1 and HTTP/2 only, Alt-Svc is not advertised", err)
+	case errors.As(err, &h3Err):
+		s.logger.Errorf("%v (not retried, check server.tls and server.http3_addr); serving HTTP/1.1 and HTTP/2 only, Alt-Svc is not advertised", err)
+	default:
+		s.logger.Errorf("HTTP/3 server stopped: %v; serving HTTP/1.1 and HTTP/2 only, Alt-Svc is no longer advertised", err)
+	}
+}
@@ -0,0 +1,86 @@
+// @chris
+package server
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ===== 啟動前的初始化 =====
+
+// startHook OnStart 註冊的初始化步驟
+type startHook struct {
+	name string
+	fn   func(ctx context.Context) error
+}
+
+// OnStart 註冊啟動 hook，用於在開始監聽前依序初始化應用資源（資料庫、快取、遷移等）
+// Start 在寫入 PID 檔與開啟任何監聽之前，以註冊順序逐一呼叫並記錄每個步驟；
+// 任一 hook 回傳錯誤（含 panic）即停止後續 hook，先以相反順序執行目前已註冊的 OnShutdown hook
+// 釋放前面步驟取得的資源，Start 再回傳帶有該 hook 名稱的錯誤
+// ctx 在 Shutdown 開始時取消，可用於中斷耗時的連線重試
+//
+// EX：
+//
+//	srv.OnStart("database", func(ctx context.Context) error {
+//	    db, err := hidb.New(&cfg.Database)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    srv.OnShutdown(func(context.Context) error { return db.Close() })
+//	    return nil
+//	})
+//	srv.OnStart("migrations", runMigrations)
+func (s *Server) OnStart(name string, fn func(ctx context.Context) error) {
+	s.drainMu.Lock()
+	defer s.drainMu.Unlock()
+	s.startHooks = append(s.startHooks, startHook{name: name, fn: fn})
+}
+
+// startCleanupTimeout 啟動失敗時執行關閉 hook 的期限
+const startCleanupTimeout = 30 * time.Second
+
+// runStartHooks 依註冊順序執行啟動 hook，遇到第一個錯誤時執行已註冊的關閉 hook 後回傳
+func (s *Server) runStartHooks() error {
+	s.drainMu.Lock()
+	hooks := append([]startHook{}, s.startHooks...)
+	s.drainMu.Unlock()
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		select {
+		case <-s.shutdownChan:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for i, h := range hooks {
+		s.logger.Infof("Starting step %d/%d: %s", i+1, len(hooks), h.name)
+		begin := time.Now()
+		if err := callStartHook(ctx, h.fn); err != nil {
+			s.logger.Errorf("Startup step %s failed: %v", h.name, err)
+			cleanupCtx, cancelCleanup := context.WithTimeout(context.Background(), startCleanupTimeout)
+			s.runShutdownHooks(cleanupCtx)
+			cancelCleanup()
+			return fmt.Errorf("startup hook %q failed: %w", h.name, err)
+		}
+		s.logger.Debugf("Startup step %s done in %s", h.name, time.Since(begin))
+	}
+	return nil
+}
+
+// callStartHook 將 hook 的 panic 轉為錯誤，讓 Start 以一般錯誤結束
+func callStartHook(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return fn(ctx)
+}
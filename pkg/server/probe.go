@@ -0,0 +1,67 @@
+// @chris
+package server
+
+import (
+	"net/http"
+
+	"github.com/maoxiaoyue/hypgo/pkg/config"
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/health"
+)
+
+// ===== Kubernetes 探針 =====
+
+// HealthRegistry 返回伺服器使用的健康檢查註冊中心
+// 資料庫、快取等依賴可在啟動前註冊，Readiness 會等待它們全部健康
+func (s *Server) HealthRegistry() *health.Registry {
+	return s.healthRegistry
+}
+
+// Liveness 存活探針：只要進程未進入關閉流程就回傳 200
+func (s *Server) Liveness() hypcontext.HandlerFunc {
+	return func(c *hypcontext.Context) {
+		c.Header("Cache-Control", "no-store")
+		if s.shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, hypcontext.H{"status": "shutting_down"})
+			return
+		}
+		c.JSON(http.StatusOK, hypcontext.H{"status": "alive"})
+	}
+}
+
+// Readiness 就緒探針：啟動中、關閉中，或任何已註冊的依賴檢查失敗時回傳 503
+func (s *Server) Readiness() hypcontext.HandlerFunc {
+	checks := s.healthRegistry.Handler()
+	return func(c *hypcontext.Context) {
+		c.Header("Cache-Control", "no-store")
+		if s.shuttingDown.Load() {
+			c.JSON(http.StatusServiceUnavailable, hypcontext.H{"status": "shutting_down"})
+			return
+		}
+		if !s.started.Load() {
+			c.JSON(http.StatusServiceUnavailable, hypcontext.H{"status": "starting"})
+			return
+		}
+		checks(c)
+	}
+}
+
+// registerProbes 依配置路徑註冊探針路由
+// 路徑為 "-"（config.ProbeDisabled）時不註冊；應用已自行註冊同一路徑時保留應用的路由
+func (s *Server) registerProbes() {
+	s.registerProbe(config.ProbePath(s.config.Server.LivenessPath), s.Liveness())
+	s.registerProbe(config.ProbePath(s.config.Server.ReadinessPath), s.Readiness())
+}
+
+func (s *Server) registerProbe(path string, handler hypcontext.HandlerFunc) {
+	if path == "" {
+		return
+	}
+	for _, route := range s.router.Routes() {
+		if route.Method == http.MethodGet && route.Path == path {
+			s.logger.Debugf("Probe path %s already registered, skipping", path)
+			return
+		}
+	}
+	s.router.GET(path, handler)
+}
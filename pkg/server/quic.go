@@ -0,0 +1,25 @@
+// @chris
+package server
+
+import "github.com/quic-go/quic-go"
+
+// ===== QUIC 調校 =====
+
+// quicConfig 將 server.quic 配置轉為 quic.Config
+// 未設定的欄位保持零值，由 quic-go 套用預設
+func (s *Server) quicConfig() *quic.Config {
+	q := s.config.Server.QUIC
+	return &quic.Config{
+		HandshakeIdleTimeout:           q.HandshakeIdleTimeout,
+		MaxIdleTimeout:                 q.MaxIdleTimeout,
+		KeepAlivePeriod:                q.KeepAlivePeriod,
+		InitialStreamReceiveWindow:     q.InitialStreamReceiveWindow,
+		MaxStreamReceiveWindow:         q.MaxStreamReceiveWindow,
+		InitialConnectionReceiveWindow: q.InitialConnectionReceiveWindow,
+		MaxConnectionReceiveWindow:     q.MaxConnectionReceiveWindow,
+		MaxIncomingStreams:             q.MaxIncomingStreams,
+		MaxIncomingUniStreams:          q.MaxIncomingUniStreams,
+		EnableDatagrams:                q.EnableDatagrams,
+		Allow0RTT:                      q.Allow0RTT,
+	}
+}
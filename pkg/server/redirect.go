@@ -0,0 +1,91 @@
+// @chris
+package server
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// acmeChallengePrefix ACME HTTP-01 驗證路徑，分離埠模式下不重導向，交由 router 處理
+const acmeChallengePrefix = "/.well-known/acme-challenge/"
+
+// isSplitPortEnabled 是否啟用 HTTP / HTTPS 分離埠模式
+func (s *Server) isSplitPortEnabled() bool {
+	return s.config.Server.HTTPAddr != "" && s.config.Server.HTTPSAddr != ""
+}
+
+// listenAddr 主伺服器監聽位址：分離埠模式用 HTTPSAddr，否則用 Addr
+func (s *Server) listenAddr() string {
+	if s.isSplitPortEnabled() {
+		return s.config.Server.HTTPSAddr
+	}
+	return s.config.Server.Addr
+}
+
+// startRedirectServer 在 HTTPAddr 啟動 HTTP→HTTPS 重導向伺服器（背景執行）
+// 先同步建立 listener，讓埠號衝突等錯誤能直接回報給 Start
+func (s *Server) startRedirectServer() error {
+	ln, err := s.listenTCP(s.config.Server.HTTPAddr)
+	if err != nil {
+		return err
+	}
+	s.redirectListener = ln
+
+	s.redirectServer = &http.Server{
+		Handler:           s.redirectHandler(),
+		ReadHeaderTimeout: s.config.Server.ReadHeaderTimeout,
+		IdleTimeout:       time.Duration(s.config.Server.IdleTimeout) * time.Second,
+		MaxHeaderBytes:    s.config.Server.GetMaxHeaderBytes(),
+	}
+
+	s.logger.Infof("Starting HTTP redirect server on %s -> %s", s.config.Server.HTTPAddr, s.config.Server.HTTPSAddr)
+	go func() {
+		if err := s.redirectServer.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Warningf("HTTP redirect server failed: %v", err)
+		}
+	}()
+	return nil
+}
+
+// closeRedirectServer 立即關閉重導向伺服器（主監聽未能啟動時使用）
+// Serve 可能尚未開始追蹤 listener，因此同時直接關閉 listener，確保埠號立即釋放
+func (s *Server) closeRedirectServer() {
+	if s.redirectServer == nil {
+		return
+	}
+	if err := s.redirectServer.Close(); err != nil {
+		s.logger.Warningf("Redirect server close: %v", err)
+	}
+	s.redirectListener.Close()
+}
+
+// redirectHandler 將請求以 301 導向 HTTPS；ACME challenge 路徑直接交給 router
+func (s *Server) redirectHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, acmeChallengePrefix) {
+			s.router.ServeHTTP(w, s.withRequestContext(r))
+			return
+		}
+		http.Redirect(w, r, httpsURL(r, s.config.Server.HTTPSAddr), http.StatusMovedPermanently)
+	})
+}
+
+// httpsURL 依請求 Host 與 HTTPSAddr 的埠號組出對應的 https URL（443 時省略埠號）
+func httpsURL(r *http.Request, httpsAddr string) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if strings.Contains(host, ":") {
+		host = "[" + host + "]" // IPv6
+	}
+
+	if _, port, err := net.SplitHostPort(httpsAddr); err == nil && port != "" && port != "443" {
+		host += ":" + port
+	}
+
+	return "https://" + host + r.URL.RequestURI()
+}
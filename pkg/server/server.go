@@ -8,6 +8,7 @@ import (
 	"crypto/rand"
 	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -20,13 +21,15 @@ import (
 
 	"github.com/maoxiaoyue/hypgo/pkg/config"
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/health"
 	"github.com/maoxiaoyue/hypgo/pkg/logger"
 	"github.com/maoxiaoyue/hypgo/pkg/manifest"
 	"github.com/maoxiaoyue/hypgo/pkg/middleware"
 	"github.com/maoxiaoyue/hypgo/pkg/router"
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
 	"github.com/quic-go/quic-go/http3"
 	"golang.org/x/net/http2"
-	"golang.org/x/net/http2/h2c"
+	"google.golang.org/grpc"
 )
 
 // strongCipherSuites 統一的安全 cipher suite 列表，所有協議共用
@@ -45,18 +48,42 @@ type Server struct {
 	router     *router.Router
 	httpServer *http.Server
 	h3Server   *http3.Server
-	logger     *logger.Logger
-	listener   net.Listener
+	// 分離埠模式下的 HTTP→HTTPS 重導向伺服器
+	redirectServer   *http.Server
+	redirectListener net.Listener
+	logger           *logger.Logger
+	listener         net.Listener
 
 	// 協議檢測
 	protocol Protocol
 
-	// 0-RTT 支援（帶 LRU 淘汰 + TTL）
+	// HTTP/3 單次使用 session ticket（帶 LRU 淘汰 + TTL），防止 0-RTT 重放
 	sessionCache *SessionCache
+	// TCP 監聽的 session ticket 金鑰輪替
+	ticketKeys sessionTicketKeys
+	// 可信代理網段，由 wrapHandler 注入每個請求
+	trustedProxies []*net.IPNet
+	// 錯誤回報器（SetErrorReporter），由 wrapHandler 注入每個請求
+	errorReporter hypcontext.ErrorReporter
+	// 健康檢查註冊中心（預設 health.Default），供 Readiness 使用
+	healthRegistry *health.Registry
 
 	// 優雅關閉（atomic 避免競態）
 	shutdownChan chan struct{}
 	shuttingDown atomic.Bool
+	// 已開始監聽（Readiness、Health 讀取），於 Serve 前設定
+	started atomic.Bool
+	// 監聽前的初始化 hook（OnStart）、排空開始時呼叫的 hook（OnDrain）與停止服務後的清理 hook（OnShutdown），由 drainMu 保護
+	startHooks    []startHook
+	drainHooks    []func(ctx context.Context)
+	shutdownHooks []func(ctx context.Context) error
+	drainMu       sync.Mutex
+	// 追蹤 exporter 的 flush 函數（setupTracing 設定）
+	traceShutdown tracing.ShutdownFunc
+	// HTTP/3 開始服務後的 Alt-Svc 值，未服務時為 nil
+	altSvc atomic.Pointer[string]
+	// 與路由共用埠的 gRPC 服務（RegisterGRPC）
+	grpcServer *grpc.Server
 }
 
 // Protocol 協議類型
@@ -75,7 +102,7 @@ type sessionEntry struct {
 	createdAt time.Time
 }
 
-// SessionCache 0-RTT session 快取（帶大小上限 + TTL）
+// SessionCache HTTP/3 session 快取（帶大小上限 + TTL），ticket 取出即刪除，每張只能恢復一次
 type SessionCache struct {
 	entries map[string]sessionEntry
 	mu      sync.Mutex
@@ -174,12 +201,19 @@ func (c *SessionCache) Len() int {
 
 // New 創建新的伺服器實例
 func New(cfg *config.Config, log *logger.Logger) *Server {
+	proxies, err := config.ParseTrustedProxies(cfg.Server.TrustedProxies)
+	if err != nil {
+		log.Warningf("Ignoring trusted proxies: %v", err)
+	}
+
 	return &Server{
-		config:       cfg,
-		router:       router.New(),
-		logger:       log,
-		sessionCache: newSessionCache(),
-		shutdownChan: make(chan struct{}),
+		config:         cfg,
+		router:         router.New(),
+		logger:         log,
+		sessionCache:   newSessionCache(),
+		trustedProxies: proxies,
+		healthRegistry: health.Default,
+		shutdownChan:   make(chan struct{}),
 	}
 }
 
@@ -193,13 +227,46 @@ func (s *Server) Use(middlewares ...hypcontext.HandlerFunc) {
 	s.router.Use(middlewares...)
 }
 
+// SetErrorReporter 註冊錯誤回報器（須在 Start 前呼叫），nil 表示停用
+// c.Error、5xx 的 AbortWithError 與 Recovery 捕捉的 panic 會交給它；
+// 未以 hypcontext.LimitReports 包裝時套用預設限流（同一錯誤每分鐘 5 次）
+//
+// EX：
+//
+//	reporter, _ := errors.NewSentryReporter(os.Getenv("SENTRY_DSN"))
+//	srv.SetErrorReporter(reporter)
+func (s *Server) SetErrorReporter(r hypcontext.ErrorReporter) {
+	if r == nil {
+		s.errorReporter = nil
+		return
+	}
+	if _, limited := r.(*hypcontext.RateLimitedReporter); !limited {
+		r = hypcontext.LimitReports(r, 0, 0)
+	}
+	s.errorReporter = r
+}
+
 // Start 根據配置啟動伺服器
 func (s *Server) Start() error {
+	// 依序執行 OnStart 註冊的初始化步驟，失敗時不開啟任何監聽
+	if err := s.runStartHooks(); err != nil {
+		return err
+	}
+
 	// 保存 PID 檔案
 	if err := s.savePIDFile(); err != nil {
 		s.logger.Warningf("Failed to save PID file: %v", err)
 	}
 
+	// 註冊 liveness / readiness 探針
+	s.registerProbes()
+
+	// 初始化 OpenTelemetry exporter（monitoring.trace_enabled）
+	s.setupTracing()
+
+	// TCP 監聽的 session ticket 金鑰輪替（tls.session_ticket_rotation）
+	s.startTicketKeyRotation()
+
 	// 將 BindInput 型別不符回報接到 logger（context 對 logger 零依賴，故以 hook 注入）
 	hypcontext.SetBindInputReporter(func(routeKey, declared, bound string) {
 		s.logger.Warningf("BindInput 型別不符 [%s]：handler 綁定 %s，但 Schema 宣告 %s", routeKey, bound, declared)
@@ -212,12 +279,33 @@ func (s *Server) Start() error {
 	)
 	sync.SyncSafe()
 
+	// 向健康檢查中心註冊伺服器自身的存活檢查
+	s.healthRegistry.Register("server", func(context.Context) error {
+		return s.Health()
+	}, health.WithKind(health.Liveness))
+
 	// 設置優雅重啟處理
 	if s.isGracefulRestartEnabled() {
 		go s.handleGracefulRestart()
 	}
 
-	// 自動檢測協議或使用指定協議
+	// 分離埠模式：額外啟動 HTTP 重導向監聽
+	if s.isSplitPortEnabled() {
+		if err := s.startRedirectServer(); err != nil {
+			return err
+		}
+	}
+
+	// 主監聽啟動失敗（例如埠號被占用）時一併關閉已啟動的重導向監聽
+	err := s.startProtocol()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.closeRedirectServer()
+	}
+	return err
+}
+
+// startProtocol 自動檢測協議或使用指定協議啟動主伺服器
+func (s *Server) startProtocol() error {
 	if s.config.Server.Protocol == "auto" {
 		return s.startAutoProtocol()
 	}
@@ -234,13 +322,13 @@ func (s *Server) Start() error {
 
 // startAutoProtocol 自動協議選擇（同時支援 HTTP/1.1/2/3）
 func (s *Server) startAutoProtocol() error {
-	s.logger.Infof("Starting server with auto protocol detection on %s", s.config.Server.Addr)
+	s.logger.Infof("Starting server with auto protocol detection on %s", s.listenAddr())
 
 	// 啟動 HTTP/3 伺服器（UDP）
 	if s.config.Server.TLS.Enabled {
 		go func() {
 			if err := s.startHTTP3(); err != nil {
-				s.logger.Warningf("HTTP/3 server failed: %v", err)
+				s.logHTTP3Failure(err)
 			}
 		}()
 	}
@@ -249,7 +337,8 @@ func (s *Server) startAutoProtocol() error {
 	return s.startHTTP2WithFallback()
 }
 
-// getTLSWrapSession 取得用於 TLS 1.3 0-RTT 的 WrapSession 函數
+// getTLSWrapSession 取得用於 HTTP/3（TLS 1.3 0-RTT）的 WrapSession 函數
+// ticket 為隨機鍵，session 狀態保存在 sessionCache
 func (s *Server) getTLSWrapSession() func(tls.ConnectionState, *tls.SessionState) ([]byte, error) {
 	return func(cs tls.ConnectionState, ss *tls.SessionState) ([]byte, error) {
 		ticket := make([]byte, 32)
@@ -290,20 +379,50 @@ func (s *Server) loadCertificate() (tls.Certificate, error) {
 	return cert, nil
 }
 
+// newTLSConfig 依 config.Server.TLS 建立 TCP 監聽（HTTP/1.1、HTTP/2）使用的 tls.Config
+// 未設定 min_version / cipher_suites 時沿用 TLS 1.2 + strongCipherSuites
+func (s *Server) newTLSConfig(nextProtos ...string) (*tls.Config, error) {
+	tlsCfg := s.config.Server.TLS
+
+	minVersion, err := config.ParseTLSVersion(tlsCfg.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+
+	cipherSuites, err := config.ParseCipherSuites(tlsCfg.CipherSuites)
+	if err != nil {
+		return nil, err
+	}
+	if len(cipherSuites) == 0 {
+		cipherSuites = strongCipherSuites
+	}
+
+	return &tls.Config{
+		NextProtos:   nextProtos,
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		// Go 1.18 起 crypto/tls 會自行排序，此欄位僅保留設定相容性
+		PreferServerCipherSuites: tlsCfg.PreferServerCiphers,
+	}, nil
+}
+
 // startHTTP3 啟動 HTTP/3 伺服器
 func (s *Server) startHTTP3() error {
-	s.logger.Infof("Starting HTTP/3 server on %s", s.config.Server.Addr)
+	s.logger.Infof("Starting HTTP/3 server on %s", s.http3Addr())
 
 	if !s.config.Server.TLS.Enabled {
-		return fmt.Errorf("HTTP/3 requires TLS to be enabled")
+		return &HTTP3Error{Addr: s.http3Addr(), Attempts: 1, Err: fmt.Errorf("HTTP/3 requires TLS to be enabled")}
 	}
 
 	cert, err := s.loadCertificate()
 	if err != nil {
-		return err
+		return &HTTP3Error{Addr: s.http3Addr(), Attempts: 1, Err: err}
 	}
 
-	// 配置 TLS（HTTP/3 要求 TLS 1.3）
+	// 配置 TLS（HTTP/3 要求 TLS 1.3，忽略 min_version；TLS 1.3 的 cipher suite 不可設定）
 	tlsConfig := &tls.Config{
 		Certificates:  []tls.Certificate{cert},
 		NextProtos:    []string{"h3"},
@@ -315,19 +434,31 @@ func (s *Server) startHTTP3() error {
 	// 創建 HTTP/3 伺服器
 	s.h3Server = &http3.Server{
 		Handler:         s.wrapH3Handler(),
-		Addr:            s.config.Server.Addr,
+		Addr:            s.http3Addr(),
 		TLSConfig:       tlsConfig,
-		EnableDatagrams: false,
-		MaxHeaderBytes:  1 << 20,
+		QUICConfig:      s.quicConfig(),
+		EnableDatagrams: s.config.Server.QUIC.EnableDatagrams,
+		MaxHeaderBytes:  s.config.Server.GetMaxHeaderBytes(),
+		ConnContext:     withQuicConn,
+	}
+
+	// 先綁定 UDP，Alt-Svc 才能發佈實際埠號（http3_addr 為 :0 時亦然）；綁定失敗時不發佈 Alt-Svc
+	conn, err := s.listenHTTP3()
+	if err != nil {
+		return err
 	}
+	defer conn.Close() // http3.Server.Close 不會關閉外部傳入的連線
 
-	// 監聽並服務
-	return s.h3Server.ListenAndServe()
+	s.advertiseHTTP3(conn.LocalAddr())
+	defer s.advertiseHTTP3(nil)
+
+	s.started.Store(true)
+	return s.h3Server.Serve(conn)
 }
 
 // startHTTP2WithFallback 啟動 HTTP/2 伺服器（支援 HTTP/1.1 降級）
 func (s *Server) startHTTP2WithFallback() error {
-	s.logger.Infof("Starting HTTP/2 server with HTTP/1.1 fallback on %s", s.config.Server.Addr)
+	s.logger.Infof("Starting HTTP/2 server with HTTP/1.1 fallback on %s", s.listenAddr())
 
 	// 驗證並修正 HTTP/2 設定
 	maxReadFrameSize := s.config.Server.MaxReadFrameSize
@@ -359,31 +490,38 @@ func (s *Server) startHTTP2WithFallback() error {
 	}
 	s.listener = listener
 
-	// 包裝處理器以支援協議檢測
-	handler := s.wrapHandler(h2c.NewHandler(s.router, h2s))
+	// 包裝處理器以支援協議檢測（已註冊 gRPC 時依 Content-Type 分流）
+	handler := s.h2Handler(h2s)
 
 	// 創建 HTTP 伺服器
 	s.httpServer = &http.Server{
 		Handler:           handler,
 		ReadTimeout:       time.Duration(s.config.Server.ReadTimeout) * time.Second,
-		ReadHeaderTimeout: time.Duration(s.config.Server.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: s.config.Server.ReadHeaderTimeout,
 		WriteTimeout:      time.Duration(s.config.Server.WriteTimeout) * time.Second,
 		IdleTimeout:       time.Duration(s.config.Server.IdleTimeout) * time.Second,
-		MaxHeaderBytes:    1 << 20, // 1 MB
+		MaxHeaderBytes:    s.config.Server.GetMaxHeaderBytes(),
 	}
 
 	// TLS 配置（統一 cipher suites）
 	if s.config.Server.TLS.Enabled {
-		s.httpServer.TLSConfig = &tls.Config{
-			NextProtos:    []string{"h2", "http/1.1"},
-			MinVersion:    tls.VersionTLS12,
-			CipherSuites:  strongCipherSuites,
-			WrapSession:   s.getTLSWrapSession(),
-			UnwrapSession: s.getTLSUnwrapSession(),
+		tlsConfig, err := s.newTLSConfig("h2", "http/1.1")
+		if err != nil {
+			return err
 		}
-		return s.httpServer.ServeTLS(listener, s.config.Server.TLS.CertFile, s.config.Server.TLS.KeyFile)
+		if err := s.applyTicketKeys(tlsConfig); err != nil {
+			return err
+		}
+		s.httpServer.TLSConfig = tlsConfig
+		// TLS 路徑的 HTTP/2 由 net/http 內建設定，需明確套用 h2s 才會使用
+		// max_concurrent_streams、max_read_frame_size 與 idle_timeout
+		if err := http2.ConfigureServer(s.httpServer, h2s); err != nil {
+			return fmt.Errorf("configure HTTP/2: %w", err)
+		}
+		return s.serveTLS(listener, tlsConfig)
 	}
 
+	s.started.Store(true)
 	return s.httpServer.Serve(listener)
 }
 
@@ -396,8 +534,11 @@ func (s *Server) startHTTP2() error {
 
 // startHTTP1 啟動 HTTP/1.1 伺服器
 func (s *Server) startHTTP1() error {
-	s.logger.Infof("Starting HTTP/1.1 server on %s", s.config.Server.Addr)
+	s.logger.Infof("Starting HTTP/1.1 server on %s", s.listenAddr())
 	s.protocol = HTTP1
+	if s.grpcServer != nil {
+		s.logger.Warning("gRPC requires HTTP/2; registered gRPC services are not served in HTTP/1.1 mode")
+	}
 
 	listener, err := s.getListener()
 	if err != nil {
@@ -410,39 +551,74 @@ func (s *Server) startHTTP1() error {
 	s.httpServer = &http.Server{
 		Handler:           handler,
 		ReadTimeout:       time.Duration(s.config.Server.ReadTimeout) * time.Second,
-		ReadHeaderTimeout: time.Duration(s.config.Server.ReadTimeout) * time.Second,
+		ReadHeaderTimeout: s.config.Server.ReadHeaderTimeout,
 		WriteTimeout:      time.Duration(s.config.Server.WriteTimeout) * time.Second,
 		IdleTimeout:       time.Duration(s.config.Server.IdleTimeout) * time.Second,
-		MaxHeaderBytes:    1 << 20,
+		MaxHeaderBytes:    s.config.Server.GetMaxHeaderBytes(),
 	}
 
 	// TLS 配置（統一 cipher suites）
 	if s.config.Server.TLS.Enabled {
-		s.httpServer.TLSConfig = &tls.Config{
-			MinVersion:   tls.VersionTLS12,
-			CipherSuites: strongCipherSuites,
+		tlsConfig, err := s.newTLSConfig("http/1.1")
+		if err != nil {
+			return err
+		}
+		if err := s.applyTicketKeys(tlsConfig); err != nil {
+			return err
 		}
-		return s.httpServer.ServeTLS(listener, s.config.Server.TLS.CertFile, s.config.Server.TLS.KeyFile)
+		s.httpServer.TLSConfig = tlsConfig
+		return s.serveTLS(listener, tlsConfig)
 	}
 
+	s.started.Store(true)
 	return s.httpServer.Serve(listener)
 }
 
-// wrapHandler 包裝處理器以注入 Alt-Svc 標頭
+// serveTLS 以 tlsConfig 本身包裝監聽並開始服務
+// http.Server.ServeTLS 會複製 TLSConfig，之後輪替的 session ticket 金鑰無法套用到實際監聽，因此不使用
+func (s *Server) serveTLS(listener net.Listener, tlsConfig *tls.Config) error {
+	cert, err := s.loadCertificate()
+	if err != nil {
+		return err
+	}
+	tlsConfig.Certificates = []tls.Certificate{cert}
+	s.started.Store(true)
+	return s.httpServer.Serve(tls.NewListener(listener, tlsConfig))
+}
+
+// wrapHandler 包裝處理器以注入 Alt-Svc 標頭，並套用排空閘門與 server 層的 request_timeout
 func (s *Server) wrapHandler(h http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if s.config.Server.TLS.Enabled && r.ProtoMajor < 3 {
-			w.Header().Set("Alt-Svc", fmt.Sprintf(`h3="%s"; ma=86400`, s.config.Server.Addr))
-		}
-		h.ServeHTTP(w, r)
-	})
+	return s.withDrainGate(s.withRequestTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.setAltSvc(w, r)
+		h.ServeHTTP(w, s.withRequestContext(r))
+	})))
 }
 
-// wrapH3Handler 包裝 HTTP/3 處理器
+// wrapH3Handler 包裝 HTTP/3 處理器，並套用排空閘門、0-RTT 防護與 server 層的 request_timeout
 func (s *Server) wrapH3Handler() http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		s.router.ServeHTTP(w, r)
-	})
+	return s.withDrainGate(s.withEarlyDataGuard(s.withRequestTimeout(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.router.ServeHTTP(w, s.withRequestContext(r))
+	}))))
+}
+
+// withRequestContext 將可信代理設定（供 Context.ClientIP）、錯誤回報器與非預設的 multipart 記憶體上限注入請求 context
+func (s *Server) withRequestContext(r *http.Request) *http.Request {
+	multipartMemory := s.config.Server.MaxMultipartMemory
+	customMultipart := multipartMemory > 0 && multipartMemory != hypcontext.DefaultMaxMemory
+	if len(s.trustedProxies) == 0 && s.errorReporter == nil && !customMultipart {
+		return r
+	}
+	ctx := r.Context()
+	if len(s.trustedProxies) > 0 {
+		ctx = hypcontext.WithTrustedProxies(ctx, s.trustedProxies)
+	}
+	if s.errorReporter != nil {
+		ctx = hypcontext.WithErrorReporter(ctx, s.errorReporter)
+	}
+	if customMultipart {
+		ctx = hypcontext.WithMaxMultipartMemory(ctx, multipartMemory)
+	}
+	return r.WithContext(ctx)
 }
 
 // detectProtocol 檢測請求使用的協議
@@ -460,9 +636,45 @@ func (s *Server) detectProtocol(r *http.Request) string {
 // getListener 創建或繼承監聽器
 func (s *Server) getListener() (net.Listener, error) {
 	if ln := s.getInheritedListener(); ln != nil {
-		return ln, nil
+		// 繼承的 listener 無法套用 ListenConfig，改為逐一設定接受的連線
+		return keepAliveListener{TCPListener: ln.(*net.TCPListener), period: s.keepAlivePeriod()}, nil
 	}
-	return net.Listen("tcp", s.config.Server.Addr)
+	return s.listenTCP(s.listenAddr())
+}
+
+// listenTCP 以設定的 keep-alive 週期建立 TCP 監聽
+func (s *Server) listenTCP(addr string) (net.Listener, error) {
+	lc := net.ListenConfig{KeepAlive: s.keepAlivePeriod()}
+	return lc.Listen(context.Background(), "tcp", addr)
+}
+
+// keepAlivePeriod 將 keep_alive（秒）轉為 net.ListenConfig 的語意：負值停用、0 為系統預設
+func (s *Server) keepAlivePeriod() time.Duration {
+	if s.config.Server.KeepAlive < 0 {
+		return -1
+	}
+	return time.Duration(s.config.Server.KeepAlive) * time.Second
+}
+
+// keepAliveListener 對每個接受的 TCP 連線套用 keep-alive 週期
+type keepAliveListener struct {
+	*net.TCPListener
+	period time.Duration
+}
+
+func (ln keepAliveListener) Accept() (net.Conn, error) {
+	conn, err := ln.AcceptTCP()
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case ln.period < 0:
+		conn.SetKeepAlive(false)
+	case ln.period > 0:
+		conn.SetKeepAlive(true)
+		conn.SetKeepAlivePeriod(ln.period)
+	}
+	return conn, nil
 }
 
 // getInheritedListener 獲取繼承的監聽器（帶驗證）
@@ -490,11 +702,14 @@ func (s *Server) getInheritedListener() net.Listener {
 	return listener
 }
 
-// Shutdown 優雅關閉伺服器（並行處理 HTTP/1+2 和 HTTP/3）
+// Shutdown 優雅關閉伺服器（並行處理 HTTP/1+2 和 HTTP/3），停止服務後執行 OnShutdown 註冊的 hook
 func (s *Server) Shutdown(ctx context.Context) error {
 	s.logger.Info("Shutting down server...")
 	s.shuttingDown.Store(true)
 
+	// readiness 已失敗：通知 hook 並等待負載平衡器移除本實例
+	s.startDrain(ctx)
+
 	// 關閉監聽器（停止接受新連線）
 	if s.listener != nil {
 		s.listener.Close()
@@ -505,6 +720,11 @@ func (s *Server) Shutdown(ctx context.Context) error {
 	done := make(chan struct{})
 
 	go func() {
+		if s.redirectServer != nil {
+			if err := s.redirectServer.Shutdown(ctx); err != nil {
+				s.logger.Warningf("Redirect server shutdown: %v", err)
+			}
+		}
 		if s.httpServer != nil {
 			httpErr = s.httpServer.Shutdown(ctx)
 		}
@@ -522,12 +742,27 @@ func (s *Server) Shutdown(ctx context.Context) error {
 		s.logger.Warning("Shutdown timed out, forcing close")
 	}
 
+	// HTTP/2 連線已送出 GOAWAY；中斷仍未結束的 gRPC 串流
+	if s.grpcServer != nil {
+		s.grpcServer.Stop()
+	}
+
 	close(s.shutdownChan)
 
-	if httpErr != nil {
-		return httpErr
+	// 監聽已停止：釋放應用註冊的資源
+	hookErr := s.runShutdownHooks(ctx)
+
+	// 請求已結束，送出剩餘的 span
+	s.flushTracing(ctx)
+
+	err := httpErr
+	if err == nil {
+		err = h3Err
+	}
+	if hookErr != nil {
+		return errors.Join(err, hookErr)
 	}
-	return h3Err
+	return err
 }
 
 // handleGracefulRestart 處理優雅重啟
@@ -617,7 +852,7 @@ func (s *Server) Health() error {
 		return fmt.Errorf("server is shutting down")
 	}
 
-	if s.httpServer == nil && s.h3Server == nil {
+	if !s.started.Load() {
 		return fmt.Errorf("server not started")
 	}
 
@@ -2,14 +2,37 @@ package server
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/gorilla/websocket"
 	"github.com/maoxiaoyue/hypgo/pkg/config"
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/health"
 	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/quic-go/quic-go/http3"
+	"golang.org/x/net/http2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 func TestNewServer(t *testing.T) {
@@ -62,7 +85,7 @@ func TestServerHealth(t *testing.T) {
 	}
 
 	// Fake start
-	s.httpServer = &http.Server{}
+	s.started.Store(true)
 	if err := s.Health(); err != nil {
 		t.Errorf("Expected no error from Health when started, got: %v", err)
 	}
@@ -216,17 +239,34 @@ func TestServerNotFound(t *testing.T) {
 	cfg := config.Config{}
 	cfg.ApplyDefaults()
 	s := New(&cfg, logger.NewLogger())
+	s.router.GET("/users", func(c *hypcontext.Context) {
+		c.String(http.StatusOK, "ok")
+	})
 
-	called := false
 	s.NotFound(func(c *hypcontext.Context) {
-		called = true
-		c.Status(404)
-		c.Writer.WriteHeaderNow()
+		c.JSON(http.StatusNotFound, hypcontext.H{"error": "not_found", "path": c.Request.URL.Path})
+	})
+	s.MethodNotAllowed(func(c *hypcontext.Context) {
+		c.JSON(http.StatusMethodNotAllowed, hypcontext.H{"error": "method_not_allowed"})
 	})
+	h := s.wrapHandler(s.router)
 
-	// 驗證不會 panic（之前是空殼）
-	if called {
-		t.Error("handler should not be called yet (only registered)")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q, want JSON", ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, `"path":"/missing"`) {
+		t.Errorf("body = %s, want custom JSON 404", body)
+	}
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("DELETE", "/users", nil))
+	if w.Code != http.StatusMethodNotAllowed || !strings.Contains(w.Body.String(), "method_not_allowed") {
+		t.Errorf("got %d %s, want custom JSON 405", w.Code, w.Body.String())
 	}
 }
 
@@ -247,6 +287,52 @@ func TestLoadCertificateError(t *testing.T) {
 	}
 }
 
+// --- TLS 設定測試 ---
+
+func TestNewTLSConfigDefaults(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+
+	tlsCfg, err := s.newTLSConfig("h2", "http/1.1")
+	if err != nil {
+		t.Fatalf("newTLSConfig: %v", err)
+	}
+	if tlsCfg.MinVersion != tls.VersionTLS12 {
+		t.Errorf("MinVersion = %x, want TLS 1.2", tlsCfg.MinVersion)
+	}
+	if len(tlsCfg.CipherSuites) != len(strongCipherSuites) {
+		t.Errorf("CipherSuites = %v, want default strong list", tlsCfg.CipherSuites)
+	}
+	if len(tlsCfg.NextProtos) != 2 {
+		t.Errorf("NextProtos = %v", tlsCfg.NextProtos)
+	}
+}
+
+func TestNewTLSConfigCustom(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.TLS.MinVersion = "1.3"
+	cfg.Server.TLS.CipherSuites = []string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384"}
+	s := New(&cfg, logger.NewLogger())
+
+	tlsCfg, err := s.newTLSConfig()
+	if err != nil {
+		t.Fatalf("newTLSConfig: %v", err)
+	}
+	if tlsCfg.MinVersion != tls.VersionTLS13 {
+		t.Errorf("MinVersion = %x, want TLS 1.3", tlsCfg.MinVersion)
+	}
+	if len(tlsCfg.CipherSuites) != 1 || tlsCfg.CipherSuites[0] != tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 {
+		t.Errorf("CipherSuites = %v", tlsCfg.CipherSuites)
+	}
+
+	s.config.Server.TLS.CipherSuites = []string{"bogus"}
+	if _, err := s.newTLSConfig(); err == nil {
+		t.Error("expected error for unknown cipher suite")
+	}
+}
+
 // --- Shutdown atomic 測試 ---
 
 func TestShutdownAtomic(t *testing.T) {
@@ -276,3 +362,912 @@ func TestShutdownAtomic(t *testing.T) {
 		t.Error("shuttingDown should be true after Store(true)")
 	}
 }
+
+// --- 分離埠（HTTP→HTTPS 重導向）測試 ---
+
+func TestListenAddrSplitPort(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+
+	if got := s.listenAddr(); got != cfg.Server.Addr {
+		t.Errorf("listenAddr() = %q, want %q", got, cfg.Server.Addr)
+	}
+
+	s.config.Server.HTTPAddr = ":80"
+	s.config.Server.HTTPSAddr = ":443"
+	if got := s.listenAddr(); got != ":443" {
+		t.Errorf("listenAddr() = %q, want :443", got)
+	}
+}
+
+func TestRedirectServerClosedWhenMainServerFails(t *testing.T) {
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpAddr := probe.Addr().String()
+	probe.Close()
+
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.HTTPAddr = httpAddr
+	cfg.Server.HTTPSAddr = "127.0.0.1:0"
+	// HTTP/3 未啟用 TLS：重導向監聽已啟動後，主伺服器啟動失敗
+	cfg.Server.Protocol = "http3"
+	s := New(&cfg, logger.NewLogger())
+
+	if err := s.Start(); err == nil {
+		t.Fatal("expected Start to fail when the main server cannot start")
+	}
+
+	// 重導向監聽已關閉，埠號可再次使用
+	ln, err := net.Listen("tcp", httpAddr)
+	if err != nil {
+		t.Fatalf("redirect listener still open after the main server failed: %v", err)
+	}
+	ln.Close()
+}
+
+// --- HTTP/3 Alt-Svc 測試 ---
+
+// writeTestCert 產生自簽憑證供 HTTP/3 監聽使用
+func writeTestCert(t *testing.T) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	certFile = filepath.Join(dir, "server.crt")
+	keyFile = filepath.Join(dir, "server.key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return certFile, keyFile
+}
+
+func TestAltSvcReflectsHTTP3Port(t *testing.T) {
+	// 取得一個可用的 UDP 埠，與 TCP 主位址刻意不同
+	probe, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	h3Addr := probe.LocalAddr().String()
+	_, h3Port, _ := net.SplitHostPort(h3Addr)
+	probe.Close()
+
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.Addr = ":8443"
+	cfg.Server.HTTP3Addr = h3Addr
+	cfg.Server.AltSvcMaxAge = 3600
+	cfg.Server.TLS.Enabled = true
+	cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile = writeTestCert(t)
+	s := New(&cfg, logger.NewLogger())
+	s.EnableHTTP3(nil)
+	s.router.GET("/", func(c *hypcontext.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	h := s.wrapHandler(s.router)
+
+	altSvc := func() string {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w.Header().Get("Alt-Svc")
+	}
+
+	// HTTP/3 尚未服務：不發佈，router 的預設值也不得出現
+	if got := altSvc(); got != "" {
+		t.Fatalf("Alt-Svc before HTTP/3 starts = %q, want empty", got)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- s.startHTTP3() }()
+
+	want := `h3=":` + h3Port + `"; ma=3600`
+	deadline := time.Now().Add(5 * time.Second)
+	for altSvc() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Alt-Svc = %q, want %q", altSvc(), want)
+		}
+		select {
+		case err := <-done:
+			t.Fatalf("startHTTP3 returned early: %v", err)
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	if !s.HTTP3Active() {
+		t.Error("expected HTTP3Active while serving")
+	}
+
+	// HTTP/3 停止後撤回
+	s.h3Server.Close()
+	<-done
+	if got := altSvc(); got != "" {
+		t.Errorf("Alt-Svc after HTTP/3 stops = %q, want empty", got)
+	}
+	if s.HTTP3Active() {
+		t.Error("expected HTTP3Active to be false after HTTP/3 stops")
+	}
+}
+
+func TestHTTP3BindRetry(t *testing.T) {
+	// 占用 UDP 埠，讓 HTTP/3 綁定失敗
+	busy, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer busy.Close()
+
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.HTTP3Addr = busy.LocalAddr().String()
+	cfg.Server.HTTP3BindRetries = 2
+	cfg.Server.HTTP3BindRetryDelay = 10 * time.Millisecond
+	cfg.Server.TLS.Enabled = true
+	cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile = writeTestCert(t)
+	s := New(&cfg, logger.NewLogger())
+
+	err = s.startHTTP3()
+	var h3Err *HTTP3Error
+	if !errors.As(err, &h3Err) || !h3Err.Transient || h3Err.Attempts != 3 {
+		t.Fatalf("expected transient HTTP3Error after 3 attempts, got %#v", err)
+	}
+	if s.HTTP3Active() {
+		t.Error("expected HTTP/3 inactive after bind failure")
+	}
+
+	// 埠在重試期間釋放：第二次嘗試即成功
+	cfg.Server.HTTP3BindRetryDelay = 200 * time.Millisecond
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		busy.Close()
+	}()
+	conn, err := s.listenHTTP3()
+	if err != nil {
+		t.Fatalf("expected bind to succeed after the port is released: %v", err)
+	}
+	conn.Close()
+
+	// 設定錯誤不重試
+	cfg.Server.TLS.Enabled = false
+	if err := s.startHTTP3(); !errors.As(err, &h3Err) || h3Err.Transient {
+		t.Errorf("expected fatal HTTP3Error without TLS, got %v", err)
+	}
+}
+
+// --- Session 恢復與 0-RTT 測試 ---
+
+func TestSessionTicketKeyRotation(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.TLS.Enabled = true
+	cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile = writeTestCert(t)
+	cfg.Server.TLS.SessionTicketRotation = time.Hour
+	s := New(&cfg, logger.NewLogger())
+
+	// 未設定週期：不接管金鑰
+	s.config.Server.TLS.SessionTicketRotation = 0
+	if err := s.applyTicketKeys(&tls.Config{}); err != nil || len(s.ticketKeys.keys) != 0 {
+		t.Fatalf("Expected no managed keys without rotation period, got %d (err=%v)", len(s.ticketKeys.keys), err)
+	}
+	s.config.Server.TLS.SessionTicketRotation = time.Hour
+
+	tlsConfig, err := s.newTLSConfig("http/1.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.applyTicketKeys(tlsConfig); err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.httpServer = &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})}
+	done := make(chan error, 1)
+	go func() { done <- s.serveTLS(ln, tlsConfig) }()
+	defer func() {
+		s.httpServer.Close()
+		<-done
+	}()
+
+	client := &http.Client{Transport: &http.Transport{
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+			ClientSessionCache: tls.NewLRUClientSessionCache(8),
+		},
+	}}
+	resumed := func() bool {
+		t.Helper()
+		resp, err := client.Get("https://" + ln.Addr().String() + "/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return resp.TLS.DidResume
+	}
+
+	if resumed() {
+		t.Fatal("first connection should be a full handshake")
+	}
+	// 輪替一次：舊金鑰仍可解密，session 可跨輪替恢復
+	if err := s.ticketKeys.rotate(); err != nil {
+		t.Fatal(err)
+	}
+	if !resumed() {
+		t.Error("expected session to resume across a key rotation")
+	}
+
+	// 輪替到舊金鑰全部淘汰：實際監聽也必須拒絕舊 ticket
+	for i := 0; i < maxSessionTicketKeys; i++ {
+		if err := s.ticketKeys.rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n := len(s.ticketKeys.keys); n != maxSessionTicketKeys {
+		t.Errorf("kept %d keys, want %d", n, maxSessionTicketKeys)
+	}
+	if resumed() {
+		t.Error("expected retired keys to no longer resume sessions on the live listener")
+	}
+	if !resumed() {
+		t.Error("expected the new ticket to resume")
+	}
+}
+
+func TestEarlyDataGuard(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	serve := func(h http.Handler, method string, handshakeDone bool) int {
+		req := httptest.NewRequest(method, "/", nil)
+		req.TLS = &tls.ConnectionState{HandshakeComplete: handshakeDone}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// 未允許 0-RTT 時不包裝
+	if code := serve(s.withEarlyDataGuard(ok), "POST", false); code != http.StatusOK {
+		t.Errorf("status = %d, want 200 when allow_0rtt is off", code)
+	}
+
+	s.config.Server.QUIC.Allow0RTT = true
+	h := s.withEarlyDataGuard(ok)
+	if code := serve(h, "POST", false); code != hypcontext.StatusTooEarly {
+		t.Errorf("0-RTT POST status = %d, want 425", code)
+	}
+	if code := serve(h, "GET", false); code != http.StatusOK {
+		t.Errorf("0-RTT GET status = %d, want 200", code)
+	}
+	if code := serve(h, "POST", true); code != http.StatusOK {
+		t.Errorf("1-RTT POST status = %d, want 200", code)
+	}
+	if !s.quicConfig().Allow0RTT {
+		t.Error("Expected quic.Config to allow 0-RTT")
+	}
+}
+
+func TestHTTP3RequestMetrics(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.HTTP3Addr = "127.0.0.1:0"
+	cfg.Server.TLS.Enabled = true
+	cfg.Server.TLS.CertFile, cfg.Server.TLS.KeyFile = writeTestCert(t)
+	s := New(&cfg, logger.NewLogger())
+
+	metrics := make(chan hypcontext.RequestMetrics, 1)
+	s.router.POST("/upload", func(c *hypcontext.Context) {
+		io.Copy(io.Discard, c.Request.Body)
+		c.String(http.StatusOK, "stored")
+		metrics <- c.Metrics()
+	})
+
+	done := make(chan error, 1)
+	go func() { done <- s.startHTTP3() }()
+	defer func() {
+		s.h3Server.Close()
+		<-done
+	}()
+
+	// 等待 UDP 綁定完成，從 Alt-Svc 值取得實際埠號
+	var port string
+	for deadline := time.Now().Add(5 * time.Second); port == ""; {
+		if v := s.altSvc.Load(); v != nil {
+			port = strings.TrimSuffix(strings.TrimPrefix(*v, `h3=":`), `"; ma=86400`)
+		} else if time.Now().After(deadline) {
+			t.Fatal("HTTP/3 server did not start")
+		} else {
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	tr := &http3.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	defer tr.Close()
+	client := &http.Client{Transport: tr, Timeout: 5 * time.Second}
+	resp, err := client.Post("https://127.0.0.1:"+port+"/upload", "text/plain", strings.NewReader(strings.Repeat("x", 4096)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	m := <-metrics
+	if m.BytesIn != 4096 || m.BytesOut != int64(len("stored")) {
+		t.Errorf("BytesIn/BytesOut = %d/%d, want 4096/6", m.BytesIn, m.BytesOut)
+	}
+	if m.RTT <= 0 {
+		t.Errorf("RTT = %v, want a sample from the QUIC connection", m.RTT)
+	}
+}
+
+func TestKeepAlivePeriod(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+
+	if got := s.keepAlivePeriod(); got != 30*time.Second {
+		t.Errorf("keepAlivePeriod() = %v, want 30s", got)
+	}
+	s.config.Server.KeepAlive = -1
+	if got := s.keepAlivePeriod(); got >= 0 {
+		t.Errorf("keepAlivePeriod() = %v, want negative (disabled)", got)
+	}
+
+	// 繼承路徑：keepAliveListener 仍能正常接受連線
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kl := keepAliveListener{TCPListener: ln.(*net.TCPListener), period: 10 * time.Second}
+	defer kl.Close()
+
+	go func() {
+		if conn, err := net.Dial("tcp", kl.Addr().String()); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := kl.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+}
+
+func TestQUICConfig(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+
+	// 未設定時全為零值，交由 quic-go 套用預設
+	if q := s.quicConfig(); q.MaxIncomingStreams != 0 || q.MaxIdleTimeout != 0 || q.EnableDatagrams {
+		t.Errorf("Expected zero-value quic.Config, got %+v", q)
+	}
+
+	s.config.Server.QUIC = config.QUICConfig{
+		MaxIncomingStreams:         500,
+		MaxStreamReceiveWindow:     16 << 20,
+		MaxConnectionReceiveWindow: 64 << 20,
+		MaxIdleTimeout:             2 * time.Minute,
+		KeepAlivePeriod:            20 * time.Second,
+		EnableDatagrams:            true,
+	}
+	q := s.quicConfig()
+	if q.MaxIncomingStreams != 500 || q.MaxStreamReceiveWindow != 16<<20 ||
+		q.MaxConnectionReceiveWindow != 64<<20 || q.MaxIdleTimeout != 2*time.Minute ||
+		q.KeepAlivePeriod != 20*time.Second || !q.EnableDatagrams {
+		t.Errorf("quic.Config not mapped from config: %+v", q)
+	}
+}
+
+func TestRedirectHandler(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.HTTPAddr = ":8080"
+	cfg.Server.HTTPSAddr = ":8443"
+	s := New(&cfg, logger.NewLogger())
+	s.router.GET("/.well-known/acme-challenge/:token", func(c *hypcontext.Context) {
+		c.String(http.StatusOK, c.Param("token"))
+	})
+
+	h := s.redirectHandler()
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "http://example.com:8080/users?id=1", nil)
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusMovedPermanently {
+		t.Fatalf("status = %d, want 301", w.Code)
+	}
+	if loc := w.Header().Get("Location"); loc != "https://example.com:8443/users?id=1" {
+		t.Errorf("Location = %q", loc)
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest("GET", "http://example.com/.well-known/acme-challenge/abc", nil)
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Body.String() != "abc" {
+		t.Errorf("ACME challenge should pass through, got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestHTTPSURLDefaultPort(t *testing.T) {
+	req := httptest.NewRequest("GET", "http://example.com/a", nil)
+	if got := httpsURL(req, ":443"); got != "https://example.com/a" {
+		t.Errorf("httpsURL = %q", got)
+	}
+}
+
+// --- 請求逾時測試 ---
+
+func newTimeoutTestServer(timeout time.Duration) *Server {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.RequestTimeout = timeout
+	return New(&cfg, logger.NewLogger())
+}
+
+func TestRequestTimeoutWrites503(t *testing.T) {
+	s := newTimeoutTestServer(20 * time.Millisecond)
+	s.router.GET("/slow", func(c *hypcontext.Context) {
+		<-c.Request.Context().Done()
+		c.String(http.StatusOK, "too late")
+	})
+
+	w := httptest.NewRecorder()
+	s.wrapHandler(s.router).ServeHTTP(w, httptest.NewRequest("GET", "/slow", nil))
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "too late") {
+		t.Errorf("handler output leaked after timeout: %q", w.Body.String())
+	}
+}
+
+func TestRequestTimeoutAlreadyWritten(t *testing.T) {
+	s := newTimeoutTestServer(20 * time.Millisecond)
+	s.router.GET("/stream", func(c *hypcontext.Context) {
+		c.Writer.WriteHeader(http.StatusOK)
+		c.Writer.Write([]byte("partial"))
+		<-c.Request.Context().Done()
+	})
+
+	w := httptest.NewRecorder()
+	s.wrapHandler(s.router).ServeHTTP(w, httptest.NewRequest("GET", "/stream", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("got %d %q, want 200 \"partial\"", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestTimeoutOptOut(t *testing.T) {
+	s := newTimeoutTestServer(10 * time.Millisecond)
+	s.router.GET("/events", func(c *hypcontext.Context) {
+		c.DisableRequestTimeout()
+		time.Sleep(40 * time.Millisecond)
+		c.String(http.StatusOK, "done")
+	})
+
+	w := httptest.NewRecorder()
+	s.wrapH3Handler().ServeHTTP(w, httptest.NewRequest("GET", "/events", nil))
+
+	if w.Code != http.StatusOK || w.Body.String() != "done" {
+		t.Errorf("got %d %q, want 200 \"done\"", w.Code, w.Body.String())
+	}
+}
+
+func TestRequestTimeoutWebSocketUpgrade(t *testing.T) {
+	s := newTimeoutTestServer(30 * time.Millisecond)
+	upgrader := websocket.Upgrader{}
+	s.router.GET("/ws", func(c *hypcontext.Context) {
+		conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
+		if err != nil {
+			t.Errorf("upgrade: %v", err)
+			return
+		}
+		defer conn.Close()
+		// 連線存活超過 request_timeout 仍可收發
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, msg)
+	})
+
+	ts := httptest.NewServer(s.wrapHandler(s.router))
+	defer ts.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	time.Sleep(80 * time.Millisecond)
+	if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, msg, err := conn.ReadMessage(); err != nil || string(msg) != "ping" {
+		t.Errorf("echo = %q, %v", msg, err)
+	}
+}
+
+// --- 探針測試 ---
+
+func probeStatus(s *Server, path string) int {
+	w := httptest.NewRecorder()
+	s.router.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w.Code
+}
+
+func TestLivenessAndReadiness(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+	s.healthRegistry = health.NewRegistry()
+	s.registerProbes()
+
+	dbErr := errors.New("db not ready")
+	s.healthRegistry.Register("database", func(context.Context) error { return dbErr })
+
+	// 啟動中：存活但未就緒
+	if code := probeStatus(s, "/livez"); code != http.StatusOK {
+		t.Errorf("liveness during startup = %d, want 200", code)
+	}
+	if code := probeStatus(s, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness before start = %d, want 503", code)
+	}
+
+	// 已啟動但依賴未就緒
+	s.started.Store(true)
+	if code := probeStatus(s, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness with failing db = %d, want 503", code)
+	}
+
+	dbErr = nil
+	if code := probeStatus(s, "/readyz"); code != http.StatusOK {
+		t.Errorf("readiness when healthy = %d, want 200", code)
+	}
+
+	// 關閉中：兩者皆失敗
+	s.shuttingDown.Store(true)
+	if code := probeStatus(s, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("readiness during shutdown = %d, want 503", code)
+	}
+	if code := probeStatus(s, "/livez"); code != http.StatusServiceUnavailable {
+		t.Errorf("liveness during shutdown = %d, want 503", code)
+	}
+}
+
+func TestProbeRegistration(t *testing.T) {
+	// "-" 停用探針；空字串仍套用預設路徑
+	cfg := config.Config{}
+	cfg.Server.LivenessPath = config.ProbeDisabled
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+	s.registerProbes()
+	if code := probeStatus(s, "/livez"); code != http.StatusNotFound {
+		t.Errorf("disabled liveness = %d, want 404", code)
+	}
+	if code := probeStatus(s, "/readyz"); code != http.StatusServiceUnavailable {
+		t.Errorf("default readiness = %d, want 503", code)
+	}
+
+	// 應用已註冊同一路徑時保留應用的路由，不因重複註冊而 panic
+	cfg = config.Config{}
+	cfg.ApplyDefaults()
+	s = New(&cfg, logger.NewLogger())
+	s.router.GET("/readyz", func(c *hypcontext.Context) {
+		c.String(http.StatusTeapot, "custom")
+	})
+	s.registerProbes()
+	if code := probeStatus(s, "/readyz"); code != http.StatusTeapot {
+		t.Errorf("custom readiness = %d, want 418", code)
+	}
+	if code := probeStatus(s, "/livez"); code != http.StatusOK {
+		t.Errorf("liveness = %d, want 200", code)
+	}
+}
+
+// --- 排空測試 ---
+
+func TestDrainGate(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+	s.router.GET("/", func(c *hypcontext.Context) {
+		c.String(http.StatusOK, "ok")
+	})
+	h := s.wrapHandler(s.router)
+
+	s.shuttingDown.Store(true)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Connection") != "close" {
+		t.Errorf("got %d Connection=%q, want 200 with Connection: close", w.Code, w.Header().Get("Connection"))
+	}
+
+	cfg.Server.DrainReject = true
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 when drain_reject is enabled", w.Code)
+	}
+}
+
+func TestShutdownRunsDrainHooks(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.DrainDelay = 30 * time.Millisecond
+	s := New(&cfg, logger.NewLogger())
+
+	var readyDuringHook bool
+	s.OnDrain(func(ctx context.Context) {
+		readyDuringHook = !s.shuttingDown.Load()
+	})
+
+	start := time.Now()
+	if err := s.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if readyDuringHook {
+		t.Error("readiness should already be failing when drain hooks run")
+	}
+	if time.Since(start) < cfg.Server.DrainDelay {
+		t.Error("Shutdown should wait for drain_delay before closing listeners")
+	}
+}
+
+func TestShutdownHooks(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+
+	var order []string
+	var stoppedBeforeHooks bool
+	errFlush := errors.New("flush failed")
+	s.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "db")
+		_, hasDeadline := ctx.Deadline()
+		if !hasDeadline {
+			t.Error("expected hooks to receive the shutdown deadline")
+		}
+		return nil
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		order = append(order, "broker")
+		panic("broker close")
+	})
+	s.OnShutdown(func(ctx context.Context) error {
+		select {
+		case <-s.shutdownChan:
+			stoppedBeforeHooks = true
+		default:
+		}
+		order = append(order, "queue")
+		return errFlush
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	err := s.Shutdown(ctx)
+	if strings.Join(order, ",") != "queue,broker,db" {
+		t.Errorf("hooks ran in %v, want reverse registration order", order)
+	}
+	if !stoppedBeforeHooks {
+		t.Error("hooks should run after the listeners stop")
+	}
+	if !errors.Is(err, errFlush) || !strings.Contains(err.Error(), "broker close") {
+		t.Errorf("expected aggregated hook errors, got %v", err)
+	}
+}
+
+func TestMaxMultipartMemoryFromConfig(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+	var got int64
+	s.router.GET("/upload", func(c *hypcontext.Context) {
+		got = c.MaxMultipartMemory()
+	})
+
+	s.wrapHandler(s.router).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/upload", nil))
+	if got != 32<<20 {
+		t.Errorf("default max_multipart_memory = %d, want 32MB", got)
+	}
+
+	cfg.Server.MaxMultipartMemory = 128 << 20
+	s.wrapHandler(s.router).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/upload", nil))
+	if got != 128<<20 {
+		t.Errorf("configured max_multipart_memory = %d, want 128MB", got)
+	}
+}
+
+func TestStartHooks(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+
+	var order []string
+	errDB := errors.New("connection refused")
+	s.OnStart("config", func(ctx context.Context) error {
+		order = append(order, "config")
+		s.OnShutdown(func(context.Context) error {
+			order = append(order, "close config")
+			return nil
+		})
+		return nil
+	})
+	s.OnStart("database", func(ctx context.Context) error {
+		order = append(order, "database")
+		return errDB
+	})
+	s.OnStart("migrations", func(ctx context.Context) error {
+		order = append(order, "migrations")
+		return nil
+	})
+
+	err := s.Start()
+	if strings.Join(order, ",") != "config,database,close config" {
+		t.Errorf("hooks ran in %v, want registration order stopping at the failure, then cleanup", order)
+	}
+	if !errors.Is(err, errDB) || !strings.Contains(err.Error(), `"database"`) {
+		t.Errorf("expected error naming the failed hook, got %v", err)
+	}
+	if s.httpServer != nil {
+		t.Error("no listener should be opened when a start hook fails")
+	}
+
+	// 已執行過的關閉 hook 不會在 Shutdown 時重複執行
+	s.runShutdownHooks(context.Background())
+	if n := len(order); n != 3 {
+		t.Errorf("shutdown hooks ran again: %v", order)
+	}
+}
+
+func TestStartHookPanic(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	s := New(&cfg, logger.NewLogger())
+	s.OnStart("cache", func(ctx context.Context) error {
+		panic("nil client")
+	})
+
+	err := s.runStartHooks()
+	if err == nil || !strings.Contains(err.Error(), `"cache"`) || !strings.Contains(err.Error(), "nil client") {
+		t.Errorf("expected panic converted to error, got %v", err)
+	}
+}
+
+// --- gRPC 共用埠測試 ---
+
+func TestGRPCSharesPortWithRouter(t *testing.T) {
+	cfg := config.Config{}
+	cfg.ApplyDefaults()
+	cfg.Server.RequestTimeout = time.Second // gRPC 不應經過 timeoutWriter
+	s := New(&cfg, logger.NewLogger())
+	s.router.GET("/ping", func(c *hypcontext.Context) {
+		c.String(http.StatusOK, "pong")
+	})
+
+	gs := grpc.NewServer()
+	hs := grpchealth.NewServer()
+	hs.SetServingStatus("demo", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(gs, hs)
+	s.RegisterGRPC(gs)
+
+	ts := httptest.NewServer(s.h2Handler(&http2.Server{}))
+	defer ts.Close()
+
+	conn, err := grpc.NewClient(strings.TrimPrefix(ts.URL, "http://"),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	resp, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "demo"})
+	if err != nil {
+		t.Fatalf("gRPC Check: %v", err)
+	}
+	if resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("status = %v, want SERVING", resp.Status)
+	}
+
+	res, err := http.Get(ts.URL + "/ping")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(res.Body)
+	res.Body.Close()
+	if res.StatusCode != http.StatusOK || string(body) != "pong" {
+		t.Errorf("HTTP route = %d %q, want 200 pong", res.StatusCode, body)
+	}
+}
+
+func TestIsGRPCRequest(t *testing.T) {
+	for ct, want := range map[string]bool{
+		"application/grpc":              true,
+		"application/grpc+proto":        true,
+		"application/grpc; charset=utf": true,
+		"application/grpc-web":          false,
+		"application/json":              false,
+	} {
+		r := httptest.NewRequest("POST", "/svc/Method", nil)
+		r.ProtoMajor = 2
+		r.Header.Set("Content-Type", ct)
+		if got := isGRPCRequest(r); got != want {
+			t.Errorf("isGRPCRequest(%q) = %v, want %v", ct, got, want)
+		}
+	}
+
+	r := httptest.NewRequest("POST", "/svc/Method", nil)
+	r.Header.Set("Content-Type", "application/grpc")
+	if isGRPCRequest(r) {
+		t.Error("HTTP/1.1 request must not be routed to gRPC")
+	}
+}
+
+// --- 錯誤回報測試 ---
+
+func TestSetErrorReporter(t *testing.T) {
+	s := newTimeoutTestServer(0)
+	var mu sync.Mutex
+	var reported []error
+	s.SetErrorReporter(hypcontext.ErrorReporterFunc(func(err error, _ map[string]interface{}) {
+		mu.Lock()
+		reported = append(reported, err)
+		mu.Unlock()
+	}))
+	if _, limited := s.errorReporter.(*hypcontext.RateLimitedReporter); !limited {
+		t.Fatalf("expected default rate limiting, got %T", s.errorReporter)
+	}
+	s.router.GET("/fail", func(c *hypcontext.Context) {
+		c.AbortWithError(http.StatusInternalServerError, errors.New("db down"))
+	})
+
+	for i := 0; i < hypcontext.DefaultReportLimit+3; i++ {
+		s.wrapHandler(s.router).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/fail", nil))
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(reported) != hypcontext.DefaultReportLimit {
+		t.Errorf("reported %d errors, want %d", len(reported), hypcontext.DefaultReportLimit)
+	}
+
+	custom := hypcontext.LimitReports(hypcontext.NopErrorReporter, 1, time.Second)
+	s.SetErrorReporter(custom)
+	if s.errorReporter != custom {
+		t.Error("an already rate-limited reporter should be used as-is")
+	}
+}
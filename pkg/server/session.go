@@ -0,0 +1,124 @@
+// @chris
+package server
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"net/http"
+	"sync"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+)
+
+// ===== Session 恢復與 0-RTT =====
+//
+// TCP（HTTP/1.1、HTTP/2）：無狀態 session ticket，以定期輪替的金鑰加密，
+// 多實例只要共用同一組設定即可，伺服器不需保存狀態。Go 的 TLS 伺服器不支援 early data，
+// 因此 TCP 路徑只有 1-RTT 恢復，不存在重放問題。
+//
+// QUIC（HTTP/3）：ticket 只是 SessionCache 中的隨機鍵，恢復時以 GetAndDelete 取出，
+// 每張 ticket 只能使用一次，同一實例內的 0-RTT 重放會退回完整握手。
+// 但跨實例、或攻擊者在 ticket 被使用前搶先重放，仍可能讓 early data 被處理兩次，
+// 因此 withEarlyDataGuard 只放行安全方法，其餘以 425 要求客戶端於握手後重送。
+
+// maxSessionTicketKeys 保留的金鑰數（目前金鑰 + 兩個舊金鑰）
+const maxSessionTicketKeys = 3
+
+// sessionTicketKeys 輪替中的 session ticket 金鑰，keys[0] 用於加密，其餘僅用於解密
+type sessionTicketKeys struct {
+	mu      sync.Mutex
+	keys    [][32]byte
+	configs []*tls.Config
+}
+
+// apply 將目前金鑰套用到 tls.Config，並在之後的輪替中同步更新
+func (k *sessionTicketKeys) apply(cfg *tls.Config) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.keys) == 0 {
+		if err := k.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	cfg.SetSessionTicketKeys(k.keys)
+	k.configs = append(k.configs, cfg)
+	return nil
+}
+
+// rotate 產生新金鑰並淘汰最舊的金鑰
+func (k *sessionTicketKeys) rotate() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.rotateLocked()
+}
+
+func (k *sessionTicketKeys) rotateLocked() error {
+	var key [32]byte
+	if _, err := rand.Read(key[:]); err != nil {
+		return err
+	}
+	keys := append([][32]byte{key}, k.keys...)
+	if len(keys) > maxSessionTicketKeys {
+		keys = keys[:maxSessionTicketKeys]
+	}
+	k.keys = keys
+	for _, cfg := range k.configs {
+		cfg.SetSessionTicketKeys(keys)
+	}
+	return nil
+}
+
+// applyTicketKeys 為 TCP 監聽的 tls.Config 套用輪替金鑰；未設定輪替週期時沿用 crypto/tls 的自動輪替
+func (s *Server) applyTicketKeys(cfg *tls.Config) error {
+	if s.config.Server.TLS.SessionTicketRotation <= 0 {
+		return nil
+	}
+	return s.ticketKeys.apply(cfg)
+}
+
+// startTicketKeyRotation 依 tls.session_ticket_rotation 定期輪替金鑰，直到伺服器關閉
+func (s *Server) startTicketKeyRotation() {
+	period := s.config.Server.TLS.SessionTicketRotation
+	if !s.config.Server.TLS.Enabled || period <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.ticketKeys.rotate(); err != nil {
+					s.logger.Warningf("Session ticket key rotation failed: %v", err)
+				}
+			case <-s.shutdownChan:
+				return
+			}
+		}
+	}()
+}
+
+// withEarlyDataGuard 拒絕以 0-RTT 送達的非安全方法請求（RFC 8470）
+func (s *Server) withEarlyDataGuard(h http.Handler) http.Handler {
+	if !s.config.Server.QUIC.Allow0RTT {
+		return h
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS != nil && !r.TLS.HandshakeComplete && !isSafeMethod(r.Method) {
+			http.Error(w, http.StatusText(hypcontext.StatusTooEarly), hypcontext.StatusTooEarly)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// isSafeMethod 是否為 RFC 9110 定義的安全方法
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+// @chris
+package server
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/quic-go/quic-go"
+)
+
+// withQuicConn 作為 http3.Server.ConnContext，將 QUIC 連線放入 context，供逾時計算與請求指標取得 RTT
+func withQuicConn(ctx context.Context, conn *quic.Conn) context.Context {
+	return hypcontext.WithQUICConn(ctx, conn)
+}
+
+// requestTimeout 計算本次請求的逾時時間
+// HTTP/3 與 Timeout 中間件一致：依連線 RTT 延長 2 倍 RTT
+func (s *Server) requestTimeout(r *http.Request) time.Duration {
+	timeout := s.config.Server.RequestTimeout
+	if r.ProtoMajor == 3 {
+		if conn, ok := hypcontext.QUICConnFromContext(r.Context()); ok {
+			if rtt := conn.ConnectionStats().SmoothedRTT; rtt > 0 {
+				timeout += rtt * 2
+			}
+		}
+	}
+	return timeout
+}
+
+// withRequestTimeout 套用 server 層的 request_timeout（未設定時直接回傳原處理器）
+// 逾時且尚未寫出回應時回傳 503；若處理器已開始寫出則只取消 context 並等待其結束，避免重複寫入
+func (s *Server) withRequestTimeout(h http.Handler) http.Handler {
+	if s.config.Server.RequestTimeout <= 0 {
+		return h
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		control := hypcontext.NewRequestTimeoutControl()
+		r = r.WithContext(hypcontext.WithRequestTimeoutControl(ctx, control))
+
+		tw := &timeoutWriter{w: w, header: make(http.Header), hijacked: make(chan struct{})}
+		done := make(chan struct{})
+		panicChan := make(chan interface{}, 1)
+
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+			}()
+			h.ServeHTTP(tw, r)
+			close(done)
+		}()
+
+		timer := time.NewTimer(s.requestTimeout(r))
+		defer timer.Stop()
+
+		select {
+		case <-done:
+			return
+		case p := <-panicChan:
+			panic(p)
+		case <-control.Disabled():
+			// 路由已豁免逾時
+		case <-tw.hijacked:
+			// 連線已被接管（WebSocket 升級），不再受請求逾時限制
+		case <-timer.C:
+			cancel()
+			if tw.timeout() {
+				return
+			}
+			// 處理器已開始寫出，無法再改寫狀態碼
+		}
+
+		select {
+		case <-done:
+		case p := <-panicChan:
+			panic(p)
+		}
+	})
+}
+
+// timeoutWriter 以互斥鎖保護寫入，確保逾時回應與處理器的回應只會有一方寫出
+type timeoutWriter struct {
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	header      http.Header
+	wroteHeader bool
+	timedOut    bool
+	hijacked    chan struct{} // Hijack 成功後關閉，停止逾時計時
+}
+
+// Header 回傳處理器專用的標頭，於寫出狀態碼時才複製到底層 ResponseWriter
+func (tw *timeoutWriter) Header() http.Header {
+	return tw.header
+}
+
+// WriteHeader 寫出狀態碼；逾時後忽略
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.writeHeaderLocked(code)
+}
+
+// Write 寫出回應內容；逾時後回傳 http.ErrHandlerTimeout
+func (tw *timeoutWriter) Write(b []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	return tw.w.Write(b)
+}
+
+// Flush 支援串流回應
+func (tw *timeoutWriter) Flush() {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return
+	}
+	tw.writeHeaderLocked(http.StatusOK)
+	if f, ok := tw.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack 支援 WebSocket 等協定升級；接管後停止逾時計時，逾時後回傳 http.ErrHandlerTimeout
+func (tw *timeoutWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return nil, nil, http.ErrHandlerTimeout
+	}
+	hj, ok := tw.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, http.ErrNotSupported
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	// 連線已交由處理器，逾時回應不可再寫出
+	tw.wroteHeader = true
+	close(tw.hijacked)
+	return conn, rw, nil
+}
+
+// Unwrap 回傳底層 ResponseWriter，供 http.ResponseController 使用
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.w
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader = true
+	dst := tw.w.Header()
+	for k, v := range tw.header {
+		dst[k] = append([]string(nil), v...)
+	}
+	tw.w.WriteHeader(code)
+}
+
+// timeout 嘗試寫出 503 逾時回應；處理器已寫出時回傳 false
+func (tw *timeoutWriter) timeout() bool {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.wroteHeader {
+		return false
+	}
+	tw.timedOut = true
+	http.Error(tw.w, http.StatusText(http.StatusServiceUnavailable), http.StatusServiceUnavailable)
+	return true
+}
@@ -0,0 +1,35 @@
+// @chris
+package server
+
+import (
+	"context"
+
+	"github.com/maoxiaoyue/hypgo/pkg/tracing"
+)
+
+// ===== 分散式追蹤 =====
+
+// setupTracing 依 monitoring 配置初始化 exporter（trace_enabled 為 false 時不做事）
+// 初始化失敗僅記錄警告，不阻止伺服器啟動
+func (s *Server) setupTracing() {
+	shutdown, err := tracing.Setup(context.Background(), s.config.Monitoring)
+	if err != nil {
+		s.logger.Warningf("Tracing disabled: %v", err)
+		return
+	}
+	s.traceShutdown = shutdown
+	if s.config.Monitoring.TraceEnabled {
+		s.logger.Infof("Tracing enabled (%s → %s)", s.config.Monitoring.TraceProvider, s.config.Monitoring.TraceEndpoint)
+	}
+}
+
+// flushTracing 送出尚未匯出的 span 並關閉 exporter
+func (s *Server) flushTracing(ctx context.Context) {
+	if s.traceShutdown == nil {
+		return
+	}
+	if err := s.traceShutdown(ctx); err != nil {
+		s.logger.Warningf("Tracing flush: %v", err)
+	}
+	s.traceShutdown = nil
+}
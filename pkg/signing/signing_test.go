@@ -0,0 +1,85 @@
+package signing
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var secret = []byte("test-secret")
+
+func TestSignAndVerify(t *testing.T) {
+	link, err := SignURL("https://example.com/reset?lang=zh", url.Values{"user": {"42"}}, time.Minute, secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	u, _ := url.Parse(link)
+	if u.Query().Get("user") != "42" || u.Query().Get("lang") != "zh" || u.Query().Get(ExpiresParam) == "" {
+		t.Fatalf("Expected params and expiry in %s", link)
+	}
+	if err := VerifySignedURL(link, secret); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+
+	// 只簽路徑與查詢，host 不同仍可驗證（反向代理後方）
+	if err := VerifySignedURL(strings.Replace(link, "https://example.com", "http://10.0.0.5:8080", 1), secret); err != nil {
+		t.Errorf("Expected host-independent signature, got %v", err)
+	}
+
+	// 查詢參數順序不影響
+	q := u.Query()
+	reordered := "/reset?user=42&" + SignatureParam + "=" + q.Get(SignatureParam) + "&lang=zh&" + ExpiresParam + "=" + q.Get(ExpiresParam)
+	if err := VerifySignedURL(reordered, secret); err != nil {
+		t.Errorf("Expected parameter order to be irrelevant, got %v", err)
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	link, _ := SignURL("/downloads/report.pdf", url.Values{"user": {"42"}}, time.Minute, secret)
+	u, _ := url.Parse(link)
+	q := u.Query()
+
+	cases := map[string]string{
+		"changed param": strings.Replace(link, "user=42", "user=43", 1),
+		"added param":   link + "&admin=1",
+		"changed path":  strings.Replace(link, "report.pdf", "secret.pdf", 1),
+		"extended expiry": strings.Replace(link, ExpiresParam+"="+q.Get(ExpiresParam),
+			ExpiresParam+"="+strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10), 1),
+	}
+	for name, tampered := range cases {
+		if err := VerifySignedURL(tampered, secret); !errors.Is(err, ErrInvalidSignature) {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+
+	if err := VerifySignedURL(link, []byte("other-secret")); !errors.Is(err, ErrInvalidSignature) {
+		t.Errorf("Expected wrong secret to fail, got %v", err)
+	}
+	if err := VerifySignedURL("/downloads/report.pdf?user=42", secret); !errors.Is(err, ErrMissingSignature) {
+		t.Errorf("Expected ErrMissingSignature, got %v", err)
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	u, _ := url.Parse("/reset")
+	q := url.Values{ExpiresParam: {strconv.FormatInt(time.Now().Add(-time.Second).Unix(), 10)}}
+	u.RawQuery = q.Encode()
+	q.Set(SignatureParam, sign(u.EscapedPath(), u.RawQuery, secret))
+	u.RawQuery = q.Encode()
+
+	if err := Verify(u, secret); !errors.Is(err, ErrExpired) {
+		t.Errorf("Expected ErrExpired, got %v", err)
+	}
+}
+
+func TestSignURLValidation(t *testing.T) {
+	if _, err := SignURL("/x", nil, 0, secret); err == nil {
+		t.Error("Expected error for non-positive ttl")
+	}
+	if _, err := SignURL("/x", nil, time.Minute, nil); err == nil {
+		t.Error("Expected error for empty secret")
+	}
+}
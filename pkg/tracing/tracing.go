@@ -0,0 +1,124 @@
+// Package tracing 初始化 OpenTelemetry 追蹤並提供框架共用的 Tracer。
+//
+// Setup 依 monitoring 配置建立 OTLP/HTTP exporter、設定全域 TracerProvider
+// 與 W3C traceparent / baggage propagator；middleware.Tracing 與 hidb.WithTracing
+// 皆透過 Tracer() 取得全域 provider 的 Tracer，未呼叫 Setup 時為 no-op。
+//
+// EX：
+//
+//	shutdown, err := tracing.Setup(ctx, cfg.Monitoring)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer shutdown(context.Background()) // flush 尚未送出的 span
+//
+// @chris
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/maoxiaoyue/hypgo/pkg/config"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName 框架產生之 span 的 instrumentation scope
+const InstrumentationName = "github.com/maoxiaoyue/hypgo"
+
+// ShutdownFunc flush 並關閉 exporter
+type ShutdownFunc func(ctx context.Context) error
+
+// Tracer 取得全域 TracerProvider 的框架 Tracer
+func Tracer() trace.Tracer {
+	return otel.Tracer(InstrumentationName)
+}
+
+// Propagator 取得全域 propagator
+func Propagator() propagation.TextMapPropagator {
+	return otel.GetTextMapPropagator()
+}
+
+// Setup 依配置初始化追蹤；trace_enabled 為 false 時不做任何事並回傳 no-op shutdown
+func Setup(ctx context.Context, cfg config.MonitoringConfig) (ShutdownFunc, error) {
+	noop := func(context.Context) error { return nil }
+	if !cfg.TraceEnabled {
+		return noop, nil
+	}
+
+	exporter, err := newExporter(ctx, cfg)
+	if err != nil {
+		return noop, err
+	}
+
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = "hypgo"
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return noop, fmt.Errorf("tracing: build resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(newSampler(cfg.TraceSampleRate)),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	return provider.Shutdown, nil
+}
+
+// newSampler 依 trace_sample_rate 建立取樣器：未設定時全部取樣，0 時一律不取樣（含上游已取樣的請求），
+// 其餘依比例取樣根 span 並沿用上游的取樣決定
+func newSampler(rate *float64) sdktrace.Sampler {
+	switch {
+	case rate == nil || *rate >= 1:
+		return sdktrace.ParentBased(sdktrace.AlwaysSample())
+	case *rate <= 0:
+		return sdktrace.NeverSample()
+	default:
+		return sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*rate))
+	}
+}
+
+// newExporter 建立 OTLP/HTTP exporter
+// endpoint 含 scheme 時視為完整 URL（http:// 自動使用明文），否則為 host:port
+func newExporter(ctx context.Context, cfg config.MonitoringConfig) (sdktrace.SpanExporter, error) {
+	switch strings.ToLower(cfg.TraceProvider) {
+	case "", "otlp", "jaeger":
+	default:
+		return nil, fmt.Errorf("tracing: unsupported trace_provider %q (use otlp or jaeger)", cfg.TraceProvider)
+	}
+
+	var opts []otlptracehttp.Option
+	switch endpoint := cfg.TraceEndpoint; {
+	case endpoint == "":
+		// 交由 OTEL_EXPORTER_OTLP_* 環境變數決定
+	case strings.Contains(endpoint, "://"):
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	default:
+		opts = append(opts, otlptracehttp.WithEndpoint(endpoint))
+	}
+
+	exporter, err := otlptracehttp.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("tracing: create exporter: %w", err)
+	}
+	return exporter, nil
+}
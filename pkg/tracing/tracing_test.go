@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/config"
+)
+
+func TestSetupDisabled(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.MonitoringConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected no-op shutdown, got %v", err)
+	}
+}
+
+func TestSetupUnsupportedProvider(t *testing.T) {
+	_, err := Setup(context.Background(), config.MonitoringConfig{
+		TraceEnabled:  true,
+		TraceProvider: "zipkin",
+	})
+	if err == nil {
+		t.Error("Expected error for unsupported provider")
+	}
+}
+
+func TestSetupOTLP(t *testing.T) {
+	shutdown, err := Setup(context.Background(), config.MonitoringConfig{
+		TraceEnabled:  true,
+		TraceProvider: "jaeger",
+		TraceEndpoint: "http://127.0.0.1:4318/v1/traces",
+		ServiceName:   "test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Errorf("Expected clean shutdown without spans, got %v", err)
+	}
+}
+
+func TestNewSampler(t *testing.T) {
+	tests := []struct {
+		rate *float64
+		want string
+	}{
+		{nil, "ParentBased{root:AlwaysOnSampler"},
+		{config.SampleRate(0), "AlwaysOffSampler"},
+		{config.SampleRate(0.25), "ParentBased{root:TraceIDRatioBased{0.25}"},
+	}
+	for _, tt := range tests {
+		if got := newSampler(tt.rate).Description(); !strings.HasPrefix(got, tt.want) {
+			t.Errorf("rate %v: got %s, want prefix %s", tt.rate, got, tt.want)
+		}
+	}
+}
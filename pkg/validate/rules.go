@@ -0,0 +1,331 @@
+// @chris
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ===== 結構化錯誤 =====
+
+// Error 單一欄位的驗證錯誤
+// Rule 為對應的 validate tag（email、min、strong_password 等），Param 為 tag 參數
+type Error struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errors 多個欄位的驗證錯誤，依檢查順序排列
+type Errors []*Error
+
+func (e Errors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		msgs[i] = fe.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Get 取得指定欄位的第一個錯誤，沒有時為 nil
+func (e Errors) Get(field string) *Error {
+	for _, fe := range e {
+		if fe.Field == field {
+			return fe
+		}
+	}
+	return nil
+}
+
+// ===== 可組合的規則 =====
+
+// Rule 單一字串值的驗證規則，可單獨使用（Check / Collector），
+// 也以同名 validate tag 供 struct 驗證使用，兩者的錯誤訊息一致
+type Rule struct {
+	name  string
+	param string
+	check func(value string) bool
+}
+
+// Name 規則對應的 validate tag 名稱
+func (r Rule) Name() string {
+	return r.name
+}
+
+// Param 規則參數
+func (r Rule) Param() string {
+	return r.param
+}
+
+// Email 合法的 email 位址（與 validate:"email" 相同的判斷）
+func Email() Rule {
+	return Rule{name: "email", check: func(v string) bool {
+		return Default().Var(v, "email") == nil
+	}}
+}
+
+// MinLen 至少 n 個字元（以 rune 計，與 validate:"min=n" 對字串的判斷相同）
+func MinLen(n int) Rule {
+	return Rule{name: "min", param: strconv.Itoa(n), check: func(v string) bool {
+		return utf8.RuneCountInString(v) >= n
+	}}
+}
+
+// MaxLen 至多 n 個字元（以 rune 計，與 validate:"max=n" 對字串的判斷相同）
+func MaxLen(n int) Rule {
+	return Rule{name: "max", param: strconv.Itoa(n), check: func(v string) bool {
+		return utf8.RuneCountInString(v) <= n
+	}}
+}
+
+// MaxBytes 至多 n 個位元組（UTF-8 編碼長度），用於 bcrypt 等以位元組計算上限的場合
+// struct tag 形式為 validate:"max_bytes=n"
+func MaxBytes(n int) Rule {
+	return Rule{name: "max_bytes", param: strconv.Itoa(n), check: func(v string) bool {
+		return len(v) <= n
+	}}
+}
+
+// Alphanumeric 僅含 ASCII 英文字母與數字，且不可為空（與 validate:"alphanum" 相同）
+func Alphanumeric() Rule {
+	return Rule{name: "alphanum", check: isAlphanumeric}
+}
+
+// Regex 符合正規表示式 pattern（需完整比對時自行加上 ^ 與 $）；pattern 無法編譯時 panic（與 regexp.MustCompile 相同）
+// struct tag 形式為 validate:"regex=^[a-z]+$"，pattern 不可含逗號與 |（validator 的分隔符號）
+func Regex(pattern string) Rule {
+	re, err := compileRegex(pattern)
+	if err != nil {
+		panic(err)
+	}
+	return Rule{name: "regex", param: pattern, check: re.MatchString}
+}
+
+// StrongPassword 至少 8 個字元，且同時包含大寫、小寫、數字與符號
+// struct tag 形式為 validate:"strong_password"
+func StrongPassword() Rule {
+	return Rule{name: "strong_password", check: isStrongPassword}
+}
+
+// In 值必須為 values 之一（區分大小寫，與 validate:"oneof=..." 相同，但允許值含空白）
+func In(values ...string) Rule {
+	return Rule{name: "oneof", param: strings.Join(values, " "), check: func(v string) bool {
+		for _, allowed := range values {
+			if v == allowed {
+				return true
+			}
+		}
+		return false
+	}}
+}
+
+// Check 依序以 rules 檢查 value，回傳第一個失敗規則的 *Error，全部通過時為 nil
+//
+// EX：
+//
+//	if err := validate.Check("username", name, validate.MinLen(3), validate.MaxLen(32), validate.Alphanumeric()); err != nil {
+//	    return err
+//	}
+func Check(field, value string, rules ...Rule) error {
+	for _, r := range rules {
+		if !r.check(value) {
+			return newError(field, r.name, r.param)
+		}
+	}
+	return nil
+}
+
+// Collector 彙整多個欄位的檢查結果，每個欄位只保留第一個錯誤
+//
+// EX：
+//
+//	var v validate.Collector
+//	v.Check("email", req.Email, validate.Email())
+//	v.Check("password", req.Password, validate.StrongPassword())
+//	if err := v.Err(); err != nil {
+//	    return err // validate.Errors
+//	}
+type Collector struct {
+	errs Errors
+}
+
+// Check 以 rules 檢查單一欄位，失敗時記錄錯誤；回傳是否通過
+func (c *Collector) Check(field, value string, rules ...Rule) bool {
+	if err := Check(field, value, rules...); err != nil {
+		c.errs = append(c.errs, err.(*Error))
+		return false
+	}
+	return true
+}
+
+// Add 加入自訂錯誤（例如需要查詢資料庫的唯一性檢查）
+func (c *Collector) Add(field, message string) {
+	c.errs = append(c.errs, &Error{Field: field, Rule: "custom", Message: message})
+}
+
+// Err 沒有錯誤時為 nil，否則回傳 Errors
+func (c *Collector) Err() error {
+	if len(c.errs) == 0 {
+		return nil
+	}
+	return c.errs
+}
+
+// ===== struct 驗證 =====
+
+// ValidateStruct 以共用規則表驗證 struct，回傳所有欄位的錯誤（Errors）而非只有第一個
+// Field 為 json 路徑（巢狀以 . 連接）；s 不是 struct 時回傳 validator 的原始錯誤
+//
+// EX：
+//
+//	type SignUp struct {
+//	    Username string `json:"username" validate:"required,min=3,max=32,alphanum"`
+//	    Password string `json:"password" validate:"required,strong_password"`
+//	}
+//	if err := validate.ValidateStruct(&req); err != nil {
+//	    var errs validate.Errors
+//	    errors.As(err, &errs)
+//	}
+func ValidateStruct(s interface{}) error {
+	err := Default().Struct(s)
+	if err == nil {
+		return nil
+	}
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	errs := make(Errors, len(verrs))
+	for i, fe := range verrs {
+		// Namespace 形如 "User.address.city"，去掉頂層型別名稱
+		field := fe.Namespace()
+		if _, rest, ok := strings.Cut(field, "."); ok {
+			field = rest
+		}
+		errs[i] = newError(field, fe.Tag(), fe.Param())
+	}
+	return errs
+}
+
+// registerRules 將 validator 未內建的規則註冊為 validate tag（Default 初始化時呼叫）
+func registerRules(v *validator.Validate) {
+	v.RegisterValidation("strong_password", func(fl validator.FieldLevel) bool {
+		return fl.Field().Kind() == reflect.String && isStrongPassword(fl.Field().String())
+	})
+	v.RegisterValidation("max_bytes", func(fl validator.FieldLevel) bool {
+		n, err := strconv.Atoi(fl.Param())
+		return err == nil && fl.Field().Kind() == reflect.String && len(fl.Field().String()) <= n
+	})
+	// tag 的 pattern 無法編譯時一律驗證失敗並於訊息中指出，不在請求處理中 panic
+	v.RegisterValidation("regex", func(fl validator.FieldLevel) bool {
+		if fl.Field().Kind() != reflect.String {
+			return false
+		}
+		re, err := compileRegex(fl.Param())
+		return err == nil && re.MatchString(fl.Field().String())
+	})
+}
+
+// ===== 內部 =====
+
+// newError 以規則名稱產生訊息，與 Context.BindAndValidate 的訊息措辭一致
+func newError(field, rule, param string) *Error {
+	return &Error{Field: field, Rule: rule, Param: param, Message: message(field, rule, param)}
+}
+
+func message(field, rule, param string) string {
+	name := field
+	if name == "" {
+		name = "value"
+	}
+	switch rule {
+	case "required":
+		return name + " is required"
+	case "email":
+		return name + " must be a valid email address"
+	case "min":
+		return name + " must be at least " + param
+	case "max":
+		return name + " must not exceed " + param
+	case "max_bytes":
+		return name + " must not exceed " + param + " bytes"
+	case "oneof":
+		return name + " must be one of [" + param + "]"
+	case "alphanum":
+		return name + " must contain only letters and digits"
+	case "regex":
+		if _, err := compileRegex(param); err != nil {
+			return name + " has an invalid regex pattern " + param + ": " + err.Error()
+		}
+		return name + " must match " + param
+	case "strong_password":
+		return name + " must be at least 8 characters and include upper and lower case letters, a digit and a symbol"
+	default:
+		return fmt.Sprintf("%s failed %s validation", name, rule)
+	}
+}
+
+func isAlphanumeric(v string) bool {
+	if v == "" {
+		return false
+	}
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		if !('a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isStrongPassword(v string) bool {
+	if utf8.RuneCountInString(v) < 8 {
+		return false
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range v {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r):
+			symbol = true
+		}
+	}
+	return upper && lower && digit && symbol
+}
+
+// regexCache struct tag 的 pattern 只編譯一次，編譯失敗的結果也一併快取
+var regexCache sync.Map // pattern -> compiledRegex
+
+type compiledRegex struct {
+	re  *regexp.Regexp
+	err error
+}
+
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if c, ok := regexCache.Load(pattern); ok {
+		return c.(compiledRegex).re, c.(compiledRegex).err
+	}
+	re, err := regexp.Compile(pattern)
+	regexCache.Store(pattern, compiledRegex{re: re, err: err})
+	return re, err
+}
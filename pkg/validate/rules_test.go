@@ -0,0 +1,141 @@
+package validate
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		rule  Rule
+		value string
+		ok    bool
+	}{
+		{Email(), "a@example.com", true},
+		{Email(), "not-an-email", false},
+		{MinLen(3), "日本語", true},
+		{MinLen(3), "ab", false},
+		{MaxLen(3), "abcd", false},
+		{MaxBytes(9), "日本語", true},
+		{MaxBytes(8), "日本語", false},
+		{Alphanumeric(), "abc123", true},
+		{Alphanumeric(), "abc_123", false},
+		{Alphanumeric(), "", false},
+		{Regex(`^[a-z]+-\d+$`), "order-42", true},
+		{Regex(`^[a-z]+-\d+$`), "Order-42", false},
+		{StrongPassword(), "Sup3r$ecret", true},
+		{StrongPassword(), "password1", false},
+		{StrongPassword(), "Ab1!", false},
+		{In("draft", "in review"), "in review", true},
+		{In("draft", "in review"), "published", false},
+	}
+	for _, tt := range tests {
+		err := Check("field", tt.value, tt.rule)
+		if (err == nil) != tt.ok {
+			t.Errorf("%s(%s) on %q: got %v, want ok=%v", tt.rule.Name(), tt.rule.Param(), tt.value, err, tt.ok)
+		}
+	}
+}
+
+func TestCheckReturnsFirstFailure(t *testing.T) {
+	err := Check("username", "a!", MinLen(3), Alphanumeric())
+	var fe *Error
+	if !errors.As(err, &fe) || fe.Rule != "min" || fe.Param != "3" || fe.Message != "username must be at least 3" {
+		t.Errorf("unexpected error %#v", err)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	var v Collector
+	v.Check("email", "a@example.com", Email())
+	v.Check("password", "short", MinLen(8), StrongPassword())
+	v.Add("username", "username is taken")
+
+	var errs Errors
+	if !errors.As(v.Err(), &errs) || len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %v", v.Err())
+	}
+	if errs.Get("password").Rule != "min" || errs.Get("username").Rule != "custom" || errs.Get("email") != nil {
+		t.Errorf("unexpected errors %v", errs)
+	}
+
+	var empty Collector
+	if empty.Err() != nil {
+		t.Error("expected nil error when nothing failed")
+	}
+}
+
+func TestValidateStruct(t *testing.T) {
+	type address struct {
+		City string `json:"city" validate:"required"`
+	}
+	type signUp struct {
+		Username string  `json:"username" validate:"required,min=3,alphanum"`
+		Password string  `json:"password" validate:"strong_password"`
+		Code     string  `json:"code" validate:"regex=^[A-Z]{3}$"`
+		Address  address `json:"address"`
+	}
+
+	err := ValidateStruct(&signUp{Username: "a_", Password: "password", Code: "abc"})
+	var errs Errors
+	if !errors.As(err, &errs) {
+		t.Fatalf("expected Errors, got %T %v", err, err)
+	}
+	want := map[string]string{
+		"username":     "min",
+		"password":     "strong_password",
+		"code":         "regex",
+		"address.city": "required",
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("expected %d errors, got %v", len(want), errs)
+	}
+	for field, rule := range want {
+		if fe := errs.Get(field); fe == nil || fe.Rule != rule {
+			t.Errorf("%s: got %+v, want rule %s", field, fe, rule)
+		}
+	}
+
+	// tag 與單獨使用的規則產生相同訊息
+	if errs.Get("password").Message != Check("password", "password", StrongPassword()).Error() {
+		t.Errorf("tag and standalone messages differ: %q", errs.Get("password").Message)
+	}
+
+	ok := signUp{Username: "alice", Password: "Sup3r$ecret", Code: "ABC", Address: address{City: "Taipei"}}
+	if err := ValidateStruct(&ok); err != nil {
+		t.Errorf("expected valid struct, got %v", err)
+	}
+}
+
+func TestValidateStructMaxBytesTag(t *testing.T) {
+	type login struct {
+		Password string `json:"password" validate:"max=8,max_bytes=8"`
+	}
+	// 3 個字元、9 個位元組：max 通過，max_bytes 失敗
+	err := ValidateStruct(&login{Password: "日本語"})
+	var errs Errors
+	if !errors.As(err, &errs) || errs.Get("password") == nil || errs.Get("password").Rule != "max_bytes" {
+		t.Fatalf("expected max_bytes error, got %v", err)
+	}
+	if errs.Get("password").Message != Check("password", "日本語", MaxBytes(8)).Error() {
+		t.Errorf("tag and standalone messages differ: %q", errs.Get("password").Message)
+	}
+}
+
+func TestValidateStructInvalidRegexTag(t *testing.T) {
+	type form struct {
+		Code string `json:"code" validate:"regex=^[a-z+$"`
+	}
+
+	for i := 0; i < 2; i++ {
+		var errs Errors
+		if err := ValidateStruct(&form{Code: "abc"}); !errors.As(err, &errs) {
+			t.Fatalf("expected Errors for invalid pattern, got %v", err)
+		}
+		fe := errs.Get("code")
+		if fe == nil || fe.Rule != "regex" || !strings.Contains(fe.Message, "invalid regex pattern") {
+			t.Errorf("unexpected error: %+v", fe)
+		}
+	}
+}
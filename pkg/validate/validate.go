@@ -35,6 +35,7 @@ func Default() *validator.Validate {
 			}
 			return name
 		})
+		registerRules(v)
 		instance = v
 	})
 	return instance
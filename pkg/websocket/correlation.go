@@ -0,0 +1,81 @@
+// @chris
+package websocket
+
+import (
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+)
+
+// ===== 與 HTTP 升級請求的關聯 =====
+
+// 由升級請求的 Context 複製到客戶端 metadata 的鍵（Hub.ServeHTTP 設定）
+// Context 在升級的處理器返回後會被回收，連線期間需讀取這些值時應使用 metadata 而非 Client.Context
+const (
+	MetadataRequestID = "request_id"
+	MetadataUserID    = "user_id"
+	MetadataRoles     = "roles"
+)
+
+// inheritRequest 複製 HTTP 中間件設定的 request_id、user_id 與 roles，
+// 讓 socket 訊息的日誌能與升級請求的存取日誌對應
+func (c *Client) inheritRequest(ctx *hypcontext.Context) {
+	requestID := ctx.GetString("request_id")
+	if requestID == "" {
+		requestID = ctx.GetHeader(hypcontext.HeaderXRequestID)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if requestID != "" {
+		c.metadata[MetadataRequestID] = requestID
+	}
+	if userID := ctx.GetUserID(); userID != nil {
+		c.metadata[MetadataUserID] = userID
+	}
+	if roles := ctx.GetRoles(); roles != nil {
+		c.metadata[MetadataRoles] = append([]string(nil), roles...)
+	}
+}
+
+// RequestID 升級請求的 request id，沒有時為空字串
+func (c *Client) RequestID() string {
+	id, _ := c.GetMetadata(MetadataRequestID)
+	s, _ := id.(string)
+	return s
+}
+
+// UserID 升級請求經認證中間件設定的使用者 ID，未認證時為 nil
+func (c *Client) UserID() interface{} {
+	id, _ := c.GetMetadata(MetadataUserID)
+	return id
+}
+
+// Roles 升級請求經認證中間件設定的角色
+func (c *Client) Roles() []string {
+	roles, _ := c.GetMetadata(MetadataRoles)
+	r, _ := roles.([]string)
+	return r
+}
+
+// Logger 帶 client_id、request_id、user_id 欄位的 Hub logger，用於記錄與此連線相關的訊息
+//
+// EX：
+//
+//	hub.SetCallbacks(nil, nil, func(c *websocket.Client, msg *websocket.Message) {
+//	    c.Logger().Infow("chat message", "type", msg.Type)
+//	})
+func (c *Client) Logger() *logger.Logger {
+	return c.Hub.logger.With(c.logFields()...)
+}
+
+// logFields 連線的關聯欄位
+func (c *Client) logFields() []interface{} {
+	fields := []interface{}{"client_id", c.ID}
+	if id := c.RequestID(); id != "" {
+		fields = append(fields, "request_id", id)
+	}
+	if id := c.UserID(); id != nil {
+		fields = append(fields, "user_id", id)
+	}
+	return fields
+}
@@ -0,0 +1,70 @@
+package websocket
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func TestClientInheritsUpgradeRequest(t *testing.T) {
+	hub := NewHub(logger.NewLogger(), DefaultConfig)
+	connected := make(chan *Client, 1)
+	hub.SetCallbacks(func(c *Client) { connected <- c }, nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	roles := []string{"editor"}
+	r := router.New()
+	r.Use(func(c *hypcontext.Context) {
+		c.SetRequestID("req-42")
+		c.SetUserID(int64(7))
+		c.SetRoles(roles)
+		c.Next()
+	})
+	r.GET("/ws", hub.ServeHTTP)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := <-connected
+	if client.RequestID() != "req-42" || client.UserID() != int64(7) || !reflect.DeepEqual(client.Roles(), []string{"editor"}) {
+		t.Errorf("unexpected correlation values: request_id=%q user_id=%v roles=%v", client.RequestID(), client.UserID(), client.Roles())
+	}
+
+	// roles 為複本，升級後修改原 slice 不影響客戶端
+	roles[0] = "admin"
+	if client.Roles()[0] != "editor" {
+		t.Errorf("expected roles to be copied, got %v", client.Roles())
+	}
+
+	fields := client.logFields()
+	if !reflect.DeepEqual(fields, []interface{}{"client_id", client.ID, "request_id", "req-42", "user_id", int64(7)}) {
+		t.Errorf("unexpected log fields %v", fields)
+	}
+}
+
+func TestClientRequestIDFromHeader(t *testing.T) {
+	client := AcquireClient("c1", nil, nil, codecJSON)
+	defer client.Release()
+
+	req := httptest.NewRequest("GET", "/ws", nil)
+	req.Header.Set("X-Request-ID", "from-header")
+	client.inheritRequest(hypcontext.New(httptest.NewRecorder(), req))
+	if client.RequestID() != "from-header" || client.UserID() != nil || client.Roles() != nil {
+		t.Errorf("unexpected values: %q %v %v", client.RequestID(), client.UserID(), client.Roles())
+	}
+}
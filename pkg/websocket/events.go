@@ -0,0 +1,45 @@
+// @chris
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/maoxiaoyue/hypgo/pkg/events"
+)
+
+// ===== 事件匯流排橋接 =====
+
+// PublishEvents 將匯流排 topic 的事件轉發到頻道，回傳取消橋接的函數
+// payload 為 []byte / json.RawMessage 時視為已編碼的 JSON 原樣送出，其餘以 json.Marshal 編碼；
+// 以 events.Async() 訂閱，Publish 不會等待推送給客戶端
+//
+// EX：
+//
+//	stop := hub.PublishEvents(bus, "order.shipped", "orders")
+//	srv.OnShutdown(func(ctx context.Context) error { stop(); return nil })
+func (h *Hub) PublishEvents(bus *events.Bus, topic, channel string) (stop func()) {
+	return bus.Subscribe(topic, func(ctx context.Context, e events.Event) error {
+		data, err := eventData(e.Payload)
+		if err != nil {
+			return err
+		}
+		h.PublishToChannelRaw(channel, data)
+		return nil
+	}, events.Async(), events.Named("websocket:"+channel))
+}
+
+func eventData(payload interface{}) ([]byte, error) {
+	switch p := payload.(type) {
+	case json.RawMessage:
+		return p, nil
+	case []byte:
+		return p, nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("websocket: encode event payload: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,52 @@
+package websocket
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/maoxiaoyue/hypgo/pkg/events"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+func TestPublishEvents(t *testing.T) {
+	hub := NewHub(logger.NewLogger(), DefaultConfig)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	r := router.New()
+	r.GET("/ws", hub.ServeHTTP)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer conn.Close()
+	conn.WriteJSON(map[string]interface{}{"type": "subscribe", "data": map[string]string{"channel": "orders"}})
+	waitFor(t, "subscription", func() bool { return len(hub.Presence("orders")) == 1 })
+
+	bus := events.New(events.Config{Workers: 1})
+	stop := hub.PublishEvents(bus, "order.shipped", "orders")
+
+	bus.Publish(context.Background(), "order.shipped", map[string]int{"id": 7})
+	bus.Publish(context.Background(), "order.shipped", []byte(`"raw"`))
+	for _, want := range []string{`{"id":7}`, `"raw"`} {
+		if msg := readMessage(t, conn); msg.Channel != "orders" || string(msg.Data) != want {
+			t.Errorf("Expected %s on orders, got %+v", want, msg)
+		}
+	}
+
+	stop()
+	if n := bus.Topics()["order.shipped"]; n != 0 {
+		t.Errorf("Expected bridge to unsubscribe, still %d subscribers", n)
+	}
+	if err := bus.Close(context.Background()); err != nil {
+		t.Errorf("Close: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// @chris
+package websocket
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ===== Prometheus 指標 =====
+
+// CollectorOption 指標收集器選項
+type CollectorOption func(*hubCollector)
+
+// WithChannelLabels 額外輸出每個頻道的訂閱數（channel 標籤）
+// 頻道名稱若來自使用者輸入會造成高基數，僅在頻道數量有限時啟用
+func WithChannelLabels() CollectorOption {
+	return func(c *hubCollector) {
+		c.perChannel = true
+	}
+}
+
+// WithRoomLabels 額外輸出每個房間的成員數（room 標籤）
+// 房間通常隨使用者動態建立，僅在房間數量有限時啟用
+func WithRoomLabels() CollectorOption {
+	return func(c *hubCollector) {
+		c.perRoom = true
+	}
+}
+
+// hubCollector 於每次抓取時讀取 Hub 統計，不另外維護計數
+type hubCollector struct {
+	hub        *Hub
+	perChannel bool
+	perRoom    bool
+
+	activeConns      *prometheus.Desc
+	totalConns       *prometheus.Desc
+	messagesSent     *prometheus.Desc
+	messagesReceived *prometheus.Desc
+	bytesSent        *prometheus.Desc
+	bytesReceived    *prometheus.Desc
+	channels         *prometheus.Desc
+	rooms            *prometheus.Desc
+	channelClients   *prometheus.Desc
+	roomClients      *prometheus.Desc
+}
+
+// Collector 回傳 Hub 的 Prometheus 收集器
+// 預設只輸出不帶標籤的總量，逐頻道 / 房間的數值需以 WithChannelLabels / WithRoomLabels 啟用。
+//
+// EX：
+//
+//	reg := prometheus.NewRegistry()
+//	provider, _ := middleware.NewPrometheusMeterProvider(reg)
+//	reg.MustRegister(hub.Collector())
+//	srv.Use(middleware.Metrics(middleware.MetricsConfig{MeterProvider: provider}))
+//	srv.Router().GET("/metrics", middleware.PrometheusHandler(reg))
+func (h *Hub) Collector(opts ...CollectorOption) prometheus.Collector {
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		return prometheus.NewDesc("hypgo_websocket_"+name, help, labels, nil)
+	}
+	c := &hubCollector{
+		hub:              h,
+		activeConns:      desc("connections_active", "Number of currently connected WebSocket clients."),
+		totalConns:       desc("connections_total", "Total number of accepted WebSocket connections."),
+		messagesSent:     desc("messages_sent_total", "Total number of messages queued to clients."),
+		messagesReceived: desc("messages_received_total", "Total number of messages received from clients."),
+		bytesSent:        desc("sent_bytes_total", "Total bytes queued to clients."),
+		bytesReceived:    desc("received_bytes_total", "Total bytes received from clients."),
+		channels:         desc("channels", "Number of channels with at least one subscriber."),
+		rooms:            desc("rooms", "Number of rooms."),
+		channelClients:   desc("channel_subscribers", "Number of subscribers per channel.", "channel"),
+		roomClients:      desc("room_clients", "Number of clients per room.", "room"),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Describe 實現 prometheus.Collector
+func (c *hubCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeConns
+	ch <- c.totalConns
+	ch <- c.messagesSent
+	ch <- c.messagesReceived
+	ch <- c.bytesSent
+	ch <- c.bytesReceived
+	ch <- c.channels
+	ch <- c.rooms
+	if c.perChannel {
+		ch <- c.channelClients
+	}
+	if c.perRoom {
+		ch <- c.roomClients
+	}
+}
+
+// Collect 實現 prometheus.Collector
+func (c *hubCollector) Collect(ch chan<- prometheus.Metric) {
+	h := c.hub
+	counter := func(d *prometheus.Desc, v int64) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, float64(v))
+	}
+	gauge := func(d *prometheus.Desc, v int, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, float64(v), labels...)
+	}
+
+	gauge(c.activeConns, int(h.stats.ActiveConnections.Load()))
+	counter(c.totalConns, h.stats.TotalConnections.Load())
+	counter(c.messagesSent, h.stats.MessagesSent.Load())
+	counter(c.messagesReceived, h.stats.MessagesReceived.Load())
+	counter(c.bytesSent, h.stats.BytesSent.Load())
+	counter(c.bytesReceived, h.stats.BytesReceived.Load())
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	gauge(c.channels, len(h.channels))
+	gauge(c.rooms, len(h.rooms))
+	if c.perChannel {
+		for channel, clients := range h.channels {
+			gauge(c.channelClients, len(clients), channel)
+		}
+	}
+	if c.perRoom {
+		for id, room := range h.rooms {
+			room.mu.RLock()
+			n := len(room.Clients)
+			room.mu.RUnlock()
+			gauge(c.roomClients, n, id)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package websocket
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestHubCollector(t *testing.T) {
+	hub := NewHub(logger.NewLogger(), DefaultConfig)
+	a := AcquireClient("a", nil, hub, codecJSON)
+	b := AcquireClient("b", nil, hub, codecJSON)
+	hub.handleRegister(a)
+	hub.handleRegister(b)
+	a.Subscribe("news")
+	b.Subscribe("news")
+	a.JoinRoom("lobby")
+	hub.stats.MessagesReceived.Add(3)
+	hub.stats.BytesReceived.Add(42)
+
+	expected := `
+# HELP hypgo_websocket_connections_active Number of currently connected WebSocket clients.
+# TYPE hypgo_websocket_connections_active gauge
+hypgo_websocket_connections_active 2
+# HELP hypgo_websocket_connections_total Total number of accepted WebSocket connections.
+# TYPE hypgo_websocket_connections_total counter
+hypgo_websocket_connections_total 2
+# HELP hypgo_websocket_messages_received_total Total number of messages received from clients.
+# TYPE hypgo_websocket_messages_received_total counter
+hypgo_websocket_messages_received_total 3
+# HELP hypgo_websocket_received_bytes_total Total bytes received from clients.
+# TYPE hypgo_websocket_received_bytes_total counter
+hypgo_websocket_received_bytes_total 42
+# HELP hypgo_websocket_channels Number of channels with at least one subscriber.
+# TYPE hypgo_websocket_channels gauge
+hypgo_websocket_channels 1
+# HELP hypgo_websocket_rooms Number of rooms.
+# TYPE hypgo_websocket_rooms gauge
+hypgo_websocket_rooms 1
+`
+	names := []string{
+		"hypgo_websocket_connections_active", "hypgo_websocket_connections_total",
+		"hypgo_websocket_messages_received_total", "hypgo_websocket_received_bytes_total",
+		"hypgo_websocket_channels", "hypgo_websocket_rooms",
+	}
+	if err := testutil.CollectAndCompare(hub.Collector(), strings.NewReader(expected), names...); err != nil {
+		t.Error(err)
+	}
+
+	// 預設不輸出逐頻道 / 房間的標籤
+	if n := testutil.CollectAndCount(hub.Collector()); n != 8 {
+		t.Errorf("Expected 8 unlabelled metrics by default, got %d", n)
+	}
+	labelled := hub.Collector(WithChannelLabels(), WithRoomLabels())
+	if n := testutil.CollectAndCount(labelled, "hypgo_websocket_channel_subscribers"); n != 1 {
+		t.Errorf("Expected 1 channel series, got %d", n)
+	}
+	expected = `
+# HELP hypgo_websocket_room_clients Number of clients per room.
+# TYPE hypgo_websocket_room_clients gauge
+hypgo_websocket_room_clients{room="lobby"} 1
+`
+	if err := testutil.CollectAndCompare(labelled, strings.NewReader(expected), "hypgo_websocket_room_clients"); err != nil {
+		t.Error(err)
+	}
+
+	// 斷線後 active 減少、total 不變，頻道移除該訂閱者
+	hub.handleUnregister(b)
+	expected = `
+# HELP hypgo_websocket_connections_active Number of currently connected WebSocket clients.
+# TYPE hypgo_websocket_connections_active gauge
+hypgo_websocket_connections_active 1
+# HELP hypgo_websocket_connections_total Total number of accepted WebSocket connections.
+# TYPE hypgo_websocket_connections_total counter
+hypgo_websocket_connections_total 2
+# HELP hypgo_websocket_channel_subscribers Number of subscribers per channel.
+# TYPE hypgo_websocket_channel_subscribers gauge
+hypgo_websocket_channel_subscribers{channel="news"} 1
+`
+	if err := testutil.CollectAndCompare(labelled, strings.NewReader(expected),
+		"hypgo_websocket_connections_active", "hypgo_websocket_connections_total",
+		"hypgo_websocket_channel_subscribers"); err != nil {
+		t.Error(err)
+	}
+}
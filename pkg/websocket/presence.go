@@ -0,0 +1,134 @@
+// @chris
+package websocket
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+// ===== Presence =====
+
+// presence 系統訊息類型（Message.Type）
+const (
+	presenceJoin  = "join"
+	presenceLeave = "leave"
+)
+
+// ClientInfo 在線成員資訊
+type ClientInfo struct {
+	ID           string    `json:"id"`
+	ConnectedAt  time.Time `json:"connected_at"`
+	LastActivity time.Time `json:"last_activity"`
+}
+
+// presenceChange 一次成員異動：channel 與 roomID 擇一，members 為需通知的其他成員
+type presenceChange struct {
+	channel string
+	roomID  string
+	members []*Client
+}
+
+// touch 記錄最後活動時間
+func (c *Client) touch() {
+	c.lastActivity.Store(time.Now().UnixNano())
+}
+
+// LastActivity 最後一次收到訊息或 pong 的時間
+func (c *Client) LastActivity() time.Time {
+	return time.Unix(0, c.lastActivity.Load())
+}
+
+// info 在線資訊快照
+func (c *Client) info() ClientInfo {
+	return ClientInfo{ID: c.ID, ConnectedAt: c.connectedAt, LastActivity: c.LastActivity()}
+}
+
+// Presence 回傳頻道目前的訂閱者，依連線時間排序
+func (h *Hub) Presence(channel string) []ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return sortedInfo(clientSet(h.channels[channel]))
+}
+
+// RoomPresence 回傳房間目前的成員，依連線時間排序
+func (h *Hub) RoomPresence(roomID string) []ClientInfo {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	room, ok := h.rooms[roomID]
+	if !ok {
+		return nil
+	}
+	room.mu.RLock()
+	defer room.mu.RUnlock()
+	return sortedInfo(clientSet(room.Clients))
+}
+
+func sortedInfo(clients []*Client) []ClientInfo {
+	if len(clients) == 0 {
+		return nil
+	}
+	infos := make([]ClientInfo, len(clients))
+	for i, c := range clients {
+		infos[i] = c.info()
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].ConnectedAt.Equal(infos[j].ConnectedAt) {
+			return infos[i].ConnectedAt.Before(infos[j].ConnectedAt)
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos
+}
+
+// clientSet 將成員集合轉為 slice，排除 except；呼叫端需持有對應的鎖
+func clientSet(set map[*Client]bool, except ...*Client) []*Client {
+	clients := make([]*Client, 0, len(set))
+	for c := range set {
+		if len(except) == 0 || c != except[0] {
+			clients = append(clients, c)
+		}
+	}
+	return clients
+}
+
+// remove 從房間移除客戶端，回傳是否原為成員與剩餘成員
+func (r *Room) remove(client *Client) (member bool, remaining []*Client) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	member = r.Clients[client]
+	if member {
+		delete(r.Clients, client)
+		r.lastActivity = time.Now()
+	}
+	return member, clientSet(r.Clients)
+}
+
+// notifyPresence 向其他成員發送 join / leave 系統訊息（需啟用 Config.PresenceEvents）
+// data 為 {"client_id": ..., "channel" 或 "room_id": ...}；呼叫時不可持有 Hub 或 Room 的鎖
+func (h *Hub) notifyPresence(event, clientID string, change presenceChange) {
+	if !h.config.PresenceEvents || len(change.members) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(struct {
+		ClientID string `json:"client_id"`
+		Channel  string `json:"channel,omitempty"`
+		RoomID   string `json:"room_id,omitempty"`
+	}{clientID, change.channel, change.roomID})
+	if err != nil {
+		return
+	}
+
+	msg := AcquireMessage()
+	defer msg.Release()
+	msg.Type = event
+	msg.Channel = change.channel
+	msg.ClientID = clientID
+	msg.Data = data
+
+	marshalForClients(msg, change.members, h.security, func(n int64) {
+		h.stats.MessagesSent.Add(1)
+		h.stats.BytesSent.Add(n)
+	})
+}
@@ -0,0 +1,112 @@
+package websocket
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+)
+
+// recvPresence 從 Send 取出一則訊息並解析，無訊息時回傳 nil
+func recvPresence(t *testing.T, c *Client) *Message {
+	t.Helper()
+	select {
+	case data := <-c.Send:
+		var msg Message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			t.Fatalf("invalid message: %v", err)
+		}
+		return &msg
+	default:
+		return nil
+	}
+}
+
+func TestHubPresence(t *testing.T) {
+	config := DefaultConfig
+	config.PresenceEvents = true
+	hub := NewHub(logger.NewLogger(), config)
+
+	a := AcquireClient("a", nil, hub, codecJSON)
+	time.Sleep(time.Millisecond)
+	b := AcquireClient("b", nil, hub, codecJSON)
+	hub.handleRegister(a)
+	hub.handleRegister(b)
+
+	a.Subscribe("chat")
+	if msg := recvPresence(t, a); msg != nil {
+		t.Errorf("joining client should not receive its own join, got %+v", msg)
+	}
+	b.Subscribe("chat")
+	b.Subscribe("chat") // 重複訂閱不重發
+	msg := recvPresence(t, a)
+	if msg == nil || msg.Type != "join" || msg.Channel != "chat" || msg.ClientID != "b" {
+		t.Fatalf("Expected join from b, got %+v", msg)
+	}
+	if extra := recvPresence(t, a); extra != nil {
+		t.Errorf("duplicate subscribe should not emit, got %+v", extra)
+	}
+
+	infos := hub.Presence("chat")
+	if len(infos) != 2 || infos[0].ID != "a" || infos[1].ID != "b" {
+		t.Fatalf("Expected [a b] ordered by connect time, got %+v", infos)
+	}
+	if infos[0].LastActivity.IsZero() || infos[0].ConnectedAt.After(infos[1].ConnectedAt) {
+		t.Errorf("unexpected presence info: %+v", infos)
+	}
+
+	// 房間：加入與離開
+	a.JoinRoom("lobby")
+	b.JoinRoom("lobby")
+	if msg := recvPresence(t, a); msg == nil || msg.Type != "join" || string(msg.Data) != `{"client_id":"b","room_id":"lobby"}` {
+		t.Fatalf("Expected room join from b, got %+v", msg)
+	}
+	if got := hub.RoomPresence("lobby"); len(got) != 2 {
+		t.Errorf("Expected 2 room members, got %+v", got)
+	}
+
+	// 斷線：其他成員收到頻道與房間的 leave，presence 清除
+	hub.handleUnregister(b)
+	events := map[string]bool{}
+	for msg := recvPresence(t, a); msg != nil; msg = recvPresence(t, a) {
+		if msg.Type != "leave" || msg.ClientID != "b" {
+			t.Errorf("unexpected event %+v", msg)
+		}
+		events[string(msg.Data)] = true
+	}
+	if !events[`{"client_id":"b","channel":"chat"}`] || !events[`{"client_id":"b","room_id":"lobby"}`] {
+		t.Errorf("Expected channel and room leave events, got %v", events)
+	}
+	if got := hub.Presence("chat"); len(got) != 1 || got[0].ID != "a" {
+		t.Errorf("Expected only a in chat, got %+v", got)
+	}
+
+	// 最後一人離開時房間刪除
+	a.LeaveRoom("lobby")
+	if got := hub.RoomPresence("lobby"); got != nil {
+		t.Errorf("Expected empty room to be removed, got %+v", got)
+	}
+	if n := hub.GetStats()["total_rooms"]; n != 0 {
+		t.Errorf("Expected 0 rooms, got %v", n)
+	}
+}
+
+func TestHubPresenceEventsDisabled(t *testing.T) {
+	hub := NewHub(logger.NewLogger(), DefaultConfig)
+	a := AcquireClient("a", nil, hub, codecJSON)
+	b := AcquireClient("b", nil, hub, codecJSON)
+	hub.handleRegister(a)
+	hub.handleRegister(b)
+
+	a.Subscribe("chat")
+	b.Subscribe("chat")
+	hub.handleUnregister(b)
+
+	if msg := recvPresence(t, a); msg != nil {
+		t.Errorf("presence events are opt-in, got %+v", msg)
+	}
+	if got := hub.Presence("chat"); len(got) != 1 {
+		t.Errorf("presence should be tracked regardless of events, got %+v", got)
+	}
+}
@@ -0,0 +1,88 @@
+// @chris
+package websocket
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"golang.org/x/time/rate"
+)
+
+// ===== 訊息限速 =====
+
+// RateLimitPolicy 客戶端超過訊息速率時的處理方式
+type RateLimitPolicy string
+
+const (
+	// RateLimitDrop 丟棄超量訊息（預設）
+	RateLimitDrop RateLimitPolicy = "drop"
+	// RateLimitWarn 丟棄超量訊息，並以 type "warning" 訊息通知客戶端（每秒最多一次）
+	RateLimitWarn RateLimitPolicy = "warn"
+	// RateLimitDisconnect 以 1008 Policy Violation 關閉連線
+	RateLimitDisconnect RateLimitPolicy = "disconnect"
+)
+
+// MetadataRateLimitViolations 客戶端 metadata 中累計超速次數（int）的鍵
+const MetadataRateLimitViolations = "rate_limit_violations"
+
+// rateLimitWarningInterval 限速警告的最小間隔，避免警告本身成為放大流量
+const rateLimitWarningInterval = time.Second
+
+// newMessageLimiter 依配置建立客戶端令牌桶，未設定速率時回傳 nil
+func (c Config) newMessageLimiter() *rate.Limiter {
+	if c.MessageRate <= 0 {
+		return nil
+	}
+	burst := c.MessageBurst
+	if burst <= 0 {
+		burst = int(c.MessageRate)
+		if burst < 1 {
+			burst = 1
+		}
+	}
+	return rate.NewLimiter(rate.Limit(c.MessageRate), burst)
+}
+
+// RateLimitViolations 回傳客戶端累計超速次數
+func (c *Client) RateLimitViolations() int {
+	n, _ := c.GetMetadata(MetadataRateLimitViolations)
+	violations, _ := n.(int)
+	return violations
+}
+
+// onRateLimited 記錄超速並依策略處理，回傳 false 表示應關閉連線
+func (c *Client) onRateLimited(policy RateLimitPolicy) bool {
+	c.mu.Lock()
+	violations, _ := c.metadata[MetadataRateLimitViolations].(int)
+	violations++
+	c.metadata[MetadataRateLimitViolations] = violations
+	c.mu.Unlock()
+
+	switch policy {
+	case RateLimitDisconnect:
+		c.Hub.logger.Warningf("Client %s exceeded message rate limit, disconnecting", c.ID)
+		closeMsg := websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "message rate limit exceeded")
+		c.Conn.WriteControl(websocket.CloseMessage, closeMsg, time.Now().Add(time.Second))
+		return false
+
+	case RateLimitWarn:
+		if now := time.Now(); now.Sub(c.lastWarning) >= rateLimitWarningInterval {
+			c.lastWarning = now
+			msg := AcquireMessage()
+			msg.Type = "warning"
+			msg.Data, _ = json.Marshal(map[string]interface{}{
+				"code":       "rate_limited",
+				"message":    "message rate limit exceeded, messages are being dropped",
+				"violations": violations,
+			})
+			c.Hub.SendToClient(c.ID, msg)
+			msg.Release()
+		}
+	}
+
+	if violations == 1 {
+		c.Hub.logger.Warningf("Client %s exceeded message rate limit", c.ID)
+	}
+	return true
+}
@@ -0,0 +1,114 @@
+package websocket
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+// dialRateLimitedHub 啟動套用 config 的 Hub，回傳已連線的客戶端與收到的訊息計數
+func dialRateLimitedHub(t *testing.T, config Config) (*websocket.Conn, *Hub, *atomic.Int32) {
+	t.Helper()
+	hub := NewHub(logger.NewLogger(), config)
+	var received atomic.Int32
+	hub.SetCallbacks(nil, nil, func(*Client, *Message) { received.Add(1) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go hub.Run(ctx)
+
+	r := router.New()
+	r.GET("/ws", hub.ServeHTTP)
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws?client_id=flood", nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, hub, &received
+}
+
+func sendN(t *testing.T, conn *websocket.Conn, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := conn.WriteMessage(websocket.TextMessage, []byte(`{"type":"ping"}`)); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+	}
+}
+
+func TestMessageRateLimitWarn(t *testing.T) {
+	config := DefaultConfig
+	config.MessageRate = 1
+	config.MessageBurst = 3
+	config.RateLimitPolicy = RateLimitWarn
+	conn, hub, received := dialRateLimitedHub(t, config)
+
+	sendN(t, conn, 10)
+
+	// 第一次超速即收到警告，其後一秒內不重複
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := conn.ReadMessage()
+	if err != nil || !strings.Contains(string(data), `"type":"warning"`) || !strings.Contains(string(data), "rate_limited") {
+		t.Fatalf("Expected rate limit warning, got %s (%v)", data, err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for received.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if n := received.Load(); n != 3 {
+		t.Errorf("Expected burst of 3 messages dispatched, got %d", n)
+	}
+
+	hub.mu.RLock()
+	client := hub.clients["flood"]
+	hub.mu.RUnlock()
+	if client == nil || client.RateLimitViolations() != 7 {
+		t.Errorf("Expected 7 violations recorded in metadata, got %v", client)
+	}
+}
+
+func TestMessageRateLimitDisconnect(t *testing.T) {
+	config := DefaultConfig
+	config.MessageRate = 1
+	config.MessageBurst = 2
+	config.RateLimitPolicy = RateLimitDisconnect
+	conn, _, received := dialRateLimitedHub(t, config)
+
+	sendN(t, conn, 3)
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+				t.Fatalf("Expected policy violation close, got %v", err)
+			}
+			break
+		}
+	}
+	if n := received.Load(); n != 2 {
+		t.Errorf("Expected 2 messages dispatched before disconnect, got %d", n)
+	}
+}
+
+func TestNewMessageLimiter(t *testing.T) {
+	if (Config{}).newMessageLimiter() != nil {
+		t.Error("Expected no limiter when MessageRate is 0")
+	}
+	if l := (Config{MessageRate: 0.5}).newMessageLimiter(); l == nil || l.Burst() != 1 {
+		t.Errorf("Expected burst to default to at least 1, got %v", l)
+	}
+	if l := (Config{MessageRate: 20}).newMessageLimiter(); l.Burst() != 20 {
+		t.Errorf("Expected burst to default to the rate, got %d", l.Burst())
+	}
+}
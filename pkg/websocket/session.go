@@ -0,0 +1,178 @@
+// @chris
+package websocket
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// ===== Session 恢復 =====
+
+// MetadataSessionToken 客戶端 metadata 中目前 session token 的鍵
+const MetadataSessionToken = "session_token"
+
+// defaultSessionBufferSize 未設定 SessionBufferSize 時每個 session 保留的訊息數
+const defaultSessionBufferSize = 100
+
+// detachedSession 斷線後保留的訂閱與期間錯過的訊息
+type detachedSession struct {
+	clientID string
+	channels map[string]bool
+	buffer   []Message // 僅保留最後 N 則，Data 為獨立副本
+	dropped  int       // 超出 N 而被捨棄的訊息數
+	expires  time.Time
+}
+
+// sessionStore 以 token 索引的斷線 session
+type sessionStore struct {
+	mu       sync.Mutex
+	detached map[string]*detachedSession
+}
+
+// detach 客戶端斷線時保留其訂閱，TTL 內可憑 token 恢復
+func (s *sessionStore) detach(token, clientID string, channels []string, ttl time.Duration) {
+	set := make(map[string]bool, len(channels))
+	for _, ch := range channels {
+		set[ch] = true
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.detached == nil {
+		s.detached = make(map[string]*detachedSession)
+	}
+	s.detached[token] = &detachedSession{
+		clientID: clientID,
+		channels: set,
+		expires:  time.Now().Add(ttl),
+	}
+}
+
+// take 取出並移除 token 對應的 session；token 只能使用一次
+func (s *sessionStore) take(token string) *detachedSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.detached[token]
+	if !ok {
+		return nil
+	}
+	delete(s.detached, token)
+	if time.Now().After(sess.expires) {
+		return nil
+	}
+	return sess
+}
+
+// record 將發往 channel 的訊息存入訂閱該頻道的斷線 session；channel 為空表示廣播
+func (s *sessionStore) record(channel string, msg *Message, limit int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.detached) == 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, sess := range s.detached {
+		if now.After(sess.expires) || (channel != "" && !sess.channels[channel]) {
+			continue
+		}
+		copied := *msg
+		copied.Data = append(json.RawMessage(nil), msg.Data...)
+		sess.buffer = append(sess.buffer, copied)
+		if over := len(sess.buffer) - limit; over > 0 {
+			sess.buffer = append(sess.buffer[:0], sess.buffer[over:]...)
+			sess.dropped += over
+		}
+	}
+}
+
+// purge 移除過期的 session
+func (s *sessionStore) purge(now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for token, sess := range s.detached {
+		if now.After(sess.expires) {
+			delete(s.detached, token)
+		}
+	}
+}
+
+// newSessionToken 產生 128-bit 隨機 token
+func newSessionToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sessionBufferSize 每個 session 保留的訊息上限
+func (h *Hub) sessionBufferSize() int {
+	if h.config.SessionBufferSize > 0 {
+		return h.config.SessionBufferSize
+	}
+	return defaultSessionBufferSize
+}
+
+// recordForSessions 在啟用 session 恢復時，為斷線客戶端保留錯過的訊息
+func (h *Hub) recordForSessions(channel string, msg *Message) {
+	if h.config.SessionTTL > 0 {
+		h.sessions.record(channel, msg, h.sessionBufferSize())
+	}
+}
+
+// startSession 為新連線發出 session token；resumed 非 nil 時重播錯過的訊息並恢復訂閱
+// 客戶端先收到 type "session" 訊息：{"token", "resumed", "replayed", "dropped"}，其後為重播內容。
+// 重播先寫入 Send 佇列再重新訂閱，確保重播訊息排在即時訊息之前
+func (h *Hub) startSession(client *Client, resumed *detachedSession) {
+	token := newSessionToken()
+	client.SetMetadata(MetadataSessionToken, token)
+
+	var replayed, dropped int
+	if resumed != nil {
+		replayed, dropped = len(resumed.buffer), resumed.dropped
+	}
+
+	msg := AcquireMessage()
+	msg.Type = "session"
+	msg.Data, _ = json.Marshal(map[string]interface{}{
+		"token":    token,
+		"resumed":  resumed != nil,
+		"replayed": replayed,
+		"dropped":  dropped,
+	})
+	h.sendToClient(client, msg)
+	msg.Release()
+
+	if resumed == nil {
+		return
+	}
+	for i := range resumed.buffer {
+		if err := h.sendToClient(client, &resumed.buffer[i]); err != nil {
+			h.logger.Warningf("Session replay for client %s stopped: %v", client.ID, err)
+			break
+		}
+	}
+	for ch := range resumed.channels {
+		client.Subscribe(ch)
+	}
+	h.logger.Infof("Client %s resumed session, replayed %d messages", client.ID, replayed)
+}
+
+// detachSession 斷線時保留客戶端的 session（需啟用 SessionTTL 且已發出 token）
+func (h *Hub) detachSession(client *Client) {
+	if h.config.SessionTTL <= 0 {
+		return
+	}
+	token, ok := client.GetMetadata(MetadataSessionToken)
+	if !ok {
+		return
+	}
+	client.mu.RLock()
+	channels := make([]string, 0, len(client.Channels))
+	for ch := range client.Channels {
+		channels = append(channels, ch)
+	}
+	client.mu.RUnlock()
+	h.sessions.detach(token.(string), client.ID, channels, h.config.SessionTTL)
+}
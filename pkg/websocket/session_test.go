@@ -0,0 +1,150 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"github.com/maoxiaoyue/hypgo/pkg/router"
+)
+
+type sessionInfo struct {
+	Token    string `json:"token"`
+	Resumed  bool   `json:"resumed"`
+	Replayed int    `json:"replayed"`
+	Dropped  int    `json:"dropped"`
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) Message {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg Message
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+func readSession(t *testing.T, conn *websocket.Conn) sessionInfo {
+	t.Helper()
+	msg := readMessage(t, conn)
+	var info sessionInfo
+	if msg.Type != "session" || json.Unmarshal(msg.Data, &info) != nil || info.Token == "" {
+		t.Fatalf("Expected session message, got %+v", msg)
+	}
+	return info
+}
+
+// waitFor 輪詢直到條件成立
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSessionResume(t *testing.T) {
+	config := DefaultConfig
+	config.SessionTTL = time.Minute
+	config.SessionBufferSize = 2
+	hub := NewHub(logger.NewLogger(), config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go hub.Run(ctx)
+
+	r := router.New()
+	r.GET("/ws", hub.ServeHTTP)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws?client_id=mobile"
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	first := readSession(t, conn)
+	if first.Resumed {
+		t.Fatal("Expected fresh session on first connect")
+	}
+	conn.WriteJSON(map[string]interface{}{"type": "subscribe", "data": map[string]string{"channel": "news"}})
+	waitFor(t, "subscription", func() bool { return len(hub.Presence("news")) == 1 })
+
+	conn.Close()
+	waitFor(t, "detach", func() bool {
+		hub.sessions.mu.Lock()
+		defer hub.sessions.mu.Unlock()
+		return len(hub.sessions.detached) == 1
+	})
+
+	for _, body := range []string{`1`, `2`, `3`} {
+		hub.PublishToChannelRaw("news", []byte(body))
+	}
+	hub.PublishToChannelRaw("other", []byte(`"ignored"`))
+
+	header := http.Header{"X-Session-Token": {first.Token}}
+	conn2, _, err := websocket.DefaultDialer.Dial(strings.Replace(url, "mobile", "new-id", 1), header)
+	if err != nil {
+		t.Fatalf("redial: %v", err)
+	}
+	defer conn2.Close()
+
+	resumed := readSession(t, conn2)
+	if !resumed.Resumed || resumed.Replayed != 2 || resumed.Dropped != 1 || resumed.Token == first.Token {
+		t.Fatalf("Unexpected resume info: %+v", resumed)
+	}
+	for _, want := range []string{`2`, `3`} {
+		if msg := readMessage(t, conn2); msg.Channel != "news" || string(msg.Data) != want {
+			t.Errorf("Expected replay of %s on news, got %+v", want, msg)
+		}
+	}
+
+	// 沿用原 ID 並恢復訂閱
+	if p := hub.Presence("news"); len(p) != 1 || p[0].ID != "mobile" {
+		t.Fatalf("Expected resumed subscription for mobile, got %+v", p)
+	}
+	hub.PublishToChannelRaw("news", []byte(`4`))
+	if msg := readMessage(t, conn2); string(msg.Data) != `4` {
+		t.Errorf("Expected live message after resume, got %+v", msg)
+	}
+
+	// token 只能使用一次
+	if hub.sessions.take(first.Token) != nil {
+		t.Error("Expected used token to be consumed")
+	}
+}
+
+func TestSessionStoreExpiry(t *testing.T) {
+	var s sessionStore
+	s.detach("expired", "a", []string{"news"}, -time.Second)
+	s.detach("live", "b", []string{"news"}, time.Minute)
+
+	msg := &Message{Type: "message", Channel: "news", Data: []byte(`1`)}
+	s.record("news", msg, 10)
+	msg.Data[0] = '9'
+	s.record("", &Message{Type: "broadcast", Data: []byte(`2`)}, 10)
+
+	if s.take("expired") != nil {
+		t.Error("Expected expired session to be rejected")
+	}
+	sess := s.take("live")
+	if sess == nil || len(sess.buffer) != 2 || string(sess.buffer[0].Data) != `1` {
+		t.Fatalf("Expected buffered copies of both messages, got %+v", sess)
+	}
+
+	s.detach("old", "c", nil, -time.Second)
+	s.purge(time.Now())
+	if len(s.detached) != 0 {
+		t.Errorf("Expected purge to drop expired sessions, %d left", len(s.detached))
+	}
+}
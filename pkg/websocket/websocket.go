@@ -10,11 +10,13 @@ import (
 	"fmt"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 	hypcontext "github.com/maoxiaoyue/hypgo/pkg/context"
 	"github.com/maoxiaoyue/hypgo/pkg/logger"
+	"golang.org/x/time/rate"
 )
 
 // ===== 物件池 =====
@@ -96,6 +98,18 @@ type Config struct {
 	TLS               *TLSConfig         // nil = ws://，non-nil = wss://（獨立模式）
 	Security          *SecurityConfig    // nil = 無安全層（AES + HMAC）
 	Compression       *CompressionConfig // nil 時回退 EnableCompression
+	PresenceEvents    bool               // 訂閱 / 加入房間與離開（含斷線）時，向其他成員發送 join / leave 系統訊息
+
+	// 每個客戶端的訊息速率上限（令牌桶）：MessageRate 為每秒訊息數，0 不限制；
+	// MessageBurst 為瞬間上限，0 時取 MessageRate（至少 1）；超過時依 RateLimitPolicy 處理
+	MessageRate     float64
+	MessageBurst    int
+	RateLimitPolicy RateLimitPolicy
+
+	// 斷線恢復：SessionTTL > 0 時，斷線客戶端的訂閱與錯過的最後 SessionBufferSize 則訊息
+	// （0 時為 100）保留 SessionTTL；客戶端以 X-Session-Token 標頭或 session_token 參數重連即可恢復
+	SessionTTL        time.Duration
+	SessionBufferSize int
 }
 
 // DefaultConfig 預設配置
@@ -201,7 +215,11 @@ type Client struct {
 	mu           sync.RWMutex
 	pingTicker   *time.Ticker
 	isClosing    bool
-	lastActivity time.Time
+	connectedAt  time.Time
+	limiter      *rate.Limiter          // nil 表示不限速；僅 readPump 使用
+	lastWarning  time.Time              // 最近一次限速警告時間，僅 readPump 使用
+	refs         atomic.Int32           // 生命週期參照數，0 表示非由 ServeHTTP 管理
+	lastActivity atomic.Int64           // UnixNano；readPump 與 pong handler 更新，Presence 與清理並行讀取
 	metadata     map[string]interface{} // 客戶端元數據
 }
 
@@ -214,7 +232,11 @@ func AcquireClient(id string, conn *websocket.Conn, hub *Hub, codec Codec) *Clie
 	client.Hub = hub
 	client.codec = codec
 	client.wsFrameType = codec.WebSocketMessageType()
-	client.lastActivity = time.Now()
+	client.connectedAt = time.Now()
+	client.touch()
+	if hub != nil {
+		client.limiter = hub.config.newMessageLimiter()
+	}
 	return client
 }
 
@@ -229,6 +251,13 @@ func (c *Client) Release() {
 	clientPool.Put(c)
 }
 
+// releaseRef 釋放一個生命週期參照，最後一個參照釋放時返回池中
+func (c *Client) releaseRef() {
+	if c.refs.Add(-1) == 0 {
+		c.Release()
+	}
+}
+
 // reset 重置 Client
 // GC 優化：map 重建替代逐一 delete，channel 非阻塞 drain
 func (c *Client) reset() {
@@ -239,15 +268,23 @@ func (c *Client) reset() {
 	c.codec = nil
 	c.wsFrameType = 0
 	c.isClosing = false
+	c.limiter = nil
+	c.lastWarning = time.Time{}
+	c.refs.Store(0)
 
 	// GC 優化：重建 map 替代逐一 delete
 	c.Channels = make(map[string]bool, 4)
 	c.metadata = make(map[string]interface{}, 4)
 
 	// 非阻塞 drain channel：避免持有大量 []byte 引用
+	// handleUnregister 會關閉 Send，已關閉的 channel 永遠可讀，需重建後才能放回池中
 	for {
 		select {
-		case <-c.Send:
+		case _, ok := <-c.Send:
+			if !ok {
+				c.Send = make(chan []byte, 256)
+				return
+			}
 		default:
 			return
 		}
@@ -294,17 +331,17 @@ type Hub struct {
 	config     Config
 	upgrader   *Upgrader
 	security   *SecurityConfig // AES + HMAC 安全管線配置
+	sessions   sessionStore    // 斷線待恢復的 session
 	mu         sync.RWMutex
 
-	// 統計資訊
+	// 統計資訊（原子計數：pump goroutine、Run 迴圈與指標收集會並行存取）
 	stats struct {
-		TotalConnections  int64
-		ActiveConnections int32
-		MessagesSent      int64
-		MessagesReceived  int64
-		BytesSent         int64
-		BytesReceived     int64
-		mu                sync.RWMutex
+		TotalConnections  atomic.Int64
+		ActiveConnections atomic.Int32
+		MessagesSent      atomic.Int64
+		MessagesReceived  atomic.Int64
+		BytesSent         atomic.Int64
+		BytesReceived     atomic.Int64
 	}
 
 	// 回調函數
@@ -372,25 +409,26 @@ func (h *Hub) Run(ctx context.Context) {
 func (h *Hub) handleRegister(client *Client) {
 	h.mu.Lock()
 	h.clients[client.ID] = client
-	h.stats.TotalConnections++
-	h.stats.ActiveConnections++
+	h.stats.TotalConnections.Add(1)
+	h.stats.ActiveConnections.Add(1)
 	h.mu.Unlock()
 
 	if h.onConnect != nil {
 		h.onConnect(client)
 	}
 
-	h.logger.Infof("Client %s connected", client.ID)
+	h.logger.With(client.logFields()...).Infof("Client %s connected", client.ID)
 }
 
 // handleUnregister 處理客戶端註銷
 // 安全設計：即使同一 client 被多次 unregister（快速重連/斷線），也不會 panic
 func (h *Hub) handleUnregister(client *Client) {
+	var departures []presenceChange
 	h.mu.Lock()
 	_, exists := h.clients[client.ID]
 	if exists {
 		delete(h.clients, client.ID)
-		h.stats.ActiveConnections--
+		h.stats.ActiveConnections.Add(-1)
 
 		// 從所有頻道移除
 		for channel := range client.Channels {
@@ -398,13 +436,24 @@ func (h *Hub) handleUnregister(client *Client) {
 				delete(clients, client)
 				if len(clients) == 0 {
 					delete(h.channels, channel)
+				} else if h.config.PresenceEvents {
+					departures = append(departures, presenceChange{channel: channel, members: clientSet(clients)})
 				}
 			}
 		}
 
-		// 從所有房間移除
-		for _, room := range h.rooms {
-			room.RemoveClient(client)
+		// 從所有房間移除，空房間一併刪除
+		for id, room := range h.rooms {
+			member, remaining := room.remove(client)
+			if !member {
+				continue
+			}
+			if len(remaining) == 0 {
+				delete(h.rooms, id)
+				room.Release()
+			} else if h.config.PresenceEvents {
+				departures = append(departures, presenceChange{roomID: id, members: remaining})
+			}
 		}
 	}
 	h.mu.Unlock()
@@ -415,18 +464,30 @@ func (h *Hub) handleUnregister(client *Client) {
 		return
 	}
 
+	h.detachSession(client)
+
+	for _, d := range departures {
+		h.notifyPresence(presenceLeave, client.ID, d)
+	}
+
 	if h.onDisconnect != nil {
 		h.onDisconnect(client)
 	}
 
+	h.logger.Infof("Client %s disconnected", client.ID)
+
 	// 安全 close：使用 recover 防止極端 race condition 下的 double close
 	func() {
 		defer func() { recover() }()
 		close(client.Send)
 	}()
 
-	client.Release() // 返回池中
-	h.logger.Infof("Client %s disconnected", client.ID)
+	// ServeHTTP 建立的客戶端待讀寫循環都結束後才返回池中，避免 pump 存取已重置的 Client
+	if client.refs.Load() == 0 {
+		client.Release()
+	} else {
+		client.releaseRef()
+	}
 }
 
 // handleBroadcast 處理廣播（支持跨協議序列化 + 安全管線）
@@ -443,9 +504,10 @@ func (h *Hub) handleBroadcast(msg *Message) {
 	h.mu.RUnlock()
 
 	marshalForClients(msg, clients, h.security, func(n int64) {
-		h.stats.MessagesSent++
-		h.stats.BytesSent += n
+		h.stats.MessagesSent.Add(1)
+		h.stats.BytesSent.Add(n)
 	})
+	h.recordForSessions("", msg)
 
 	// 清除引用防止 client 被 pool 持有而無法 GC
 	for i := range clients {
@@ -464,12 +526,14 @@ func (h *Hub) cleanupInactiveClients() {
 
 	var inactiveClients []*Client
 	for _, client := range h.clients {
-		if now.Sub(client.lastActivity) > timeout {
+		if now.Sub(client.LastActivity()) > timeout {
 			inactiveClients = append(inactiveClients, client)
 		}
 	}
 	h.mu.RUnlock()
 
+	h.sessions.purge(now)
+
 	for _, client := range inactiveClients {
 		h.logger.Debugf("Cleaning up inactive client: %s", client.ID)
 		h.unregister <- client
@@ -494,12 +558,27 @@ func (h *Hub) ServeHTTP(c *hypcontext.Context) {
 		}
 	}
 
+	// 斷線恢復：有效 token 沿用原客戶端 ID
+	var resumed *detachedSession
+	if h.config.SessionTTL > 0 {
+		token := c.GetHeader("X-Session-Token")
+		if token == "" {
+			token = c.Query("session_token")
+		}
+		if token != "" {
+			if resumed = h.sessions.take(token); resumed != nil {
+				clientID = resumed.clientID
+			}
+		}
+	}
+
 	// 根據協商的子協議選擇 Codec
 	codec := CodecByName(conn.Subprotocol())
 
 	// 從池中獲取客戶端
 	client := AcquireClient(clientID, conn, h, codec)
 	client.Context = c // 關聯 HypGo Context
+	client.inheritRequest(c)
 
 	// 套用 permessage-deflate 壓縮配置
 	if h.config.Compression != nil {
@@ -513,16 +592,20 @@ func (h *Hub) ServeHTTP(c *hypcontext.Context) {
 	conn.SetReadLimit(h.config.MaxMessageSize)
 	conn.SetReadDeadline(time.Now().Add(h.config.PongTimeout))
 	conn.SetPongHandler(func(string) error {
-		client.lastActivity = time.Now()
+		client.touch()
 		conn.SetReadDeadline(time.Now().Add(h.config.PongTimeout))
 		return nil
 	})
 
-	// 註冊客戶端
+	// 註冊客戶端：readPump、writePump 與註銷各持有一個參照
+	client.refs.Store(3)
 	h.register <- client
 
-	// 啟動讀寫循環
+	// 啟動讀寫循環；session 訊息與重播在 readPump 之前送出，此時 Send 不會被關閉
 	go client.writePump(h.config)
+	if h.config.SessionTTL > 0 {
+		h.startSession(client, resumed)
+	}
 	go client.readPump(h.config)
 }
 
@@ -533,6 +616,7 @@ func (c *Client) readPump(config Config) {
 	defer func() {
 		c.Hub.unregister <- c
 		c.Conn.Close()
+		c.releaseRef()
 	}()
 
 	for {
@@ -544,9 +628,17 @@ func (c *Client) readPump(config Config) {
 			break
 		}
 
-		c.lastActivity = time.Now()
-		c.Hub.stats.MessagesReceived++
-		c.Hub.stats.BytesReceived += int64(len(data))
+		c.touch()
+		c.Hub.stats.MessagesReceived.Add(1)
+		c.Hub.stats.BytesReceived.Add(int64(len(data)))
+
+		// 限速：在解密與解析前檢查，避免濫用的連線消耗 CPU
+		if c.limiter != nil && !c.limiter.Allow() {
+			if !c.onRateLimited(config.RateLimitPolicy) {
+				break
+			}
+			continue
+		}
 
 		// 安全管線：解密 + 驗證簽名
 		if c.Hub.security != nil {
@@ -590,6 +682,7 @@ func (c *Client) writePump(config Config) {
 		if c.Conn != nil {
 			c.Conn.Close()
 		}
+		c.releaseRef()
 	}()
 
 	for {
@@ -695,9 +788,15 @@ func (c *Client) Subscribe(channel string) {
 	if c.Hub.channels[channel] == nil {
 		c.Hub.channels[channel] = make(map[*Client]bool)
 	}
+	joined := !c.Hub.channels[channel][c]
 	c.Hub.channels[channel][c] = true
+	var others []*Client
+	if joined && c.Hub.config.PresenceEvents {
+		others = clientSet(c.Hub.channels[channel], c)
+	}
 	c.Hub.mu.Unlock()
 
+	c.Hub.notifyPresence(presenceJoin, c.ID, presenceChange{channel: channel, members: others})
 	c.Hub.logger.Debugf("Client %s subscribed to channel %s", c.ID, channel)
 }
 
@@ -707,15 +806,20 @@ func (c *Client) Unsubscribe(channel string) {
 	delete(c.Channels, channel)
 	c.mu.Unlock()
 
+	var others []*Client
 	c.Hub.mu.Lock()
-	if clients, ok := c.Hub.channels[channel]; ok {
+	if clients, ok := c.Hub.channels[channel]; ok && clients[c] {
 		delete(clients, c)
 		if len(clients) == 0 {
 			delete(c.Hub.channels, channel)
+		} else if c.Hub.config.PresenceEvents {
+			others = clientSet(clients)
 		}
 	}
 	c.Hub.mu.Unlock()
 
+	c.Hub.notifyPresence(presenceLeave, c.ID, presenceChange{channel: channel, members: others})
+
 	c.Hub.logger.Debugf("Client %s unsubscribed from channel %s", c.ID, channel)
 }
 
@@ -819,30 +923,45 @@ func (c *Client) JoinRoom(roomID string) {
 		room = AcquireRoom(roomID)
 		c.Hub.rooms[roomID] = room
 	}
+	// 持有 Hub 鎖加入，避免與離開最後一人時的刪除房間交錯
+	room.mu.Lock()
+	joined := !room.Clients[c]
+	room.Clients[c] = true
+	room.lastActivity = time.Now()
+	var others []*Client
+	if joined && c.Hub.config.PresenceEvents {
+		others = clientSet(room.Clients, c)
+	}
+	room.mu.Unlock()
 	c.Hub.mu.Unlock()
 
-	room.AddClient(c)
+	c.Hub.notifyPresence(presenceJoin, c.ID, presenceChange{roomID: roomID, members: others})
 	c.Hub.logger.Debugf("Client %s joined room %s", c.ID, roomID)
 }
 
 // LeaveRoom 離開房間
 func (c *Client) LeaveRoom(roomID string) {
-	c.Hub.mu.RLock()
+	c.Hub.mu.Lock()
 	room, exists := c.Hub.rooms[roomID]
-	c.Hub.mu.RUnlock()
+	if !exists {
+		c.Hub.mu.Unlock()
+		return
+	}
+	member, remaining := room.remove(c)
+	// 如果房間為空，刪除房間並返回池中
+	if len(remaining) == 0 {
+		delete(c.Hub.rooms, roomID)
+		room.Release()
+	}
+	c.Hub.mu.Unlock()
 
-	if exists {
-		room.RemoveClient(c)
-		c.Hub.logger.Debugf("Client %s left room %s", c.ID, roomID)
-
-		// 如果房間為空，刪除房間
-		if len(room.Clients) == 0 {
-			c.Hub.mu.Lock()
-			delete(c.Hub.rooms, roomID)
-			c.Hub.mu.Unlock()
-			room.Release() // 返回池中
-		}
+	if !member {
+		return
+	}
+	if c.Hub.config.PresenceEvents {
+		c.Hub.notifyPresence(presenceLeave, c.ID, presenceChange{roomID: roomID, members: remaining})
 	}
+	c.Hub.logger.Debugf("Client %s left room %s", c.ID, roomID)
 }
 
 // ===== Hub 便利方法 =====
@@ -880,10 +999,6 @@ func (h *Hub) PublishToChannel(channel string, msg *Message) {
 	}
 	h.mu.RUnlock()
 
-	if len(clients) == 0 {
-		return
-	}
-
 	// 確保 channel 和 type 正確
 	pubMsg := AcquireMessage()
 	defer pubMsg.Release()
@@ -894,9 +1009,14 @@ func (h *Hub) PublishToChannel(channel string, msg *Message) {
 	pubMsg.Timestamp = msg.Timestamp
 	pubMsg.ClientID = msg.ClientID
 
+	h.recordForSessions(channel, pubMsg)
+	if len(clients) == 0 {
+		return
+	}
+
 	marshalForClients(pubMsg, clients, h.security, func(n int64) {
-		h.stats.MessagesSent++
-		h.stats.BytesSent += n
+		h.stats.MessagesSent.Add(1)
+		h.stats.BytesSent.Add(n)
 	})
 }
 
@@ -920,7 +1040,11 @@ func (h *Hub) SendToClient(clientID string, data interface{}) error {
 	if !ok {
 		return fmt.Errorf("client %s not found", clientID)
 	}
+	return h.sendToClient(client, data)
+}
 
+// sendToClient 序列化並送入客戶端的 Send 佇列，不要求客戶端已完成註冊
+func (h *Hub) sendToClient(client *Client, data interface{}) error {
 	var msgBytes []byte
 	var err error
 
@@ -944,20 +1068,18 @@ func (h *Hub) SendToClient(clientID string, data interface{}) error {
 
 	select {
 	case client.Send <- msgBytes:
-		h.stats.MessagesSent++
-		h.stats.BytesSent += int64(len(msgBytes))
+		h.stats.MessagesSent.Add(1)
+		h.stats.BytesSent.Add(int64(len(msgBytes)))
 		return nil
 	default:
-		return fmt.Errorf("client %s send buffer full", clientID)
+		return fmt.Errorf("client %s send buffer full", client.ID)
 	}
 }
 
 // GetStats 獲取統計資訊
 func (h *Hub) GetStats() map[string]interface{} {
 	h.mu.RLock()
-	h.stats.mu.RLock()
 	defer h.mu.RUnlock()
-	defer h.stats.mu.RUnlock()
 
 	channelStats := make(map[string]int)
 	for channel, clients := range h.channels {
@@ -970,12 +1092,12 @@ func (h *Hub) GetStats() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"total_connections":  h.stats.TotalConnections,
-		"active_connections": h.stats.ActiveConnections,
-		"messages_sent":      h.stats.MessagesSent,
-		"messages_received":  h.stats.MessagesReceived,
-		"bytes_sent":         h.stats.BytesSent,
-		"bytes_received":     h.stats.BytesReceived,
+		"total_connections":  h.stats.TotalConnections.Load(),
+		"active_connections": h.stats.ActiveConnections.Load(),
+		"messages_sent":      h.stats.MessagesSent.Load(),
+		"messages_received":  h.stats.MessagesReceived.Load(),
+		"bytes_sent":         h.stats.BytesSent.Load(),
+		"bytes_received":     h.stats.BytesReceived.Load(),
 		"total_clients":      len(h.clients),
 		"total_channels":     len(h.channels),
 		"total_rooms":        len(h.rooms),